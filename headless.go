@@ -0,0 +1,471 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/app"
+	"github.com/billie-coop/loco/internal/mcp"
+	"github.com/billie-coop/loco/internal/permission"
+	"github.com/billie-coop/loco/internal/server"
+	"github.com/billie-coop/loco/internal/tools"
+	"github.com/billie-coop/loco/internal/tui/events"
+)
+
+// Exit codes for headless commands
+const (
+	exitOK    = 0
+	exitError = 1 // The command ran and failed
+	exitUsage = 2 // Bad arguments
+)
+
+// headlessUsage is printed by `loco help` and on usage errors
+const headlessUsage = `Usage:
+  loco                          Start the interactive TUI
+  loco ask [flags] <question>   Answer a question from the RAG index
+  loco analyze [flags]          Analyze the project
+  loco run [flags] <command>    Run a slash command, e.g. loco run /git-status
+  loco review [flags] [target]  Review a diff: uncommitted changes, staged,
+                                a ref, a range or a patch file
+  loco serve [flags]            Serve chat, analysis and knowledge over HTTP
+  loco mcp                      Run as an MCP server on stdio
+  loco help                     Show this help
+
+Any command, or the TUI, can be pointed at another project with
+-C <dir> before it, e.g. loco -C ~/src/app mcp.
+
+Flags:
+  ask      -k N        Chunks to retrieve (default 8)
+           --json      Print the answer and sources as JSON
+  analyze  --tier T    instant, quick, detailed, deep or full (default quick)
+           --project P Analyze one workspace subproject, or all of them
+           --dry-run   Estimate files, tokens and time without calling a model
+           --json      Print the analysis as JSON
+  run      --json      Print the tool response as JSON
+  review   --json      Print the summary and comments as JSON
+           --fail-on S Exit 1 on a comment of severity S or worse: error
+                       (default), warning, note, or none
+  serve    --addr A    Listen address (default 127.0.0.1:7777)
+
+Tools whose policy is "ask" are refused, since there's nobody to ask. Set
+them to "allow" under tool_policies in .loco/config.jsonc to run them here.
+`
+
+// headlessCommands maps subcommands to their handlers
+var headlessCommands = map[string]func(a *app.App, args []string, stdout, stderr io.Writer) int{
+	"ask":     runAsk,
+	"analyze": runAnalyze,
+	"run":     runCommand,
+	"review":  runReview,
+	"serve":   runServe,
+	"mcp":     runMCP,
+}
+
+// runHeadless runs a subcommand without the TUI. It reports false when args
+// don't name one, so the TUI should start instead.
+func runHeadless(args []string, stdout, stderr io.Writer) (int, bool) {
+	// -C works like git's, for launchers that can't set the directory
+	if len(args) > 0 && args[0] == "-C" {
+		if len(args) < 2 {
+			fmt.Fprint(stderr, "-C needs a directory\n\n"+headlessUsage)
+			return exitUsage, true
+		}
+		if err := os.Chdir(args[1]); err != nil {
+			fmt.Fprintf(stderr, "Failed to change directory: %v\n", err)
+			return exitError, true
+		}
+		args = args[2:]
+	}
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch args[0] {
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, headlessUsage)
+		return exitOK, true
+	}
+	handler, ok := headlessCommands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], headlessUsage)
+		return exitUsage, true
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to get working directory: %v\n", err)
+		return exitError, true
+	}
+
+	eventBroker := events.NewBroker()
+	appInstance := app.New(workingDir, eventBroker)
+	defer appInstance.Cleanup()
+
+	if err := appInstance.InitLLMFromConfig(); err != nil {
+		fmt.Fprintf(stderr, "Failed to initialize LLM client: %v\n", err)
+		return exitError, true
+	}
+
+	// Subscribe before any tool runs so no request slips through
+	requests := eventBroker.SubscribeWith(events.SubscribeOptions{
+		Name:         "headless permissions",
+		Backpressure: events.Block,
+	}, permission.RequestTopic.Type)
+	go denyPermissionRequests(eventBroker, requests, stderr)
+
+	return handler(appInstance, args[1:], stdout, stderr), true
+}
+
+// denyPermissionRequests refuses every permission prompt, since there is no
+// dialog to answer it; tools with an "allow" policy never prompt
+func denyPermissionRequests(eventBroker *events.Broker, requests <-chan events.Event, stderr io.Writer) {
+	for event := range requests {
+		request, ok := permission.RequestTopic.Payload(event)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(stderr, "Refused %s: its policy is \"ask\" and there is no one to ask (set it to \"allow\" to run headless)\n", request.Request.ToolName)
+		eventBroker.Publish(events.Event{
+			Type: events.ToolExecutionDeniedEvent,
+			Payload: events.ToolExecutionPayload{
+				ID:       request.ID,
+				Decision: permission.DecisionDeny,
+			},
+		})
+	}
+}
+
+// runAsk answers a question with the ask_codebase tool
+func runAsk(a *app.App, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("ask", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	k := flags.Int("k", 0, "chunks to retrieve")
+	asJSON := flags.Bool("json", false, "print JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	question := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(question) == "" {
+		fmt.Fprint(stderr, "loco ask needs a question\n\n"+headlessUsage)
+		return exitUsage
+	}
+
+	input, _ := json.Marshal(tools.AskCodebaseParams{Question: question, K: *k})
+	result, err := runTool(a, tools.ToolCall{Name: tools.AskCodebaseToolName, Input: string(input)})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if result.IsError {
+		fmt.Fprintln(stderr, result.Content)
+		return exitError
+	}
+
+	var answer tools.AskCodebaseResult
+	data, _ := json.Marshal(result.Metadata)
+	if err := json.Unmarshal(data, &answer); err != nil {
+		fmt.Fprintf(stderr, "Unexpected ask_codebase response: %v\n", err)
+		return exitError
+	}
+
+	if *asJSON {
+		return writeJSON(stdout, stderr, answer)
+	}
+
+	fmt.Fprintln(stdout, answer.Answer)
+	if len(answer.Sources) > 0 {
+		fmt.Fprintln(stdout, "\nSources:")
+		for _, source := range answer.Sources {
+			line := "  " + source.Location()
+			if source.Symbol != "" {
+				line += " (" + source.Symbol + ")"
+			}
+			fmt.Fprintln(stdout, line)
+		}
+	}
+	return exitOK
+}
+
+// runAnalyze runs one analysis tier and prints the result. In a workspace
+// (go.work, pnpm-workspace.yaml or a Cargo workspace), --project picks a
+// subproject, or "all" analyzes each and writes the workspace overview.
+// --dry-run prints what the tier would take instead of running it.
+func runAnalyze(a *app.App, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	tier := flags.String("tier", string(analysis.TierQuick), "analysis tier")
+	project := flags.String("project", "", "workspace subproject, or all")
+	dryRun := flags.Bool("dry-run", false, "estimate without calling a model")
+	asJSON := flags.Bool("json", false, "print JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if *dryRun {
+		if *project == "all" {
+			fmt.Fprintln(stderr, "--dry-run estimates one project; pick it with --project")
+			return exitUsage
+		}
+		return runAnalyzeDryRun(a, *project, analysis.Tier(*tier), *asJSON, stdout, stderr)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *project == "all" {
+		return runAnalyzeWorkspace(ctx, a, analysis.Tier(*tier), *asJSON, stdout, stderr)
+	}
+
+	projectPath, err := analysis.ResolveProject(a.Sessions.ProjectPath, *project)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	result, err := analysis.RunTier(ctx, a.Analysis, projectPath, analysis.Tier(*tier))
+	if err != nil {
+		fmt.Fprintf(stderr, "Analysis failed: %v\n", err)
+		return exitError
+	}
+
+	if *asJSON {
+		return writeJSON(stdout, stderr, result)
+	}
+	fmt.Fprintln(stdout, result.FormatForPrompt())
+	return exitOK
+}
+
+// runAnalyzeDryRun prints the estimate for running tier on a project
+func runAnalyzeDryRun(a *app.App, project string, tier analysis.Tier, asJSON bool, stdout, stderr io.Writer) int {
+	projectPath, err := analysis.ResolveProject(a.Sessions.ProjectPath, project)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	estimates, err := a.EstimateAnalysis(projectPath, tier)
+	if err != nil {
+		fmt.Fprintf(stderr, "Estimate failed: %v\n", err)
+		return exitError
+	}
+
+	if asJSON {
+		return writeJSON(stdout, stderr, estimates)
+	}
+	fmt.Fprint(stdout, analysis.FormatEstimates(estimates))
+	return exitOK
+}
+
+// runAnalyzeWorkspace analyzes every subproject of the workspace and prints
+// each result, keyed by subproject name in JSON
+func runAnalyzeWorkspace(ctx context.Context, a *app.App, tier analysis.Tier, asJSON bool, stdout, stderr io.Writer) int {
+	w := analysis.DetectWorkspace(a.Sessions.ProjectPath)
+	if w == nil {
+		fmt.Fprintf(stderr, "%s is not a workspace\n", a.Sessions.ProjectPath)
+		return exitUsage
+	}
+
+	results, err := analysis.RunWorkspace(ctx, a.Analysis, w, tier)
+	if err != nil {
+		fmt.Fprintf(stderr, "Analysis failed: %v\n", err)
+	}
+
+	if asJSON {
+		if code := writeJSON(stdout, stderr, results); code != exitOK {
+			return code
+		}
+	} else {
+		for _, p := range w.Projects {
+			if result, ok := results[p.Name]; ok {
+				fmt.Fprintf(stdout, "## %s\n\n%s\n", p.Name, result.FormatForPrompt())
+			}
+		}
+	}
+	if err != nil {
+		return exitError
+	}
+	return exitOK
+}
+
+// runCommand runs a slash command through the tool registry
+func runCommand(a *app.App, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	command := strings.Join(flags.Args(), " ")
+	if command == "" {
+		fmt.Fprint(stderr, "loco run needs a command\n\n"+headlessUsage)
+		return exitUsage
+	}
+	if !strings.HasPrefix(command, "/") {
+		command = "/" + command
+	}
+
+	call, err := a.Tools.ParseCommand(command)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	result, err := runTool(a, *call)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	if *asJSON {
+		if code := writeJSON(stdout, stderr, result); code != exitOK {
+			return code
+		}
+	} else if result.IsError {
+		fmt.Fprintln(stderr, result.Content)
+	} else {
+		fmt.Fprintln(stdout, result.Content)
+	}
+	if result.IsError {
+		return exitError
+	}
+	return exitOK
+}
+
+// runReview reviews a diff with the review tool. It exits 1 when a comment
+// is at least as severe as --fail-on, so it can gate a commit or a CI job.
+func runReview(a *app.App, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("review", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print JSON")
+	failOn := flags.String("fail-on", tools.ReviewError, "severity that fails the review")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	switch *failOn {
+	case tools.ReviewError, tools.ReviewWarning, tools.ReviewNote, "none":
+	default:
+		fmt.Fprint(stderr, "--fail-on must be error, warning, note or none\n\n"+headlessUsage)
+		return exitUsage
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprint(stderr, "loco review takes one target\n\n"+headlessUsage)
+		return exitUsage
+	}
+
+	input, _ := json.Marshal(tools.ReviewParams{Target: flags.Arg(0)})
+	result, err := runTool(a, tools.ToolCall{Name: tools.ReviewToolName, Input: string(input)})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if result.IsError {
+		fmt.Fprintln(stderr, result.Content)
+		return exitError
+	}
+
+	var review tools.ReviewResult
+	if result.Metadata != nil {
+		data, _ := json.Marshal(result.Metadata)
+		if err := json.Unmarshal(data, &review); err != nil {
+			fmt.Fprintf(stderr, "Unexpected review response: %v\n", err)
+			return exitError
+		}
+	}
+
+	if *asJSON {
+		if code := writeJSON(stdout, stderr, review); code != exitOK {
+			return code
+		}
+	} else {
+		fmt.Fprintln(stdout, result.Content)
+	}
+	if *failOn != "none" {
+		for _, comment := range review.Comments {
+			if tools.SeverityRank(comment.Severity) <= tools.SeverityRank(*failOn) {
+				return exitError
+			}
+		}
+	}
+	return exitOK
+}
+
+// runServe serves the HTTP API until interrupted
+func runServe(a *app.App, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	addr := flags.String("addr", server.DefaultAddr, "listen address")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Fprintf(stdout, "Loco serving %s on http://%s (Ctrl-C to stop)\n", a.Sessions.ProjectPath, *addr)
+	if err := server.New(a).ListenAndServe(ctx, *addr); err != nil {
+		fmt.Fprintf(stderr, "Server failed: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// runMCP serves the Model Context Protocol on stdin and stdout
+func runMCP(a *app.App, args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		fmt.Fprint(stderr, "loco mcp takes no arguments\n\n"+headlessUsage)
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	mcpServer := mcp.NewServer(a.Tools, a.Analysis, a.Sessions.ProjectPath, buildVersion())
+	if err := mcpServer.Serve(ctx, os.Stdin, stdout); err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintf(stderr, "MCP server failed: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// buildVersion reports the module version when built from a tagged release
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// runTool runs a registered tool, canceling it on Ctrl-C
+func runTool(a *app.App, call tools.ToolCall) (tools.ToolResponse, error) {
+	tool, ok := a.Tools.Get(call.Name)
+	if !ok {
+		return tools.ToolResponse{}, fmt.Errorf("unknown tool: %s", call.Name)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx = context.WithValue(ctx, tools.InitiatorKey, "user")
+
+	result, err := tool.Run(ctx, call)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return result, fmt.Errorf("interrupted")
+	}
+	return result, err
+}
+
+// writeJSON prints v as indented JSON
+func writeJSON(stdout, stderr io.Writer, v any) int {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		fmt.Fprintf(stderr, "Failed to encode JSON: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,306 @@
+// Package agents runs sub-agents: scoped tasks handed to one model of the
+// team, such as "summarize internal/session" on the small model or "design
+// the refactor of the tool executor" on the large one.
+//
+// A sub-agent works in a context window of its own. It sees the task and
+// the files the task names, never the conversation that spawned it, so
+// the main chat only pays for its answer. Tasks go through the LLM queue
+// in their model's lane, so tasks for different models run side by side.
+package agents
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/llm/queue"
+	"github.com/billie-coop/loco/internal/mention"
+)
+
+// Size picks the team model a task runs on
+type Size string
+
+const (
+	SizeSmall  Size = "small"
+	SizeMedium Size = "medium"
+	SizeLarge  Size = "large"
+)
+
+// contextChars caps the file content given to a sub-agent of each size,
+// leaving room in the window for the task and the answer
+var contextChars = map[Size]int{
+	SizeSmall:  12000,
+	SizeMedium: 24000,
+	SizeLarge:  48000,
+}
+
+// maxDirFiles caps the files read from one directory
+const maxDirFiles = 40
+
+// Task is one job for a sub-agent
+type Task struct {
+	Goal  string   `json:"task"`
+	Size  Size     `json:"size,omitempty"`  // Empty routes by the goal's wording
+	Paths []string `json:"paths,omitempty"` // Files or directories to read, relative to the project
+}
+
+// Result is what a sub-agent came back with
+type Result struct {
+	Goal    string        `json:"task"`
+	Size    Size          `json:"size"`
+	Model   string        `json:"model,omitempty"`
+	Output  string        `json:"output,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Files   []string      `json:"files,omitempty"` // The files it was given
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// routeWords are the word stems that send a task to a size. The first
+// word of the goal with one of them decides.
+var routeWords = map[Size][]string{
+	SizeSmall: {"summar", "list", "find", "extract", "classif", "count", "outline", "describe"},
+	SizeLarge: {"design", "architect", "refactor", "plan", "review", "debug", "migrat", "optimi", "tradeoff", "trade-off", "secur", "rewrite"},
+}
+
+// Route picks the size for a goal from its wording: lookups and summaries
+// go to the small model, design and review to the large one, anything
+// else to the medium one
+func Route(goal string) Size {
+	for _, word := range strings.Fields(strings.ToLower(goal)) {
+		for _, size := range []Size{SizeSmall, SizeLarge} {
+			for _, prefix := range routeWords[size] {
+				if strings.HasPrefix(word, prefix) {
+					return size
+				}
+			}
+		}
+	}
+	return SizeMedium
+}
+
+// ParseSize reads a size name, also accepting the team's S/M/L letters
+func ParseSize(name string) (Size, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "small", "s", "xs":
+		return SizeSmall, true
+	case "medium", "m":
+		return SizeMedium, true
+	case "large", "l", "xl":
+		return SizeLarge, true
+	}
+	return "", false
+}
+
+// Runner runs sub-agents on the model team
+type Runner struct {
+	workingDir string
+
+	mu     sync.RWMutex
+	team   *llm.TeamClients
+	client llm.Client     // Runs every size when there is no team
+	queue  *queue.Manager // nil runs tasks directly
+}
+
+// NewRunner creates a runner for the project at workingDir
+func NewRunner(workingDir string) *Runner {
+	return &Runner{workingDir: workingDir}
+}
+
+// SetClient sets the model used when there is no team, and the queue
+// tasks wait in
+func (r *Runner) SetClient(client llm.Client, q *queue.Manager) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.client = client
+	r.queue = q
+}
+
+// SetTeam sets the models tasks are routed to
+func (r *Runner) SetTeam(team *llm.TeamClients) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.team = team
+}
+
+// RunAll runs the tasks side by side and returns their results in order
+func (r *Runner) RunAll(ctx context.Context, tasks []Task) []Result {
+	results := make([]Result, len(tasks))
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.Run(ctx, task)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// Run runs one task. Failures are reported in the result.
+func (r *Runner) Run(ctx context.Context, task Task) (result Result) {
+	start := time.Now()
+	size := task.Size
+	if size == "" {
+		size = Route(task.Goal)
+	}
+	result = Result{Goal: strings.TrimSpace(task.Goal), Size: size}
+	defer func() { result.Elapsed = time.Since(start).Round(time.Millisecond) }()
+
+	client, q := r.clientFor(size)
+	if client == nil {
+		result.Error = "no model connected"
+		return result
+	}
+	if lm, ok := client.(*llm.LMStudioClient); ok {
+		result.Model = lm.CurrentModel()
+	}
+
+	paths := append(slices.Clone(task.Paths), mention.Paths(task.Goal)...)
+	files, given := r.gather(paths, contextChars[size])
+	result.Files = files
+	messages := []llm.Message{
+		{Role: "system", Content: "You are a sub-agent of Loco, working on one scoped task for the main assistant. " +
+			"You see only the task and the files given, not the conversation. " +
+			"Answer the task directly and completely; your answer is handed back to the main assistant."},
+		{Role: "user", Content: given + "Task: " + result.Goal},
+	}
+
+	var output string
+	var err error
+	if q == nil {
+		output, err = client.Complete(ctx, messages)
+	} else {
+		var queued string
+		var queuedErr error
+		done := make(chan struct{})
+		q.Submit(ctx, func(ctx context.Context) error {
+			defer close(done)
+			queued, queuedErr = client.Complete(ctx, messages)
+			return queuedErr
+		},
+			queue.WithPriority(5),
+			queue.WithType("agent_"+string(size)),
+			queue.WithModel(lane(client)),
+			queue.WithTimeout(0), // A task takes as long as it takes
+		)
+		select {
+		case <-done:
+			output, err = queued, queuedErr
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Output = strings.TrimSpace(output)
+	return result
+}
+
+// clientFor returns the client and queue for a size
+func (r *Runner) clientFor(size Size) (llm.Client, *queue.Manager) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.team == nil {
+		return r.client, r.queue
+	}
+	var client llm.Client
+	switch size {
+	case SizeSmall:
+		client = r.team.Small
+	case SizeLarge:
+		client = r.team.Large
+	default:
+		client = r.team.Medium
+	}
+	if client == nil {
+		client = r.client
+	}
+	return client, r.queue
+}
+
+// lane picks the queue lane for a client: its model when LM Studio has it
+// loaded, otherwise the shared unloaded lane, as analysis tiers do
+func lane(client llm.Client) string {
+	lm, ok := client.(*llm.LMStudioClient)
+	if !ok || lm.CurrentModel() == "" {
+		return analysis.UnloadedLane
+	}
+	loaded, err := lm.LoadedModels()
+	if err != nil || !slices.Contains(loaded, lm.CurrentModel()) {
+		return analysis.UnloadedLane
+	}
+	return lm.CurrentModel()
+}
+
+// gather reads the named files, and the files directly in named
+// directories, into a context block of at most maxChars. Paths outside
+// the project and binary files are skipped. Returns the files included.
+func (r *Runner) gather(paths []string, maxChars int) ([]string, string) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		local := filepath.Clean(filepath.FromSlash(path))
+		if !filepath.IsLocal(local) && local != "." {
+			continue
+		}
+		full := filepath.Join(r.workingDir, local)
+		info, err := os.Stat(full)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			if !seen[local] {
+				seen[local] = true
+				files = append(files, local)
+			}
+			continue
+		}
+		entries, err := os.ReadDir(full)
+		if err != nil {
+			continue
+		}
+		added := 0
+		for _, entry := range entries {
+			name := filepath.Join(local, entry.Name())
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || seen[name] || added == maxDirFiles {
+				continue
+			}
+			seen[name] = true
+			files = append(files, name)
+			added++
+		}
+	}
+
+	var b strings.Builder
+	var included []string
+	omitted := 0
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(r.workingDir, file))
+		if err != nil || bytes.IndexByte(data, 0) >= 0 {
+			continue
+		}
+		block := fmt.Sprintf("### %s\n```\n%s\n```\n\n", filepath.ToSlash(file), strings.TrimRight(string(data), "\n"))
+		if b.Len()+len(block) > maxChars {
+			omitted++
+			continue
+		}
+		b.WriteString(block)
+		included = append(included, filepath.ToSlash(file))
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "(%d more file(s) left out for space)\n\n", omitted)
+	}
+	return included, b.String()
+}
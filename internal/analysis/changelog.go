@@ -0,0 +1,225 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/files"
+)
+
+// changelogMD is the history summary at the knowledge root
+const changelogMD = "changelog.md"
+
+// Limits on what changelog.md shows of each commit
+const (
+	maxChangelogWhy   = 300 // Characters of the message body kept as the reason
+	maxChangelogFiles = 5   // Files listed by name
+	maxChangelogAreas = 8   // Directories in the most-changed summary
+)
+
+// commit is one entry of the project's history
+type commit struct {
+	Hash    string
+	Author  string
+	Date    time.Time
+	Subject string
+	Body    string
+	Files   []string
+}
+
+// RefreshChangelog writes knowledge/changelog.md from the last
+// analysis.changelog_commits commits touching the project, newest first,
+// with those since the last model analysis called out, so the chat knows
+// what changed recently and why. It returns whether the file changed;
+// outside a git repository it writes nothing and returns the error.
+func RefreshChangelog(ctx context.Context, projectPath string) (bool, error) {
+	limit := 50
+	cfgMgr := config.NewManager(configRoot(projectPath))
+	_ = cfgMgr.Load()
+	if cfg := cfgMgr.Get(); cfg != nil && cfg.Analysis.ChangelogCommits > 0 {
+		limit = cfg.Analysis.ChangelogCommits
+	}
+
+	commits, err := recentCommits(ctx, projectPath, limit)
+	if err != nil {
+		return false, err
+	}
+	tier, since := lastModelAnalysis(projectPath)
+	content := changelogMarkdown(commits, tier, since)
+
+	path := filepath.Join(knowledgeDir(projectPath), changelogMD)
+	if existing, err := os.ReadFile(path); err == nil && string(existing) == content {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, []byte(content), 0644)
+}
+
+// recentCommits reads the last limit non-merge commits touching the
+// project directory, with the files each changed relative to it
+func recentCommits(ctx context.Context, projectPath string, limit int) ([]commit, error) {
+	out, err := files.Git(ctx, projectPath, "log", "--no-merges", "--relative", "-n", strconv.Itoa(limit),
+		"--format=%x1e%H%x1f%an%x1f%aI%x1f%s%x1f%b%x1f", "--name-only", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	var commits []commit
+	for _, record := range bytes.Split(out, []byte{0x1e}) {
+		fields := strings.Split(string(record), "\x1f")
+		if len(fields) < 6 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		c := commit{Hash: fields[0], Author: fields[1], Date: date.UTC(), Subject: fields[3], Body: strings.TrimSpace(fields[4])}
+		for _, file := range strings.Split(fields[5], "\n") {
+			if file = strings.TrimSpace(file); file != "" {
+				c.Files = append(c.Files, file)
+			}
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+// lastModelAnalysis returns the most recent tier run with a model and when
+// it ran, or a zero time when none has
+func lastModelAnalysis(projectPath string) (Tier, time.Time) {
+	var latest Tier
+	var at time.Time
+	for _, tier := range []Tier{TierQuick, TierDetailed, TierDeep, TierFull} {
+		data, err := os.ReadFile(filepath.Join(knowledgeDir(projectPath), string(tier), "analysis.json"))
+		if err != nil {
+			continue
+		}
+		var cached struct {
+			Generated time.Time `json:"generated"`
+		}
+		if json.Unmarshal(data, &cached) == nil && cached.Generated.After(at) {
+			latest, at = tier, cached.Generated
+		}
+	}
+	return latest, at
+}
+
+// changelogMarkdown renders the commits as changelog.md: the directories
+// changed most, then each commit by day with the first paragraph of its
+// message as the reason
+func changelogMarkdown(commits []commit, tier Tier, since time.Time) string {
+	var b strings.Builder
+	b.WriteString("# Recent changes\n\n")
+	if len(commits) == 0 {
+		b.WriteString("No commits yet.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "The last %d commits, newest first, up to `%s` (%s).\n", len(commits), commits[0].Hash[:min(7, len(commits[0].Hash))], commits[0].Date.Format("2006-01-02"))
+
+	counts := map[string]int{}
+	for _, c := range commits {
+		touched := map[string]bool{}
+		for _, file := range c.Files {
+			touched[topLevelDir(file)] = true
+		}
+		for dir := range touched {
+			counts[dir]++
+		}
+	}
+	areas := make([]string, 0, len(counts))
+	for dir := range counts {
+		areas = append(areas, dir)
+	}
+	sort.Slice(areas, func(i, j int) bool {
+		if counts[areas[i]] != counts[areas[j]] {
+			return counts[areas[i]] > counts[areas[j]]
+		}
+		return areas[i] < areas[j]
+	})
+	if len(areas) > 0 {
+		b.WriteString("\n## Most changed\n\n")
+		for _, dir := range areas[:min(maxChangelogAreas, len(areas))] {
+			name := dir + "/"
+			if dir == "." {
+				name = "(root files)"
+			}
+			fmt.Fprintf(&b, "- `%s`: %d commits\n", name, counts[dir])
+		}
+	}
+
+	if since.IsZero() {
+		writeChangelogCommits(&b, "Commits", commits)
+		return b.String()
+	}
+	var recent, earlier []commit
+	for _, c := range commits {
+		if c.Date.After(since) {
+			recent = append(recent, c)
+		} else {
+			earlier = append(earlier, c)
+		}
+	}
+	last := fmt.Sprintf("the last analysis (%s, %s)", tier, since.Format("2006-01-02 15:04"))
+	if len(recent) == 0 {
+		fmt.Fprintf(&b, "\nNo commits since %s.\n", last)
+	}
+	writeChangelogCommits(&b, "Since "+last, recent)
+	writeChangelogCommits(&b, "Earlier", earlier)
+	return b.String()
+}
+
+// writeChangelogCommits writes a section of commits grouped by day
+func writeChangelogCommits(b *strings.Builder, title string, commits []commit) {
+	if len(commits) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n## %s\n", title)
+	day := ""
+	for _, c := range commits {
+		if d := c.Date.Format("2006-01-02"); d != day {
+			day = d
+			fmt.Fprintf(b, "\n### %s\n\n", day)
+		}
+		fmt.Fprintf(b, "- `%s` %s (%s)\n", c.Hash[:min(7, len(c.Hash))], c.Subject, c.Author)
+		if why := changelogWhy(c.Body); why != "" {
+			fmt.Fprintf(b, "  - Why: %s\n", why)
+		}
+		if len(c.Files) > 0 {
+			listed := c.Files[:min(maxChangelogFiles, len(c.Files))]
+			fmt.Fprintf(b, "  - Files: %s", strings.Join(listed, ", "))
+			if more := len(c.Files) - len(listed); more > 0 {
+				fmt.Fprintf(b, " and %d more", more)
+			}
+			b.WriteString("\n")
+		}
+	}
+}
+
+// changelogWhy is the first paragraph of a commit body on one line, without
+// trailers like Signed-off-by
+func changelogWhy(body string) string {
+	paragraph, _, _ := strings.Cut(body, "\n\n")
+	var lines []string
+	for _, line := range strings.Split(paragraph, "\n") {
+		line = strings.TrimSpace(line)
+		if key, _, ok := strings.Cut(line, ": "); ok && !strings.Contains(key, " ") && strings.Contains(key, "-") {
+			continue // A trailer
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	why := strings.Join(lines, " ")
+	if len(why) > maxChangelogWhy {
+		why = strings.ToValidUTF8(why[:maxChangelogWhy], "") + "..."
+	}
+	return why
+}
@@ -0,0 +1,63 @@
+package analysis
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRefreshChangelog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	git := func(date string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Bea", "-c", "user.email=bea@example.com"}, args...)...)
+		cmd.Dir = root
+		cmd.Env = append(cmd.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("", "init", "-q")
+	writeFiles(t, root, map[string]string{"main.go": "package main\n", "internal/db/db.go": "package db\n"})
+	git("2024-03-01T12:00:00Z", "add", ".")
+	git("2024-03-01T12:00:00Z", "commit", "-q", "-m", "Add the store\n\nThe cache needed somewhere to live.\n\nSigned-off-by: Bea <bea@example.com>")
+	writeFiles(t, root, map[string]string{
+		".loco/knowledge/quick/analysis.json": `{"tier": "quick", "generated": "2024-03-02T09:00:00Z"}`,
+		"internal/db/db.go":                   "package db\n\n// Open opens it\nfunc Open() {}\n",
+	})
+	git("2024-03-03T12:00:00Z", "commit", "-q", "-am", "Open the store")
+
+	written, err := RefreshChangelog(context.Background(), root)
+	if err != nil || !written {
+		t.Fatalf("first refresh: written %v, err %v", written, err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, ".loco", "knowledge", "changelog.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := string(data)
+	for _, want := range []string{
+		"The last 2 commits",
+		"- `internal/`: 2 commits",
+		"## Since the last analysis (quick, 2024-03-02 09:00)\n\n### 2024-03-03\n\n- `",
+		" Open the store (Bea)\n  - Files: internal/db/db.go\n",
+		"## Earlier\n",
+		"  - Why: The cache needed somewhere to live.\n  - Files: internal/db/db.go, main.go\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("changelog.md lacks %q:\n%s", want, md)
+		}
+	}
+
+	if written, _ := RefreshChangelog(context.Background(), root); written {
+		t.Error("refresh rewrote the changelog with nothing changed")
+	}
+	if _, err := RefreshChangelog(context.Background(), t.TempDir()); err == nil {
+		t.Error("no error outside a git repository")
+	}
+}
@@ -0,0 +1,93 @@
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileCheckpoint holds the per-file summaries of a run that has not
+// finished yet. It is saved after every file and removed when the run
+// completes, so an interrupted run resumes from the last completed file.
+type fileCheckpoint struct {
+	Started time.Time                      `json:"started"`
+	Files   map[string]checkpointedSummary `json:"files"`
+}
+
+// checkpointedSummary is one file's summary, valid while the content it was
+// made from is unchanged
+type checkpointedSummary struct {
+	ContentHash string `json:"content_hash"`
+	Purpose     string `json:"purpose"`
+	Importance  int    `json:"importance"`
+	Summary     string `json:"summary"`
+}
+
+func (s *service) getCheckpointPath(projectPath string, tier Tier) string {
+	return filepath.Join(knowledgeDir(projectPath), string(tier), "checkpoint.json")
+}
+
+// loadCheckpoint returns the checkpoint left by an interrupted run, or an
+// empty one
+func (s *service) loadCheckpoint(projectPath string, tier Tier) *fileCheckpoint {
+	checkpoint := &fileCheckpoint{}
+	if data, err := os.ReadFile(s.getCheckpointPath(projectPath, tier)); err == nil {
+		_ = json.Unmarshal(data, checkpoint)
+	}
+	if checkpoint.Files == nil {
+		checkpoint.Started = time.Now()
+		checkpoint.Files = make(map[string]checkpointedSummary)
+	}
+	return checkpoint
+}
+
+// saveCheckpoint writes the checkpoint through a temporary file so an
+// interruption mid-write leaves the previous one intact
+func (s *service) saveCheckpoint(projectPath string, tier Tier, checkpoint *fileCheckpoint) error {
+	path := s.getCheckpointPath(projectPath, tier)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// clearCheckpoint removes the checkpoint once a run has completed
+func (s *service) clearCheckpoint(projectPath string, tier Tier) {
+	_ = os.Remove(s.getCheckpointPath(projectPath, tier))
+}
+
+// lookup returns the checkpointed summary for a file if its content has not
+// changed since
+func (c *fileCheckpoint) lookup(path, content string) (checkpointedSummary, bool) {
+	summary, ok := c.Files[path]
+	if !ok || summary.ContentHash != hashContent(content) {
+		return checkpointedSummary{}, false
+	}
+	return summary, true
+}
+
+// record adds a file's summary to the checkpoint
+func (c *fileCheckpoint) record(path, content string, summary FileSummary) {
+	c.Files[path] = checkpointedSummary{
+		ContentHash: hashContent(content),
+		Purpose:     summary.Purpose,
+		Importance:  summary.Importance,
+		Summary:     summary.Summary,
+	}
+}
+
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,144 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConsensusMetrics measures how far the quick tier's workers agreed on the
+// important files
+type ConsensusMetrics struct {
+	Workers     int     `json:"workers"`       // Workers whose rankings were merged
+	TopKOverlap float64 `json:"top_k_overlap"` // Mean pairwise Jaccard overlap of the workers' top-K, 0-1
+	VoteEntropy float64 `json:"vote_entropy"`  // 0 when every worker picked the same files, 1 when no two did
+	Agreement   float64 `json:"agreement"`     // Overlap and concentrated votes combined, 0-1
+}
+
+// Thresholds on Agreement for the confidence levels
+const (
+	highAgreement   = 0.6
+	mediumAgreement = 0.3
+)
+
+// Level names the confidence the agreement gives: "high", "medium" or "low"
+func (m ConsensusMetrics) Level() string {
+	switch {
+	case m.Agreement >= highAgreement:
+		return "high"
+	case m.Agreement >= mediumAgreement:
+		return "medium"
+	}
+	return "low"
+}
+
+// String describes the metrics in one line
+func (m ConsensusMetrics) String() string {
+	return fmt.Sprintf("%s (agreement %.2f: top-K overlap %.2f, vote entropy %.2f across %d workers)",
+		m.Level(), m.Agreement, m.TopKOverlap, m.VoteEntropy, m.Workers)
+}
+
+// consensusMetrics compares the workers' top-K lists. Overlap is the mean
+// Jaccard index of every pair of lists. Entropy is that of the votes spread
+// over the files, scaled so K files each voted for by all W workers is 0 and
+// W*K files with one vote each is 1. It returns nil for fewer than two
+// workers, where there is nothing to agree on.
+func consensusMetrics(lists [][]string) *ConsensusMetrics {
+	var workers [][]string
+	for _, list := range lists {
+		if len(list) > 0 {
+			workers = append(workers, list)
+		}
+	}
+	if len(workers) < 2 {
+		return nil
+	}
+
+	sets := make([]map[string]bool, len(workers))
+	votes := map[string]int{}
+	totalVotes := 0
+	for i, list := range workers {
+		sets[i] = map[string]bool{}
+		for _, path := range list {
+			if !sets[i][path] {
+				sets[i][path] = true
+				votes[path]++
+				totalVotes++
+			}
+		}
+	}
+
+	var overlap float64
+	pairs := 0
+	for i := range sets {
+		for j := i + 1; j < len(sets); j++ {
+			shared := 0
+			for path := range sets[i] {
+				if sets[j][path] {
+					shared++
+				}
+			}
+			overlap += float64(shared) / float64(len(sets[i])+len(sets[j])-shared)
+			pairs++
+		}
+	}
+	overlap /= float64(pairs)
+
+	var entropy float64
+	for _, n := range votes {
+		p := float64(n) / float64(totalVotes)
+		entropy -= p * math.Log(p)
+	}
+	meanK := float64(totalVotes) / float64(len(workers))
+	spread := (entropy - math.Log(meanK)) / math.Log(float64(len(workers)))
+	spread = math.Max(0, math.Min(1, spread))
+
+	return &ConsensusMetrics{
+		Workers:     len(workers),
+		TopKOverlap: overlap,
+		VoteEntropy: spread,
+		Agreement:   (overlap + 1 - spread) / 2,
+	}
+}
+
+// withConsensusFrontmatter records the workers' agreement in a knowledge
+// document's frontmatter, keeping any other keys
+func withConsensusFrontmatter(doc string, m *ConsensusMetrics) string {
+	if m == nil {
+		return doc
+	}
+	front, body := splitFrontmatter(doc)
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	for _, line := range front {
+		if key, _, _ := strings.Cut(line, ":"); strings.HasPrefix(strings.TrimSpace(key), "consensus_") {
+			continue
+		}
+		sb.WriteString(line + "\n")
+	}
+	fmt.Fprintf(&sb, "consensus_confidence: %s\n", m.Level())
+	fmt.Fprintf(&sb, "consensus_agreement: %.2f\n", m.Agreement)
+	fmt.Fprintf(&sb, "consensus_top_k_overlap: %.2f\n", m.TopKOverlap)
+	fmt.Fprintf(&sb, "consensus_vote_entropy: %.2f\n", m.VoteEntropy)
+	fmt.Fprintf(&sb, "consensus_workers: %d\n", m.Workers)
+	sb.WriteString("---\n\n")
+	sb.WriteString(body)
+	return sb.String()
+}
+
+// LoadConsensusMetrics reads the workers' agreement from the last quick
+// analysis, or nil when there is none or it ran without ranking workers
+func LoadConsensusMetrics(projectPath string) *ConsensusMetrics {
+	data, err := os.ReadFile(filepath.Join(knowledgeDir(projectPath), "quick", "adjudicated_summary.json"))
+	if err != nil {
+		return nil
+	}
+	var consensus ConsensusResult
+	if err := json.Unmarshal(data, &consensus); err != nil {
+		return nil
+	}
+	return consensus.Metrics
+}
@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestConsensusMetrics(t *testing.T) {
+	tests := []struct {
+		name        string
+		lists       [][]string
+		overlap     float64
+		entropy     float64
+		wantLevel   string
+		wantWorkers int
+	}{
+		{
+			name:        "same picks",
+			lists:       [][]string{{"a.go", "b.go"}, {"b.go", "a.go"}, {"a.go", "b.go"}},
+			overlap:     1,
+			entropy:     0,
+			wantLevel:   "high",
+			wantWorkers: 3,
+		},
+		{
+			name:        "no shared picks",
+			lists:       [][]string{{"a.go", "b.go"}, {"c.go", "d.go"}, {"e.go", "f.go"}},
+			overlap:     0,
+			entropy:     1,
+			wantLevel:   "low",
+			wantWorkers: 3,
+		},
+		{
+			name:        "failed workers are left out",
+			lists:       [][]string{{"a.go"}, nil, {"a.go"}},
+			overlap:     1,
+			entropy:     0,
+			wantLevel:   "high",
+			wantWorkers: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := consensusMetrics(tt.lists)
+			if m == nil {
+				t.Fatal("consensusMetrics() = nil")
+			}
+			if math.Abs(m.TopKOverlap-tt.overlap) > 1e-9 || math.Abs(m.VoteEntropy-tt.entropy) > 1e-9 {
+				t.Errorf("overlap %.2f, entropy %.2f; want %.2f, %.2f", m.TopKOverlap, m.VoteEntropy, tt.overlap, tt.entropy)
+			}
+			if m.Level() != tt.wantLevel || m.Workers != tt.wantWorkers {
+				t.Errorf("level %s from %d workers, want %s from %d", m.Level(), m.Workers, tt.wantLevel, tt.wantWorkers)
+			}
+		})
+	}
+
+	if m := consensusMetrics([][]string{{"a.go"}}); m != nil {
+		t.Errorf("consensusMetrics(one worker) = %+v, want nil", m)
+	}
+}
+
+func TestConsensusFrontmatterKeepsVerifiedSections(t *testing.T) {
+	doc := "---\nhuman_verified:\n  - Build\nconsensus_confidence: low\n---\n\n# Summary\n"
+	got := withConsensusFrontmatter(doc, &ConsensusMetrics{Workers: 2, TopKOverlap: 1, Agreement: 1})
+	if strings.Count(got, "consensus_confidence:") != 1 || !strings.Contains(got, "consensus_confidence: high\n") {
+		t.Errorf("confidence not replaced:\n%s", got)
+	}
+	if titles := verifiedTitles(strings.Split(got, "\n")); len(titles) != 1 || titles[0] != "Build" {
+		t.Errorf("verified titles = %v, want [Build]", titles)
+	}
+	if !strings.HasSuffix(got, "# Summary\n") {
+		t.Errorf("body lost:\n%s", got)
+	}
+}
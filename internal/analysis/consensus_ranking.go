@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/crash"
 	"github.com/billie-coop/loco/internal/llm"
 )
 
@@ -25,7 +26,7 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 	start := time.Now()
 
 	// Load quick config
-	cfgMgr := config.NewManager(projectPath)
+	cfgMgr := config.NewManager(configRoot(projectPath))
 	_ = cfgMgr.Load()
 	cfg := cfgMgr.Get()
 	qc := cfg.Analysis.Quick
@@ -33,26 +34,27 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 	// Per-tier debug gating (analysis.quick.debug or LOCO_DEBUG)
 	shouldDebug := (cfg != nil && qc.Debug) || os.Getenv("LOCO_DEBUG") == "true"
 
-	// Prepare debug dir only if enabled
-	var debugDir string
-	if shouldDebug {
-		ts := time.Now().Format("20060102_150405")
-		debugDir = filepath.Join(projectPath, s.cachePath, "debug", "quick", ts)
-		_ = os.MkdirAll(debugDir, 0o755)
-	}
+	// Prepare debug dir only if enabled and logging.content allows it
+	dump := newDebugDump(projectPath, "quick", cfg, shouldDebug)
+	shouldDebug = dump != nil
 
 	// Prefilter file list for ranking
 	filtered := prefilterForRanking(files)
-	ReportProgress(ctx, Progress{Phase: string(TierQuick), TotalFiles: len(filtered), CompletedFiles: 0, CurrentFile: "prefiltered files"})
+	s.reportProgress(ctx, Progress{Phase: string(TierQuick), TotalFiles: len(filtered), CompletedFiles: 0, CurrentFile: "prefiltered files"})
 
 	// Compute structure hints once from the full filtered set
 	dirCounts := topLevelDirCounts(filtered)
 	typeCounts := fileTypeCounts(filtered)
 	structureSummary := buildStructureSummary(dirCounts, typeCounts)
 	if shouldDebug {
-		_ = os.WriteFile(filepath.Join(debugDir, "structure_hints.txt"), []byte(structureSummary), 0o644)
+		dump.write("structure_hints.txt", structureSummary)
 	}
 
+	// Score files by the import graph as well, so ranking does not rest on
+	// path names alone. Only the import headers are parsed.
+	_, goFiles := parseGoFiles(projectPath, files, goImportsMode)
+	signals := computeStructuralSignals(s.updateDependencyGraph(projectPath, files, goFiles))
+
 	// Parameters
 	workerCount := qc.Workers
 	if workerCount <= 0 {
@@ -93,6 +95,11 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 	adjudicatorCtxSize := workerCtxSize * 2
 	adjudicatorTimeoutMs := workerTimeoutMs
 
+	strategy, err := mergeStrategyFor(qc.MergeStrategy)
+	if err != nil {
+		return nil, err
+	}
+
 	// Natural language worker mode flags
 	nlMode := qc.NaturalLanguageWorkers
 	nlWordLimit := qc.WorkerSummaryWordLimit
@@ -100,33 +107,14 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 		nlWordLimit = 200
 	}
 
-	// Create worker slices
-	fileChunks := make([][]string, workerCount)
-	if len(filtered) > maxPathsPerCall {
-		// Partition evenly to respect cap
-		chunkSize := (len(filtered) + workerCount - 1) / workerCount
-		for i := 0; i < workerCount; i++ {
-			startIdx := i * chunkSize
-			endIdx := min((i+1)*chunkSize, len(filtered))
-			if startIdx >= len(filtered) {
-				fileChunks[i] = []string{}
-				continue
-			}
-			paths := filtered[startIdx:endIdx]
-			if len(paths) > maxPathsPerCall {
-				paths = paths[:maxPathsPerCall]
-			}
-			fileChunks[i] = paths
-		}
-	} else {
-		// All workers see the same capped list
-		paths := filtered
-		if len(paths) > maxPathsPerCall {
-			paths = paths[:maxPathsPerCall]
-		}
-		for i := 0; i < workerCount; i++ {
-			fileChunks[i] = paths
+	// Give each worker its share of the files
+	fileChunks := shardFiles(filtered, workerCount, maxPathsPerCall, qc.Sharding)
+	if shouldDebug {
+		var sb strings.Builder
+		for i, chunk := range fileChunks {
+			fmt.Fprintf(&sb, "worker %d: %d files\n", i, len(chunk))
 		}
+		dump.write("worker_shards.txt", sb.String())
 	}
 
 	// Focuses
@@ -145,34 +133,44 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 	outCh := make(chan workerOut, workerCount)
 	var wg sync.WaitGroup
 
-	// Concurrency cap
-	maxConc := qc.WorkerConcurrency
-	if maxConc <= 0 {
-		maxConc = 2
+	// Concurrency cap: the shared adaptive limit if there is one, otherwise
+	// worker_concurrency for this run
+	limit := s.workerLimit
+	if limit == nil {
+		maxConc := qc.WorkerConcurrency
+		if maxConc <= 0 {
+			maxConc = 2
+		}
+		limit = llm.NewAdaptiveLimit(maxConc, maxConc)
 	}
-	sem := make(chan struct{}, maxConc)
 
-	// Precompute tracked set for post-filtering
-	tracked := s.getGitTrackedSet(projectPath)
+	// Precompute the project's files for post-filtering
+	tracked := projectFileSet(projectPath)
 
 	// Progress tracking per worker
 	var doneMu sync.Mutex
 	workersDone := 0
 	workerErrors := make([]string, 0)
 
+	// Deterministic runs take the workers one at a time, in order
+	inOrder := llm.IsDeterministic(ctx)
 	for i := 0; i < workerCount; i++ {
 		wg.Add(1)
-		go func(workerIndex int) {
+		work := func(workerIndex int) {
+			defer crash.Recover("quick analysis worker")
 			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
+			if err := limit.Acquire(ctx); err != nil {
+				outCh <- workerOut{idx: workerIndex, err: err}
+				return
+			}
+			defer limit.Release()
 
 			focus := focuses[workerIndex%len(focuses)]
 			paths := fileChunks[workerIndex]
-			list, summary, err := s.runRankingWorkerWithLimitAndOptions(ctx, focus, structureSummary, paths, perWorkerTop, workerCtxSize, workerMaxTokens, workerTimeoutMs, shouldDebug, debugDir, workerIndex, 1, nlMode, nlWordLimit)
+			list, summary, err := s.runRankingWorkerWithLimitAndOptions(ctx, projectPath, focus, structureSummary, paths, perWorkerTop, workerCtxSize, workerMaxTokens, workerTimeoutMs, dump, workerIndex, 1, nlMode, nlWordLimit)
 			if err != nil && qc.WorkerRetry > 0 {
 				// Retry once
-				list, summary, err = s.runRankingWorkerWithLimitAndOptions(ctx, focus, structureSummary, paths, perWorkerTop, workerCtxSize, workerMaxTokens, workerTimeoutMs, shouldDebug, debugDir, workerIndex, 2, nlMode, nlWordLimit)
+				list, summary, err = s.runRankingWorkerWithLimitAndOptions(ctx, projectPath, focus, structureSummary, paths, perWorkerTop, workerCtxSize, workerMaxTokens, workerTimeoutMs, dump, workerIndex, 2, nlMode, nlWordLimit)
 			}
 			// Post-filter only in ranking mode
 			if err == nil && !nlMode {
@@ -186,7 +184,7 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 			}
 			if err == nil && shouldDebug && !nlMode {
 				b, _ := json.MarshalIndent(list, "", "  ")
-				_ = os.WriteFile(filepath.Join(debugDir, fmt.Sprintf("worker_%d_rankings.json", workerIndex)), b, 0o644)
+				dump.write(fmt.Sprintf("worker_%d_rankings.json", workerIndex), string(b))
 			}
 			if err != nil {
 				doneMu.Lock()
@@ -198,10 +196,15 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 			workersDone++
 			d := workersDone
 			doneMu.Unlock()
-			ReportProgress(ctx, Progress{Phase: string(TierQuick), TotalFiles: workerCount, CompletedFiles: d, CurrentFile: fmt.Sprintf("worker %d done", workerIndex)})
+			s.reportProgress(ctx, Progress{Phase: string(TierQuick), TotalFiles: workerCount, CompletedFiles: d, CurrentFile: fmt.Sprintf("worker %d done", workerIndex)})
 
 			outCh <- workerOut{idx: workerIndex, list: list, err: err, summary: summary}
-		}(i)
+		}
+		if inOrder {
+			work(i)
+		} else {
+			go work(i)
+		}
 	}
 
 	go func() {
@@ -241,7 +244,7 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 	// Strict fail-fast: any failed worker (after retry) aborts
 	if qc.StrictFail && failures > 0 {
 		if shouldDebug && len(workerErrors) > 0 {
-			_ = os.WriteFile(filepath.Join(debugDir, "worker_errors.log"), []byte(strings.Join(workerErrors, "\n")), 0o644)
+			dump.write("worker_errors.log", strings.Join(workerErrors, "\n"))
 		}
 		return nil, fmt.Errorf("quick ranking failed: %d/%d workers failed", failures, workerCount)
 	}
@@ -249,54 +252,55 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 	if nlMode {
 		// Save adjudicator input (summaries)
 		if shouldDebug {
-			_ = os.WriteFile(filepath.Join(debugDir, "adjudicator_input.txt"), []byte(strings.Join(perSummary, "\n\n---\n\n")+"\n\n"+structureSummary), 0o644)
+			dump.write("adjudicator_input.txt", strings.Join(perSummary, "\n\n---\n\n")+"\n\n"+structureSummary)
 		}
 		// Adjudicate from summaries (markdown-only)
-		consensus, err := s.adjudicateSummariesWithOptions(ctx, perSummary, structureSummary, adjudicatorCtxSize, adjudicatorMaxTokens, adjudicatorTimeoutMs, shouldDebug, debugDir)
+		consensus, err := s.adjudicateSummariesWithOptions(ctx, projectPath, perSummary, structureSummary, adjudicatorCtxSize, adjudicatorMaxTokens, adjudicatorTimeoutMs, dump)
 		if err != nil {
 			return nil, fmt.Errorf("adjudicator failed after retry: %v", err)
 		}
 		// Finalize metadata
+		applyStructuralSignals(consensus.Rankings, signals)
 		consensus.TotalFiles = len(files)
 		consensus.TopDirs = dirCounts
 		consensus.FileTypes = typeCounts
 		consensus.ConsensusTime = time.Since(start)
 		if shouldDebug {
-			_ = os.WriteFile(filepath.Join(debugDir, "adjudicated_ranking.json"), []byte(""), 0o644) // placeholder to keep downstream tools calm if inspected
+			dump.write("adjudicated_ranking.json", "") // placeholder to keep downstream tools calm if inspected
 		}
 		return consensus, nil
 	}
 
-	// Merge
+	// Merge with the configured strategy, each worker's list best first
+	workerRankings := make([]WorkerRanking, len(perWorker))
+	for i, wl := range perWorker {
+		sort.SliceStable(wl, func(i, j int) bool { return wl[i].Importance > wl[j].Importance })
+		workerRankings[i] = WorkerRanking{Focus: focuses[i%len(focuses)], Rankings: wl}
+	}
 	crowdMap := map[string]*FileRanking{}
-	for _, wl := range perWorker {
-		if len(wl) == 0 {
-			continue
+	for _, fr := range mergeRankings(workerRankings, perWorkerTop, strategy) {
+		crowdMap[fr.Path] = &fr
+	}
+
+	// Measure agreement on each worker's top-K, as merged
+	topLists := make([][]string, len(perWorker))
+	for i, wl := range perWorker {
+		for _, fr := range wl[:min(perWorkerTop, len(wl))] {
+			topLists[i] = append(topLists[i], fr.Path)
 		}
-		// Sort by importance desc
-		sort.SliceStable(wl, func(i, j int) bool { return wl[i].Importance > wl[j].Importance })
-		take := min(perWorkerTop, len(wl))
-		for i := 0; i < take; i++ {
-			fr := wl[i]
-			fr.Category = normalizeCategory(fr.Category)
-			fr.Reason = truncate(fr.Reason, 120)
-			if existing, ok := crowdMap[fr.Path]; ok {
-				totalVotes := existing.VoteCount + 1
-				existing.Importance = (existing.Importance*float64(existing.VoteCount) + fr.Importance) / float64(totalVotes)
-				existing.VoteCount = totalVotes
-				if fr.Importance > existing.Importance && strings.TrimSpace(fr.Reason) != "" {
-					existing.Reason = fr.Reason
-				}
-				if existing.Category == "other" && fr.Category != "" {
-					existing.Category = fr.Category
-				}
-			} else {
-				copy := fr
-				if copy.VoteCount <= 0 {
-					copy.VoteCount = 1
-				}
-				crowdMap[copy.Path] = &copy
-			}
+	}
+	metrics := consensusMetrics(topLists)
+	if shouldDebug && metrics != nil {
+		dump.write("consensus_metrics.txt", metrics.String())
+	}
+
+	// Let structurally central files no worker picked compete too
+	for _, candidate := range structuralCandidates(signals, crowdMap, perWorkerTop) {
+		crowdMap[candidate.Path] = &candidate
+	}
+	for path, r := range crowdMap {
+		if signal, ok := signals[path]; ok {
+			r.Structural = signal.Score
 		}
 	}
 
@@ -324,7 +328,11 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 		if i >= 150 {
 			break
 		}
-		line := fmt.Sprintf("%s • votes:%d • imp:%.2f • reason:%s", kvp.Path, kvp.R.VoteCount, kvp.R.Importance, truncate(kvp.R.Reason, 160))
+		line := fmt.Sprintf("%s • votes:%d • imp:%.2f", kvp.Path, kvp.R.VoteCount, kvp.R.Importance)
+		if kvp.R.Structural > 0 {
+			line += fmt.Sprintf(" • structure:%.1f", kvp.R.Structural)
+		}
+		line += " • reason:" + truncate(kvp.R.Reason, 160)
 		if len(line) > 200 {
 			line = truncate(line, 200)
 		}
@@ -332,39 +340,37 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 	}
 
 	if shouldDebug {
-		_ = os.WriteFile(filepath.Join(debugDir, "adjudicator_input.txt"), []byte(strings.Join(lines, "\n")+"\n\n"+structureSummary), 0o644)
+		dump.write("adjudicator_input.txt", strings.Join(lines, "\n")+"\n\n"+structureSummary)
 	}
 
 	// Adjudication (if enabled)
 	var consensus *ConsensusResult
-	var err error
 	if !nlMode {
 		if qc.UseModelAdjudicator {
 			if qc.AdjudicatorRetry > 0 {
-				consensus, err = s.adjudicateRankingWithOptions(ctx, lines, structureSummary, adjudicatorCtxSize, adjudicatorMaxTokens, adjudicatorTimeoutMs, shouldDebug, debugDir)
+				consensus, err = s.adjudicateRankingWithOptions(ctx, projectPath, lines, structureSummary, adjudicatorCtxSize, adjudicatorMaxTokens, adjudicatorTimeoutMs, dump)
 				if err != nil {
-					consensus, err = s.adjudicateRankingWithOptions(ctx, lines, structureSummary, adjudicatorCtxSize, adjudicatorMaxTokens, adjudicatorTimeoutMs, shouldDebug, debugDir)
+					consensus, err = s.adjudicateRankingWithOptions(ctx, projectPath, lines, structureSummary, adjudicatorCtxSize, adjudicatorMaxTokens, adjudicatorTimeoutMs, dump)
 				}
 			} else {
-				consensus, err = s.adjudicateRankingWithOptions(ctx, lines, structureSummary, adjudicatorCtxSize, adjudicatorMaxTokens, adjudicatorTimeoutMs, shouldDebug, debugDir)
+				consensus, err = s.adjudicateRankingWithOptions(ctx, projectPath, lines, structureSummary, adjudicatorCtxSize, adjudicatorMaxTokens, adjudicatorTimeoutMs, dump)
 			}
 			if err != nil {
 				// Strict fail-fast: adjudicator failure aborts
 				return nil, fmt.Errorf("adjudicator failed after retry: %v", err)
 			}
 		} else {
-			// Local consensus: sort merged and take top-K
-			take := min(finalTopK, len(merged))
-			rank := make([]FileRanking, 0, take)
-			for i := 0; i < take; i++ {
-				r := *merged[i].R
-				rank = append(rank, r)
+			// Local consensus: take every merged file; top-K is cut once
+			// the structural blend has reordered them
+			rank := make([]FileRanking, 0, len(merged))
+			for _, kvp := range merged {
+				rank = append(rank, *kvp.R)
 			}
 			consensus = &ConsensusResult{Rankings: rank, Confidence: 0}
 		}
 	}
 
-	// Filter adjudicated rankings to git-tracked files only
+	// Filter adjudicated rankings to the project's files only
 	filteredRank := make([]FileRanking, 0, len(consensus.Rankings))
 	for _, r := range consensus.Rankings {
 		if _, ok := tracked[strings.TrimSpace(r.Path)]; ok {
@@ -373,6 +379,9 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 	}
 	consensus.Rankings = filteredRank
 
+	// Blend the models' importance with the import graph and reorder
+	applyStructuralSignals(consensus.Rankings, signals)
+
 	// Normalize top-K
 	if len(consensus.Rankings) > finalTopK {
 		consensus.Rankings = consensus.Rankings[:finalTopK]
@@ -382,16 +391,17 @@ func (s *service) consensusRankFiles(ctx context.Context, projectPath string, fi
 	consensus.TopDirs = dirCounts
 	consensus.FileTypes = typeCounts
 	consensus.ConsensusTime = time.Since(start)
+	consensus.Metrics = metrics
 
 	if shouldDebug {
 		b, _ := json.MarshalIndent(consensus, "", "  ")
-		_ = os.WriteFile(filepath.Join(debugDir, "adjudicated_ranking.json"), b, 0o644)
+		dump.write("adjudicated_ranking.json", string(b))
 	}
 
 	return consensus, nil
 }
 
-func (s *service) runRankingWorkerWithLimitAndOptions(ctx context.Context, focus string, structureSummary string, files []string, takeTop int, ctxSize int, maxTokens int, timeoutMs int, debugEnabled bool, debugDir string, workerIndex int, attemptIndex int, nlMode bool, wordLimit int) ([]FileRanking, string, error) {
+func (s *service) runRankingWorkerWithLimitAndOptions(ctx context.Context, projectPath string, focus string, structureSummary string, files []string, takeTop int, ctxSize int, maxTokens int, timeoutMs int, dump *debugDump, workerIndex int, attemptIndex int, nlMode bool, wordLimit int) ([]FileRanking, string, error) {
 	if s.llmClient == nil {
 		return nil, "", fmt.Errorf("LLM client not available")
 	}
@@ -399,8 +409,8 @@ func (s *service) runRankingWorkerWithLimitAndOptions(ctx context.Context, focus
 	// Check if we are in natural language worker mode
 	var useSummary bool
 	var summaryWordLimit int
-	if debugDir != "" {
-		// Load config to read the flag; debugDir implies we have projectPath context higher up already
+	if dump != nil {
+		// Load config to read the flag; a dump implies we have projectPath context higher up already
 		// We cannot easily access cfg here without threading; instead, detect via presence of a marker in prompt building below.
 	}
 
@@ -422,46 +432,13 @@ func (s *service) runRankingWorkerWithLimitAndOptions(ctx context.Context, focus
 
 	var prompt string
 	if useSummary {
-		prompt = fmt.Sprintf(`Given this list of file paths, quickly scan the path/name signals and summarize your top findings in natural language.
-Focus: %s
-
-Rules:
-- Use ONLY path/name hints; do not read file contents.
-- Keep it under %d words.
-- Mention specific paths or directories that look most important and why (path-based reasons only).
-- No code fences. Output plain text only.`, focus, summaryWordLimit)
-		prompt = prompt + "\n\nStructure hints:\n" + structureSummary + "\n\nFILES:\n" + sb.String()
+		prompt = s.renderPrompt(projectPath, "ranking_worker_summary", map[string]any{
+			"Focus": focus, "WordLimit": summaryWordLimit, "Structure": structureSummary, "Files": sb.String(),
+		})
 	} else {
-		prompt = fmt.Sprintf(`Given this list of file paths, quickly predict which files look most important and rank them.
-Focus: %s
-
-Use ONLY path/name signals (no content). Consider:
-- Top-level directories and their roles (cmd/, internal/, pkg/, app/, server/, ui/, docs/, tests/)
-- File extensions mix and what they imply (.go, .ts, .js, .yml, .md, etc.)
-- Common entrypoints (main.go, cmd/*, server startup, cli entry)
-- Orchestrators/hubs (app.go, service registries, router setup)
-- Configuration/build/CI files (go.mod, Makefile, Dockerfile, .github/workflows)
-- Tests/docs are usually lower importance unless they gate critical flows
-
-Scoring (1–10):
-- 10: primary entrypoint/bootstrap
-- 8–9: core services/components central to runtime
-- 6–7: important configuration/integration
-- 4–5: shared utilities/helpers
-- 2–3: tests/docs/examples
-
-Rules:
-- Return TOP %d items as a JSON array of objects exactly like:
-  [{"path":"...","importance":9,"reason":"<=120 chars, path-based","category":"entry|config|core|util|test|doc|other"}]
-- Reasons must be path-based and concrete (e.g., "cmd/capture-responses/main.go is CLI entrypoint").
-- Do NOT cite file size/length or placeholders like "looks long".
-- Keep reasons terse (<=120 chars).
-
-Structure hints:
-%s
-
-FILES:
-%s`, focus, takeTop, structureSummary, sb.String())
+		prompt = s.renderPrompt(projectPath, "ranking_worker", map[string]any{
+			"Focus": focus, "TakeTop": takeTop, "Structure": structureSummary, "Files": sb.String(),
+		})
 	}
 
 	messages := []llm.Message{{Role: "system", Content: "You are a file importance analyzer."}, {Role: "user", Content: prompt}}
@@ -493,8 +470,8 @@ FILES:
 	if lm, ok := s.llmClient.(*llm.LMStudioClient); ok {
 		out, err := lm.CompleteWithOptions(cctx, messages, opts)
 		if err != nil {
-			if debugEnabled {
-				_ = os.WriteFile(filepath.Join(debugDir, fmt.Sprintf("worker_%d_attempt_%d_error.txt", workerIndex, attemptIndex)), []byte(fmt.Sprintf("request error: %v\nelapsed_ms:%d", err, time.Since(startAttempt).Milliseconds())), 0o644)
+			if dump != nil {
+				dump.write(fmt.Sprintf("worker_%d_attempt_%d_error.txt", workerIndex, attemptIndex), fmt.Sprintf("request error: %v\nelapsed_ms:%d", err, time.Since(startAttempt).Milliseconds()))
 			}
 			return nil, "", err
 		}
@@ -502,18 +479,18 @@ FILES:
 	} else {
 		out, err := s.llmClient.Complete(cctx, messages)
 		if err != nil {
-			if debugEnabled {
-				_ = os.WriteFile(filepath.Join(debugDir, fmt.Sprintf("worker_%d_attempt_%d_error.txt", workerIndex, attemptIndex)), []byte(fmt.Sprintf("request error: %v\nelapsed_ms:%d", err, time.Since(startAttempt).Milliseconds())), 0o644)
+			if dump != nil {
+				dump.write(fmt.Sprintf("worker_%d_attempt_%d_error.txt", workerIndex, attemptIndex), fmt.Sprintf("request error: %v\nelapsed_ms:%d", err, time.Since(startAttempt).Milliseconds()))
 			}
 			return nil, "", err
 		}
 		content = out
 	}
-	if debugEnabled {
-		_ = os.WriteFile(filepath.Join(debugDir, fmt.Sprintf("worker_%d_attempt_%d_prompt.txt", workerIndex, attemptIndex)), []byte(prompt), 0o644)
-		_ = os.WriteFile(filepath.Join(debugDir, fmt.Sprintf("worker_%d_attempt_%d_raw.txt", workerIndex, attemptIndex)), []byte(content), 0o644)
-		_ = os.WriteFile(filepath.Join(debugDir, fmt.Sprintf("worker_%d_prompt.txt", workerIndex)), []byte(prompt), 0o644)
-		_ = os.WriteFile(filepath.Join(debugDir, fmt.Sprintf("worker_%d_raw.txt", workerIndex)), []byte(content), 0o644)
+	if dump != nil {
+		dump.write(fmt.Sprintf("worker_%d_attempt_%d_prompt.txt", workerIndex, attemptIndex), prompt)
+		dump.write(fmt.Sprintf("worker_%d_attempt_%d_raw.txt", workerIndex, attemptIndex), content)
+		dump.write(fmt.Sprintf("worker_%d_prompt.txt", workerIndex), prompt)
+		dump.write(fmt.Sprintf("worker_%d_raw.txt", workerIndex), content)
 	}
 
 	if useSummary {
@@ -521,32 +498,41 @@ FILES:
 		return []FileRanking{}, content, nil
 	}
 
-	jsonStart := strings.Index(content, "[")
-	jsonEnd := strings.LastIndex(content, "]")
-	if jsonStart < 0 || jsonEnd <= jsonStart {
-		if debugEnabled {
+	valid, err := parseWorkerRankings(content, takeTop)
+	if err != nil {
+		if dump != nil {
 			var notes []string
 			if strings.Contains(content, "```") {
 				notes = append(notes, "code_fence: true")
 			}
 			notes = append(notes, fmt.Sprintf("content_len:%d", len(content)))
-			_ = os.WriteFile(filepath.Join(debugDir, fmt.Sprintf("worker_%d_attempt_%d_error.txt", workerIndex, attemptIndex)), []byte("no JSON array found\n"+strings.Join(notes, "\n")+fmt.Sprintf("\nelapsed_ms:%d", time.Since(startAttempt).Milliseconds())), 0o644)
+			dump.write(fmt.Sprintf("worker_%d_attempt_%d_error.txt", workerIndex, attemptIndex), fmt.Sprintf("%v\n%v\nelapsed_ms:%d", err, strings.Join(notes, "\n"), time.Since(startAttempt).Milliseconds()))
 		}
-		return nil, "", fmt.Errorf("worker returned no JSON array")
+		return nil, "", err
 	}
-	jsonStr := content[jsonStart : jsonEnd+1]
 
+	return valid, content, nil
+}
+
+// parseWorkerRankings extracts a worker's JSON array of rankings from its
+// response, dropping empty and repeated paths, clamping importance to 1-10
+// and keeping at most takeTop
+func parseWorkerRankings(content string, takeTop int) ([]FileRanking, error) {
+	arrBytes := extractJSONArray([]byte(content))
 	var out []FileRanking
-	if err := json.Unmarshal([]byte(jsonStr), &out); err != nil {
-		if debugEnabled {
-			var notes []string
-			if strings.Contains(content, "```") {
-				notes = append(notes, "code_fence: true")
-			}
-			notes = append(notes, fmt.Sprintf("content_len:%d", len(content)))
-			_ = os.WriteFile(filepath.Join(debugDir, fmt.Sprintf("worker_%d_attempt_%d_error.txt", workerIndex, attemptIndex)), []byte(fmt.Sprintf("json parse error: %v\n%v\nelapsed_ms:%d", err, strings.Join(notes, "\n"), time.Since(startAttempt).Milliseconds())), 0o644)
+	err := json.Unmarshal(arrBytes, &out)
+	if err != nil {
+		// Small models often stop mid-array at the token limit; keep the
+		// elements they finished
+		if repaired := repairJSON([]byte(content), '['); len(repaired) > 0 {
+			out = nil
+			err = json.Unmarshal(repaired, &out)
+		} else if len(arrBytes) == 0 {
+			return nil, fmt.Errorf("worker returned no JSON array")
 		}
-		return nil, "", fmt.Errorf("failed to parse worker JSON: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse worker JSON: %w", err)
 	}
 
 	// Clean and cap
@@ -574,23 +560,17 @@ FILES:
 			break
 		}
 	}
-
-	return valid, content, nil
+	return valid, nil
 }
 
-func (s *service) adjudicateRankingWithOptions(ctx context.Context, compactCrowdLines []string, structureSummary string, ctxSize int, maxTokens int, timeoutMs int, shouldDebug bool, debugDir string) (*ConsensusResult, error) {
+func (s *service) adjudicateRankingWithOptions(ctx context.Context, projectPath string, compactCrowdLines []string, structureSummary string, ctxSize int, maxTokens int, timeoutMs int, dump *debugDump) (*ConsensusResult, error) {
 	if s.llmClient == nil {
 		return nil, fmt.Errorf("LLM client not available")
 	}
 
-	prompt := fmt.Sprintf(`Given the crowd lists below, choose the final consensus ranking (TOP 100).
-Prefer agreement; when split, choose the most plausible given structure.
-Return JSON with fields: {"rankings":[{path, importance, reason, category}], "confidence": 0.0..1.0}
-CROWD (condensed):
-%s
-
-STRUCTURE:
-%s`, strings.Join(compactCrowdLines, "\n"), structureSummary)
+	prompt := s.renderPrompt(projectPath, "adjudicate_rankings", map[string]any{
+		"Crowd": strings.Join(compactCrowdLines, "\n"), "Structure": structureSummary,
+	})
 
 	messages := []llm.Message{{Role: "system", Content: "Adjudicate crowd answers into a single JSON. Output only valid JSON."}, {Role: "user", Content: prompt}}
 
@@ -630,75 +610,74 @@ STRUCTURE:
 		content = out
 	}
 
-	if shouldDebug {
-		_ = os.WriteFile(filepath.Join(debugDir, "adjudicator_raw.txt"), []byte(content), 0o644)
+	if dump != nil {
+		dump.write("adjudicator_raw.txt", content)
 	}
 
-	// Attempt robust JSON extraction
-	if objBytes := extractJSONObject([]byte(content)); len(objBytes) > 0 {
-		var obj struct {
-			Rankings   []FileRanking `json:"rankings"`
-			Confidence float64       `json:"confidence"`
-		}
-		if err := json.Unmarshal(objBytes, &obj); err == nil {
-			for i := range obj.Rankings {
-				obj.Rankings[i].Reason = truncate(obj.Rankings[i].Reason, 120)
-				obj.Rankings[i].Category = normalizeCategory(obj.Rankings[i].Category)
-			}
-			return &ConsensusResult{Rankings: obj.Rankings, Confidence: obj.Confidence}, nil
-		}
+	return parseAdjudication(content)
+}
+
+// parseAdjudication extracts the adjudicator's consensus from its response:
+// a {"rankings": [...], "confidence": n} object, or failing that a bare
+// array of rankings
+func parseAdjudication(content string) (*ConsensusResult, error) {
+	data := []byte(content)
+	if consensus := decodeAdjudicationObject(extractJSONObject(data)); consensus != nil {
+		return consensus, nil
+	}
+	if consensus := decodeAdjudicationArray(extractJSONArray(data)); consensus != nil {
+		return consensus, nil
+	}
+	// Last resort: a response cut off at the token limit
+	if consensus := decodeAdjudicationObject(repairJSON(data, '{')); consensus != nil {
+		return consensus, nil
 	}
+	if consensus := decodeAdjudicationArray(repairJSON(data, '[')); consensus != nil {
+		return consensus, nil
+	}
+	return nil, fmt.Errorf("adjudicator object parse failed: unable to extract JSON")
+}
 
-	// Fallback: try array form
-	if arrBytes := extractJSONArray([]byte(content)); len(arrBytes) > 0 {
-		var arr []FileRanking
-		if err := json.Unmarshal(arrBytes, &arr); err == nil {
-			for i := range arr {
-				arr[i].Reason = truncate(arr[i].Reason, 120)
-				arr[i].Category = normalizeCategory(arr[i].Category)
-			}
-			return &ConsensusResult{Rankings: arr, Confidence: 0}, nil
-		}
+// decodeAdjudicationObject reads the consensus object, or returns nil. An
+// object without rankings is the first item of a bare array.
+func decodeAdjudicationObject(data []byte) *ConsensusResult {
+	var obj struct {
+		Rankings   []FileRanking `json:"rankings"`
+		Confidence float64       `json:"confidence"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil || len(obj.Rankings) == 0 {
+		return nil
+	}
+	return &ConsensusResult{Rankings: cleanAdjudicated(obj.Rankings), Confidence: obj.Confidence}
+}
+
+// decodeAdjudicationArray reads a bare array of rankings, or returns nil
+func decodeAdjudicationArray(data []byte) *ConsensusResult {
+	var arr []FileRanking
+	if err := json.Unmarshal(data, &arr); err != nil {
+		return nil
 	}
+	return &ConsensusResult{Rankings: cleanAdjudicated(arr), Confidence: 0}
+}
 
-	return nil, fmt.Errorf("adjudicator object parse failed: unable to extract JSON")
+// cleanAdjudicated caps reasons and normalizes categories in place
+func cleanAdjudicated(rankings []FileRanking) []FileRanking {
+	for i := range rankings {
+		rankings[i].Reason = truncate(rankings[i].Reason, 120)
+		rankings[i].Category = normalizeCategory(rankings[i].Category)
+	}
+	return rankings
 }
 
-func (s *service) adjudicateSummariesWithOptions(ctx context.Context, summaries []string, structureSummary string, ctxSize int, maxTokens int, timeoutMs int, shouldDebug bool, debugDir string) (*ConsensusResult, error) {
+func (s *service) adjudicateSummariesWithOptions(ctx context.Context, projectPath string, summaries []string, structureSummary string, ctxSize int, maxTokens int, timeoutMs int, dump *debugDump) (*ConsensusResult, error) {
 	if s.llmClient == nil {
 		return nil, fmt.Errorf("LLM client not available")
 	}
 
 	// Markdown-only adjudication with strict template
-	mdPrompt := fmt.Sprintf(`# Project Summary
-
-**Purpose**: <short string>
-
-**Structure overview**:
-
-<short paragraph grounded in path/name signals>
-
-**Important files**:
-
-- path — role: reason
-- path — role: reason
-- path — role: reason
-
-**Notes**:
-- <short caveats or unknowns>
-
-Constraints:
-- Output only the template above. No extra sections, no code fences.
-- Do not restate or enumerate individual worker summaries.
-- Synthesize an overview; do not quote or paraphrase worker text.
-- Use only paths present in worker summaries or FILES. Choose at most 10 items.
-- role ∈ {entry, config, core, util, test, doc, other}; reason ≤ 120 chars and path-anchored.
-
-WORKER SUMMARIES:
-%s
-
-STRUCTURE HINTS:
-%s`, strings.Join(summaries, "\n\n---\n\n"), structureSummary)
+	mdPrompt := s.renderPrompt(projectPath, "adjudicate_summaries", map[string]any{
+		"Summaries": strings.Join(summaries, "\n\n---\n\n"), "Structure": structureSummary,
+	})
 
 	messages := []llm.Message{{Role: "system", Content: "Adjudicate worker summaries into exactly the provided markdown template. Be strict. Output only the template. Do not restate or enumerate worker summaries. No extra sections, no code fences."}, {Role: "user", Content: mdPrompt}}
 
@@ -737,8 +716,8 @@ STRUCTURE HINTS:
 		content = out
 	}
 
-	if shouldDebug {
-		_ = os.WriteFile(filepath.Join(debugDir, "adjudicator_raw.txt"), []byte(content), 0o644)
+	if dump != nil {
+		dump.write("adjudicator_raw.txt", content)
 	}
 
 	return &ConsensusResult{SummaryMarkdown: content}, nil
@@ -843,15 +822,12 @@ func prefilterForRanking(files []string) []string {
 	return out
 }
 
+// topLevelDirCounts counts project files by their top-level directory, "."
+// for files at the root
 func topLevelDirCounts(files []string) map[string]int {
 	m := map[string]int{}
 	for _, f := range files {
-		parts := strings.Split(f, string(os.PathSeparator))
-		if len(parts) > 1 {
-			m[parts[0]]++
-		} else {
-			m["."]++
-		}
+		m[topLevelDir(f)]++
 	}
 	return m
 }
@@ -880,7 +856,12 @@ func buildStructureSummary(topDirs, fileTypes map[string]int) string {
 			V int
 		}{k, v})
 	}
-	sort.SliceStable(dirPairs, func(i, j int) bool { return dirPairs[i].V > dirPairs[j].V })
+	sort.Slice(dirPairs, func(i, j int) bool {
+		if dirPairs[i].V != dirPairs[j].V {
+			return dirPairs[i].V > dirPairs[j].V
+		}
+		return dirPairs[i].K < dirPairs[j].K
+	})
 	if len(dirPairs) > 10 {
 		dirPairs = dirPairs[:10]
 	}
@@ -895,7 +876,12 @@ func buildStructureSummary(topDirs, fileTypes map[string]int) string {
 			V int
 		}{k, v})
 	}
-	sort.SliceStable(typePairs, func(i, j int) bool { return typePairs[i].V > typePairs[j].V })
+	sort.Slice(typePairs, func(i, j int) bool {
+		if typePairs[i].V != typePairs[j].V {
+			return typePairs[i].V > typePairs[j].V
+		}
+		return typePairs[i].K < typePairs[j].K
+	})
 	if len(typePairs) > 10 {
 		typePairs = typePairs[:10]
 	}
@@ -0,0 +1,185 @@
+package analysis
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DescribeConventions lists what the file list shows of how the project is
+// laid out, from names alone: language, key directories, file naming and
+// where tests go. It reads no source, so it is right even before any tier
+// has run.
+func DescribeConventions(projectPath string) (string, error) {
+	files, err := GetProjectFiles(projectPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get project files: %w", err)
+	}
+	var code []string
+	for _, file := range files {
+		if isTestableLanguage(file) && !isVendored(file) {
+			code = append(code, file)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "- Main language: %s\n", detectMainLanguage(files))
+	if module := readGoModulePath(projectPath); module != "" {
+		fmt.Fprintf(&b, "- Go module: %s\n", module)
+	}
+	if dirs := keyDirectories(code, maxInstantDirs); len(dirs) > 0 {
+		fmt.Fprintf(&b, "- Code lives under: %s\n", strings.Join(dirs, ", "))
+	}
+	if naming := namingStyles(code); naming != "" {
+		fmt.Fprintf(&b, "- File names: %s\n", naming)
+	}
+	if testMap, _, err := RefreshTestMap(projectPath); err == nil {
+		fmt.Fprintf(&b, "- Tests: %s\n", testStyle(testMap))
+	}
+	return b.String(), nil
+}
+
+// ConventionExamples picks up to n existing source files whose paths share
+// the most words with thing, each followed by the test that covers it by
+// package or name when there is one, as models to copy the layout of
+func ConventionExamples(projectPath, thing string, n int) ([]string, error) {
+	files, err := GetProjectFiles(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project files: %w", err)
+	}
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(thing), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if len(word) >= 3 {
+			words = append(words, strings.TrimSuffix(word, "s"))
+		}
+	}
+
+	scores := map[string]int{}
+	var candidates []string
+	for _, file := range files {
+		if !isTestableLanguage(file) || isTestFile(file) || isVendored(file) || !isTestableSource(file) {
+			continue
+		}
+		lower := strings.ToLower(file)
+		for _, word := range words {
+			if strings.Contains(lower, word) {
+				scores[file]++
+			}
+		}
+		if scores[file] > 0 {
+			candidates = append(candidates, file)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if scores[candidates[i]] != scores[candidates[j]] {
+			return scores[candidates[i]] > scores[candidates[j]]
+		}
+		if len(candidates[i]) != len(candidates[j]) {
+			return len(candidates[i]) < len(candidates[j])
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	testMap := LoadTestMap(projectPath)
+	var examples []string
+	for _, file := range candidates[:min(n, len(candidates))] {
+		examples = append(examples, file)
+		if testMap == nil {
+			continue
+		}
+		for _, mapping := range testMap.Tests {
+			if how := mapping.Covers[file]; how == CoverPackage || how == CoverName {
+				examples = append(examples, mapping.Test)
+				break
+			}
+		}
+	}
+	return examples, nil
+}
+
+// namingStyles names the two most common ways the code's file names are
+// written, with counts
+func namingStyles(code []string) string {
+	counts := map[string]int{}
+	for _, file := range code {
+		stem := strings.SplitN(path.Base(file), ".", 2)[0]
+		stem = strings.TrimSuffix(strings.TrimPrefix(stem, "test_"), "_test")
+		switch {
+		case strings.Contains(stem, "_"):
+			counts["snake_case"]++
+		case strings.Contains(stem, "-"):
+			counts["kebab-case"]++
+		case stem != "" && unicode.IsUpper(rune(stem[0])):
+			counts["PascalCase"]++
+		case strings.ToLower(stem) != stem:
+			counts["camelCase"]++
+		default:
+			counts["single lowercase word"]++
+		}
+	}
+	styles := make([]string, 0, len(counts))
+	for style := range counts {
+		styles = append(styles, style)
+	}
+	sort.Slice(styles, func(i, j int) bool {
+		if counts[styles[i]] != counts[styles[j]] {
+			return counts[styles[i]] > counts[styles[j]]
+		}
+		return styles[i] < styles[j]
+	})
+	var parts []string
+	for _, style := range styles[:min(2, len(styles))] {
+		parts = append(parts, fmt.Sprintf("%s (%d)", style, counts[style]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// testStyle describes where the project's tests sit and how they are named
+func testStyle(m *TestMap) string {
+	if len(m.Tests) == 0 {
+		return "none yet"
+	}
+	counts := map[string]int{}
+	for _, mapping := range m.Tests {
+		test := mapping.Test
+		base := path.Base(test)
+		switch {
+		case strings.HasSuffix(base, "_test.go"):
+			counts["<name>_test.go next to the code, in its package"]++
+		case strings.Contains(test, "__tests__/"):
+			counts["in __tests__ directories"]++
+		case strings.Contains(base, ".test."):
+			counts["<name>.test.<ext> next to the code"]++
+		case strings.Contains(base, ".spec."):
+			counts["<name>.spec.<ext> next to the code"]++
+		case strings.HasPrefix(base, "test_") && underDir(test, "tests", "test"):
+			counts["test_<name>.py under tests/"]++
+		case strings.HasPrefix(base, "test_"):
+			counts["test_<name>.py next to the code"]++
+		default:
+			counts["<name>_test.py"]++
+		}
+	}
+	best := ""
+	for style, count := range counts {
+		if best == "" || count > counts[best] || (count == counts[best] && style < best) {
+			best = style
+		}
+	}
+	return fmt.Sprintf("%s; %d of %d source files have a test by package or name", best, len(m.Direct), len(m.Sources))
+}
+
+// underDir reports whether file sits under a directory with one of the
+// names
+func underDir(file string, names ...string) bool {
+	for _, part := range strings.Split(path.Dir(file), "/") {
+		for _, name := range names {
+			if part == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConventions(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                       "module example.com/app\n",
+		"internal/tools/bash.go":       "package tools\n",
+		"internal/tools/bash_test.go":  "package tools\n",
+		"internal/tools/git_commit.go": "package tools\n",
+		"internal/store/store.go":      "package store\n",
+		"cmd/app/main.go":              "package main\n",
+	})
+
+	conventions, err := DescribeConventions(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"- Go module: example.com/app",
+		"- File names: single lowercase word (4), snake_case (1)",
+		"- Tests: <name>_test.go next to the code, in its package; 2 of 4 source files",
+	} {
+		if !strings.Contains(conventions, want) {
+			t.Errorf("conventions missing %q:\n%s", want, conventions)
+		}
+	}
+
+	examples, err := ConventionExamples(root, "a tool that lists ports", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"internal/tools/bash.go", "internal/tools/bash_test.go", "internal/tools/git_commit.go", "internal/tools/bash_test.go"}; !reflect.DeepEqual(examples, want) {
+		t.Errorf("examples = %v, want %v", examples, want)
+	}
+	if examples, _ := ConventionExamples(root, "a parser", 2); len(examples) != 0 {
+		t.Errorf("unrelated thing matched %v", examples)
+	}
+}
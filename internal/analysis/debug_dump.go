@@ -0,0 +1,114 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/billie-coop/loco/internal/config"
+)
+
+// debugDump is the debug directory of one analysis run, under
+// .loco/debug/<tier>/. What it keeps of prompts and responses follows
+// logging.content.
+type debugDump struct {
+	dir     string
+	logging config.LoggingConfig
+}
+
+// newDebugDump creates the debug directory for a tier's run. It returns nil,
+// which writes nothing, when debugging is off or logging.content is "none".
+func newDebugDump(projectPath, tier string, cfg *config.Config, enabled bool) *debugDump {
+	if !enabled {
+		return nil
+	}
+	logging := config.DefaultConfig().Logging
+	if cfg != nil {
+		logging = cfg.Logging
+	}
+	if logging.Content == config.LogContentNone {
+		return nil
+	}
+	dir := filepath.Join(locoDir(projectPath), "debug", tier, time.Now().Format("20060102_150405"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil
+	}
+	return &debugDump{dir: dir, logging: logging}
+}
+
+// write saves a file of the dump, or only the size and hash of its content
+// when logging.content is "hashes"
+func (d *debugDump) write(name, content string) {
+	if d == nil {
+		return
+	}
+	if text, ok := d.logging.Dump(content); ok {
+		_ = os.WriteFile(filepath.Join(d.dir, name), []byte(text), 0o644)
+	}
+}
+
+// PruneDebugDumps removes the analysis debug dumps under .loco/debug that
+// are older than days, returning how many it removed. days below one keeps
+// them all.
+func PruneDebugDumps(projectPath string, days int) (int, error) {
+	if days < 1 {
+		return 0, nil
+	}
+	root := filepath.Join(locoDir(projectPath), "debug")
+	tiers, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -days)
+	removed := 0
+	for _, tier := range tiers {
+		if !tier.IsDir() {
+			continue
+		}
+		runs, err := os.ReadDir(filepath.Join(root, tier.Name()))
+		if err != nil {
+			continue
+		}
+		for _, run := range runs {
+			info, err := run.Info()
+			if err != nil || !info.ModTime().Before(cutoff) {
+				continue
+			}
+			if err := os.RemoveAll(filepath.Join(root, tier.Name(), run.Name())); err == nil {
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
+
+// LatestDebugDumps returns the newest debug dump of each analysis tier
+func LatestDebugDumps(projectPath string) []string {
+	root := filepath.Join(locoDir(projectPath), "debug")
+	tiers, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var latest []string
+	for _, tier := range tiers {
+		if !tier.IsDir() {
+			continue
+		}
+		runs, err := os.ReadDir(filepath.Join(root, tier.Name()))
+		if err != nil {
+			continue
+		}
+		// Runs are named by their start time, so the last one is the newest
+		for i := len(runs) - 1; i >= 0; i-- {
+			if runs[i].IsDir() {
+				latest = append(latest, filepath.Join(root, tier.Name(), runs[i].Name()))
+				break
+			}
+		}
+	}
+	return latest
+}
@@ -0,0 +1,147 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+// decisionsFile is the decision log at the knowledge root
+const decisionsFile = "decisions.md"
+
+// decisionsHeader starts a new decision log
+const decisionsHeader = `# Decisions
+
+Decisions made in conversations with Loco and why, newest last. Each links
+back to the session it was made in, under .loco/sessions/.
+`
+
+// Decision is a choice made in a conversation, with why it was made
+type Decision struct {
+	Title     string    `json:"decision"`
+	Rationale string    `json:"rationale"`
+	Time      time.Time `json:"-"` // When the turn it was made in started
+	SessionID string    `json:"-"`
+}
+
+// Exchange is one turn of a conversation as given to ExtractDecisions
+type Exchange struct {
+	Time time.Time
+	Text string // The user's message and the replies, labelled by role
+}
+
+// ExtractDecisions asks the model which decisions the exchanges made. Only
+// settled choices count: options merely discussed, questions and plain
+// answers are left out. Each decision takes the time of the exchange that
+// made it.
+func ExtractDecisions(ctx context.Context, client llm.Client, sessionID string, exchanges []Exchange) ([]Decision, error) {
+	if len(exchanges) == 0 {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	for i, exchange := range exchanges {
+		fmt.Fprintf(&b, "[%d]\n%s\n\n", i+1, strings.TrimSpace(exchange.Text))
+	}
+	b.WriteString(`List the decisions made in these exchanges as a JSON array of {"exchange": <number>, "decision": "...", "rationale": "..."}. ` +
+		`A decision is a settled choice about the project's design, tools, conventions or direction. ` +
+		`Write each decision as a short imperative title and the rationale as one or two sentences from what was said. ` +
+		`Reply [] when nothing was decided.`)
+
+	messages := []llm.Message{
+		{Role: "system", Content: "You keep an architecture decision record. Reply with JSON only."},
+		{Role: "user", Content: b.String()},
+	}
+	reply, err := client.Complete(ctx, messages)
+	if err != nil {
+		return nil, fmt.Errorf("decision extraction failed: %w", err)
+	}
+
+	raw := extractJSONArray([]byte(reply))
+	if raw == nil {
+		return nil, fmt.Errorf("decision extraction returned no JSON array")
+	}
+	var found []struct {
+		Exchange  int    `json:"exchange"`
+		Decision  string `json:"decision"`
+		Rationale string `json:"rationale"`
+	}
+	if err := json.Unmarshal(raw, &found); err != nil {
+		return nil, fmt.Errorf("failed to parse decisions: %w", err)
+	}
+
+	var decisions []Decision
+	for _, d := range found {
+		title := strings.Join(strings.Fields(d.Decision), " ")
+		if title == "" {
+			continue
+		}
+		when := exchanges[len(exchanges)-1].Time
+		if d.Exchange >= 1 && d.Exchange <= len(exchanges) {
+			when = exchanges[d.Exchange-1].Time
+		}
+		decisions = append(decisions, Decision{
+			Title:     title,
+			Rationale: strings.TrimSpace(d.Rationale),
+			Time:      when,
+			SessionID: sessionID,
+		})
+	}
+	return decisions, nil
+}
+
+// AppendDecisions adds decisions to .loco/knowledge/decisions.md, creating
+// it when needed. Each entry links to its session's file.
+func AppendDecisions(projectPath string, decisions []Decision) error {
+	if len(decisions) == 0 {
+		return nil
+	}
+	dir := knowledgeDir(projectPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, decisionsFile)
+
+	var b strings.Builder
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		b.WriteString(decisionsHeader)
+	}
+	// Link from the log's directory to .loco/sessions/
+	sessions, err := filepath.Rel(dir, filepath.Join(locoDir(projectPath), "sessions"))
+	if err != nil {
+		return err
+	}
+	for _, d := range decisions {
+		fmt.Fprintf(&b, "\n## %s — %s\n\n", d.Time.Format("2006-01-02 15:04"), d.Title)
+		if d.Rationale != "" {
+			fmt.Fprintf(&b, "%s\n\n", d.Rationale)
+		}
+		fmt.Fprintf(&b, "Session: [%s](%s)\n", d.SessionID, filepath.ToSlash(filepath.Join(sessions, d.SessionID+".json")))
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadDecisions returns the decision log, or "" when nothing has been
+// logged yet
+func LoadDecisions(projectPath string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(knowledgeDir(projectPath), decisionsFile))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return string(data), err
+}
@@ -0,0 +1,59 @@
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+func TestDecisionLog(t *testing.T) {
+	dir := t.TempDir()
+	client := llm.NewMockClient([]llm.MockResponse{{
+		Match: "decisions made in these exchanges",
+		Response: "Here you go:\n```json\n" +
+			`[{"exchange": 2, "decision": "Use  SQLite for\nthe cache", "rationale": "It ships with the binary. "},` +
+			`{"exchange": 1, "decision": " ", "rationale": "skipped"}]` + "\n```",
+	}}, 0)
+
+	first := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	exchanges := []Exchange{
+		{Time: first, Text: "User: hi\n\nAssistant: hello"},
+		{Time: first.Add(time.Hour), Text: "User: let's cache in SQLite\n\nAssistant: agreed"},
+	}
+	decisions, err := ExtractDecisions(context.Background(), client, "chat_1", exchanges)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 1 {
+		t.Fatalf("ExtractDecisions() = %d decisions, want 1", len(decisions))
+	}
+	d := decisions[0]
+	if d.Title != "Use SQLite for the cache" || d.Rationale != "It ships with the binary." || !d.Time.Equal(exchanges[1].Time) || d.SessionID != "chat_1" {
+		t.Errorf("decision = %+v", d)
+	}
+
+	if err := AppendDecisions(dir, decisions); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendDecisions(dir, []Decision{{Title: "Keep tests table-driven", Time: first, SessionID: "chat_2"}}); err != nil {
+		t.Fatal(err)
+	}
+	log, err := LoadDecisions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(log, "# Decisions") != 1 {
+		t.Errorf("log should have one header:\n%s", log)
+	}
+	for _, want := range []string{
+		"## 2026-03-01 10:30 — Use SQLite for the cache\n\nIt ships with the binary.\n\nSession: [chat_1](../sessions/chat_1.json)",
+		"## 2026-03-01 09:30 — Keep tests table-driven\n\nSession: [chat_2](../sessions/chat_2.json)",
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("log missing %q:\n%s", want, log)
+		}
+	}
+}
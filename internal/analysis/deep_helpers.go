@@ -87,7 +87,7 @@ func (s *service) generateKnowledgeDocumentsSkeptical(
 	fileSummaries *FileAnalysisResult,
 	previousKnowledge map[string]string,
 ) (map[string]string, error) {
-	return s.generateKnowledgeDocumentsSkeptic(ctx, projectPath, fileSummaries, previousKnowledge)
+	return s.generateKnowledgeDocumentsSkeptic(ctx, projectPath, fileSummaries, TierDeep, previousKnowledge)
 }
 
 // compareKnowledgeFiles identifies what changed between tiers.
@@ -0,0 +1,363 @@
+package analysis
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/files"
+)
+
+// Dependency files at the knowledge root
+const (
+	dependenciesJSON = "dependencies.json"
+	dependenciesMD   = "dependencies.md"
+)
+
+// Dependency kinds
+const (
+	DependencyRuntime  = "runtime"
+	DependencyDev      = "dev"
+	DependencyBuild    = "build"
+	DependencyPeer     = "peer"
+	DependencyOptional = "optional"
+	DependencyIndirect = "indirect"
+)
+
+// Dependency is one requirement declared in a manifest
+type Dependency struct {
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"` // As written: a version, a range, or empty when unpinned
+	Kind     string `json:"kind"`
+	Manifest string `json:"manifest"` // Project-relative path of the manifest declaring it
+}
+
+// DependencyManifest is a manifest the dependency list was read from
+type DependencyManifest struct {
+	Path         string `json:"path"`
+	Ecosystem    string `json:"ecosystem"` // go, npm, pip or cargo
+	ContentHash  string `json:"content_hash"`
+	Dependencies int    `json:"dependencies"`
+}
+
+// DependencyReport is every dependency the project's manifests declare
+type DependencyReport struct {
+	Generated    time.Time            `json:"generated"`
+	Manifests    []DependencyManifest `json:"manifests"`
+	Dependencies []Dependency         `json:"dependencies"`
+}
+
+// manifestParsers read the dependencies of each manifest file name, with
+// the ecosystem it belongs to
+var manifestParsers = map[string]struct {
+	ecosystem string
+	parse     func(data []byte) ([]Dependency, error)
+}{
+	"go.mod":           {"go", parseGoModDependencies},
+	"package.json":     {"npm", parsePackageJSONDependencies},
+	"requirements.txt": {"pip", parseRequirementsDependencies},
+	"Cargo.toml":       {"cargo", parseCargoDependencies},
+}
+
+// IsManifest reports whether a file is a manifest the dependency analysis
+// reads
+func IsManifest(file string) bool {
+	_, ok := manifestParsers[filepath.Base(file)]
+	return ok
+}
+
+// RefreshDependencies writes knowledge/dependencies.json and
+// dependencies.md from the project's manifests, unless none changed since
+// they were last written. It returns the report and whether it was
+// rewritten.
+func RefreshDependencies(projectPath string) (*DependencyReport, bool, error) {
+	listed, err := GetProjectFiles(projectPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get project files: %w", err)
+	}
+	var manifests []DependencyManifest
+	for _, file := range listed {
+		if IsManifest(file) && !isVendored(file) {
+			manifests = append(manifests, DependencyManifest{
+				Path:        file,
+				Ecosystem:   manifestParsers[path.Base(file)].ecosystem,
+				ContentHash: files.ContentHash(filepath.Join(projectPath, file)),
+			})
+		}
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Path < manifests[j].Path })
+
+	if saved := LoadDependencies(projectPath); saved != nil && sameManifests(saved.Manifests, manifests) {
+		return saved, false, nil
+	}
+
+	report := &DependencyReport{Generated: analysisStamp(projectPath), Manifests: manifests, Dependencies: []Dependency{}}
+	for i, manifest := range manifests {
+		data, err := os.ReadFile(filepath.Join(projectPath, manifest.Path))
+		if err != nil {
+			continue
+		}
+		deps, err := manifestParsers[path.Base(manifest.Path)].parse(data)
+		if err != nil {
+			continue // A manifest that doesn't parse lists nothing until fixed
+		}
+		for _, dep := range deps {
+			dep.Manifest = manifest.Path
+			report.Dependencies = append(report.Dependencies, dep)
+		}
+		report.Manifests[i].Dependencies = len(deps)
+	}
+
+	dir := knowledgeDir(projectPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, false, err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, dependenciesJSON), data, 0644); err != nil {
+		return nil, false, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, dependenciesMD), []byte(report.Markdown()), 0644); err != nil {
+		return nil, false, err
+	}
+	return report, true, nil
+}
+
+// LoadDependencies reads the saved dependency report, or nil when there is
+// none
+func LoadDependencies(projectPath string) *DependencyReport {
+	data, err := os.ReadFile(filepath.Join(knowledgeDir(projectPath), dependenciesJSON))
+	if err != nil {
+		return nil
+	}
+	var report DependencyReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil
+	}
+	return &report
+}
+
+// sameManifests reports whether two manifest lists name the same files with
+// the same contents
+func sameManifests(a, b []DependencyManifest) bool {
+	return slices.EqualFunc(a, b, func(x, y DependencyManifest) bool {
+		return x.Path == y.Path && x.ContentHash == y.ContentHash
+	})
+}
+
+// Markdown renders the report as dependencies.md: a table per manifest
+func (r *DependencyReport) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# Dependencies\n\n")
+	if len(r.Manifests) == 0 {
+		b.WriteString("No go.mod, package.json, requirements.txt or Cargo.toml found.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%d dependencies declared in %d manifests.\n", len(r.Dependencies), len(r.Manifests))
+	for _, manifest := range r.Manifests {
+		fmt.Fprintf(&b, "\n## %s (%s)\n\n", manifest.Path, manifest.Ecosystem)
+		if manifest.Dependencies == 0 {
+			b.WriteString("No dependencies.\n")
+			continue
+		}
+		b.WriteString("| Name | Version | Kind |\n|------|---------|------|\n")
+		for _, dep := range r.Dependencies {
+			if dep.Manifest != manifest.Path {
+				continue
+			}
+			version := dep.Version
+			if version == "" {
+				version = "-"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", dep.Name, version, dep.Kind)
+		}
+	}
+	return b.String()
+}
+
+// parseGoModDependencies reads the require directives of a go.mod, single
+// or in blocks. Requirements marked // indirect are kept as such.
+func parseGoModDependencies(data []byte) ([]Dependency, error) {
+	var deps []Dependency
+	inRequire := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, comment, _ := strings.Cut(scanner.Text(), "//")
+		line = strings.TrimSpace(line)
+		var fields []string
+		switch {
+		case inRequire && line == ")":
+			inRequire = false
+			continue
+		case inRequire:
+			fields = strings.Fields(line)
+		case line == "require (":
+			inRequire = true
+			continue
+		case strings.HasPrefix(line, "require "):
+			fields = strings.Fields(strings.TrimPrefix(line, "require "))
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		kind := DependencyRuntime
+		if strings.Contains(comment, "indirect") {
+			kind = DependencyIndirect
+		}
+		deps = append(deps, Dependency{Name: strings.Trim(fields[0], `"`), Version: fields[1], Kind: kind})
+	}
+	return deps, scanner.Err()
+}
+
+// parsePackageJSONDependencies reads every dependency section of a
+// package.json
+func parsePackageJSONDependencies(data []byte) ([]Dependency, error) {
+	var manifest npmManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	var deps []Dependency
+	for _, section := range []struct {
+		kind string
+		deps map[string]string
+	}{
+		{DependencyRuntime, manifest.Dependencies},
+		{DependencyDev, manifest.DevDependencies},
+		{DependencyPeer, manifest.PeerDependencies},
+		{DependencyOptional, manifest.OptionalDependencies},
+	} {
+		for _, name := range slices.Sorted(maps.Keys(section.deps)) {
+			deps = append(deps, Dependency{Name: name, Version: section.deps[name], Kind: section.kind})
+		}
+	}
+	return deps, nil
+}
+
+// parseRequirementsDependencies reads a pip requirements file. Options,
+// includes and editable installs are skipped; a == pin is kept as the bare
+// version, any other specifier as written.
+func parseRequirementsDependencies(data []byte) ([]Dependency, error) {
+	var deps []Dependency
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		line, _, _ = strings.Cut(line, ";") // Environment markers
+		end := strings.IndexAny(line, "=<>!~[ ")
+		if end < 0 {
+			deps = append(deps, Dependency{Name: line, Kind: DependencyRuntime})
+			continue
+		}
+		name, spec := line[:end], strings.TrimSpace(line[end:])
+		if strings.HasPrefix(spec, "[") {
+			// Extras come before the specifier
+			if close := strings.Index(spec, "]"); close >= 0 {
+				spec = strings.TrimSpace(spec[close+1:])
+			}
+		}
+		if version, ok := strings.CutPrefix(spec, "=="); ok {
+			spec = strings.TrimSpace(version)
+		}
+		deps = append(deps, Dependency{Name: name, Version: spec, Kind: DependencyRuntime})
+	}
+	return deps, scanner.Err()
+}
+
+// cargoSections maps Cargo.toml dependency tables to dependency kinds
+var cargoSections = map[string]string{
+	"dependencies":       DependencyRuntime,
+	"dev-dependencies":   DependencyDev,
+	"build-dependencies": DependencyBuild,
+}
+
+// parseCargoDependencies reads the dependency tables of a Cargo.toml,
+// target-specific ones included: name = "version", inline tables with a
+// version key, and [dependencies.name] tables
+func parseCargoDependencies(data []byte) ([]Dependency, error) {
+	var deps []Dependency
+	kind := ""  // Kind of the dependency table being read, "" outside one
+	table := -1 // Index in deps of the [dependencies.name] table being read
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(tomlStripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			kind, table = "", -1
+			parts := strings.Split(strings.Trim(line, "[] "), ".")
+			for i, part := range parts {
+				sectionKind, ok := cargoSections[part]
+				if !ok || (i > 0 && parts[0] != "target" && parts[0] != "workspace") {
+					continue
+				}
+				switch rest := parts[i+1:]; len(rest) {
+				case 0:
+					kind = sectionKind
+				case 1:
+					deps = append(deps, Dependency{Name: strings.Trim(rest[0], `"`), Kind: sectionKind})
+					table = len(deps) - 1
+				}
+				break
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.Trim(strings.TrimSpace(key), `"`), strings.TrimSpace(value)
+		switch {
+		case table >= 0 && key == "version":
+			deps[table].Version = strings.Trim(value, `"`)
+		case kind != "" && strings.HasPrefix(value, "{"):
+			deps = append(deps, Dependency{Name: key, Version: inlineTableValue(value, "version"), Kind: kind})
+		case kind != "":
+			deps = append(deps, Dependency{Name: key, Version: strings.Trim(value, `"`), Kind: kind})
+		}
+	}
+	return deps, scanner.Err()
+}
+
+// tomlStripComment cuts a TOML line at the first # outside a string
+func tomlStripComment(line string) string {
+	quoted := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '#' && !quoted:
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// inlineTableValue returns a string key's value from a TOML inline table
+// like { version = "1.0", features = ["derive"] }
+func inlineTableValue(table, key string) string {
+	for _, field := range strings.Split(strings.Trim(table, "{} "), ",") {
+		k, v, ok := strings.Cut(field, "=")
+		if ok && strings.TrimSpace(k) == key {
+			return strings.Trim(strings.TrimSpace(v), `"`)
+		}
+	}
+	return ""
+}
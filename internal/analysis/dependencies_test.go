@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestManifestParsers(t *testing.T) {
+	tests := []struct {
+		manifest string
+		content  string
+		want     []Dependency
+	}{
+		{
+			manifest: "go.mod",
+			content:  "module x\n\nrequire github.com/a/b v1.0.0\n\nrequire (\n\tgithub.com/c/d v1.2.0 // indirect\n\tgolang.org/x/e v0.3.0\n)\n",
+			want: []Dependency{
+				{Name: "github.com/a/b", Version: "v1.0.0", Kind: DependencyRuntime},
+				{Name: "github.com/c/d", Version: "v1.2.0", Kind: DependencyIndirect},
+				{Name: "golang.org/x/e", Version: "v0.3.0", Kind: DependencyRuntime},
+			},
+		},
+		{
+			manifest: "package.json",
+			content:  `{"dependencies": {"react": "^18.2.0", "clsx": "2.0.0"}, "devDependencies": {"vite": "~5.0.0"}, "peerDependencies": {"react-dom": ">=18"}}`,
+			want: []Dependency{
+				{Name: "clsx", Version: "2.0.0", Kind: DependencyRuntime},
+				{Name: "react", Version: "^18.2.0", Kind: DependencyRuntime},
+				{Name: "vite", Version: "~5.0.0", Kind: DependencyDev},
+				{Name: "react-dom", Version: ">=18", Kind: DependencyPeer},
+			},
+		},
+		{
+			manifest: "requirements.txt",
+			content:  "# web\nflask==3.0.0\nrequests[socks] >= 2.31 # http\n-r dev.txt\nnumpy\nuvloop; sys_platform != 'win32'\n",
+			want: []Dependency{
+				{Name: "flask", Version: "3.0.0", Kind: DependencyRuntime},
+				{Name: "requests", Version: ">= 2.31", Kind: DependencyRuntime},
+				{Name: "numpy", Kind: DependencyRuntime},
+				{Name: "uvloop", Kind: DependencyRuntime},
+			},
+		},
+		{
+			manifest: "Cargo.toml",
+			content:  "[package]\nname = \"x\"\nversion = \"0.1.0\"\n\n[dependencies]\nserde = { version = \"1.0\", features = [\"derive\"] }\nanyhow = \"1\" # errors\n\n[dependencies.tokio]\nversion = \"1.35\"\nfeatures = [\"full\"]\n\n[target.'cfg(unix)'.dev-dependencies]\ntempfile = \"3\"\n",
+			want: []Dependency{
+				{Name: "serde", Version: "1.0", Kind: DependencyRuntime},
+				{Name: "anyhow", Version: "1", Kind: DependencyRuntime},
+				{Name: "tokio", Version: "1.35", Kind: DependencyRuntime},
+				{Name: "tempfile", Version: "3", Kind: DependencyDev},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.manifest, func(t *testing.T) {
+			got, err := manifestParsers[tt.manifest].parse([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefreshDependencies(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                              "module x\n\nrequire github.com/a/b v1.0.0\n",
+		"web/package.json":                    `{"dependencies": {"react": "^18.2.0"}}`,
+		"web/node_modules/react/package.json": `{"dependencies": {"loose-envify": "^1.1.0"}}`,
+	})
+
+	report, written, err := RefreshDependencies(root)
+	if err != nil || !written {
+		t.Fatalf("first refresh: written %v, err %v", written, err)
+	}
+	if len(report.Manifests) != 2 || len(report.Dependencies) != 2 {
+		t.Fatalf("report = %+v, want go.mod and web/package.json with one dependency each", report)
+	}
+	if md := report.Markdown(); !strings.Contains(md, "## web/package.json (npm)") || !strings.Contains(md, "| react | ^18.2.0 | runtime |") {
+		t.Errorf("dependencies.md:\n%s", md)
+	}
+
+	if _, written, _ := RefreshDependencies(root); written {
+		t.Error("refresh rewrote the report with no manifest changed")
+	}
+	writeFiles(t, root, map[string]string{"go.mod": "module x\n\nrequire github.com/a/b v1.1.0\n"})
+	report, written, _ = RefreshDependencies(root)
+	if !written || report.Dependencies[0].Version != "v1.1.0" {
+		t.Errorf("after go.mod changed: written %v, %+v", written, report.Dependencies)
+	}
+}
@@ -0,0 +1,584 @@
+package analysis
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ModuleNode is one module of the dependency graph: a directory of source
+// files, which is what Go calls a package
+type ModuleNode struct {
+	Path      string   `json:"path"` // Directory relative to the project, "." for the root
+	Language  string   `json:"language"`
+	Files     []string `json:"files"`
+	DependsOn []string `json:"depends_on,omitempty"` // Modules this one imports
+	UsedBy    []string `json:"used_by,omitempty"`    // Modules that import this one
+	Entry     bool     `json:"entry,omitempty"`      // Holds a program entry point
+	Doc       bool     `json:"doc,omitempty"`        // Has a package comment or README
+}
+
+// FileNode is one source file of the dependency graph
+type FileNode struct {
+	Path       string   `json:"path"`
+	Module     string   `json:"module"`
+	Imports    []string `json:"imports,omitempty"`     // Project files it imports; for Go, package directories
+	ImportedBy []string `json:"imported_by,omitempty"` // Files that import it or its package
+}
+
+// DependencyGraph holds the imports between a project's own files and
+// modules. Imports of third-party code are left out.
+type DependencyGraph struct {
+	Generated time.Time    `json:"generated"`
+	Files     []FileNode   `json:"files"`
+	Modules   []ModuleNode `json:"modules"`
+	Cycles    [][]string   `json:"cycles,omitempty"` // Modules that import each other, directly or not
+}
+
+// DeadCodeCandidate is code nothing in the project seems to use. Candidates
+// come from name matching, so reflection, generated code and external users
+// can make them false positives.
+type DeadCodeCandidate struct {
+	Path   string `json:"path"` // File, or module directory for a whole module
+	Symbol string `json:"symbol,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// maxDeadCodeCandidates caps how many candidates a run reports
+const maxDeadCodeCandidates = 200
+
+var (
+	jsImportPattern = regexp.MustCompile(`(?:from\s*|require\(\s*|import\s*\(?\s*)['"](\.{1,2}/[^'"]*)['"]`)
+	pyImportPattern = regexp.MustCompile(`^\s*(?:from\s+(\.*[\w.]*)\s+import|import\s+([\w.]+))`)
+)
+
+// BuildDependencyGraph builds the dependency graph of the project's files
+// from source. It does not save it; analysis runs keep graph.json current.
+func BuildDependencyGraph(projectPath string) (*DependencyGraph, error) {
+	files, err := GetProjectFiles(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project files: %w", err)
+	}
+	_, goFiles := parseGoFiles(projectPath, files, goImportsMode)
+	return buildDependencyGraph(projectPath, files, goFiles), nil
+}
+
+// LoadDependencyGraph reads the graph the last analysis run saved
+func LoadDependencyGraph(projectPath string) (*DependencyGraph, error) {
+	data, err := os.ReadFile(filepath.Join(knowledgeDir(projectPath), graphFile))
+	if err != nil {
+		return nil, err
+	}
+	var graph DependencyGraph
+	if err := json.Unmarshal(data, &graph); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", graphFile, err)
+	}
+	return &graph, nil
+}
+
+// graphFile is the name of the saved graph at the knowledge root
+const graphFile = "graph.json"
+
+// updateDependencyGraph builds the graph and saves it at the knowledge root
+func (s *service) updateDependencyGraph(projectPath string, files []string, goFiles map[string]*ast.File) *DependencyGraph {
+	graph := buildDependencyGraph(projectPath, files, goFiles)
+	_ = s.saveKnowledgeRootJSON(projectPath, graphFile, graph)
+	return graph
+}
+
+// buildDependencyGraph reads the imports of every source file and links the
+// files and modules they point to. Go imports are parsed; JavaScript,
+// TypeScript and Python imports are matched by pattern. Test files are left
+// out so code used only by tests still shows as unused.
+func buildDependencyGraph(projectPath string, files []string, goFiles map[string]*ast.File) *DependencyGraph {
+	modulePath := readGoModulePath(projectPath)
+
+	nodes := map[string]*ModuleNode{}
+	known := map[string]bool{}
+	for _, file := range files {
+		language := detectLanguageForFile(file)
+		if !isGraphLanguage(language) || isTestFile(file) {
+			continue
+		}
+		file = filepath.ToSlash(file)
+		dir := path.Dir(file)
+		node, ok := nodes[dir]
+		if !ok {
+			node = &ModuleNode{Path: dir, Language: language}
+			nodes[dir] = node
+		}
+		node.Files = append(node.Files, file)
+		known[file] = true
+		if isEntryFile(file) {
+			node.Entry = true
+		}
+	}
+	for _, file := range files {
+		if node, ok := nodes[path.Dir(filepath.ToSlash(file))]; ok && strings.EqualFold(path.Base(file), "README.md") {
+			node.Doc = true
+		}
+	}
+
+	// Link files to what they import: a Go file imports package
+	// directories, a script imports files or package directories
+	fileImports := map[string][]string{}
+	importedBy := map[string]map[string]bool{}
+	moduleImports := map[string]map[string]bool{}
+	for dir, node := range nodes {
+		moduleImports[dir] = map[string]bool{}
+		for _, file := range node.Files {
+			var targets []string
+			if parsed, ok := goFiles[file]; ok {
+				if parsed.Name.Name == "main" {
+					node.Entry = true
+				}
+				if parsed.Doc != nil {
+					node.Doc = true
+				}
+				targets = goImportTargets(parsed, modulePath)
+			} else {
+				targets = scriptImportTargets(projectPath, file, nodes, known)
+			}
+
+			seen := map[string]bool{}
+			for _, target := range targets {
+				targetModule := target
+				if known[target] {
+					targetModule = path.Dir(target)
+				}
+				imported, ok := nodes[targetModule]
+				if !ok || target == file || seen[target] {
+					continue
+				}
+				seen[target] = true
+				fileImports[file] = append(fileImports[file], target)
+				if targetModule != dir {
+					moduleImports[dir][targetModule] = true
+				}
+				importedFiles := imported.Files
+				if known[target] {
+					importedFiles = []string{target}
+				}
+				for _, importedFile := range importedFiles {
+					if importedFile == file {
+						continue
+					}
+					if importedBy[importedFile] == nil {
+						importedBy[importedFile] = map[string]bool{}
+					}
+					importedBy[importedFile][file] = true
+				}
+			}
+		}
+	}
+
+	graph := &DependencyGraph{Generated: analysisStamp(projectPath)}
+	for dir, node := range nodes {
+		for target := range moduleImports[dir] {
+			node.DependsOn = append(node.DependsOn, target)
+			nodes[target].UsedBy = append(nodes[target].UsedBy, dir)
+		}
+	}
+	for _, node := range nodes {
+		sort.Strings(node.Files)
+		sort.Strings(node.DependsOn)
+		sort.Strings(node.UsedBy)
+		graph.Modules = append(graph.Modules, *node)
+		for _, file := range node.Files {
+			fileNode := FileNode{Path: file, Module: node.Path, Imports: fileImports[file]}
+			for importer := range importedBy[file] {
+				fileNode.ImportedBy = append(fileNode.ImportedBy, importer)
+			}
+			sort.Strings(fileNode.Imports)
+			sort.Strings(fileNode.ImportedBy)
+			graph.Files = append(graph.Files, fileNode)
+		}
+	}
+	sort.Slice(graph.Modules, func(i, j int) bool { return graph.Modules[i].Path < graph.Modules[j].Path })
+	sort.Slice(graph.Files, func(i, j int) bool { return graph.Files[i].Path < graph.Files[j].Path })
+	graph.Cycles = findCycles(graph.Modules)
+	return graph
+}
+
+// File returns the node of a file, or nil
+func (g *DependencyGraph) File(path string) *FileNode {
+	path = cleanGraphPath(path)
+	for i := range g.Files {
+		if g.Files[i].Path == path {
+			return &g.Files[i]
+		}
+	}
+	return nil
+}
+
+// Module returns the node of a module directory, or nil
+func (g *DependencyGraph) Module(path string) *ModuleNode {
+	path = cleanGraphPath(path)
+	for i := range g.Modules {
+		if g.Modules[i].Path == path {
+			return &g.Modules[i]
+		}
+	}
+	return nil
+}
+
+// FanOut returns what a file or module imports from the project
+func (g *DependencyGraph) FanOut(path string) []string {
+	if file := g.File(path); file != nil {
+		return file.Imports
+	}
+	if module := g.Module(path); module != nil {
+		return module.DependsOn
+	}
+	return nil
+}
+
+// FanIn returns the files importing a file, or the modules importing a module
+func (g *DependencyGraph) FanIn(path string) []string {
+	if file := g.File(path); file != nil {
+		return file.ImportedBy
+	}
+	if module := g.Module(path); module != nil {
+		return module.UsedBy
+	}
+	return nil
+}
+
+// MostImported returns up to n files with the highest fan-in, the ones a
+// change ripples furthest from
+func (g *DependencyGraph) MostImported(n int) []FileNode {
+	files := append([]FileNode{}, g.Files...)
+	sort.SliceStable(files, func(i, j int) bool { return len(files[i].ImportedBy) > len(files[j].ImportedBy) })
+	return files[:min(n, len(files))]
+}
+
+func cleanGraphPath(p string) string {
+	p = path.Clean(filepath.ToSlash(p))
+	return strings.TrimPrefix(p, "./")
+}
+
+// HasEntryPoint reports whether the project builds a program rather than
+// only a library, so unused exported code can be flagged too
+func (g *DependencyGraph) HasEntryPoint() bool {
+	for _, module := range g.Modules {
+		if module.Entry {
+			return true
+		}
+	}
+	return false
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func (g *DependencyGraph) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("```mermaid\ngraph LR\n")
+	ids := map[string]string{}
+	for i, module := range g.Modules {
+		ids[module.Path] = fmt.Sprintf("m%d", i)
+		sb.WriteString(fmt.Sprintf("  %s[%q]\n", ids[module.Path], module.Path))
+	}
+	for _, module := range g.Modules {
+		for _, dep := range module.DependsOn {
+			sb.WriteString(fmt.Sprintf("  %s --> %s\n", ids[module.Path], ids[dep]))
+		}
+	}
+	sb.WriteString("```\n")
+	return sb.String()
+}
+
+func isGraphLanguage(language string) bool {
+	switch language {
+	case "Go", "JavaScript", "TypeScript", "Python":
+		return true
+	}
+	return false
+}
+
+func isTestFile(file string) bool {
+	base := path.Base(filepath.ToSlash(file))
+	return strings.HasSuffix(base, "_test.go") ||
+		strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		(strings.HasPrefix(base, "test_") && strings.HasSuffix(base, ".py")) ||
+		strings.HasSuffix(base, "_test.py")
+}
+
+func isEntryFile(file string) bool {
+	switch path.Base(filepath.ToSlash(file)) {
+	case "index.js", "index.ts", "main.js", "main.ts", "cli.js", "cli.ts",
+		"main.py", "__main__.py", "app.py", "manage.py":
+		return true
+	}
+	return false
+}
+
+// readGoModulePath returns the module path declared in go.mod, if any
+func readGoModulePath(projectPath string) string {
+	f, err := os.Open(filepath.Join(projectPath, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// Parser modes: the graph needs only package clauses and imports, dead code
+// detection needs whole files
+const (
+	goImportsMode = parser.ImportsOnly | parser.ParseComments
+	goSourceMode  = parser.ParseComments | parser.SkipObjectResolution
+)
+
+// parseGoFiles parses the project's Go files, tests included. Files that
+// fail to parse are skipped.
+func parseGoFiles(projectPath string, files []string, mode parser.Mode) (*token.FileSet, map[string]*ast.File) {
+	fset := token.NewFileSet()
+	parsed := map[string]*ast.File{}
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(projectPath, file), nil, mode)
+		if err == nil {
+			parsed[file] = f
+		}
+	}
+	return fset, parsed
+}
+
+// goImportTargets maps a Go file's imports of its own module to directories
+func goImportTargets(f *ast.File, modulePath string) []string {
+	if modulePath == "" {
+		return nil
+	}
+	var targets []string
+	for _, spec := range f.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if importPath == modulePath {
+			targets = append(targets, ".")
+		} else if rest, ok := strings.CutPrefix(importPath, modulePath+"/"); ok {
+			targets = append(targets, rest)
+		}
+	}
+	return targets
+}
+
+// scriptImportExts are tried in order to resolve an import without one
+var scriptImportExts = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".py", "/index.ts", "/index.js", "/__init__.py"}
+
+// scriptImportTargets resolves the relative imports of a JavaScript,
+// TypeScript or Python file to project files, or to module directories when
+// no single file matches
+func scriptImportTargets(projectPath, file string, nodes map[string]*ModuleNode, known map[string]bool) []string {
+	content, err := readFileHead(filepath.Join(projectPath, file), 2000)
+	if err != nil {
+		return nil
+	}
+	dir := path.Dir(file)
+	var targets []string
+	resolve := func(resolved string) {
+		if known[resolved] {
+			targets = append(targets, resolved)
+			return
+		}
+		for _, ext := range scriptImportExts {
+			if known[resolved+ext] {
+				targets = append(targets, resolved+ext)
+				return
+			}
+		}
+		if _, ok := nodes[resolved]; ok {
+			targets = append(targets, resolved)
+		}
+	}
+
+	if detectLanguageForFile(file) != "Python" {
+		for _, match := range jsImportPattern.FindAllStringSubmatch(content, -1) {
+			resolve(path.Clean(path.Join(dir, match[1])))
+		}
+		return targets
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		match := pyImportPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		name := match[1] + match[2]
+		base := "."
+		if trimmed := strings.TrimLeft(name, "."); len(trimmed) < len(name) {
+			// Relative import: one dot is this package, each more goes up
+			base = dir
+			for range len(name) - len(trimmed) - 1 {
+				base = path.Dir(base)
+			}
+			name = trimmed
+		}
+		resolve(path.Clean(path.Join(base, strings.ReplaceAll(name, ".", "/"))))
+	}
+	return targets
+}
+
+// findCycles returns the groups of modules that import each other, using
+// Tarjan's strongly connected components
+func findCycles(modules []ModuleNode) [][]string {
+	edges := map[string][]string{}
+	for _, module := range modules {
+		edges[module.Path] = module.DependsOn
+	}
+
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var cycles [][]string
+	next := 0
+
+	var visit func(string)
+	visit = func(v string) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range edges[v] {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var component []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		if len(component) > 1 {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, module := range modules {
+		if _, seen := index[module.Path]; !seen {
+			visit(module.Path)
+		}
+	}
+	return cycles
+}
+
+// findDeadCode lists modules nothing imports and top-level Go functions and
+// types nothing refers to. Exported code only counts when the project is a
+// program, as a library's exports are meant for others.
+func findDeadCode(graph *DependencyGraph, fset *token.FileSet, goFiles map[string]*ast.File) []DeadCodeCandidate {
+	program := graph.HasEntryPoint()
+	var candidates []DeadCodeCandidate
+
+	if program {
+		for _, module := range graph.Modules {
+			if len(module.UsedBy) == 0 && !module.Entry {
+				candidates = append(candidates, DeadCodeCandidate{
+					Path:   module.Path,
+					Reason: "module is not imported by any other module and has no entry point",
+				})
+			}
+		}
+	}
+
+	// Count every identifier outside tests; a declaration whose name shows
+	// up only once is referenced nowhere but its own declaration
+	uses := map[string]int{}
+	for file, f := range goFiles {
+		if isTestFile(file) {
+			continue
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok {
+				uses[ident.Name]++
+			}
+			return true
+		})
+	}
+
+	files := make([]string, 0, len(goFiles))
+	for file := range goFiles {
+		if !isTestFile(file) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		f := goFiles[file]
+		for _, decl := range f.Decls {
+			for _, name := range declaredNames(decl) {
+				if uses[name.Name] > 1 || name.Name == "_" || (name.IsExported() && !program) {
+					continue
+				}
+				candidates = append(candidates, DeadCodeCandidate{
+					Path:   file,
+					Symbol: name.Name,
+					Line:   fset.Position(name.Pos()).Line,
+					Reason: "declared but never referenced",
+				})
+			}
+		}
+	}
+
+	if len(candidates) > maxDeadCodeCandidates {
+		candidates = candidates[:maxDeadCodeCandidates]
+	}
+	return candidates
+}
+
+// declaredNames returns the top-level functions and types a declaration
+// introduces. Methods are left out as they may satisfy an interface.
+func declaredNames(decl ast.Decl) []*ast.Ident {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv != nil || slices.Contains([]string{"main", "init"}, d.Name.Name) {
+			return nil
+		}
+		return []*ast.Ident{d.Name}
+	case *ast.GenDecl:
+		if d.Tok != token.TYPE {
+			return nil
+		}
+		var names []*ast.Ident
+		for _, spec := range d.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok {
+				names = append(names, ts.Name)
+			}
+		}
+		return names
+	}
+	return nil
+}
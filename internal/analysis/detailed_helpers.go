@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 
+	"github.com/billie-coop/loco/internal/crash"
 	"github.com/billie-coop/loco/internal/llm"
 )
 
@@ -52,6 +54,7 @@ func (s *service) generateDetailedFileSummaries(ctx context.Context, projectPath
 
 	// For detailed analysis, we analyze key files more thoroughly
 	summaries := []FileSummary{}
+	parsed := map[string]*staticFacts{}
 
 	// First, add quick summaries for all files (structure)
 	for i, file := range files {
@@ -64,18 +67,23 @@ func (s *service) generateDetailedFileSummaries(ctx context.Context, projectPath
 		// If we have content for this file, analyze it more deeply
 		if content, ok := fileContents[file]; ok {
 			summary.Size = len(content)
-			// We'll analyze these in detail below
+			// We'll analyze these in detail below, telling the model what
+			// parsing already settled
+			if facts := extractStaticFacts(projectPath, file); facts != nil {
+				summary.Package, summary.Exports, summary.Imports = facts.Package, facts.Exports, facts.Imports
+				parsed[file] = facts
+			}
 		} else {
 			// Basic summary based on filename
 			summary.Purpose = fmt.Sprintf("File: %s", filepath.Base(file))
-			summary.Summary = fmt.Sprintf("%s file in %s", classifyFileType(file), filepath.Dir(file))
+			summary.Summary = fmt.Sprintf("%s file in %s", classifyFileType(file), path.Dir(file))
 			summary.Importance = estimateImportance(file)
 		}
 
 		summaries = append(summaries, summary)
 
 		// Progress for structure pass
-		ReportProgress(ctx, Progress{
+		s.reportProgress(ctx, Progress{
 			Phase:          string(TierDetailed),
 			TotalFiles:     len(files),
 			CompletedFiles: i + 1,
@@ -83,14 +91,36 @@ func (s *service) generateDetailedFileSummaries(ctx context.Context, projectPath
 		})
 	}
 
-	// Now analyze key files with content in parallel
+	// Now analyze key files with content in parallel, skipping files an
+	// interrupted run already summarized
+	checkpoint := s.loadCheckpoint(projectPath, TierDetailed)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	processed := 0
 
 	for file, content := range fileContents {
+		if saved, ok := checkpoint.lookup(file, content); ok {
+			for i := range summaries {
+				if summaries[i].Path == file {
+					summaries[i].Purpose = saved.Purpose
+					summaries[i].Importance = saved.Importance
+					summaries[i].Summary = saved.Summary
+					break
+				}
+			}
+			processed++
+			s.reportProgress(ctx, Progress{
+				Phase:          string(TierDetailed),
+				TotalFiles:     len(fileContents),
+				CompletedFiles: processed,
+				CurrentFile:    file + " (from checkpoint)",
+			})
+			continue
+		}
+
 		wg.Add(1)
-		go func(f string, c string) {
+		go func(f string, c string, facts *staticFacts) {
+			defer crash.Recover("detailed analysis worker")
 			defer wg.Done()
 
 			prompt := fmt.Sprintf(`Analyze this file in detail:
@@ -108,6 +138,24 @@ Provide a JSON response:
   "exports": ["list", "of", "exports"],
   "patterns": ["design", "patterns", "used"]
 }`, f, c)
+			if facts != nil {
+				// Imports and exports are known, so the model only
+				// describes them
+				prompt = fmt.Sprintf(`Analyze this file in detail:
+File: %s
+
+%s
+Content:
+%s
+
+Provide a JSON response:
+{
+  "purpose": "Detailed purpose of this file",
+  "importance": 8,  // 1-10 scale
+  "summary": "Comprehensive summary of functionality, naming only the exports listed above",
+  "patterns": ["design", "patterns", "used"]
+}`, f, facts.prompt(), c)
+			}
 
 			messages := []llm.Message{
 				{
@@ -147,13 +195,16 @@ Provide a JSON response:
 							summaries[i].Purpose = detailed.Purpose
 							summaries[i].Importance = detailed.Importance
 							summaries[i].Summary = detailed.Summary
+							checkpoint.record(f, c, summaries[i])
 							break
 						}
 					}
 					processed++
+					// Save as we go so an interrupted run can resume
+					_ = s.saveCheckpoint(projectPath, TierDetailed, checkpoint)
 					mu.Unlock()
 					// Progress for detailed content pass
-					ReportProgress(ctx, Progress{
+					s.reportProgress(ctx, Progress{
 						Phase:          string(TierDetailed),
 						TotalFiles:     len(fileContents),
 						CompletedFiles: processed,
@@ -161,7 +212,7 @@ Provide a JSON response:
 					})
 				}
 			}
-		}(file, content)
+		}(file, content, parsed[file])
 	}
 
 	wg.Wait()
@@ -189,7 +240,7 @@ func (s *service) generateKnowledgeDocumentsWithSkepticism(
 
 	// Generate with skepticism prompts if we have previous results
 	if previousKnowledge != nil && len(previousKnowledge) > 0 {
-		return s.generateKnowledgeDocumentsSkeptic(ctx, projectPath, fileSummaries, previousKnowledge)
+		return s.generateKnowledgeDocumentsSkeptic(ctx, projectPath, fileSummaries, tier, previousKnowledge)
 	}
 
 	// Otherwise generate normally
@@ -197,10 +248,12 @@ func (s *service) generateKnowledgeDocumentsWithSkepticism(
 }
 
 // generateKnowledgeDocumentsSkeptic creates knowledge docs while questioning previous tier.
+// Sections a person marked as verified are handed to the model to keep as they are.
 func (s *service) generateKnowledgeDocumentsSkeptic(
 	ctx context.Context,
 	projectPath string,
 	fileSummaries *FileAnalysisResult,
+	tier Tier,
 	previousKnowledge map[string]string,
 ) (map[string]string, error) {
 	if s.llmClient == nil {
@@ -210,9 +263,13 @@ func (s *service) generateKnowledgeDocumentsSkeptic(
 	knowledgeFiles := make(map[string]string)
 	summariesJSON, _ := json.MarshalIndent(fileSummaries, "", "  ")
 	summariesStr := string(summariesJSON)
+	previous := func(name string) (string, []verifiedSection) {
+		return stripFrontmatter(previousKnowledge[name]), s.loadVerifiedSections(projectPath, tier, name)
+	}
 
 	// Step 1: Refine structure.md with skepticism
-	structureContent, err := s.refineStructureDoc(ctx, summariesStr, previousKnowledge["structure.md"])
+	previousStructure, verifiedStructure := previous("structure.md")
+	structureContent, err := s.refineStructureDoc(ctx, projectPath, summariesStr, previousStructure, verifiedStructure)
 	if err != nil {
 		return nil, fmt.Errorf("failed to refine structure.md: %w", err)
 	}
@@ -223,19 +280,23 @@ func (s *service) generateKnowledgeDocumentsSkeptic(
 	var patternsContent, contextContent string
 	var patternsErr, contextErr error
 
+	previousPatterns, verifiedPatterns := previous("patterns.md")
+	previousContext, verifiedContext := previous("context.md")
 	wg.Add(2)
 
 	go func() {
+		defer crash.Recover("detailed analysis")
 		defer wg.Done()
 		patternsContent, patternsErr = s.refinePatternsDoc(
-			ctx, summariesStr, structureContent, previousKnowledge["patterns.md"],
+			ctx, projectPath, summariesStr, structureContent, previousPatterns, verifiedPatterns,
 		)
 	}()
 
 	go func() {
+		defer crash.Recover("detailed analysis")
 		defer wg.Done()
 		contextContent, contextErr = s.refineContextDoc(
-			ctx, summariesStr, structureContent, previousKnowledge["context.md"],
+			ctx, projectPath, summariesStr, structureContent, previousContext, verifiedContext,
 		)
 	}()
 
@@ -252,9 +313,10 @@ func (s *service) generateKnowledgeDocumentsSkeptic(
 	knowledgeFiles["context.md"] = contextContent
 
 	// Step 3: Refine overview with all refined docs
+	previousOverview, verifiedOverview := previous("overview.md")
 	overviewContent, err := s.refineOverviewDoc(
-		ctx, summariesStr, structureContent, patternsContent, contextContent,
-		previousKnowledge["overview.md"],
+		ctx, projectPath, summariesStr, structureContent, patternsContent, contextContent,
+		previousOverview, verifiedOverview,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to refine overview.md: %w", err)
@@ -265,23 +327,10 @@ func (s *service) generateKnowledgeDocumentsSkeptic(
 }
 
 // Refinement methods with skepticism
-func (s *service) refineStructureDoc(ctx context.Context, fileSummaries, previousDoc string) (string, error) {
-	prompt := fmt.Sprintf(`You are refining a structure analysis. Be skeptical of the previous analysis.
-
-Previous structure.md:
-%s
-
-New detailed file analysis:
-%s
-
-Create an improved structure.md that:
-1. Corrects any misunderstandings in the previous version
-2. Adds more accurate details based on actual file contents
-3. Identifies the TRUE architecture (not assumed)
-4. Lists actual dependencies and relationships
-5. Highlights what the previous analysis got wrong
-
-Be critical and accurate. Format as proper markdown.`, previousDoc, fileSummaries)
+func (s *service) refineStructureDoc(ctx context.Context, projectPath string, fileSummaries, previousDoc string, verified []verifiedSection) (string, error) {
+	prompt := s.renderPrompt(projectPath, "refine_structure", map[string]any{
+		"Previous": previousDoc, "Summaries": fileSummaries,
+	}) + verifiedPromptBlock(verified)
 
 	messages := []llm.Message{
 		{
@@ -297,26 +346,10 @@ Be critical and accurate. Format as proper markdown.`, previousDoc, fileSummarie
 	return s.llmClient.Complete(ctx, messages)
 }
 
-func (s *service) refinePatternsDoc(ctx context.Context, fileSummaries, structureDoc, previousDoc string) (string, error) {
-	prompt := fmt.Sprintf(`You are refining a patterns analysis. Be skeptical of the previous analysis.
-
-Previous patterns.md:
-%s
-
-Refined structure:
-%s
-
-New file analysis:
-%s
-
-Create an improved patterns.md that:
-1. Identifies ACTUAL patterns from code (not guessed)
-2. Corrects pattern misidentifications
-3. Shows real code conventions used
-4. Identifies actual design patterns implemented
-5. Notes what the previous analysis assumed incorrectly
-
-Be precise and evidence-based. Format as proper markdown.`, previousDoc, structureDoc, fileSummaries)
+func (s *service) refinePatternsDoc(ctx context.Context, projectPath string, fileSummaries, structureDoc, previousDoc string, verified []verifiedSection) (string, error) {
+	prompt := s.renderPrompt(projectPath, "refine_patterns", map[string]any{
+		"Previous": previousDoc, "Structure": structureDoc, "Summaries": fileSummaries,
+	}) + verifiedPromptBlock(verified)
 
 	messages := []llm.Message{
 		{
@@ -332,26 +365,10 @@ Be precise and evidence-based. Format as proper markdown.`, previousDoc, structu
 	return s.llmClient.Complete(ctx, messages)
 }
 
-func (s *service) refineContextDoc(ctx context.Context, fileSummaries, structureDoc, previousDoc string) (string, error) {
-	prompt := fmt.Sprintf(`You are refining a context analysis. Be skeptical of the previous analysis.
-
-Previous context.md:
-%s
-
-Refined structure:
-%s
-
-New file analysis:
-%s
-
-Create an improved context.md that:
-1. Identifies the REAL purpose (from actual code)
-2. Corrects business logic misunderstandings
-3. Clarifies actual problem being solved
-4. Updates design decisions based on evidence
-5. Notes what the previous tier misunderstood
-
-Focus on accuracy over assumptions. Format as proper markdown.`, previousDoc, structureDoc, fileSummaries)
+func (s *service) refineContextDoc(ctx context.Context, projectPath string, fileSummaries, structureDoc, previousDoc string, verified []verifiedSection) (string, error) {
+	prompt := s.renderPrompt(projectPath, "refine_context", map[string]any{
+		"Previous": previousDoc, "Structure": structureDoc, "Summaries": fileSummaries,
+	}) + verifiedPromptBlock(verified)
 
 	messages := []llm.Message{
 		{
@@ -367,25 +384,10 @@ Focus on accuracy over assumptions. Format as proper markdown.`, previousDoc, st
 	return s.llmClient.Complete(ctx, messages)
 }
 
-func (s *service) refineOverviewDoc(ctx context.Context, fileSummaries, structureDoc, patternsDoc, contextDoc, previousDoc string) (string, error) {
-	prompt := fmt.Sprintf(`Create a refined overview incorporating all corrected analyses.
-
-Previous overview:
-%s
-
-Refined documents:
-- Structure: Corrected architecture understanding
-- Patterns: Actual patterns identified
-- Context: Real purpose clarified
-
-Create an improved overview.md that:
-1. Summarizes the corrected understanding
-2. Highlights key corrections made
-3. Provides accurate tech stack
-4. Gives truthful quick start guide
-5. Notes major refinements from previous tier
-
-Be comprehensive but accurate.`, previousDoc)
+func (s *service) refineOverviewDoc(ctx context.Context, projectPath string, fileSummaries, structureDoc, patternsDoc, contextDoc, previousDoc string, verified []verifiedSection) (string, error) {
+	prompt := s.renderPrompt(projectPath, "refine_overview", map[string]any{
+		"Previous": previousDoc, "Structure": structureDoc, "Patterns": patternsDoc, "Context": contextDoc,
+	}) + verifiedPromptBlock(verified)
 
 	messages := []llm.Message{
 		{
@@ -560,7 +562,7 @@ func detectEntryPoints(files []string, fileContents map[string]string) []string
 
 func extractArchitecture(structureDoc string) string {
 	// Extract first paragraph or summary from structure doc
-	lines := strings.Split(structureDoc, "\n")
+	lines := strings.Split(stripFrontmatter(structureDoc), "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 		if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "*") {
@@ -572,7 +574,7 @@ func extractArchitecture(structureDoc string) string {
 
 func extractPurpose(contextDoc string) string {
 	// Extract first paragraph or summary from context doc
-	lines := strings.Split(contextDoc, "\n")
+	lines := strings.Split(stripFrontmatter(contextDoc), "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 		if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "*") {
@@ -0,0 +1,46 @@
+package analysis
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/files"
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+// deterministic reports whether the project sets analysis.deterministic
+func deterministic(projectPath string) bool {
+	cfgMgr := config.NewManager(configRoot(projectPath))
+	_ = cfgMgr.Load()
+	cfg := cfgMgr.Get()
+	return cfg != nil && cfg.Analysis.Deterministic
+}
+
+// withDeterminism marks ctx for repeatable completions when the project is
+// in deterministic mode
+func withDeterminism(ctx context.Context, projectPath string) context.Context {
+	if deterministic(projectPath) {
+		return llm.WithDeterministic(ctx)
+	}
+	return ctx
+}
+
+// analysisStamp is the time written into knowledge files: now, or in
+// deterministic mode the HEAD commit's time, so reruns on a commit don't
+// differ by when they ran. Outside git it is the zero time.
+func analysisStamp(projectPath string) time.Time {
+	if !deterministic(projectPath) {
+		return time.Now()
+	}
+	out, err := files.Git(context.Background(), projectPath, "log", "-1", "--format=%cI")
+	if err != nil {
+		return time.Time{}
+	}
+	stamp, err := time.Parse(time.RFC3339, strings.TrimSpace(string(out)))
+	if err != nil {
+		return time.Time{}
+	}
+	return stamp.UTC()
+}
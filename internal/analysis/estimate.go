@@ -0,0 +1,277 @@
+package analysis
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/config"
+)
+
+// Rough sizes for estimates, in tokens, where the real size depends on the
+// model's answer
+const (
+	charsPerToken       = 4
+	promptOverhead      = 300  // Instructions around the data of a request
+	fileSummaryTokens   = 250  // A file's JSON summary
+	summaryEntryTokens  = 40   // A file's entry in the summaries handed to a knowledge doc
+	rankingLineTokens   = 30   // A ranked file in the adjudicator's input
+	knowledgeDocTokens  = 900  // structure.md, patterns.md, context.md or overview.md
+	moduleDocTokens     = 500  // One directory's doc
+	architectureTokens  = 2000 // ARCHITECTURE.md
+	workerRankingTokens = 600  // A quick worker's ranking, when max tokens is unset
+	adjudicationTokens  = 800  // The quick tier's adjudicated summary, when max tokens is unset
+	knowledgeDocs       = 4    // Knowledge docs written by the detailed and deep tiers
+)
+
+// promptSpeedup is how much faster a local model reads a prompt than it
+// writes an answer; the probe only measures writing
+const promptSpeedup = 10
+
+// ModelSpeed is the model a tier runs on and its measured output speed
+type ModelSpeed struct {
+	Model           string
+	TokensPerSecond float64 // 0 when the model hasn't been benchmarked
+}
+
+// Estimate is what running one tier would take
+type Estimate struct {
+	Tier            Tier          `json:"tier"`
+	Cached          bool          `json:"cached"`        // A fresh cached result would be used, so nothing runs
+	Files           int           `json:"files"`         // Project files the tier looks at
+	ContentFiles    int           `json:"content_files"` // Files whose content goes to the model
+	Modules         int           `json:"modules"`       // Directories that get a module doc, at most
+	Requests        int           `json:"requests"`
+	PromptTokens    int           `json:"prompt_tokens"`
+	OutputTokens    int           `json:"output_tokens"`
+	Model           string        `json:"model,omitempty"`
+	TokensPerSecond float64       `json:"tokens_per_second,omitempty"`
+	Duration        time.Duration `json:"duration,omitempty"` // Expected wall-clock time; 0 when the model's speed is unknown
+}
+
+// EstimateTier works out what running tier would take without calling a
+// model: for the tier and the tiers it builds on, the files analyzed, the
+// requests and tokens, and the wall-clock time given each model's measured
+// speed. Requests to a model are taken to run one at a time, as LM Studio
+// serves them, and module docs are counted as if all were rewritten.
+func EstimateTier(projectPath string, tier Tier, speeds map[Tier]ModelSpeed) ([]Estimate, error) {
+	var tiers []Tier
+	switch tier {
+	case TierInstant:
+		tiers = []Tier{TierInstant}
+	case TierQuick:
+		tiers = []Tier{TierQuick}
+	case TierDetailed:
+		tiers = []Tier{TierDetailed}
+	case TierDeep:
+		tiers = []Tier{TierDetailed, TierDeep}
+	case TierFull:
+		tiers = []Tier{TierDetailed, TierDeep, TierFull}
+	default:
+		return nil, fmt.Errorf("unknown tier %q (want instant, quick, detailed, deep or full)", tier)
+	}
+
+	files, err := GetProjectFiles(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project files: %w", err)
+	}
+	cfgMgr := config.NewManager(configRoot(projectPath))
+	_ = cfgMgr.Load()
+	cfg := cfgMgr.Get()
+
+	s := &service{cachePath: ".loco"}
+	estimates := make([]Estimate, 0, len(tiers))
+	for _, t := range tiers {
+		estimate := Estimate{Tier: t, Model: speeds[t].Model, TokensPerSecond: speeds[t].TokensPerSecond}
+		if !tierClean(cfg, t) {
+			if stale, err := s.IsStale(projectPath, t); err == nil && !stale {
+				estimate.Cached = true
+				estimates = append(estimates, estimate)
+				continue
+			}
+		}
+
+		switch t {
+		case TierInstant:
+			estimate.Files = len(files) // No model requests
+		case TierQuick:
+			estimateQuick(&estimate, files, cfg)
+		case TierDetailed:
+			estimateContentTier(&estimate, s, projectPath, files, selectKeyFiles(files), 500)
+		case TierDeep:
+			estimateContentTier(&estimate, s, projectPath, files, selectExtendedFiles(files, 50), 1000)
+		case TierFull:
+			estimate.Files = len(files)
+			// The deep run before it has summarized its files by then
+			estimate.Modules = countModules(s, projectPath, selectExtendedFiles(files, 50))
+			estimate.addRequests(estimate.Modules, promptOverhead+20*summaryEntryTokens, moduleDocTokens)
+			estimate.addRequests(1, promptOverhead+knowledgeDocTokens+estimate.Modules*moduleDocTokens/2, architectureTokens)
+		}
+		if estimate.TokensPerSecond > 0 {
+			seconds := float64(estimate.OutputTokens)/estimate.TokensPerSecond +
+				float64(estimate.PromptTokens)/(estimate.TokensPerSecond*promptSpeedup)
+			estimate.Duration = time.Duration(seconds * float64(time.Second)).Round(time.Second)
+		}
+		estimates = append(estimates, estimate)
+	}
+	return estimates, nil
+}
+
+// addRequests counts n requests of the given prompt and output size
+func (e *Estimate) addRequests(n, promptTokens, outputTokens int) {
+	e.Requests += n
+	e.PromptTokens += n * promptTokens
+	e.OutputTokens += n * outputTokens
+}
+
+// tierClean reports whether the tier's clean flag skips its cache
+func tierClean(cfg *config.Config, tier Tier) bool {
+	if cfg == nil {
+		return false
+	}
+	switch tier {
+	case TierQuick:
+		return cfg.Analysis.Quick.Clean
+	case TierDetailed:
+		return cfg.Analysis.Detailed.Clean
+	case TierDeep:
+		return cfg.Analysis.Deep.Clean
+	case TierFull:
+		return cfg.Analysis.Full.Clean
+	}
+	return false
+}
+
+// estimateQuick counts the quick tier's ranking workers, each reading its
+// share of the file list, and the adjudicator
+func estimateQuick(e *Estimate, files []string, cfg *config.Config) {
+	var qc config.AnalysisQuickConfig
+	if cfg != nil {
+		qc = cfg.Analysis.Quick
+	}
+	workers := qc.Workers
+	if workers <= 0 {
+		workers = 5
+	}
+	maxPaths := qc.MaxPathsPerCall
+	if maxPaths <= 0 {
+		maxPaths = 400
+	}
+	perWorkerTop := qc.TopFileRankingCount
+	if perWorkerTop <= 0 {
+		perWorkerTop = 20
+	}
+
+	filtered := prefilterForRanking(files)
+	e.Files = len(filtered)
+	pathChars := 0
+	for _, shard := range shardFiles(filtered, workers, maxPaths, qc.Sharding) {
+		for _, file := range shard {
+			pathChars += len(file) + 1
+		}
+	}
+
+	workerOutput := workerRankingTokens
+	if qc.MaxCompletionTokensWorker > 0 {
+		workerOutput = qc.MaxCompletionTokensWorker
+	}
+	e.addRequests(workers, promptOverhead+pathChars/workers/charsPerToken, workerOutput)
+
+	if qc.NaturalLanguageWorkers || qc.UseModelAdjudicator {
+		adjudicatorOutput := adjudicationTokens
+		if qc.MaxCompletionTokensAdjudicator > 0 {
+			adjudicatorOutput = qc.MaxCompletionTokensAdjudicator
+		}
+		e.addRequests(1, promptOverhead+workers*perWorkerTop*rankingLineTokens, adjudicatorOutput)
+	}
+}
+
+// estimateContentTier counts a tier that summarizes the content of some
+// files, writes the knowledge docs from all the summaries and documents
+// the directories
+func estimateContentTier(e *Estimate, s *service, projectPath string, files, contentFiles []string, maxLines int) {
+	e.Files = len(files)
+	for _, file := range contentFiles {
+		content, err := readFileHead(filepath.Join(projectPath, file), maxLines)
+		if err != nil {
+			continue
+		}
+		e.ContentFiles++
+		e.addRequests(1, promptOverhead+len(content)/charsPerToken, fileSummaryTokens)
+	}
+
+	summaries := len(files)*summaryEntryTokens + e.ContentFiles*fileSummaryTokens
+	e.addRequests(knowledgeDocs, promptOverhead+summaries+knowledgeDocTokens, knowledgeDocTokens)
+
+	e.Modules = countModules(s, projectPath, contentFiles)
+	e.addRequests(e.Modules, promptOverhead+20*summaryEntryTokens, moduleDocTokens)
+}
+
+// countModules returns how many directories would get a module doc from the
+// canonical summaries, with summarized the files a run would add to them
+func countModules(s *service, projectPath string, summarized []string) int {
+	summaries := s.loadCanonicalSummaries(projectPath)
+	for _, file := range summarized {
+		if _, ok := summaries[file]; !ok {
+			summaries[file] = canonicalFileSummary{Path: file, Summary: "pending"}
+		}
+	}
+	graph, err := BuildDependencyGraph(projectPath)
+	if err != nil {
+		return 0
+	}
+	return len(selectModuleDirectories(summaries, graph))
+}
+
+// FormatEstimates describes estimates for a person deciding whether to run
+// the analysis
+func FormatEstimates(estimates []Estimate) string {
+	var sb strings.Builder
+	var requests, tokens int
+	var total time.Duration
+	unknownSpeed := false
+	sb.WriteString("Dry run: no model was called.\n\n")
+	for _, e := range estimates {
+		fmt.Fprintf(&sb, "%s", e.Tier)
+		if e.Model != "" {
+			fmt.Fprintf(&sb, " (%s", e.Model)
+			if e.TokensPerSecond > 0 {
+				fmt.Fprintf(&sb, ", %.1f tok/s", e.TokensPerSecond)
+			}
+			sb.WriteString(")")
+		}
+		sb.WriteString(":\n")
+		if e.Cached {
+			sb.WriteString("  up to date; the cached result would be used\n\n")
+			continue
+		}
+		fmt.Fprintf(&sb, "  files: %d", e.Files)
+		if e.ContentFiles > 0 {
+			fmt.Fprintf(&sb, " (%d read in full)", e.ContentFiles)
+		}
+		if e.Modules > 0 {
+			fmt.Fprintf(&sb, ", %d module docs", e.Modules)
+		}
+		fmt.Fprintf(&sb, "\n  requests: %d, ~%d prompt + ~%d output tokens\n", e.Requests, e.PromptTokens, e.OutputTokens)
+		if e.Duration > 0 {
+			fmt.Fprintf(&sb, "  time: ~%s\n", e.Duration)
+		} else {
+			sb.WriteString("  time: unknown (the model's speed hasn't been measured)\n")
+			unknownSpeed = true
+		}
+		sb.WriteString("\n")
+		requests += e.Requests
+		tokens += e.PromptTokens + e.OutputTokens
+		total += e.Duration
+	}
+	fmt.Fprintf(&sb, "Total: %d requests, ~%d tokens", requests, tokens)
+	if total > 0 {
+		fmt.Fprintf(&sb, ", ~%s", total)
+		if unknownSpeed {
+			sb.WriteString(" plus the unmeasured tiers")
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -1,50 +1,76 @@
 package analysis
 
 import (
-	"os/exec"
-	"path/filepath"
+	"context"
+	"path"
 	"strings"
+
+	"github.com/billie-coop/loco/internal/files"
 )
 
-// GetProjectFiles returns all git-tracked files in the project.
+// GetProjectFiles returns the files to analyze in the project: what git
+// lists in a repository, or everything under the project directory outside
+// one. Project files are named by slash-separated paths relative to the
+// project root on every platform, as git prints them; only code that opens
+// a file converts one, with filepath.Join.
 func GetProjectFiles(projectPath string) ([]string, error) {
-	// Try git ls-files first
-	cmd := exec.Command("git", "ls-files")
-	cmd.Dir = projectPath
-	
-	output, err := cmd.Output()
+	return listProjectFiles(files.ForDir(context.Background(), projectPath))
+}
+
+// getProjectFilesWalk lists the files under a project that isn't a git
+// repository
+func getProjectFilesWalk(projectPath string) ([]string, error) {
+	return listProjectFiles(files.NewWalkProvider(projectPath))
+}
+
+// listProjectFiles returns the provider's files worth analyzing
+func listProjectFiles(provider files.Provider) ([]string, error) {
+	listed, err := provider.Files(context.Background())
 	if err != nil {
-		// Fallback to walking the directory if git fails
-		return getProjectFilesWalk(projectPath)
+		return nil, err
 	}
-	
-	// Parse the output
-	lines := strings.Split(string(output), "\n")
-	files := []string{}
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		
-		// Filter out files we don't want to analyze
-		if shouldAnalyzeFile(line) {
-			files = append(files, line)
+	analyzed := []string{}
+	for _, file := range listed {
+		if shouldAnalyzeFile(file) {
+			analyzed = append(analyzed, file)
 		}
 	}
-	
-	return files, nil
+	return analyzed, nil
 }
 
-// getProjectFilesWalk walks the directory tree as a fallback.
-func getProjectFilesWalk(projectPath string) ([]string, error) {
-	// TODO: Implement directory walking fallback
-	return []string{}, nil
+// projectFileSet returns every file in the project, analyzed or not, for
+// checking paths a model named
+func projectFileSet(projectPath string) map[string]struct{} {
+	set := map[string]struct{}{}
+	listed, _ := files.ForDir(context.Background(), projectPath).Files(context.Background())
+	for _, file := range listed {
+		set[file] = struct{}{}
+	}
+	return set
+}
+
+// projectStateHash fingerprints the project's state: git status and HEAD
+// in a repository, every file's content outside one
+func projectStateHash(projectPath string) (string, error) {
+	return files.ForDir(context.Background(), projectPath).StateHash(context.Background())
+}
+
+// isGitRepo reports whether the project is in a git work tree
+func isGitRepo(projectPath string) bool {
+	_, ok := files.ForDir(context.Background(), projectPath).(*files.GitProvider)
+	return ok
+}
+
+// skippedDirs are dependency, build and tool directories left out of
+// analysis wherever they are in the tree
+var skippedDirs = map[string]bool{
+	"node_modules": true, "vendor": true, ".git": true,
+	"dist": true, "build": true, "target": true,
+	"__pycache__": true, ".pytest_cache": true,
 }
 
-// shouldAnalyzeFile determines if a file should be analyzed.
-func shouldAnalyzeFile(path string) bool {
+// shouldAnalyzeFile determines if a project file should be analyzed.
+func shouldAnalyzeFile(file string) bool {
 	// Skip binary and image files
 	skipExts := []string{
 		".png", ".jpg", ".jpeg", ".gif", ".svg", ".ico",
@@ -53,27 +79,22 @@ func shouldAnalyzeFile(path string) bool {
 		".pyc", ".pyo", ".class", ".o",
 		".lock", ".sum",
 	}
-	
-	ext := strings.ToLower(filepath.Ext(path))
+
+	ext := strings.ToLower(path.Ext(file))
 	for _, skip := range skipExts {
 		if ext == skip {
 			return false
 		}
 	}
-	
-	// Skip vendor and build directories
-	skipPaths := []string{
-		"node_modules/", "vendor/", ".git/",
-		"dist/", "build/", "target/",
-		"__pycache__/", ".pytest_cache/",
-	}
-	
-	for _, skip := range skipPaths {
-		if strings.Contains(path, skip) {
+
+	// Skip vendor and build directories, matching whole path components so
+	// "vendor/" skips vendor/x but not myvendor/x
+	for _, dir := range strings.Split(path.Dir(file), "/") {
+		if skippedDirs[dir] {
 			return false
 		}
 	}
-	
+
 	return true
 }
 
@@ -85,6 +106,11 @@ type FileSummary struct {
 	Summary    string  `json:"summary"`
 	FileType   string  `json:"file_type"`
 	Size       int     `json:"size"`
+
+	// Parsed from the source where the language has a static extractor
+	Package string   `json:"package,omitempty"`
+	Exports []string `json:"exports,omitempty"`
+	Imports []string `json:"imports,omitempty"`
 }
 
 // FileAnalysisResult contains all file summaries.
@@ -0,0 +1,194 @@
+package analysis
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// projectTree is a project layout exercising nested directories, skipped
+// directories and names git would otherwise quote
+var projectTree = []string{
+	"main.go",
+	"internal/app/app.go",
+	"docs/read me.md",
+	"docs/café.md",
+	"myvendor/lib.go",
+	"vendor/dep/dep.go",
+	"web/node_modules/pkg/index.js",
+	"assets/logo.png",
+}
+
+// wantProjectFiles is what analysis should list from projectTree: slash
+// paths on every platform, with skipped directories and binaries left out
+var wantProjectFiles = []string{
+	"docs/café.md",
+	"docs/read me.md",
+	"internal/app/app.go",
+	"main.go",
+	"myvendor/lib.go",
+}
+
+// writeProjectTree creates the files under dir using the platform's
+// separators
+func writeProjectTree(t *testing.T, dir string) {
+	t.Helper()
+	for _, file := range projectTree {
+		writeFile(t, filepath.Join(dir, filepath.FromSlash(file)))
+	}
+}
+
+func TestGetProjectFilesGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	writeProjectTree(t, dir)
+	for _, args := range [][]string{{"init", "-q"}, {"add", "-A"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	files, err := GetProjectFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	if !reflect.DeepEqual(files, wantProjectFiles) {
+		t.Errorf("GetProjectFiles() = %q, want %q", files, wantProjectFiles)
+	}
+}
+
+func TestGetProjectFilesWalk(t *testing.T) {
+	dir := t.TempDir()
+	writeProjectTree(t, dir)
+	// The walk also keeps loco's own cache out
+	writeFile(t, filepath.Join(dir, ".loco", "quick.json"))
+
+	files, err := getProjectFilesWalk(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	if !reflect.DeepEqual(files, wantProjectFiles) {
+		t.Errorf("getProjectFilesWalk() = %q, want %q", files, wantProjectFiles)
+	}
+}
+
+func TestGetProjectFilesSubmodules(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	git := func(dir string, args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=loco", "-c", "user.email=loco@example.com", "-c", "protocol.file.allow=always"}, args...)
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	root := t.TempDir()
+	sub, dir := filepath.Join(root, "sub"), filepath.Join(root, "project")
+	writeFile(t, filepath.Join(sub, "lib.go"))
+	git(sub, "init", "-q")
+	git(sub, "add", "-A")
+	git(sub, "commit", "-qm", "lib")
+	writeFile(t, filepath.Join(dir, "main.go"))
+	git(dir, "init", "-q")
+	git(dir, "submodule", "add", "-q", sub, "third_party/sub")
+	git(dir, "add", "-A")
+
+	// The submodule itself is not a file to read
+	files, err := GetProjectFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	if want := []string{".gitmodules", "main.go"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("GetProjectFiles() = %q, want %q", files, want)
+	}
+
+	// Opting in lists what is checked out in it
+	configPath := filepath.Join(dir, ".loco", "config.json")
+	writeFile(t, configPath)
+	if err := os.WriteFile(configPath, []byte(`{"files": {"submodules": true}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files, err = GetProjectFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(files, "third_party/sub/lib.go") {
+		t.Errorf("GetProjectFiles() = %q, want third_party/sub/lib.go included", files)
+	}
+}
+
+func TestProjectStateHashWithoutGit(t *testing.T) {
+	dir := t.TempDir()
+	if isGitRepo(dir) {
+		t.Skip("temp dir is inside a git work tree")
+	}
+	writeProjectTree(t, dir)
+
+	before, err := projectStateHash(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := projectStateHash(dir); again != before {
+		t.Errorf("state hash changed without an edit")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := projectStateHash(dir); after == before {
+		t.Errorf("state hash unchanged after editing main.go")
+	}
+}
+
+func TestShouldAnalyzeFile(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{"main.go", true},
+		{"vendor/x/y.go", false},
+		{"a/vendor/y.go", false},
+		{"myvendor/y.go", true},
+		{"vendor.go", true},
+		{"cmd/build/main.go", false},
+		{"cmd/prebuild/main.go", true},
+		{"go.sum", false},
+		{"docs/Logo.PNG", false},
+	}
+	for _, tt := range tests {
+		if got := shouldAnalyzeFile(tt.file); got != tt.want {
+			t.Errorf("shouldAnalyzeFile(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestTopLevelDirCounts(t *testing.T) {
+	got := topLevelDirCounts([]string{"main.go", "go.mod", "internal/app/app.go", "internal/llm/client.go", "docs/a.md"})
+	want := map[string]int{".": 2, "internal": 2, "docs": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("topLevelDirCounts() = %v, want %v", got, want)
+	}
+}
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
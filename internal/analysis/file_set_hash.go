@@ -0,0 +1,70 @@
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/billie-coop/loco/internal/files"
+)
+
+// fileSetHash fingerprints what a tier was built from: the project's file
+// list and the content of each file the tier read. Unlike git status and
+// HEAD it changes exactly when those inputs do, whether the edit is
+// committed, staged or only on disk, and not for commits touching files the
+// tier never looked at.
+func fileSetHash(projectPath string, listed, consumed []string) string {
+	h := sha256.New()
+	for _, file := range sortedUnique(listed) {
+		fmt.Fprintf(h, "%s\x00", file)
+	}
+	h.Write([]byte{0})
+	for _, file := range sortedUnique(consumed) {
+		fmt.Fprintf(h, "%s\x00%s\x00", file, files.ContentHash(filepath.Join(projectPath, file)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// sortedUnique returns the paths sorted without duplicates
+func sortedUnique(paths []string) []string {
+	seen := make(map[string]bool, len(paths))
+	unique := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			unique = append(unique, path)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+// contentKeys lists the files a tier read, for its file-set hash
+func contentKeys(fileContents map[string]string) []string {
+	keys := make([]string, 0, len(fileContents))
+	for file := range fileContents {
+		keys = append(keys, file)
+	}
+	return sortedUnique(keys)
+}
+
+// cachedFileSet returns the file-set hash a cached analysis was saved with
+// and the files it read. ok is false for caches written before the hash was
+// recorded.
+func cachedFileSet(cached Analysis) (hash string, consumed []string, ok bool) {
+	switch a := cached.(type) {
+	case *InstantAnalysis:
+		hash, consumed = a.FileSetHash, a.ConsumedFiles
+	case *QuickAnalysis:
+		hash = a.FileSetHash
+	case *DetailedAnalysis:
+		hash, consumed = a.FileSetHash, a.ConsumedFiles
+	case *DeepAnalysis:
+		hash, consumed = a.FileSetHash, a.ConsumedFiles
+	case *FullAnalysis:
+		hash, consumed = a.FileSetHash, a.ConsumedFiles
+	}
+	return hash, consumed, hash != ""
+}
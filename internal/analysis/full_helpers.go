@@ -0,0 +1,174 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+// generateArchitectureDoc writes ARCHITECTURE.md from the deep tier's docs,
+// the dependency graph and the module docs. The Mermaid diagram is drawn
+// from the graph itself rather than left to the model.
+func (s *service) generateArchitectureDoc(
+	ctx context.Context,
+	deep *DeepAnalysis,
+	graph *DependencyGraph,
+	moduleDocs map[string]string,
+	deadCode []DeadCodeCandidate,
+) (string, error) {
+	if s.llmClient == nil {
+		return "", fmt.Errorf("LLM client not available")
+	}
+
+	var modules strings.Builder
+	for _, module := range graph.Modules {
+		line := fmt.Sprintf("- %s (%d files)", module.Path, len(module.Files))
+		if module.Entry {
+			line += " [entry point]"
+		}
+		if len(module.DependsOn) > 0 {
+			line += " → " + strings.Join(module.DependsOn, ", ")
+		}
+		modules.WriteString(line + "\n")
+	}
+
+	var docs strings.Builder
+	paths := make([]string, 0, len(moduleDocs))
+	for path := range moduleDocs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		docs.WriteString(fmt.Sprintf("### %s\n%s\n\n", path, firstParagraphs(stripFrontmatter(moduleDocs[path]), 2)))
+	}
+
+	var cycles strings.Builder
+	for _, cycle := range graph.Cycles {
+		cycles.WriteString("- " + strings.Join(cycle, " ↔ ") + "\n")
+	}
+
+	prompt := fmt.Sprintf(`Write ARCHITECTURE.md for this project: the document a new contributor reads first.
+
+Overview from the previous analysis tier:
+%s
+
+Structure from the previous analysis tier:
+%s
+
+Modules and their imports (measured from the code, not guessed):
+%s
+Import cycles:
+%s
+Module docs (excerpts):
+%s
+%d dead code candidates were found.
+
+Create a markdown document with:
+1. Purpose: what the system does and for whom
+2. High-level design: the layers or subsystems and how requests flow through them
+3. Module map: each important module and its responsibility
+4. Key design decisions and their trade-offs
+5. Where to start: which files to read first for common changes
+6. Known issues: cycles, dead code and other structural debt
+
+The module list above is measured; prefer it over the earlier tiers where they disagree. Do not draw a diagram, one is added separately.`,
+		stripFrontmatter(deep.KnowledgeFiles["overview.md"]), stripFrontmatter(deep.KnowledgeFiles["structure.md"]),
+		modules.String(), linesOrNone(cycles.String()), docs.String(), len(deadCode))
+
+	messages := []llm.Message{
+		{
+			Role:    "system",
+			Content: "You are a principal engineer writing the architecture document for a codebase. Be accurate, concrete and skeptical of earlier analysis.",
+		},
+		{
+			Role:    "user",
+			Content: prompt,
+		},
+	}
+
+	doc, err := s.completeWithContext(ctx, messages, 32768)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(doc) + "\n\n## Dependency Graph\n\n" + graph.Mermaid(), nil
+}
+
+// formatDeadCodeReport renders dead code candidates as markdown
+func formatDeadCodeReport(candidates []DeadCodeCandidate) string {
+	var sb strings.Builder
+	sb.WriteString("# Dead Code Candidates\n\n")
+	sb.WriteString("Found by name matching, so check each one before removing it: reflection, generated code and users outside the project can all hide a use.\n\n")
+	if len(candidates) == 0 {
+		sb.WriteString("None found.\n")
+		return sb.String()
+	}
+	for _, c := range candidates {
+		switch {
+		case c.Symbol == "":
+			sb.WriteString(fmt.Sprintf("- `%s/`: %s\n", c.Path, c.Reason))
+		case c.Line > 0:
+			sb.WriteString(fmt.Sprintf("- `%s` (%s:%d): %s\n", c.Symbol, c.Path, c.Line, c.Reason))
+		default:
+			sb.WriteString(fmt.Sprintf("- `%s` (%s): %s\n", c.Symbol, c.Path, c.Reason))
+		}
+	}
+	return sb.String()
+}
+
+// assessStructure turns the graph and dead code into technical debt,
+// recommendations and documentation gaps
+func assessStructure(graph *DependencyGraph, deadCode []DeadCodeCandidate) (debt, recommendations, gaps []string) {
+	for _, cycle := range graph.Cycles {
+		debt = append(debt, "Import cycle between "+strings.Join(cycle, ", "))
+	}
+	if len(graph.Cycles) > 0 {
+		recommendations = append(recommendations, "Break the import cycles by moving shared types into a module both sides can depend on")
+	}
+	if len(deadCode) > 0 {
+		debt = append(debt, fmt.Sprintf("%d dead code candidates", len(deadCode)))
+		recommendations = append(recommendations, "Review dead_code.md and remove what is really unused")
+	}
+
+	for _, module := range graph.Modules {
+		if !module.Doc && len(module.UsedBy) > 0 {
+			gaps = append(gaps, fmt.Sprintf("%s is used by %d modules but has no package comment or README", module.Path, len(module.UsedBy)))
+		}
+	}
+	if len(gaps) > 0 {
+		recommendations = append(recommendations, "Document the shared modules listed under documentation gaps, starting with the most used")
+	}
+	return debt, recommendations, gaps
+}
+
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "(none)"
+	}
+	return strings.Join(items, ", ")
+}
+
+func linesOrNone(lines string) string {
+	if lines == "" {
+		return "(none)\n"
+	}
+	return lines
+}
+
+// firstParagraphs returns the first n non-heading paragraphs of a document
+func firstParagraphs(doc string, n int) string {
+	var kept []string
+	for _, paragraph := range strings.Split(doc, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" || strings.HasPrefix(paragraph, "#") {
+			continue
+		}
+		kept = append(kept, paragraph)
+		if len(kept) == n {
+			break
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
@@ -0,0 +1,162 @@
+package analysis
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files from the current output")
+
+// goldenTakeTop is the per-worker cap the worker responses are replayed with
+const goldenTakeTop = 10
+
+// capturedResponse is a saved model response, as cmd/capture-responses writes it
+type capturedResponse struct {
+	Model    string `json:"model"`
+	Prompt   string `json:"prompt"`
+	Response string `json:"response"`
+}
+
+// goldenRanking is what a replayed response must keep extracting to
+type goldenRanking struct {
+	Rankings   []FileRanking `json:"rankings"`
+	Confidence float64       `json:"confidence,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// TestGoldenWorkerRankings replays captured ranking worker responses
+// through the JSON extraction the quick tier uses
+func TestGoldenWorkerRankings(t *testing.T) {
+	replayGolden(t, "ranking", func(response string) goldenRanking {
+		rankings, err := parseWorkerRankings(response, goldenTakeTop)
+		if err != nil {
+			return goldenRanking{Rankings: []FileRanking{}, Error: err.Error()}
+		}
+		return goldenRanking{Rankings: rankings}
+	})
+}
+
+// TestGoldenAdjudication replays captured adjudicator responses
+func TestGoldenAdjudication(t *testing.T) {
+	replayGolden(t, "adjudication", func(response string) goldenRanking {
+		consensus, err := parseAdjudication(response)
+		if err != nil {
+			return goldenRanking{Rankings: []FileRanking{}, Error: err.Error()}
+		}
+		if consensus.Rankings == nil {
+			consensus.Rankings = []FileRanking{}
+		}
+		return goldenRanking{Rankings: consensus.Rankings, Confidence: consensus.Confidence}
+	})
+}
+
+// replayGolden runs each captured response in testdata/golden/<kind>
+// through extract and compares the result with the .golden file saved next
+// to it. Run with -update after an intended change and review the diff.
+func replayGolden(t *testing.T, kind string, extract func(response string) goldenRanking) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "testdata", "golden", kind, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skipf("No golden responses found in testdata/golden/%s", kind)
+	}
+
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".json"), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var captured capturedResponse
+			if err := json.Unmarshal(data, &captured); err != nil {
+				t.Fatalf("Failed to parse %s: %v", path, err)
+			}
+
+			actual, err := json.MarshalIndent(extract(captured.Response), "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual = append(actual, '\n')
+
+			goldenPath := strings.TrimSuffix(path, ".json") + ".golden"
+			if *update {
+				if err := os.WriteFile(goldenPath, actual, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("Missing golden file %s (run go test -update): %v", goldenPath, err)
+			}
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("Output changed from %s\nwant:\n%s\ngot:\n%s", goldenPath, expected, actual)
+			}
+		})
+	}
+}
+
+// goldenFocuses are the default worker focuses, given to the captured
+// worker responses in file order
+var goldenFocuses = []string{"entry/init", "config/build", "core/domain", "api/handlers", "tests/docs"}
+
+// TestGoldenMergeStrategies merges the captured worker responses in
+// testdata/golden/ranking as one crowd with each strategy and compares the
+// result with testdata/golden/merge/<strategy>.golden
+func TestGoldenMergeStrategies(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "testdata", "golden", "ranking", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) < 2 {
+		t.Skip("Need at least two captured worker responses in testdata/golden/ranking")
+	}
+
+	var workers []WorkerRanking
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var captured capturedResponse
+		if err := json.Unmarshal(data, &captured); err != nil {
+			t.Fatalf("Failed to parse %s: %v", path, err)
+		}
+		rankings, _ := parseWorkerRankings(captured.Response, goldenTakeTop)
+		workers = append(workers, WorkerRanking{Focus: goldenFocuses[i%len(goldenFocuses)], Rankings: rankings})
+	}
+
+	for name, strategy := range mergeStrategies {
+		t.Run(name, func(t *testing.T) {
+			actual, err := json.MarshalIndent(goldenRanking{Rankings: mergeRankings(workers, goldenTakeTop, strategy)}, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual = append(actual, '\n')
+
+			goldenPath := filepath.Join("..", "..", "testdata", "golden", "merge", name+".golden")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(goldenPath, actual, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("Missing golden file %s (run go test -update): %v", goldenPath, err)
+			}
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("Output changed from %s\nwant:\n%s\ngot:\n%s", goldenPath, expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// verifiedKey is the frontmatter key listing the sections of a knowledge
+// file a person has checked. Later runs keep those sections word for word:
+//
+//	---
+//	human_verified:
+//	  - Architecture
+//	  - Build and run
+//	---
+const verifiedKey = "human_verified"
+
+// knowledgeTiers lists the tiers from the least to the most capable
+var knowledgeTiers = []Tier{TierInstant, TierQuick, TierDetailed, TierDeep, TierFull}
+
+var headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+
+// verifiedSection is a human-verified section of a knowledge file
+type verifiedSection struct {
+	Title string // Heading text without the #s
+	Text  string // The heading line and everything up to the next heading of the same or higher level
+}
+
+// splitFrontmatter separates a leading "---" block from the document
+func splitFrontmatter(doc string) (front []string, body string) {
+	if !strings.HasPrefix(doc, "---\n") {
+		return nil, doc
+	}
+	lines := strings.Split(doc, "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return lines[1:i], strings.TrimLeft(strings.Join(lines[i+1:], "\n"), "\n")
+		}
+	}
+	return nil, doc
+}
+
+// stripFrontmatter returns a knowledge document without its frontmatter
+func stripFrontmatter(doc string) string {
+	_, body := splitFrontmatter(doc)
+	return body
+}
+
+// verifiedTitles reads the section titles listed under human_verified, as a
+// block list or an inline [a, b] list
+func verifiedTitles(front []string) []string {
+	var titles []string
+	add := func(item string) {
+		item = strings.Trim(strings.TrimSpace(item), `"'`)
+		item = strings.TrimSpace(strings.TrimLeft(item, "#"))
+		if item != "" {
+			titles = append(titles, item)
+		}
+	}
+	for i := 0; i < len(front); i++ {
+		key, value, ok := strings.Cut(front[i], ":")
+		if !ok || strings.TrimSpace(key) != verifiedKey {
+			continue
+		}
+		if value = strings.TrimSpace(value); strings.HasPrefix(value, "[") {
+			for _, item := range strings.Split(strings.Trim(value, "[]"), ",") {
+				add(item)
+			}
+			continue
+		}
+		for i+1 < len(front) && strings.HasPrefix(strings.TrimSpace(front[i+1]), "- ") {
+			i++
+			add(strings.TrimPrefix(strings.TrimSpace(front[i]), "- "))
+		}
+	}
+	return titles
+}
+
+// findSection returns the line range of the section with the given title,
+// skipping headings inside code fences
+func findSection(lines []string, title string) (start, end int, ok bool) {
+	level := 0
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		m := headingPattern.FindStringSubmatch(line)
+		if inFence || m == nil {
+			continue
+		}
+		if level > 0 && len(m[1]) <= level {
+			return start, i, true
+		}
+		if level == 0 && strings.EqualFold(m[2], title) {
+			start, level = i, len(m[1])
+		}
+	}
+	return start, len(lines), level > 0
+}
+
+// extractVerifiedSections returns the human-verified sections of a document.
+// A listed title with no matching heading is ignored.
+func extractVerifiedSections(doc string) []verifiedSection {
+	front, body := splitFrontmatter(doc)
+	lines := strings.Split(strings.TrimRight(body, "\n"), "\n")
+	var sections []verifiedSection
+	for _, title := range verifiedTitles(front) {
+		if start, end, ok := findSection(lines, title); ok {
+			text := strings.TrimRight(strings.Join(lines[start:end], "\n"), "\n")
+			sections = append(sections, verifiedSection{Title: title, Text: text})
+		}
+	}
+	return sections
+}
+
+// loadVerifiedSections collects the verified sections of a knowledge file
+// from the saved copies of this tier and the tiers below it, so a correction
+// made once carries up through every refinement. A higher tier's copy of a
+// section wins.
+func (s *service) loadVerifiedSections(projectPath string, tier Tier, filename string) []verifiedSection {
+	var sections []verifiedSection
+	index := map[string]int{}
+	for _, t := range knowledgeTiers {
+		data, err := os.ReadFile(filepath.Join(knowledgeDir(projectPath), string(t), filename))
+		if err == nil {
+			for _, section := range extractVerifiedSections(string(data)) {
+				key := strings.ToLower(section.Title)
+				if i, ok := index[key]; ok {
+					sections[i] = section
+				} else {
+					index[key] = len(sections)
+					sections = append(sections, section)
+				}
+			}
+		}
+		if t == tier {
+			break
+		}
+	}
+	return sections
+}
+
+// applyVerifiedSections puts the verified sections into a generated
+// document word for word, replacing the model's version of each or appending
+// it when the model dropped the heading, and lists them in the frontmatter
+// so the next run keeps them too
+func applyVerifiedSections(doc string, sections []verifiedSection) string {
+	if len(sections) == 0 {
+		return doc
+	}
+	front, body := splitFrontmatter(doc)
+	lines := strings.Split(strings.TrimRight(body, "\n"), "\n")
+	for _, section := range sections {
+		if start, end, ok := findSection(lines, section.Title); ok {
+			replacement := strings.Split(section.Text, "\n")
+			if end < len(lines) {
+				replacement = append(replacement, "")
+			}
+			lines = append(lines[:start], append(replacement, lines[end:]...)...)
+		} else {
+			lines = append(lines, "", section.Text)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	for i := 0; i < len(front); i++ {
+		if key, _, _ := strings.Cut(front[i], ":"); strings.TrimSpace(key) == verifiedKey {
+			for i+1 < len(front) && strings.HasPrefix(strings.TrimSpace(front[i+1]), "- ") {
+				i++
+			}
+			continue
+		}
+		sb.WriteString(front[i] + "\n")
+	}
+	sb.WriteString(verifiedKey + ":\n")
+	for _, section := range sections {
+		sb.WriteString("  - " + section.Title + "\n")
+	}
+	sb.WriteString("---\n\n")
+	sb.WriteString(strings.Join(lines, "\n") + "\n")
+	return sb.String()
+}
+
+// preserveVerifiedSections applies the verified sections saved for each
+// generated file of a tier. Call it before the files are cached or saved.
+func (s *service) preserveVerifiedSections(projectPath string, tier Tier, files map[string]string) {
+	for name, doc := range files {
+		files[name] = applyVerifiedSections(doc, s.loadVerifiedSections(projectPath, tier, name))
+	}
+}
+
+// verifiedPromptBlock tells a refinement prompt which sections it must keep
+func verifiedPromptBlock(sections []verifiedSection) string {
+	if len(sections) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\nHuman-verified sections: a person checked these. Include each one exactly as written, under the same heading, and do not reword, correct or contradict them:\n")
+	for _, section := range sections {
+		sb.WriteString("\n" + section.Text + "\n")
+	}
+	return sb.String()
+}
@@ -8,13 +8,14 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/crash"
+	"github.com/billie-coop/loco/internal/files"
 	"github.com/billie-coop/loco/internal/llm"
 )
 
@@ -23,6 +24,8 @@ type service struct {
 	llmClient   llm.Client
 	cachePath   string
 	startupScan *StartupScanResult // Cached startup scan result
+	progress    ProgressCallback   // Receives progress of every run (optional)
+	workerLimit *llm.AdaptiveLimit // Shared cap on concurrent quick workers (optional)
 }
 
 // NewService creates a new analysis service.
@@ -38,9 +41,10 @@ func NewService(llmClient llm.Client) Service {
 
 // QuickAnalyze performs Tier 1 analysis.
 func (s *service) QuickAnalyze(ctx context.Context, projectPath string) (*QuickAnalysis, error) {
+	ctx = withDeterminism(ctx, projectPath)
 	// Respect per-tier clean flag
 	forceClean := false
-	if cfgMgr := config.NewManager(projectPath); cfgMgr != nil {
+	if cfgMgr := config.NewManager(configRoot(projectPath)); cfgMgr != nil {
 		_ = cfgMgr.Load()
 		if c := cfgMgr.Get(); c != nil && c.Analysis.Quick.Clean {
 			forceClean = true
@@ -59,7 +63,7 @@ func (s *service) QuickAnalyze(ctx context.Context, projectPath string) (*QuickA
 		// Purge quick cache and knowledge when clean is set
 		cacheFile := s.getCachePath(projectPath, TierQuick)
 		_ = os.Remove(cacheFile)
-		_ = os.RemoveAll(filepath.Join(projectPath, s.cachePath, "knowledge", string(TierQuick)))
+		_ = os.RemoveAll(filepath.Join(knowledgeDir(projectPath), string(TierQuick)))
 	}
 
 	// Perform new analysis
@@ -71,7 +75,7 @@ func (s *service) QuickAnalyze(ctx context.Context, projectPath string) (*QuickA
 		return nil, fmt.Errorf("failed to get project files: %w", err)
 	}
 	// Progress: discovered file list
-	ReportProgress(ctx, Progress{Phase: string(TierQuick), TotalFiles: len(files), CompletedFiles: 0, CurrentFile: "discovered files"})
+	s.reportProgress(ctx, Progress{Phase: string(TierQuick), TotalFiles: len(files), CompletedFiles: 0, CurrentFile: "discovered files"})
 
 	// Step 2: Summaries + adjudication (no file contents)
 	consensus, err := s.consensusRankFiles(ctx, projectPath, files)
@@ -79,10 +83,10 @@ func (s *service) QuickAnalyze(ctx context.Context, projectPath string) (*QuickA
 		return nil, fmt.Errorf("failed to adjudicate worker summaries: %w", err)
 	}
 	// Progress: show worker-level completion for quick tier
-	qcCfg := config.NewManager(projectPath)
+	qcCfg := config.NewManager(configRoot(projectPath))
 	_ = qcCfg.Load()
 	qc := qcCfg.Get().Analysis.Quick
-	ReportProgress(ctx, Progress{Phase: string(TierQuick), TotalFiles: max(1, qc.Workers), CompletedFiles: max(1, qc.Workers), CurrentFile: "adjudication complete"})
+	s.reportProgress(ctx, Progress{Phase: string(TierQuick), TotalFiles: max(1, qc.Workers), CompletedFiles: max(1, qc.Workers), CurrentFile: "adjudication complete"})
 
 	// Step 3: Generate quick knowledge: single summary.md
 	knowledgeFiles, err := s.generateQuickKnowledge(ctx, projectPath, consensus)
@@ -96,13 +100,7 @@ func (s *service) QuickAnalyze(ctx context.Context, projectPath string) (*QuickA
 	framework := detectFramework(files)
 	keyDirs := []string{}
 	entryPoints := detectEntryPoints(files, map[string]string{})
-	codeFiles := 0
-	for _, f := range files {
-		ext := filepath.Ext(f)
-		if ext == ".go" || ext == ".js" || ext == ".ts" || ext == ".py" || ext == ".java" || ext == ".rs" || ext == ".rb" || ext == ".php" {
-			codeFiles++
-		}
-	}
+	codeFiles := countCodeFiles(files)
 
 	// Create result
 	result := &QuickAnalysis{
@@ -119,6 +117,7 @@ func (s *service) QuickAnalyze(ctx context.Context, projectPath string) (*QuickA
 		EntryPoints:    entryPoints,
 		Duration:       time.Since(start),
 		KnowledgeFiles: knowledgeFiles,
+		FileSetHash:    fileSetHash(projectPath, files, nil),
 	}
 
 	// Cache the result
@@ -129,6 +128,7 @@ func (s *service) QuickAnalyze(ctx context.Context, projectPath string) (*QuickA
 
 	// Start background Detailed job queue after quick adjudication
 	go func() {
+		defer crash.Recover("detailed analysis")
 		ctxBg := context.Background()
 		_, _ = s.DetailedAnalyze(ctxBg, projectPath)
 	}()
@@ -138,9 +138,10 @@ func (s *service) QuickAnalyze(ctx context.Context, projectPath string) (*QuickA
 
 // DetailedAnalyze performs Tier 2 analysis.
 func (s *service) DetailedAnalyze(ctx context.Context, projectPath string) (*DetailedAnalysis, error) {
+	ctx = withDeterminism(ctx, projectPath)
 	// Respect per-tier clean flag
 	forceClean := false
-	if cfgMgr := config.NewManager(projectPath); cfgMgr != nil {
+	if cfgMgr := config.NewManager(configRoot(projectPath)); cfgMgr != nil {
 		_ = cfgMgr.Load()
 		if c := cfgMgr.Get(); c != nil && c.Analysis.Detailed.Clean {
 			forceClean = true
@@ -161,23 +162,22 @@ func (s *service) DetailedAnalyze(ctx context.Context, projectPath string) (*Det
 	start := time.Now()
 
 	// Per-tier debug gating (analysis.detailed.debug or LOCO_DEBUG)
-	cfgMgr := config.NewManager(projectPath)
+	cfgMgr := config.NewManager(configRoot(projectPath))
 	_ = cfgMgr.Load()
 	cfg := cfgMgr.Get()
 	shouldDebugDetailed := (cfg != nil && cfg.Analysis.Detailed.Debug) || os.Getenv("LOCO_DEBUG") == "true"
-	var detailedDebugDir string
-	if shouldDebugDetailed {
-		ts := time.Now().Format("20060102_150405")
-		detailedDebugDir = filepath.Join(projectPath, s.cachePath, "debug", "detailed", ts)
-		_ = os.MkdirAll(detailedDebugDir, 0o755)
-	}
+	detailedDump := newDebugDump(projectPath, "detailed", cfg, shouldDebugDetailed)
 
 	// Step 1: Get all project files
 	files, err := GetProjectFiles(projectPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get project files: %w", err)
 	}
-	ReportProgress(ctx, Progress{Phase: string(TierDetailed), TotalFiles: len(files), CompletedFiles: 0, CurrentFile: "discovered files"})
+	s.reportProgress(ctx, Progress{Phase: string(TierDetailed), TotalFiles: len(files), CompletedFiles: 0, CurrentFile: "discovered files"})
+
+	// Refresh the dependency graph; it needs no model, so every detailed run keeps it current
+	_, goFiles := parseGoFiles(projectPath, files, goImportsMode)
+	graph := s.updateDependencyGraph(projectPath, files, goFiles)
 
 	// Step 2: Read key file contents for deeper analysis
 	keyFiles := selectKeyFiles(files)
@@ -187,7 +187,7 @@ func (s *service) DetailedAnalyze(ctx context.Context, projectPath string) (*Det
 		if err == nil {
 			fileContents[file] = content
 		}
-		ReportProgress(ctx, Progress{Phase: string(TierDetailed), TotalFiles: len(keyFiles), CompletedFiles: i + 1, CurrentFile: file})
+		s.reportProgress(ctx, Progress{Phase: string(TierDetailed), TotalFiles: len(keyFiles), CompletedFiles: i + 1, CurrentFile: file})
 	}
 
 	// Step 3: Generate more thorough file summaries (including content analysis)
@@ -212,6 +212,10 @@ func (s *service) DetailedAnalyze(ctx context.Context, projectPath string) (*Det
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate knowledge documents: %w", err)
 	}
+	s.preserveVerifiedSections(projectPath, TierDetailed, knowledgeFiles)
+
+	// Step 6: Document each significant directory from its files' summaries
+	s.updateModuleDocs(ctx, projectPath, TierDetailed, graph)
 
 	// Detect tech stack from actual file contents
 	techStack := detectTechStack(files, fileContents)
@@ -232,14 +236,18 @@ func (s *service) DetailedAnalyze(ctx context.Context, projectPath string) (*Det
 		KnowledgeFiles: knowledgeFiles,
 		Duration:       time.Since(start),
 	}
-	if hash, err := s.getGitStatusHash(projectPath); err == nil {
+	if hash, err := projectStateHash(projectPath); err == nil {
 		result.GitStatusHash = hash
 	}
+	result.ConsumedFiles = contentKeys(fileContents)
+	result.FileSetHash = fileSetHash(projectPath, files, result.ConsumedFiles)
 
-	// Cache the result
+	// Cache the result; the run is complete so its checkpoint is no longer needed
 	if err := s.saveCachedAnalysis(projectPath, result); err != nil {
 		// Log but don't fail
 		_ = err
+	} else {
+		s.clearCheckpoint(projectPath, TierDetailed)
 	}
 
 	// Save knowledge files to disk
@@ -249,8 +257,8 @@ func (s *service) DetailedAnalyze(ctx context.Context, projectPath string) (*Det
 	}
 
 	// Write debug artifact if enabled
-	if shouldDebugDetailed {
-		_ = os.WriteFile(filepath.Join(detailedDebugDir, "summary.txt"), []byte("detailed analysis completed"), 0o644)
+	if detailedDump != nil {
+		detailedDump.write("summary.txt", "detailed analysis completed")
 	}
 
 	return result, nil
@@ -258,6 +266,7 @@ func (s *service) DetailedAnalyze(ctx context.Context, projectPath string) (*Det
 
 // DeepAnalyze performs Tier 3 analysis.
 func (s *service) DeepAnalyze(ctx context.Context, projectPath string) (*DeepAnalysis, error) {
+	ctx = withDeterminism(ctx, projectPath)
 	// Need Tier 2 results first
 	detailed, err := s.DetailedAnalyze(ctx, projectPath)
 	if err != nil {
@@ -266,7 +275,7 @@ func (s *service) DeepAnalyze(ctx context.Context, projectPath string) (*DeepAna
 
 	// Respect per-tier clean flag
 	forceClean := false
-	if cfgMgr := config.NewManager(projectPath); cfgMgr != nil {
+	if cfgMgr := config.NewManager(configRoot(projectPath)); cfgMgr != nil {
 		_ = cfgMgr.Load()
 		if c := cfgMgr.Get(); c != nil && c.Analysis.Deep.Clean {
 			forceClean = true
@@ -287,16 +296,11 @@ func (s *service) DeepAnalyze(ctx context.Context, projectPath string) (*DeepAna
 	start := time.Now()
 
 	// Per-tier debug gating (analysis.deep.debug or LOCO_DEBUG)
-	cfgMgr := config.NewManager(projectPath)
+	cfgMgr := config.NewManager(configRoot(projectPath))
 	_ = cfgMgr.Load()
 	cfg := cfgMgr.Get()
 	shouldDebugDeep := (cfg != nil && cfg.Analysis.Deep.Debug) || os.Getenv("LOCO_DEBUG") == "true"
-	var deepDebugDir string
-	if shouldDebugDeep {
-		ts := time.Now().Format("20060102_150405")
-		deepDebugDir = filepath.Join(projectPath, s.cachePath, "debug", "deep", ts)
-		_ = os.MkdirAll(deepDebugDir, 0o755)
-	}
+	deepDump := newDebugDump(projectPath, "deep", cfg, shouldDebugDeep)
 
 	// Step 1: Get all project files
 	files, err := GetProjectFiles(projectPath)
@@ -330,8 +334,14 @@ func (s *service) DeepAnalyze(ctx context.Context, projectPath string) (*DeepAna
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate deep knowledge documents: %w", err)
 	}
+	s.preserveVerifiedSections(projectPath, TierDeep, knowledgeFiles)
 
-	// Step 5: Extract architectural insights
+	// Step 5: Redo the module docs whose summaries changed, or all of them
+	// when the detailed tier wrote them with a smaller model
+	_, goFiles := parseGoFiles(projectPath, files, goImportsMode)
+	s.updateModuleDocs(ctx, projectPath, TierDeep, s.updateDependencyGraph(projectPath, files, goFiles))
+
+	// Step 6: Extract architectural insights
 	insights := extractArchitecturalInsights(knowledgeFiles, detailed.KnowledgeFiles)
 
 	// Create result
@@ -352,6 +362,9 @@ func (s *service) DeepAnalyze(ctx context.Context, projectPath string) (*DeepAna
 		RefinementNotes:       refinementNotes,
 		ArchitecturalInsights: insights,
 	}
+	// Deep builds on the detailed tier's docs, so its reads count too
+	result.ConsumedFiles = sortedUnique(append(contentKeys(fileContents), detailed.ConsumedFiles...))
+	result.FileSetHash = fileSetHash(projectPath, files, result.ConsumedFiles)
 
 	// Cache the result
 	if err := s.saveCachedAnalysis(projectPath, result); err != nil {
@@ -366,8 +379,8 @@ func (s *service) DeepAnalyze(ctx context.Context, projectPath string) (*DeepAna
 	}
 
 	// Write debug artifact if enabled
-	if shouldDebugDeep {
-		_ = os.WriteFile(filepath.Join(deepDebugDir, "summary.txt"), []byte("deep analysis completed"), 0o644)
+	if deepDump != nil {
+		deepDump.write("summary.txt", "deep analysis completed")
 	}
 
 	return result, nil
@@ -389,6 +402,9 @@ type canonicalFileSummary struct {
 	Purpose       string            `json:"purpose,omitempty"`
 	Importance    int               `json:"importance,omitempty"`
 	Tags          []string          `json:"tags,omitempty"`
+	Package       string            `json:"package,omitempty"` // Parsed from the source
+	Exports       []string          `json:"exports,omitempty"` // Parsed from the source
+	Imports       []string          `json:"imports,omitempty"` // Parsed from the source
 	Confidence    float64           `json:"confidence,omitempty"`
 	AnalyzedAt    time.Time         `json:"analyzed_at"`
 	Tier          Tier              `json:"tier"`
@@ -400,7 +416,7 @@ type canonicalFileSummary struct {
 
 // updateCanonicalSummaries merges the latest summaries into .loco/knowledge/file_summaries.json and writes a global compact view.
 func (s *service) updateCanonicalSummaries(projectPath string, tier Tier, fileSummaries *FileAnalysisResult) error {
-	canonPath := filepath.Join(projectPath, s.cachePath, "knowledge", "file_summaries.json")
+	canonPath := filepath.Join(knowledgeDir(projectPath), "file_summaries.json")
 	_ = os.MkdirAll(filepath.Dir(canonPath), 0755)
 
 	// Load existing if present
@@ -410,10 +426,12 @@ func (s *service) updateCanonicalSummaries(projectPath string, tier Tier, fileSu
 	}
 
 	// Prepare git info
+	versioned := isGitRepo(projectPath)
 	tracked := s.getGitTrackedSet(projectPath)
 	status := s.getGitStatusMap(projectPath)
 
 	// Merge each summary, skip empty paths
+	stamp := analysisStamp(projectPath)
 	for _, fs := range fileSummaries.Files {
 		path := strings.TrimSpace(fs.Path)
 		if path == "" {
@@ -422,7 +440,7 @@ func (s *service) updateCanonicalSummaries(projectPath string, tier Tier, fileSu
 		c := existing[path]
 		c.Path = path
 		c.Tier = tier
-		c.AnalyzedAt = time.Now()
+		c.AnalyzedAt = stamp
 		c.SchemaVersion = 1
 		// Prefer deterministic file type
 		c.FileType = classifyByExt(path)
@@ -430,6 +448,9 @@ func (s *service) updateCanonicalSummaries(projectPath string, tier Tier, fileSu
 		if tier != TierQuick {
 			c.Summary = fs.Summary
 			c.Purpose = fs.Purpose
+			if fs.Package != "" {
+				c.Package, c.Exports, c.Imports = fs.Package, fs.Exports, fs.Imports
+			}
 		}
 		if fs.Importance > 0 {
 			c.Importance = fs.Importance
@@ -445,8 +466,10 @@ func (s *service) updateCanonicalSummaries(projectPath string, tier Tier, fileSu
 			c.SizeBytes = sizeBytes
 			c.LineCount = lineCount
 		}
-		// Git status
-		if _, ok := tracked[path]; ok {
+		// Git status, left empty outside a repository
+		if !versioned {
+			c.GitStatus = ""
+		} else if _, ok := tracked[path]; ok {
 			c.GitStatus = "tracked"
 		} else if st, ok := status[path]; ok {
 			c.GitStatus = st
@@ -583,27 +606,22 @@ func computeContentHashStats(absPath string) (hash string, size int, lines int)
 
 func (s *service) getGitTrackedSet(projectPath string) map[string]struct{} {
 	res := map[string]struct{}{}
-	cmd := exec.Command("git", "ls-files")
-	cmd.Dir = projectPath
-	out, err := cmd.Output()
+	// -z keeps names with spaces or non-ASCII characters unquoted
+	out, err := files.Git(context.Background(), projectPath, "ls-files", "-z")
 	if err != nil {
 		return res
 	}
-	for _, line := range strings.Split(string(out), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name != "" {
+			res[name] = struct{}{}
 		}
-		res[line] = struct{}{}
 	}
 	return res
 }
 
 func (s *service) getGitStatusMap(projectPath string) map[string]string {
 	res := map[string]string{}
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = projectPath
-	out, err := cmd.Output()
+	out, err := files.Git(context.Background(), projectPath, "status", "--porcelain")
 	if err != nil {
 		return res
 	}
@@ -630,6 +648,7 @@ func (s *service) getGitStatusMap(projectPath string) map[string]string {
 
 // FullAnalyze performs Tier 4 analysis.
 func (s *service) FullAnalyze(ctx context.Context, projectPath string) (*FullAnalysis, error) {
+	ctx = withDeterminism(ctx, projectPath)
 	// Need Tier 3 results first
 	deep, err := s.DeepAnalyze(ctx, projectPath)
 	if err != nil {
@@ -638,7 +657,7 @@ func (s *service) FullAnalyze(ctx context.Context, projectPath string) (*FullAna
 
 	// Respect per-tier clean flag
 	forceClean := false
-	if cfgMgr := config.NewManager(projectPath); cfgMgr != nil {
+	if cfgMgr := config.NewManager(configRoot(projectPath)); cfgMgr != nil {
 		_ = cfgMgr.Load()
 		if c := cfgMgr.Get(); c != nil && c.Analysis.Full.Clean {
 			forceClean = true
@@ -659,29 +678,71 @@ func (s *service) FullAnalyze(ctx context.Context, projectPath string) (*FullAna
 	start := time.Now()
 
 	// Per-tier debug gating (analysis.full.debug or LOCO_DEBUG)
-	cfgMgr := config.NewManager(projectPath)
+	cfgMgr := config.NewManager(configRoot(projectPath))
 	_ = cfgMgr.Load()
 	cfg := cfgMgr.Get()
 	shouldDebugFull := (cfg != nil && cfg.Analysis.Full.Debug) || os.Getenv("LOCO_DEBUG") == "true"
-	var fullDebugDir string
-	if shouldDebugFull {
-		ts := time.Now().Format("20060102_150405")
-		fullDebugDir = filepath.Join(projectPath, s.cachePath, "debug", "full", ts)
-		_ = os.MkdirAll(fullDebugDir, 0o755)
+	fullDump := newDebugDump(projectPath, "full", cfg, shouldDebugFull)
+
+	// Step 1: Get all project files
+	files, err := GetProjectFiles(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project files: %w", err)
 	}
-	// TODO: Implement full analysis
-	result := &FullAnalysis{
-		Tier:           TierFull,
-		Generated:      time.Now(),
-		ProjectPath:    projectPath,
-		Description:    "Full analysis not yet implemented",
-		Architecture:   deep.Architecture,
-		KnowledgeFiles: map[string]string{},
-		Duration:       time.Since(start),
+	s.reportProgress(ctx, Progress{Phase: string(TierFull), TotalFiles: len(files), CompletedFiles: 0, CurrentFile: "building dependency graph"})
+
+	// Step 2: Build the cross-file dependency graph and look for dead code
+	fset, goFiles := parseGoFiles(projectPath, files, goSourceMode)
+	graph := s.updateDependencyGraph(projectPath, files, goFiles)
+	deadCode := findDeadCode(graph, fset, goFiles)
+
+	// Step 3: Bring the module docs up to the large model
+	moduleDocs := s.updateModuleDocs(ctx, projectPath, TierFull, graph)
+
+	// Step 4: Write ARCHITECTURE.md on top of the deep tier and the graph
+	architecture, err := s.generateArchitectureDoc(ctx, deep, graph, moduleDocs, deadCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate architecture document: %w", err)
+	}
+	knowledgeFiles := map[string]string{
+		"ARCHITECTURE.md": architecture,
+		"dead_code.md":    formatDeadCodeReport(deadCode),
 	}
-	if hash, err := s.getGitStatusHash(projectPath); err == nil {
+	s.preserveVerifiedSections(projectPath, TierFull, knowledgeFiles)
+
+	debt, recommendations, gaps := assessStructure(graph, deadCode)
+
+	// Create result
+	result := &FullAnalysis{
+		Tier:              TierFull,
+		Generated:         time.Now(),
+		ProjectPath:       projectPath,
+		Description:       "Full analysis with dependency graph, dead code candidates and architecture docs",
+		Architecture:      extractArchitecture(architecture),
+		Purpose:           deep.Purpose,
+		TechStack:         deep.TechStack,
+		KeyFiles:          deep.KeyFiles,
+		EntryPoints:       deep.EntryPoints,
+		FileCount:         len(files),
+		KnowledgeFiles:    knowledgeFiles,
+		Duration:          time.Since(start),
+		GitStatusHash:     deep.GitStatusHash,
+		TechnicalDebt:     debt,
+		Recommendations:   recommendations,
+		DocumentationGaps: gaps,
+		Modules:           graph.Modules,
+		Cycles:            graph.Cycles,
+		DeadCode:          deadCode,
+	}
+	if hash, err := projectStateHash(projectPath); err == nil {
 		result.GitStatusHash = hash
 	}
+	parsed := deep.ConsumedFiles
+	for file := range goFiles {
+		parsed = append(parsed, file)
+	}
+	result.ConsumedFiles = sortedUnique(parsed)
+	result.FileSetHash = fileSetHash(projectPath, files, result.ConsumedFiles)
 
 	// Cache the result
 	if err := s.saveCachedAnalysis(projectPath, result); err != nil {
@@ -689,9 +750,17 @@ func (s *service) FullAnalyze(ctx context.Context, projectPath string) (*FullAna
 		_ = err
 	}
 
+	// Save knowledge files to disk
+	if err := s.saveKnowledgeFiles(projectPath, TierFull, knowledgeFiles); err != nil {
+		// Log but don't fail
+		_ = err
+	}
+
 	// Write debug artifact if enabled
-	if shouldDebugFull {
-		_ = os.WriteFile(filepath.Join(fullDebugDir, "summary.txt"), []byte("full analysis completed"), 0o644)
+	if fullDump != nil {
+		summary := fmt.Sprintf("full analysis completed: %d modules, %d cycles, %d dead code candidates, %d module docs",
+			len(graph.Modules), len(graph.Cycles), len(deadCode), len(moduleDocs))
+		fullDump.write("summary.txt", summary)
 	}
 
 	return result, nil
@@ -702,23 +771,29 @@ func (s *service) GetCachedAnalysis(projectPath string, tier Tier) (Analysis, er
 	return s.loadCachedAnalysis(projectPath, tier)
 }
 
-// IsStale checks if cached analysis needs refresh.
+// IsStale checks if cached analysis needs refresh: the project's file list
+// or the content of a file the tier read changed, or the cache is past the
+// tier's maximum age.
 func (s *service) IsStale(projectPath string, tier Tier) (bool, error) {
 	cached, err := s.loadCachedAnalysis(projectPath, tier)
 	if err != nil {
 		return true, err // No cache or error loading = stale
 	}
 
-	// Get current git status
-	currentHash, err := s.getGitStatusHash(projectPath)
-	if err != nil {
-		// If we can't get git status, check age
+	// Compare what the tier was built from with what is there now
+	if hash, consumed, ok := cachedFileSet(cached); ok {
+		files, err := GetProjectFiles(projectPath)
+		if err != nil {
+			return true, nil
+		}
+		if fileSetHash(projectPath, files, consumed) != hash {
+			return true, nil
+		}
+	} else if currentHash, err := projectStateHash(projectPath); err != nil {
+		// If the project's state can't be read, check age
 		return time.Since(cached.GetGenerated()) > 1*time.Hour, nil
-	}
-
-	// Check git status hash for detailed/deep/full tiers
-	switch tier {
-	case TierDetailed, TierDeep, TierFull:
+	} else {
+		// Caches saved before the file-set hash fall back to the state hash
 		if detailed, ok := cached.(*DetailedAnalysis); ok && detailed.GitStatusHash != currentHash {
 			return true, nil
 		}
@@ -732,6 +807,7 @@ func (s *service) IsStale(projectPath string, tier Tier) (bool, error) {
 
 	// Fallback: consider stale after reasonable time
 	maxAge := map[Tier]time.Duration{
+		TierInstant:  24 * time.Hour,
 		TierQuick:    1 * time.Hour,
 		TierDetailed: 24 * time.Hour,
 		TierDeep:     7 * 24 * time.Hour,
@@ -744,7 +820,7 @@ func (s *service) IsStale(projectPath string, tier Tier) (bool, error) {
 // Cache management
 
 func (s *service) getCachePath(projectPath string, tier Tier) string {
-	return filepath.Join(projectPath, s.cachePath, "knowledge", string(tier), "analysis.json")
+	return filepath.Join(knowledgeDir(projectPath), string(tier), "analysis.json")
 }
 
 func (s *service) loadCachedAnalysis(projectPath string, tier Tier) (Analysis, error) {
@@ -757,6 +833,12 @@ func (s *service) loadCachedAnalysis(projectPath string, tier Tier) (Analysis, e
 
 	// Create the appropriate type based on tier
 	switch tier {
+	case TierInstant:
+		var analysis InstantAnalysis
+		if err := json.Unmarshal(data, &analysis); err != nil {
+			return nil, err
+		}
+		return &analysis, nil
 	case TierQuick:
 		var analysis QuickAnalysis
 		if err := json.Unmarshal(data, &analysis); err != nil {
@@ -799,12 +881,18 @@ func (s *service) saveCachedAnalysis(projectPath string, analysis Analysis) erro
 		return err
 	}
 
-	return os.WriteFile(cachePath, data, 0644)
+	if err := os.WriteFile(cachePath, data, 0644); err != nil {
+		return err
+	}
+	// Other projects find this one through the global registry; failing to
+	// update it doesn't fail the analysis
+	_ = RegisterAnalysis(projectPath, analysis)
+	return nil
 }
 
 // saveKnowledgeRootJSON writes JSON into .loco/knowledge/
 func (s *service) saveKnowledgeRootJSON(projectPath string, filename string, data any) error {
-	dir := filepath.Join(projectPath, s.cachePath, "knowledge")
+	dir := knowledgeDir(projectPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
@@ -816,33 +904,6 @@ func (s *service) saveKnowledgeRootJSON(projectPath string, filename string, dat
 	return os.WriteFile(path, b, 0644)
 }
 
-func (s *service) getGitStatusHash(projectPath string) (string, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = projectPath
-
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("git status failed: %w", err)
-	}
-
-	// Also include the current HEAD commit for better tracking
-	headCmd := exec.Command("git", "rev-parse", "HEAD")
-	headCmd.Dir = projectPath
-	headOutput, err := headCmd.Output()
-	if err != nil {
-		// If we can't get HEAD, just use status
-		headOutput = []byte("no-head")
-	}
-
-	// Combine status and HEAD commit
-	combined := append(output, headOutput...)
-
-	// Create hash
-	h := sha256.New()
-	h.Write(combined)
-	return hex.EncodeToString(h.Sum(nil)), nil
-}
-
 // GetStartupScan returns the cached startup scan result.
 func (s *service) GetStartupScan(projectPath string) *StartupScanResult {
 	// Check in-memory cache first
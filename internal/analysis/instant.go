@@ -0,0 +1,323 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxInstantDirs caps how many top-level directories the instant tier lists
+// as key directories
+const maxInstantDirs = 8
+
+// goManifest is what the instant tier reads from go.mod
+type goManifest struct {
+	Module   string   `json:"module"`
+	Go       string   `json:"go,omitempty"`
+	Requires []string `json:"requires,omitempty"` // Direct requirements
+}
+
+// npmManifest is what the instant tier reads from package.json
+type npmManifest struct {
+	Name            string            `json:"name"`
+	Description     string            `json:"description"`
+	Main            string            `json:"main"`
+	Bin             json.RawMessage   `json:"bin"` // A path, or a map of command to path
+	Scripts         map[string]string `json:"scripts"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// InstantAnalyze performs Tier 0 analysis from the file list, go.mod and
+// package.json alone. It never calls a model, so it gives the chat some
+// context on startup before one is loaded. It finishes too fast to report
+// progress.
+func (s *service) InstantAnalyze(ctx context.Context, projectPath string) (*InstantAnalysis, error) {
+	if cached, err := s.loadCachedAnalysis(projectPath, TierInstant); err == nil {
+		if stale, err := s.IsStale(projectPath, TierInstant); err == nil && !stale {
+			if instant, ok := cached.(*InstantAnalysis); ok {
+				return instant, nil
+			}
+		}
+	}
+
+	start := time.Now()
+	files, err := GetProjectFiles(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project files: %w", err)
+	}
+	result := &InstantAnalysis{
+		Tier:           TierInstant,
+		Generated:      time.Now(),
+		ProjectPath:    projectPath,
+		ProjectType:    detectProjectType(files),
+		MainLanguage:   detectMainLanguage(files),
+		Framework:      detectFramework(files),
+		TotalFiles:     len(files),
+		CodeFiles:      countCodeFiles(files),
+		KeyDirectories: keyDirectories(files, maxInstantDirs),
+	}
+	var consumed []string
+	if slices.Contains(files, "go.mod") {
+		if gm, err := readGoManifest(filepath.Join(projectPath, "go.mod")); err == nil {
+			result.GoModule = gm
+			consumed = append(consumed, "go.mod")
+		}
+	}
+	var npm *npmManifest
+	if slices.Contains(files, "package.json") {
+		if data, err := os.ReadFile(filepath.Join(projectPath, "package.json")); err == nil && json.Unmarshal(data, &npm) == nil {
+			result.Package = npm.Name
+			result.Scripts = slices.Sorted(maps.Keys(npm.Scripts))
+			result.NPMDependencies = len(npm.Dependencies)
+			result.NPMDevDependencies = len(npm.DevDependencies)
+			consumed = append(consumed, "package.json")
+		}
+	}
+	result.EntryPoints = instantEntryPoints(files, npm)
+	result.Description = instantDescription(result, npm)
+	result.ConsumedFiles = consumed
+	result.FileSetHash = fileSetHash(projectPath, files, consumed)
+
+	result.KnowledgeFiles = map[string]string{
+		"overview.md":  instantOverview(result, projectPath),
+		"structure.md": instantStructure(files),
+	}
+	s.preserveVerifiedSections(projectPath, TierInstant, result.KnowledgeFiles)
+	_ = s.saveKnowledgeFiles(projectPath, TierInstant, result.KnowledgeFiles)
+	result.Duration = time.Since(start)
+	_ = s.saveCachedAnalysis(projectPath, result)
+	return result, nil
+}
+
+// readGoManifest reads the module path, Go version and direct requirements
+// of a go.mod
+func readGoManifest(file string) (*goManifest, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	gm := &goManifest{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		line = strings.TrimSpace(line)
+		if module, ok := strings.CutPrefix(line, "module "); ok {
+			gm.Module = strings.Trim(strings.TrimSpace(module), `"`)
+		} else if version, ok := strings.CutPrefix(line, "go "); ok {
+			gm.Go = strings.TrimSpace(version)
+		}
+	}
+	deps, err := parseGoModDependencies(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, dep := range deps {
+		if dep.Kind != DependencyIndirect {
+			gm.Requires = append(gm.Requires, dep.Name)
+		}
+	}
+	return gm, nil
+}
+
+// instantEntryPoints guesses the entry points from file names and
+// package.json's main and bin, without reading any source
+func instantEntryPoints(files []string, npm *npmManifest) []string {
+	seen := map[string]bool{}
+	var entries []string
+	add := func(file string) {
+		file = path.Clean(filepath.ToSlash(file))
+		if !seen[file] {
+			seen[file] = true
+			entries = append(entries, file)
+		}
+	}
+
+	listed := map[string]bool{}
+	for _, file := range files {
+		listed[file] = true
+	}
+	if npm != nil {
+		if npm.Main != "" && listed[path.Clean(npm.Main)] {
+			add(npm.Main)
+		}
+		var bin string
+		var bins map[string]string
+		if json.Unmarshal(npm.Bin, &bin) == nil && bin != "" {
+			bins = map[string]string{npm.Name: bin}
+		} else {
+			_ = json.Unmarshal(npm.Bin, &bins)
+		}
+		for _, name := range slices.Sorted(maps.Keys(bins)) {
+			if listed[path.Clean(bins[name])] {
+				add(bins[name])
+			}
+		}
+	}
+
+	for _, file := range files {
+		if isTestFile(file) || isVendored(file) {
+			continue
+		}
+		if path.Base(file) == "main.go" || isEntryFile(file) {
+			add(file)
+		}
+	}
+	return entries
+}
+
+// isVendored reports whether a file belongs to a dependency checked into
+// the project
+func isVendored(file string) bool {
+	for _, part := range strings.Split(file, "/") {
+		if part == "vendor" || part == "node_modules" {
+			return true
+		}
+	}
+	return false
+}
+
+// keyDirectories returns the top-level directories holding the most files,
+// at most n of them
+func keyDirectories(files []string, n int) []string {
+	counts := map[string]int{}
+	for _, file := range files {
+		if dir := topLevelDir(file); dir != "." {
+			counts[dir]++
+		}
+	}
+	dirs := make([]string, 0, len(counts))
+	for dir := range counts {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if counts[dirs[i]] != counts[dirs[j]] {
+			return counts[dirs[i]] > counts[dirs[j]]
+		}
+		return dirs[i] < dirs[j]
+	})
+	return dirs[:min(n, len(dirs))]
+}
+
+// countCodeFiles counts the files in a language the tiers recognise
+func countCodeFiles(files []string) int {
+	count := 0
+	for _, f := range files {
+		switch filepath.Ext(f) {
+		case ".go", ".js", ".ts", ".py", ".java", ".rs", ".rb", ".php":
+			count++
+		}
+	}
+	return count
+}
+
+// instantDescription is package.json's description, or one built from what
+// the structure shows
+func instantDescription(a *InstantAnalysis, npm *npmManifest) string {
+	if npm != nil && strings.TrimSpace(npm.Description) != "" {
+		return strings.TrimSpace(npm.Description)
+	}
+	desc := fmt.Sprintf("%s %s", a.MainLanguage, strings.ToLower(a.ProjectType))
+	switch {
+	case a.GoModule != nil && a.GoModule.Module != "":
+		desc += " (module " + a.GoModule.Module + ")"
+	case a.Package != "":
+		desc += " (package " + a.Package + ")"
+	}
+	return desc
+}
+
+// instantOverview writes overview.md from the structural facts
+func instantOverview(a *InstantAnalysis, projectPath string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", filepath.Base(projectPath))
+	fmt.Fprintf(&b, "%s.\n\n", strings.TrimSuffix(a.Description, "."))
+	b.WriteString("Structural snapshot from file names and manifests; no model has read this project yet.\n\n")
+
+	b.WriteString("## Project\n\n")
+	fmt.Fprintf(&b, "- **Type**: %s\n", a.ProjectType)
+	fmt.Fprintf(&b, "- **Language**: %s\n", a.MainLanguage)
+	if a.Framework != "" {
+		fmt.Fprintf(&b, "- **Framework**: %s\n", a.Framework)
+	}
+	if a.GoModule != nil {
+		fmt.Fprintf(&b, "- **Go module**: `%s`", a.GoModule.Module)
+		if a.GoModule.Go != "" {
+			fmt.Fprintf(&b, " (go %s)", a.GoModule.Go)
+		}
+		fmt.Fprintf(&b, ", %d direct dependencies\n", len(a.GoModule.Requires))
+	}
+	if a.Package != "" {
+		fmt.Fprintf(&b, "- **npm package**: `%s`, %d dependencies, %d dev dependencies\n", a.Package, a.NPMDependencies, a.NPMDevDependencies)
+	}
+	fmt.Fprintf(&b, "- **Files**: %d total (%d code)\n", a.TotalFiles, a.CodeFiles)
+
+	if len(a.EntryPoints) > 0 {
+		b.WriteString("\n## Entry points\n\n")
+		for _, entry := range a.EntryPoints {
+			fmt.Fprintf(&b, "- `%s`\n", entry)
+		}
+	}
+	if len(a.KeyDirectories) > 0 {
+		b.WriteString("\n## Key directories\n\n")
+		for _, dir := range a.KeyDirectories {
+			fmt.Fprintf(&b, "- `%s/`\n", dir)
+		}
+	}
+	if len(a.Scripts) > 0 {
+		b.WriteString("\n## npm scripts\n\n")
+		for _, script := range a.Scripts {
+			fmt.Fprintf(&b, "- `%s`\n", script)
+		}
+	}
+	return b.String()
+}
+
+// instantStructure writes structure.md: every directory with its file
+// count, and the files at the root
+func instantStructure(files []string) string {
+	counts := map[string]int{}
+	var rootFiles []string
+	for _, file := range files {
+		dir := path.Dir(file)
+		if dir == "." {
+			rootFiles = append(rootFiles, file)
+			continue
+		}
+		counts[dir]++
+	}
+	dirs := make([]string, 0, len(counts))
+	for dir := range counts {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var b strings.Builder
+	b.WriteString("# Structure\n\n")
+	b.WriteString("Directories and their file counts, from the file list alone.\n\n")
+	if len(dirs) > 0 {
+		b.WriteString("## Directories\n\n")
+		for _, dir := range dirs {
+			fmt.Fprintf(&b, "- `%s/` (%d)\n", dir, counts[dir])
+		}
+		b.WriteString("\n")
+	}
+	if len(rootFiles) > 0 {
+		sort.Strings(rootFiles)
+		b.WriteString("## Root files\n\n")
+		for _, file := range rootFiles {
+			fmt.Fprintf(&b, "- `%s`\n", file)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package analysis
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInstantAnalyze(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // Keep the run out of the real registry
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                 "module example.com/tool\n\ngo 1.24\n\nrequire (\n\tgithub.com/a/b v1.0.0\n\tgithub.com/c/d v1.2.0 // indirect\n)\n\nrequire github.com/e/f v0.1.0\n",
+		"main.go":                "package main\n",
+		"cmd/worker/main.go":     "package main\n",
+		"internal/app/app.go":    "package app\n",
+		"internal/app/run.go":    "package app\n",
+		"internal/app/x_test.go": "package app\n",
+		"web/package.json":       `{"name": "web"}`,
+		"web/src/index.ts":       "",
+	})
+
+	s := NewService(nil)
+	result, err := s.InstantAnalyze(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.GoModule == nil || result.GoModule.Module != "example.com/tool" || result.GoModule.Go != "1.24" {
+		t.Fatalf("GoModule = %+v", result.GoModule)
+	}
+	if want := []string{"github.com/a/b", "github.com/e/f"}; !reflect.DeepEqual(result.GoModule.Requires, want) {
+		t.Errorf("Requires = %v, want %v", result.GoModule.Requires, want)
+	}
+	if want := []string{"cmd/worker/main.go", "main.go", "web/src/index.ts"}; !reflect.DeepEqual(result.EntryPoints, want) {
+		t.Errorf("EntryPoints = %v, want %v", result.EntryPoints, want)
+	}
+	if want := []string{"internal", "web", "cmd"}; !reflect.DeepEqual(result.KeyDirectories, want) {
+		t.Errorf("KeyDirectories = %v, want %v", result.KeyDirectories, want)
+	}
+	if !strings.Contains(result.KnowledgeFiles["overview.md"], "`example.com/tool` (go 1.24), 2 direct dependencies") {
+		t.Errorf("overview.md:\n%s", result.KnowledgeFiles["overview.md"])
+	}
+
+	if source, _, ok := LoadBestKnowledge(root, "structure"); !ok || source != "instant/structure.md" {
+		t.Errorf("LoadBestKnowledge(structure) = %q, %v; want the instant tier's", source, ok)
+	}
+	if stale, err := s.IsStale(root, TierInstant); err != nil || stale {
+		t.Errorf("IsStale right after the run = %v, %v", stale, err)
+	}
+	writeFiles(t, root, map[string]string{"go.mod": "module example.com/renamed\n"})
+	if stale, _ := s.IsStale(root, TierInstant); !stale {
+		t.Error("IsStale = false after go.mod changed")
+	}
+}
+
+func TestInstantEntryPointsFromPackageJSON(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json":      `{"name": "cli", "description": "Does things.", "main": "lib/api.js", "bin": {"cli": "./bin/run.js"}, "scripts": {"test": "x", "build": "y"}}`,
+		"lib/api.js":        "",
+		"bin/run.js":        "",
+		"src/index.test.js": "",
+	})
+
+	result, err := NewService(nil).InstantAnalyze(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"lib/api.js", "bin/run.js"}; !reflect.DeepEqual(result.EntryPoints, want) {
+		t.Errorf("EntryPoints = %v, want %v", result.EntryPoints, want)
+	}
+	if result.Description != "Does things." || !reflect.DeepEqual(result.Scripts, []string{"build", "test"}) {
+		t.Errorf("Description = %q, Scripts = %v", result.Description, result.Scripts)
+	}
+}
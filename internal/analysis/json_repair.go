@@ -0,0 +1,109 @@
+package analysis
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// repairJSON recovers the JSON value starting at the first open ('[' or
+// '{') from model output that is cut off or followed by garbage, as small
+// models' output is when it hits the token limit. A value that closes is
+// returned without whatever follows it. A truncated one gets its string and
+// brackets closed; if that doesn't parse, it is cut back to the last
+// complete array element, or failing that the last complete object field,
+// rather than keep an element with made-up defaults. It returns nil when
+// nothing valid is left.
+func repairJSON(data []byte, open byte) []byte {
+	s := strings.ReplaceAll(string(data), "```json", "")
+	s = strings.ReplaceAll(s, "```", "")
+	start := strings.IndexByte(s, open)
+	if start < 0 {
+		return nil
+	}
+
+	// cut is a point the value can be truncated at, with the brackets still
+	// open there
+	type cut struct {
+		end     int
+		closers string
+		inArray bool // Between array elements rather than object fields
+	}
+	var cuts []cut
+	var stack []byte
+	inString, escaped := false, false
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '[', '{':
+			stack = append(stack, c)
+		case ']', '}':
+			if len(stack) == 0 || stack[len(stack)-1] != openerOf(c) {
+				return nil
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return []byte(s[start : i+1])
+			}
+			cuts = append(cuts, cut{i + 1, closersFor(stack), stack[len(stack)-1] == '['})
+		case ',':
+			cuts = append(cuts, cut{i, closersFor(stack), stack[len(stack)-1] == '['})
+		}
+	}
+
+	// Truncated: finish the last element, else drop it
+	tail := s[start:]
+	if inString {
+		if escaped {
+			tail = tail[:len(tail)-1]
+		}
+		tail += `"`
+	}
+	if candidate := tail + closersFor(stack); json.Valid([]byte(candidate)) {
+		return []byte(candidate)
+	}
+	for _, inArray := range []bool{true, false} {
+		for i := len(cuts) - 1; i >= 0; i-- {
+			if cuts[i].inArray != inArray {
+				continue
+			}
+			if candidate := s[start:cuts[i].end] + cuts[i].closers; json.Valid([]byte(candidate)) {
+				return []byte(candidate)
+			}
+		}
+	}
+	return nil
+}
+
+// openerOf returns the bracket a closing bracket matches
+func openerOf(c byte) byte {
+	if c == ']' {
+		return '['
+	}
+	return '{'
+}
+
+// closersFor returns the brackets that close the open ones, innermost first
+func closersFor(stack []byte) string {
+	var b strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == '[' {
+			b.WriteByte(']')
+		} else {
+			b.WriteByte('}')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,276 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/files"
+)
+
+const (
+	// diffsDir holds the latest diff of each knowledge file, under the
+	// knowledge root and mirroring the tier and modules folders
+	diffsDir = "diffs"
+	// diffContext is how many unchanged lines surround each hunk
+	diffContext = 3
+	// maxDiffCells caps the line comparison table; bigger changes are shown
+	// as the whole old file replaced by the new one
+	maxDiffCells = 4_000_000
+)
+
+// saveKnowledgeDiff compares a knowledge file about to be overwritten with
+// its new content and saves the diff, so the change in the model's
+// understanding can be read after a rerun. Nothing is saved for a new or
+// unchanged file; the previous diff then stays.
+func (s *service) saveKnowledgeDiff(projectPath, folder, filename, previousPath, content string) error {
+	info, err := os.Stat(previousPath)
+	if err != nil {
+		return nil
+	}
+	previous, err := os.ReadFile(previousPath)
+	if err != nil || string(previous) == content {
+		return nil
+	}
+
+	name := folder + "/" + filepath.ToSlash(filename)
+	commit := gitShortHead(projectPath)
+	diff := UnifiedDiff(
+		fmt.Sprintf("%s\t%s", name, info.ModTime().Format("2006-01-02 15:04")),
+		fmt.Sprintf("%s\t%s%s", name, time.Now().Format("2006-01-02 15:04"), commit),
+		string(previous), content,
+	)
+
+	diffPath := filepath.Join(knowledgeDir(projectPath), diffsDir, folder, filename+".diff")
+	if err := os.MkdirAll(filepath.Dir(diffPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(diffPath, []byte(diff), 0644)
+}
+
+// LoadKnowledgeDiff reads the diff saved the last time a knowledge file
+// changed. target is "<tier>/<file>", e.g. "deep/overview.md", or
+// "modules/<file>" for a module doc.
+func LoadKnowledgeDiff(projectPath, target string) (string, error) {
+	target = strings.TrimSuffix(filepath.ToSlash(strings.TrimSpace(target)), ".diff")
+	folder, file, ok := strings.Cut(target, "/")
+	if !ok || file == "" || strings.Contains(target, "..") {
+		return "", fmt.Errorf("expected <tier>/<file>, such as deep/overview.md, got %q", target)
+	}
+	if folder != modulesDir && tierRank(Tier(folder)) < 0 {
+		return "", fmt.Errorf("unknown tier %q", folder)
+	}
+
+	data, err := os.ReadFile(filepath.Join(knowledgeDir(projectPath), diffsDir, folder, filepath.FromSlash(file)+".diff"))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ListKnowledgeDiffs returns the knowledge files with a saved diff as
+// "<tier>/<file>", sorted
+func ListKnowledgeDiffs(projectPath string) ([]string, error) {
+	root := filepath.Join(knowledgeDir(projectPath), diffsDir)
+	var targets []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".diff") {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, strings.TrimSuffix(filepath.ToSlash(rel), ".diff"))
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	sort.Strings(targets)
+	return targets, nil
+}
+
+// gitShortHead returns " (<commit>)" for the project's HEAD, or "" outside
+// a repository
+func gitShortHead(projectPath string) string {
+	out, err := files.Git(context.Background(), projectPath, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return " (" + strings.TrimSpace(string(out)) + ")"
+}
+
+// diffOp is one line of a diff: ' ' kept, '-' removed or '+' added
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// UnifiedDiff renders the line changes from old to new as a unified diff
+func UnifiedDiff(oldName, newName, before, after string) string {
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	var sb strings.Builder
+	sb.WriteString("--- " + oldName + "\n")
+	sb.WriteString("+++ " + newName + "\n")
+
+	// Walk the ops, cutting a hunk wherever more than twice the context of
+	// unchanged lines separates two changes
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		start := max(0, i-diffContext)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContext {
+				end = min(run, end+diffContext)
+				break
+			}
+			end = run
+		}
+
+		hunkOld, hunkNew := oldLine-(i-start), newLine-(i-start)
+		oldCount, newCount := 0, 0
+		var body strings.Builder
+		for _, op := range ops[start:end] {
+			body.WriteString(string(op.kind) + op.line + "\n")
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(hunkOld, oldCount), hunkRange(hunkNew, newCount)))
+		sb.WriteString(body.String())
+
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+	return sb.String()
+}
+
+// hunkRange formats a hunk's start and length; an empty range starts on
+// the line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// diffLines finds the longest common subsequence of two line lists and
+// returns the edit script between them
+func diffLines(a, b []string) []diffOp {
+	// Common prefix and suffix need no table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// diffMiddle diffs what is left between the common prefix and suffix
+func diffMiddle(a, b []string) []diffOp {
+	var ops []diffOp
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the common subsequence length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// splitLines splits text into lines without the trailing empty one
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
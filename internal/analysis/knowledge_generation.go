@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/billie-coop/loco/internal/crash"
 	"github.com/billie-coop/loco/internal/llm"
 )
 
@@ -33,6 +34,7 @@ func (s *service) generateFileSummaries(ctx context.Context, projectPath string,
 	for i, file := range files {
 		wg.Add(1)
 		go func(index int, filePath string) {
+			defer crash.Recover("knowledge worker")
 			defer wg.Done()
 			// Ensure progress is reported even on early returns
 			defer func() {
@@ -40,7 +42,7 @@ func (s *service) generateFileSummaries(ctx context.Context, projectPath string,
 				processed++
 				completed := processed
 				mu.Unlock()
-				ReportProgress(ctx, Progress{
+				s.reportProgress(ctx, Progress{
 					Phase:          string(TierQuick),
 					TotalFiles:     len(files),
 					CompletedFiles: completed,
@@ -147,7 +149,7 @@ func (s *service) generateKnowledgeDocuments(ctx context.Context, projectPath st
 	compactStr := string(compactJSON)
 
 	// Step 1: Generate structure.md (runs first)
-	structureContent, err := s.generateStructureDoc(ctx, compactStr)
+	structureContent, err := s.generateStructureDoc(ctx, projectPath, compactStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate structure.md: %w", err)
 	}
@@ -161,13 +163,15 @@ func (s *service) generateKnowledgeDocuments(ctx context.Context, projectPath st
 	wg.Add(2)
 
 	go func() {
+		defer crash.Recover("knowledge generation")
 		defer wg.Done()
-		patternsContent, patternsErr = s.generatePatternsDoc(ctx, compactStr, structureContent)
+		patternsContent, patternsErr = s.generatePatternsDoc(ctx, projectPath, compactStr, structureContent)
 	}()
 
 	go func() {
+		defer crash.Recover("knowledge generation")
 		defer wg.Done()
-		contextContent, contextErr = s.generateContextDoc(ctx, compactStr, structureContent)
+		contextContent, contextErr = s.generateContextDoc(ctx, projectPath, compactStr, structureContent)
 	}()
 
 	wg.Wait()
@@ -183,7 +187,7 @@ func (s *service) generateKnowledgeDocuments(ctx context.Context, projectPath st
 	knowledgeFiles["context.md"] = contextContent
 
 	// Step 3: Generate overview.md (runs last, uses all previous)
-	overviewContent, err := s.generateOverviewDoc(ctx, compactStr, structureContent, patternsContent, contextContent)
+	overviewContent, err := s.generateOverviewDoc(ctx, projectPath, compactStr, structureContent, patternsContent, contextContent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate overview.md: %w", err)
 	}
@@ -193,20 +197,8 @@ func (s *service) generateKnowledgeDocuments(ctx context.Context, projectPath st
 }
 
 // generateStructureDoc creates the structure.md document.
-func (s *service) generateStructureDoc(ctx context.Context, compactSummaries string) (string, error) {
-	prompt := fmt.Sprintf(`Analyze this project's file structure and create a comprehensive structure.md document.
-
-File Summaries (path + summary only):
-%s
-
-Create a markdown document that covers:
-1. Directory layout and organization
-2. Key files and their roles
-3. Module structure and dependencies
-4. Entry points and main components
-5. Configuration files and their purposes
-
-Format as a proper markdown document with sections and bullet points.`, compactSummaries)
+func (s *service) generateStructureDoc(ctx context.Context, projectPath, compactSummaries string) (string, error) {
+	prompt := s.renderPrompt(projectPath, "knowledge_structure", map[string]any{"Summaries": compactSummaries})
 
 	messages := []llm.Message{
 		{
@@ -223,24 +215,8 @@ Format as a proper markdown document with sections and bullet points.`, compactS
 }
 
 // generatePatternsDoc creates the patterns.md document.
-func (s *service) generatePatternsDoc(ctx context.Context, compactSummaries, structureDoc string) (string, error) {
-	prompt := fmt.Sprintf(`Analyze this project's development patterns and create a patterns.md document.
-
-File Summaries (path + summary only):
-%s
-
-Project Structure:
-%s
-
-Create a markdown document that covers:
-1. Code style and conventions
-2. Design patterns used
-3. Data flow patterns
-4. Common operations and utilities
-5. Testing patterns
-6. Error handling patterns
-
-Format as a proper markdown document with sections and code examples where relevant.`, compactSummaries, structureDoc)
+func (s *service) generatePatternsDoc(ctx context.Context, projectPath string, compactSummaries, structureDoc string) (string, error) {
+	prompt := s.renderPrompt(projectPath, "knowledge_patterns", map[string]any{"Summaries": compactSummaries, "Structure": structureDoc})
 
 	messages := []llm.Message{
 		{
@@ -257,24 +233,8 @@ Format as a proper markdown document with sections and code examples where relev
 }
 
 // generateContextDoc creates the context.md document.
-func (s *service) generateContextDoc(ctx context.Context, compactSummaries, structureDoc string) (string, error) {
-	prompt := fmt.Sprintf(`Analyze this project's purpose and context to create a context.md document.
-
-File Summaries (path + summary only):
-%s
-
-Project Structure:
-%s
-
-Create a markdown document that covers:
-1. Project purpose and goals
-2. Business logic and domain
-3. Key design decisions
-4. Problem it solves
-5. Target users/audience
-6. Integration points
-
-Format as a proper markdown document with clear explanations.`, compactSummaries, structureDoc)
+func (s *service) generateContextDoc(ctx context.Context, projectPath string, compactSummaries, structureDoc string) (string, error) {
+	prompt := s.renderPrompt(projectPath, "knowledge_context", map[string]any{"Summaries": compactSummaries, "Structure": structureDoc})
 
 	messages := []llm.Message{
 		{
@@ -291,33 +251,10 @@ Format as a proper markdown document with clear explanations.`, compactSummaries
 }
 
 // generateOverviewDoc creates the overview.md document.
-func (s *service) generateOverviewDoc(ctx context.Context, compactSummaries, structureDoc, patternsDoc, contextDoc string) (string, error) {
-	prompt := fmt.Sprintf(`Create a comprehensive overview.md document that summarizes this entire project.
-
-You have access to:
-1. Compact file summaries (path + summary only)
-2. Structure documentation
-3. Patterns documentation
-4. Context documentation
-
-Structure Document:
-%s
-
-Patterns Document:
-%s
-
-Context Document:
-%s
-
-Create a markdown document that provides:
-1. Executive summary (2-3 paragraphs)
-2. Technology stack
-3. Key features and capabilities
-4. Quick start guide
-5. Architecture highlights
-6. Development workflow
-
-This should be the go-to document for understanding the project quickly.`, structureDoc, patternsDoc, contextDoc)
+func (s *service) generateOverviewDoc(ctx context.Context, projectPath string, compactSummaries, structureDoc, patternsDoc, contextDoc string) (string, error) {
+	prompt := s.renderPrompt(projectPath, "knowledge_overview", map[string]any{
+		"Structure": structureDoc, "Patterns": patternsDoc, "Context": contextDoc,
+	})
 
 	messages := []llm.Message{
 		{
@@ -347,10 +284,10 @@ func (s *service) completeWithContext(ctx context.Context, messages []llm.Messag
 
 // buildCompactSummaries selects up to maxN entries as {path, summary}, prioritizing non-empty summaries.
 func buildCompactSummaries(fileSummaries *FileAnalysisResult, maxN int) []map[string]string {
-	type pair struct{ Path, Summary string }
+	type pair struct{ Path, Summary, Exports string }
 	pairs := make([]pair, 0, len(fileSummaries.Files))
 	for _, f := range fileSummaries.Files {
-		p := pair{Path: f.Path, Summary: strings.TrimSpace(f.Summary)}
+		p := pair{Path: f.Path, Summary: strings.TrimSpace(f.Summary), Exports: strings.Join(f.Exports, ", ")}
 		pairs = append(pairs, p)
 	}
 	// Prioritize non-empty summaries
@@ -370,14 +307,24 @@ func buildCompactSummaries(fileSummaries *FileAnalysisResult, maxN int) []map[st
 		if p.Summary == "" {
 			continue // drop empties to save space
 		}
-		out = append(out, map[string]string{"path": p.Path, "summary": p.Summary})
+		entry := map[string]string{"path": p.Path, "summary": p.Summary}
+		if p.Exports != "" {
+			entry["exports"] = p.Exports
+		}
+		out = append(out, entry)
 	}
 	return out
 }
 
 // saveKnowledgeFiles saves knowledge documents to disk.
 func (s *service) saveKnowledgeFiles(projectPath string, tier Tier, files map[string]string) error {
-	knowledgePath := filepath.Join(projectPath, s.cachePath, "knowledge", string(tier))
+	return s.saveKnowledgeFolder(projectPath, string(tier), files)
+}
+
+// saveKnowledgeFolder saves documents into a folder of .loco/knowledge/,
+// keeping a diff of each one that changed.
+func (s *service) saveKnowledgeFolder(projectPath string, folder string, files map[string]string) error {
+	knowledgePath := filepath.Join(knowledgeDir(projectPath), folder)
 
 	if err := os.MkdirAll(knowledgePath, 0755); err != nil {
 		return err
@@ -385,6 +332,12 @@ func (s *service) saveKnowledgeFiles(projectPath string, tier Tier, files map[st
 
 	for filename, content := range files {
 		filePath := filepath.Join(knowledgePath, filename)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return err
+		}
+		if err := s.saveKnowledgeDiff(projectPath, folder, filename, filePath, content); err != nil {
+			return err
+		}
 		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
 			return err
 		}
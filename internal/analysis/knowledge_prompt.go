@@ -0,0 +1,31 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// knowledgeSources lists where each kind of knowledge doc is saved, from
+// the most to the least refined
+var knowledgeSources = map[string][]string{
+	"overview":  {"deep/overview.md", "detailed/overview.md", "quick/summary.md", "instant/overview.md"},
+	"structure": {"full/ARCHITECTURE.md", "deep/structure.md", "detailed/structure.md", "instant/structure.md"},
+	"patterns":  {"deep/patterns.md", "detailed/patterns.md"},
+}
+
+// LoadBestKnowledge returns the most refined saved doc of a kind,
+// "overview", "structure" or "patterns", and the knowledge file it came
+// from, without frontmatter. ok is false when no tier has written one yet.
+func LoadBestKnowledge(projectPath, kind string) (source, content string, ok bool) {
+	for _, source := range knowledgeSources[kind] {
+		data, err := os.ReadFile(filepath.Join(knowledgeDir(projectPath), filepath.FromSlash(source)))
+		if err != nil {
+			continue
+		}
+		if content := strings.TrimSpace(stripFrontmatter(string(data))); content != "" {
+			return source, content, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,181 @@
+package analysis
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Merge strategies for the quick tier's worker rankings, set with
+// analysis.quick.merge_strategy
+const (
+	MergeMean   = "mean"   // Mean importance of the votes a file got (default)
+	MergeMedian = "median" // Median importance, so one excited worker can't lift a file
+	MergeBorda  = "borda"  // Points by list position, so files left out of a list score 0 there
+	MergeFocus  = "focus"  // Mean importance, counting votes in a worker's focus double
+)
+
+// focusWeight is how much a vote counts when the file's category is the
+// worker's focus, under MergeFocus
+const focusWeight = 2.0
+
+// WorkerRanking is one quick worker's ranked files and its focus
+type WorkerRanking struct {
+	Focus    string
+	Rankings []FileRanking // Best first
+}
+
+// workerVote is one worker's ranking of a file
+type workerVote struct {
+	Ranking FileRanking
+	Rank    int // Position in the worker's list, 0 first
+	Listed  int // Files the worker listed
+	Focus   string
+}
+
+// MergeStrategy scores a file from the workers' votes for it
+type MergeStrategy interface {
+	// Score returns a file's merged importance on the workers' 1-10 scale,
+	// given its votes (in worker order) out of workers rankings
+	Score(votes []workerVote, workers int) float64
+}
+
+// mergeStrategies maps merge_strategy names to strategies
+var mergeStrategies = map[string]MergeStrategy{
+	MergeMean:   meanStrategy{},
+	MergeMedian: medianStrategy{},
+	MergeBorda:  bordaStrategy{},
+	MergeFocus:  focusStrategy{},
+}
+
+// mergeStrategyFor returns the named strategy; empty means MergeMean
+func mergeStrategyFor(name string) (MergeStrategy, error) {
+	if name == "" {
+		return meanStrategy{}, nil
+	}
+	strategy, ok := mergeStrategies[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown merge strategy %q (want mean, median, borda or focus)", name)
+	}
+	return strategy, nil
+}
+
+// mergeRankings combines each worker's top take files into one list scored
+// by strategy. Every strategy shares the rest: a file's vote count, the
+// reason from its highest-scored vote and the first category other than
+// "other". The list is ordered by votes, then score, then path.
+func mergeRankings(workers []WorkerRanking, take int, strategy MergeStrategy) []FileRanking {
+	votes := map[string][]workerVote{}
+	voters := 0
+	for _, worker := range workers {
+		if len(worker.Rankings) == 0 {
+			continue
+		}
+		voters++
+		list := slices.Clone(worker.Rankings)
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Importance > list[j].Importance })
+		list = list[:min(take, len(list))]
+		for rank, fr := range list {
+			fr.Category = normalizeCategory(fr.Category)
+			fr.Reason = truncate(fr.Reason, 120)
+			votes[fr.Path] = append(votes[fr.Path], workerVote{Ranking: fr, Rank: rank, Listed: len(list), Focus: worker.Focus})
+		}
+	}
+
+	merged := make([]FileRanking, 0, len(votes))
+	for path, fileVotes := range votes {
+		fr := FileRanking{Path: path, VoteCount: len(fileVotes), Importance: strategy.Score(fileVotes, voters), Category: "other"}
+		best := -1.0
+		for _, vote := range fileVotes {
+			if vote.Ranking.Importance > best && strings.TrimSpace(vote.Ranking.Reason) != "" {
+				best = vote.Ranking.Importance
+				fr.Reason = vote.Ranking.Reason
+			}
+			if fr.Category == "other" && vote.Ranking.Category != "" {
+				fr.Category = vote.Ranking.Category
+			}
+		}
+		merged = append(merged, fr)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].VoteCount != merged[j].VoteCount {
+			return merged[i].VoteCount > merged[j].VoteCount
+		}
+		if merged[i].Importance != merged[j].Importance {
+			return merged[i].Importance > merged[j].Importance
+		}
+		return merged[i].Path < merged[j].Path
+	})
+	return merged
+}
+
+// meanStrategy averages the importance of the votes
+type meanStrategy struct{}
+
+func (meanStrategy) Score(votes []workerVote, workers int) float64 {
+	var sum float64
+	for _, vote := range votes {
+		sum += vote.Ranking.Importance
+	}
+	return sum / float64(len(votes))
+}
+
+// medianStrategy takes the middle importance of the votes
+type medianStrategy struct{}
+
+func (medianStrategy) Score(votes []workerVote, workers int) float64 {
+	scores := make([]float64, len(votes))
+	for i, vote := range votes {
+		scores[i] = vote.Ranking.Importance
+	}
+	slices.Sort(scores)
+	mid := len(scores) / 2
+	if len(scores)%2 == 0 {
+		return (scores[mid-1] + scores[mid]) / 2
+	}
+	return scores[mid]
+}
+
+// bordaStrategy gives a file listed n-th of k points (k-n)/k from each
+// worker, 0 from workers that left it out, and scales the total to 10
+type bordaStrategy struct{}
+
+func (bordaStrategy) Score(votes []workerVote, workers int) float64 {
+	var points float64
+	for _, vote := range votes {
+		points += float64(vote.Listed-vote.Rank) / float64(vote.Listed)
+	}
+	return 10 * points / float64(workers)
+}
+
+// focusStrategy averages importance, counting a vote focusWeight times when
+// the file's category is what the worker was asked to look for
+type focusStrategy struct{}
+
+func (focusStrategy) Score(votes []workerVote, workers int) float64 {
+	var sum, weights float64
+	for _, vote := range votes {
+		weight := 1.0
+		if inFocus(vote.Focus, vote.Ranking.Category) {
+			weight = focusWeight
+		}
+		sum += weight * vote.Ranking.Importance
+		weights += weight
+	}
+	return sum / weights
+}
+
+// inFocus reports whether a category is one a focus like "tests/docs" asks
+// for: some part of the focus starts with the category's name
+func inFocus(focus, category string) bool {
+	if category == "" || category == "other" {
+		return false
+	}
+	for _, part := range strings.FieldsFunc(strings.ToLower(focus), func(r rune) bool { return r == '/' || r == ' ' || r == ',' }) {
+		if strings.HasPrefix(part, category) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,301 @@
+package analysis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+const (
+	// modulesDir is the knowledge folder holding one doc per significant
+	// directory, shared by the tiers
+	modulesDir = "modules"
+	// moduleIndexFile lists the module docs with a line on each
+	moduleIndexFile = "INDEX.md"
+	// moduleManifestFile records what each module doc was generated from
+	moduleManifestFile = "module_docs.json"
+	// maxModuleDocs caps how many directories get a doc; the most connected
+	// and biggest come first
+	maxModuleDocs = 30
+	// minModuleFiles is how many summarized files make a directory
+	// significant on size alone
+	minModuleFiles = 3
+)
+
+// moduleDocEntry records what a module doc was generated from, so a run
+// only regenerates docs whose inputs changed
+type moduleDocEntry struct {
+	Fingerprint string    `json:"fingerprint"`
+	Tier        Tier      `json:"tier"`
+	Model       string    `json:"model,omitempty"`
+	Generated   time.Time `json:"generated"`
+}
+
+// moduleDirectory is a directory worth its own doc
+type moduleDirectory struct {
+	Path      string
+	Language  string
+	Files     []string // Files with a summary
+	DependsOn []string
+	UsedBy    []string
+}
+
+// moduleDocName is the file a directory's doc is saved as under modules/
+func moduleDocName(dir string) string {
+	if dir == "." {
+		return "root.md"
+	}
+	return strings.ReplaceAll(dir, "/", "_") + ".md"
+}
+
+// loadCanonicalSummaries reads the per-file summaries earlier tiers saved
+func (s *service) loadCanonicalSummaries(projectPath string) map[string]canonicalFileSummary {
+	summaries := map[string]canonicalFileSummary{}
+	if data, err := os.ReadFile(filepath.Join(knowledgeDir(projectPath), "file_summaries.json")); err == nil {
+		_ = json.Unmarshal(data, &summaries)
+	}
+	return summaries
+}
+
+// SummarizedFiles returns the project files the canonical
+// .loco/knowledge/file_summaries.json describes, sorted. It is nil when no
+// tier has written one yet.
+func SummarizedFiles(projectPath string) []string {
+	data, err := os.ReadFile(filepath.Join(knowledgeDir(projectPath), "file_summaries.json"))
+	if err != nil {
+		return nil
+	}
+	var summaries map[string]canonicalFileSummary
+	if json.Unmarshal(data, &summaries) != nil {
+		return nil
+	}
+	paths := make([]string, 0, len(summaries))
+	for path := range summaries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// summaryText is the best description of a file the summaries hold
+func summaryText(summary canonicalFileSummary) string {
+	if text := strings.TrimSpace(summary.Summary); text != "" {
+		return text
+	}
+	return strings.TrimSpace(summary.Purpose)
+}
+
+// selectModuleDirectories picks the directories worth a doc: those with
+// enough summarized files, and code modules other modules import. The ones
+// most others depend on come first, then the biggest.
+func selectModuleDirectories(summaries map[string]canonicalFileSummary, graph *DependencyGraph) []moduleDirectory {
+	byDir := map[string][]string{}
+	for path, summary := range summaries {
+		if summaryText(summary) != "" {
+			dir := filepath.ToSlash(filepath.Dir(path))
+			byDir[dir] = append(byDir[dir], path)
+		}
+	}
+
+	var dirs []moduleDirectory
+	for dir, files := range byDir {
+		sort.Strings(files)
+		module := moduleDirectory{Path: dir, Files: files}
+		if graph != nil {
+			if node := graph.Module(dir); node != nil {
+				module.Language = node.Language
+				module.DependsOn = node.DependsOn
+				module.UsedBy = node.UsedBy
+			}
+		}
+		if len(files) >= minModuleFiles || len(module.UsedBy) > 0 {
+			dirs = append(dirs, module)
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if len(dirs[i].UsedBy) != len(dirs[j].UsedBy) {
+			return len(dirs[i].UsedBy) > len(dirs[j].UsedBy)
+		}
+		if len(dirs[i].Files) != len(dirs[j].Files) {
+			return len(dirs[i].Files) > len(dirs[j].Files)
+		}
+		return dirs[i].Path < dirs[j].Path
+	})
+	return dirs[:min(maxModuleDocs, len(dirs))]
+}
+
+// moduleFingerprint hashes everything a module doc is generated from
+func moduleFingerprint(module moduleDirectory, summaries map[string]canonicalFileSummary) string {
+	h := sha256.New()
+	for _, file := range module.Files {
+		fmt.Fprintf(h, "%s\x00%s\x00", file, summaryText(summaries[file]))
+	}
+	fmt.Fprintf(h, "%s\x00%s", strings.Join(module.DependsOn, ","), strings.Join(module.UsedBy, ","))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// tierRank orders the tiers from the least to the most capable
+func tierRank(tier Tier) int {
+	for i, t := range knowledgeTiers {
+		if t == tier {
+			return i
+		}
+	}
+	return -1
+}
+
+// updateModuleDocs writes knowledge/modules/<dir>.md for each significant
+// directory from its files' summaries and its place in the graph, plus an
+// INDEX.md linking them. A doc is regenerated only when its summaries or
+// imports changed or a lower tier wrote it with another model, so reruns on
+// a large repo only pay for the modules that moved. Human-verified sections
+// of a doc are kept. It returns every current doc by directory; a module
+// whose doc fails keeps its old one, if any.
+func (s *service) updateModuleDocs(ctx context.Context, projectPath string, tier Tier, graph *DependencyGraph) map[string]string {
+	summaries := s.loadCanonicalSummaries(projectPath)
+	modules := selectModuleDirectories(summaries, graph)
+	dir := filepath.Join(knowledgeDir(projectPath), modulesDir)
+
+	manifest := map[string]moduleDocEntry{}
+	manifestPath := filepath.Join(knowledgeDir(projectPath), moduleManifestFile)
+	if data, err := os.ReadFile(manifestPath); err == nil {
+		_ = json.Unmarshal(data, &manifest)
+	}
+
+	model := ""
+	if lm, ok := s.llmClient.(*llm.LMStudioClient); ok {
+		model = lm.CurrentModel()
+	}
+
+	docs := map[string]string{}
+	changed := map[string]string{}
+	stamp := analysisStamp(projectPath)
+	for i, module := range modules {
+		name := moduleDocName(module.Path)
+		fingerprint := moduleFingerprint(module, summaries)
+		previous, _ := os.ReadFile(filepath.Join(dir, name))
+		entry, ok := manifest[module.Path]
+		upgrade := tierRank(entry.Tier) < tierRank(tier) && entry.Model != model
+		if ok && len(previous) > 0 && entry.Fingerprint == fingerprint && !upgrade {
+			docs[module.Path] = string(previous)
+			continue
+		}
+
+		doc, err := s.generateModuleDoc(ctx, module, summaries)
+		if err == nil && strings.TrimSpace(doc) != "" {
+			doc = applyVerifiedSections(doc, extractVerifiedSections(string(previous)))
+			docs[module.Path] = doc
+			changed[name] = doc
+			manifest[module.Path] = moduleDocEntry{Fingerprint: fingerprint, Tier: tier, Model: model, Generated: stamp}
+		} else if len(previous) > 0 {
+			docs[module.Path] = string(previous)
+		}
+		s.reportProgress(ctx, Progress{
+			Phase:          string(tier),
+			TotalFiles:     len(modules),
+			CompletedFiles: i + 1,
+			CurrentFile:    modulesDir + "/" + name,
+		})
+	}
+
+	// Docs of directories that are gone from the project go with them
+	for path := range manifest {
+		if _, err := os.Stat(filepath.Join(projectPath, path)); os.IsNotExist(err) {
+			_ = os.Remove(filepath.Join(dir, moduleDocName(path)))
+			delete(manifest, path)
+		}
+	}
+
+	changed[moduleIndexFile] = formatModuleIndex(modules, docs)
+	_ = s.saveKnowledgeFolder(projectPath, modulesDir, changed)
+	_ = s.saveKnowledgeRootJSON(projectPath, moduleManifestFile, manifest)
+	return docs
+}
+
+// generateModuleDoc asks the model for one directory's doc
+func (s *service) generateModuleDoc(ctx context.Context, module moduleDirectory, summaries map[string]canonicalFileSummary) (string, error) {
+	if s.llmClient == nil {
+		return "", fmt.Errorf("LLM client not available")
+	}
+
+	var files strings.Builder
+	for _, file := range module.Files {
+		files.WriteString(fmt.Sprintf("- %s: %s\n", file, summaryText(summaries[file])))
+	}
+	language := module.Language
+	if language == "" {
+		language = "mixed"
+	}
+
+	prompt := fmt.Sprintf(`Write the doc for one module (directory) of this project.
+
+Module: %s (%s)
+Depends on: %s
+Used by: %s
+
+Files and what earlier analysis found in them:
+%s
+Create a markdown document with:
+1. Responsibility: what this module owns, in 2-3 sentences
+2. Key components: the main types, functions or files and what they do
+3. Dependencies: why it needs each module it depends on
+4. Consumers: what the modules using it rely on
+5. Notes: invariants, gotchas or design decisions worth knowing
+
+Only state what the summaries support; say so when something is unclear.`,
+		module.Path, language, listOrNone(module.DependsOn), listOrNone(module.UsedBy), files.String())
+
+	messages := []llm.Message{
+		{
+			Role:    "system",
+			Content: "You are a software architect documenting one module of a codebase. Be precise and concise.",
+		},
+		{
+			Role:    "user",
+			Content: prompt,
+		},
+	}
+
+	return s.completeWithContext(ctx, messages, 16384)
+}
+
+// formatModuleIndex lists the module docs with the first line of each
+func formatModuleIndex(modules []moduleDirectory, docs map[string]string) string {
+	var sb strings.Builder
+	sb.WriteString("# Modules\n\n")
+	sb.WriteString("One doc per significant directory, most depended on first.\n\n")
+	for _, module := range modules {
+		doc, ok := docs[module.Path]
+		if !ok {
+			continue
+		}
+		line := fmt.Sprintf("- [%s](%s) (%d files", module.Path, moduleDocName(module.Path), len(module.Files))
+		if len(module.UsedBy) > 0 {
+			line += fmt.Sprintf(", used by %d", len(module.UsedBy))
+		}
+		line += ")"
+		if summary := firstSentence(firstParagraphs(stripFrontmatter(doc), 1)); summary != "" {
+			line += ": " + summary
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// firstSentence returns the first sentence of a paragraph on one line
+func firstSentence(paragraph string) string {
+	paragraph = strings.Join(strings.Fields(paragraph), " ")
+	if i := strings.Index(paragraph, ". "); i >= 0 {
+		return paragraph[:i+1]
+	}
+	return paragraph
+}
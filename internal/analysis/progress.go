@@ -24,6 +24,16 @@ func WithProgressCallback(ctx context.Context, cb ProgressCallback) context.Cont
 	return context.WithValue(ctx, progressCallbackKey{}, cb)
 }
 
+// reportProgress sends an update to the context's callback and to the
+// service's publisher. The publisher sees every run, including background
+// ones started without the caller's context.
+func (s *service) reportProgress(ctx context.Context, p Progress) {
+	ReportProgress(ctx, p)
+	if s.progress != nil {
+		s.progress(p)
+	}
+}
+
 // ReportProgress invokes the progress callback in the context if present.
 func ReportProgress(ctx context.Context, p Progress) {
 	if ctx == nil {
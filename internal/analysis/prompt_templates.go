@@ -0,0 +1,66 @@
+package analysis
+
+import (
+	"bytes"
+	"embed"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// promptsDir holds the prompt templates: the embedded defaults here, and a
+// project's overrides under .loco/prompts/
+const promptsDir = "prompts"
+
+//go:embed prompts/*.tmpl
+var promptFiles embed.FS
+
+// defaultPrompts are the embedded templates by name, parsed once
+var defaultPrompts = func() map[string]*template.Template {
+	entries, err := promptFiles.ReadDir(promptsDir)
+	if err != nil {
+		panic(err)
+	}
+	prompts := make(map[string]*template.Template, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".tmpl")
+		data, err := promptFiles.ReadFile(promptsDir + "/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		prompts[name] = template.Must(template.New(name).Option("missingkey=error").Parse(string(data)))
+	}
+	return prompts
+}()
+
+// renderPrompt fills in the prompt template with the given name
+func (s *service) renderPrompt(projectPath, name string, data map[string]any) string {
+	return RenderPrompt(projectPath, name, data)
+}
+
+// RenderPrompt fills in the prompt template with the given name. A project
+// can tune any prompt by saving its own version as
+// .loco/prompts/<name>.tmpl; one that fails to parse or render falls back
+// to the embedded default, so a broken edit never stops an analysis.
+func RenderPrompt(projectPath, name string, data map[string]any) string {
+	if content, err := os.ReadFile(filepath.Join(locoDir(projectPath), promptsDir, name+".tmpl")); err == nil {
+		if tmpl, err := template.New(name).Option("missingkey=error").Parse(string(content)); err == nil {
+			if prompt, err := executePrompt(tmpl, data); err == nil {
+				return prompt
+			}
+		}
+	}
+	prompt, _ := executePrompt(defaultPrompts[name], data)
+	return prompt
+}
+
+// executePrompt renders a template without the trailing newline its file
+// ends with
+func executePrompt(tmpl *template.Template, data map[string]any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
@@ -46,13 +46,19 @@ func (s *service) generateQuickKnowledge(ctx context.Context, projectPath string
 		}
 	}
 
-	files["summary.md"] = summary
+	files["summary.md"] = withConsensusFrontmatter(summary, consensus.Metrics)
+	s.preserveVerifiedSections(projectPath, TierQuick, files)
 
 	// Save under quick tier
 	_ = s.saveKnowledgeFiles(projectPath, TierQuick, files)
 
-	// Also save a compact JSON for debugging/reference
-	_ = s.saveKnowledgeRootJSON(projectPath, filepath.Join("quick", "adjudicated_summary.json"), consensus)
+	// Also save a compact JSON for debugging/reference; how long it took
+	// differs between otherwise identical deterministic runs
+	saved := *consensus
+	if deterministic(projectPath) {
+		saved.ConsensusTime = 0
+	}
+	_ = s.saveKnowledgeRootJSON(projectPath, filepath.Join("quick", "adjudicated_summary.json"), saved)
 
 	return files, nil
 }
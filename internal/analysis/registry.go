@@ -0,0 +1,204 @@
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RegistryEntry is what the global registry keeps about one analyzed
+// project: its knowledge documents from each tier run on it, so sessions in
+// other projects can search and quote them.
+type RegistryEntry struct {
+	Name    string                `json:"name"`
+	Path    string                `json:"path"`
+	Summary string                `json:"summary"` // One sentence from the most complete tier
+	Updated time.Time             `json:"updated"`
+	Tiers   map[Tier]RegistryTier `json:"tiers"`
+}
+
+// RegistryTier is one tier's knowledge in a registry entry
+type RegistryTier struct {
+	Generated time.Time         `json:"generated"`
+	Knowledge map[string]string `json:"knowledge"`
+}
+
+// Best returns the most complete tier in the entry
+func (e *RegistryEntry) Best() (Tier, RegistryTier, bool) {
+	for _, tier := range []Tier{TierFull, TierDeep, TierDetailed, TierQuick, TierInstant} {
+		if t, ok := e.Tiers[tier]; ok {
+			return tier, t, true
+		}
+	}
+	return "", RegistryTier{}, false
+}
+
+// RegistryMatch is a registry entry found by SearchRegistry
+type RegistryMatch struct {
+	Entry *RegistryEntry
+	Score int
+	Lines []string // Knowledge lines mentioning the query
+}
+
+// registryDir is ~/.loco/registry, one JSON file per project
+func registryDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".loco", "registry"), nil
+}
+
+// registryFileName names a project's registry file after the project, with
+// a hash of its path so projects of the same name don't collide
+var registryFileName = func() func(name, path string) string {
+	unsafe := regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	return func(name, path string) string {
+		sum := sha256.Sum256([]byte(path))
+		return unsafe.ReplaceAllString(name, "_") + "-" + hex.EncodeToString(sum[:4]) + ".json"
+	}
+}()
+
+// registryName is how a project is known in the registry: its directory's
+// name, or <workspace>/<subproject> for a workspace subproject
+func registryName(projectPath string) string {
+	if root, name, ok := workspaceOf(projectPath); ok {
+		return filepath.Base(root) + "/" + name
+	}
+	return filepath.Base(projectPath)
+}
+
+// RegisterAnalysis records an analysis's knowledge in the global registry,
+// replacing what the project's previous run of that tier left there
+func RegisterAnalysis(projectPath string, a Analysis) error {
+	dir, err := registryDir()
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return err
+	}
+	name := registryName(abs)
+	path := filepath.Join(dir, registryFileName(name, abs))
+
+	entry := &RegistryEntry{Name: name, Path: abs, Tiers: make(map[Tier]RegistryTier)}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, entry) // A damaged entry is rebuilt
+		if entry.Tiers == nil {
+			entry.Tiers = make(map[Tier]RegistryTier)
+		}
+	}
+	entry.Tiers[a.GetTier()] = RegistryTier{Generated: a.GetGenerated(), Knowledge: a.GetKnowledgeFiles()}
+	entry.Updated = time.Now()
+	if tier, _, _ := entry.Best(); tier == a.GetTier() || entry.Summary == "" {
+		entry.Summary = subprojectPurpose(a)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadRegistry returns every project in the registry, most recently
+// updated first. A missing registry is empty.
+func LoadRegistry() ([]*RegistryEntry, error) {
+	dir, err := registryDir()
+	if err != nil {
+		return nil, err
+	}
+	names, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*RegistryEntry
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		var entry RegistryEntry
+		if err := json.Unmarshal(data, &entry); err != nil || entry.Name == "" {
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	slices.SortFunc(entries, func(a, b *RegistryEntry) int { return b.Updated.Compare(a.Updated) })
+	return entries, nil
+}
+
+// FindRegistryEntry returns the project called name, or at path
+func FindRegistryEntry(entries []*RegistryEntry, name string) (*RegistryEntry, error) {
+	var matches []*RegistryEntry
+	for _, entry := range entries {
+		if entry.Name == name || entry.Path == name {
+			return entry, nil
+		}
+		if filepath.Base(entry.Path) == name {
+			matches = append(matches, entry)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no project %q in the registry; analyze it first", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("several projects are called %q; use the path", name)
+	}
+}
+
+// SearchRegistry ranks the projects whose name, summary or knowledge
+// mention the query's words. Name and summary hits count more than ones in
+// the knowledge documents.
+func SearchRegistry(entries []*RegistryEntry, query string) []RegistryMatch {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var matches []RegistryMatch
+	for _, entry := range entries {
+		match := RegistryMatch{Entry: entry}
+		for _, term := range terms {
+			match.Score += 5*strings.Count(strings.ToLower(entry.Name), term) + 3*strings.Count(strings.ToLower(entry.Summary), term)
+		}
+		if _, tier, ok := entry.Best(); ok {
+			for _, file := range slices.Sorted(maps.Keys(tier.Knowledge)) {
+				for _, line := range strings.Split(tier.Knowledge[file], "\n") {
+					lower := strings.ToLower(line)
+					hits := 0
+					for _, term := range terms {
+						hits += strings.Count(lower, term)
+					}
+					if hits == 0 {
+						continue
+					}
+					match.Score += hits
+					if len(match.Lines) < 3 {
+						match.Lines = append(match.Lines, file+": "+strings.TrimSpace(line))
+					}
+				}
+			}
+		}
+		if match.Score > 0 {
+			matches = append(matches, match)
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b RegistryMatch) int { return b.Score - a.Score })
+	return matches
+}
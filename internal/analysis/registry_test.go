@@ -0,0 +1,51 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	lib := filepath.Join(t.TempDir(), "shared-lib")
+	app := filepath.Join(t.TempDir(), "app")
+
+	if err := RegisterAnalysis(lib, &QuickAnalysis{
+		Tier:           TierQuick,
+		Description:    "Shared helpers for services",
+		KnowledgeFiles: map[string]string{"structure.md": "# Structure\n\nauth/ holds the token middleware\n"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterAnalysis(lib, &DeepAnalysis{
+		Tier:           TierDeep,
+		KnowledgeFiles: map[string]string{"context.md": "# Context\n\nThe auth middleware checks JWT tokens.\n"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterAnalysis(app, &QuickAnalysis{Tier: TierQuick, Description: "A web app"}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := LoadRegistry()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("LoadRegistry() = %d entries, %v; want 2", len(entries), err)
+	}
+	entry, err := FindRegistryEntry(entries, "shared-lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tier, _, _ := entry.Best(); tier != TierDeep || len(entry.Tiers) != 2 {
+		t.Errorf("shared-lib best tier = %s with %d tiers, want deep with 2", tier, len(entry.Tiers))
+	}
+
+	matches := SearchRegistry(entries, "JWT middleware")
+	if len(matches) != 1 || matches[0].Entry.Path != lib {
+		t.Fatalf("SearchRegistry(JWT middleware) = %v, want shared-lib", matches)
+	}
+	if want := "context.md: The auth middleware checks JWT tokens."; len(matches[0].Lines) == 0 || matches[0].Lines[0] != want {
+		t.Errorf("matched lines = %q, want %q first", matches[0].Lines, want)
+	}
+}
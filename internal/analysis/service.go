@@ -3,6 +3,7 @@ package analysis
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -15,6 +16,10 @@ type Service interface {
 	GetStartupScan(projectPath string) *StartupScanResult
 	StoreStartupScan(projectPath string, result *StartupScanResult)
 
+	// InstantAnalyze performs Tier 0 analysis (no model, ~100ms)
+	// Uses the file list, go.mod and package.json only
+	InstantAnalyze(ctx context.Context, projectPath string) (*InstantAnalysis, error)
+
 	// QuickAnalyze performs Tier 1 analysis (⚡ XS models, 2-3 seconds)
 	// Uses file list only, no content reading
 	QuickAnalyze(ctx context.Context, projectPath string) (*QuickAnalysis, error)
@@ -27,14 +32,15 @@ type Service interface {
 	// Skeptical refinement of Tier 2 results
 	DeepAnalyze(ctx context.Context, projectPath string) (*DeepAnalysis, error)
 
-	// FullAnalyze performs Tier 4 analysis (🚀 XL models, future)
-	// Professional-grade documentation using largest models
+	// FullAnalyze performs Tier 4 analysis (🚀 XL models, 5-15 minutes)
+	// Dependency graph, dead code candidates, module docs and ARCHITECTURE.md
 	FullAnalyze(ctx context.Context, projectPath string) (*FullAnalysis, error)
 
 	// GetCachedAnalysis returns cached analysis if available and not stale
 	GetCachedAnalysis(projectPath string, tier Tier) (Analysis, error)
 
-	// IsStale checks if cached analysis needs refresh based on git status
+	// IsStale checks if cached analysis needs refresh: the project's file
+	// list or the contents of the files the tier read have changed
 	IsStale(projectPath string, tier Tier) (bool, error)
 }
 
@@ -56,12 +62,51 @@ type StartupScanResult struct {
 type Tier string
 
 const (
+	TierInstant  Tier = "instant"  // No model, file structure and manifests only
 	TierQuick    Tier = "quick"    // XS models, file list only
 	TierDetailed Tier = "detailed" // S→M models, content analysis
 	TierDeep     Tier = "deep"     // L models, skeptical refinement
 	TierFull     Tier = "full"     // XL models, professional docs
 )
 
+// RunTier runs the analysis for tier, the dispatch shared by every caller
+// that takes the tier as input
+func RunTier(ctx context.Context, s Service, projectPath string, tier Tier) (Analysis, error) {
+	switch tier {
+	case TierInstant:
+		result, err := s.InstantAnalyze(ctx, projectPath)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	case TierQuick:
+		result, err := s.QuickAnalyze(ctx, projectPath)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	case TierDetailed:
+		result, err := s.DetailedAnalyze(ctx, projectPath)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	case TierDeep:
+		result, err := s.DeepAnalyze(ctx, projectPath)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	case TierFull:
+		result, err := s.FullAnalyze(ctx, projectPath)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("unknown tier %q (want instant, quick, detailed, deep or full)", tier)
+}
+
 // Analysis is the common interface for all analysis results.
 type Analysis interface {
 	GetTier() Tier
@@ -72,6 +117,30 @@ type Analysis interface {
 	FormatForPrompt() string
 }
 
+// InstantAnalysis represents Tier 0 structural results, made without a model.
+type InstantAnalysis struct {
+	Tier               Tier              `json:"tier"`
+	Generated          time.Time         `json:"generated"`
+	ProjectPath        string            `json:"project_path"`
+	ProjectType        string            `json:"project_type"`
+	MainLanguage       string            `json:"main_language"`
+	Framework          string            `json:"framework"`
+	Description        string            `json:"description"` // package.json's, or one built from the structure
+	TotalFiles         int               `json:"total_files"`
+	CodeFiles          int               `json:"code_files"`
+	KeyDirectories     []string          `json:"key_directories"` // Top-level directories with the most files
+	EntryPoints        []string          `json:"entry_points"`    // From file names and package.json main/bin
+	GoModule           *goManifest       `json:"go_module,omitempty"`
+	Package            string            `json:"package,omitempty"` // package.json name
+	Scripts            []string          `json:"scripts,omitempty"` // package.json script names
+	NPMDependencies    int               `json:"npm_dependencies,omitempty"`
+	NPMDevDependencies int               `json:"npm_dev_dependencies,omitempty"`
+	Duration           time.Duration     `json:"duration"`
+	KnowledgeFiles     map[string]string `json:"knowledge_files"`
+	FileSetHash        string            `json:"file_set_hash,omitempty"`  // Hash of the file list and the manifests' contents
+	ConsumedFiles      []string          `json:"consumed_files,omitempty"` // The manifests read
+}
+
 // QuickAnalysis represents Tier 1 fast analysis results.
 type QuickAnalysis struct {
 	Tier           Tier              `json:"tier"`
@@ -86,7 +155,8 @@ type QuickAnalysis struct {
 	KeyDirectories []string          `json:"key_directories"` // Main directories
 	EntryPoints    []string          `json:"entry_points"`    // Likely main files
 	Duration       time.Duration     `json:"duration"`
-	KnowledgeFiles map[string]string `json:"knowledge_files"` // Generated knowledge docs
+	KnowledgeFiles map[string]string `json:"knowledge_files"`         // Generated knowledge docs
+	FileSetHash    string            `json:"file_set_hash,omitempty"` // Hash of the file list; quick reads no contents
 
 	// Quick-tier consensus stats (for better rendering)
 	WorkersUsed         int           `json:"workers_used,omitempty"`
@@ -114,6 +184,8 @@ type DetailedAnalysis struct {
 	KnowledgeFiles map[string]string `json:"knowledge_files"` // Generated knowledge docs
 	Duration       time.Duration     `json:"duration"`
 	GitStatusHash  string            `json:"git_status_hash"`
+	FileSetHash    string            `json:"file_set_hash,omitempty"`  // Hash of the file list and ConsumedFiles' contents
+	ConsumedFiles  []string          `json:"consumed_files,omitempty"` // Files whose contents the analysis read
 }
 
 // DeepAnalysis represents Tier 3 refined analysis results.
@@ -131,32 +203,45 @@ type DeepAnalysis struct {
 	KnowledgeFiles        map[string]string `json:"knowledge_files"` // Refined knowledge docs
 	Duration              time.Duration     `json:"duration"`
 	GitStatusHash         string            `json:"git_status_hash"`
-	RefinementNotes       []string          `json:"refinement_notes"`       // What was corrected from Tier 2
-	ArchitecturalInsights []string          `json:"architectural_insights"` // Deeper insights from L models
+	FileSetHash           string            `json:"file_set_hash,omitempty"`  // Hash of the file list and ConsumedFiles' contents
+	ConsumedFiles         []string          `json:"consumed_files,omitempty"` // Files read by this tier and the detailed tier
+	RefinementNotes       []string          `json:"refinement_notes"`         // What was corrected from Tier 2
+	ArchitecturalInsights []string          `json:"architectural_insights"`   // Deeper insights from L models
 }
 
 // FullAnalysis represents Tier 4 professional documentation.
 type FullAnalysis struct {
-	Tier              Tier              `json:"tier"`
-	Generated         time.Time         `json:"generated"`
-	ProjectPath       string            `json:"project_path"`
-	Description       string            `json:"description"`
-	Architecture      string            `json:"architecture"`
-	Purpose           string            `json:"purpose"`
-	TechStack         []string          `json:"tech_stack"`
-	KeyFiles          []string          `json:"key_files"`
-	EntryPoints       []string          `json:"entry_points"`
-	FileCount         int               `json:"file_count"`
-	KnowledgeFiles    map[string]string `json:"knowledge_files"` // Professional-grade docs
-	Duration          time.Duration     `json:"duration"`
-	GitStatusHash     string            `json:"git_status_hash"`
-	BusinessValue     string            `json:"business_value"`     // Business context
-	TechnicalDebt     []string          `json:"technical_debt"`     // Identified issues
-	Recommendations   []string          `json:"recommendations"`    // Improvement suggestions
-	DocumentationGaps []string          `json:"documentation_gaps"` // Missing docs
+	Tier              Tier                `json:"tier"`
+	Generated         time.Time           `json:"generated"`
+	ProjectPath       string              `json:"project_path"`
+	Description       string              `json:"description"`
+	Architecture      string              `json:"architecture"`
+	Purpose           string              `json:"purpose"`
+	TechStack         []string            `json:"tech_stack"`
+	KeyFiles          []string            `json:"key_files"`
+	EntryPoints       []string            `json:"entry_points"`
+	FileCount         int                 `json:"file_count"`
+	KnowledgeFiles    map[string]string   `json:"knowledge_files"` // Professional-grade docs
+	Duration          time.Duration       `json:"duration"`
+	GitStatusHash     string              `json:"git_status_hash"`
+	FileSetHash       string              `json:"file_set_hash,omitempty"`  // Hash of the file list and ConsumedFiles' contents
+	ConsumedFiles     []string            `json:"consumed_files,omitempty"` // Go files parsed here plus the deep tier's
+	BusinessValue     string              `json:"business_value"`           // Business context
+	TechnicalDebt     []string            `json:"technical_debt"`           // Identified issues
+	Recommendations   []string            `json:"recommendations"`          // Improvement suggestions
+	DocumentationGaps []string            `json:"documentation_gaps"`       // Missing docs
+	Modules           []ModuleNode        `json:"modules"`                  // Dependency graph between modules
+	Cycles            [][]string          `json:"cycles,omitempty"`         // Modules importing each other
+	DeadCode          []DeadCodeCandidate `json:"dead_code"`                // Code nothing seems to use
 }
 
 // Implement Analysis interface for all types
+func (a *InstantAnalysis) GetTier() Tier                        { return a.Tier }
+func (a *InstantAnalysis) GetGenerated() time.Time              { return a.Generated }
+func (a *InstantAnalysis) GetProjectPath() string               { return a.ProjectPath }
+func (a *InstantAnalysis) GetKnowledgeFiles() map[string]string { return a.KnowledgeFiles }
+func (a *InstantAnalysis) GetDuration() time.Duration           { return a.Duration }
+
 func (a *QuickAnalysis) GetTier() Tier                        { return a.Tier }
 func (a *QuickAnalysis) GetGenerated() time.Time              { return a.Generated }
 func (a *QuickAnalysis) GetProjectPath() string               { return a.ProjectPath }
@@ -182,6 +267,42 @@ func (a *FullAnalysis) GetKnowledgeFiles() map[string]string { return a.Knowledg
 func (a *FullAnalysis) GetDuration() time.Duration           { return a.Duration }
 
 // FormatForPrompt implementations - return formatted markdown content
+func (a *InstantAnalysis) FormatForPrompt() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("## Instant Analysis\n%s\n\n", a.Description))
+
+	sb.WriteString("## Project Snapshot\n")
+	sb.WriteString(fmt.Sprintf("- **Type**: %s\n", a.ProjectType))
+	sb.WriteString(fmt.Sprintf("- **Language**: %s\n", a.MainLanguage))
+	if a.Framework != "" {
+		sb.WriteString(fmt.Sprintf("- **Framework**: %s\n", a.Framework))
+	}
+	if a.GoModule != nil {
+		sb.WriteString(fmt.Sprintf("- **Go module**: %s\n", a.GoModule.Module))
+	}
+	if a.Package != "" {
+		sb.WriteString(fmt.Sprintf("- **npm package**: %s\n", a.Package))
+	}
+	sb.WriteString(fmt.Sprintf("- **Files**: %d total (%d code)\n\n", a.TotalFiles, a.CodeFiles))
+
+	if len(a.EntryPoints) > 0 {
+		sb.WriteString("## Entry Points\n")
+		for _, entry := range a.EntryPoints {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", entry))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## What This Tier Did\n")
+	sb.WriteString("- Read the file list, go.mod and package.json; no model was called\n")
+	sb.WriteString("- Wrote `overview.md` and `structure.md`\n\n")
+
+	sb.WriteString("## Next\n")
+	sb.WriteString("- Run quick once a model is loaded to rank the important files.\n")
+	return sb.String()
+}
+
 func (a *QuickAnalysis) FormatForPrompt() string {
 	var sb strings.Builder
 
@@ -310,6 +431,25 @@ func (a *FullAnalysis) FormatForPrompt() string {
 		sb.WriteString(fmt.Sprintf("%s\n\n", a.BusinessValue))
 	}
 
+	if len(a.Modules) > 0 {
+		edges := 0
+		for _, module := range a.Modules {
+			edges += len(module.DependsOn)
+		}
+		sb.WriteString("## Dependency Graph\n")
+		sb.WriteString(fmt.Sprintf("- %d modules, %d imports between them\n", len(a.Modules), edges))
+		sb.WriteString(fmt.Sprintf("- %d import cycles\n", len(a.Cycles)))
+		sb.WriteString(fmt.Sprintf("- %d dead code candidates (see `dead_code.md`)\n\n", len(a.DeadCode)))
+	}
+
+	if len(a.TechnicalDebt) > 0 {
+		sb.WriteString("## Technical Debt\n")
+		for _, debt := range a.TechnicalDebt {
+			sb.WriteString(fmt.Sprintf("- %s\n", debt))
+		}
+		sb.WriteString("\n")
+	}
+
 	if len(a.Recommendations) > 0 {
 		sb.WriteString("## Recommendations\n")
 		for _, rec := range a.Recommendations {
@@ -318,9 +458,22 @@ func (a *FullAnalysis) FormatForPrompt() string {
 		sb.WriteString("\n")
 	}
 
+	if len(a.DocumentationGaps) > 0 {
+		sb.WriteString("## Documentation Gaps\n")
+		for _, gap := range a.DocumentationGaps {
+			sb.WriteString(fmt.Sprintf("- %s\n", gap))
+		}
+		sb.WriteString("\n")
+	}
+
 	if len(a.KnowledgeFiles) > 0 {
 		sb.WriteString("## Knowledge Files Updated\n")
+		files := make([]string, 0, len(a.KnowledgeFiles))
 		for file := range a.KnowledgeFiles {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		for _, file := range files {
 			sb.WriteString(fmt.Sprintf("- `%s`\n", file))
 		}
 	}
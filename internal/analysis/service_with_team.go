@@ -2,14 +2,25 @@ package analysis
 
 import (
 	"context"
+	"fmt"
+	"slices"
 
 	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/llm/queue"
 )
 
+// UnloadedLane is the queue lane shared by tiers whose model LM Studio has
+// not loaded, so runs that make it load a model never overlap each other
+const UnloadedLane = "lmstudio:unloaded"
+
 // ServiceWithTeam wraps the basic service and adds team client support.
+// With a queue, each tier runs in its model's lane: the quick tier on the
+// small model and the detailed tier on the medium model can run at the same
+// time when LM Studio has both loaded, while runs on one model wait their turn.
 type ServiceWithTeam struct {
 	Service
 	teamClients *llm.TeamClients
+	queue       *queue.Manager
 }
 
 // NewServiceWithTeam creates a new analysis service with team support.
@@ -30,23 +41,41 @@ func (s *ServiceWithTeam) SetTeamClients(clients *llm.TeamClients) {
 	}
 }
 
+// SetQueue makes tier runs wait for a slot in their model's queue lane.
+// Without a queue they run as soon as they are called.
+func (s *ServiceWithTeam) SetQueue(q *queue.Manager) {
+	s.queue = q
+}
+
+// SetWorkerLimit shares a concurrency limit between quick-tier workers of
+// every run, so it can follow LM Studio's health. Without one each run uses
+// worker_concurrency.
+func (s *ServiceWithTeam) SetWorkerLimit(limit *llm.AdaptiveLimit) {
+	if impl, ok := s.Service.(*service); ok {
+		impl.workerLimit = limit
+	}
+}
+
+// SetProgressPublisher sets a callback that receives the progress of every
+// analysis run, whatever context it was started with.
+func (s *ServiceWithTeam) SetProgressPublisher(cb ProgressCallback) {
+	if impl, ok := s.Service.(*service); ok {
+		impl.progress = cb
+	}
+}
+
 // QuickAnalyze performs quick analysis using small model with startup scan.
 func (s *ServiceWithTeam) QuickAnalyze(ctx context.Context, projectPath string) (*QuickAnalysis, error) {
-	// Use small client for quick analysis if available
-	if s.teamClients != nil && s.teamClients.Small != nil {
-		// Temporarily set the small client for this analysis
-		if impl, ok := s.Service.(*service); ok {
-			originalClient := impl.llmClient
-			impl.llmClient = s.teamClients.Small
-			defer func() { impl.llmClient = originalClient }()
-		}
-	}
-	
 	// Check if we have startup scan results to use as foundation
 	startupScan := s.GetStartupScan(projectPath)
 	
-	// Call base implementation
-	result, err := s.Service.QuickAnalyze(ctx, projectPath)
+	// Run the base implementation on the small model
+	var result *QuickAnalysis
+	err := s.schedule(ctx, TierQuick, func(ctx context.Context) error {
+		var err error
+		result, err = s.forTier(TierQuick).QuickAnalyze(ctx, projectPath)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -73,30 +102,94 @@ func (s *ServiceWithTeam) QuickAnalyze(ctx context.Context, projectPath string)
 
 // DetailedAnalyze performs detailed analysis using medium model.
 func (s *ServiceWithTeam) DetailedAnalyze(ctx context.Context, projectPath string) (*DetailedAnalysis, error) {
-	// Use medium client for detailed analysis if available
-	if s.teamClients != nil && s.teamClients.Medium != nil {
-		if impl, ok := s.Service.(*service); ok {
-			originalClient := impl.llmClient
-			impl.llmClient = s.teamClients.Medium
-			defer func() { impl.llmClient = originalClient }()
-		}
-	}
-	
-	return s.Service.DetailedAnalyze(ctx, projectPath)
+	var result *DetailedAnalysis
+	err := s.schedule(ctx, TierDetailed, func(ctx context.Context) error {
+		var err error
+		result, err = s.forTier(TierDetailed).DetailedAnalyze(ctx, projectPath)
+		return err
+	})
+	return result, err
 }
 
 // DeepAnalyze performs deep analysis using large model.
 func (s *ServiceWithTeam) DeepAnalyze(ctx context.Context, projectPath string) (*DeepAnalysis, error) {
-	// Use large client for deep analysis if available
-	if s.teamClients != nil && s.teamClients.Large != nil {
-		if impl, ok := s.Service.(*service); ok {
-			originalClient := impl.llmClient
-			impl.llmClient = s.teamClients.Large
-			defer func() { impl.llmClient = originalClient }()
-		}
+	var result *DeepAnalysis
+	err := s.schedule(ctx, TierDeep, func(ctx context.Context) error {
+		var err error
+		result, err = s.forTier(TierDeep).DeepAnalyze(ctx, projectPath)
+		return err
+	})
+	return result, err
+}
+
+// FullAnalyze performs full analysis using large model. The deep tier runs
+// first in its own lane so its result is cached by the time full needs it.
+func (s *ServiceWithTeam) FullAnalyze(ctx context.Context, projectPath string) (*FullAnalysis, error) {
+	if _, err := s.DeepAnalyze(ctx, projectPath); err != nil {
+		return nil, fmt.Errorf("failed to get deep analysis for full analysis: %w", err)
+	}
+	var result *FullAnalysis
+	err := s.schedule(ctx, TierFull, func(ctx context.Context) error {
+		var err error
+		result, err = s.forTier(TierFull).FullAnalyze(ctx, projectPath)
+		return err
+	})
+	return result, err
+}
+
+// forTier returns the service a tier runs on: a copy of the base service
+// using the tier's team client, so concurrent runs don't swap one shared
+// client under each other
+func (s *ServiceWithTeam) forTier(tier Tier) Service {
+	impl, ok := s.Service.(*service)
+	client := s.GetClient(tier)
+	if !ok || client == nil {
+		return s.Service
+	}
+	tierService := *impl
+	tierService.llmClient = client
+	return &tierService
+}
+
+// lane picks the queue lane for a tier: its model when LM Studio has it
+// loaded, otherwise the shared UnloadedLane
+func (s *ServiceWithTeam) lane(tier Tier) string {
+	lm, ok := s.GetClient(tier).(*llm.LMStudioClient)
+	if !ok || lm.CurrentModel() == "" {
+		return UnloadedLane
+	}
+	loaded, err := lm.LoadedModels()
+	if err != nil || !slices.Contains(loaded, lm.CurrentModel()) {
+		return UnloadedLane
+	}
+	return lm.CurrentModel()
+}
+
+// schedule runs fn in the tier's queue lane and waits for it. Without a
+// queue or a team there is only one model, so fn runs directly.
+func (s *ServiceWithTeam) schedule(ctx context.Context, tier Tier, fn func(context.Context) error) error {
+	if s.queue == nil || s.teamClients == nil {
+		return fn(ctx)
+	}
+
+	done := make(chan error, 1)
+	s.queue.Submit(ctx, func(ctx context.Context) error {
+		err := fn(ctx)
+		done <- err
+		return err
+	},
+		queue.WithPriority(5),
+		queue.WithType(string(tier)+"_analysis"),
+		queue.WithModel(s.lane(tier)),
+		queue.WithTimeout(0), // A tier takes as long as it takes
+	)
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	
-	return s.Service.DeepAnalyze(ctx, projectPath)
 }
 
 // GetClient returns the appropriate client for a tier.
@@ -0,0 +1,133 @@
+package analysis
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Ways to split the file list between quick-tier workers, set with
+// analysis.quick.sharding
+const (
+	ShardContiguous = "contiguous" // Consecutive runs of the sorted list (default)
+	ShardDirectory  = "directory"  // Whole top-level directories where they fit
+	ShardExtension  = "extension"  // Whole file types where they fit
+	ShardRandom     = "random"     // A shuffle seeded by the file list, so reruns match
+)
+
+// shardFiles gives each of workers its paths to rank. A list that fits in
+// maxPaths goes to every worker, so they vote on the same files; a longer
+// one is split by strategy so together the workers see up to
+// workers*maxPaths files.
+func shardFiles(files []string, workers, maxPaths int, strategy string) [][]string {
+	shards := make([][]string, workers)
+	if len(files) <= maxPaths {
+		for i := range shards {
+			shards[i] = files
+		}
+		return shards
+	}
+
+	switch strategy {
+	case ShardDirectory:
+		shards = shardByKey(files, workers, maxPaths, topLevelDir)
+	case ShardExtension:
+		shards = shardByKey(files, workers, maxPaths, func(file string) string {
+			return strings.ToLower(filepath.Ext(file))
+		})
+	case ShardRandom:
+		shuffled := append([]string(nil), files...)
+		h := fnv.New64a()
+		for _, file := range files {
+			h.Write([]byte(file))
+			h.Write([]byte{0})
+		}
+		rng := rand.New(rand.NewPCG(h.Sum64(), 0))
+		rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		shards = shardContiguous(shuffled, workers, maxPaths)
+		for _, shard := range shards {
+			sort.Strings(shard)
+		}
+	default:
+		shards = shardContiguous(files, workers, maxPaths)
+	}
+	return shards
+}
+
+// shardContiguous cuts files into workers runs of equal length, each capped
+// at maxPaths
+func shardContiguous(files []string, workers, maxPaths int) [][]string {
+	shards := make([][]string, workers)
+	chunkSize := (len(files) + workers - 1) / workers
+	for i := range shards {
+		start := min(i*chunkSize, len(files))
+		end := min(start+min(chunkSize, maxPaths), len(files))
+		shards[i] = files[start:end]
+	}
+	return shards
+}
+
+// shardByKey keeps files sharing a key on one worker where it can. Groups
+// bigger than an even share are cut into shares first; pieces then go,
+// largest first, to the least loaded worker, and whatever overflows
+// maxPaths moves to workers with room.
+func shardByKey(files []string, workers, maxPaths int, key func(string) string) [][]string {
+	groups := make(map[string][]string)
+	for _, file := range files {
+		k := key(file)
+		groups[k] = append(groups[k], file)
+	}
+
+	share := (len(files) + workers - 1) / workers
+	type piece struct {
+		key   string
+		files []string
+	}
+	var pieces []piece
+	for k, group := range groups {
+		for start := 0; start < len(group); start += share {
+			pieces = append(pieces, piece{key: k, files: group[start:min(start+share, len(group))]})
+		}
+	}
+	sort.SliceStable(pieces, func(i, j int) bool {
+		if len(pieces[i].files) != len(pieces[j].files) {
+			return len(pieces[i].files) > len(pieces[j].files)
+		}
+		return pieces[i].key < pieces[j].key
+	})
+
+	shards := make([][]string, workers)
+	for _, p := range pieces {
+		least := 0
+		for i := range shards {
+			if len(shards[i]) < len(shards[least]) {
+				least = i
+			}
+		}
+		shards[least] = append(shards[least], p.files...)
+	}
+
+	var overflow []string
+	for i := range shards {
+		if len(shards[i]) > maxPaths {
+			overflow = append(overflow, shards[i][maxPaths:]...)
+			shards[i] = shards[i][:maxPaths]
+		}
+	}
+	for i := range shards {
+		room := min(maxPaths-len(shards[i]), len(overflow))
+		shards[i] = append(shards[i], overflow[:room]...)
+		overflow = overflow[room:]
+	}
+	return shards
+}
+
+// topLevelDir returns a path's first directory, "." for files at the root
+func topLevelDir(file string) string {
+	if dir, _, ok := strings.Cut(file, "/"); ok {
+		return dir
+	}
+	return "."
+}
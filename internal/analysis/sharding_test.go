@@ -0,0 +1,70 @@
+package analysis
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// shardingFiles is a project too big for one worker: a large cmd directory,
+// a mid-sized internal one and a few root files
+func shardingFiles() []string {
+	var files []string
+	for i := range 30 {
+		files = append(files, fmt.Sprintf("cmd/tool%02d.go", i))
+	}
+	for i := range 12 {
+		files = append(files, fmt.Sprintf("internal/pkg%02d.md", i))
+	}
+	files = append(files, "README.md", "go.mod", "main.go")
+	slices.Sort(files)
+	return files
+}
+
+func TestShardFilesCoversEveryFile(t *testing.T) {
+	files := shardingFiles()
+	for _, strategy := range []string{ShardContiguous, ShardDirectory, ShardExtension, ShardRandom, ""} {
+		shards := shardFiles(files, 4, 15, strategy)
+		var seen []string
+		for i, shard := range shards {
+			if len(shard) > 15 {
+				t.Errorf("%s: worker %d got %d files, over the cap of 15", strategy, i, len(shard))
+			}
+			seen = append(seen, shard...)
+		}
+		slices.Sort(seen)
+		if !reflect.DeepEqual(seen, files) {
+			t.Errorf("%s: workers saw %d files, want each of the %d once", strategy, len(seen), len(files))
+		}
+	}
+}
+
+func TestShardFilesSmallListIsShared(t *testing.T) {
+	files := []string{"a.go", "b.go"}
+	for i, shard := range shardFiles(files, 3, 400, ShardDirectory) {
+		if !reflect.DeepEqual(shard, files) {
+			t.Errorf("worker %d got %v, want the whole list", i, shard)
+		}
+	}
+}
+
+func TestShardFilesKeepsDirectoriesTogether(t *testing.T) {
+	shards := shardFiles(shardingFiles(), 4, 15, ShardDirectory)
+	for i, shard := range shards {
+		if slices.Contains(shard, "internal/pkg00.md") && !slices.Contains(shard, "internal/pkg11.md") {
+			t.Errorf("worker %d got part of internal/: %v", i, shard)
+		}
+	}
+}
+
+func TestShardFilesRandomIsRepeatable(t *testing.T) {
+	files := shardingFiles()
+	first := shardFiles(files, 4, 15, ShardRandom)
+	if again := shardFiles(files, 4, 15, ShardRandom); !reflect.DeepEqual(first, again) {
+		t.Errorf("random shards changed between runs on the same files")
+	}
+	if reflect.DeepEqual(first, shardFiles(files, 4, 15, ShardContiguous)) {
+		t.Errorf("random shards match contiguous ones")
+	}
+}
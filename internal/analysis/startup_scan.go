@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+const (
+	// startupScanFile is where the latest startup scan is saved under .loco
+	startupScanFile = "startup_scan.json"
+	// maxScanFiles caps how many paths the startup scan shows the model
+	maxScanFiles = 200
+	// maxScanReadme caps how much of the README the startup scan shows it
+	maxScanReadme = 2000
+)
+
+// ScanProjectStructure detects a project's type, language and framework from
+// its file list alone, without the model. It returns the files it looked at.
+func ScanProjectStructure(projectPath string) (*StartupScanResult, []string, error) {
+	files, err := GetProjectFiles(projectPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &StartupScanResult{
+		ProjectPath: projectPath,
+		ProjectType: detectProjectType(files),
+		Language:    detectMainLanguage(files),
+		Framework:   detectFramework(files),
+		FileCount:   len(files),
+		Confidence:  0.5,
+	}, files, nil
+}
+
+// LoadStartupScan reads the last saved startup scan however old it is, or
+// nil when no scan has run in this project yet
+func LoadStartupScan(projectPath string) *StartupScanResult {
+	data, err := os.ReadFile(filepath.Join(projectPath, ".loco", startupScanFile))
+	if err != nil {
+		return nil
+	}
+	var result StartupScanResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil
+	}
+	return &result
+}
+
+// DescribeProject asks the model for the project's purpose in about ten
+// words from its file list and README. The previous scan's purpose, if any,
+// is offered for the model to confirm or sharpen, so each run refines the
+// last.
+func DescribeProject(ctx context.Context, client llm.Client, scan *StartupScanResult, files []string, previous *StartupScanResult) (string, error) {
+	if client == nil {
+		return "", fmt.Errorf("LLM client not available")
+	}
+
+	listed := files[:min(maxScanFiles, len(files))]
+	fileList := strings.Join(listed, "\n")
+	if len(files) > len(listed) {
+		fileList += fmt.Sprintf("\n... and %d more", len(files)-len(listed))
+	}
+
+	readme := ""
+	for _, name := range []string{"README.md", "README", "readme.md", "README.txt"} {
+		if data, err := os.ReadFile(filepath.Join(scan.ProjectPath, name)); err == nil {
+			readme = string(data)
+			if len(readme) > maxScanReadme {
+				readme = readme[:maxScanReadme] + "\n..."
+			}
+			break
+		}
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(fmt.Sprintf("Project type: %s\nLanguage: %s\nFramework: %s\n\n", scan.ProjectType, scan.Language, scan.Framework))
+	prompt.WriteString("Files:\n" + fileList + "\n")
+	if readme != "" {
+		prompt.WriteString("\nREADME:\n" + readme + "\n")
+	}
+	if previous != nil && previous.Purpose != "" {
+		prompt.WriteString(fmt.Sprintf("\nA previous scan described it as: %s\nKeep that if it still fits; otherwise improve it.\n", previous.Purpose))
+	}
+	prompt.WriteString("\nWhat is this project for? Answer with one phrase of at most 10 words and nothing else.")
+
+	messages := []llm.Message{
+		{
+			Role:    "system",
+			Content: "You identify software projects at a glance. Be brief and concrete.",
+		},
+		{
+			Role:    "user",
+			Content: prompt.String(),
+		},
+	}
+
+	response, err := client.Complete(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+	purpose := strings.TrimSpace(strings.SplitN(strings.TrimSpace(response), "\n", 2)[0])
+	return strings.Trim(purpose, `"*. `), nil
+}
@@ -0,0 +1,124 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// staticFacts is what a file's source says for certain, parsed before a
+// model reads it so summaries don't invent exports or dependencies
+type staticFacts struct {
+	Package string
+	Exports []string // Exported names in declaration order, methods as Type.Method
+	Imports []string
+}
+
+// staticExtractors parse a file's source by extension. Languages without
+// one are left to the model.
+var staticExtractors = map[string]func(file string, src []byte) (*staticFacts, error){
+	".go": extractGoFacts,
+}
+
+// extractStaticFacts parses a project file with its language's extractor.
+// It reads the whole file, as the model may only see its head, and returns
+// nil when there is no extractor or the file doesn't parse.
+func extractStaticFacts(projectPath, file string) *staticFacts {
+	extract, ok := staticExtractors[filepath.Ext(file)]
+	if !ok {
+		return nil
+	}
+	src, err := os.ReadFile(filepath.Join(projectPath, file))
+	if err != nil {
+		return nil
+	}
+	facts, err := extract(file, src)
+	if err != nil {
+		return nil
+	}
+	return facts
+}
+
+// extractGoFacts reads a Go file's package, imports and exported
+// declarations
+func extractGoFacts(file string, src []byte) (*staticFacts, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	facts := &staticFacts{Package: f.Name.Name}
+	for _, spec := range f.Imports {
+		if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+			facts.Imports = append(facts.Imports, importPath)
+		}
+	}
+	for _, decl := range f.Decls {
+		facts.Exports = append(facts.Exports, goExports(decl)...)
+	}
+	return facts, nil
+}
+
+// goExports returns the exported names a declaration introduces. Methods
+// are named after their receiver's type and only count when both are
+// exported.
+func goExports(decl ast.Decl) []string {
+	var names []string
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if !d.Name.IsExported() {
+			return nil
+		}
+		if d.Recv == nil || len(d.Recv.List) == 0 {
+			return []string{d.Name.Name}
+		}
+		if recv := receiverType(d.Recv.List[0].Type); ast.IsExported(recv) {
+			return []string{recv + "." + d.Name.Name}
+		}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				if s.Name.IsExported() {
+					names = append(names, s.Name.Name)
+				}
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					if name.IsExported() {
+						names = append(names, name.Name)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// receiverType names a method receiver's type without pointer or type
+// parameters
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// prompt renders the facts for a summary prompt
+func (f *staticFacts) prompt() string {
+	var b strings.Builder
+	b.WriteString("Parsed from the source (exact; don't contradict them or add to them):\n")
+	b.WriteString("Package: " + f.Package + "\n")
+	b.WriteString("Imports: " + listOrNone(f.Imports) + "\n")
+	b.WriteString("Exports: " + listOrNone(f.Exports) + "\n")
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractGoFacts(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"store/store.go": `package store
+
+import (
+	"context"
+	db "database/sql"
+)
+
+const Version, build = "1", "dev"
+
+var ErrMissing error
+
+type Store struct{ db *db.DB }
+
+type cursor[T any] struct{}
+
+func New() *Store { return nil }
+
+func (s *Store) Get(ctx context.Context) {}
+
+func (c cursor[T]) Next() {}
+
+func helper() {}
+`,
+		"broken.go": "package broken\n\nfunc {",
+		"notes.md":  "# Notes\n",
+	})
+
+	facts := extractStaticFacts(root, "store/store.go")
+	if facts == nil {
+		t.Fatal("extractStaticFacts(store.go) = nil")
+	}
+	want := &staticFacts{
+		Package: "store",
+		Imports: []string{"context", "database/sql"},
+		Exports: []string{"Version", "ErrMissing", "Store", "New", "Store.Get"},
+	}
+	if !reflect.DeepEqual(facts, want) {
+		t.Errorf("facts = %+v, want %+v", facts, want)
+	}
+
+	for _, file := range []string{"broken.go", "notes.md", "missing.go"} {
+		if facts := extractStaticFacts(root, file); facts != nil {
+			t.Errorf("extractStaticFacts(%s) = %+v, want nil", file, facts)
+		}
+	}
+}
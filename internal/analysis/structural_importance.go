@@ -0,0 +1,150 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// structuralWeight is how much the import graph counts against the models'
+// votes when scoring a file's importance
+const structuralWeight = 0.35
+
+// structuralSignal is what the import graph says about one file
+type structuralSignal struct {
+	FanIn int     // Files importing it or its package
+	Entry bool    // Is a program entry point
+	Depth int     // Import hops from the nearest entry point; -1 when unreachable
+	Score float64 // 1-10, comparable to a model's importance
+}
+
+// computeStructuralSignals scores every file of the graph by how many files
+// import it and how close it is to an entry point. A file many others import
+// or that every run goes through matters whatever its name says. Libraries
+// without entry points are scored on fan-in alone.
+func computeStructuralSignals(graph *DependencyGraph) map[string]structuralSignal {
+	modules := map[string]*ModuleNode{}
+	for i := range graph.Modules {
+		modules[graph.Modules[i].Path] = &graph.Modules[i]
+	}
+	imports := map[string][]string{}
+	maxFanIn := 0
+	var entries []string
+	for _, file := range graph.Files {
+		for _, target := range file.Imports {
+			if module, ok := modules[target]; ok {
+				imports[file.Path] = append(imports[file.Path], module.Files...)
+			} else {
+				imports[file.Path] = append(imports[file.Path], target)
+			}
+		}
+		maxFanIn = max(maxFanIn, len(file.ImportedBy))
+		module := modules[file.Module]
+		if isEntryFile(file.Path) || (module != nil && module.Entry && module.Language == "Go") {
+			entries = append(entries, file.Path)
+		}
+	}
+
+	// Breadth-first from every entry point at once gives each file its
+	// distance from the nearest one
+	depth := map[string]int{}
+	queue := entries
+	for _, entry := range entries {
+		depth[entry] = 0
+	}
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+		for _, next := range imports[file] {
+			if _, seen := depth[next]; !seen {
+				depth[next] = depth[file] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	program := len(entries) > 0
+	signals := make(map[string]structuralSignal, len(graph.Files))
+	for _, file := range graph.Files {
+		signal := structuralSignal{FanIn: len(file.ImportedBy), Depth: -1}
+		if d, ok := depth[file.Path]; ok {
+			signal.Depth = d
+			signal.Entry = d == 0
+		}
+
+		fanIn := 0.0
+		if maxFanIn > 0 {
+			fanIn = math.Log1p(float64(signal.FanIn)) / math.Log1p(float64(maxFanIn))
+		}
+		if program {
+			reach := 0.0
+			switch {
+			case signal.Entry:
+				reach = 3
+			case signal.Depth > 0:
+				reach = 2 / float64(signal.Depth)
+			}
+			signal.Score = 1 + 6*fanIn + reach
+		} else {
+			signal.Score = 1 + 9*fanIn
+		}
+		signal.Score = math.Min(math.Max(signal.Score, 1), 10)
+		signals[file.Path] = signal
+	}
+	return signals
+}
+
+// blendImportance mixes a model's importance with the structural score
+func blendImportance(modelScore float64, signal structuralSignal) float64 {
+	return (1-structuralWeight)*modelScore + structuralWeight*signal.Score
+}
+
+// applyStructuralSignals blends the structural score into each ranking the
+// graph knows and reorders them by the result. Files outside the graph, such
+// as docs and config, keep the models' score.
+func applyStructuralSignals(rankings []FileRanking, signals map[string]structuralSignal) {
+	for i := range rankings {
+		if signal, ok := signals[rankings[i].Path]; ok {
+			rankings[i].Structural = signal.Score
+			rankings[i].Importance = blendImportance(rankings[i].Importance, signal)
+		}
+	}
+	sort.SliceStable(rankings, func(i, j int) bool { return rankings[i].Importance > rankings[j].Importance })
+}
+
+// structuralCandidates returns up to n of the structurally strongest files
+// no worker voted for, so a core file with a bland name still gets a say.
+// With no votes they count as the lowest model score.
+func structuralCandidates(signals map[string]structuralSignal, voted map[string]*FileRanking, n int) []FileRanking {
+	var candidates []FileRanking
+	for path, signal := range signals {
+		if _, ok := voted[path]; ok || signal.FanIn == 0 {
+			continue
+		}
+		candidates = append(candidates, FileRanking{
+			Path:       path,
+			Importance: blendImportance(1, signal),
+			Structural: signal.Score,
+			Reason:     structuralHint(signal),
+			Category:   "core",
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Structural != candidates[j].Structural {
+			return candidates[i].Structural > candidates[j].Structural
+		}
+		return candidates[i].Path < candidates[j].Path
+	})
+	return candidates[:min(n, len(candidates))]
+}
+
+// structuralHint describes a file's place in the import graph in a few words
+func structuralHint(signal structuralSignal) string {
+	switch {
+	case signal.Entry:
+		return fmt.Sprintf("entry point, imported by %d files", signal.FanIn)
+	case signal.Depth > 0:
+		return fmt.Sprintf("imported by %d files, %d hops from an entry point", signal.FanIn, signal.Depth)
+	}
+	return fmt.Sprintf("imported by %d files", signal.FanIn)
+}
@@ -0,0 +1,309 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Test map files at the knowledge root
+const (
+	testMapJSON = "tests.json"
+	testMapMD   = "tests.md"
+)
+
+// maxUntestedListed caps the untested files tests.md lists by name
+const maxUntestedListed = 100
+
+// How a test was matched to a source file
+const (
+	CoverPackage = "package" // A Go test in the source file's package
+	CoverName    = "name"    // The test is named after the source file
+	CoverImport  = "import"  // The test imports the source file or its package
+)
+
+// TestMapping is one test file and the source files it exercises
+type TestMapping struct {
+	Test   string            `json:"test"`
+	Covers map[string]string `json:"covers"` // Source file -> how it was matched
+}
+
+// TestMap maps a project's tests to its source files
+type TestMap struct {
+	Generated   time.Time     `json:"generated"`
+	FileSetHash string        `json:"file_set_hash"` // The file list and the tests' contents
+	Tests       []TestMapping `json:"tests"`
+	Sources     []string      `json:"sources"`  // Source files that could have tests
+	Direct      []string      `json:"direct"`   // Sources a test covers by package or name
+	Indirect    []string      `json:"indirect"` // Sources only reached through a test's imports
+	Untested    []string      `json:"untested"`
+}
+
+// RefreshTestMap writes knowledge/tests.json and tests.md, mapping test
+// files to the source files they cover by package, name and imports, unless
+// neither the file list nor a test changed since they were last written. It
+// returns the map and whether it was rewritten.
+func RefreshTestMap(projectPath string) (*TestMap, bool, error) {
+	files, err := GetProjectFiles(projectPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get project files: %w", err)
+	}
+	var tests, sources []string
+	for _, file := range files {
+		switch {
+		case isVendored(file) || !isTestableLanguage(file):
+		case isTestFile(file):
+			tests = append(tests, file)
+		case isTestableSource(file):
+			sources = append(sources, file)
+		}
+	}
+
+	hash := fileSetHash(projectPath, files, tests)
+	if saved := LoadTestMap(projectPath); saved != nil && saved.FileSetHash == hash {
+		return saved, false, nil
+	}
+
+	m := buildTestMap(projectPath, files, tests, sources)
+	m.Generated = analysisStamp(projectPath)
+	m.FileSetHash = hash
+
+	dir := knowledgeDir(projectPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, false, err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, testMapJSON), data, 0644); err != nil {
+		return nil, false, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, testMapMD), []byte(m.Markdown()), 0644); err != nil {
+		return nil, false, err
+	}
+	return m, true, nil
+}
+
+// LoadTestMap reads the saved test map, or nil when there is none
+func LoadTestMap(projectPath string) *TestMap {
+	data, err := os.ReadFile(filepath.Join(knowledgeDir(projectPath), testMapJSON))
+	if err != nil {
+		return nil
+	}
+	var m TestMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return &m
+}
+
+// buildTestMap matches each test to sources: Go tests cover their package
+// and the project packages they import; JavaScript, TypeScript and Python
+// tests cover the file they are named after and the project files they
+// import
+func buildTestMap(projectPath string, files, tests, sources []string) *TestMap {
+	known := map[string]bool{}
+	for _, file := range files {
+		known[file] = true
+	}
+	byDir := map[string][]string{}
+	for _, source := range sources {
+		byDir[path.Dir(source)] = append(byDir[path.Dir(source)], source)
+	}
+	isSource := map[string]bool{}
+	for _, source := range sources {
+		isSource[source] = true
+	}
+
+	modulePath := readGoModulePath(projectPath)
+	_, goTests := parseGoFiles(projectPath, tests, goImportsMode)
+
+	m := &TestMap{Tests: []TestMapping{}, Sources: sources}
+	for _, test := range tests {
+		mapping := TestMapping{Test: test, Covers: map[string]string{}}
+		cover := func(source, how string) {
+			// A closer match wins over an import
+			if isSource[source] && (mapping.Covers[source] == "" || mapping.Covers[source] == CoverImport) {
+				mapping.Covers[source] = how
+			}
+		}
+
+		if strings.HasSuffix(test, ".go") {
+			for _, source := range byDir[path.Dir(test)] {
+				if strings.HasSuffix(source, ".go") {
+					cover(source, CoverPackage)
+				}
+			}
+			if f, ok := goTests[test]; ok {
+				for _, dir := range goImportTargets(f, modulePath) {
+					for _, source := range byDir[dir] {
+						if strings.HasSuffix(source, ".go") {
+							cover(source, CoverImport)
+						}
+					}
+				}
+			}
+		} else {
+			for _, source := range namedSources(test, known) {
+				cover(source, CoverName)
+			}
+			for _, source := range scriptImportTargets(projectPath, test, nil, known) {
+				cover(source, CoverImport)
+			}
+		}
+		m.Tests = append(m.Tests, mapping)
+	}
+
+	best := map[string]string{}
+	for _, mapping := range m.Tests {
+		for source, how := range mapping.Covers {
+			if best[source] == "" || best[source] == CoverImport {
+				best[source] = how
+			}
+		}
+	}
+	for _, source := range sources {
+		switch best[source] {
+		case "":
+			m.Untested = append(m.Untested, source)
+		case CoverImport:
+			m.Indirect = append(m.Indirect, source)
+		default:
+			m.Direct = append(m.Direct, source)
+		}
+	}
+	return m
+}
+
+// namedSources returns the files a script test is named after:
+// foo.test.ts and __tests__/foo.ts for foo.ts, test_foo.py and foo_test.py
+// for foo.py, looked for next to the test, one directory up, and for Python
+// anywhere when only one file has the name
+func namedSources(test string, known map[string]bool) []string {
+	dir, base := path.Dir(test), path.Base(test)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	var exts []string
+	if ext == ".py" {
+		stem = strings.TrimSuffix(strings.TrimPrefix(stem, "test_"), "_test")
+		exts = []string{".py"}
+	} else {
+		stem = strings.TrimSuffix(strings.TrimSuffix(stem, ".test"), ".spec")
+		exts = []string{".ts", ".tsx", ".js", ".jsx", ".mjs"}
+	}
+
+	var found []string
+	for _, d := range []string{dir, path.Dir(dir)} {
+		for _, e := range exts {
+			if candidate := path.Join(d, stem+e); candidate != test && known[candidate] {
+				found = append(found, candidate)
+			}
+		}
+		if len(found) > 0 {
+			return found
+		}
+	}
+	if ext == ".py" {
+		for file := range known {
+			if path.Base(file) == stem+".py" && !isTestFile(file) {
+				found = append(found, file)
+			}
+		}
+		if len(found) == 1 {
+			return found
+		}
+	}
+	return nil
+}
+
+// isTestableLanguage reports whether tests are mapped for the file's
+// language
+func isTestableLanguage(file string) bool {
+	switch path.Ext(file) {
+	case ".go", ".js", ".jsx", ".ts", ".tsx", ".mjs", ".py":
+		return true
+	}
+	return false
+}
+
+// isTestableSource reports whether a source file is one worth testing:
+// not test data, declarations or package markers
+func isTestableSource(file string) bool {
+	base := path.Base(file)
+	if strings.HasSuffix(base, ".d.ts") || base == "__init__.py" || base == "conftest.py" {
+		return false
+	}
+	return !slices.ContainsFunc(strings.Split(file, "/"), func(part string) bool {
+		return part == "testdata" || part == "__tests__" || part == "__mocks__" || part == "fixtures"
+	})
+}
+
+// Markdown renders the map as tests.md: overall coverage, coverage by
+// directory with the least tested first, and the untested files
+func (m *TestMap) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# Tests\n\n")
+	if len(m.Sources) == 0 {
+		b.WriteString("No Go, JavaScript, TypeScript or Python source files found.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%d test files cover %d of %d source files (%d%%) by package or name; %d more are only reached through a test's imports and %d have no test.\n\n",
+		len(m.Tests), len(m.Direct), len(m.Sources), 100*len(m.Direct)/len(m.Sources), len(m.Indirect), len(m.Untested))
+	b.WriteString("Mapped from file names, packages and imports, not from running the tests.\n")
+
+	type dirStats struct{ sources, direct, indirect, untested int }
+	stats := map[string]*dirStats{}
+	count := func(files []string, field func(*dirStats) *int) {
+		for _, file := range files {
+			dir := path.Dir(file)
+			if stats[dir] == nil {
+				stats[dir] = &dirStats{}
+			}
+			*field(stats[dir])++
+		}
+	}
+	count(m.Sources, func(s *dirStats) *int { return &s.sources })
+	count(m.Direct, func(s *dirStats) *int { return &s.direct })
+	count(m.Indirect, func(s *dirStats) *int { return &s.indirect })
+	count(m.Untested, func(s *dirStats) *int { return &s.untested })
+
+	dirs := make([]string, 0, len(stats))
+	for dir := range stats {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		x, y := stats[dirs[i]], stats[dirs[j]]
+		if x.direct*y.sources != y.direct*x.sources {
+			return x.direct*y.sources < y.direct*x.sources // Lowest share covered first
+		}
+		if x.untested != y.untested {
+			return x.untested > y.untested
+		}
+		return dirs[i] < dirs[j]
+	})
+	b.WriteString("\n## Coverage by directory\n\n")
+	b.WriteString("| Directory | Sources | Tested | Through imports | Untested |\n|-----------|---------|--------|-----------------|----------|\n")
+	for _, dir := range dirs {
+		s := stats[dir]
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %d |\n", dir, s.sources, s.direct, s.indirect, s.untested)
+	}
+
+	if len(m.Untested) > 0 {
+		b.WriteString("\n## Untested files\n\n")
+		for _, file := range m.Untested[:min(maxUntestedListed, len(m.Untested))] {
+			fmt.Fprintf(&b, "- `%s`\n", file)
+		}
+		if len(m.Untested) > maxUntestedListed {
+			fmt.Fprintf(&b, "- ... and %d more\n", len(m.Untested)-maxUntestedListed)
+		}
+	}
+	return b.String()
+}
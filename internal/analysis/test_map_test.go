@@ -0,0 +1,54 @@
+package analysis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRefreshTestMap(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                  "module example.com/app\n",
+		"store/store.go":          "package store\n",
+		"store/cache.go":          "package store\n",
+		"store/store_test.go":     "package store\n",
+		"api/api.go":              "package api\n",
+		"api/api_test.go":         "package api_test\n\nimport (\n\t\"testing\"\n\n\t\"example.com/app/util\"\n)\n",
+		"util/util.go":            "package util\n",
+		"cmd/tool/main.go":        "package main\n",
+		"web/src/button.tsx":      "",
+		"web/src/button.test.tsx": "import { format } from './format'\n",
+		"web/src/format.ts":       "",
+		"web/src/types.d.ts":      "",
+		"py/app/models.py":        "",
+		"py/tests/test_models.py": "",
+		"py/app/views.py":         "",
+		"testdata/case.go":        "package testdata\n",
+	})
+
+	m, written, err := RefreshTestMap(root)
+	if err != nil || !written {
+		t.Fatalf("first refresh: written %v, err %v", written, err)
+	}
+	if want := []string{"api/api.go", "py/app/models.py", "store/cache.go", "store/store.go", "web/src/button.tsx"}; !reflect.DeepEqual(m.Direct, want) {
+		t.Errorf("Direct = %v, want %v", m.Direct, want)
+	}
+	if want := []string{"util/util.go", "web/src/format.ts"}; !reflect.DeepEqual(m.Indirect, want) {
+		t.Errorf("Indirect = %v, want %v", m.Indirect, want)
+	}
+	if want := []string{"cmd/tool/main.go", "py/app/views.py"}; !reflect.DeepEqual(m.Untested, want) {
+		t.Errorf("Untested = %v, want %v", m.Untested, want)
+	}
+	if md := m.Markdown(); !strings.Contains(md, "| cmd/tool | 1 | 0 | 0 | 1 |") || !strings.Contains(md, "- `py/app/views.py`") {
+		t.Errorf("tests.md:\n%s", md)
+	}
+
+	if _, written, _ := RefreshTestMap(root); written {
+		t.Error("refresh rewrote the map with nothing changed")
+	}
+	writeFiles(t, root, map[string]string{"cmd/tool/main_test.go": "package main\n"})
+	if m, written, _ := RefreshTestMap(root); !written || len(m.Untested) != 1 {
+		t.Errorf("after adding a test: written %v, untested %v", written, m.Untested)
+	}
+}
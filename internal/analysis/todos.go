@@ -0,0 +1,169 @@
+package analysis
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/files"
+)
+
+// todosJSON is the inventory file at the knowledge root
+const todosJSON = "todos.json"
+
+// maxTodoScanSize skips files too large to be hand-written source
+const maxTodoScanSize = 1 << 20
+
+// todoPattern matches a tag in a comment, with an optional owner in
+// parentheses: // TODO(ana): text, # FIXME text, /* HACK: text */
+var todoPattern = regexp.MustCompile(`(?://|#|/\*|\*|--|<!--|;)\s*(TODO|FIXME|HACK|XXX)\b(?:\(([^)]*)\))?:?\s*(.*)`)
+
+// Todo is one TODO, FIXME, HACK or XXX comment
+type Todo struct {
+	File      string    `json:"file"`
+	Line      int       `json:"line"`
+	Tag       string    `json:"tag"`
+	Text      string    `json:"text"`
+	Owner     string    `json:"owner,omitempty"`    // Named in the comment, else who last changed the line
+	Committed time.Time `json:"committed,omitzero"` // When the line was last committed, per git blame
+}
+
+// TodoInventory is every tagged comment in the project
+type TodoInventory struct {
+	Generated   time.Time `json:"generated"`
+	FileSetHash string    `json:"file_set_hash"` // The file list, the scanned files' contents and HEAD
+	Todos       []Todo    `json:"todos"`
+}
+
+// RefreshTodos writes knowledge/todos.json from the TODO, FIXME, HACK and
+// XXX comments in the project's source, owned by the name in the comment or
+// else by git blame, unless no file or commit changed since it was last
+// written. It returns the inventory and whether it was rewritten.
+func RefreshTodos(ctx context.Context, projectPath string) (*TodoInventory, bool, error) {
+	listed, err := GetProjectFiles(projectPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get project files: %w", err)
+	}
+	var scanned []string
+	for _, file := range listed {
+		if files.IsIndexable(file) && !isVendored(file) {
+			scanned = append(scanned, file)
+		}
+	}
+
+	hash := fileSetHash(projectPath, listed, scanned)
+	if head, err := files.Git(ctx, projectPath, "rev-parse", "HEAD"); err == nil {
+		hash += ":" + strings.TrimSpace(string(head))
+	}
+	if saved := LoadTodos(projectPath); saved != nil && saved.FileSetHash == hash {
+		return saved, false, nil
+	}
+
+	inventory := &TodoInventory{Generated: analysisStamp(projectPath), FileSetHash: hash, Todos: []Todo{}}
+	for _, file := range scanned {
+		todos := scanTodos(projectPath, file)
+		if len(todos) > 0 {
+			blameTodos(ctx, projectPath, file, todos)
+			inventory.Todos = append(inventory.Todos, todos...)
+		}
+	}
+
+	dir := knowledgeDir(projectPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, false, err
+	}
+	data, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, todosJSON), data, 0644); err != nil {
+		return nil, false, err
+	}
+	return inventory, true, nil
+}
+
+// LoadTodos reads the saved inventory, or nil when there is none
+func LoadTodos(projectPath string) *TodoInventory {
+	data, err := os.ReadFile(filepath.Join(knowledgeDir(projectPath), todosJSON))
+	if err != nil {
+		return nil
+	}
+	var inventory TodoInventory
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return nil
+	}
+	return &inventory
+}
+
+// scanTodos returns a file's tagged comments in line order
+func scanTodos(projectPath, file string) []Todo {
+	full := filepath.Join(projectPath, file)
+	if info, err := os.Stat(full); err != nil || info.Size() > maxTodoScanSize {
+		return nil
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return nil
+	}
+
+	var todos []Todo
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxTodoScanSize)
+	for line := 1; scanner.Scan(); line++ {
+		m := todoPattern.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(m[3]), "*/"), "-->"))
+		todos = append(todos, Todo{File: file, Line: line, Tag: m[1], Text: text, Owner: strings.TrimSpace(m[2])})
+	}
+	return todos
+}
+
+// blameTodos fills in who last committed each line and when, and makes
+// them the owner where the comment names none. Lines not yet committed, and
+// files outside a git repository, keep what the comment says.
+func blameTodos(ctx context.Context, projectPath, file string, todos []Todo) {
+	args := []string{"blame", "--line-porcelain"}
+	for _, todo := range todos {
+		args = append(args, "-L", fmt.Sprintf("%d,%d", todo.Line, todo.Line))
+	}
+	out, err := files.Git(ctx, projectPath, append(args, "--", file)...)
+	if err != nil {
+		return
+	}
+
+	byLine := map[int]*Todo{}
+	for i := range todos {
+		byLine[todos[i].Line] = &todos[i]
+	}
+	var current *Todo
+	uncommitted := false
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 3 && len(fields[0]) >= 40 && strings.Trim(fields[0], "0123456789abcdef") == "":
+			// Header: <sha> <original line> <final line> [<group size>]
+			final, _ := strconv.Atoi(fields[2])
+			current = byLine[final]
+			uncommitted = strings.Trim(fields[0], "0") == ""
+		case current == nil || uncommitted:
+		case strings.HasPrefix(line, "author "):
+			if current.Owner == "" {
+				current.Owner = strings.TrimPrefix(line, "author ")
+			}
+		case strings.HasPrefix(line, "author-time "):
+			if sec, err := strconv.ParseInt(strings.TrimPrefix(line, "author-time "), 10, 64); err == nil {
+				current.Committed = time.Unix(sec, 0).UTC()
+			}
+		}
+	}
+}
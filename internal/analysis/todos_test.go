@@ -0,0 +1,66 @@
+package analysis
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestScanTodos(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.go": "package a\n\n// TODO(ana): split this up\nfunc f() {} // FIXME handle errors\n\n/* HACK: works around a race */\nvar todoList = 1 // not a tag: TODOs\n",
+		"b.py": "x = 1  # XXX magic number\n",
+	})
+
+	got := scanTodos(root, "a.go")
+	want := []Todo{
+		{File: "a.go", Line: 3, Tag: "TODO", Text: "split this up", Owner: "ana"},
+		{File: "a.go", Line: 4, Tag: "FIXME", Text: "handle errors"},
+		{File: "a.go", Line: 6, Tag: "HACK", Text: "works around a race"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("a.go:\ngot  %+v\nwant %+v", got, want)
+	}
+	if got := scanTodos(root, "b.py"); len(got) != 1 || got[0].Tag != "XXX" || got[0].Text != "magic number" {
+		t.Errorf("b.py: %+v", got)
+	}
+}
+
+func TestRefreshTodosBlame(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"main.go": "package main\n\n// TODO: committed\n"})
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(cmd.Environ(), "GIT_AUTHOR_DATE=2024-03-01T12:00:00Z", "GIT_COMMITTER_DATE=2024-03-01T12:00:00Z")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", "main.go")
+	git("-c", "user.name=Bea", "-c", "user.email=bea@example.com", "commit", "-q", "-m", "init")
+	writeFiles(t, root, map[string]string{"main.go": "package main\n\n// TODO: committed\n// FIXME: not yet\n"})
+
+	inventory, written, err := RefreshTodos(context.Background(), root)
+	if err != nil || !written {
+		t.Fatalf("first refresh: written %v, err %v", written, err)
+	}
+	if len(inventory.Todos) != 2 {
+		t.Fatalf("Todos = %+v", inventory.Todos)
+	}
+	if todo := inventory.Todos[0]; todo.Owner != "Bea" || todo.Committed.Format("2006-01-02") != "2024-03-01" {
+		t.Errorf("committed TODO = %+v", todo)
+	}
+	if todo := inventory.Todos[1]; todo.Owner != "" || !todo.Committed.IsZero() {
+		t.Errorf("uncommitted FIXME = %+v", todo)
+	}
+	if _, written, _ := RefreshTodos(context.Background(), root); written {
+		t.Error("refresh rewrote the inventory with nothing changed")
+	}
+}
@@ -4,11 +4,12 @@ import "time"
 
 // FileRanking represents a single file's relative importance from crowd/adjudication.
 type FileRanking struct {
-	Path       string  `json:"path"`       // relative path
-	Importance float64 `json:"importance"` // 1–10 (consensus-weighted or adjudicated)
-	Reason     string  `json:"reason"`     // <= 120 chars
-	Category   string  `json:"category"`   // entry|config|core|util|test|doc|other
-	VoteCount  int     `json:"vote_count"` // # of workers that voted for this
+	Path       string  `json:"path"`                 // relative path
+	Importance float64 `json:"importance"`           // 1–10 (consensus-weighted or adjudicated)
+	Reason     string  `json:"reason"`               // <= 120 chars
+	Category   string  `json:"category"`             // entry|config|core|util|test|doc|other
+	VoteCount  int     `json:"vote_count"`           // # of workers that voted for this
+	Structural float64 `json:"structural,omitempty"` // 1–10 from import fan-in and entry reachability
 }
 
 // ConsensusResult is the adjudicated result plus summary stats.
@@ -20,10 +21,11 @@ type ConsensusResult struct {
 	Notes             []string `json:"notes"`
 
 	// Final important files (optionally ordered)
-	Rankings      []FileRanking  `json:"rankings"` // final top-K (default 100)
-	TopDirs       map[string]int `json:"top_directories"`
-	FileTypes     map[string]int `json:"file_types"`
-	TotalFiles    int            `json:"total_files"`
-	ConsensusTime time.Duration  `json:"consensus_time"`
-	Confidence    float64        `json:"confidence"` // from adjudicator if provided
+	Rankings      []FileRanking     `json:"rankings"` // final top-K (default 100)
+	TopDirs       map[string]int    `json:"top_directories"`
+	FileTypes     map[string]int    `json:"file_types"`
+	TotalFiles    int               `json:"total_files"`
+	ConsensusTime time.Duration     `json:"consensus_time"`
+	Confidence    float64           `json:"confidence"`        // from adjudicator if provided
+	Metrics       *ConsensusMetrics `json:"metrics,omitempty"` // How far the ranking workers agreed; nil in natural language mode
 }
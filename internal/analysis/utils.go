@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -31,13 +32,10 @@ func GetProjectStructure(projectPath string) (string, error) {
 			"coverage", ".next", ".nuxt", "out",
 		}
 
-		for _, skip := range skipDirs {
-			if strings.Contains(path, string(os.PathSeparator)+skip) {
-				if info.IsDir() {
-				return filepath.SkipDir
-				}
-				return nil
-			}
+		// Match whole names: a substring check would skip .github for .git
+		// and outline.md for out
+		if info.IsDir() && slices.Contains(skipDirs, info.Name()) {
+			return filepath.SkipDir
 		}
 
 		// Limit depth to 4 levels
@@ -0,0 +1,384 @@
+package analysis
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Workspace is a monorepo: a root whose manifests list subprojects that
+// are analyzed one at a time
+type Workspace struct {
+	Root     string       `json:"root"`
+	Projects []Subproject `json:"projects"`
+}
+
+// Subproject is one project of a workspace
+type Subproject struct {
+	Name string `json:"name"` // Slash path relative to the workspace root
+	Kind string `json:"kind"` // "go", "pnpm" or "cargo": the manifest listing it
+}
+
+// Path returns the subproject's directory
+func (w *Workspace) Path(p Subproject) string {
+	return filepath.Join(w.Root, filepath.FromSlash(p.Name))
+}
+
+// Find returns the subproject named name, as listed or with a trailing
+// slash or leading ./
+func (w *Workspace) Find(name string) (Subproject, bool) {
+	name = path.Clean(filepath.ToSlash(strings.TrimSpace(name)))
+	for _, p := range w.Projects {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Subproject{}, false
+}
+
+// workspaceOverviewFile is the workspace-level overview at the knowledge root
+const workspaceOverviewFile = "workspace.md"
+
+// DetectWorkspace reads the workspace manifests at root: go.work,
+// pnpm-workspace.yaml and a Cargo.toml with a [workspace] section. Returns
+// nil when root lists no subprojects.
+func DetectWorkspace(root string) *Workspace {
+	w := &Workspace{Root: root}
+	seen := map[string]bool{}
+	add := func(kind, marker string, dirs []string) {
+		for _, dir := range dirs {
+			name := path.Clean(filepath.ToSlash(dir))
+			if name == "." || strings.HasPrefix(name, "../") || seen[name] {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(name), marker)); err != nil {
+				continue
+			}
+			seen[name] = true
+			w.Projects = append(w.Projects, Subproject{Name: name, Kind: kind})
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "go.work")); err == nil {
+		add("go", "go.mod", goWorkUses(data))
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml")); err == nil {
+		add("pnpm", "package.json", expandMembers(root, pnpmPackages(data)))
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "Cargo.toml")); err == nil {
+		add("cargo", "Cargo.toml", expandMembers(root, cargoMembers(data)))
+	}
+
+	if len(w.Projects) == 0 {
+		return nil
+	}
+	slices.SortFunc(w.Projects, func(a, b Subproject) int { return strings.Compare(a.Name, b.Name) })
+	return w
+}
+
+// goWorkUses returns the module directories of go.work's use directives,
+// in both the single-line and block forms
+func goWorkUses(data []byte) []string {
+	var dirs []string
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			dirs = append(dirs, strings.Trim(line, "\"`"))
+		case line == "use (":
+			inBlock = true
+		case strings.HasPrefix(line, "use "):
+			dirs = append(dirs, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "use ")), "\"`"))
+		}
+	}
+	return dirs
+}
+
+// pnpmPackages returns the globs under pnpm-workspace.yaml's packages key;
+// exclusions keep their leading !
+func pnpmPackages(data []byte) []string {
+	var globs []string
+	inPackages := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-") {
+			inPackages = strings.HasPrefix(trimmed, "packages:")
+			continue
+		}
+		if item, ok := strings.CutPrefix(trimmed, "-"); inPackages && ok {
+			globs = append(globs, strings.Trim(strings.TrimSpace(item), `'"`))
+		}
+	}
+	return globs
+}
+
+// cargoMembers returns the members and, marked with a leading !, the
+// exclusions of Cargo.toml's [workspace] section
+func cargoMembers(data []byte) []string {
+	var globs []string
+	section, key := "", ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if strings.HasPrefix(line, "[") && key == "" {
+			section = strings.Trim(line, "[] ")
+			continue
+		}
+		if section != "workspace" {
+			continue
+		}
+		if name, value, ok := strings.Cut(line, "="); ok && key == "" {
+			switch strings.TrimSpace(name) {
+			case "members", "exclude":
+				key, line = strings.TrimSpace(name), value
+			default:
+				continue
+			}
+		}
+		if key == "" {
+			continue
+		}
+		for _, item := range strings.Split(strings.Trim(strings.TrimSpace(line), "[]"), ",") {
+			if item = strings.Trim(strings.TrimSpace(item), `"'`); item != "" {
+				if key == "exclude" {
+					item = "!" + item
+				}
+				globs = append(globs, item)
+			}
+		}
+		if strings.Contains(line, "]") {
+			key = ""
+		}
+	}
+	return globs
+}
+
+// expandMembers resolves member globs against root. A ** matches any depth
+// (skipping dependency directories); globs starting with ! take matches out.
+func expandMembers(root string, globs []string) []string {
+	var dirs, excluded []string
+	for _, glob := range globs {
+		matches := matchDirs(root, strings.TrimPrefix(glob, "!"))
+		if strings.HasPrefix(glob, "!") {
+			excluded = append(excluded, matches...)
+		} else {
+			dirs = append(dirs, matches...)
+		}
+	}
+	return slices.DeleteFunc(dirs, func(dir string) bool { return slices.Contains(excluded, dir) })
+}
+
+// matchDirs returns the directories under root matching glob, as slash
+// paths relative to root
+func matchDirs(root, glob string) []string {
+	glob = path.Clean(strings.TrimPrefix(glob, "./"))
+	var dirs []string
+	if prefix, _, ok := strings.Cut(glob, "**"); ok {
+		base := filepath.Join(root, filepath.FromSlash(prefix))
+		_ = filepath.WalkDir(base, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			if skippedDirs[d.Name()] || (p != base && strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			if rel, err := filepath.Rel(root, p); err == nil {
+				dirs = append(dirs, filepath.ToSlash(rel))
+			}
+			return nil
+		})
+		return dirs
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(glob)))
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			if rel, err := filepath.Rel(root, match); err == nil {
+				dirs = append(dirs, filepath.ToSlash(rel))
+			}
+		}
+	}
+	return dirs
+}
+
+// workspaceOf returns the workspace root and subproject name of
+// projectPath when it is a subproject of a workspace loco is set up in.
+// A subproject with its own .loco is a project of its own.
+func workspaceOf(projectPath string) (string, string, bool) {
+	if hasLocoDir(projectPath) {
+		return "", "", false
+	}
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "", "", false
+	}
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		if hasLocoDir(dir) {
+			rel, err := filepath.Rel(dir, abs)
+			if err != nil {
+				return "", "", false
+			}
+			if w := DetectWorkspace(dir); w != nil {
+				if p, ok := w.Find(rel); ok {
+					return dir, p.Name, true
+				}
+			}
+			return "", "", false
+		}
+		if filepath.Dir(dir) == dir {
+			return "", "", false
+		}
+	}
+}
+
+func hasLocoDir(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, ".loco"))
+	return err == nil && info.IsDir()
+}
+
+// locoDir returns the .loco directory analysis of projectPath uses: the
+// workspace root's for a subproject
+func locoDir(projectPath string) string {
+	if root, _, ok := workspaceOf(projectPath); ok {
+		return filepath.Join(root, ".loco")
+	}
+	return filepath.Join(projectPath, ".loco")
+}
+
+// configRoot returns the directory whose .loco config applies to
+// projectPath
+func configRoot(projectPath string) string {
+	if root, _, ok := workspaceOf(projectPath); ok {
+		return root
+	}
+	return projectPath
+}
+
+// knowledgeDir returns where projectPath's knowledge lives:
+// .loco/knowledge/, or .loco/knowledge/<subproject>/ at the workspace root
+// for a subproject
+func knowledgeDir(projectPath string) string {
+	if root, name, ok := workspaceOf(projectPath); ok {
+		return filepath.Join(root, ".loco", "knowledge", filepath.FromSlash(name))
+	}
+	return filepath.Join(projectPath, ".loco", "knowledge")
+}
+
+// ResolveProject returns the directory to analyze for project: root itself
+// when project is empty, otherwise the workspace subproject of that name
+func ResolveProject(root, project string) (string, error) {
+	if project == "" {
+		return root, nil
+	}
+	w := DetectWorkspace(root)
+	if w == nil {
+		return "", fmt.Errorf("no workspace found: %s has no go.work, pnpm-workspace.yaml or Cargo.toml [workspace]", root)
+	}
+	p, ok := w.Find(project)
+	if !ok {
+		names := make([]string, len(w.Projects))
+		for i, p := range w.Projects {
+			names[i] = p.Name
+		}
+		return "", fmt.Errorf("unknown project %q (workspace has %s)", project, strings.Join(names, ", "))
+	}
+	return w.Path(p), nil
+}
+
+// RunWorkspace runs tier on every subproject in turn, then writes the
+// workspace overview. A failing subproject doesn't stop the others; their
+// errors are returned together with the results that succeeded.
+func RunWorkspace(ctx context.Context, s Service, w *Workspace, tier Tier) (map[string]Analysis, error) {
+	results := make(map[string]Analysis)
+	var errs []error
+	for _, p := range w.Projects {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result, err := RunTier(ctx, s, w.Path(p), tier)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+			continue
+		}
+		results[p.Name] = result
+	}
+	if _, err := WriteWorkspaceOverview(s, w); err != nil {
+		errs = append(errs, fmt.Errorf("workspace overview: %w", err))
+	}
+	return results, errors.Join(errs...)
+}
+
+// WriteWorkspaceOverview writes .loco/knowledge/workspace.md: each
+// subproject with its most complete analysis so far. Returns the document.
+func WriteWorkspaceOverview(s Service, w *Workspace) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Workspace overview\n\n%d projects in %s, updated %s.\n", len(w.Projects), filepath.Base(w.Root), analysisStamp(w.Root).Format("Jan 2, 2006 15:04"))
+
+	for _, p := range w.Projects {
+		fmt.Fprintf(&b, "\n## %s\n\n", p.Name)
+		facts := []string{p.Kind}
+		if files, err := GetProjectFiles(w.Path(p)); err == nil {
+			facts = append(facts, fmt.Sprintf("%d files", len(files)))
+		}
+
+		var latest Analysis
+		for _, tier := range []Tier{TierFull, TierDeep, TierDetailed, TierQuick, TierInstant} {
+			if cached, err := s.GetCachedAnalysis(w.Path(p), tier); err == nil {
+				latest = cached
+				break
+			}
+		}
+		if latest == nil {
+			fmt.Fprintf(&b, "%s · not analyzed yet\n", strings.Join(facts, " · "))
+			continue
+		}
+		facts = append(facts, fmt.Sprintf("%s analysis from %s", latest.GetTier(), latest.GetGenerated().Format("Jan 2 15:04")))
+		fmt.Fprintf(&b, "%s\n\n", strings.Join(facts, " · "))
+		fmt.Fprintf(&b, "%s\n\nKnowledge: `.loco/knowledge/%s/%s/`\n", subprojectPurpose(latest), p.Name, latest.GetTier())
+	}
+
+	doc := b.String()
+	dir := filepath.Join(w.Root, ".loco", "knowledge")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return doc, os.WriteFile(filepath.Join(dir, workspaceOverviewFile), []byte(doc), 0o644)
+}
+
+// subprojectPurpose describes a subproject in a sentence from its analysis
+func subprojectPurpose(a Analysis) string {
+	if quick, ok := a.(*QuickAnalysis); ok && quick.Description != "" {
+		return quick.Description
+	}
+	if instant, ok := a.(*InstantAnalysis); ok && instant.Description != "" {
+		return instant.Description
+	}
+	if context, ok := a.GetKnowledgeFiles()["context.md"]; ok {
+		return extractPurpose(context)
+	}
+	return "No summary available."
+}
@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates each file under dir with the given content
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetectWorkspace(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.work":         "go 1.24\n\nuse ./cmd/tool // the CLI\n\nuse (\n\t./lib\n\t./missing\n)\n",
+		"cmd/tool/go.mod": "module tool\n",
+		"lib/go.mod":      "module lib\n",
+
+		"pnpm-workspace.yaml":           "packages:\n  - 'packages/*'\n  - \"!packages/private\"\n# comment\ncatalog:\n  - ignored\n",
+		"packages/web/package.json":     "{}",
+		"packages/private/package.json": "{}",
+		"packages/empty/README.md":      "",
+
+		"Cargo.toml":             "[workspace]\nmembers = [\n  \"crates/*\", # all crates\n]\nexclude = [\"crates/old\"]\n\n[workspace.package]\nversion = \"0.1.0\"\n",
+		"crates/core/Cargo.toml": "[package]\n",
+		"crates/old/Cargo.toml":  "[package]\n",
+	})
+
+	w := DetectWorkspace(root)
+	if w == nil {
+		t.Fatal("DetectWorkspace found no workspace")
+	}
+	want := []Subproject{
+		{Name: "cmd/tool", Kind: "go"},
+		{Name: "crates/core", Kind: "cargo"},
+		{Name: "lib", Kind: "go"},
+		{Name: "packages/web", Kind: "pnpm"},
+	}
+	if !reflect.DeepEqual(w.Projects, want) {
+		t.Errorf("Projects = %v, want %v", w.Projects, want)
+	}
+
+	if p, ok := w.Find("./lib/"); !ok || p.Name != "lib" {
+		t.Errorf("Find(./lib/) = %v, %v", p, ok)
+	}
+	if DetectWorkspace(t.TempDir()) != nil {
+		t.Error("DetectWorkspace found a workspace in an empty directory")
+	}
+}
+
+func TestKnowledgeDirInWorkspace(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.work":         "use ./api\nuse ./web\n",
+		"api/go.mod":      "module api\n",
+		"web/go.mod":      "module web\n",
+		".loco/.keep":     "",
+		"web/.loco/.keep": "",
+	})
+
+	tests := []struct {
+		projectPath string
+		want        string
+	}{
+		{root, filepath.Join(root, ".loco", "knowledge")},
+		{filepath.Join(root, "api"), filepath.Join(root, ".loco", "knowledge", "api")},
+		// A subproject set up on its own keeps its own knowledge
+		{filepath.Join(root, "web"), filepath.Join(root, "web", ".loco", "knowledge")},
+	}
+	for _, tt := range tests {
+		if got := knowledgeDir(tt.projectPath); got != tt.want {
+			t.Errorf("knowledgeDir(%s) = %s, want %s", tt.projectPath, got, tt.want)
+		}
+	}
+	if got := configRoot(filepath.Join(root, "api")); got != root {
+		t.Errorf("configRoot(api) = %s, want %s", got, root)
+	}
+
+	if path, err := ResolveProject(root, "api"); err != nil || path != filepath.Join(root, "api") {
+		t.Errorf("ResolveProject(api) = %s, %v", path, err)
+	}
+	if _, err := ResolveProject(root, "docs"); err == nil {
+		t.Error("ResolveProject(docs) succeeded for a project not in the workspace")
+	}
+}
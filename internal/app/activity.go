@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/llm/queue"
+	"github.com/billie-coop/loco/internal/tui/events"
+)
+
+// modelActivity publishes what LM Studio is busy with for the status bar:
+// the queue, the chat requests in flight and which models are loaded
+type modelActivity struct {
+	broker *events.Broker
+
+	mu     sync.Mutex
+	loaded map[string]bool // Models LM Studio reported loaded or that answered
+}
+
+// newModelActivity starts publishing the queue's and LM Studio's activity
+func newModelActivity(q *queue.Manager, broker *events.Broker) *modelActivity {
+	a := &modelActivity{
+		broker: broker,
+		loaded: make(map[string]bool),
+	}
+	q.OnChange(func(status queue.Status) {
+		events.LLMQueue.PublishAsync(broker, events.QueuePayload{Pending: status.Pending, Running: status.Running})
+	})
+	llm.ObserveActivity(a.observeActivity)
+	llm.ObserveRequests(a.observeRequest)
+	return a
+}
+
+// setLoaded records the models LM Studio reported loaded at startup
+func (a *modelActivity) setLoaded(probes []llm.ModelProbe) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, probe := range probes {
+		a.loaded[probe.ID] = true
+	}
+}
+
+// observeActivity publishes the chat requests in flight. A request to a
+// model not known to be loaded makes LM Studio load it first.
+func (a *modelActivity) observeActivity(info llm.RequestInfo, active int) {
+	events.LLMRequests.PublishAsync(a.broker, events.RequestsPayload{Active: active})
+
+	if info.Duration > 0 || info.Model == "" {
+		return
+	}
+	a.mu.Lock()
+	loaded := a.loaded[info.Model]
+	a.mu.Unlock()
+	if !loaded {
+		events.ModelLoading.PublishAsync(a.broker, events.ModelStatusPayload{ModelID: info.Model})
+	}
+}
+
+// observeRequest publishes a model as loaded once it answers, or as failed
+// when LM Studio could not be reached. Busy answers and cancelled or timed
+// out requests say nothing about the model.
+func (a *modelActivity) observeRequest(info llm.RequestInfo) {
+	if info.Model == "" {
+		return
+	}
+
+	switch err := info.Err(); {
+	case err != nil:
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return
+		}
+		a.mu.Lock()
+		delete(a.loaded, info.Model)
+		a.mu.Unlock()
+		events.ModelError.PublishAsync(a.broker, events.ModelStatusPayload{ModelID: info.Model, Error: info.Error})
+
+	case info.Status == http.StatusOK:
+		a.mu.Lock()
+		wasLoaded := a.loaded[info.Model]
+		a.loaded[info.Model] = true
+		a.mu.Unlock()
+		if !wasLoaded {
+			events.ModelLoaded.PublishAsync(a.broker, events.ModelStatusPayload{ModelID: info.Model})
+		}
+	}
+}
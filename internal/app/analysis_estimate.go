@@ -0,0 +1,39 @@
+package app
+
+import (
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+// EstimateAnalysis works out what running tier on projectPath would take,
+// with each tier's model and its benchmarked speed, without calling a model
+func (a *App) EstimateAnalysis(projectPath string, tier analysis.Tier) ([]analysis.Estimate, error) {
+	speeds := make(map[analysis.Tier]analysis.ModelSpeed)
+	for _, t := range []analysis.Tier{analysis.TierQuick, analysis.TierDetailed, analysis.TierDeep, analysis.TierFull} {
+		speeds[t] = a.analysisModelSpeed(t)
+	}
+	return analysis.EstimateTier(projectPath, tier, speeds)
+}
+
+// analysisModelSpeed returns the model a tier runs on, its team client's or
+// else the main client's, and that model's probed speed
+func (a *App) analysisModelSpeed(tier analysis.Tier) analysis.ModelSpeed {
+	client := a.LLM
+	if withTeam, ok := a.Analysis.(*analysis.ServiceWithTeam); ok {
+		if teamClient := withTeam.GetClient(tier); teamClient != nil {
+			client = teamClient
+		}
+	}
+	lm, ok := client.(*llm.LMStudioClient)
+	if !ok || lm.CurrentModel() == "" {
+		return analysis.ModelSpeed{}
+	}
+
+	speed := analysis.ModelSpeed{Model: lm.CurrentModel()}
+	for _, probe := range a.ModelProbes {
+		if probe.ID == speed.Model {
+			speed.TokensPerSecond = probe.TokensPerSecond
+		}
+	}
+	return speed
+}
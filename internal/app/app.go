@@ -2,19 +2,30 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/billie-coop/loco/internal/agents"
 	"github.com/billie-coop/loco/internal/analysis"
 	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/crash"
 	"github.com/billie-coop/loco/internal/knowledge"
 	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/llm/queue"
+	"github.com/billie-coop/loco/internal/lsp"
+	"github.com/billie-coop/loco/internal/mcp"
+	"github.com/billie-coop/loco/internal/orchestrator"
 	"github.com/billie-coop/loco/internal/parser"
 	"github.com/billie-coop/loco/internal/permission"
+	"github.com/billie-coop/loco/internal/process"
+	"github.com/billie-coop/loco/internal/redact"
 	"github.com/billie-coop/loco/internal/session"
 	"github.com/billie-coop/loco/internal/sidecar"
-	"github.com/billie-coop/loco/internal/sidecar/embedder"
 	"github.com/billie-coop/loco/internal/sidecar/vectordb"
+	"github.com/billie-coop/loco/internal/tasks"
 	"github.com/billie-coop/loco/internal/tools"
 	"github.com/billie-coop/loco/internal/tui/events"
 	"github.com/billie-coop/loco/internal/watcher"
@@ -25,26 +36,50 @@ type App struct {
 	// Core services
 	Config       *config.Manager
 	Sessions     *session.Manager
+	Tasks        *tasks.Tracker // The current session's plan
+	Agents       *agents.Runner // Sub-agents on the model team
+	Router       *orchestrator.Router // Picks the team model for each chat message
+	Verifier     *orchestrator.Verifier // Reviews answers before they are shown
+	Redactor     *redact.Redactor // Scrubs secrets from model requests (nil when redaction is disabled)
 	LLM          llm.Client
 	TeamClients  *llm.TeamClients // Multiple clients for different model sizes
+	ModelProbes  []llm.ModelProbe // What each loaded model can do, probed at startup
 	Knowledge    *knowledge.Manager
 	Tools        *tools.Registry
 	Parser       *parser.Parser
 	ModelManager *llm.ModelManager
+	Queue        *queue.Manager // Shares LM Studio between background LLM requests
+	WorkerLimit  *llm.AdaptiveLimit // Quick-tier worker concurrency, following LM Studio's health
 
 	// Analysis service
 	Analysis analysis.Service
 	
 	// Sidecar/RAG service
 	Sidecar sidecar.Service
+
+	// Past conversation turns, recalled into chat prompts
+	ChatMemory     *ChatMemory
+	stopChatMemory context.CancelFunc
+
+	// Decisions from past conversations, logged in .loco/knowledge/decisions.md
+	DecisionLog     *DecisionLog
+	stopDecisionLog context.CancelFunc
 	
 	// File watcher service
 	FileWatcher *watcher.FileWatcher
 
+	// Language servers for symbol-level tools (nil when disabled)
+	LSP *lsp.Manager
+
+	// External MCP servers whose tools are in the registry (nil when none are configured)
+	MCP *mcp.Manager
+
+	// Long-running commands started with run_process
+	Processes *process.Manager
+
 	// New services we'll add
-	LLMService        *LLMService
-	PermissionService *PermissionService
-	CommandService    *CommandService
+	LLMService     *LLMService
+	CommandService *CommandService
 
 	// Unified tool architecture
 	ToolExecutor *ToolExecutor
@@ -55,6 +90,7 @@ type App struct {
 
 	// Internal references for re-initialization
 	permissionServiceInternal permission.Service
+	activity                  *modelActivity
 	workingDir                string
 }
 
@@ -89,21 +125,6 @@ func (fwa *fileWatcherAdapter) Subscribe(callback func(sidecar.FileChangeEvent))
 	})
 }
 
-// toolExecutorAdapter adapts app.ToolExecutor to sidecar.ToolExecutor interface
-type toolExecutorAdapter struct {
-	executor *ToolExecutor
-}
-
-// ExecuteFileWatch adapts the tool call to the app's tool system
-func (tea *toolExecutorAdapter) ExecuteFileWatch(call sidecar.ToolCall) {
-	// Convert sidecar.ToolCall to tools.ToolCall
-	appToolCall := tools.ToolCall{
-		Name:  call.Name,
-		Input: call.Input,
-	}
-	tea.executor.ExecuteFileWatch(appToolCall)
-}
-
 // New creates a new app with all services initialized
 func New(workingDir string, eventBroker *events.Broker) *App {
 	app := &App{
@@ -120,97 +141,145 @@ func New(workingDir string, eventBroker *events.Broker) *App {
 
 	// Initialize existing services
 	app.Sessions = session.NewManager(workingDir)
+	app.Tasks = tasks.NewTracker(app.Sessions)
 	if err := app.Sessions.Initialize(); err != nil {
 		// Log but continue
 		_ = err
 	}
 
-	// Get allowed tools from config
-	cfg := app.Config.Get()
-	allowedTools := []string{}
-	if cfg != nil && cfg.AllowedTools != nil {
-		allowedTools = cfg.AllowedTools
+	// Remove analysis debug dumps past logging.retention_days
+	if cfg := app.Config.Get(); cfg != nil {
+		if removed, err := analysis.PruneDebugDumps(workingDir, cfg.Logging.RetentionDays); err == nil && removed > 0 {
+			eventBroker.PublishAsync(events.Event{
+				Type: events.StatusMessageEvent,
+				Payload: events.StatusMessagePayload{
+					Message: fmt.Sprintf("Removed %d debug dump(s) older than %d days", removed, cfg.Logging.RetentionDays),
+					Type:    "info",
+				},
+			})
+		}
 	}
 
-	// Create permission service with persistent state
+	// Scrub secrets from everything sent to a model; each LM Studio client
+	// gets the redactor as it is configured
+	app.Redactor = newRedactor(app.Config.Get(), workingDir, eventBroker)
+
+	// Create permission service; per-tool policies live in the config and
+	// approved actions are audited under .loco/
 	statePath := filepath.Join(workingDir, ".loco")
-	permissionService := permission.NewService(eventBroker, allowedTools, statePath)
+	permissionService := permission.NewService(eventBroker, app.Config, statePath)
 	app.permissionServiceInternal = permissionService
 
+	// Scale analysis workers with how LM Studio copes
+	app.WorkerLimit = newWorkerLimit(app.Config.Get(), eventBroker)
+
 	// Create analysis service (will be set up properly when LLM client is available)
-	app.Analysis = analysis.NewService(nil)
+	app.Analysis = app.newAnalysisService(nil)
 
 	// Initialize new tool registry with Crush-style tools
 	app.Tools = tools.CreateDefaultRegistry(permissionService, workingDir, app.Analysis)
+	app.Tools.SetGuardrails(app.Config) // Protected paths are refused whatever the permission answer
 
 	app.Parser = parser.New()
+	app.Parser.SetSchemaProvider(app.Tools)
 	app.Knowledge = knowledge.NewManager(workingDir, nil)
+	app.Knowledge.SetRedactor(app.Redactor)
+
+	app.Queue = queue.NewManager(1)
+	app.Queue.SetAging(queueAging(app.Config.Get(), eventBroker))
+	_ = app.Queue.Start()
+	app.activity = newModelActivity(app.Queue, eventBroker)
 
 	// Initialize new services
 	app.LLMService = NewLLMService(eventBroker)
-	app.PermissionService = NewPermissionService(eventBroker)
+	app.LLMService.SetParser(app.Parser)
 	app.CommandService = NewCommandService(app, eventBroker)
 
 	// Register command tools
 	app.Tools.Register(tools.NewCopyTool(permissionService, app.Sessions))
 	app.Tools.Register(tools.NewChatTool(app.LLMService, app.Sessions))
-	
-	// Initialize sidecar/RAG service based on config
-	var sidecarEmbedder sidecar.Embedder
-	
-	// Get embedder type from config
-	embedderType := "lmstudio"
-	if cfg := app.Config.Get(); cfg != nil && cfg.Analysis.RAG.Embedder != "" {
-		embedderType = cfg.Analysis.RAG.Embedder
+	app.Tools.Register(tools.NewSearchCodeTool(workingDir))
+	app.Tools.Register(tools.NewBashTool(permissionService, workingDir, app.Config))
+	app.Tools.Register(tools.NewRunTestsTool(workingDir))
+	app.Processes = process.NewManager(workingDir)
+	app.Processes.SetUpdateHandler(func(info process.Info, tail []string) {
+		publishProcessCard(eventBroker, info, tail)
+	})
+	app.Tools.Register(tools.NewRunProcessTool(app.Processes, permissionService, app.Config))
+	app.Tools.Register(tools.NewHTTPRequestTool(permissionService, app.Config))
+	app.Tools.Register(tools.NewEditFileTool(permissionService, workingDir, app.Tools.Checkpoints()))
+	app.Tools.Register(tools.NewMultiEditTool(app.Tools))
+	app.Tools.Register(tools.NewScaffoldTool(app.Tools, nil))
+	app.Tools.Register(tools.NewUndoTool(app.Tools.Checkpoints()))
+	app.Tools.Register(tools.NewCheckpointsTool(app.Tools.Checkpoints()))
+	app.Tools.Register(tools.NewGitStatusTool(workingDir))
+	app.Tools.Register(tools.NewGitDiffTool(workingDir))
+	app.Tools.Register(tools.NewGitLogTool(workingDir))
+	app.Tools.Register(tools.NewGitBranchTool(permissionService, workingDir))
+	app.Tools.Register(tools.NewGitCommitTool(permissionService, workingDir, nil))
+	app.Tools.Register(tools.NewReviewTool(workingDir, nil))
+	app.Tools.Register(tools.NewEventsTool(eventBroker))
+	app.Tools.Register(tools.NewStatsTool(app.Queue))
+	app.Tools.Register(tools.NewTeamTool(nil, nil))
+	app.Tools.Register(tools.NewModelTool(app.LLMService, app.Sessions, eventBroker, nil, nil))
+	app.Tools.Register(tools.NewGraphTool(workingDir))
+	app.Tools.Register(tools.NewKnowledgeTool(workingDir))
+	app.Tools.Register(tools.NewProjectsTool(workingDir))
+	app.Tools.Register(tools.NewMemoryTool(permissionService, workingDir))
+	app.DecisionLog = NewDecisionLog(app.Sessions, workingDir)
+	app.Tools.Register(tools.NewDecisionsTool(workingDir, app.DecisionLog.Scan))
+	app.Tools.Register(tools.NewTodosTool(workingDir))
+	app.Tools.Register(tools.NewPlanTool(app.Tasks, nil))
+	app.Agents = agents.NewRunner(workingDir)
+	app.Tools.Register(tools.NewAgentTool(app.Agents))
+	app.Tools.Register(tools.NewStartupScanTool(permissionService, app.Queue, app.Analysis, nil, app.Config, workingDir))
+
+	if cfg := app.Config.Get(); cfg != nil && cfg.LSP.Enabled {
+		app.LSP = lsp.NewManager(workingDir, cfg.LSP.Servers)
 	}
-	
-	// Create appropriate embedder
-	switch embedderType {
-	case "lmstudio":
-		lmStudioURL := "http://localhost:1234"
-		embeddingModel := "text-embedding-nomic-embed-text-v1.5@q8_0"
-		if cfg := app.Config.Get(); cfg != nil {
-			if cfg.LMStudioURL != "" {
-				lmStudioURL = cfg.LMStudioURL
-			}
-			if cfg.Analysis.RAG.EmbeddingModel != "" {
-				embeddingModel = cfg.Analysis.RAG.EmbeddingModel
-			}
+	app.Tools.Register(tools.NewFindDefinitionTool(app.LSP, workingDir))
+	app.Tools.Register(tools.NewFindReferencesTool(app.LSP, workingDir))
+	app.Tools.Register(tools.NewSymbolOutlineTool(app.LSP, workingDir))
+	app.Tools.Register(tools.NewRenameSymbolTool(app.Tools, app.LSP))
+
+	// External MCP servers; their tools join the registry under mcp_<server>_<tool>
+	if cfg := app.Config.Get(); cfg != nil && len(cfg.MCPServers) > 0 {
+		app.MCP = mcp.NewManager(workingDir, cfg.MCPServers)
+		app.MCP.Connect(context.Background())
+		for _, tool := range app.MCP.Tools(permissionService) {
+			app.Tools.Register(tool)
 		}
-		lmEmbedder := embedder.NewLMStudioEmbedder(lmStudioURL)
-		lmEmbedder.SetModel(embeddingModel)
-		sidecarEmbedder = lmEmbedder
-	case "mock":
-		// Mock for testing only
-		sidecarEmbedder = embedder.NewMockEmbedder(384)
-	default:
-		// Default to LM Studio
-		lmStudioURL := "http://localhost:1234"
-		embeddingModel := "text-embedding-nomic-embed-text-v1.5@q8_0"
-		if cfg := app.Config.Get(); cfg != nil {
-			if cfg.LMStudioURL != "" {
-				lmStudioURL = cfg.LMStudioURL
-			}
-			if cfg.Analysis.RAG.EmbeddingModel != "" {
-				embeddingModel = cfg.Analysis.RAG.EmbeddingModel
-			}
+	}
+	app.Tools.Register(mcp.NewStatusTool(app.MCP))
+	
+	// Initialize sidecar/RAG service based on config
+	var ragConfig config.RAGConfig
+	lmStudioURL := "http://localhost:1234"
+	if cfg := app.Config.Get(); cfg != nil {
+		ragConfig = cfg.Analysis.RAG
+		if cfg.LMStudioURL != "" {
+			lmStudioURL = cfg.LMStudioURL
 		}
-		lmEmbedder := embedder.NewLMStudioEmbedder(lmStudioURL)
-		lmEmbedder.SetModel(embeddingModel)
-		sidecarEmbedder = lmEmbedder
 	}
+	sidecarEmbedder := newEmbedder(ragConfig, lmStudioURL, app.Redactor)
 	
-	// Create SQLite vector store
+	// Create vector store
 	databasePath := "vectors.db"
-	if cfg := app.Config.Get(); cfg != nil && cfg.Analysis.RAG.DatabasePath != "" {
-		databasePath = cfg.Analysis.RAG.DatabasePath
+	vectorBackend := vectordb.BackendAuto
+	if cfg := app.Config.Get(); cfg != nil {
+		if cfg.Analysis.RAG.DatabasePath != "" {
+			databasePath = cfg.Analysis.RAG.DatabasePath
+		}
+		if cfg.Analysis.RAG.VectorStore != "" {
+			vectorBackend = cfg.Analysis.RAG.VectorStore
+		}
 	}
 	
 	locoDir := filepath.Join(workingDir, ".loco")
 	dbPath := filepath.Join(locoDir, databasePath)
-	vectorStore, err := vectordb.NewSQLiteStore(dbPath, sidecarEmbedder)
+	vectorStore, err := vectordb.New(vectorBackend, dbPath, sidecarEmbedder)
 	if err != nil {
-		panic("Failed to create SQLite vector store: " + err.Error()) // Fail fast if SQLite can't be created
+		panic("Failed to create vector store: " + err.Error()) // Fail fast if the store can't be created
 	}
 	
 	// Create file watcher based on config
@@ -228,6 +297,18 @@ func New(workingDir string, eventBroker *events.Broker) *App {
 	// Create file watcher (no callback needed - services subscribe to events)
 	fileWatcher = watcher.NewWatcher(debounceDelay, nil)
 	app.FileWatcher = fileWatcher
+
+	// Rewrite the dependency list when a manifest changes, and the test
+	// map when anything does; each skips the rewrite if its inputs are the same
+	fileWatcher.Subscribe(func(event watcher.FileChangeEvent) {
+		go func() {
+			defer crash.Recover("dependency and test analysis")
+			if slices.ContainsFunc(event.Paths, analysis.IsManifest) {
+				_, _, _ = analysis.RefreshDependencies(workingDir)
+			}
+			_, _, _ = analysis.RefreshTestMap(workingDir)
+		}()
+	})
 	
 	// Create sidecar service with file watcher integration
 	if autoIndexOnChange && fileWatcher != nil {
@@ -238,23 +319,196 @@ func New(workingDir string, eventBroker *events.Broker) *App {
 		app.Sidecar = sidecar.NewService(workingDir, sidecarEmbedder, vectorStore)
 	}
 	
+	// Retrieve indexed code into chat prompts, next to the knowledge docs
+	ragTopK, promptBudget := 0, 0
+	if cfg := app.Config.Get(); cfg != nil {
+		ragTopK = cfg.Analysis.RAG.ContextTopK
+		promptBudget = cfg.PromptBudget
+		if promptBudget == 0 {
+			promptBudget = cfg.LMStudioContextSize / 4
+		}
+	}
+	app.LLMService.SetRAG(app.Sidecar, ragTopK, workingDir)
+
+	// Earlier sessions' turns live in a store of their own next to the code
+	// index, so code retrieval and pruning never see them
+	memoryTopK := 0
+	if cfg := app.Config.Get(); cfg != nil {
+		memoryTopK = cfg.Analysis.RAG.ChatMemoryTopK
+	}
+	app.ChatMemory = NewChatMemory(app.Sessions, sidecarEmbedder, func() (sidecar.VectorStore, error) {
+		return vectordb.New(vectorBackend, filepath.Join(locoDir, chatMemoryFile), sidecarEmbedder)
+	})
+	app.LLMService.SetChatMemory(app.ChatMemory, memoryTopK)
+	assembler := NewPromptAssembler(workingDir, promptBudget)
+	assembler.SetTasks(app.Tasks)
+	app.LLMService.SetPromptAssembler(assembler)
+
+	// Route each chat message by its kind, unless /model picked the model
+	app.Router = orchestrator.NewRouter(app.Config)
+	app.LLMService.SetRouter(app.Router, func() bool {
+		current, err := app.Sessions.GetCurrent()
+		return err == nil && current.Model != ""
+	})
+
+	// Have a second model check answers, when verify is enabled
+	app.Verifier = orchestrator.NewVerifier(workingDir, app.Config)
+	app.LLMService.SetVerifier(app.Verifier)
+
 	// Register RAG tools
 	app.Tools.Register(tools.NewRagTool(app.Sidecar))
 	app.Tools.Register(tools.NewRagIndexTool(workingDir, app.Sidecar, nil, app.Config))
+	app.Tools.Register(tools.NewRagPruneTool(app.Sidecar))
+	app.Tools.Register(tools.NewFetchDocsTool(workingDir, app.Sidecar, app.Config))
+	app.Tools.Register(tools.NewAskCodebaseTool(app.Sidecar, nil, workingDir))
+	app.Tools.Register(tools.NewExplainTool(app.Sidecar, nil, workingDir))
+	app.Tools.Register(tools.NewRefactorTool(app.Tools, app.Sidecar, nil))
 
 	// Create unified tool architecture
 	app.ToolExecutor = NewToolExecutor(app.Tools, eventBroker, app.Sessions, app.LLMService, permissionService)
+	app.ToolExecutor.SetTasks(app.Tasks)
+	app.ToolExecutor.SetBuildCheck(NewBuildChecker(workingDir, app.Config, eventBroker))
 	app.InputRouter = NewUserInputRouter(app.ToolExecutor, app.Tools)
 
-	// Wire ToolExecutor to sidecar service for auto-indexing
-	if app.Sidecar != nil && app.ToolExecutor != nil {
-		executorAdapter := &toolExecutorAdapter{executor: app.ToolExecutor}
-		app.Sidecar.SetToolExecutor(executorAdapter)
+	// Surface background re-indexing of changed files in the sidebar
+	if app.Sidecar != nil {
+		app.Sidecar.SetProgressHandler(func(progress sidecar.IndexProgress) {
+			eventBroker.Publish(events.Event{
+				Type: events.RAGIndexProgressEvent,
+				Payload: events.RAGIndexProgressPayload{
+					Total:       progress.Total,
+					Completed:   progress.Completed,
+					Failed:      progress.Failed,
+					CurrentFile: progress.CurrentFile,
+					Done:        progress.Done,
+				},
+			})
+		})
 	}
 
 	return app
 }
 
+// newAnalysisService creates the analysis service with its progress
+// published on the broker, so the sidebar, status bar, HTTP server and
+// headless commands can follow any run by subscribing to analysis.progress
+func (a *App) newAnalysisService(client llm.Client) analysis.Service {
+	service := analysis.NewService(client)
+	if withTeam, ok := service.(*analysis.ServiceWithTeam); ok {
+		withTeam.SetWorkerLimit(a.WorkerLimit)
+		withTeam.SetProgressPublisher(func(p analysis.Progress) {
+			events.AnalysisProgress.Publish(a.EventBroker, events.AnalysisProgressPayload{
+				Phase:          p.Phase,
+				TotalFiles:     p.TotalFiles,
+				CompletedFiles: p.CompletedFiles,
+				CurrentFile:    p.CurrentFile,
+			})
+		})
+	}
+	return service
+}
+
+// newWorkerLimit creates the adaptive limit for quick-tier workers. It starts
+// at worker_concurrency, may grow to max_worker_concurrency (default: the
+// number of workers) and is fed every LM Studio request. Changes are
+// published for the sidebar.
+func newWorkerLimit(cfg *config.Config, broker *events.Broker) *llm.AdaptiveLimit {
+	level, ceiling := 2, 5
+	if cfg != nil {
+		quick := cfg.Analysis.Quick
+		if quick.WorkerConcurrency > 0 {
+			level = quick.WorkerConcurrency
+		}
+		ceiling = max(quick.Workers, level)
+		if quick.MaxWorkerConcurrency > 0 {
+			ceiling = quick.MaxWorkerConcurrency
+		}
+	}
+
+	limit := llm.NewAdaptiveLimit(level, ceiling)
+	limit.OnChange(func(level, ceiling int, reason string) {
+		events.WorkerConcurrency.PublishAsync(broker, events.ConcurrencyPayload{Level: level, Max: ceiling, Reason: reason})
+	})
+	llm.ObserveRequests(limit.Observe)
+	return limit
+}
+
+// queueAging turns queue.aging into the queue's policy. An unknown curve
+// is reported and the default policy is used instead.
+func queueAging(cfg *config.Config, broker *events.Broker) queue.Aging {
+	aging := queue.DefaultAging()
+	if cfg == nil {
+		return aging
+	}
+	settings := cfg.Queue.Aging
+	switch settings.Curve {
+	case config.QueueAgingLinear, config.QueueAgingExponential, config.QueueAgingNone:
+	default:
+		broker.PublishAsync(events.Event{
+			Type: events.StatusMessageEvent,
+			Payload: events.StatusMessagePayload{
+				Message: fmt.Sprintf("Unknown queue.aging.curve %q (use linear, exponential or none); using %s", settings.Curve, aging),
+				Type:    "warning",
+			},
+		})
+		return aging
+	}
+	return queue.Aging{
+		Curve:    settings.Curve,
+		Interval: time.Duration(settings.IntervalMs) * time.Millisecond,
+		Step:     settings.Step,
+		Ceiling:  settings.MaxPriority,
+	}
+}
+
+// newRedactor creates the redactor for model requests, logging to
+// .loco/redactions.log, or nil when redaction.disabled is set. A bad extra
+// pattern is reported and the built-in rules are used alone. Secrets are
+// announced the first time they are held back.
+func newRedactor(cfg *config.Config, workingDir string, broker *events.Broker) *redact.Redactor {
+	var patterns []string
+	if cfg != nil {
+		if cfg.Redaction.Disabled {
+			return nil
+		}
+		patterns = cfg.Redaction.Patterns
+	}
+
+	logPath := filepath.Join(workingDir, ".loco", "redactions.log")
+	redactor, err := redact.New(logPath, patterns)
+	if err != nil {
+		broker.PublishAsync(events.Event{
+			Type: events.StatusMessageEvent,
+			Payload: events.StatusMessagePayload{
+				Message: fmt.Sprintf("Redaction: %v; using the built-in rules only", err),
+				Type:    "warning",
+			},
+		})
+		redactor, _ = redact.New(logPath, nil)
+	}
+	redactor.OnRedact(func(source string, findings []redact.Finding) {
+		var kinds []string
+		for _, finding := range findings {
+			if !slices.Contains(kinds, finding.Kind) {
+				kinds = append(kinds, finding.Kind)
+			}
+		}
+		broker.PublishAsync(events.Event{
+			Type: events.StatusMessageEvent,
+			Payload: events.StatusMessagePayload{
+				Message: fmt.Sprintf("Redacted %d secret(s) (%s) from a %s; see .loco/redactions.log", len(findings), strings.Join(kinds, ", "), source),
+				Type:    "warning",
+			},
+		})
+	})
+	return redactor
+}
+
+// WorkingDir returns the project directory loco was started in
+func (a *App) WorkingDir() string {
+	return a.workingDir
+}
+
 // SetLLMClient sets the LLM client for all services that need it
 func (a *App) SetLLMClient(client llm.Client) {
 	a.LLM = client
@@ -268,13 +522,52 @@ func (a *App) SetLLMClient(client llm.Client) {
 			lm.SetNumKeep(cfg.LMStudioNumKeep)
 		}
 	}
+	if lm, ok := client.(*llm.LMStudioClient); ok {
+		lm.SetRedactor(a.Redactor)
+	}
 
 	// Recreate analysis service with LLM client
-	a.Analysis = analysis.NewService(client)
+	a.Analysis = a.newAnalysisService(client)
 
 	// Register or replace the analyze tool now that we have the service
 	// Analyze tool deleted - no longer needed
 
+	// git_commit generates messages with the model when none is given,
+	// review, scaffold, explain and refactor write their output with it,
+	// and ask_codebase answers questions with it
+	if a.Tools != nil {
+		a.Tools.Replace(tools.NewGitCommitTool(a.permissionServiceInternal, a.workingDir, client))
+		a.Tools.Replace(tools.NewReviewTool(a.workingDir, client))
+		a.Tools.Replace(tools.NewScaffoldTool(a.Tools, client))
+		a.Tools.Replace(tools.NewExplainTool(a.Sidecar, client, a.workingDir))
+		a.Tools.Replace(tools.NewRefactorTool(a.Tools, a.Sidecar, client))
+		a.Tools.Replace(tools.NewPlanTool(a.Tasks, client))
+		if a.Sidecar != nil {
+			a.Tools.Replace(tools.NewAskCodebaseTool(a.Sidecar, client, a.workingDir))
+		}
+	}
+
+	// Sub-agents fall back to the new client until there is a team
+	if a.Agents != nil {
+		a.Agents.SetClient(client, a.Queue)
+	}
+
+	// So do answer reviews
+	if a.Verifier != nil {
+		a.Verifier.SetClient(client)
+	}
+
+	// Decisions are read from conversations by the new client, in the
+	// background of the queue
+	if a.DecisionLog != nil {
+		a.DecisionLog.SetClient(client, a.Queue)
+	}
+
+	// startup_scan describes the project with the new client through the queue
+	if a.Tools != nil {
+		a.Tools.Replace(tools.NewStartupScanTool(a.permissionServiceInternal, a.Queue, a.Analysis, client, a.Config, a.workingDir))
+	}
+
 	// Register startup_welcome tool if LM Studio client is available
 	if a.Tools != nil {
 		if lm, ok := client.(*llm.LMStudioClient); ok {
@@ -291,6 +584,20 @@ func (a *App) SetModelManager(mm *llm.ModelManager) {
 
 // InitLLMFromConfig initializes the LLM client using configuration settings
 func (a *App) InitLLMFromConfig() error {
+	if cfg := a.Config.Get(); cfg != nil && cfg.Provider == "mock" {
+		return a.initMockLLM(cfg.Mock)
+	}
+
+	// Record or replay model requests for reproducible runs
+	llm.UseCassette(nil)
+	if cfg := a.Config.Get(); cfg != nil && cfg.Cassette != "" {
+		cassette, err := llm.NewCassette(filepath.Join(a.workingDir, ".loco", "cassettes"), cfg.Cassette)
+		if err != nil {
+			return err
+		}
+		llm.UseCassette(cassette)
+	}
+
 	// Create LLM client (main client for chat)
 	client := llm.NewLMStudioClient()
 
@@ -307,12 +614,39 @@ func (a *App) InitLLMFromConfig() error {
 	modelManager := llm.NewModelManager(a.Sessions.ProjectPath)
 	a.SetModelManager(modelManager)
 
+	// Probe the loaded models so the team can be picked by what they can do
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	probes, probeErr := client.ProbeModels(ctx, filepath.Join(a.workingDir, ".loco", "model_probes.json"))
+	cancel()
+	if probeErr == nil {
+		a.ModelProbes = probes
+		a.activity.setLoaded(probes)
+		a.LLMService.SetModelProbes(probes)
+	}
+
 	// Create team clients for different model sizes
 	if models, err := client.GetModels(); err == nil && len(models) > 0 {
-		// Build team from config.llm model IDs if provided, otherwise auto
+		// Build team from config.llm model IDs if provided; probed models
+		// fill the other slots, falling back to guessing sizes from names
 		var team *llm.ModelTeam
 		if cfg := a.Config.Get(); cfg != nil {
 			team = llm.BuildTeamFromConfig(cfg.LLM.Smallest.ModelID, cfg.LLM.Medium.ModelID, cfg.LLM.Largest.ModelID, models)
+			if auto := llm.TeamFromProbes(probes); auto != nil {
+				if cfg.LLM.Smallest.ModelID == "" {
+					team.Small = auto.Small
+				}
+				if cfg.LLM.Medium.ModelID == "" {
+					team.Medium = auto.Medium
+				}
+				if cfg.LLM.Largest.ModelID == "" {
+					team.Large = auto.Large
+				}
+				if cfg.LLM.Smallest.ModelID == "" && cfg.LLM.Medium.ModelID == "" && cfg.LLM.Largest.ModelID == "" {
+					team.Name = auto.Name
+				}
+			}
+		} else if auto := llm.TeamFromProbes(probes); auto != nil {
+			team = auto
 		} else {
 			team = llm.GetDefaultTeam(models)
 		}
@@ -323,49 +657,130 @@ func (a *App) InitLLMFromConfig() error {
 			if cfg := a.Config.Get(); cfg != nil {
 				if c, ok := a.TeamClients.Small.(*llm.LMStudioClient); ok {
 					c.SetEndpoint(cfg.LMStudioURL)
+					c.SetRedactor(a.Redactor)
 					// Use policy context if provided, else global
-					if cfg.LLM.Smallest.ContextSize > 0 {
-						c.SetContextSize(cfg.LLM.Smallest.ContextSize)
-					} else {
-						c.SetContextSize(cfg.LMStudioContextSize)
-					}
+					c.SetContextSize(a.contextSizeFor(team.Small, cfg.LLM.Smallest.ContextSize, cfg.LMStudioContextSize))
 					c.SetNumKeep(cfg.LMStudioNumKeep)
 				}
 				if c, ok := a.TeamClients.Medium.(*llm.LMStudioClient); ok {
 					c.SetEndpoint(cfg.LMStudioURL)
-					if cfg.LLM.Medium.ContextSize > 0 {
-						c.SetContextSize(cfg.LLM.Medium.ContextSize)
-					} else {
-						c.SetContextSize(cfg.LMStudioContextSize)
-					}
+					c.SetRedactor(a.Redactor)
+					c.SetContextSize(a.contextSizeFor(team.Medium, cfg.LLM.Medium.ContextSize, cfg.LMStudioContextSize))
 					c.SetNumKeep(cfg.LMStudioNumKeep)
 				}
 				if c, ok := a.TeamClients.Large.(*llm.LMStudioClient); ok {
 					c.SetEndpoint(cfg.LMStudioURL)
-					if cfg.LLM.Largest.ContextSize > 0 {
-						c.SetContextSize(cfg.LLM.Largest.ContextSize)
-					} else {
-						c.SetContextSize(cfg.LMStudioContextSize)
-					}
+					c.SetRedactor(a.Redactor)
+					c.SetContextSize(a.contextSizeFor(team.Large, cfg.LLM.Largest.ContextSize, cfg.LMStudioContextSize))
 					c.SetNumKeep(cfg.LMStudioNumKeep)
 				}
 			}
 
-			// Update analysis service with team clients
+			// Update analysis service with team clients; tiers share the
+			// queue so each model works on its own runs
 			if analysisService, ok := a.Analysis.(*analysis.ServiceWithTeam); ok {
 				analysisService.SetTeamClients(teamClients)
+				analysisService.SetQueue(a.Queue)
+			}
+			if cfg := a.Config.Get(); cfg != nil && a.Queue != nil {
+				setModelLimits(a.Queue, team, cfg.LLM)
+			}
+
+			// Rerank RAG results with the small model when enabled
+			if cfg := a.Config.Get(); cfg != nil && cfg.Analysis.RAG.Rerank && a.Sidecar != nil {
+				timeout := time.Duration(cfg.Analysis.RAG.RerankTimeoutMs) * time.Millisecond
+				reranker := sidecar.NewLLMReranker(teamClients.Small, a.Queue, timeout)
+				a.Sidecar.SetReranker(reranker, cfg.Analysis.RAG.RerankCandidates)
+			}
+
+			// Route sub-agents, chat messages and reviews to the team's models
+			if a.Agents != nil {
+				a.Agents.SetTeam(teamClients)
+			}
+			if a.Router != nil {
+				a.Router.SetTeam(teamClients)
+			}
+			if a.Verifier != nil {
+				a.Verifier.SetTeam(teamClients)
 			}
 
 			// Give ToolExecutor access to team to display in welcome
 			if a.ToolExecutor != nil {
 				a.ToolExecutor.SetTeamClients(teamClients)
 			}
+
+			if a.Tools != nil {
+				a.Tools.Replace(tools.NewTeamTool(team, a.ModelProbes))
+				a.Tools.Replace(tools.NewModelTool(a.LLMService, a.Sessions, a.EventBroker, team, a.ModelProbes))
+			}
+		}
+	}
+
+	return nil
+}
+
+// initMockLLM installs the scripted mock client in place of LM Studio, so
+// chat and analysis run with no model server. There is no team; analysis
+// falls back to the one client.
+func (a *App) initMockLLM(cfg config.MockConfig) error {
+	var responses []llm.MockResponse
+	if cfg.Responses != "" {
+		path := cfg.Responses
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(a.workingDir, path)
+		}
+		loaded, err := llm.LoadMockResponses(path)
+		if err != nil {
+			return fmt.Errorf("failed to load mock responses: %w", err)
 		}
+		responses = loaded
 	}
 
+	a.SetLLMClient(llm.NewMockClient(responses, time.Duration(cfg.LatencyMs)*time.Millisecond))
+	a.SetModelManager(llm.NewModelManager(a.Sessions.ProjectPath))
 	return nil
 }
 
+// probeTimeout bounds model probing at startup, benchmarks included
+const probeTimeout = 60 * time.Second
+
+// contextSizeFor picks the context size for a team model: the slot's policy
+// if set, else the global setting, capped at what the probed model supports
+func (a *App) contextSizeFor(modelID string, policy, global int) int {
+	size := global
+	if policy > 0 {
+		size = policy
+	}
+	for _, probe := range a.ModelProbes {
+		if probe.ID == modelID && probe.ContextLength > 0 && size > probe.ContextLength {
+			return probe.ContextLength
+		}
+	}
+	return size
+}
+
+// setModelLimits gives each team model its policy's concurrency in the
+// queue. A model in several slots gets the highest.
+func setModelLimits(q *queue.Manager, team *llm.ModelTeam, policies config.LLMConfig) {
+	slots := []struct {
+		model string
+		limit int
+	}{
+		{team.Small, policies.Smallest.Concurrency},
+		{team.Medium, policies.Medium.Concurrency},
+		{team.Large, policies.Largest.Concurrency},
+	}
+	limits := make(map[string]int)
+	for _, slot := range slots {
+		if slot.model != "" && slot.limit > limits[slot.model] {
+			limits[slot.model] = slot.limit
+		}
+	}
+	for model, n := range limits {
+		q.SetModelLimit(model, n)
+	}
+}
+
 // Cleanup stops all services gracefully
 func (a *App) Cleanup() {
 	// Stop file watcher
@@ -377,6 +792,39 @@ func (a *App) Cleanup() {
 	if a.Sidecar != nil {
 		a.Sidecar.Stop()
 	}
+
+	// Stop embedding conversation turns
+	if a.stopChatMemory != nil {
+		a.stopChatMemory()
+	}
+	if a.ChatMemory != nil {
+		a.ChatMemory.Close()
+	}
+
+	// Stop logging decisions
+	if a.stopDecisionLog != nil {
+		a.stopDecisionLog()
+	}
+
+	// Shut down language servers
+	if a.LSP != nil {
+		a.LSP.Close()
+	}
+
+	// Stop external MCP servers
+	if a.MCP != nil {
+		a.MCP.Close()
+	}
+
+	// Stop background processes and the children they started
+	if a.Processes != nil {
+		a.Processes.Close()
+	}
+
+	// Drain queued LLM requests
+	if a.Queue != nil {
+		_ = a.Queue.Stop()
+	}
 }
 
 // RunStartupAnalysis triggers startup tools and analysis.
@@ -393,11 +841,26 @@ func (a *App) RunStartupAnalysis() {
 		Input: `{}`,
 	})
 
+	// Structural, dependency, test, TODO and history analysis need no
+	// model, so the chat has some knowledge of the project before one is
+	// loaded
+	if a.Analysis != nil {
+		go func() {
+			defer crash.Recover("instant analysis")
+			_, _ = a.Analysis.InstantAnalyze(context.Background(), a.workingDir)
+			_, _, _ = analysis.RefreshDependencies(a.workingDir)
+			_, _, _ = analysis.RefreshTestMap(a.workingDir)
+			_, _, _ = analysis.RefreshTodos(context.Background(), a.workingDir)
+			_, _ = analysis.RefreshChangelog(context.Background(), a.workingDir)
+		}()
+	}
+
 	// Start file watcher if auto-indexing is enabled
 	if a.FileWatcher != nil {
 		if cfg := a.Config.Get(); cfg != nil && cfg.Analysis.RAG.AutoIndexOnChange {
 			// Start watching the working directory
 			go func() {
+				defer crash.Recover("file watcher")
 				if err := a.FileWatcher.StartWatching(a.workingDir); err != nil {
 					// Log error but don't fail startup
 					_ = err
@@ -409,6 +872,7 @@ func (a *App) RunStartupAnalysis() {
 	// Start sidecar/RAG service BEFORE startup scan to avoid conflicts
 	if a.Sidecar != nil {
 		go func() {
+			defer crash.Recover("sidecar")
 			if err := a.Sidecar.Start(context.Background()); err != nil {
 				// Log error but don't fail startup
 				_ = err
@@ -429,10 +893,31 @@ func (a *App) RunStartupAnalysis() {
 		}
 	}
 
-	// Ensure startup_scan uses the updated analysis service (replace tool)
-	// Startup scan tool deleted - no longer needed
-	
-	// Startup scan functionality removed - no longer auto-running
+	// Embed new conversation turns in the background for recall
+	if a.ChatMemory != nil {
+		if cfg := a.Config.Get(); cfg != nil && cfg.Analysis.RAG.ChatMemoryTopK > 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+			a.stopChatMemory = cancel
+			a.ChatMemory.Start(ctx, time.Duration(cfg.Analysis.RAG.ChatMemorySyncMs)*time.Millisecond)
+		}
+	}
+
+	// Log decisions from settled conversations in the background
+	if a.DecisionLog != nil {
+		if cfg := a.Config.Get(); cfg != nil && cfg.Analysis.DecisionLogMs > 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+			a.stopDecisionLog = cancel
+			a.DecisionLog.Start(ctx, time.Duration(cfg.Analysis.DecisionLogMs)*time.Millisecond)
+		}
+	}
+
+	// Startup scan goes through the queue after indexing has been submitted
+	if cfg := a.Config.Get(); cfg != nil && cfg.Analysis.Startup.Autorun {
+		a.ToolExecutor.ExecuteSystem(tools.ToolCall{
+			Name:  tools.StartupScanToolName,
+			Input: `{}`,
+		})
+	}
 
 	// Analysis functionality removed - no longer auto-running
 }
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/crash"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/tools"
+	"github.com/billie-coop/loco/internal/tui/events"
+)
+
+// maxBuildCheckLines caps the errors reported from one check
+const maxBuildCheckLines = 30
+
+// goDiagnosticLine matches a compiler or vet finding (path.go:line:col: message)
+var goDiagnosticLine = regexp.MustCompile(`^\S+\.go:\d+(:\d+)?: `)
+
+// BuildChecker runs go build and go vet in the background after tools
+// change files in a Go module, when build_check is enabled, and reports the
+// errors to the model so it can fix its own mistakes. Changes made while a
+// check runs are covered by one more check once it finishes.
+type BuildChecker struct {
+	workingDir string
+	config     *config.Manager
+	broker     *events.Broker
+
+	mu       sync.Mutex
+	running  bool
+	pending  string // Tool whose change arrived during a check
+	reported string // Errors of the last failure reported, to skip repeats
+}
+
+// NewBuildChecker creates the build checker for the project
+func NewBuildChecker(workingDir string, cfg *config.Manager, broker *events.Broker) *BuildChecker {
+	return &BuildChecker{workingDir: workingDir, config: cfg, broker: broker}
+}
+
+// changesFiles reports whether a tool edits project files
+func changesFiles(toolName string) bool {
+	switch toolName {
+	case tools.EditFileToolName, tools.MultiEditToolName, tools.ScaffoldToolName,
+		tools.RefactorToolName, tools.RenameSymbolToolName, tools.UndoToolName:
+		return true
+	}
+	return false
+}
+
+// AfterTool starts a check if toolName changed files, the check is enabled
+// and the project is a Go module
+func (c *BuildChecker) AfterTool(toolName string) {
+	if !changesFiles(toolName) || !c.settings().Enabled {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(c.workingDir, "go.mod")); err != nil {
+		return
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	if c.running {
+		c.pending = toolName
+		c.mu.Unlock()
+		return
+	}
+	c.running = true
+	c.mu.Unlock()
+
+	go func() {
+		defer crash.Recover("build check")
+		for {
+			c.check(toolName)
+
+			c.mu.Lock()
+			if c.pending == "" {
+				c.running = false
+				c.mu.Unlock()
+				return
+			}
+			toolName, c.pending = c.pending, ""
+			c.mu.Unlock()
+		}
+	}()
+}
+
+// settings returns the build_check config, falling back to defaults
+func (c *BuildChecker) settings() config.BuildCheckConfig {
+	if c.config != nil {
+		if cfg := c.config.Get(); cfg != nil {
+			return cfg.BuildCheck
+		}
+	}
+	return config.DefaultConfig().BuildCheck
+}
+
+// check runs the configured checks in order, stopping at the first that
+// fails, and reports the result when it differs from the last one
+func (c *BuildChecker) check(toolName string) {
+	settings := c.settings()
+	timeout := time.Duration(settings.TimeoutMs) * time.Millisecond
+
+	for _, name := range []string{config.BuildCheckBuild, config.BuildCheckVet} {
+		if !slices.Contains(settings.Checks, name) {
+			continue
+		}
+		command := "go " + name + " ./..."
+		errs, err := c.run(timeout, name)
+		if err != nil {
+			// Not a verdict on the code (e.g. the check timed out)
+			c.publish("⚠️ "+command+" could not run: "+err.Error(), "")
+			return
+		}
+		if errs == "" {
+			continue
+		}
+
+		c.mu.Lock()
+		repeat := errs == c.reported
+		c.reported = errs
+		c.mu.Unlock()
+		if !repeat {
+			c.publish(fmt.Sprintf("🔨 %s failed after %s:\n%s", command, toolName, errs),
+				fmt.Sprintf("`%s` fails after your last change (%s). Fix these errors:\n%s", command, toolName, errs))
+		}
+		return
+	}
+
+	// Only a recovery is worth a message; passing is the normal case
+	c.mu.Lock()
+	recovered := c.reported != ""
+	c.reported = ""
+	c.mu.Unlock()
+	if recovered {
+		c.publish("🔨 The build is clean again", "The Go build and vet errors reported earlier are fixed.")
+	}
+}
+
+// run runs one go command over the module, returning the diagnostics it
+// printed; an empty string means it passed
+func (c *BuildChecker) run(timeout time.Duration, subcommand string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	args := []string{subcommand, "./..."}
+	if subcommand == config.BuildCheckBuild {
+		// Compile without writing binaries into the project
+		args = []string{"build", "-o", os.DevNull, "./..."}
+	}
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = c.workingDir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("timed out after %s", timeout)
+	}
+	if err == nil {
+		return "", nil
+	}
+	if _, ok := err.(*exec.ExitError); !ok {
+		return "", err
+	}
+	return compactDiagnostics(out.String()), nil
+}
+
+// compactDiagnostics keeps the file:line findings of go build or go vet
+// output, or all of it when there are none (a broken go.mod, say)
+func compactDiagnostics(output string) string {
+	var findings, other []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		line = strings.TrimRight(line, " \t")
+		switch {
+		case goDiagnosticLine.MatchString(line):
+			findings = append(findings, line)
+		case line != "" && !strings.HasPrefix(line, "# "):
+			other = append(other, line)
+		}
+	}
+	if len(findings) == 0 {
+		findings = other
+	}
+	if len(findings) > maxBuildCheckLines {
+		findings = append(findings[:maxBuildCheckLines:maxBuildCheckLines], fmt.Sprintf("... %d more", len(findings)-maxBuildCheckLines))
+	}
+	return strings.Join(findings, "\n")
+}
+
+// publish posts a system notice, carrying feedback for the model when set
+func (c *BuildChecker) publish(content, feedback string) {
+	msg := llm.Message{Role: "system", Content: content}
+	if feedback != "" {
+		msg.Metadata = &llm.MessageMetadata{Feedback: feedback}
+	}
+	c.broker.Publish(events.Event{
+		Type:    events.SystemMessageEvent,
+		Payload: events.MessagePayload{Message: msg},
+	})
+}
@@ -0,0 +1,273 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/crash"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/session"
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+const (
+	// chatMemoryFile is the project's store of embedded conversation turns
+	// under .loco, kept apart from the code index so pruning and code
+	// retrieval never see them
+	chatMemoryFile = "chat_memory.db"
+
+	// chatMemoryPrefix starts the path every turn of a session is stored
+	// under, so a cleared or deleted session's turns go in one Delete
+	chatMemoryPrefix = "session:"
+
+	// maxTurnChars caps the text embedded for one turn
+	maxTurnChars = 4000
+
+	// turnSettleTime is how long the last turn of a session must sit
+	// untouched before it is embedded, so replies still being worked on
+	// aren't stored half done
+	turnSettleTime = time.Minute
+)
+
+// ChatMemory embeds the project's past conversation turns into a vector
+// store of their own and recalls the ones relevant to a new message, so
+// decisions made in earlier sessions carry over. A turn is a user message
+// with the assistant's replies to it.
+type ChatMemory struct {
+	sessions *session.Manager
+	embedder sidecar.Embedder
+	open     func() (sidecar.VectorStore, error)
+
+	mu    sync.Mutex // Guards store
+	store sidecar.VectorStore
+
+	syncMu sync.Mutex     // One sync at a time; recall doesn't wait for it
+	synced map[string]int // Session path -> turns stored, when the store has no metadata
+}
+
+// NewChatMemory creates a chat memory over the sessions. The store is
+// opened on first use, since opening it may need the embedder running.
+func NewChatMemory(sessions *session.Manager, embedder sidecar.Embedder, open func() (sidecar.VectorStore, error)) *ChatMemory {
+	return &ChatMemory{
+		sessions: sessions,
+		embedder: embedder,
+		open:     open,
+		synced:   make(map[string]int),
+	}
+}
+
+// Start syncs now and then every interval until ctx is done. Sync failures,
+// such as the embedder not running, are retried on the next tick.
+func (m *ChatMemory) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		defer crash.Recover("chat memory")
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			_, _ = m.Sync(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Sync embeds the turns added since the last sync and drops the turns of
+// sessions that were cleared or deleted. Returns how many turns it stored.
+func (m *ChatMemory) Sync(ctx context.Context) (int, error) {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+
+	store, err := m.openStore()
+	if err != nil {
+		return 0, err
+	}
+	synced := m.syncedCounts(ctx, store)
+
+	stored := 0
+	live := make(map[string]bool)
+	for _, s := range m.sessions.ListSessions() {
+		path := chatMemoryPrefix + s.ID
+		live[path] = true
+
+		turns := chatTurns(s.Messages.ToSlice(), time.Since(s.LastUpdated) >= turnSettleTime)
+		done := synced[path]
+		if len(turns) < done {
+			// Cleared or rewritten since: store it afresh
+			if err := store.Delete(ctx, path); err != nil {
+				return stored, err
+			}
+			done = 0
+		}
+		if len(turns) == done {
+			continue
+		}
+
+		texts := make([]string, 0, len(turns)-done)
+		for _, turn := range turns[done:] {
+			texts = append(texts, turnText(s.Title, turn))
+		}
+		embeddings, err := m.embedder.EmbedBatch(ctx, texts)
+		if err != nil {
+			return stored, fmt.Errorf("failed to embed turns of %s: %w", s.ID, err)
+		}
+
+		docs := make([]sidecar.Document, len(texts))
+		for i, text := range texts {
+			docs[i] = sidecar.Document{
+				ID:        fmt.Sprintf("%s#%d", path, done+i),
+				Path:      path,
+				Content:   text,
+				Embedding: embeddings[i],
+				UpdatedAt: turns[done+i][0].Time,
+			}
+		}
+		if err := store.StoreBatch(ctx, docs); err != nil {
+			return stored, err
+		}
+		m.setSynced(ctx, store, path, len(turns))
+		stored += len(docs)
+	}
+
+	for path := range synced {
+		if !live[path] {
+			_ = store.Delete(ctx, path)
+			m.deleteSynced(ctx, store, path)
+		}
+	}
+	return stored, nil
+}
+
+// Recall returns the k stored turns most relevant to query, leaving out
+// the current session's, which are already in the conversation
+func (m *ChatMemory) Recall(ctx context.Context, query string, k int) ([]sidecar.SimilarDocument, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+	store, err := m.openStore()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := store.QueryText(ctx, query, k*2)
+	if err != nil {
+		return nil, err
+	}
+	current := ""
+	if s, err := m.sessions.GetCurrent(); err == nil {
+		current = chatMemoryPrefix + s.ID
+	}
+	recalled := make([]sidecar.SimilarDocument, 0, k)
+	for _, result := range results {
+		if result.Path != current && len(recalled) < k {
+			recalled = append(recalled, result)
+		}
+	}
+	return recalled, nil
+}
+
+// Close closes the store if it was opened
+func (m *ChatMemory) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if closer, ok := m.store.(io.Closer); ok {
+		_ = closer.Close()
+	}
+	m.store = nil
+}
+
+func (m *ChatMemory) openStore() (sidecar.VectorStore, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.store == nil {
+		store, err := m.open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open chat memory: %w", err)
+		}
+		m.store = store
+	}
+	return m.store, nil
+}
+
+// syncedCounts returns how many turns of each session are stored. Stores
+// that keep metadata remember this across runs in their file states.
+func (m *ChatMemory) syncedCounts(ctx context.Context, store sidecar.VectorStore) map[string]int {
+	meta, ok := store.(sidecar.MetadataStore)
+	if !ok {
+		return m.synced
+	}
+	states, err := meta.GetFileStates(ctx)
+	if err != nil {
+		return m.synced
+	}
+	counts := make(map[string]int)
+	for path, state := range states {
+		if n, err := strconv.Atoi(state.Hash); err == nil && strings.HasPrefix(path, chatMemoryPrefix) {
+			counts[path] = n
+		}
+	}
+	return counts
+}
+
+func (m *ChatMemory) setSynced(ctx context.Context, store sidecar.VectorStore, path string, turns int) {
+	if meta, ok := store.(sidecar.MetadataStore); ok {
+		_ = meta.SetFileState(ctx, path, sidecar.FileState{Hash: strconv.Itoa(turns), IndexedAt: time.Now(), Success: true})
+		return
+	}
+	m.synced[path] = turns
+}
+
+func (m *ChatMemory) deleteSynced(ctx context.Context, store sidecar.VectorStore, path string) {
+	if meta, ok := store.(sidecar.MetadataStore); ok {
+		_ = meta.DeleteFileState(ctx, path)
+		return
+	}
+	delete(m.synced, path)
+}
+
+// chatTurns groups a session's messages into turns: each user message with
+// the assistant messages answering it. System notices and tool output are
+// left out. The last turn only counts once settled, since more replies may
+// still come.
+func chatTurns(messages []llm.Message, settled bool) [][]llm.Message {
+	var turns [][]llm.Message
+	for _, msg := range messages {
+		switch {
+		case msg.Role == "user":
+			turns = append(turns, []llm.Message{msg})
+		case msg.Role == "assistant" && len(turns) > 0 && strings.TrimSpace(msg.Content) != "":
+			turns[len(turns)-1] = append(turns[len(turns)-1], msg)
+		}
+	}
+	// A question nobody answered isn't worth recalling
+	if len(turns) > 0 && (!settled || len(turns[len(turns)-1]) == 1) {
+		turns = turns[:len(turns)-1]
+	}
+	return turns
+}
+
+// turnText is what is embedded and recalled for a turn: where it came
+// from, then the exchange, cut to maxTurnChars
+func turnText(title string, turn []llm.Message) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Session %q, %s\n", title, turn[0].Time.Format("Jan 2, 2006"))
+	for _, msg := range turn {
+		role := "User"
+		if msg.Role == "assistant" {
+			role = "Assistant"
+		}
+		fmt.Fprintf(&b, "\n%s: %s\n", role, strings.TrimSpace(msg.Content))
+	}
+	text := b.String()
+	if len(text) > maxTurnChars {
+		text = strings.ToValidUTF8(text[:maxTurnChars], "") + "\n... (truncated)"
+	}
+	return text
+}
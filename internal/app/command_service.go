@@ -3,10 +3,12 @@ package app
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/clipboard"
+	"github.com/billie-coop/loco/internal/crash"
 	"github.com/billie-coop/loco/internal/llm"
 	"github.com/billie-coop/loco/internal/session"
 	"github.com/billie-coop/loco/internal/tui/events"
@@ -65,11 +67,17 @@ func (s *CommandService) HandleCommand(command string) {
 		s.handleDebugToggle()
 		commandResult = "🐛 Toggled debug mode"
 	case "/analyze":
+		args, dryRun := analyzeDryRun(parts[1:])
 		tier := "quick"
-		if len(parts) > 1 {
-			tier = parts[1]
+		if len(args) > 0 {
+			tier = args[0]
+		}
+		if dryRun {
+			s.handleAnalyzeDryRun(args)
+			commandResult = fmt.Sprintf("🧮 Estimating %s analysis...", tier)
+			break
 		}
-		s.handleAnalyze(parts[1:]) // Pass remaining arguments
+		s.handleAnalyze(args) // Pass remaining arguments
 		commandResult = fmt.Sprintf("🔍 Starting %s analysis...", tier)
 	case "/copy":
 		count := "1"
@@ -111,10 +119,12 @@ func (s *CommandService) handleHelp() {
 **Chat Commands:**
 • /clear - Clear all messages
 • /copy [N] - Copy last N messages to clipboard (default: 1)
+• /export md|html [path] - Save the conversation as a document
 • /help - Show this help message
 
 **Analysis:**
-• /analyze [tier] - Analyze project (quick/detailed/deep/full)
+• /analyze [tier] - Analyze project (instant/quick/detailed/deep/full)
+• /analyze [tier] --dry-run - Estimate files, tokens and time without calling a model
 
 **Settings:**
 • /model [select] - Show current model or open selection dialog
@@ -222,13 +232,13 @@ func (s *CommandService) handleAnalyze(args []string) {
 	
 	// Validate tier
 	validTiers := map[string]bool{
-		"quick": true, "detailed": true, "deep": true, "full": true,
+		"instant": true, "quick": true, "detailed": true, "deep": true, "full": true,
 	}
 	if !validTiers[tier] {
 		s.eventBroker.Publish(events.Event{
 			Type: events.StatusMessageEvent,
 			Payload: events.StatusMessagePayload{
-				Message: "Invalid tier. Use: quick, detailed, deep, or full",
+				Message: "Invalid tier. Use: instant, quick, detailed, deep, or full",
 				Type:    "error",
 			},
 		})
@@ -258,6 +268,7 @@ func (s *CommandService) handleAnalyze(args []string) {
 	
 	// Run analysis in background
 	go func() {
+		defer crash.Recover("analysis")
 		workingDir := "."
 		if s.app.Sessions != nil && s.app.Sessions.ProjectPath != "" {
 			workingDir = s.app.Sessions.ProjectPath
@@ -280,6 +291,8 @@ func (s *CommandService) handleAnalyze(args []string) {
 		// Call appropriate analysis tier
 		ctx := context.Background()
 		switch tier {
+		case "instant":
+			result, err = s.app.Analysis.InstantAnalyze(ctx, workingDir)
 		case "quick":
 			result, err = s.app.Analysis.QuickAnalyze(ctx, workingDir)
 		case "detailed":
@@ -347,6 +360,59 @@ func (s *CommandService) handleAnalyze(args []string) {
 	}()
 }
 
+// analyzeDryRun takes --dry-run out of /analyze's arguments, reporting
+// whether it was there
+func analyzeDryRun(args []string) ([]string, bool) {
+	rest := make([]string, 0, len(args))
+	dryRun := false
+	for _, arg := range args {
+		if arg == "--dry-run" {
+			dryRun = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, dryRun
+}
+
+// handleAnalyzeDryRun shows what running a tier would take, without calling
+// a model
+func (s *CommandService) handleAnalyzeDryRun(args []string) {
+	tier := analysis.TierQuick
+	if len(args) > 0 {
+		tier = analysis.Tier(strings.ToLower(args[0]))
+	}
+	workingDir := "."
+	if s.app.Sessions != nil && s.app.Sessions.ProjectPath != "" {
+		workingDir = s.app.Sessions.ProjectPath
+	}
+
+	go func() {
+		defer crash.Recover("analysis estimate")
+		estimates, err := s.app.EstimateAnalysis(workingDir, tier)
+		if err != nil {
+			s.eventBroker.Publish(events.Event{
+				Type: events.StatusMessageEvent,
+				Payload: events.StatusMessagePayload{
+					Message: "Estimate failed: " + err.Error(),
+					Type:    "error",
+				},
+			})
+			return
+		}
+
+		s.eventBroker.Publish(events.Event{
+			Type: events.SystemMessageEvent,
+			Payload: events.MessagePayload{
+				Message: llm.Message{
+					Role:    "system",
+					Content: fmt.Sprintf("🧮 %s Analysis Estimate\n\n%s", strings.Title(string(tier)), analysis.FormatEstimates(estimates)),
+				},
+			},
+		})
+	}()
+}
+
 // handleCopy copies the last N messages to clipboard
 func (s *CommandService) handleCopy(args []string) {
 	// Parse count argument (default to 1)
@@ -421,7 +487,7 @@ func (s *CommandService) handleCopy(args []string) {
 	
 	// Copy to clipboard
 	content := formatted.String()
-	if err := copyToClipboard(content); err != nil {
+	if err := clipboard.Copy(content); err != nil {
 		s.eventBroker.Publish(events.Event{
 			Type: events.StatusMessageEvent,
 			Payload: events.StatusMessagePayload{
@@ -446,45 +512,6 @@ func (s *CommandService) handleCopy(args []string) {
 	})
 }
 
-// copyToClipboard copies text to the system clipboard
-func copyToClipboard(text string) error {
-	// Detect platform and use appropriate command
-	// macOS
-	cmd := exec.Command("pbcopy")
-	
-	// You could add Linux/Windows support later:
-	// Linux: xclip -selection clipboard
-	// Windows: clip
-	
-	// Set up stdin
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return fmt.Errorf("failed to get stdin pipe: %w", err)
-	}
-	
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start pbcopy: %w", err)
-	}
-	
-	// Write the text to stdin
-	if _, err := stdin.Write([]byte(text)); err != nil {
-		return fmt.Errorf("failed to write to pbcopy: %w", err)
-	}
-	
-	// Close stdin
-	if err := stdin.Close(); err != nil {
-		return fmt.Errorf("failed to close stdin: %w", err)
-	}
-	
-	// Wait for the command to finish
-	if err := cmd.Wait(); err != nil {
-		return fmt.Errorf("pbcopy command failed: %w", err)
-	}
-	
-	return nil
-}
-
 // handleQuit quits the application
 func (s *CommandService) handleQuit() {
 	s.eventBroker.Publish(events.Event{
@@ -504,7 +531,8 @@ func (s *CommandService) GetAvailableCommands() []struct {
 		{"/help", "Show help message"},
 		{"/clear", "Clear all messages"},
 		{"/copy", "Copy last N messages to clipboard"},
-		{"/analyze", "Run project analysis (quick/detailed/deep/full)"},
+		{"/export", "Save the conversation as Markdown or HTML"},
+		{"/analyze", "Run project analysis (quick/detailed/deep/full, --dry-run to estimate)"},
 		{"/model", "Show current model"},
 		{"/model select", "Select a different model"},
 		{"/team", "Show current team"},
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/redact"
+)
+
+// ExportDebugBundle zips what a bug report needs: version info, the
+// effective config, the models in use, the newest debug dump of each
+// analysis tier and the newest crash bundle. Secrets are redacted from every
+// file. With no path the archive goes to .loco/exports; the path written is
+// returned.
+func (a *App) ExportDebugBundle(path string) (string, error) {
+	now := time.Now()
+	if path == "" {
+		path = filepath.Join(".loco", "exports", "debug-"+now.Format("20060102-150405")+".zip")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(a.workingDir, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	zw := zip.NewWriter(f)
+	bundle := &debugBundle{zip: zw, added: now}
+
+	bundle.add("version.txt", a.versionReport(now))
+	if cfg := a.Config.Get(); cfg != nil {
+		data, _ := json.MarshalIndent(bundleConfig(*cfg), "", "  ")
+		bundle.add("config.json", string(data))
+	}
+	data, _ := json.MarshalIndent(a.modelReport(), "", "  ")
+	bundle.add("models.json", string(data))
+
+	locoDir := filepath.Join(a.workingDir, ".loco")
+	for _, dir := range analysis.LatestDebugDumps(a.workingDir) {
+		bundle.addDir(filepath.Join("debug", filepath.Base(filepath.Dir(dir)), filepath.Base(dir)), dir)
+	}
+	if crashDir := latestDir(filepath.Join(locoDir, "crash")); crashDir != "" {
+		bundle.addDir(filepath.Join("crash", filepath.Base(crashDir)), crashDir)
+	}
+
+	if err := bundle.err; err != nil {
+		zw.Close()
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// debugBundle writes redacted files to a debug bundle, keeping the first
+// error
+type debugBundle struct {
+	zip   *zip.Writer
+	added time.Time
+	err   error
+}
+
+// add writes one file with its secrets redacted
+func (b *debugBundle) add(name, content string) {
+	if b.err != nil {
+		return
+	}
+	redacted, _ := redact.Scan(content)
+	w, err := b.zip.CreateHeader(&zip.FileHeader{
+		Name:     filepath.ToSlash(name),
+		Method:   zip.Deflate,
+		Modified: b.added,
+	})
+	if err != nil {
+		b.err = err
+		return
+	}
+	_, b.err = w.Write([]byte(redacted))
+}
+
+// addDir writes the files under dir beneath name
+func (b *debugBundle) addDir(name, dir string) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		b.add(filepath.Join(name, rel), string(data))
+		return nil
+	})
+}
+
+// versionReport describes the build, the platform and the model server
+func (a *App) versionReport(now time.Time) string {
+	var sb strings.Builder
+	version := "(unknown)"
+	var settings []debug.BuildSetting
+	if info, ok := debug.ReadBuildInfo(); ok {
+		version = info.Main.Version
+		settings = info.Settings
+	}
+	fmt.Fprintf(&sb, "Version:  %s\n", version)
+	for _, setting := range settings {
+		if strings.HasPrefix(setting.Key, "vcs.") {
+			fmt.Fprintf(&sb, "%-9s %s\n", strings.TrimPrefix(setting.Key, "vcs.")+":", setting.Value)
+		}
+	}
+	fmt.Fprintf(&sb, "Go:       %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if cfg := a.Config.Get(); cfg != nil {
+		fmt.Fprintf(&sb, "Provider: %s (%s)\n", cfg.Provider, cfg.LMStudioURL)
+	}
+	fmt.Fprintf(&sb, "Exported: %s\n", now.Format(time.RFC3339))
+	return sb.String()
+}
+
+// bundleModels lists the models loco is using and what each can do
+type bundleModels struct {
+	Chat   string            `json:"chat,omitempty"`
+	Team   map[string]string `json:"team,omitempty"`
+	Probes []llm.ModelProbe  `json:"probes"`
+}
+
+// modelReport returns the chat model, the team and the probed models
+func (a *App) modelReport() bundleModels {
+	report := bundleModels{Probes: a.ModelProbes}
+	if a.LLMService != nil {
+		report.Chat = a.LLMService.ChatModel()
+	}
+	if a.TeamClients != nil {
+		report.Team = make(map[string]string)
+		for size, client := range map[string]llm.Client{"small": a.TeamClients.Small, "medium": a.TeamClients.Medium, "large": a.TeamClients.Large} {
+			if named, ok := client.(interface{ CurrentModel() string }); ok {
+				report.Team[size] = named.CurrentModel()
+			}
+		}
+	}
+	return report
+}
+
+// bundleConfig returns cfg with the settings that hold credentials blanked
+func bundleConfig(cfg config.Config) config.Config {
+	if cfg.Analysis.RAG.EmbedderAPIKey != "" {
+		cfg.Analysis.RAG.EmbedderAPIKey = "[REDACTED]"
+	}
+	if len(cfg.MCPServers) > 0 {
+		servers := make(map[string]config.MCPServerConfig, len(cfg.MCPServers))
+		for name, server := range cfg.MCPServers {
+			env := make(map[string]string, len(server.Env))
+			for key := range server.Env {
+				env[key] = "[REDACTED]"
+			}
+			server.Env = env
+			servers[name] = server
+		}
+		cfg.MCPServers = servers
+	}
+	return cfg
+}
+
+// latestDir returns the newest directory in dir. Crash bundles are named by
+// time, and ReadDir sorts by name.
+func latestDir(dir string) string {
+	entries, _ := os.ReadDir(dir)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].IsDir() {
+			return filepath.Join(dir, entries[i].Name())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/crash"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/llm/queue"
+	"github.com/billie-coop/loco/internal/session"
+)
+
+const (
+	// decisionStateFile records how many turns of each session have been
+	// scanned for decisions, under .loco
+	decisionStateFile = "decisions_state.json"
+
+	// maxDecisionTurns caps the turns sent to the model in one request
+	maxDecisionTurns = 12
+)
+
+// DecisionLog scans settled conversation turns for decisions and appends
+// them, with their rationale and session, to .loco/knowledge/decisions.md.
+// Each turn is scanned once.
+type DecisionLog struct {
+	sessions    *session.Manager
+	projectPath string
+
+	mu     sync.Mutex // One scan at a time; guards client and queue
+	client llm.Client
+	queue  *queue.Manager // nil calls the client directly
+}
+
+// NewDecisionLog creates the decision log for the project's sessions
+func NewDecisionLog(sessions *session.Manager, projectPath string) *DecisionLog {
+	return &DecisionLog{sessions: sessions, projectPath: projectPath}
+}
+
+// SetClient sets the model that reads conversations, and the queue its
+// requests wait in
+func (d *DecisionLog) SetClient(client llm.Client, q *queue.Manager) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.client = client
+	d.queue = q
+}
+
+// Start scans every interval until ctx is done. A failed scan, such as
+// with no model loaded, is retried on the next tick.
+func (d *DecisionLog) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		defer crash.Recover("decision log")
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, _ = d.Scan(ctx)
+			}
+		}
+	}()
+}
+
+// Scan extracts the decisions of the turns not scanned yet, oldest session
+// first so the log stays in order, and returns how many it logged
+func (d *DecisionLog) Scan(ctx context.Context) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.client == nil {
+		return 0, fmt.Errorf("no model connected")
+	}
+
+	state := d.loadState()
+	sessions := d.sessions.ListSessions()
+	slices.Reverse(sessions)
+
+	logged := 0
+	live := make(map[string]bool)
+	for _, s := range sessions {
+		live[s.ID] = true
+		turns := chatTurns(s.Messages.ToSlice(), time.Since(s.LastUpdated) >= turnSettleTime)
+		done := state[s.ID]
+		if len(turns) < done {
+			done = 0 // Cleared since
+		}
+
+		for done < len(turns) {
+			batch := turns[done:min(done+maxDecisionTurns, len(turns))]
+			exchanges := make([]analysis.Exchange, len(batch))
+			for i, turn := range batch {
+				exchanges[i] = analysis.Exchange{Time: turn[0].Time, Text: turnText(s.Title, turn)}
+			}
+
+			decisions, err := d.extract(ctx, s.ID, exchanges)
+			if err != nil {
+				_ = d.saveState(state)
+				return logged, err
+			}
+			if err := analysis.AppendDecisions(d.projectPath, decisions); err != nil {
+				_ = d.saveState(state)
+				return logged, err
+			}
+			logged += len(decisions)
+			done += len(batch)
+			state[s.ID] = done
+		}
+	}
+
+	for id := range state {
+		if !live[id] {
+			delete(state, id)
+		}
+	}
+	return logged, d.saveState(state)
+}
+
+// extract runs the extraction request on the queue as background work
+func (d *DecisionLog) extract(ctx context.Context, sessionID string, exchanges []analysis.Exchange) ([]analysis.Decision, error) {
+	if d.queue == nil {
+		return analysis.ExtractDecisions(ctx, d.client, sessionID, exchanges)
+	}
+
+	var decisions []analysis.Decision
+	var err error
+	done := make(chan struct{})
+	d.queue.Submit(ctx, func(ctx context.Context) error {
+		defer close(done)
+		decisions, err = analysis.ExtractDecisions(ctx, d.client, sessionID, exchanges)
+		return err
+	}, queue.WithPriority(1), queue.WithType("decisions"))
+
+	select {
+	case <-done:
+		return decisions, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (d *DecisionLog) statePath() string {
+	return filepath.Join(d.projectPath, ".loco", decisionStateFile)
+}
+
+// loadState returns the turns scanned per session ID
+func (d *DecisionLog) loadState() map[string]int {
+	state := make(map[string]int)
+	if data, err := os.ReadFile(d.statePath()); err == nil {
+		_ = json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+func (d *DecisionLog) saveState(state map[string]int) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(d.statePath(), data, 0o644)
+}
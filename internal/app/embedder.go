@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/redact"
+	"github.com/billie-coop/loco/internal/sidecar"
+	"github.com/billie-coop/loco/internal/sidecar/embedder"
+)
+
+// newEmbedder creates the embedder selected by analysis.rag.embedder,
+// defaulting to LM Studio, with secrets redacted from what it embeds
+func newEmbedder(rag config.RAGConfig, lmStudioURL string, redactor *redact.Redactor) sidecar.Embedder {
+	inner := selectEmbedder(rag, lmStudioURL)
+	if redactor == nil {
+		return inner
+	}
+	return &redactingEmbedder{Embedder: inner, redactor: redactor}
+}
+
+// selectEmbedder creates the embedder analysis.rag.embedder names
+func selectEmbedder(rag config.RAGConfig, lmStudioURL string) sidecar.Embedder {
+	model := rag.ResolvedEmbeddingModel()
+
+	switch rag.Embedder {
+	case "ollama":
+		ollamaEmbedder := embedder.NewOllamaEmbedder(rag.EmbedderURL)
+		ollamaEmbedder.SetModel(model)
+		return ollamaEmbedder
+	case "openai":
+		openAIEmbedder := embedder.NewOpenAIEmbedder(rag.EmbedderURL, rag.EmbedderAPIKey)
+		openAIEmbedder.SetModel(model)
+		return openAIEmbedder
+	case "mock":
+		// Mock for testing only
+		return embedder.NewMockEmbedder(384)
+	default:
+		lmEmbedder := embedder.NewLMStudioEmbedder(lmStudioURL)
+		lmEmbedder.SetModel(model)
+		return lmEmbedder
+	}
+}
+
+// redactingEmbedder scrubs secrets from texts before they are embedded, so
+// indexed files don't send them to the embedding backend
+type redactingEmbedder struct {
+	sidecar.Embedder
+	redactor *redact.Redactor
+}
+
+// Embed embeds the redacted text
+func (e *redactingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	return e.Embedder.Embed(ctx, e.redactor.Redact("embedding request", text))
+}
+
+// EmbedBatch embeds the redacted texts
+func (e *redactingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	redacted := make([]string, len(texts))
+	for i, text := range texts {
+		redacted[i] = e.redactor.Redact("embedding request", text)
+	}
+	return e.Embedder.EmbedBatch(ctx, redacted)
+}
+
+// DetectDimension asks the wrapped embedder when it can tell, and embeds a
+// probe text otherwise
+func (e *redactingEmbedder) DetectDimension(ctx context.Context) (int, error) {
+	if detector, ok := e.Embedder.(sidecar.DimensionDetector); ok {
+		return detector.DetectDimension(ctx)
+	}
+	embedding, err := e.Embedder.Embed(ctx, "test")
+	if err != nil {
+		return 0, err
+	}
+	return len(embedding), nil
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/mention"
+)
+
+// unsafeNameChars are replaced in the names of copied attachments so they
+// can be @-mentioned
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// imageMentions returns the @-mentioned paths in text that are image files
+// inside the project
+func imageMentions(workingDir, text string) []string {
+	var images []string
+	for _, path := range mention.Paths(text) {
+		local := filepath.FromSlash(path)
+		if !llm.IsImage(path) || !filepath.IsLocal(local) {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(workingDir, local)); err == nil && info.Mode().IsRegular() {
+			images = append(images, path)
+		}
+	}
+	return images
+}
+
+// SetModelProbes tells the service which loaded models can read images
+func (s *LLMService) SetModelProbes(probes []llm.ModelProbe) {
+	s.probes = probes
+}
+
+// chatModelSeesImages reports whether the chat model reads images. A model
+// that wasn't probed gets them and LM Studio decides.
+func (s *LLMService) chatModelSeesImages() bool {
+	model := s.ChatModel()
+	for _, probe := range s.probes {
+		if probe.ID == model {
+			return probe.Vision
+		}
+	}
+	return true
+}
+
+// withImages prepares attached images for the chat model: their paths
+// resolved in the project for a vision model, or replaced by a note naming
+// them for a text-only one
+func (s *LLMService) withImages(messages []llm.Message) []llm.Message {
+	sees := s.chatModelSeesImages()
+	prepared := make([]llm.Message, len(messages))
+	for i, msg := range messages {
+		if len(msg.Images) > 0 {
+			images := msg.Images
+			msg.Images = nil
+			if sees {
+				for _, path := range images {
+					if !filepath.IsAbs(path) {
+						path = filepath.Join(s.workingDir, filepath.FromSlash(path))
+					}
+					msg.Images = append(msg.Images, path)
+				}
+			} else {
+				msg.Content += fmt.Sprintf("\n\n[Attached images not shown because this model can't read images: %s]", strings.Join(images, ", "))
+			}
+		}
+		prepared[i] = msg
+	}
+	return prepared
+}
+
+// AttachImage makes an image file pasted or dropped into the terminal
+// mentionable, returning the project-relative path to @-mention. Images
+// outside the project, or with names a mention can't hold, are copied into
+// .loco/attachments.
+func (a *App) AttachImage(path string) (string, error) {
+	path = pastedPath(path)
+	if !llm.IsImage(path) {
+		return "", fmt.Errorf("%s is not a PNG, JPEG, GIF or WebP image", filepath.Base(path))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a file", path)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(a.workingDir, abs); err == nil && filepath.IsLocal(rel) && !strings.ContainsAny(rel, " \t\n@") {
+		return filepath.ToSlash(rel), nil
+	}
+
+	dir := filepath.Join(a.workingDir, ".loco", "attachments")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+	name := time.Now().Format("20060102-150405") + "-" + unsafeNameChars.ReplaceAllString(filepath.Base(abs), "-")
+	if err := copyFile(abs, filepath.Join(dir, name)); err != nil {
+		return "", fmt.Errorf("failed to attach %s: %w", filepath.Base(abs), err)
+	}
+	return ".loco/attachments/" + name, nil
+}
+
+// pastedPath undoes how terminals paste a dropped file's path: quoted,
+// with escaped spaces or as a file:// URL
+func pastedPath(text string) string {
+	path := strings.TrimSpace(text)
+	if len(path) >= 2 && (path[0] == '\'' || path[0] == '"') && path[len(path)-1] == path[0] {
+		path = path[1 : len(path)-1]
+	} else {
+		path = strings.ReplaceAll(path, `\ `, " ")
+	}
+	if u, err := url.Parse(path); err == nil && u.Scheme == "file" {
+		path = u.Path
+	}
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(path, "~/") {
+		path = filepath.Join(home, path[2:])
+	}
+	return path
+}
+
+// copyFile copies the file at src to dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
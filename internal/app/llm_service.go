@@ -6,20 +6,53 @@ import (
 	"strings"
 	"time"
 
+	"github.com/billie-coop/loco/internal/crash"
 	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/orchestrator"
+	"github.com/billie-coop/loco/internal/parser"
+	"github.com/billie-coop/loco/internal/sidecar"
 	"github.com/billie-coop/loco/internal/tui/events"
 )
 
+// maxToolFeedbackRounds caps how often invalid tool params are sent back to
+// the model for correction within a single user turn.
+const maxToolFeedbackRounds = 2
+
 // LLMService handles all LLM-related business logic
 type LLMService struct {
 	client      llm.Client
 	eventBroker *events.Broker
+	parser      *parser.Parser
+
+	// Retrieval of project code into chat prompts
+	rag        sidecar.Service
+	ragTopK    int
+	workingDir string
+	prompt     *PromptAssembler // Builds each turn's system prompt
+
+	// Recall of earlier sessions' turns into chat prompts
+	memory     *ChatMemory
+	memoryTopK int
+
+	probes []llm.ModelProbe // Loaded models, to tell which read images
+
+	// Routing of each message to a team model by its kind
+	router *orchestrator.Router
+	pinned func() bool // Reports whether the user picked the chat model
+
+	// Review of answers by a second model before they are shown
+	verifier *orchestrator.Verifier
 
 	// Current state
 	isStreaming     bool
 	streamingMsg    string
 	streamingTokens int
 	streamingStart  time.Time
+	contextChunks   []llm.ContextChunk  // Chunks retrieved for the current turn
+	turn            orchestrator.Route  // How the current turn is answered
+	question        string              // The user message of the current turn
+	review          orchestrator.Review // What the reviewer found in the current answer
+	citations       []llm.Citation      // The files the current answer refers to
 
 	// Debug mode
 	debugMode bool
@@ -37,31 +70,120 @@ func (s *LLMService) SetClient(client llm.Client) {
 	s.client = client
 }
 
-// HandleUserMessage processes a user message and streams the response
-func (s *LLMService) HandleUserMessage(messages []llm.Message, userMessage string) {
-	// Check if we have a client before using debug mode
-	if s.client == nil {
-		s.handleDebugEcho(userMessage)
-		return
+// ChatModel returns the model answering chat messages, or "" when the
+// client doesn't say
+func (s *LLMService) ChatModel() string {
+	if client, ok := s.client.(interface{ CurrentModel() string }); ok {
+		return client.CurrentModel()
+	}
+	return ""
+}
+
+// SetChatModel switches the model answering chat messages. It reports
+// false when the client can't switch models.
+func (s *LLMService) SetChatModel(modelID string) bool {
+	client, ok := s.client.(interface{ SetModel(string) })
+	if !ok {
+		return false
+	}
+	client.SetModel(modelID)
+	return true
+}
+
+// SetParser sets the parser used to validate tool calls in responses
+func (s *LLMService) SetParser(p *parser.Parser) {
+	s.parser = p
+}
+
+// SetRAG enables retrieving the topK most relevant chunks from the sidecar
+// into each chat prompt. A topK of zero or less disables retrieval.
+func (s *LLMService) SetRAG(rag sidecar.Service, topK int, workingDir string) {
+	s.rag = rag
+	s.ragTopK = topK
+	s.workingDir = workingDir
+}
+
+// SetChatMemory enables recalling the topK turns of earlier sessions most
+// relevant to each chat message. A topK of zero or less disables recall.
+func (s *LLMService) SetChatMemory(memory *ChatMemory, topK int) {
+	s.memory = memory
+	s.memoryTopK = topK
+}
+
+// SetRouter routes each message to the team model, prompt and temperature
+// configured for its kind. While pinned reports true, the model the user
+// picked answers every message; so does the chat model for messages with
+// images, since it is the one checked for vision.
+func (s *LLMService) SetRouter(router *orchestrator.Router, pinned func() bool) {
+	s.router = router
+	s.pinned = pinned
+}
+
+// route returns how userMessage is answered
+func (s *LLMService) route(userMessage string) orchestrator.Route {
+	if s.router == nil {
+		return orchestrator.Route{Kind: orchestrator.KindChat, Temperature: llm.DefaultCompleteOptions().Temperature}
 	}
+	route, _ := s.router.Route(userMessage)
+	if (s.pinned != nil && s.pinned()) || len(imageMentions(s.workingDir, userMessage)) > 0 {
+		route.Client, route.Model = nil, ""
+	}
+	return route
+}
+
+// turnClient returns the client answering the current turn
+func (s *LLMService) turnClient() llm.Client {
+	if s.turn.Client != nil {
+		return s.turn.Client
+	}
+	return s.client
+}
+
+// SetVerifier sets the reviewer of answers before they are shown
+func (s *LLMService) SetVerifier(verifier *orchestrator.Verifier) {
+	s.verifier = verifier
+}
+
+// SetPromptAssembler sets how the system prompt is built from knowledge and
+// retrieved code
+func (s *LLMService) SetPromptAssembler(assembler *PromptAssembler) {
+	s.prompt = assembler
+}
 
-	// Add user message to history
+// HandleUserMessage processes a user message and streams the response
+func (s *LLMService) HandleUserMessage(messages []llm.Message, userMessage string) {
+	// Add user message to history, with the images it mentions attached
+	images := imageMentions(s.workingDir, userMessage)
 	messages = append(messages, llm.Message{
 		Role:    "user",
 		Content: userMessage,
+		Images:  images,
+		Time:    time.Now(),
 	})
 
 	// Publish user message event
 	s.eventBroker.Publish(events.Event{
 		Type: events.UserMessageEvent,
 		Payload: events.MessagePayload{
-			Message: llm.Message{
-				Role:    "user",
-				Content: userMessage,
-			},
+			Message: messages[len(messages)-1],
 		},
 	})
 
+	// Check if we have a client before using debug mode
+	if s.client == nil {
+		s.handleDebugEcho(userMessage)
+		return
+	}
+	if len(images) > 0 && !s.chatModelSeesImages() {
+		s.eventBroker.PublishAsync(events.Event{
+			Type: events.StatusMessageEvent,
+			Payload: events.StatusMessagePayload{
+				Message: s.ChatModel() + " can't read images; sending their names only",
+				Type:    "warning",
+			},
+		})
+	}
+
 	// Start streaming
 	s.eventBroker.Publish(events.Event{
 		Type: events.StreamStartEvent,
@@ -72,13 +194,54 @@ func (s *LLMService) HandleUserMessage(messages []llm.Message, userMessage strin
 	s.streamingMsg = ""
 	s.streamingTokens = 0
 	s.streamingStart = time.Now()
+	s.contextChunks = nil
+	s.turn = s.route(userMessage)
+	s.question = userMessage
+
+	// Retrieve relevant code, build the system prompt, then stream from LLM
+	go func() {
+		defer crash.Recover("chat response")
+		messages, s.contextChunks = s.withSystemPrompt(messages, userMessage, s.turn.SystemPrompt, s.retrieveContext(userMessage), s.recallTurns(userMessage))
+		s.streamResponse(s.withImages(messages), 0)
+	}()
+}
+
+// Complete answers userMessage after messages without streaming or
+// publishing events, for callers outside the TUI. The system prompt is built
+// the same way as for chat, and the chunks used are returned with the reply.
+func (s *LLMService) Complete(ctx context.Context, messages []llm.Message, userMessage string) (string, []llm.ContextChunk, error) {
+	if s.client == nil {
+		return "", nil, fmt.Errorf("no LLM client configured")
+	}
 
-	// Stream from LLM
-	go s.streamResponse(messages)
+	messages = append(messages, llm.Message{Role: "user", Content: userMessage, Images: imageMentions(s.workingDir, userMessage)})
+
+	route := s.route(userMessage)
+	messages, chunks := s.withSystemPrompt(messages, userMessage, route.SystemPrompt, s.retrieveContext(userMessage), s.recallTurns(userMessage))
+
+	client := route.Client
+	if client == nil {
+		client = s.client
+	}
+	var reply string
+	var err error
+	if withOptions, ok := client.(interface {
+		CompleteWithOptions(context.Context, []llm.Message, llm.CompleteOptions) (string, error)
+	}); ok {
+		opts := llm.DefaultCompleteOptions()
+		opts.Temperature = route.Temperature
+		reply, err = withOptions.CompleteWithOptions(ctx, s.withImages(messages), opts)
+	} else {
+		reply, err = client.Complete(ctx, s.withImages(messages))
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return reply, chunks, nil
 }
 
 // streamResponse handles the actual streaming from LLM
-func (s *LLMService) streamResponse(messages []llm.Message) {
+func (s *LLMService) streamResponse(messages []llm.Message, feedbackRound int) {
 	ctx := context.Background()
 
 	if s.client == nil {
@@ -93,7 +256,13 @@ func (s *LLMService) streamResponse(messages []llm.Message) {
 		return
 	}
 
-	err := s.client.Stream(ctx, messages, func(chunk string) {
+	s.review = orchestrator.Review{}
+	s.citations = nil
+
+	// Watch for tool calls while the response is still arriving
+	toolDetector := parser.NewStreamParser()
+
+	onChunk := func(chunk string) {
 		s.streamingMsg += chunk
 		s.streamingTokens += len(strings.Fields(chunk))
 
@@ -105,7 +274,31 @@ func (s *LLMService) streamResponse(messages []llm.Message) {
 				TokenCount: len(strings.Fields(chunk)),
 			},
 		})
-	})
+
+		for _, detected := range toolDetector.Feed(chunk) {
+			s.eventBroker.Publish(events.Event{
+				Type: events.ToolCallDetectedEvent,
+				Payload: events.ToolDetectedPayload{
+					ToolName: detected.Call.Name,
+					Params:   detected.Call.Params,
+					Summary:  detected.Call.Summary(),
+					Complete: detected.Complete,
+				},
+			})
+		}
+	}
+
+	var err error
+	client := s.turnClient()
+	if withOptions, ok := client.(interface {
+		StreamWithOptions(context.Context, []llm.Message, llm.CompleteOptions, func(string)) error
+	}); ok {
+		opts := llm.DefaultCompleteOptions()
+		opts.Temperature = s.turn.Temperature
+		err = withOptions.StreamWithOptions(ctx, messages, opts, onChunk)
+	} else {
+		err = client.Stream(ctx, messages, onChunk)
+	}
 
 	if err != nil {
 		s.eventBroker.Publish(events.Event{
@@ -117,8 +310,115 @@ func (s *LLMService) streamResponse(messages []llm.Message) {
 		})
 	}
 
+	if err == nil && s.verifier != nil && s.streamingMsg != "" {
+		// Check the files the answer names, correcting them if configured
+		s.citations, s.streamingMsg = s.verifier.Citations(s.streamingMsg)
+	}
+	response := s.streamingMsg
+	if err == nil {
+		s.verify(ctx, messages, response)
+	}
+
 	// End streaming and convert to message
 	s.endStreaming()
+
+	if err == nil && feedbackRound < maxToolFeedbackRounds {
+		s.sendToolFeedback(messages, response, feedbackRound)
+	}
+}
+
+// verify has the verifier review the draft answer before it is shown, when
+// it is configured to review answers like this one. A failed review is
+// reported and the answer shown as it is.
+func (s *LLMService) verify(ctx context.Context, messages []llm.Message, draft string) {
+	if s.verifier == nil || strings.TrimSpace(draft) == "" || !s.verifier.Wants(s.usedTools(messages, draft)) {
+		return
+	}
+	s.eventBroker.Publish(events.Event{
+		Type: events.StatusMessageEvent,
+		Payload: events.StatusMessagePayload{
+			Message: "Reviewing the answer...",
+			Type:    "info",
+		},
+	})
+	review, err := s.verifier.Review(ctx, s.question, draft)
+	if err != nil {
+		s.eventBroker.Publish(events.Event{
+			Type: events.StatusMessageEvent,
+			Payload: events.StatusMessagePayload{
+				Message: err.Error(),
+				Type:    "warning",
+			},
+		})
+		return
+	}
+	s.review = review
+}
+
+// usedTools reports whether an answer calls tools or follows tool results
+// the user hasn't replied to yet
+func (s *LLMService) usedTools(messages []llm.Message, draft string) bool {
+	if s.parser != nil {
+		if result, err := s.parser.Parse(draft); err == nil && len(result.ToolCalls) > 0 {
+			return true
+		}
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		switch messages[i].Role {
+		case "tool":
+			return true
+		case "assistant":
+			return false
+		}
+	}
+	return false
+}
+
+// sendToolFeedback feeds schema validation errors back to the model so it
+// can correct its tool calls without the user having to intervene.
+func (s *LLMService) sendToolFeedback(messages []llm.Message, response string, feedbackRound int) {
+	if s.parser == nil || response == "" {
+		return
+	}
+	result, err := s.parser.Parse(response)
+	if err != nil {
+		return
+	}
+	feedback := result.Feedback()
+	if feedback == "" {
+		return
+	}
+
+	feedbackMsg := llm.Message{
+		Role:    "user",
+		Content: feedback,
+	}
+
+	// Show the correction request so the exchange is visible
+	s.eventBroker.Publish(events.Event{
+		Type: events.SystemMessageEvent,
+		Payload: events.MessagePayload{
+			Message: llm.Message{
+				Role:    "system",
+				Content: "🔁 " + feedback,
+			},
+		},
+	})
+
+	messages = append(messages,
+		llm.Message{Role: "assistant", Content: response},
+		feedbackMsg,
+	)
+
+	s.eventBroker.Publish(events.Event{
+		Type: events.StreamStartEvent,
+	})
+	s.isStreaming = true
+	s.streamingMsg = ""
+	s.streamingTokens = 0
+	s.streamingStart = time.Now()
+
+	s.streamResponse(messages, feedbackRound+1)
 }
 
 // endStreaming finalizes the streaming process
@@ -129,8 +429,9 @@ func (s *LLMService) endStreaming() {
 			Type: events.AssistantMessageEvent,
 			Payload: events.MessagePayload{
 				Message: llm.Message{
-					Role:    "assistant",
-					Content: s.streamingMsg,
+					Role:     "assistant",
+					Content:  s.streamingMsg,
+					Metadata: s.turnMetadata(),
 				},
 			},
 		})
@@ -147,6 +448,27 @@ func (s *LLMService) endStreaming() {
 	})
 }
 
+// turnMetadata describes what went into the current turn's response
+func (s *LLMService) turnMetadata() *llm.MessageMetadata {
+	model := s.turn.Model
+	if model == "" {
+		model = s.ChatModel()
+	}
+	route := ""
+	if s.turn.Kind != "" && s.turn.Kind != orchestrator.KindChat {
+		route = string(s.turn.Kind)
+	}
+	if len(s.contextChunks) == 0 && model == "" && route == "" && len(s.review.Issues) == 0 && len(s.citations) == 0 {
+		return nil
+	}
+	meta := &llm.MessageMetadata{ContextChunks: s.contextChunks, Model: model, Route: route, Citations: s.citations}
+	if len(s.review.Issues) > 0 {
+		meta.Review = s.review.Issues
+		meta.Reviewer = s.review.Model
+	}
+	return meta
+}
+
 // IsStreaming returns whether the service is currently streaming
 func (s *LLMService) IsStreaming() bool {
 	return s.isStreaming
@@ -159,6 +481,7 @@ func (s *LLMService) handleDebugEcho(userMessage string) {
 
 	// Simulate streaming by sending it in chunks
 	go func() {
+		defer crash.Recover("chat response")
 		// Start streaming
 		s.eventBroker.Publish(events.Event{
 			Type: events.StreamStartEvent,
@@ -0,0 +1,278 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/memory"
+	"github.com/billie-coop/loco/internal/sidecar"
+	"github.com/billie-coop/loco/internal/tasks"
+)
+
+const (
+	// charsPerToken approximates how many characters a token holds, close
+	// enough for budgeting English and code
+	charsPerToken = 4
+
+	// defaultPromptBudget is the budget in tokens when none is configured:
+	// a quarter of the default 8192 context
+	defaultPromptBudget = 2048
+
+	// minOverviewChars is how much of the overview goes in however small
+	// the budget
+	minOverviewChars = 400
+
+	// recallShare limits recalled turns to 1/recallShare of the budget,
+	// leaving the rest to retrieved code
+	recallShare = 3
+)
+
+// PromptAssembler composes the chat system prompt from the project's
+// remembered notes, the session's plan, the knowledge tiers, the files the user mentioned,
+// turns recalled from earlier sessions and retrieved code under a token
+// budget. Notes go first and always whole, since the user asked for them,
+// then the plan, also whole.
+// The project overview always goes in, cut down when it alone is over
+// budget; mentioned files next, whole or their head when they don't fit;
+// the structure doc only when it fits whole; recalled turns up to a third
+// of the budget; retrieved snippets fill what is left.
+type PromptAssembler struct {
+	workingDir string
+	budget     int // Tokens
+	memory     *memory.Store
+	tasks      *tasks.Tracker // nil leaves the plan out
+}
+
+// NewPromptAssembler creates an assembler for the project at workingDir.
+// A budget of zero or less uses defaultPromptBudget.
+func NewPromptAssembler(workingDir string, budget int) *PromptAssembler {
+	if budget <= 0 {
+		budget = defaultPromptBudget
+	}
+	return &PromptAssembler{workingDir: workingDir, budget: budget, memory: memory.NewStore(workingDir)}
+}
+
+// SetTasks sets the tracker whose plan is shown to the model
+func (p *PromptAssembler) SetTasks(tracker *tasks.Tracker) {
+	p.tasks = tracker
+}
+
+// Assemble builds the system prompt for one turn. recalled are turns of
+// earlier sessions from ChatMemory; mentions are the project-relative paths
+// the user @-mentioned. The returned chunks are the retrieved ones that
+// made it in. The prompt is empty when there are no notes, plan or knowledge and
+// nothing was mentioned, recalled or retrieved.
+func (p *PromptAssembler) Assemble(results, recalled []sidecar.SimilarDocument, mentions []string) (string, []llm.ContextChunk) {
+	remaining := p.budget * charsPerToken
+
+	var b strings.Builder
+	if notes, err := p.memory.Notes(); err == nil && len(notes) > 0 {
+		b.WriteString("Notes the user asked you to remember about this project. Follow them.\n\n")
+		for _, note := range notes {
+			b.WriteString("- " + note + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if p.tasks != nil {
+		if plan, err := p.tasks.Current(); err == nil && plan != nil {
+			b.WriteString("The plan for this session. Use the plan tool to mark steps done as you finish them.\n\n")
+			b.WriteString(plan.String() + "\n\n")
+		}
+	}
+
+	if source, overview, ok := analysis.LoadBestKnowledge(p.workingDir, "overview"); ok {
+		b.WriteString("Project knowledge from earlier analysis of this repository. ")
+		b.WriteString("It may be out of date; read files with tools when you need certainty.\n")
+		section := knowledgeSection("Project overview", source, overview)
+		if len(section) > remaining-b.Len() {
+			section = truncateLines(section, max(remaining-b.Len(), minOverviewChars))
+		}
+		b.WriteString(section)
+	}
+
+	if block := p.renderMentionedFiles(mentions, remaining-b.Len()); block != "" {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(block)
+	}
+
+	if source, structure, ok := analysis.LoadBestKnowledge(p.workingDir, "structure"); ok {
+		if section := knowledgeSection("Project structure", source, structure); b.Len()+len(section) <= remaining {
+			b.WriteString(section)
+		}
+	}
+
+	if block := renderRecalled(recalled, min(remaining-b.Len(), remaining/recallShare)); block != "" {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(block)
+	}
+
+	block, used := p.renderContextBlock(results, remaining-b.Len())
+	if block != "" {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(block)
+	}
+	return b.String(), used
+}
+
+// knowledgeSection formats one knowledge doc for the prompt
+func knowledgeSection(title, source, content string) string {
+	return fmt.Sprintf("\n## %s (%s)\n\n%s\n", title, source, content)
+}
+
+// truncateLines cuts text to at most maxChars, at a line break when there
+// is one, and marks the cut
+func truncateLines(text string, maxChars int) string {
+	const marker = "\n... (truncated)\n"
+	if maxChars <= len(marker) {
+		return ""
+	}
+	text = text[:maxChars-len(marker)]
+	if i := strings.LastIndex(text, "\n"); i > 0 {
+		text = text[:i]
+	}
+	return text + marker
+}
+
+// renderMentionedFiles formats the files the user mentioned, stopping before
+// maxChars is reached. The space left is shared evenly among the files still
+// to come, so a file that doesn't fit its share goes in cut to its head.
+// Mentions that are not readable text files in the project are skipped.
+func (p *PromptAssembler) renderMentionedFiles(mentions []string, maxChars int) string {
+	var files []string
+	var contents []string
+	for _, path := range mentions {
+		if content, ok := p.readMentionedFile(path); ok {
+			files = append(files, path)
+			contents = append(contents, content)
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("Files the user mentioned in the next message.\n")
+	written := 0
+	for i, path := range files {
+		header := "\n### " + path + "\n```\n"
+		const footer = "\n```\n"
+		share := (maxChars-b.Len())/(len(files)-i) - len(header) - len(footer)
+		content := contents[i]
+		if len(content) > share {
+			content = truncateLines(content, share)
+			if content == "" {
+				continue
+			}
+		}
+		b.WriteString(header + strings.TrimRight(content, "\n") + footer)
+		written++
+	}
+	if written == 0 {
+		return ""
+	}
+	return b.String()
+}
+
+// readMentionedFile reads a mentioned file if it is a text file inside the
+// project
+func (p *PromptAssembler) readMentionedFile(path string) (string, bool) {
+	local := filepath.FromSlash(path)
+	if !filepath.IsLocal(local) {
+		return "", false
+	}
+	data, err := os.ReadFile(filepath.Join(p.workingDir, local))
+	if err != nil || bytes.IndexByte(data, 0) >= 0 {
+		return "", false
+	}
+	return string(data), true
+}
+
+// renderRecalled formats turns recalled from earlier sessions, stopping
+// before maxChars is reached. Each turn is also cut to
+// maxContextChunkChars.
+func renderRecalled(recalled []sidecar.SimilarDocument, maxChars int) string {
+	if len(recalled) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("Earlier conversations in this project that may bear on the next message. ")
+	b.WriteString("Decisions in them may since have changed.\n")
+	written := 0
+	for _, turn := range recalled {
+		content := turn.Content
+		if len(content) > maxContextChunkChars {
+			content = content[:maxContextChunkChars] + "\n... (truncated)"
+		}
+		entry := "\n---\n" + strings.TrimSpace(content) + "\n"
+		if b.Len()+len(entry) > maxChars {
+			break
+		}
+		b.WriteString(entry)
+		written++
+	}
+	if written == 0 {
+		return ""
+	}
+	return b.String()
+}
+
+// renderContextBlock formats retrieved chunks as a context block for the
+// model, stopping before maxChars is reached. Each chunk is also cut to
+// maxContextChunkChars.
+func (p *PromptAssembler) renderContextBlock(results []sidecar.SimilarDocument, maxChars int) (string, []llm.ContextChunk) {
+	if len(results) == 0 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Relevant code retrieved from this project for the next message. ")
+	b.WriteString("It may be incomplete or out of date; read files with tools when you need certainty.\n")
+
+	var used []llm.ContextChunk
+	for _, result := range results {
+		chunk := contextChunk(result, p.workingDir)
+
+		content := result.Content
+		if len(content) > maxContextChunkChars {
+			content = content[:maxContextChunkChars] + "\n... (truncated)"
+		}
+
+		var entry strings.Builder
+		entry.WriteString("\n### " + chunk.Path)
+		if chunk.StartLine > 0 {
+			entry.WriteString(fmt.Sprintf(":%d-%d", chunk.StartLine, chunk.EndLine))
+		}
+		if chunk.Symbol != "" {
+			entry.WriteString(" (" + chunk.Symbol + ")")
+		}
+		language, _ := result.Metadata["language"].(string)
+		if language == "text" {
+			language = ""
+		}
+		entry.WriteString("\n```" + language + "\n" + content + "\n```\n")
+
+		if b.Len()+entry.Len() > maxChars {
+			break
+		}
+		b.WriteString(entry.String())
+		used = append(used, chunk)
+	}
+
+	if len(used) == 0 {
+		return "", nil
+	}
+	return b.String(), used
+}
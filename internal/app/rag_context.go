@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/mention"
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+const (
+	// ragRetrievalTimeout bounds how long a chat message waits on retrieval,
+	// leaving room for the optional rerank pass
+	ragRetrievalTimeout = 15 * time.Second
+
+	// maxContextChunkChars truncates each retrieved chunk in the prompt
+	maxContextChunkChars = 2000
+)
+
+// retrieveContext fetches the chunks most relevant to userMessage. Retrieval
+// is best effort: an empty index or unavailable embedder yields nothing.
+func (s *LLMService) retrieveContext(userMessage string) []sidecar.SimilarDocument {
+	if s.rag == nil || s.ragTopK <= 0 || strings.TrimSpace(userMessage) == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ragRetrievalTimeout)
+	defer cancel()
+
+	results, err := s.rag.QuerySimilar(ctx, userMessage, s.ragTopK)
+	if err != nil {
+		return nil
+	}
+	return results
+}
+
+// recallTurns fetches the turns of earlier sessions most relevant to
+// userMessage. Like retrieval it is best effort: before the first sync, or
+// without the embedder, nothing is recalled.
+func (s *LLMService) recallTurns(userMessage string) []sidecar.SimilarDocument {
+	if s.memory == nil || s.memoryTopK <= 0 || strings.TrimSpace(userMessage) == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ragRetrievalTimeout)
+	defer cancel()
+
+	recalled, err := s.memory.Recall(ctx, userMessage, s.memoryTopK)
+	if err != nil {
+		return nil
+	}
+	return recalled
+}
+
+// withSystemPrompt puts the route's instructions and the assembled system
+// prompt (knowledge, the files userMessage mentions, recalled turns and the
+// retrieved chunks) in front of the conversation. System messages already in the history are UI
+// notices such as analysis reports and are left out, except for the feedback
+// some carry for the model; the assembled prompt
+// carries what the model should know. The returned chunks are the ones that made it into
+// the prompt.
+func (s *LLMService) withSystemPrompt(messages []llm.Message, userMessage, instructions string, results, recalled []sidecar.SimilarDocument) ([]llm.Message, []llm.ContextChunk) {
+	assembler := s.prompt
+	if assembler == nil {
+		assembler = NewPromptAssembler(s.workingDir, 0)
+	}
+	prompt, used := assembler.Assemble(results, recalled, mention.Paths(userMessage))
+	if instructions != "" {
+		prompt = strings.TrimSpace(instructions + "\n\n" + prompt)
+	}
+
+	// Leave the caller's history untouched
+	withPrompt := make([]llm.Message, 0, len(messages)+1)
+	if prompt != "" {
+		withPrompt = append(withPrompt, llm.Message{Role: "system", Content: prompt})
+	}
+	for _, msg := range messages {
+		switch {
+		case msg.Role != "system":
+			withPrompt = append(withPrompt, msg)
+		case msg.Metadata != nil && msg.Metadata.Feedback != "":
+			// Feedback such as test results is for the model; it goes in as
+			// a user turn, since many chat templates allow only one system
+			// message
+			withPrompt = append(withPrompt, llm.Message{Role: "user", Content: msg.Metadata.Feedback})
+		}
+	}
+	return withPrompt, used
+}
+
+// contextChunk records where a retrieved document came from
+func contextChunk(result sidecar.SimilarDocument, workingDir string) llm.ContextChunk {
+	chunk := llm.ContextChunk{
+		Path:  result.Path,
+		Score: result.Score,
+	}
+	if workingDir != "" {
+		if rel, err := filepath.Rel(workingDir, result.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			chunk.Path = rel
+		}
+	}
+	chunk.StartLine, _ = result.Metadata["start_line"].(int)
+	chunk.EndLine, _ = result.Metadata["end_line"].(int)
+	chunk.Symbol, _ = result.Metadata["symbol"].(string)
+	return chunk
+}
@@ -8,10 +8,12 @@ import (
 	"sync"
 	"time"
 
-	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/crash"
 	"github.com/billie-coop/loco/internal/llm"
 	"github.com/billie-coop/loco/internal/permission"
+	"github.com/billie-coop/loco/internal/process"
 	"github.com/billie-coop/loco/internal/session"
+	"github.com/billie-coop/loco/internal/tasks"
 	"github.com/billie-coop/loco/internal/tools"
 	"github.com/billie-coop/loco/internal/tui/events"
 )
@@ -31,6 +33,12 @@ type ToolExecutor struct {
 
 	// Model team selection (for display in welcome tool)
 	teamClients *llm.TeamClients
+
+	// The session's plan, whose active step tool runs are recorded against
+	tasks *tasks.Tracker
+
+	// Checks the Go build after edits (nil disables)
+	buildCheck *BuildChecker
 }
 
 // NewToolExecutor creates a new tool executor.
@@ -55,6 +63,16 @@ func (e *ToolExecutor) SetTeamClients(tc *llm.TeamClients) {
 	e.teamClients = tc
 }
 
+// SetTasks sets the plan tracker that tool runs are attributed to
+func (e *ToolExecutor) SetTasks(tracker *tasks.Tracker) {
+	e.tasks = tracker
+}
+
+// SetBuildCheck sets the checker that runs after tools change files
+func (e *ToolExecutor) SetBuildCheck(checker *BuildChecker) {
+	e.buildCheck = checker
+}
+
 // IsBusy reports whether a tool is currently running (used for scheduling)
 func (e *ToolExecutor) IsBusy() bool {
 	e.activeMu.Lock()
@@ -198,21 +216,6 @@ func (e *ToolExecutor) executeWithContext(call tools.ToolCall, initiator string)
 		}
 	})
 
-	// If analyze tool, also wrap context with analysis-specific progress callback
-	if call.Name == "analyze" {
-		ctx = analysis.WithProgressCallback(ctx, func(p analysis.Progress) {
-			e.eventBroker.Publish(events.Event{
-				Type: events.AnalysisProgressEvent,
-				Payload: events.AnalysisProgressPayload{
-					Phase:          p.Phase,
-					TotalFiles:     p.TotalFiles,
-					CompletedFiles: p.CompletedFiles,
-					CurrentFile:    p.CurrentFile,
-				},
-			})
-		})
-	}
-
 	// Handle special tools that need async execution
 	if call.Name == "analyze" {
 		// Handle analysis tool specially - run it asynchronously
@@ -226,20 +229,32 @@ func (e *ToolExecutor) executeWithContext(call tools.ToolCall, initiator string)
 		return
 	}
 
-	if call.Name == "startup_scan" {
-		// During system startup, run synchronously to avoid conflicts with RAG indexing
-		// For user-initiated calls, run async
-		if initiator == "system" {
-			// Run synchronously during startup - continue to normal sync execution below
-		} else {
-			// Handle startup scan asynchronously for user calls
-			e.handleStartupScanAsync(call, ctx, initiator)
-			return
-		}
+	if call.Name == tools.StartupScanToolName {
+		// The scan may wait on a permission prompt and on the queue, so it
+		// always runs off the UI loop; the queue keeps it from competing
+		// with RAG indexing for the model
+		e.handleStartupScanAsync(call, ctx, initiator)
+		return
+	}
+
+	if tools.RunsInBackground(tool) {
+		// These tools wait on permission prompts, models, language servers
+		// or slow commands, so keep them off the UI loop
+		go func() {
+			defer crash.Recover("tool " + call.Name)
+			e.runTool(tool, call, ctx)
+		}()
+		return
 	}
 
-	// For synchronous tools, set up cancelable context and track active job
-	ctx, cancel := context.WithCancel(ctx)
+	e.runTool(tool, call, ctx)
+}
+
+// runTool runs a tool with a cancelable context, publishing its tool card
+// updates and handling side effects of special tools.
+func (e *ToolExecutor) runTool(tool tools.BaseTool, call tools.ToolCall, parentCtx context.Context) {
+	// Set up cancelable context and track active job
+	ctx, cancel := context.WithCancel(parentCtx)
 	e.setActiveJob(call.Name, cancel)
 	defer e.clearActiveJob()
 
@@ -260,6 +275,7 @@ func (e *ToolExecutor) executeWithContext(call tools.ToolCall, initiator string)
 
 	// Run the tool synchronously for all other tools
 	result, err := tool.Run(ctx, call)
+	e.recordRun(ctx, call.Name, err == nil && !result.IsError)
 	if err != nil {
 		// Update tool message to show error
 		e.eventBroker.Publish(events.Event{
@@ -293,6 +309,10 @@ func (e *ToolExecutor) executeWithContext(call tools.ToolCall, initiator string)
 		},
 	})
 
+	if e.buildCheck != nil && !result.IsError {
+		e.buildCheck.AfterTool(call.Name)
+	}
+
 	// Handle special tools with side effects
 	switch call.Name {
 	case "clear":
@@ -307,28 +327,45 @@ func (e *ToolExecutor) executeWithContext(call tools.ToolCall, initiator string)
 			Message string `json:"message"`
 		}
 		if err := json.Unmarshal([]byte(call.Input), &params); err == nil && params.Message != "" {
-			// Create user message
-			userMsg := llm.Message{
-				Role:    "user",
-				Content: params.Message,
+			// Send to LLM if available; it publishes the user message
+			if e.llmService != nil {
+				go func() {
+					defer crash.Recover("chat message")
+					var messages []llm.Message
+					if e.sessions != nil {
+						messages, _ = e.sessions.GetMessages()
+					}
+					e.llmService.HandleUserMessage(messages, params.Message)
+				}()
+			} else {
+				e.eventBroker.Publish(events.Event{
+					Type: events.UserMessageEvent,
+					Payload: events.MessagePayload{
+						Message: llm.Message{Role: "user", Content: params.Message},
+					},
+				})
 			}
+		}
 
-			// Publish user message event
+	case tools.AskCodebaseToolName:
+		e.publishCodebaseAnswer(result)
+
+	case tools.ExplainToolName:
+		e.publishExplanation(result)
+
+	case tools.RunTestsToolName:
+		e.publishTestResults(result)
+
+	case tools.AgentToolName:
+		// Sub-agents' answers join the conversation, so the main model
+		// builds on them in the next turn
+		if !result.IsError && result.Content != "" {
 			e.eventBroker.Publish(events.Event{
-				Type: events.UserMessageEvent,
+				Type: events.AssistantMessageEvent,
 				Payload: events.MessagePayload{
-					Message: userMsg,
+					Message: llm.Message{Role: "assistant", Content: result.Content},
 				},
 			})
-
-			// Send to LLM if available
-			if e.llmService != nil && e.sessions != nil {
-				go func() {
-					messages, _ := e.sessions.GetMessages()
-					messages = append(messages, userMsg)
-					e.llmService.HandleUserMessage(messages, params.Message)
-				}()
-			}
 		}
 
 	case "help":
@@ -347,6 +384,165 @@ func (e *ToolExecutor) executeWithContext(call tools.ToolCall, initiator string)
 	}
 }
 
+// publishCodebaseAnswer posts an ask_codebase answer as an assistant
+// message, followed by one collapsible card per cited source
+func (e *ToolExecutor) publishCodebaseAnswer(result tools.ToolResponse) {
+	if result.IsError || result.Metadata == nil {
+		return
+	}
+	var answer tools.AskCodebaseResult
+	data, err := json.Marshal(result.Metadata)
+	if err != nil || json.Unmarshal(data, &answer) != nil || answer.Answer == "" {
+		return
+	}
+
+	chunks := make([]llm.ContextChunk, 0, len(answer.Sources))
+	for _, source := range answer.Sources {
+		chunks = append(chunks, llm.ContextChunk{
+			Path:      source.Path,
+			StartLine: source.StartLine,
+			EndLine:   source.EndLine,
+			Symbol:    source.Symbol,
+			Score:     source.Score,
+		})
+	}
+	e.eventBroker.Publish(events.Event{
+		Type: events.AssistantMessageEvent,
+		Payload: events.MessagePayload{
+			Message: llm.Message{
+				Role:     "assistant",
+				Content:  answer.Answer,
+				Metadata: &llm.MessageMetadata{ContextChunks: chunks},
+			},
+		},
+	})
+
+	for _, source := range answer.Sources {
+		progress := source.Location()
+		if source.Symbol != "" {
+			progress += " · " + source.Symbol
+		}
+		e.eventBroker.Publish(events.Event{
+			Type: events.SystemMessageEvent,
+			Payload: events.MessagePayload{
+				Message: llm.Message{
+					Role:    "tool",
+					Content: source.Content,
+					ToolExecution: &llm.ToolExecution{
+						Name:     tools.CodebaseSourceName,
+						Status:   "complete",
+						Progress: progress,
+					},
+				},
+			},
+		})
+	}
+}
+
+// publishExplanation posts an explain result as an assistant message the
+// chat renders as an explanation card. Its Markdown form is the content,
+// so follow-up questions in the conversation can build on it.
+func (e *ToolExecutor) publishExplanation(result tools.ToolResponse) {
+	if result.IsError || result.Metadata == nil {
+		return
+	}
+	var explanation llm.Explanation
+	data, err := json.Marshal(result.Metadata)
+	if err != nil || json.Unmarshal(data, &explanation) != nil || explanation.Summary == "" {
+		return
+	}
+	e.eventBroker.Publish(events.Event{
+		Type: events.AssistantMessageEvent,
+		Payload: events.MessagePayload{
+			Message: llm.Message{
+				Role:     "assistant",
+				Content:  result.Content,
+				Metadata: &llm.MessageMetadata{Explanation: &explanation},
+			},
+		},
+	})
+}
+
+// publishTestResults shares a run_tests summary with the model, so its next
+// answer can act on the failures. The tool card already shows the summary,
+// so the notice itself is one line.
+func (e *ToolExecutor) publishTestResults(result tools.ToolResponse) {
+	var run struct {
+		Command  string              `json:"command"`
+		Passed   bool                `json:"passed"`
+		Failures []tools.TestFailure `json:"failures"`
+	}
+	data, err := json.Marshal(result.Metadata)
+	if result.Metadata == nil || err != nil || json.Unmarshal(data, &run) != nil || run.Command == "" {
+		return
+	}
+	notice := "🧪 Tests passed"
+	if !run.Passed {
+		notice = fmt.Sprintf("🧪 Tests failed (%d failures); the summary was shared with the model", len(run.Failures))
+	}
+	e.eventBroker.Publish(events.Event{
+		Type: events.SystemMessageEvent,
+		Payload: events.MessagePayload{
+			Message: llm.Message{
+				Role:     "system",
+				Content:  notice,
+				Metadata: &llm.MessageMetadata{Feedback: "Test results:\n" + result.Content},
+			},
+		},
+	})
+}
+
+// publishProcessCard shows a background process's latest output in its
+// own tool card, which stays running until the process ends
+func publishProcessCard(broker *events.Broker, info process.Info, tail []string) {
+	status := "running"
+	switch {
+	case info.Status == process.StatusRunning:
+	case info.Status == process.StatusStopped || info.ExitCode == 0:
+		status = "complete"
+	default:
+		status = "error"
+	}
+	progress := info.Command
+	if info.Status != process.StatusRunning {
+		progress = fmt.Sprintf("%s · %s", info.Command, info.Status)
+		if info.Status == process.StatusExited {
+			progress += fmt.Sprintf(" (code %d)", info.ExitCode)
+		}
+	}
+	content := strings.Join(tail, "\n")
+	if content == "" {
+		content = "(no output yet)"
+	}
+	broker.Publish(events.Event{
+		Type: events.SystemMessageEvent,
+		Payload: events.MessagePayload{
+			Message: llm.Message{
+				Role:    "tool",
+				Content: content,
+				ToolExecution: &llm.ToolExecution{
+					Name:     tools.ProcessCardPrefix + info.ID,
+					Status:   status,
+					Progress: progress,
+				},
+			},
+		},
+	})
+}
+
+// recordRun attributes a tool run to the plan's active step. Runs the
+// system or file watcher started aren't part of the plan's work, and
+// neither are chat messages or changes to the plan itself.
+func (e *ToolExecutor) recordRun(ctx context.Context, name string, ok bool) {
+	if e.tasks == nil || name == tools.PlanToolName || name == "chat" {
+		return
+	}
+	if initiator, _ := ctx.Value(tools.InitiatorKey).(string); initiator == "system" || initiator == "file-watch" {
+		return
+	}
+	e.tasks.Record(name, ok)
+}
+
 func (e *ToolExecutor) setActiveJob(name string, cancel context.CancelFunc) {
 	e.activeMu.Lock()
 	defer e.activeMu.Unlock()
@@ -368,6 +564,7 @@ func (e *ToolExecutor) handleAnalyzeAsync(call tools.ToolCall, parentCtx context
 	e.setActiveJob("analyze", cancel)
 
 	go func() {
+		defer crash.Recover("analysis")
 		defer e.clearActiveJob()
 		// Small delay to ensure dialog has closed and UI is ready
 		time.Sleep(100 * time.Millisecond)
@@ -457,6 +654,7 @@ func (e *ToolExecutor) handleRagIndexAsync(call tools.ToolCall, parentCtx contex
 	e.setActiveJob("rag_index", cancel)
 
 	go func() {
+		defer crash.Recover("RAG indexing")
 		defer e.clearActiveJob()
 		// Small delay to ensure UI is ready
 		time.Sleep(100 * time.Millisecond)
@@ -552,6 +750,7 @@ func (e *ToolExecutor) handleStartupScanAsync(call tools.ToolCall, parentCtx con
 	e.setActiveJob("startup_scan", cancel)
 
 	go func() {
+		defer crash.Recover("startup scan")
 		defer e.clearActiveJob()
 		// Add tool message to chat
 		e.eventBroker.Publish(events.Event{
@@ -578,7 +777,7 @@ func (e *ToolExecutor) handleStartupScanAsync(call tools.ToolCall, parentCtx con
 		})
 
 		// Get the tool
-		tool, exists := e.registry.Get("startup_scan")
+		tool, exists := e.registry.Get(tools.StartupScanToolName)
 		if !exists {
 			e.eventBroker.Publish(events.Event{
 				Type: events.ErrorMessageEvent,
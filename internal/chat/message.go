@@ -50,6 +50,7 @@ func (m *BaseMessage) Timestamp() time.Time {
 // UserMessage represents a message from the user
 type UserMessage struct {
 	BaseMessage
+	Images []string // Attached image files
 }
 
 // Type returns the message type
@@ -60,7 +61,8 @@ func (m *UserMessage) Type() MessageType {
 // AssistantMessage represents a message from the assistant
 type AssistantMessage struct {
 	BaseMessage
-	ToolCalls []llm.ToolCall // Tool calls requested by assistant
+	ToolCalls []llm.ToolCall       // Tool calls requested by assistant
+	Metadata  *llm.MessageMetadata // How the reply was produced
 }
 
 // Type returns the message type
@@ -145,16 +147,20 @@ func FromLLMMessage(msg llm.Message, id string) Message {
 	base := BaseMessage{
 		id:        id,
 		content:   msg.Content,
-		timestamp: time.Now(),
+		timestamp: msg.Time,
+	}
+	if base.timestamp.IsZero() {
+		base.timestamp = time.Now()
 	}
 	
 	switch msg.Role {
 	case "user":
-		return &UserMessage{BaseMessage: base}
+		return &UserMessage{BaseMessage: base, Images: msg.Images}
 	case "assistant":
 		return &AssistantMessage{
 			BaseMessage: base,
 			ToolCalls:   msg.ToolCalls,
+			Metadata:    msg.Metadata,
 		}
 	case "system":
 		return &SystemMessage{BaseMessage: base}
@@ -180,14 +186,17 @@ func FromLLMMessage(msg llm.Message, id string) Message {
 func ToLLMMessage(msg Message) llm.Message {
 	llmMsg := llm.Message{
 		Content: msg.Content(),
+		Time:    msg.Timestamp(),
 	}
 	
 	switch m := msg.(type) {
 	case *UserMessage:
 		llmMsg.Role = "user"
+		llmMsg.Images = m.Images
 	case *AssistantMessage:
 		llmMsg.Role = "assistant"
 		llmMsg.ToolCalls = m.ToolCalls
+		llmMsg.Metadata = m.Metadata
 	case *SystemMessage:
 		llmMsg.Role = "system"
 	case *ToolMessage:
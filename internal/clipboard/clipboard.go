@@ -0,0 +1,98 @@
+// Package clipboard copies text to the system clipboard on macOS, Linux
+// (Wayland and X11), Windows, WSL and over SSH.
+//
+// Copy runs the platform's clipboard command: pbcopy, wl-copy, xclip, xsel
+// or clip.exe. When none is available or they all fail, and always over
+// SSH, where they would fill the remote machine's clipboard, it asks the
+// terminal to set the clipboard with an OSC 52 escape sequence instead.
+// A terminal that ignores OSC 52 leaves the clipboard unchanged, and there
+// is no way to tell.
+//
+// The sequence is written to stdout, which a full-screen UI owns while it
+// runs; it installs its own writer with SetTerminal so the sequence goes out
+// between frames.
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnavailable is returned when there is neither a clipboard command nor
+// a terminal to send OSC 52 to
+var ErrUnavailable = errors.New("no clipboard available (install wl-copy, xclip or xsel, or use a terminal that supports OSC 52)")
+
+// command is a clipboard command that reads the text from stdin
+type command struct {
+	name string
+	args []string
+}
+
+// Copy puts text on the system clipboard
+func Copy(text string) error {
+	if overSSH() {
+		return copyOSC52(text)
+	}
+
+	var errs []error
+	for _, cmd := range commands() {
+		path, err := exec.LookPath(cmd.name)
+		if err != nil {
+			continue
+		}
+		if err := run(path, cmd.args, text); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", cmd.name, err))
+			continue
+		}
+		return nil
+	}
+
+	if err := copyOSC52(text); err == nil {
+		return nil
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return ErrUnavailable
+}
+
+// commands lists the clipboard commands to try on this platform, in order
+func commands() []command {
+	switch runtime.GOOS {
+	case "darwin":
+		return []command{{name: "pbcopy"}}
+	case "windows":
+		return []command{{name: "clip.exe"}}
+	}
+
+	var cmds []command
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, command{name: "wl-copy"})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		cmds = append(cmds,
+			command{name: "xclip", args: []string{"-selection", "clipboard"}},
+			command{name: "xsel", args: []string{"--clipboard", "--input"}},
+		)
+	}
+	// WSL runs Windows executables, so clip.exe reaches the Windows clipboard
+	return append(cmds, command{name: "clip.exe"})
+}
+
+// run feeds text to a clipboard command. Its output is not read: xclip
+// and wl-copy leave a child serving the selection that would hold the pipe
+// open until something else is copied.
+func run(path string, args []string, text string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// overSSH reports whether loco runs in an SSH session
+func overSSH() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
@@ -0,0 +1,50 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+	"sync"
+)
+
+var terminal struct {
+	sync.Mutex
+	write func(seq string)
+}
+
+// SetTerminal sends OSC 52 sequences to write instead of stdout; nil
+// restores stdout
+func SetTerminal(write func(seq string)) {
+	terminal.Lock()
+	defer terminal.Unlock()
+	terminal.write = write
+}
+
+// copyOSC52 asks the terminal to set its clipboard to text
+func copyOSC52(text string) error {
+	seq := osc52(text)
+
+	terminal.Lock()
+	write := terminal.write
+	terminal.Unlock()
+	if write != nil {
+		write(seq)
+		return nil
+	}
+
+	if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return ErrUnavailable
+	}
+	_, err := os.Stdout.WriteString(seq)
+	return err
+}
+
+// osc52 builds the sequence that sets the clipboard, wrapped for tmux to
+// pass through to the outer terminal when running inside it
+func osc52(text string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
@@ -1,12 +1,16 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 )
 
 // Analysis tier-specific settings
@@ -29,11 +33,14 @@ type AnalysisQuickConfig struct {
 	AutoRun                        bool     `json:"autorun"`
 	Workers                        int      `json:"workers"`
 	WorkerConcurrency              int      `json:"worker_concurrency"`
+	MaxWorkerConcurrency           int      `json:"max_worker_concurrency"` // Ceiling for adaptive scaling (default: workers)
 	Focuses                        []string `json:"focuses"`
 	TopFileRankingCount            int      `json:"top_file_ranking_count"`
 	FinalTopK                      int      `json:"final_top_k"`
 	UseModelAdjudicator            bool     `json:"use_model_adjudicator"`
 	MaxPathsPerCall                int      `json:"max_paths_per_call"`
+	Sharding                       string   `json:"sharding"` // How workers split a list over max_paths_per_call: "contiguous", "directory", "extension" or "random"
+	MergeStrategy                  string   `json:"merge_strategy"` // How worker votes combine: "mean", "median", "borda" or "focus"
 	MaxCompletionTokensWorker      int      `json:"max_completion_tokens_worker"`
 	MaxCompletionTokensAdjudicator int      `json:"max_completion_tokens_adjudicator"`
 	RequestTimeoutMs               int      `json:"request_timeout_ms"`
@@ -52,10 +59,40 @@ type RAGConfig struct {
 	AutoIndex          bool   `json:"autoindex"`           // Index on startup
 	AutoIndexOnChange  bool   `json:"autoindex_on_change"` // Auto-index files when they change
 	DebounceDelayMs    int    `json:"debounce_delay_ms"`   // Milliseconds to wait after file changes before indexing
-	Embedder           string `json:"embedder"`            // "mock" or "lmstudio"
+	Embedder           string `json:"embedder"`            // "lmstudio", "ollama", "openai" (any OpenAI-compatible server) or "mock"
+	EmbedderURL        string `json:"embedder_url"`        // Base URL for ollama/openai embedders ("" uses the backend default; lmstudio uses lm_studio_url)
+	EmbedderAPIKey     string `json:"embedder_api_key"`    // API key for openai embedders, e.g. "$OPENAI_API_KEY"
 	BatchSize          int    `json:"batch_size"`          // Files per batch during indexing
 	EmbeddingModel     string `json:"embedding_model"`     // Model ID for embeddings (e.g., "nomic-embed-text-v1.5-GGUF")
 	DatabasePath       string `json:"database_path"`       // Path to SQLite database (relative to .loco dir)
+	VectorStore        string `json:"vector_store"`        // "auto", "sqlite" or "memory" (pure Go, for builds without cgo)
+	ContextTopK        int    `json:"context_top_k"`       // Chunks retrieved into each chat prompt (-1 disables)
+	Rerank             bool   `json:"rerank"`              // Reorder retrieval results with the small model
+	RerankCandidates   int    `json:"rerank_candidates"`   // Hybrid results handed to the reranker
+	RerankTimeoutMs    int    `json:"rerank_timeout_ms"`   // Give up on reranking (keeping retrieval order) after this
+	ChatMemoryTopK     int    `json:"chat_memory_top_k"`   // Turns of earlier sessions recalled into each chat prompt (-1 disables)
+	ChatMemorySyncMs   int    `json:"chat_memory_sync_ms"` // How often new conversation turns are embedded
+}
+
+// defaultEmbeddingModels are each embedder's model when none is configured
+var defaultEmbeddingModels = map[string]string{
+	"lmstudio": "text-embedding-nomic-embed-text-v1.5@q8_0",
+	"ollama":   "nomic-embed-text",
+	"openai":   "text-embedding-3-small",
+}
+
+// ResolvedEmbeddingModel returns the embedding model for the configured
+// embedder. New configs ship with the LM Studio default, which is treated
+// as unset for other embedders so switching embedder is a one-line change.
+func (c RAGConfig) ResolvedEmbeddingModel() string {
+	embedder := c.Embedder
+	if embedder == "" {
+		embedder = "lmstudio"
+	}
+	if c.EmbeddingModel != "" && (embedder == "lmstudio" || c.EmbeddingModel != defaultEmbeddingModels["lmstudio"]) {
+		return c.EmbeddingModel
+	}
+	return defaultEmbeddingModels[embedder]
 }
 
 type AnalysisConfig struct {
@@ -65,15 +102,142 @@ type AnalysisConfig struct {
 	Deep     TierConfig            `json:"deep"`
 	Full     TierConfig            `json:"full"`
 	RAG      RAGConfig             `json:"rag"`
+
+	// How often settled conversation turns are scanned for decisions to log
+	// in .loco/knowledge/decisions.md (-1 disables)
+	DecisionLogMs int `json:"decision_log_ms"`
+	// How many recent commits .loco/knowledge/changelog.md summarizes
+	ChangelogCommits int `json:"changelog_commits"`
+	// Run analysis at temperature 0 with a fixed seed, workers in order and
+	// timestamps from the HEAD commit, so two runs on one commit write the
+	// same knowledge files
+	Deterministic bool `json:"deterministic"`
 	// Future: additional per-tier settings can be added here
 }
 
+// Tool permission policies
+const (
+	ToolPolicyAllow = "allow" // Run without asking
+	ToolPolicyAsk   = "ask"   // Ask the user each time
+	ToolPolicyDeny  = "deny"  // Always refuse
+)
+
+// BashConfig controls the bash tool's sandbox
+type BashConfig struct {
+	Allowlist      []string `json:"allowlist"`        // Command prefixes that run without asking (in addition to built-in read-only commands)
+	Denylist       []string `json:"denylist"`         // Command prefixes that are always refused
+	TimeoutMs      int      `json:"timeout_ms"`       // Default per-command timeout
+	MaxOutputBytes int      `json:"max_output_bytes"` // Output beyond this is truncated (head and tail kept)
+}
+
+// GuardrailsConfig limits what tools may touch, whatever the answer to a
+// permission request
+type GuardrailsConfig struct {
+	// Globs of project paths that the tools editing files or running
+	// commands (edit_file, multi_edit, bash, run_process...) refuse to
+	// touch. Patterns with a slash match from the project root, with ** for
+	// any directories; patterns without one match any file or directory
+	// name. Everything under a matching directory is protected too. An
+	// empty list protects nothing.
+	ProtectedPaths []string `json:"protected_paths"`
+}
+
+// RedactionConfig controls the scrubbing of secrets from prompts, file
+// content and embedding input before they are sent to a model
+type RedactionConfig struct {
+	Disabled bool     `json:"disabled"` // Send content as it is
+	Patterns []string `json:"patterns"` // Extra regular expressions to redact; a capture group limits the redaction to the group
+}
+
+// Content modes of the logging.content setting
+const (
+	LogContentFull   = "full"   // Prompts and responses are written as they are
+	LogContentHashes = "hashes" // Only each text's size and SHA-256 hash
+	LogContentNone   = "none"   // Nothing is written
+)
+
+// LoggingConfig controls what of prompts, responses and conversations is
+// written to disk for diagnosis: analysis debug dumps under .loco/debug and
+// crash bundles under .loco/crash
+type LoggingConfig struct {
+	Content       string `json:"content"`        // "full" (default), "hashes" or "none"
+	RetentionDays int    `json:"retention_days"` // Debug dumps older than this are removed at startup (default 14; -1 keeps them)
+}
+
+// Dump returns text as logging.content lets it be written to disk, and
+// false when nothing may be written
+func (c LoggingConfig) Dump(text string) (string, bool) {
+	switch c.Content {
+	case LogContentNone:
+		return "", false
+	case LogContentHashes:
+		sum := sha256.Sum256([]byte(text))
+		return fmt.Sprintf("sha256:%s (%d bytes)\n", hex.EncodeToString(sum[:]), len(text)), true
+	default:
+		return text, true
+	}
+}
+
+// LSPServerConfig describes how to launch one language server
+type LSPServerConfig struct {
+	Command    string   `json:"command"`               // Executable, looked up in PATH
+	Args       []string `json:"args"`                  // Extra arguments (e.g. --stdio)
+	Extensions []string `json:"extensions"`            // File extensions the server handles
+	LanguageID string   `json:"language_id,omitempty"` // LSP language ID (defaults to the server's name)
+}
+
+// LSPConfig controls the language servers behind the symbol tools
+type LSPConfig struct {
+	Enabled bool                       `json:"enabled"`
+	Servers map[string]LSPServerConfig `json:"servers"` // Keyed by language name
+}
+
+// Checks the build_check setting can run
+const (
+	BuildCheckBuild = "build" // go build ./...
+	BuildCheckVet   = "vet"   // go vet ./..., when the build succeeds
+)
+
+// BuildCheckConfig controls the Go build check run in the background after
+// tools change Go files, whose errors are reported back to the model
+type BuildCheckConfig struct {
+	Enabled   bool     `json:"enabled"`
+	Checks    []string `json:"checks"`     // "build" and "vet" (default both)
+	TimeoutMs int      `json:"timeout_ms"` // Per check (default 120000)
+}
+
+// HTTPConfig controls the http_request tool
+type HTTPConfig struct {
+	// Hosts requests may go to: a name or IP matches any port, host:port
+	// only that port, and *.example.com its subdomains
+	AllowedHosts     []string `json:"allowed_hosts"`
+	TimeoutMs        int      `json:"timeout_ms"`         // Per request (default 30000)
+	MaxResponseBytes int      `json:"max_response_bytes"` // Body shown beyond this is truncated
+}
+
+// DocsConfig controls the fetch_docs tool
+type DocsConfig struct {
+	// Hosts documentation may be fetched from, matched as in HTTPConfig
+	AllowedHosts  []string `json:"allowed_hosts"`
+	CacheTTLHours int      `json:"cache_ttl_hours"` // Fetched pages are reused for this long (default 168)
+	MaxChars      int      `json:"max_chars"`       // Text returned beyond this is truncated; the cache keeps it all
+}
+
+// MCPServerConfig describes how to launch an external MCP server
+type MCPServerConfig struct {
+	Command  string            `json:"command"`            // Executable, looked up in PATH
+	Args     []string          `json:"args"`               // Arguments (e.g. a package name for npx)
+	Env      map[string]string `json:"env,omitempty"`      // Extra environment; values may use $VAR
+	Disabled bool              `json:"disabled,omitempty"` // Keep the entry but don't start it
+}
+
 type LLMPolicy struct {
 	ModelID              string `json:"model_id"`
 	RequestTimeoutMs     int    `json:"request_timeout_ms"`
 	MaxTokensWorker      int    `json:"max_tokens_worker"`
 	MaxTokensAdjudicator int    `json:"max_tokens_adjudicator"`
 	ContextSize          int    `json:"context_size"`
+	Concurrency          int    `json:"concurrency,omitempty"` // Analysis runs the model takes at once (default 1)
 }
 
 type LLMConfig struct {
@@ -82,32 +246,137 @@ type LLMConfig struct {
 	Largest  LLMPolicy `json:"largest"`  // L/XL
 }
 
+// Curves the queue's aging can follow
+const (
+	QueueAgingLinear      = "linear"      // Step more for every interval waited
+	QueueAgingExponential = "exponential" // The gain doubles with every interval
+	QueueAgingNone        = "none"        // Waiting never raises priority
+)
+
+// QueueConfig controls how queued model requests are scheduled
+type QueueConfig struct {
+	Aging QueueAgingConfig `json:"aging"`
+}
+
+// QueueAgingConfig raises the priority of waiting requests over time, so
+// background analyses still run while the user keeps chatting
+type QueueAgingConfig struct {
+	Curve       string `json:"curve"`        // linear, exponential or none
+	IntervalMs  int    `json:"interval_ms"`  // Waiting time per increase (default 30000)
+	Step        int    `json:"step"`         // Priority gained after the first interval (default 1)
+	MaxPriority int    `json:"max_priority"` // Aging lifts nothing above this (default 10, chat's), so the longest wait goes first
+}
+
+// RouteConfig is how one kind of chat request is answered
+type RouteConfig struct {
+	Model        string   `json:"model"`                 // Team model: "small", "medium" or "large"; "" keeps the chat model
+	SystemPrompt string   `json:"system_prompt"`         // Instructions put ahead of the project context
+	Temperature  *float64 `json:"temperature,omitempty"` // Sampling temperature; unset uses the default 0.7
+}
+
+// RoutingConfig sends each chat message to the team model and prompt
+// suited to its kind. A model picked with /model still answers everything.
+type RoutingConfig struct {
+	Enabled bool                   `json:"enabled"`
+	Routes  map[string]RouteConfig `json:"routes"` // By kind: chat, code-edit, explain, analysis
+}
+
+// VerifyConfig has a second model review chat answers before they are
+// shown, for file paths that don't exist and claims the project knowledge
+// contradicts
+type VerifyConfig struct {
+	Enabled   bool   `json:"enabled"`
+	Model     string `json:"model"`      // Team model that reviews: "small", "medium" or "large"
+	ToolsOnly bool   `json:"tools_only"` // Review only answers that call tools or follow tool results
+
+	// What to do with file references in answers that name no project
+	// file: "flag" them, "correct" them to the file meant when it is clear,
+	// or "off" to leave references unchecked. Checking needs no model, so
+	// it runs whether or not reviews are enabled.
+	Citations string `json:"citations"`
+}
+
+// temperature returns a pointer for RouteConfig.Temperature
+func temperature(t float64) *float64 {
+	return &t
+}
+
+// MockConfig scripts the mock provider, which answers without a model
+type MockConfig struct {
+	Responses string `json:"responses"`  // JSON file of {"match", "response"} pairs, relative to the project ("" uses canned replies)
+	LatencyMs int    `json:"latency_ms"` // Simulated time per request
+}
+
 // Config represents the Loco configuration
 type Config struct {
+	// Model provider: "lmstudio" (default) or "mock" to run offline
+	Provider string     `json:"provider"`
+	Mock     MockConfig `json:"mock"`
+
+	// Record LM Studio requests to .loco/cassettes/ ("record") or answer
+	// them from there ("replay"); "" talks to the model as usual
+	Cassette string `json:"cassette"`
+
+
 	// LM Studio settings
 	LMStudioURL         string `json:"lm_studio_url"`
 	PreferredModel      string `json:"preferred_model"`
 	LMStudioContextSize int    `json:"lm_studio_n_ctx"`
 	LMStudioNumKeep     int    `json:"lm_studio_num_keep"`
 
+	// Tokens of project knowledge and retrieved code in the chat system
+	// prompt; 0 uses a quarter of lm_studio_n_ctx
+	PromptBudget int `json:"prompt_budget"`
+
 	// UI preferences
 	Theme string `json:"theme"`
 	Debug bool   `json:"debug"`
 
 	// Tool settings
-	ToolsEnabled bool     `json:"tools_enabled"`
-	AllowedTools []string `json:"allowed_tools"`
+	ToolsEnabled bool       `json:"tools_enabled"`
+	AllowedTools []string          `json:"allowed_tools"`
+	ToolPolicies map[string]string `json:"tool_policies"` // Tool name -> "allow", "ask" or "deny"
+	PermissionGrants map[string][]string `json:"permission_grants"` // Tool name -> commands, paths or hosts answered "always allow"
+	Bash         BashConfig        `json:"bash"`
+	Guardrails   GuardrailsConfig  `json:"guardrails"`
+	Redaction    RedactionConfig   `json:"redaction"`
+	Logging      LoggingConfig     `json:"logging"`
+	LSP          LSPConfig         `json:"lsp"`
+	BuildCheck   BuildCheckConfig  `json:"build_check"`
+	HTTP         HTTPConfig        `json:"http"`
+	Docs         DocsConfig        `json:"docs"`
+
+	// External MCP servers whose tools join the registry, keyed by name
+	MCPServers map[string]MCPServerConfig `json:"mcp_servers"`
 
 	// LLM size and model policies (t-shirt S/M/L)
 	LLM LLMConfig `json:"llm"`
 
+	// Scheduling of queued model requests
+	Queue QueueConfig `json:"queue"`
+
+	// Which team model answers each kind of chat message
+	Routing RoutingConfig `json:"routing"`
+
+	// Review of answers by a second model before they are shown
+	Verify VerifyConfig `json:"verify"`
+
 	// Analysis settings (nested)
 	Analysis AnalysisConfig `json:"analysis"`
+
+	// Which files count as part of the project
+	Files FilesConfig `json:"files"`
+}
+
+// FilesConfig controls project file discovery in git repositories
+type FilesConfig struct {
+	Submodules bool `json:"submodules"` // Include the files of checked-out submodules
 }
 
 // DefaultConfig returns a config with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
+		Provider:            "lmstudio",
 		LMStudioURL:         "http://localhost:1234",
 		PreferredModel:      "auto",
 		LMStudioContextSize: 8192,
@@ -116,11 +385,91 @@ func DefaultConfig() *Config {
 		Debug:               false,
 		ToolsEnabled:        true,
 		AllowedTools:        []string{"copy", "clear", "help", "chat"}, // Safe tools allowed by default
+		ToolPolicies: map[string]string{
+			"bash":         ToolPolicyAsk,
+			"edit_file":    ToolPolicyAsk,
+			"multi_edit":   ToolPolicyAsk,
+			"git_commit":   ToolPolicyAsk,
+			"git_branch":   ToolPolicyAsk,
+			"memory":       ToolPolicyAsk,
+			"http_request": ToolPolicyAsk,
+		},
+		Bash: BashConfig{
+			Allowlist:      []string{},
+			Denylist:       []string{"sudo", "su", "rm -rf /", "mkfs", "dd", "shutdown", "reboot", "chmod -R 777 /"},
+			TimeoutMs:      60000,
+			MaxOutputBytes: 30000,
+		},
+		Guardrails: GuardrailsConfig{
+			ProtectedPaths: []string{".git/**", "secrets/**", ".env", "*.env", ".env.local", ".env.*.local", ".env.production", "*.pem", "*.key", "id_rsa*"},
+		},
+		Logging: LoggingConfig{
+			Content:       LogContentFull,
+			RetentionDays: 14,
+		},
+		LSP: LSPConfig{
+			Enabled: true,
+			Servers: map[string]LSPServerConfig{
+				"go": {Command: "gopls", Extensions: []string{".go"}},
+				"typescript": {
+					Command:    "typescript-language-server",
+					Args:       []string{"--stdio"},
+					Extensions: []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"},
+				},
+			},
+		},
+		HTTP: HTTPConfig{
+			AllowedHosts:     []string{"localhost", "127.0.0.1", "::1"},
+			TimeoutMs:        30000,
+			MaxResponseBytes: 30000,
+		},
+		Docs: DocsConfig{
+			AllowedHosts: []string{
+				"pkg.go.dev", "go.dev", "developer.mozilla.org", "docs.python.org", "doc.rust-lang.org",
+				"docs.rs", "*.readthedocs.io", "github.com", "raw.githubusercontent.com",
+			},
+			CacheTTLHours: 168,
+			MaxChars:      30000,
+		},
+		BuildCheck: BuildCheckConfig{
+			Checks:    []string{BuildCheckBuild, BuildCheckVet},
+			TimeoutMs: 120000,
+		},
 		LLM: LLMConfig{
 			Smallest: LLMPolicy{ModelID: "", RequestTimeoutMs: 30000, MaxTokensWorker: -1, MaxTokensAdjudicator: -1, ContextSize: 8192},
 			Medium:   LLMPolicy{ModelID: "", RequestTimeoutMs: 120000, MaxTokensWorker: -1, MaxTokensAdjudicator: -1, ContextSize: 8192},
 			Largest:  LLMPolicy{ModelID: "", RequestTimeoutMs: 600000, MaxTokensWorker: -1, MaxTokensAdjudicator: -1, ContextSize: 8192},
 		},
+		Queue: QueueConfig{
+			Aging: QueueAgingConfig{Curve: QueueAgingLinear, IntervalMs: 30000, Step: 1, MaxPriority: 10},
+		},
+		Routing: RoutingConfig{
+			Enabled: true,
+			Routes: map[string]RouteConfig{
+				"chat": {Temperature: temperature(0.7)},
+				"code-edit": {
+					Model:        "large",
+					SystemPrompt: "The user wants code changed. Make the smallest change that does it, in the project's existing style. Show each change as an edit to a named file, then say how to check it.",
+					Temperature:  temperature(0.2),
+				},
+				"explain": {
+					Model:        "medium",
+					SystemPrompt: "The user wants something explained. Answer from the project's code and knowledge, citing file paths, and keep it as short as the question allows.",
+					Temperature:  temperature(0.5),
+				},
+				"analysis": {
+					Model:        "large",
+					SystemPrompt: "The user wants the project assessed. Weigh the evidence in the project context, name the files it comes from, and keep findings apart from recommendations.",
+					Temperature:  temperature(0.3),
+				},
+			},
+		},
+		Verify: VerifyConfig{
+			Enabled:   false,
+			Model:     "medium",
+			ToolsOnly: true,
+			Citations: "flag",
+		},
 		Analysis: AnalysisConfig{
 			Startup: AnalysisStartupConfig{Clean: false, Debug: false, CrowdSize: 10, Autorun: false},
 			Quick: AnalysisQuickConfig{
@@ -134,6 +483,8 @@ func DefaultConfig() *Config {
 				FinalTopK:                      100,
 				UseModelAdjudicator:            true,
 				MaxPathsPerCall:                400,
+				Sharding:                       "contiguous",
+				MergeStrategy:                  "mean",
 				MaxCompletionTokensWorker:      300,
 				MaxCompletionTokensAdjudicator: 600,
 				RequestTimeoutMs:               10000,
@@ -153,9 +504,18 @@ func DefaultConfig() *Config {
 				DebounceDelayMs:    2000,                                      // Wait 2 seconds after file changes before indexing
 				Embedder:           "lmstudio",                                // Use LM Studio for real embeddings
 				BatchSize:          10,                                        // Process 10 files at a time
-				EmbeddingModel:     "text-embedding-nomic-embed-text-v1.5@q8_0", // Default embedding model (8-bit quantized)
+				EmbeddingModel:     defaultEmbeddingModels["lmstudio"],        // Default embedding model (8-bit quantized)
 				DatabasePath:       "vectors.db",                              // Store in .loco/vectors.db
+				VectorStore:        "auto",                                    // SQLite when built with cgo, memory otherwise
+				ContextTopK:        5,                                         // Retrieve 5 chunks per chat message
+				Rerank:             false,                                     // Reranking costs a model call per query
+				RerankCandidates:   50,                                        // Rerank the top 50 hybrid results
+				RerankTimeoutMs:    10000,                                     // Fall back to retrieval order after 10s
+				ChatMemoryTopK:     3,                                         // Recall 3 earlier turns per chat message
+				ChatMemorySyncMs:   300000,                                    // Embed new turns every 5 minutes
 			},
+			DecisionLogMs:    600000, // Log new decisions every 10 minutes
+			ChangelogCommits: 50,     // Summarize the last 50 commits
 		},
 	}
 }
@@ -165,6 +525,10 @@ type Manager struct {
 	projectPath string
 	configPath  string
 	config      *Config
+
+	// mu guards the permission maps, which the permission service updates
+	// while tools read them, and serializes saves
+	mu sync.RWMutex
 }
 
 // NewManager creates a new configuration manager
@@ -276,6 +640,114 @@ func (m *Manager) Load() error {
 	if cfg.Analysis.Quick.WorkerSummaryWordLimit == 0 {
 		cfg.Analysis.Quick.WorkerSummaryWordLimit = m.config.Analysis.Quick.WorkerSummaryWordLimit
 	}
+	if cfg.Analysis.RAG.ContextTopK == 0 {
+		cfg.Analysis.RAG.ContextTopK = m.config.Analysis.RAG.ContextTopK
+	}
+	if cfg.Analysis.RAG.RerankCandidates == 0 {
+		cfg.Analysis.RAG.RerankCandidates = m.config.Analysis.RAG.RerankCandidates
+	}
+	if cfg.Analysis.RAG.RerankTimeoutMs == 0 {
+		cfg.Analysis.RAG.RerankTimeoutMs = m.config.Analysis.RAG.RerankTimeoutMs
+	}
+	if cfg.Analysis.RAG.ChatMemoryTopK == 0 {
+		cfg.Analysis.RAG.ChatMemoryTopK = m.config.Analysis.RAG.ChatMemoryTopK
+	}
+	if cfg.Analysis.RAG.ChatMemorySyncMs == 0 {
+		cfg.Analysis.RAG.ChatMemorySyncMs = m.config.Analysis.RAG.ChatMemorySyncMs
+	}
+	if cfg.Analysis.DecisionLogMs == 0 {
+		cfg.Analysis.DecisionLogMs = m.config.Analysis.DecisionLogMs
+	}
+	if cfg.Analysis.ChangelogCommits == 0 {
+		cfg.Analysis.ChangelogCommits = m.config.Analysis.ChangelogCommits
+	}
+	if cfg.ToolPolicies == nil {
+		cfg.ToolPolicies = make(map[string]string)
+		m.mu.RLock()
+		for tool, policy := range m.config.ToolPolicies {
+			cfg.ToolPolicies[tool] = policy
+		}
+		m.mu.RUnlock()
+	}
+	if cfg.Routing.Routes == nil {
+		cfg.Routing.Enabled = m.config.Routing.Enabled
+		cfg.Routing.Routes = make(map[string]RouteConfig)
+	}
+	for name, route := range m.config.Routing.Routes {
+		// Routes and fields left out keep their defaults
+		configured, ok := cfg.Routing.Routes[name]
+		if !ok {
+			configured = route
+		}
+		if configured.Temperature == nil {
+			configured.Temperature = route.Temperature
+		}
+		cfg.Routing.Routes[name] = configured
+	}
+	if cfg.Verify.Model == "" {
+		cfg.Verify.Model = m.config.Verify.Model
+	}
+	if cfg.Verify.Citations == "" {
+		cfg.Verify.Citations = m.config.Verify.Citations
+	}
+	if cfg.LSP.Servers == nil {
+		cfg.LSP.Enabled = m.config.LSP.Enabled
+		cfg.LSP.Servers = m.config.LSP.Servers
+	}
+	if cfg.HTTP.AllowedHosts == nil {
+		cfg.HTTP.AllowedHosts = append([]string{}, m.config.HTTP.AllowedHosts...)
+	}
+	if cfg.HTTP.TimeoutMs == 0 {
+		cfg.HTTP.TimeoutMs = m.config.HTTP.TimeoutMs
+	}
+	if cfg.HTTP.MaxResponseBytes == 0 {
+		cfg.HTTP.MaxResponseBytes = m.config.HTTP.MaxResponseBytes
+	}
+	if cfg.Queue.Aging.Curve == "" {
+		cfg.Queue.Aging.Curve = m.config.Queue.Aging.Curve
+	}
+	if cfg.Queue.Aging.IntervalMs == 0 {
+		cfg.Queue.Aging.IntervalMs = m.config.Queue.Aging.IntervalMs
+	}
+	if cfg.Queue.Aging.Step == 0 {
+		cfg.Queue.Aging.Step = m.config.Queue.Aging.Step
+	}
+	if cfg.Queue.Aging.MaxPriority == 0 {
+		cfg.Queue.Aging.MaxPriority = m.config.Queue.Aging.MaxPriority
+	}
+	if cfg.Docs.AllowedHosts == nil {
+		cfg.Docs.AllowedHosts = append([]string{}, m.config.Docs.AllowedHosts...)
+	}
+	if cfg.Docs.CacheTTLHours == 0 {
+		cfg.Docs.CacheTTLHours = m.config.Docs.CacheTTLHours
+	}
+	if cfg.Docs.MaxChars == 0 {
+		cfg.Docs.MaxChars = m.config.Docs.MaxChars
+	}
+	if cfg.BuildCheck.Checks == nil {
+		cfg.BuildCheck.Checks = append([]string{}, m.config.BuildCheck.Checks...)
+	}
+	if cfg.BuildCheck.TimeoutMs == 0 {
+		cfg.BuildCheck.TimeoutMs = m.config.BuildCheck.TimeoutMs
+	}
+	if cfg.Logging.Content == "" {
+		cfg.Logging.Content = m.config.Logging.Content
+	}
+	if cfg.Logging.RetentionDays == 0 {
+		cfg.Logging.RetentionDays = m.config.Logging.RetentionDays
+	}
+	if cfg.Guardrails.ProtectedPaths == nil {
+		cfg.Guardrails.ProtectedPaths = append([]string{}, m.config.Guardrails.ProtectedPaths...)
+	}
+	if cfg.Bash.Denylist == nil {
+		cfg.Bash.Denylist = append([]string{}, m.config.Bash.Denylist...)
+	}
+	if cfg.Bash.TimeoutMs == 0 {
+		cfg.Bash.TimeoutMs = m.config.Bash.TimeoutMs
+	}
+	if cfg.Bash.MaxOutputBytes == 0 {
+		cfg.Bash.MaxOutputBytes = m.config.Bash.MaxOutputBytes
+	}
 	// Ensure LLM policies are filled
 	if cfg.LLM.Smallest.RequestTimeoutMs == 0 {
 		cfg.LLM.Smallest.RequestTimeoutMs = m.config.LLM.Smallest.RequestTimeoutMs
@@ -314,12 +786,16 @@ func (m *Manager) Load() error {
 		cfg.LLM.Largest.ContextSize = m.config.LLM.Largest.ContextSize
 	}
 
+	m.mu.Lock()
 	m.config = &cfg
+	m.mu.Unlock()
 	return nil
 }
 
 // Save writes the current configuration to disk
 func (m *Manager) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	data, err := json.MarshalIndent(m.config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
@@ -337,9 +813,75 @@ func (m *Manager) Get() *Config {
 	return m.config
 }
 
+// ToolPolicy returns the permission policy for a tool. Tools without an
+// explicit policy are allowed if listed in allowed_tools, otherwise asked.
+func (m *Manager) ToolPolicy(tool string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.config == nil {
+		return ToolPolicyAsk
+	}
+	if policy, ok := m.config.ToolPolicies[tool]; ok {
+		return policy
+	}
+	for _, allowed := range m.config.AllowedTools {
+		if allowed == tool {
+			return ToolPolicyAllow
+		}
+	}
+	return ToolPolicyAsk
+}
+
+// SetToolPolicy records the permission policy for a tool and saves
+func (m *Manager) SetToolPolicy(tool, policy string) error {
+	switch policy {
+	case ToolPolicyAllow, ToolPolicyAsk, ToolPolicyDeny:
+	default:
+		return fmt.Errorf("invalid tool policy %q (use allow, ask or deny)", policy)
+	}
+	m.mu.Lock()
+	if m.config.ToolPolicies == nil {
+		m.config.ToolPolicies = make(map[string]string)
+	}
+	m.config.ToolPolicies[tool] = policy
+	m.mu.Unlock()
+	return m.Save()
+}
+
+// ScopeAllowed reports whether the user chose to always allow the tool
+// for this command, path or host
+func (m *Manager) ScopeAllowed(tool, scope string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.config == nil {
+		return false
+	}
+	return slices.Contains(m.config.PermissionGrants[tool], scope)
+}
+
+// AllowScope records that the tool may always run for this command, path
+// or host, and saves
+func (m *Manager) AllowScope(tool, scope string) error {
+	m.mu.Lock()
+	if slices.Contains(m.config.PermissionGrants[tool], scope) {
+		m.mu.Unlock()
+		return nil
+	}
+	if m.config.PermissionGrants == nil {
+		m.config.PermissionGrants = make(map[string][]string)
+	}
+	m.config.PermissionGrants[tool] = append(m.config.PermissionGrants[tool], scope)
+	m.mu.Unlock()
+	return m.Save()
+}
+
 // Set updates a configuration value and saves
 func (m *Manager) Set(key, value string) error {
 	switch key {
+	case "provider":
+		m.config.Provider = value
+	case "cassette":
+		m.config.Cassette = value
 	case "lm_studio_url":
 		m.config.LMStudioURL = value
 	case "preferred_model":
@@ -350,6 +892,25 @@ func (m *Manager) Set(key, value string) error {
 		m.config.Debug = value == "true"
 	case "tools_enabled":
 		m.config.ToolsEnabled = value == "true"
+	case "routing.enabled":
+		m.config.Routing.Enabled = value == "true"
+	case "verify.enabled":
+		m.config.Verify.Enabled = value == "true"
+	case "verify.model":
+		m.config.Verify.Model = value
+	case "verify.tools_only":
+		m.config.Verify.ToolsOnly = value == "true"
+	case "verify.citations":
+		m.config.Verify.Citations = value
+	case "redaction.disabled":
+		m.config.Redaction.Disabled = value == "true"
+	case "logging.content":
+		m.config.Logging.Content = value
+	case "logging.retention_days":
+		var n int
+		if _, err := fmt.Sscanf(value, "%d", &n); err == nil && n != 0 {
+			m.config.Logging.RetentionDays = n
+		}
 	case "analysis.startup.clean":
 		m.config.Analysis.Startup.Clean = value == "true"
 	case "analysis.startup.debug":
@@ -368,6 +929,8 @@ func (m *Manager) Set(key, value string) error {
 		}
 	case "analysis.startup.autorun":
 		m.config.Analysis.Startup.Autorun = value == "true"
+	case "analysis.deterministic":
+		m.config.Analysis.Deterministic = value == "true"
 	case "analysis.quick.clean":
 		m.config.Analysis.Quick.Clean = value == "true"
 	case "analysis.quick.debug":
@@ -386,6 +949,12 @@ func (m *Manager) Set(key, value string) error {
 		if n > 0 {
 			m.config.Analysis.Quick.WorkerConcurrency = n
 		}
+	case "analysis.quick.max_worker_concurrency":
+		var n int
+		_, _ = fmt.Sscanf(value, "%d", &n)
+		if n > 0 {
+			m.config.Analysis.Quick.MaxWorkerConcurrency = n
+		}
 	case "analysis.quick.top_file_ranking_count":
 		var n int
 		_, _ = fmt.Sscanf(value, "%d", &n)
@@ -406,6 +975,10 @@ func (m *Manager) Set(key, value string) error {
 		if n > 0 {
 			m.config.Analysis.Quick.MaxPathsPerCall = n
 		}
+	case "analysis.quick.sharding":
+		m.config.Analysis.Quick.Sharding = strings.ToLower(strings.TrimSpace(value))
+	case "analysis.quick.merge_strategy":
+		m.config.Analysis.Quick.MergeStrategy = strings.ToLower(strings.TrimSpace(value))
 	case "analysis.quick.max_completion_tokens_worker":
 		var n int
 		_, _ = fmt.Sscanf(value, "%d", &n)
@@ -475,7 +1048,18 @@ func (m *Manager) Set(key, value string) error {
 		m.config.Analysis.Full.Debug = value == "true"
 	case "analysis.full.autorun":
 		m.config.Analysis.Full.AutoRun = value == "true"
+	case "analysis.changelog_commits":
+		var n int
+		_, _ = fmt.Sscanf(value, "%d", &n)
+		if n > 0 {
+			m.config.Analysis.ChangelogCommits = n
+		}
+	case "files.submodules":
+		m.config.Files.Submodules = value == "true"
 	default:
+		if tool, ok := strings.CutPrefix(key, "tool_policies."); ok && tool != "" {
+			return m.SetToolPolicy(tool, value)
+		}
 		// Unknown key; ignore or handle elsewhere
 	}
 	return m.Save()
@@ -506,9 +1090,13 @@ cache/
 temp/
 tmp/
 
+# Undo snapshots of tool-applied edits
+checkpoints/
+
 # Allow these important files
 !config.json
 !config.jsonc
+!memory.md
 !.gitignore
 
 # Sessions are up to you - uncomment to ignore:
@@ -523,6 +1111,14 @@ func (m *Manager) expandEnvVars(cfg *Config) error {
 	cfg.LMStudioURL = m.expandString(cfg.LMStudioURL)
 	cfg.PreferredModel = m.expandString(cfg.PreferredModel)
 	cfg.Theme = m.expandString(cfg.Theme)
+	cfg.Analysis.RAG.EmbedderURL = m.expandString(cfg.Analysis.RAG.EmbedderURL)
+	cfg.Analysis.RAG.EmbedderAPIKey = m.expandString(cfg.Analysis.RAG.EmbedderAPIKey)
+	for name, server := range cfg.MCPServers {
+		for key, value := range server.Env {
+			server.Env[key] = m.expandString(value)
+		}
+		cfg.MCPServers[name] = server
+	}
 	return nil
 }
 
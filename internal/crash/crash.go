@@ -0,0 +1,186 @@
+// Package crash turns panics into diagnostic bundles under .loco/crash/.
+//
+// main calls Init with the bundle directory, registers sections (recent
+// events, the last LLM request, ...) with AddSection and a terminal restore
+// hook with SetRestore. Background goroutines start with
+//
+//	defer crash.Recover("what this goroutine does")
+//
+// so a panic anywhere writes a bundle, puts the terminal back and exits
+// with a message pointing at the bundle, instead of leaving a raw stack
+// trace in an alt-screen. Until Init is called, Recover re-panics.
+package crash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ExitCode is the process exit code after a crash
+const ExitCode = 2
+
+// reporter holds what a bundle needs; it is process-wide like the panic
+// it reports
+var reporter struct {
+	mu       sync.Mutex
+	dir      string
+	restore  func()
+	sections map[string]func() string
+	last     string // Path of the last bundle written
+	once     sync.Once
+	exiting  atomic.Bool
+}
+
+// Init enables crash bundles, written to dir (usually .loco/crash)
+func Init(dir string) {
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	reporter.dir = dir
+	if reporter.sections == nil {
+		reporter.sections = make(map[string]func() string)
+	}
+}
+
+// SetRestore sets the hook that puts the terminal back before exiting
+func SetRestore(fn func()) {
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	reporter.restore = fn
+}
+
+// AddSection adds a file to every bundle. fn runs while the process is
+// crashing, so it should only read state.
+func AddSection(name string, fn func() string) {
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	if reporter.sections == nil {
+		reporter.sections = make(map[string]func() string)
+	}
+	reporter.sections[name] = fn
+}
+
+// LastBundle returns the path of the last bundle written, if any
+func LastBundle() string {
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	return reporter.last
+}
+
+// Exiting reports whether a crashing goroutine is shutting the process down.
+// Restoring the terminal may end the TUI, so main should wait rather than
+// exit first.
+func Exiting() bool {
+	return reporter.exiting.Load()
+}
+
+// Recover must be deferred directly. It turns a panic into a bundle, restores
+// the terminal and exits with ExitCode.
+func Recover(where string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	path, err := Capture(where, r, debug.Stack())
+	if err != nil {
+		// Nothing to write to; fail the usual way
+		panic(r)
+	}
+
+	// Only the first crashing goroutine reports; others wait for the exit
+	reporter.once.Do(func() {
+		reporter.exiting.Store(true)
+		reporter.mu.Lock()
+		restore := reporter.restore
+		reporter.mu.Unlock()
+		if restore != nil {
+			restore()
+		}
+		fmt.Fprintf(os.Stderr, "loco crashed in %s: %v\nDiagnostic bundle: %s\n", where, r, path)
+		os.Exit(ExitCode)
+	})
+	select {}
+}
+
+// Capture writes a bundle for a recovered panic and returns its directory.
+// It fails when Init was not called.
+func Capture(where string, value any, stack []byte) (string, error) {
+	reporter.mu.Lock()
+	dir := reporter.dir
+	sections := make(map[string]func() string, len(reporter.sections))
+	for name, fn := range reporter.sections {
+		sections[name] = fn
+	}
+	reporter.mu.Unlock()
+	if dir == "" {
+		return "", fmt.Errorf("crash reporting is not initialized")
+	}
+
+	now := time.Now()
+	path := filepath.Join(dir, now.Format("20060102-150405.000"))
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return "", err
+	}
+
+	files := map[string]string{
+		"panic.txt":      panicReport(where, value, stack, now),
+		"goroutines.txt": allStacks(),
+	}
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		files[name+".txt"] = section(sections[name])
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(path, name), []byte(content), 0o644); err != nil {
+			return "", err
+		}
+	}
+
+	reporter.mu.Lock()
+	reporter.last = path
+	reporter.mu.Unlock()
+	return path, nil
+}
+
+// panicReport describes the panic and the build
+func panicReport(where string, value any, stack []byte, at time.Time) string {
+	version := "(unknown)"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		version = info.Main.Version
+	}
+	return fmt.Sprintf("Where:   %s\nPanic:   %v\nTime:    %s\nVersion: %s\nGo:      %s %s/%s\n\n%s",
+		where, value, at.Format(time.RFC3339), version, runtime.Version(), runtime.GOOS, runtime.GOARCH, stack)
+}
+
+// allStacks returns the stacks of every goroutine
+func allStacks() string {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= 64<<20 {
+			return string(buf[:n])
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
+
+// section runs a section provider, surviving a panic inside it
+func section(fn func() string) (content string) {
+	defer func() {
+		if r := recover(); r != nil {
+			content = fmt.Sprintf("(section failed: %v)", r)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,244 @@
+// Package export renders a conversation as a document to share: Markdown,
+// or a standalone HTML page.
+//
+// Tool blocks become collapsible sections, and each message carries its
+// time and, for replies, the model and the code it was given.
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Format is a document format to export to
+type Format string
+
+const (
+	Markdown Format = "md"
+	HTML     Format = "html"
+)
+
+// ParseFormat reads a format name as typed after /export
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "md", "markdown":
+		return Markdown, nil
+	case "html", "htm":
+		return HTML, nil
+	}
+	return "", fmt.Errorf("unknown export format %q (use md or html)", name)
+}
+
+// Conversation is what gets exported: a session's messages as shown in the
+// chat, tool blocks included
+type Conversation struct {
+	Title     string
+	SessionID string
+	Created   time.Time
+	Model     string // The session's chat model
+	Messages  []llm.Message
+}
+
+// Render renders the conversation in format
+func Render(c Conversation, format Format) ([]byte, error) {
+	switch format {
+	case Markdown:
+		return []byte(renderMarkdown(c)), nil
+	case HTML:
+		return renderHTML(c)
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// renderMarkdown renders the conversation as a Markdown document
+func renderMarkdown(c Conversation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title(c))
+	b.WriteString(strings.Join(summary(c, "`"), " · ") + "\n")
+
+	for _, msg := range c.Messages {
+		b.WriteString("\n")
+		if msg.Role == "tool" {
+			name, status := toolLabel(msg)
+			fence := codeFence(msg.Content)
+			fmt.Fprintf(&b, "<details>\n<summary>🔧 %s · %s</summary>\n\n", html.EscapeString(name), html.EscapeString(status))
+			if content := strings.TrimRight(msg.Content, "\n"); content != "" {
+				fmt.Fprintf(&b, "%s\n%s\n%s\n\n", fence, content, fence)
+			}
+			b.WriteString("</details>\n")
+			continue
+		}
+
+		fmt.Fprintf(&b, "### %s\n\n", strings.Join(heading(msg, "`"), " · "))
+		b.WriteString(strings.TrimRight(msg.Content, "\n") + "\n")
+		if len(msg.Images) > 0 {
+			b.WriteString("\nAttached: `" + strings.Join(msg.Images, "`, `") + "`\n")
+		}
+		if chunks := contextChunks(msg); len(chunks) > 0 {
+			b.WriteString("\n<sub>Context: `" + strings.Join(chunks, "`, `") + "`</sub>\n")
+		}
+	}
+	return b.String()
+}
+
+// renderHTML renders the conversation as a standalone HTML page. Markdown
+// in messages is rendered; raw HTML in them is left out.
+func renderHTML(c Conversation) ([]byte, error) {
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	var b bytes.Buffer
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n<style>%s</style>\n</head>\n<body>\n", html.EscapeString(title(c)), pageStyle)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<p class=\"summary\">%s</p>\n", html.EscapeString(title(c)), escapeJoin(summary(c, "")))
+
+	for _, msg := range c.Messages {
+		if msg.Role == "tool" {
+			name, status := toolLabel(msg)
+			fmt.Fprintf(&b, "<details class=\"tool\">\n<summary>🔧 %s · %s</summary>\n", html.EscapeString(name), html.EscapeString(status))
+			if content := strings.TrimRight(msg.Content, "\n"); content != "" {
+				fmt.Fprintf(&b, "<pre><code>%s</code></pre>\n", html.EscapeString(content))
+			}
+			b.WriteString("</details>\n")
+			continue
+		}
+
+		fmt.Fprintf(&b, "<section class=\"message %s\">\n<h3>%s</h3>\n", html.EscapeString(msg.Role), escapeJoin(heading(msg, "")))
+		if err := md.Convert([]byte(msg.Content), &b); err != nil {
+			return nil, fmt.Errorf("failed to render message: %w", err)
+		}
+		if len(msg.Images) > 0 {
+			fmt.Fprintf(&b, "<p class=\"attached\">Attached: %s</p>\n", escapeJoin(msg.Images))
+		}
+		if chunks := contextChunks(msg); len(chunks) > 0 {
+			fmt.Fprintf(&b, "<p class=\"context\">Context: %s</p>\n", escapeJoin(chunks))
+		}
+		b.WriteString("</section>\n")
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	return b.Bytes(), nil
+}
+
+// pageStyle keeps the HTML page readable without external assets
+const pageStyle = `
+body { font-family: system-ui, sans-serif; max-width: 50rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; color: #222; }
+h3 { font-size: 0.95rem; color: #555; margin-bottom: 0.25rem; }
+.summary, .context, .attached { color: #666; font-size: 0.85rem; }
+.message { border-left: 3px solid #ddd; padding-left: 1rem; margin: 1.5rem 0; }
+.message.user { border-color: #4a90d9; }
+.message.assistant { border-color: #e0a030; }
+details.tool { margin: 0.5rem 0; padding: 0.25rem 0.75rem; background: #f6f6f6; border-radius: 4px; }
+pre { background: #f0f0f0; padding: 0.75rem; overflow-x: auto; }
+code { font-size: 0.9em; }
+`
+
+// title is the document title
+func title(c Conversation) string {
+	if c.Title != "" {
+		return c.Title
+	}
+	return "Loco conversation"
+}
+
+// summary describes the session under the title, with code set in quote
+func summary(c Conversation, quote string) []string {
+	var parts []string
+	if c.SessionID != "" {
+		parts = append(parts, "Session "+quote+c.SessionID+quote)
+	}
+	if !c.Created.IsZero() {
+		parts = append(parts, "started "+c.Created.Format("Jan 2, 2006 15:04"))
+	}
+	if c.Model != "" {
+		parts = append(parts, "model "+quote+c.Model+quote)
+	}
+	return append(parts, "exported "+time.Now().Format("Jan 2, 2006 15:04"))
+}
+
+// heading names who wrote a message, when, and with which model
+func heading(msg llm.Message, quote string) []string {
+	var parts []string
+	switch msg.Role {
+	case "user":
+		parts = append(parts, "👤 You")
+	case "assistant":
+		parts = append(parts, "🤖 Assistant")
+	default:
+		parts = append(parts, "ℹ️ System")
+	}
+	if !msg.Time.IsZero() {
+		parts = append(parts, msg.Time.Format("Jan 2 15:04:05"))
+	}
+	if msg.Metadata != nil && msg.Metadata.Model != "" {
+		parts = append(parts, quote+msg.Metadata.Model+quote)
+	}
+	return parts
+}
+
+// toolLabel returns a tool block's tool name and how it went
+func toolLabel(msg llm.Message) (string, string) {
+	name, status := "tool", "done"
+	if exec := msg.ToolExecution; exec != nil {
+		name = exec.Name
+		if exec.Status != "" {
+			status = exec.Status
+		}
+		if exec.Progress != "" {
+			status += " — " + exec.Progress
+		}
+	}
+	if !msg.Time.IsZero() {
+		status += " · " + msg.Time.Format("15:04:05")
+	}
+	return name, status
+}
+
+// contextChunks lists the code given to the model for a reply
+func contextChunks(msg llm.Message) []string {
+	if msg.Metadata == nil {
+		return nil
+	}
+	var chunks []string
+	for _, chunk := range msg.Metadata.ContextChunks {
+		location := chunk.Path
+		if chunk.StartLine > 0 {
+			location += fmt.Sprintf(":%d-%d", chunk.StartLine, chunk.EndLine)
+		}
+		if chunk.Symbol != "" {
+			location += " (" + chunk.Symbol + ")"
+		}
+		chunks = append(chunks, location)
+	}
+	return chunks
+}
+
+// codeFence returns a backtick fence longer than any run of backticks in
+// content, so the content can't close it
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// escapeJoin escapes parts for HTML and joins them with middle dots
+func escapeJoin(parts []string) string {
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = html.EscapeString(part)
+	}
+	return strings.Join(escaped, " · ")
+}
@@ -0,0 +1,196 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gitCacheTTL is how long a git answer is reused. Commits, checkouts and
+// staging invalidate it sooner; edits to the work tree show up once it
+// expires.
+const gitCacheTTL = 5 * time.Second
+
+// ErrGitNotInstalled is returned when there is no git executable in PATH
+var ErrGitNotInstalled = errors.New("git is not installed")
+
+// gitPath finds the git executable once; on Windows this also settles
+// git.exe versus git.cmd
+var gitPath = sync.OnceValues(func() (string, error) {
+	path, err := exec.LookPath("git")
+	if err != nil {
+		return "", ErrGitNotInstalled
+	}
+	return path, nil
+})
+
+// repo is a git work tree
+type repo struct {
+	root      string // Top level of the work tree
+	gitDir    string // Its .git directory, or .git/worktrees/<name> for a linked worktree
+	commonDir string // Where refs live, shared by all of a repository's worktrees
+}
+
+// gitCall is one git invocation's cached answer. Callers asking while it
+// runs wait for it rather than starting git again.
+type gitCall struct {
+	done  chan struct{}
+	out   []byte
+	err   error
+	at    time.Time
+	stamp string // Repository state the answer was read in
+}
+
+// gitCache holds the repositories found per directory and recent answers
+var gitCache = struct {
+	sync.Mutex
+	repos map[string]*repo     // Directory -> its work tree
+	seen  map[string]time.Time // When a directory was found not to be in one
+	calls map[string]*gitCall  // Directory and arguments -> answer
+}{
+	repos: make(map[string]*repo),
+	seen:  make(map[string]time.Time),
+	calls: make(map[string]*gitCall),
+}
+
+// RepoRoot returns the top level of the git work tree containing dir. A
+// directory found not to be in one is checked again after a few seconds,
+// so `git init` during a session is picked up.
+func RepoRoot(ctx context.Context, dir string) (string, error) {
+	r, err := findRepo(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	return r.root, nil
+}
+
+func findRepo(ctx context.Context, dir string) (*repo, error) {
+	gitCache.Lock()
+	if r, ok := gitCache.repos[dir]; ok {
+		gitCache.Unlock()
+		return r, nil
+	}
+	if at, ok := gitCache.seen[dir]; ok && time.Since(at) < gitCacheTTL {
+		gitCache.Unlock()
+		return nil, fmt.Errorf("%s is not in a git repository", dir)
+	}
+	gitCache.Unlock()
+
+	out, err := runGit(ctx, dir, "rev-parse", "--show-toplevel", "--absolute-git-dir", "--git-common-dir")
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if err != nil || len(lines) != 3 {
+		gitCache.Lock()
+		gitCache.seen[dir] = time.Now()
+		gitCache.Unlock()
+		if err == nil || errors.Is(err, ErrGitNotInstalled) {
+			err = fmt.Errorf("%s is not in a git repository", dir)
+		}
+		return nil, err
+	}
+
+	// git prints forward slashes on every platform, and the common dir
+	// relative to dir when it is the usual .git
+	r := &repo{
+		root:      filepath.FromSlash(lines[0]),
+		gitDir:    filepath.FromSlash(lines[1]),
+		commonDir: filepath.FromSlash(lines[2]),
+	}
+	if !filepath.IsAbs(r.commonDir) {
+		r.commonDir = filepath.Join(dir, r.commonDir)
+	}
+	gitCache.Lock()
+	gitCache.repos[dir] = r
+	delete(gitCache.seen, dir)
+	gitCache.Unlock()
+	return r, nil
+}
+
+// stamp fingerprints what git keeps about the work tree's state: the
+// index, which staging changes, and HEAD with the branch it points at, which
+// commits and checkouts change. A linked worktree has its own index and
+// HEAD but shares branches with the main one.
+func (r *repo) stamp() string {
+	var b strings.Builder
+	for _, name := range []string{"index", "HEAD"} {
+		if info, err := os.Stat(filepath.Join(r.gitDir, name)); err == nil {
+			fmt.Fprintf(&b, "%d:%d;", info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	if head, err := os.ReadFile(filepath.Join(r.gitDir, "HEAD")); err == nil {
+		if ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: "); ok {
+			if info, err := os.Stat(filepath.Join(r.commonDir, filepath.FromSlash(ref))); err == nil {
+				fmt.Fprintf(&b, "%d:%d", info.ModTime().UnixNano(), info.Size())
+			}
+		}
+	}
+	return b.String()
+}
+
+// Git runs a read-only git command in dir and returns its output. Answers
+// are shared between concurrent callers and reused for a few seconds, until
+// the repository's index or HEAD changes. Commands that change the
+// repository must not go through here.
+func Git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	r, err := findRepo(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	stamp := r.stamp()
+	key := dir + "\x00" + strings.Join(args, "\x00")
+
+	gitCache.Lock()
+	if call, ok := gitCache.calls[key]; ok {
+		select {
+		case <-call.done:
+			if call.err == nil && call.stamp == stamp && time.Since(call.at) < gitCacheTTL {
+				gitCache.Unlock()
+				return call.out, nil
+			}
+		default:
+			gitCache.Unlock()
+			<-call.done
+			return call.out, call.err
+		}
+	}
+	call := &gitCall{done: make(chan struct{}), stamp: stamp}
+	gitCache.calls[key] = call
+	gitCache.Unlock()
+
+	call.out, call.err = runGit(ctx, dir, args...)
+	call.at = time.Now()
+	close(call.done)
+	return call.out, call.err
+}
+
+// InvalidateGit drops the cached git answers, for when files are known to
+// have changed
+func InvalidateGit() {
+	gitCache.Lock()
+	defer gitCache.Unlock()
+	clear(gitCache.calls)
+}
+
+// runGit runs git in dir and returns stdout, with git's message on failure
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	path, err := gitPath()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			err = fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return out, nil
+}
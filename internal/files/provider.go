@@ -0,0 +1,201 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/billie-coop/loco/internal/config"
+)
+
+// Provider lists a project's files and fingerprints their state. Files are
+// named by slash-separated paths relative to Root on every platform.
+type Provider interface {
+	// Root is the directory the files are listed from
+	Root() string
+	// Files lists the project's files
+	Files(ctx context.Context) ([]string, error)
+	// StateHash changes whenever a file is added, removed or edited
+	StateHash(ctx context.Context) (string, error)
+}
+
+// ForDir returns the provider for the project in dir: git when dir is in a
+// git work tree and git is installed, otherwise a filesystem walk. The
+// project's .loco config, if it has one, says whether submodules count.
+func ForDir(ctx context.Context, dir string) Provider {
+	if _, err := findRepo(ctx, dir); err == nil {
+		return &GitProvider{root: dir, submodules: projectConfig(dir).Files.Submodules}
+	}
+	return &WalkProvider{root: dir}
+}
+
+// projectConfig reads the config of the project in dir without creating
+// one where loco hasn't been set up
+func projectConfig(dir string) *config.Config {
+	if _, err := os.Stat(filepath.Join(dir, ".loco")); err != nil {
+		return config.DefaultConfig()
+	}
+	manager := config.NewManager(dir)
+	if err := manager.Load(); err != nil {
+		return config.DefaultConfig()
+	}
+	return manager.Get()
+}
+
+// GitProvider lists what git knows about: tracked files plus untracked ones
+// that aren't ignored. Files a sparse checkout leaves out and submodules
+// themselves are skipped; with submodules set, the files checked out in
+// them are listed instead.
+type GitProvider struct {
+	root       string
+	submodules bool
+}
+
+// Root returns the directory the files are listed from
+func (p *GitProvider) Root() string {
+	return p.root
+}
+
+// Files lists the tracked and untracked, unignored files under Root
+func (p *GitProvider) Files(ctx context.Context) ([]string, error) {
+	// -z lists names with spaces or non-ASCII characters as they are,
+	// rather than quoted and escaped. -t tags each entry so skip-worktree
+	// ones (S) can be dropped, and --stage gives the mode that marks a
+	// submodule (160000). git can only recurse into submodules for tracked
+	// files, so untracked ones are listed separately.
+	args := []string{"ls-files", "-z", "-t", "--stage"}
+	if p.submodules {
+		args = append(args, "--recurse-submodules")
+	}
+	tracked, err := p.git(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project files: %w", err)
+	}
+	untracked, err := p.git(ctx, "ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project files: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, entry := range bytes.Split(tracked, []byte{0}) {
+		// "<tag> <mode> <object> <stage>\t<path>"
+		meta, name, ok := bytes.Cut(entry, []byte{'\t'})
+		if !ok || bytes.HasPrefix(meta, []byte("S ")) || bytes.Contains(meta, []byte(" 160000 ")) {
+			continue
+		}
+		// Conflicted files have an entry per stage
+		if !seen[string(name)] {
+			seen[string(name)] = true
+			files = append(files, string(name))
+		}
+	}
+	for _, name := range bytes.Split(untracked, []byte{0}) {
+		if len(name) > 0 && !seen[string(name)] {
+			seen[string(name)] = true
+			files = append(files, string(name))
+		}
+	}
+	return files, nil
+}
+
+// StateHash hashes the work tree's status together with the HEAD commit
+func (p *GitProvider) StateHash(ctx context.Context) (string, error) {
+	status, err := p.git(ctx, "status", "--porcelain")
+	if err != nil {
+		return "", fmt.Errorf("git status failed: %w", err)
+	}
+	head, err := p.git(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		// A repository without commits has no HEAD yet
+		head = []byte("no-head")
+	}
+
+	h := sha256.New()
+	h.Write(status)
+	h.Write(head)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (p *GitProvider) git(ctx context.Context, args ...string) ([]byte, error) {
+	return Git(ctx, p.root, args...)
+}
+
+// WalkProvider lists the files of a directory that isn't under version
+// control, leaving out the directories ShouldIgnore names
+type WalkProvider struct {
+	root string
+}
+
+// NewWalkProvider returns a provider walking dir
+func NewWalkProvider(dir string) *WalkProvider {
+	return &WalkProvider{root: dir}
+}
+
+// Root returns the directory the files are listed from
+func (p *WalkProvider) Root() string {
+	return p.root
+}
+
+// Files lists the regular files under Root, skipping unreadable entries
+func (p *WalkProvider) Files(ctx context.Context) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(p.root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil || path == p.root {
+			return nil
+		}
+		if d.IsDir() {
+			if ShouldIgnore(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if rel, err := filepath.Rel(p.root, path); err == nil {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return files, err
+}
+
+// StateHash hashes every file's name and content, so it changes on any
+// edit without version control to ask
+func (p *WalkProvider) StateHash(ctx context.Context) (string, error) {
+	files, err := p.Files(ctx)
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, file := range files {
+		fmt.Fprintf(h, "%s\x00%s\x00", file, ContentHash(filepath.Join(p.root, filepath.FromSlash(file))))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ContentHash returns the id git gives a file's content as a blob, so it
+// matches `git hash-object`, or "missing" when the file cannot be read
+func ContentHash(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "missing"
+	}
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(data))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 
+	"github.com/billie-coop/loco/internal/crash"
 	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/redact"
 	"github.com/billie-coop/loco/internal/session"
 )
 
@@ -38,6 +41,11 @@ func NewManager(projectPath string, team *session.ModelTeam) *Manager {
 	}
 }
 
+// SetRedactor scrubs secrets from what the manager sends its model.
+func (m *Manager) SetRedactor(r *redact.Redactor) {
+	m.llmClient.SetRedactor(r)
+}
+
 // Initialize sets up the knowledge directory.
 func (m *Manager) Initialize() error {
 	// Create knowledge directory
@@ -64,6 +72,36 @@ func (m *Manager) GetFile(filename string) (string, error) {
 	return string(content), nil
 }
 
+// ListFiles returns the markdown knowledge files as slash paths relative to
+// the knowledge directory, e.g. deep/overview.md, in lexical order.
+func (m *Manager) ListFiles() ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var files []string
+	err := filepath.WalkDir(m.basePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+		rel, err := filepath.Rel(m.basePath, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge files: %w", err)
+	}
+	return files, nil
+}
+
 // HasInfo quickly checks if information might exist in knowledge files.
 func (m *Manager) HasInfo(query string) bool {
 	m.mu.RLock()
@@ -100,6 +138,7 @@ func (m *Manager) QueueUpdate(filename string, discoveries ...string) {
 
 // updateWorker processes knowledge updates in the background.
 func (m *Manager) updateWorker() {
+	defer crash.Recover("knowledge updates")
 	for req := range m.updateChan {
 		m.processUpdate(req)
 	}
@@ -0,0 +1,182 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AdaptiveLimit is a concurrency limit that follows how LM Studio copes.
+// It halves when the server rejects requests (429/503), steps down when
+// requests time out or latency climbs well above the best seen, and steps
+// back up after a round of fast successes. Latency is judged per model, as
+// a large model is always slower than a small one. Feed it with
+// ObserveRequests.
+type AdaptiveLimit struct {
+	mu       sync.Mutex
+	level    int
+	max      int
+	active   int
+	wake     chan struct{} // Closed and replaced when a slot may be free
+	onChange func(level, max int, reason string)
+
+	latency   map[string]time.Duration // Moving average of successful requests per model
+	baseline  map[string]time.Duration // Lowest average seen per model
+	successes int                      // Successful requests since the last change
+}
+
+// slowFactor is how far the average latency may rise above the baseline
+// before the limit steps down
+const slowFactor = 2
+
+// NewAdaptiveLimit creates a limit starting at level, never above ceiling
+func NewAdaptiveLimit(level, ceiling int) *AdaptiveLimit {
+	ceiling = max(ceiling, 1)
+	return &AdaptiveLimit{
+		level:    min(max(level, 1), ceiling),
+		max:      ceiling,
+		wake:     make(chan struct{}),
+		latency:  make(map[string]time.Duration),
+		baseline: make(map[string]time.Duration),
+	}
+}
+
+// OnChange sets a callback for level changes. It runs without the lock
+// held, so it may call Level.
+func (l *AdaptiveLimit) OnChange(fn func(level, max int, reason string)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onChange = fn
+}
+
+// Level returns the current limit and its ceiling
+func (l *AdaptiveLimit) Level() (level, ceiling int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level, l.max
+}
+
+// Acquire waits for a slot under the current limit
+func (l *AdaptiveLimit) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.active < l.level {
+			l.active++
+			l.mu.Unlock()
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (l *AdaptiveLimit) Release() {
+	l.mu.Lock()
+	l.active--
+	l.wakeLocked()
+	l.mu.Unlock()
+}
+
+// Observe adjusts the limit after a request finished
+func (l *AdaptiveLimit) Observe(info RequestInfo) {
+	l.mu.Lock()
+	before := l.level
+	reason := l.adjustLocked(info)
+	level, ceiling, onChange := l.level, l.max, l.onChange
+	if level > before {
+		l.wakeLocked()
+	}
+	l.mu.Unlock()
+
+	if level != before && onChange != nil {
+		onChange(level, ceiling, reason)
+	}
+}
+
+// adjustLocked applies one observation and says why the level changed
+func (l *AdaptiveLimit) adjustLocked(info RequestInfo) string {
+	switch {
+	case info.Status == http.StatusTooManyRequests || info.Status == http.StatusServiceUnavailable:
+		l.successes = 0
+		if l.level > 1 {
+			l.level = max(l.level/2, 1)
+			return fmt.Sprintf("LM Studio returned %d", info.Status)
+		}
+	case info.Error != "" && info.Status == 0:
+		// The request never got an answer; a timeout means too much load
+		if errors.Is(info.err, context.DeadlineExceeded) && l.level > 1 {
+			l.successes = 0
+			l.level--
+			return "request timed out"
+		}
+	case info.Error == "" && info.Status < 300 && info.Duration > 0:
+		latency := info.Duration
+		if previous := l.latency[info.Model]; previous > 0 {
+			latency = (previous*7 + info.Duration*3) / 10
+		}
+		l.latency[info.Model] = latency
+		baseline := l.baseline[info.Model]
+		if baseline == 0 || latency < baseline {
+			baseline = latency
+			l.baseline[info.Model] = baseline
+		}
+		if latency > baseline*slowFactor && l.level > 1 {
+			l.successes = 0
+			l.level--
+			// Let the average settle at the new level before judging again
+			l.latency[info.Model] = baseline
+			return fmt.Sprintf("latency up to %s", info.Duration.Round(100*time.Millisecond))
+		}
+		if latency > baseline*3/2 {
+			// Not slow enough to back off, not fast enough to grow
+			l.successes = 0
+			return ""
+		}
+		l.successes++
+		if l.successes >= 2*l.level && l.level < l.max {
+			l.successes = 0
+			l.level++
+			return "requests are fast"
+		}
+	}
+	return ""
+}
+
+// wakeLocked wakes every Acquire waiting for a slot
+func (l *AdaptiveLimit) wakeLocked() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+var requestObservers struct {
+	sync.Mutex
+	fns []func(RequestInfo)
+}
+
+// ObserveRequests calls fn after every chat request to the model server
+// finishes, with its latency, status and error
+func ObserveRequests(fn func(RequestInfo)) {
+	requestObservers.Lock()
+	defer requestObservers.Unlock()
+	requestObservers.fns = append(requestObservers.fns, fn)
+}
+
+// notifyObservers passes a finished request to every observer
+func notifyObservers(info RequestInfo) {
+	requestObservers.Lock()
+	fns := append([]func(RequestInfo){}, requestObservers.fns...)
+	requestObservers.Unlock()
+	for _, fn := range fns {
+		fn(info)
+	}
+}
@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// Cassette modes
+const (
+	CassetteRecord = "record" // Call the model and save every request/response pair
+	CassetteReplay = "replay" // Answer from saved pairs; never call the model
+)
+
+// ErrNotRecorded is returned in replay mode for a request no cassette holds
+var ErrNotRecorded = errors.New("request not recorded")
+
+// Cassette records LM Studio requests and their responses to a directory,
+// one file per request named by its hash, and replays them. Replaying a
+// recorded analysis run reproduces it exactly with no model loaded, and a
+// bug report can ship the cassettes that trigger the bug.
+type Cassette struct {
+	dir  string
+	mode string
+}
+
+// cassetteEntry is one recorded request/response pair
+type cassetteEntry struct {
+	Hash     string           `json:"hash"`
+	Recorded time.Time        `json:"recorded"`
+	Model    string           `json:"model,omitempty"`
+	Stream   bool             `json:"stream"`
+	Options  *CompleteOptions `json:"options,omitempty"`
+	Messages []Message        `json:"messages"`
+	Response string           `json:"response,omitempty"` // Complete
+	Chunks   []string         `json:"chunks,omitempty"`   // Stream, as the server sent them
+}
+
+// NewCassette creates a cassette in dir with mode CassetteRecord or
+// CassetteReplay
+func NewCassette(dir, mode string) (*Cassette, error) {
+	if mode != CassetteRecord && mode != CassetteReplay {
+		return nil, fmt.Errorf("invalid cassette mode %q (use %s or %s)", mode, CassetteRecord, CassetteReplay)
+	}
+	return &Cassette{dir: dir, mode: mode}, nil
+}
+
+var activeCassette atomic.Pointer[Cassette]
+
+// UseCassette routes every LM Studio chat request through c; nil turns
+// recording and replay off
+func UseCassette(c *Cassette) {
+	activeCassette.Store(c)
+}
+
+// RequestHash identifies a request by what the model is asked: the role and
+// content of each message and whether it streams. The model and sampling
+// options are left out so a run replays on whatever team is loaded, or
+// none.
+func RequestHash(messages []Message, stream bool) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "stream=%t\x00", stream)
+	for _, msg := range messages {
+		fmt.Fprintf(h, "%s\x00%s\x00", msg.Role, msg.Content)
+		for _, call := range msg.ToolCalls {
+			fmt.Fprintf(h, "%s\x00%s\x00", call.Name, call.Parameters)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replaying reports whether requests are answered from the cassette
+func (c *Cassette) replaying() bool {
+	return c != nil && c.mode == CassetteReplay
+}
+
+// recording reports whether responses are saved to the cassette
+func (c *Cassette) recording() bool {
+	return c != nil && c.mode == CassetteRecord
+}
+
+// load returns the recorded pair for a request
+func (c *Cassette) load(messages []Message, stream bool) (*cassetteEntry, error) {
+	hash := RequestHash(messages, stream)
+	data, err := os.ReadFile(c.path(hash))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotRecorded, hash)
+		}
+		return nil, err
+	}
+	var entry cassetteEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", hash, err)
+	}
+	return &entry, nil
+}
+
+// save writes a pair, replacing an earlier recording of the same request.
+// Failing to record never fails the request.
+func (c *Cassette) save(entry cassetteEntry) {
+	entry.Hash = RequestHash(entry.Messages, entry.Stream)
+	entry.Recorded = time.Now()
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return
+	}
+	tmp := c.path(entry.Hash) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, c.path(entry.Hash))
+}
+
+// path is where the pair with the given hash is stored
+func (c *Cassette) path(hash string) string {
+	return filepath.Join(c.dir, hash+".json")
+}
@@ -10,6 +10,9 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/redact"
 )
 
 // Message represents a chat message.
@@ -18,9 +21,84 @@ type Message struct {
 	Content   string     `json:"content"`
 	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 
+	// Image files attached to a user message, sent to vision models as
+	// image content
+	Images []string `json:"images,omitempty"`
+
 	// For tool execution messages (role="tool")
 	// This is a temporary solution - should be moved to a separate type
 	ToolExecution *ToolExecution `json:"tool_execution,omitempty"`
+
+	// Bookkeeping about how the message was produced (not model input)
+	Metadata *MessageMetadata `json:"metadata,omitempty"`
+
+	// When the message joined the conversation
+	Time time.Time `json:"time,omitzero"`
+}
+
+// MessageMetadata records how an assistant message was produced
+type MessageMetadata struct {
+	ContextChunks []ContextChunk `json:"context_chunks,omitempty"` // RAG chunks given to the model
+	Model         string         `json:"model,omitempty"`          // Model that wrote the message
+	Route         string         `json:"route,omitempty"`          // Kind of request it answered, when routed other than as chat
+	Review        []string       `json:"review,omitempty"`         // Problems a second model found in it before it was shown
+	Reviewer      string         `json:"reviewer,omitempty"`       // Model that reviewed it
+	Citations     []Citation     `json:"citations,omitempty"`      // Files it refers to, checked against the project
+	Explanation   *Explanation   `json:"explanation,omitempty"`    // Set on /explain answers, which render as an explanation card
+	Feedback      string         `json:"feedback,omitempty"`       // Set on system notices the model should read too, such as test results
+}
+
+// Explanation is a structured account of a region of code
+type Explanation struct {
+	Path      string          `json:"path"`
+	StartLine int             `json:"start_line"`
+	EndLine   int             `json:"end_line"`
+	Summary   string          `json:"summary"`
+	Steps     []string        `json:"steps,omitempty"` // What the code does, in order
+	Notes     []string        `json:"notes,omitempty"` // Assumptions, side effects and pitfalls
+	Callers   []CodeReference `json:"callers,omitempty"`
+	Callees   []CodeReference `json:"callees,omitempty"`
+}
+
+// CodeReference is code related to an explained region
+type CodeReference struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Symbol    string `json:"symbol,omitempty"`
+	Note      string `json:"note,omitempty"` // How it relates to the region
+}
+
+// Location renders the reference as path:start-end
+func (r CodeReference) Location() string {
+	if r.StartLine > 0 {
+		return fmt.Sprintf("%s:%d-%d", r.Path, r.StartLine, r.EndLine)
+	}
+	return r.Path
+}
+
+// Citation statuses
+const (
+	CitationValid     = "valid"     // Names a project file
+	CitationCorrected = "corrected" // Named a file that doesn't exist, and was changed to the one meant
+	CitationMissing   = "missing"   // Names no project file
+)
+
+// Citation is a file reference in an assistant message
+type Citation struct {
+	Text       string `json:"text"`                 // As the model wrote it
+	Path       string `json:"path,omitempty"`       // The project file it names, when exactly one matches
+	Suggestion string `json:"suggestion,omitempty"` // For a missing reference, the file probably meant
+	Status     string `json:"status"`
+}
+
+// ContextChunk identifies one retrieved code chunk
+type ContextChunk struct {
+	Path      string  `json:"path"`
+	StartLine int     `json:"start_line,omitempty"`
+	EndLine   int     `json:"end_line,omitempty"`
+	Symbol    string  `json:"symbol,omitempty"`
+	Score     float32 `json:"score"`
 }
 
 // ToolExecution represents tool execution details
@@ -55,8 +133,9 @@ type LMStudioClient struct {
 	client      *http.Client
 	baseURL     string
 	model       string
-	contextSize int // default n_ctx to send
-	numKeep     int // default n_keep to send
+	contextSize int              // default n_ctx to send
+	numKeep     int              // default n_keep to send
+	redactor    *redact.Redactor // scrubs secrets from requests; nil sends them as they are
 }
 
 // NewLMStudioClient creates a new LM Studio client.
@@ -99,12 +178,23 @@ func (c *LMStudioClient) Complete(ctx context.Context, messages []Message) (stri
 
 // CompleteWithOptions sends messages with custom options and returns the full response.
 func (c *LMStudioClient) CompleteWithOptions(ctx context.Context, messages []Message, opts CompleteOptions) (string, error) {
+	messages = c.redactMessages(messages)
+	cassette := activeCassette.Load()
+	if cassette.replaying() {
+		entry, err := cassette.load(messages, false)
+		if err != nil {
+			return "", err
+		}
+		return entry.Response, nil
+	}
+
 	payload := map[string]interface{}{
-		"messages":    messages,
+		"messages":    chatMessages(messages),
 		"temperature": opts.Temperature,
 		"max_tokens":  opts.MaxTokens,
 		"stream":      false,
 	}
+	applyDeterminism(ctx, payload, &opts)
 
 	// Add model if specified
 	if c.model != "" {
@@ -130,11 +220,16 @@ func (c *LMStudioClient) CompleteWithOptions(ctx context.Context, messages []Mes
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	contextSize, _ := payload["n_ctx"].(int)
+	track := trackRequest(req.URL.String(), c.model, messages, false, contextSize)
+	defer track.done()
 	resp, err := c.client.Do(req)
 	if err != nil {
+		track.finish(0, err)
 		return "", err
 	}
 	defer resp.Body.Close()
+	track.finish(resp.StatusCode, nil)
 
 	if resp.StatusCode != http.StatusOK {
 		data, _ := io.ReadAll(resp.Body)
@@ -153,21 +248,46 @@ func (c *LMStudioClient) CompleteWithOptions(ctx context.Context, messages []Mes
 		return "", errors.New("no choices returned")
 	}
 
-	return result.Choices[0].Message.Content, nil
+	content := result.Choices[0].Message.Content
+	if cassette.recording() {
+		cassette.save(cassetteEntry{Model: c.model, Options: &opts, Messages: messages, Response: content})
+	}
+	return content, nil
 }
 
 // Stream streams the response from the LLM.
 func (c *LMStudioClient) Stream(ctx context.Context, messages []Message, onChunk func(string)) error {
+	return c.StreamWithOptions(ctx, messages, DefaultCompleteOptions(), onChunk)
+}
+
+// StreamWithOptions streams the response with custom options.
+func (c *LMStudioClient) StreamWithOptions(ctx context.Context, messages []Message, opts CompleteOptions, onChunk func(string)) error {
+	messages = c.redactMessages(messages)
+	cassette := activeCassette.Load()
+	if cassette.replaying() {
+		entry, err := cassette.load(messages, true)
+		if err != nil {
+			return err
+		}
+		for _, chunk := range entry.Chunks {
+			onChunk(chunk)
+		}
+		return nil
+	}
+
 	payload := map[string]interface{}{
-		"messages":    messages,
-		"temperature": 0.7,
-		"max_tokens":  -1,
+		"messages":    chatMessages(messages),
+		"temperature": opts.Temperature,
+		"max_tokens":  opts.MaxTokens,
 		"stream":      true,
 	}
+	applyDeterminism(ctx, payload, &opts)
 	if c.model != "" {
 		payload["model"] = c.model
 	}
-	if c.contextSize > 0 {
+	if opts.ContextSize > 0 {
+		payload["n_ctx"] = opts.ContextSize
+	} else if c.contextSize > 0 {
 		payload["n_ctx"] = c.contextSize
 	}
 	if c.numKeep > 0 {
@@ -182,17 +302,23 @@ func (c *LMStudioClient) Stream(ctx context.Context, messages []Message, onChunk
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	contextSize, _ := payload["n_ctx"].(int)
+	track := trackRequest(req.URL.String(), c.model, messages, true, contextSize)
+	defer track.done()
 	resp, err := c.client.Do(req)
 	if err != nil {
+		track.finish(0, err)
 		return err
 	}
 	defer resp.Body.Close()
+	track.finish(resp.StatusCode, nil)
 
 	if resp.StatusCode != http.StatusOK {
 		data, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("LM Studio returned status %d: %s", resp.StatusCode, string(data))
 	}
 
+	var chunks []string
 	reader := bufio.NewReader(resp.Body)
 	for {
 		line, err := reader.ReadBytes('\n')
@@ -205,9 +331,15 @@ func (c *LMStudioClient) Stream(ctx context.Context, messages []Message, onChunk
 		if len(line) == 0 {
 			continue
 		}
+		if cassette.recording() {
+			chunks = append(chunks, string(line))
+		}
 		onChunk(string(line))
 	}
 
+	if cassette.recording() {
+		cassette.save(cassetteEntry{Model: c.model, Stream: true, Messages: messages, Chunks: chunks})
+	}
 	return nil
 }
 
@@ -268,6 +400,39 @@ func (c *LMStudioClient) GetModels() ([]Model, error) {
 	return modelsResp.Data, nil
 }
 
+// LoadedModels returns the IDs of the models LM Studio has in memory, from
+// its native REST API (/api/v0/models). Older servers without that API
+// return an error.
+func (c *LMStudioClient) LoadedModels() ([]string, error) {
+	resp, err := c.client.Get(c.baseURL + "/api/v0/models")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LM Studio returned status %d", resp.StatusCode)
+	}
+
+	var modelsResp struct {
+		Data []struct {
+			ID    string `json:"id"`
+			State string `json:"state"` // "loaded" or "not-loaded"
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&modelsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode models response: %w", err)
+	}
+
+	var loaded []string
+	for _, model := range modelsResp.Data {
+		if model.State == "loaded" {
+			loaded = append(loaded, model.ID)
+		}
+	}
+	return loaded, nil
+}
+
 // SetModel sets the model to use for completions.
 func (c *LMStudioClient) SetModel(modelID string) {
 	c.model = modelID
@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Server is a local model server with an OpenAI-compatible API
+type Server struct {
+	Name   string  // "LM Studio" or "Ollama"
+	URL    string  // Base URL, without /v1
+	Models []Model // Models it serves, filled in by DetectServers
+}
+
+// LocalServers are the servers looked for on first run, at their default ports
+var LocalServers = []Server{
+	{Name: "LM Studio", URL: "http://localhost:1234"},
+	{Name: "Ollama", URL: "http://localhost:11434"},
+}
+
+// detectTimeout bounds the wait for one server to list its models
+const detectTimeout = 2 * time.Second
+
+// DetectServers asks each candidate for its models and returns the ones that
+// answer, in the candidates' order
+func DetectServers(ctx context.Context, candidates []Server) []Server {
+	found := make([]*Server, len(candidates))
+	var wg sync.WaitGroup
+	for i, candidate := range candidates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			models, err := listModels(ctx, candidate.URL)
+			if err != nil {
+				return
+			}
+			candidate.Models = models
+			found[i] = &candidate
+		}()
+	}
+	wg.Wait()
+
+	var servers []Server
+	for _, server := range found {
+		if server != nil {
+			servers = append(servers, *server)
+		}
+	}
+	return servers
+}
+
+// listModels fetches /v1/models from the server at baseURL
+func listModels(ctx context.Context, baseURL string) ([]Model, error) {
+	ctx, cancel := context.WithTimeout(ctx, detectTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/v1/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+
+	var modelsResp ModelsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&modelsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode models response: %w", err)
+	}
+	for i := range modelsResp.Data {
+		modelsResp.Data[i].Size = DetectModelSize(modelsResp.Data[i].ID)
+		modelsResp.Data[i].Name = modelsResp.Data[i].ID
+	}
+	return modelsResp.Data, nil
+}
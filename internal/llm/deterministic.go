@@ -0,0 +1,33 @@
+package llm
+
+import "context"
+
+// deterministicSeed is the sampling seed sent with deterministic requests
+const deterministicSeed = 42
+
+// deterministicKey marks a context whose requests should be repeatable
+type deterministicKey struct{}
+
+// WithDeterministic returns a context whose completions run at temperature
+// 0 with a fixed seed, so the same prompt to the same model gives the same
+// answer whatever options the caller passes
+func WithDeterministic(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deterministicKey{}, true)
+}
+
+// IsDeterministic reports whether ctx asks for repeatable completions
+func IsDeterministic(ctx context.Context) bool {
+	on, _ := ctx.Value(deterministicKey{}).(bool)
+	return on
+}
+
+// applyDeterminism pins the sampling of a request payload when ctx asks for
+// repeatable completions
+func applyDeterminism(ctx context.Context, payload map[string]interface{}, opts *CompleteOptions) {
+	if !IsDeterministic(ctx) {
+		return
+	}
+	opts.Temperature = 0
+	payload["temperature"] = 0.0
+	payload["seed"] = deterministicSeed
+}
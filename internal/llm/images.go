@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// imageTypes maps the image extensions that can be attached to their MIME
+// types
+var imageTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// IsImage reports whether path names an image file that can be attached
+func IsImage(path string) bool {
+	_, ok := imageTypes[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// chatMessages returns messages as sent to LM Studio: a message with images
+// gets OpenAI's content parts, its text followed by each image as a data
+// URL. An image that can't be read goes in as a note instead.
+func chatMessages(messages []Message) []any {
+	wire := make([]any, len(messages))
+	for i, msg := range messages {
+		if len(msg.Images) == 0 {
+			wire[i] = msg
+			continue
+		}
+
+		parts := []map[string]any{{"type": "text", "text": msg.Content}}
+		for _, path := range msg.Images {
+			url, err := imageDataURL(path)
+			if err != nil {
+				parts = append(parts, map[string]any{
+					"type": "text",
+					"text": fmt.Sprintf("[Image %s could not be attached: %v]", filepath.Base(path), err),
+				})
+				continue
+			}
+			parts = append(parts, map[string]any{
+				"type":      "image_url",
+				"image_url": map[string]string{"url": url},
+			})
+		}
+		wire[i] = map[string]any{"role": msg.Role, "content": parts}
+	}
+	return wire
+}
+
+// imageDataURL reads an image file into a base64 data URL
+func imageDataURL(path string) (string, error) {
+	mime, ok := imageTypes[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return "", fmt.Errorf("not a supported image type")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
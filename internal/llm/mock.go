@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockResponse is one scripted reply of the mock client
+type MockResponse struct {
+	Match    string `json:"match"`    // Text the last user message must contain; empty matches any
+	Response string `json:"response"` // What the model "says"
+}
+
+// MockClient is a Client that answers from a script instead of a model, for
+// deterministic tests and offline demos. Each request gets the first
+// scripted response whose Match the last user message contains; without one
+// it gets a canned reply shaped like what the prompt asks for, so analysis
+// can run end to end.
+type MockClient struct {
+	responses []MockResponse
+	latency   time.Duration
+
+	mu    sync.Mutex
+	calls [][]Message
+}
+
+// NewMockClient creates a mock client. Every request takes latency, spread
+// over the chunks when streamed.
+func NewMockClient(responses []MockResponse, latency time.Duration) *MockClient {
+	return &MockClient{responses: responses, latency: latency}
+}
+
+// LoadMockResponses reads a script: a JSON array of {"match", "response"}
+func LoadMockResponses(path string) ([]MockResponse, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var responses []MockResponse
+	if err := json.Unmarshal(data, &responses); err != nil {
+		return nil, fmt.Errorf("invalid mock responses %s: %w", path, err)
+	}
+	return responses, nil
+}
+
+// Complete returns the scripted response after the configured latency
+func (c *MockClient) Complete(ctx context.Context, messages []Message) (string, error) {
+	response := c.respond(messages)
+	select {
+	case <-time.After(c.latency):
+		return response, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Stream sends the scripted response a word at a time
+func (c *MockClient) Stream(ctx context.Context, messages []Message, onChunk func(string)) error {
+	words := strings.SplitAfter(c.respond(messages), " ")
+	delay := c.latency / time.Duration(max(1, len(words)))
+	for _, word := range words {
+		select {
+		case <-time.After(delay):
+			onChunk(word)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Calls returns the messages of every request so far, oldest first
+func (c *MockClient) Calls() [][]Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]Message(nil), c.calls...)
+}
+
+// respond records the request and picks its reply
+func (c *MockClient) respond(messages []Message) string {
+	c.mu.Lock()
+	c.calls = append(c.calls, messages)
+	c.mu.Unlock()
+
+	var system, user string
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			system = msg.Content
+		case "user":
+			user = msg.Content
+		}
+	}
+	for _, r := range c.responses {
+		if strings.Contains(user, r.Match) {
+			return r.Response
+		}
+	}
+	return cannedResponse(system, user)
+}
+
+// cannedResponse answers an unscripted prompt in the shape it asks for:
+// rankings of the listed files, a consensus of the crowd's paths, an empty
+// JSON object, or a short markdown note
+func cannedResponse(system, user string) string {
+	switch {
+	case strings.Contains(user, "JSON array") && strings.Contains(user, "FILES:"):
+		return mockRankings(sectionLines(user, "FILES:"))
+	case strings.Contains(user, "CROWD"):
+		var paths []string
+		for _, line := range sectionLines(user, "CROWD (condensed):") {
+			path, _, _ := strings.Cut(line, " • ")
+			paths = append(paths, path)
+		}
+		return fmt.Sprintf(`{"rankings":%s,"confidence":0.5}`, mockRankings(paths))
+	case strings.Contains(system, "JSON") || strings.Contains(user, "JSON response"):
+		return "{}"
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(user), "\n")
+	return fmt.Sprintf("# Mock response\n\nThis reply comes from the mock LLM client; no model was called.\n\nRequest: %s\n", first)
+}
+
+// mockRankings ranks the first ten paths in order, most important first
+func mockRankings(paths []string) string {
+	type ranking struct {
+		Path       string `json:"path"`
+		Importance int    `json:"importance"`
+		Reason     string `json:"reason"`
+		Category   string `json:"category"`
+	}
+	rankings := []ranking{}
+	for i, path := range paths[:min(10, len(paths))] {
+		rankings = append(rankings, ranking{Path: path, Importance: 10 - i, Reason: "Ranked in listed order by the mock client", Category: "other"})
+	}
+	data, _ := json.Marshal(rankings)
+	return string(data)
+}
+
+// sectionLines returns the non-empty lines after a heading up to the next
+// blank line
+func sectionLines(text, heading string) []string {
+	_, after, ok := strings.Cut(text, heading)
+	if !ok {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimLeft(after, "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
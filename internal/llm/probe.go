@@ -0,0 +1,250 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// ModelProbe describes what a model loaded in LM Studio can do
+type ModelProbe struct {
+	ID              string    `json:"id"`
+	Size            ModelSize `json:"size"`
+	ContextLength   int       `json:"context_length,omitempty"` // Largest n_ctx the model supports
+	ToolUse         bool      `json:"tool_use"`                 // Trained for tool calls
+	Vision          bool      `json:"vision,omitempty"`         // Reads images
+	TokensPerSecond float64   `json:"tokens_per_second,omitempty"`
+	BenchmarkError  string    `json:"benchmark_error,omitempty"`
+	ProbedAt        time.Time `json:"probed_at"`
+}
+
+// benchmarkMaxAge is how long a speed benchmark is reused; the rest of a
+// probe is read fresh every time
+const benchmarkMaxAge = 7 * 24 * time.Hour
+
+// benchmarkTimeout bounds the speed benchmark of one model
+const benchmarkTimeout = 20 * time.Second
+
+// ProbeModels describes the models LM Studio has loaded. Context length and
+// tool support come from its native REST API; speed comes from a short
+// completion, cached in cachePath (if set) so startup only benchmarks models
+// it has not seen recently. Servers without LM Studio's REST API, like
+// Ollama, are probed through their OpenAI-compatible model list.
+func (c *LMStudioClient) ProbeModels(ctx context.Context, cachePath string) ([]ModelProbe, error) {
+	resp, err := c.client.Get(c.baseURL + "/api/v0/models")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return c.probeOpenAIModels(ctx, cachePath)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LM Studio returned status %d", resp.StatusCode)
+	}
+
+	var modelsResp struct {
+		Data []struct {
+			ID               string   `json:"id"`
+			Type             string   `json:"type"`  // "llm", "vlm" or "embeddings"
+			State            string   `json:"state"` // "loaded" or "not-loaded"
+			MaxContextLength int      `json:"max_context_length"`
+			Capabilities     []string `json:"capabilities"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&modelsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode models response: %w", err)
+	}
+
+	cached := loadProbeCache(cachePath)
+	var probes []ModelProbe
+	for _, model := range modelsResp.Data {
+		if model.State != "loaded" || (model.Type != "llm" && model.Type != "vlm") {
+			continue
+		}
+		probe := ModelProbe{
+			ID:            model.ID,
+			Size:          GetModelRegistry().GetModelSize(model.ID),
+			ContextLength: model.MaxContextLength,
+			ToolUse:       slices.Contains(model.Capabilities, "tool_use"),
+			Vision:        model.Type == "vlm" || slices.Contains(model.Capabilities, "vision"),
+			ProbedAt:      time.Now(),
+		}
+		c.measure(ctx, &probe, cached)
+		probes = append(probes, probe)
+	}
+
+	saveProbeCache(cachePath, cached)
+	return probes, nil
+}
+
+// probeOpenAIModels probes every model in /v1/models. Without a native API
+// there is no context length or capability list, so only speed is measured.
+func (c *LMStudioClient) probeOpenAIModels(ctx context.Context, cachePath string) ([]ModelProbe, error) {
+	models, err := c.GetModels()
+	if err != nil {
+		return nil, err
+	}
+
+	cached := loadProbeCache(cachePath)
+	var probes []ModelProbe
+	for _, model := range models {
+		if model.Size == SizeSpecial {
+			continue // Embedding models don't generate
+		}
+		probe := ModelProbe{
+			ID:       model.ID,
+			Size:     GetModelRegistry().GetModelSize(model.ID),
+			ProbedAt: time.Now(),
+		}
+		c.measure(ctx, &probe, cached)
+		probes = append(probes, probe)
+	}
+
+	saveProbeCache(cachePath, cached)
+	return probes, nil
+}
+
+// measure fills in the probe's speed, from cached if benchmarked recently,
+// and records the result in cached
+func (c *LMStudioClient) measure(ctx context.Context, probe *ModelProbe, cached map[string]ModelProbe) {
+	if previous, ok := cached[probe.ID]; ok && previous.TokensPerSecond > 0 && time.Since(previous.ProbedAt) < benchmarkMaxAge {
+		probe.TokensPerSecond = previous.TokensPerSecond
+		probe.ProbedAt = previous.ProbedAt
+	} else if tps, err := c.benchmark(ctx, probe.ID); err != nil {
+		probe.BenchmarkError = err.Error()
+	} else {
+		probe.TokensPerSecond = tps
+	}
+	cached[probe.ID] = *probe
+}
+
+// benchmark measures how fast a model generates a short answer, in tokens
+// per second. It bypasses request tracking so it doesn't skew the latency
+// that worker concurrency follows.
+func (c *LMStudioClient) benchmark(ctx context.Context, modelID string) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, benchmarkTimeout)
+	defer cancel()
+
+	body, _ := json.Marshal(map[string]any{
+		"model":       modelID,
+		"messages":    []Message{{Role: "user", Content: "Count from 1 to 30, separated by spaces."}},
+		"max_tokens":  64,
+		"temperature": 0,
+		"stream":      false,
+	})
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v1/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("LM Studio returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Usage struct {
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode benchmark response: %w", err)
+	}
+	elapsed := time.Since(start).Seconds()
+	if result.Usage.CompletionTokens == 0 || elapsed <= 0 {
+		return 0, fmt.Errorf("no token usage in benchmark response")
+	}
+	return float64(result.Usage.CompletionTokens) / elapsed, nil
+}
+
+func loadProbeCache(path string) map[string]ModelProbe {
+	cached := make(map[string]ModelProbe)
+	if path == "" {
+		return cached
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &cached)
+	}
+	return cached
+}
+
+func saveProbeCache(path string, probes map[string]ModelProbe) {
+	if path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(probes, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.MkdirAll(filepath.Dir(path), 0o755)
+	_ = os.WriteFile(path, data, 0o644)
+}
+
+// TeamFromProbes assigns team slots from probed models: the smallest and
+// fastest model is Small, the largest is Large and the one in between is
+// Medium. Among models of one size class the faster counts as smaller, and
+// a tool-capable model is preferred for the bigger slots. Returns nil when
+// no model is loaded.
+func TeamFromProbes(probes []ModelProbe) *ModelTeam {
+	if len(probes) == 0 {
+		return nil
+	}
+
+	ranked := slices.Clone(probes)
+	slices.SortStableFunc(ranked, func(a, b ModelProbe) int {
+		if d := sizeRank(a.Size) - sizeRank(b.Size); d != 0 {
+			return d
+		}
+		if a.ToolUse != b.ToolUse {
+			if a.ToolUse {
+				return 1
+			}
+			return -1
+		}
+		switch {
+		case a.TokensPerSecond > b.TokensPerSecond:
+			return -1
+		case a.TokensPerSecond < b.TokensPerSecond:
+			return 1
+		}
+		return 0
+	})
+
+	return &ModelTeam{
+		Name:   "Auto Team",
+		Small:  ranked[0].ID,
+		Medium: ranked[len(ranked)/2].ID,
+		Large:  ranked[len(ranked)-1].ID,
+	}
+}
+
+// sizeRank orders size classes from smallest to largest
+func sizeRank(size ModelSize) int {
+	switch size {
+	case SizeXS:
+		return 0
+	case SizeS:
+		return 1
+	case SizeM:
+		return 2
+	case SizeL:
+		return 3
+	case SizeXL:
+		return 4
+	}
+	return 2 // Unknown sizes sit in the middle
+}
@@ -0,0 +1,78 @@
+package queue
+
+import (
+	"fmt"
+	"time"
+)
+
+// Aging curves
+const (
+	AgingNone        = "none"
+	AgingLinear      = "linear"      // Step more for every Interval waited
+	AgingExponential = "exponential" // Step after one Interval, doubling the gain with each further one
+)
+
+// Aging raises the priority of waiting items the longer they wait, so
+// background work still gets its turn while higher-priority requests
+// keep arriving. The boost is added to QueueItem.Priority when the queue
+// picks the next item; with equal totals the older item goes first.
+//
+// Aging lifts no item above Ceiling. Requests already at the ceiling
+// gain nothing, so once a waiting item reaches it, it starts ahead of
+// everything that arrived after it.
+//
+// Used by: Queue (orders items), Manager (reports it in Status)
+type Aging struct {
+	Curve    string        // AgingLinear, AgingExponential or AgingNone
+	Interval time.Duration // How long an item waits for each increase
+	Step     int           // Boost after the first Interval
+	Ceiling  int           // Highest priority aging lifts an item to; zero means no limit
+}
+
+// DefaultAging lets a background item (priority 1) catch up with a user
+// request (priority 10) after four and a half minutes of waiting.
+func DefaultAging() Aging {
+	return Aging{Curve: AgingLinear, Interval: 30 * time.Second, Step: 1, Ceiling: 10}
+}
+
+// Enabled reports whether the policy ever boosts an item.
+func (a Aging) Enabled() bool {
+	return (a.Curve == AgingLinear || a.Curve == AgingExponential) && a.Interval > 0 && a.Step > 0
+}
+
+// Boost returns the priority added to an item with the given priority
+// that has waited this long.
+func (a Aging) Boost(priority int, waited time.Duration) int {
+	if !a.Enabled() || waited < a.Interval {
+		return 0
+	}
+	intervals := int(waited / a.Interval)
+
+	var boost int
+	switch a.Curve {
+	case AgingLinear:
+		boost = a.Step * intervals
+	case AgingExponential:
+		// 1, 3, 7, 15... times Step; the shift is bounded so it can't overflow
+		boost = a.Step * (1<<min(intervals, 20) - 1)
+	}
+	if a.Ceiling > 0 && priority+boost > a.Ceiling {
+		boost = max(0, a.Ceiling-priority)
+	}
+	return boost
+}
+
+// String describes the policy, e.g. "linear, +1 every 30s, up to priority 10".
+func (a Aging) String() string {
+	if !a.Enabled() {
+		return "off"
+	}
+	desc := fmt.Sprintf("%s, +%d every %s", a.Curve, a.Step, a.Interval)
+	if a.Curve == AgingExponential {
+		desc = fmt.Sprintf("%s, +%d after %s, gaining twice as much with each %s after that", a.Curve, a.Step, a.Interval, a.Interval)
+	}
+	if a.Ceiling > 0 {
+		desc += fmt.Sprintf(", up to priority %d", a.Ceiling)
+	}
+	return desc
+}
@@ -0,0 +1,123 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgingBoost(t *testing.T) {
+	linear := Aging{Curve: AgingLinear, Interval: 30 * time.Second, Step: 1, Ceiling: 10}
+	exponential := Aging{Curve: AgingExponential, Interval: 30 * time.Second, Step: 2}
+	tests := []struct {
+		name     string
+		aging    Aging
+		priority int
+		waited   time.Duration
+		want     int
+	}{
+		{"linear_before_interval", linear, 1, 29 * time.Second, 0},
+		{"linear_one_interval", linear, 1, 30 * time.Second, 1},
+		{"linear_partial_interval", linear, 1, 89 * time.Second, 2},
+		{"linear_capped_at_ceiling", linear, 1, time.Hour, 9},
+		{"linear_at_ceiling", linear, 10, time.Hour, 0},
+		{"linear_above_ceiling", linear, 12, time.Hour, 0},
+		{"exponential_one_interval", exponential, 1, 30 * time.Second, 2},
+		{"exponential_two_intervals", exponential, 1, 60 * time.Second, 6},
+		{"exponential_three_intervals", exponential, 1, 90 * time.Second, 14},
+		{"exponential_bounded_shift", exponential, 1, 1000 * time.Hour, 2 * (1<<20 - 1)},
+		{"no_ceiling", Aging{Curve: AgingLinear, Interval: time.Second, Step: 3}, 50, 10 * time.Second, 30},
+		{"none", Aging{Curve: AgingNone, Interval: time.Second, Step: 1}, 1, time.Hour, 0},
+		{"zero_interval", Aging{Curve: AgingLinear, Step: 1}, 1, time.Hour, 0},
+		{"zero_step", Aging{Curve: AgingLinear, Interval: time.Second}, 1, time.Hour, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.aging.Boost(tt.priority, tt.waited); got != tt.want {
+			t.Errorf("%s: Boost = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQueueOrdersByAgedPriority(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		aging Aging
+		items []*QueueItem
+		want  []string
+	}{
+		{
+			name:  "priority_then_fifo",
+			aging: Aging{Curve: AgingNone},
+			items: []*QueueItem{
+				{ID: "bg-old", Priority: 1, Created: now.Add(-time.Hour)},
+				{ID: "chat-new", Priority: 10, Created: now},
+				{ID: "chat-old", Priority: 10, Created: now.Add(-time.Minute)},
+			},
+			want: []string{"chat-old", "chat-new", "bg-old"},
+		},
+		{
+			name:  "aged_item_overtakes",
+			aging: Aging{Curve: AgingLinear, Interval: time.Minute, Step: 1, Ceiling: 10},
+			items: []*QueueItem{
+				{ID: "command", Priority: 5, Created: now},
+				{ID: "bg", Priority: 1, Created: now.Add(-10 * time.Minute)},
+			},
+			want: []string{"bg", "command"},
+		},
+		{
+			name:  "ceiling_ties_go_to_the_older",
+			aging: Aging{Curve: AgingLinear, Interval: time.Minute, Step: 1, Ceiling: 10},
+			items: []*QueueItem{
+				{ID: "chat", Priority: 10, Created: now},
+				{ID: "bg", Priority: 1, Created: now.Add(-time.Hour)},
+			},
+			want: []string{"bg", "chat"},
+		},
+		{
+			name:  "not_yet_aged",
+			aging: Aging{Curve: AgingLinear, Interval: time.Hour, Step: 1, Ceiling: 10},
+			items: []*QueueItem{
+				{ID: "bg", Priority: 1, Created: now.Add(-time.Minute)},
+				{ID: "command", Priority: 5, Created: now},
+			},
+			want: []string{"command", "bg"},
+		},
+	}
+
+	for _, tt := range tests {
+		q := NewQueue()
+		q.SetAging(tt.aging)
+		for _, item := range tt.items {
+			q.Push(item)
+		}
+		for i, want := range tt.want {
+			item := q.TryPop()
+			if item == nil || item.ID != want {
+				t.Errorf("%s: pop %d = %v, want %s", tt.name, i, item, want)
+				break
+			}
+		}
+	}
+}
+
+func TestPopWhereMarksPromotedItems(t *testing.T) {
+	now := time.Now()
+	q := NewQueue()
+	q.SetAging(Aging{Curve: AgingLinear, Interval: time.Minute, Step: 1, Ceiling: 10})
+	q.Push(&QueueItem{ID: "chat", Priority: 10, Created: now})
+	q.Push(&QueueItem{ID: "bg", Priority: 1, Created: now.Add(-time.Hour)})
+	q.Push(&QueueItem{ID: "busy", Priority: 10, Created: now.Add(-2 * time.Hour)})
+
+	notBusy := func(item *QueueItem) bool { return item.ID != "busy" }
+	first := q.PopWhere(notBusy)
+	if first.ID != "bg" || !first.Promoted {
+		t.Errorf("first = %s (promoted %v), want bg promoted", first.ID, first.Promoted)
+	}
+	second := q.PopWhere(notBusy)
+	if second.ID != "chat" || second.Promoted {
+		t.Errorf("second = %s (promoted %v), want chat not promoted", second.ID, second.Promoted)
+	}
+	if q.Len() != 1 {
+		t.Errorf("Len = %d, want the busy item left", q.Len())
+	}
+}
@@ -5,15 +5,19 @@
 // This package solves the problem of managing multiple LLM requests to LM Studio
 // when it can only handle limited concurrency. It provides:
 //   - Priority-based scheduling (user requests > background tasks)
+//   - Aging (requests gain priority while they wait, so background work
+//     isn't starved)
 //   - Request deduplication (cancel stale analyses)
 //   - Graceful cancellation (all requests are context-aware)
 //   - Adaptive concurrency (adjust to model capacity)
+//   - Per-model lanes (requests for different loaded models run side by side)
 //
 // # Architecture
 //
 // The queue system consists of composable parts:
 //
 //   - QueueItem: Represents a single LLM request with priority and context
+//   - Aging: How much priority waiting items gain over time
 //   - Queue: Priority queue that holds and sorts items
 //   - Processor: Worker that executes items from the queue
 //   - Deduplicator: Cancels superseded requests
@@ -30,8 +34,9 @@
 //
 // # Integration Points
 //
-//   - tools/startup_scan.go: Submits startup scan requests
+//   - tools/startup_scan.go: Submits startup scan requests, superseding the last
 //   - tools/analyze.go: Submits analysis requests
+//   - analysis/service_with_team.go: Runs each tier in its model's lane
 //   - app/chat.go: Submits chat completion requests
 //   - watcher/watcher.go: Triggers analysis on file changes
 //
@@ -47,4 +52,9 @@
 //	// Cancel if needed
 //	manager.Cancel(id)
 //
+//	// Let the detailed tier's medium model work while the small model
+//	// answers quick-tier requests; each model takes one at a time
+//	manager.Submit(ctx, runDetailed, queue.WithModel(mediumID), queue.WithTimeout(0))
+//	manager.Submit(ctx, runQuick, queue.WithModel(smallID), queue.WithTimeout(0))
+//
 package queue
\ No newline at end of file
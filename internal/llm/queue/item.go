@@ -21,6 +21,14 @@ type QueueItem struct {
 	// Priority determines execution order (higher = sooner)
 	// 10 = user chat, 5 = user command, 3 = file change, 1 = background
 	Priority int
+
+	// Boost is what aging adds to Priority for the time the item has
+	// waited, refreshed by the queue whenever it picks the next item
+	Boost int
+
+	// Promoted is set when aging let the item start ahead of a ready item
+	// with a higher Priority
+	Promoted bool
 	
 	// Type categorizes the request for metrics and debugging
 	// e.g., "startup_scan", "quick_analysis", "chat", "tool"
@@ -43,10 +51,22 @@ type QueueItem struct {
 	// Created timestamp for age-based decisions
 	Created time.Time
 	
+	// Model is the model this request runs on. Requests for a model are
+	// limited by its own concurrency limit instead of the shared worker
+	// pool, so requests for different loaded models run side by side.
+	// Empty means the shared pool.
+	Model string
+
+	// Timeout bounds the request once it starts; zero means no limit
+	Timeout time.Duration
+
 	// Metadata for debugging and logging
 	Metadata map[string]interface{}
 }
 
+// DefaultTimeout bounds a request unless WithTimeout says otherwise
+const DefaultTimeout = 2 * time.Minute
+
 // Option configures a QueueItem when creating it.
 // This pattern makes the API composable and extensible.
 type Option func(*QueueItem)
@@ -82,6 +102,23 @@ func WithSupersedes(ids ...string) Option {
 	}
 }
 
+// WithModel runs the request in the given model's lane.
+// Requests for the same model share its concurrency limit
+// (Manager.SetModelLimit, default 1).
+func WithModel(model string) Option {
+	return func(qi *QueueItem) {
+		qi.Model = model
+	}
+}
+
+// WithTimeout bounds how long the request may run once started.
+// Zero means no limit, for long jobs such as a whole analysis tier.
+func WithTimeout(d time.Duration) Option {
+	return func(qi *QueueItem) {
+		qi.Timeout = d
+	}
+}
+
 // WithMetadata attaches arbitrary data for debugging.
 // Useful for tracking request origin, file paths, etc.
 func WithMetadata(key string, value interface{}) Option {
@@ -106,6 +143,7 @@ func NewQueueItem(id string, ctx context.Context, request func(context.Context)
 		Context:  itemCtx,
 		Cancel:   cancel,
 		Created:  time.Now(),
+		Timeout:  DefaultTimeout,
 		Metadata: make(map[string]interface{}),
 	}
 	
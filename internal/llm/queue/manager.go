@@ -3,6 +3,7 @@ package queue
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -30,12 +31,23 @@ type Manager struct {
 	items   map[string]*QueueItem
 	itemsMu sync.RWMutex
 	
-	// Configuration
-	maxWorkers int
-	
 	// Lifecycle
 	started bool
 	mutex   sync.Mutex
+
+	// Status changes, for the UI
+	onChange   func(Status)
+	onChangeMu sync.RWMutex
+
+	// How long started requests waited, for fairness stats
+	waits struct {
+		sync.Mutex
+		started  int
+		promoted int
+		total    time.Duration
+		max      time.Duration
+		maxType  string
+	}
 }
 
 // NewManager creates a queue manager with default settings.
@@ -54,7 +66,6 @@ func NewManager(maxWorkers int) *Manager {
 		proc:       proc,
 		dedup:      dedup,
 		items:      make(map[string]*QueueItem),
-		maxWorkers: maxWorkers,
 	}
 	
 	// Hook up callbacks
@@ -129,6 +140,7 @@ func (m *Manager) Submit(ctx context.Context, request func(context.Context) erro
 	
 	// Enqueue
 	m.queue.Push(item)
+	m.notifyChange()
 	
 	return id
 }
@@ -145,6 +157,7 @@ func (m *Manager) Cancel(id string) bool {
 	// Clean up tracking
 	if canceled || removed {
 		m.removeItem(id)
+		m.notifyChange()
 		return true
 	}
 	
@@ -176,53 +189,133 @@ func (m *Manager) CancelByType(requestType string) int {
 type Status struct {
 	Pending   int
 	Active    int
+	Running   int // Requests executing now; Active also counts pending ones
 	Completed int
 	Canceled  int
 	AvgTime   time.Duration
 	ErrorRate float64
+
+	// Running requests per model ("" is the shared pool)
+	ActiveByModel map[string]int
+
+	// Fairness: the aging policy, how long started requests waited, and
+	// how many started ahead of higher-priority requests thanks to aging
+	Aging       Aging
+	Started     int
+	Promoted    int
+	AvgWait     time.Duration
+	MaxWait     time.Duration
+	MaxWaitType string // Type of the request that waited longest
 }
 
 // GetStatus returns current queue metrics.
 // Use this for UI display and monitoring.
 func (m *Manager) GetStatus() Status {
 	avgTime, errorRate := m.proc.GetMetrics()
+	pending := m.queue.Len()
+	
+	m.itemsMu.RLock()
+	running := max(0, len(m.items)-pending)
+	m.itemsMu.RUnlock()
 	
-	return Status{
-		Pending:   m.queue.Len(),
+	status := Status{
+		Pending:   pending,
 		Active:    m.dedup.ActiveCount(),
+		Running:   running,
 		AvgTime:   avgTime,
 		ErrorRate: errorRate,
+
+		ActiveByModel: m.proc.ActiveByModel(),
+		Aging:         m.queue.Aging(),
+	}
+
+	m.waits.Lock()
+	status.Started = m.waits.started
+	status.Promoted = m.waits.promoted
+	if m.waits.started > 0 {
+		status.AvgWait = m.waits.total / time.Duration(m.waits.started)
+	}
+	status.MaxWait = m.waits.max
+	status.MaxWaitType = m.waits.maxType
+	m.waits.Unlock()
+	return status
+}
+
+// Waiting returns the pending requests in the order they would start,
+// with what aging has added to their priorities.
+// Use this for UI display and monitoring.
+func (m *Manager) Waiting() []WaitingItem {
+	return m.queue.Waiting()
+}
+
+// SetAging changes how waiting raises the priority of pending requests
+// (DefaultAging unless set).
+func (m *Manager) SetAging(aging Aging) {
+	m.queue.SetAging(aging)
+}
+
+// OnChange sets a callback run whenever a request is queued, starts,
+// finishes or is canceled, with the new status.
+func (m *Manager) OnChange(fn func(Status)) {
+	m.onChangeMu.Lock()
+	defer m.onChangeMu.Unlock()
+	m.onChange = fn
+}
+
+// notifyChange passes the current status to the OnChange callback
+func (m *Manager) notifyChange() {
+	m.onChangeMu.RLock()
+	fn := m.onChange
+	m.onChangeMu.RUnlock()
+	if fn != nil {
+		fn(m.GetStatus())
 	}
 }
 
 // SetMaxWorkers adjusts parallelism dynamically.
 // Use this to adapt to model capacity.
 func (m *Manager) SetMaxWorkers(n int) {
-	if n < 1 {
-		n = 1
-	}
-	m.maxWorkers = n
 	m.proc.SetMaxWorkers(n)
 }
 
+// SetModelLimit sets how many requests submitted WithModel(model) run at
+// once. Limits are per model, so requests for two loaded models don't wait
+// on each other; AdaptConcurrency leaves them alone.
+func (m *Manager) SetModelLimit(model string, n int) {
+	m.proc.SetModelLimit(model, n)
+}
+
 // AdaptConcurrency automatically adjusts workers based on performance.
 // Call this periodically or after each request.
 func (m *Manager) AdaptConcurrency() {
 	avgTime, _ := m.proc.GetMetrics()
+	maxWorkers := m.proc.MaxWorkers()
 	
 	// Simple heuristic: reduce parallelism if responses are slow
-	if avgTime > 15*time.Second && m.maxWorkers > 1 {
-		m.SetMaxWorkers(m.maxWorkers - 1)
-	} else if avgTime < 5*time.Second && m.maxWorkers < 3 {
-		m.SetMaxWorkers(m.maxWorkers + 1)
+	if avgTime > 15*time.Second && maxWorkers > 1 {
+		m.SetMaxWorkers(maxWorkers - 1)
+	} else if avgTime < 5*time.Second && maxWorkers < 3 {
+		m.SetMaxWorkers(maxWorkers + 1)
 	}
 }
 
 // Internal callbacks
 
 func (m *Manager) onItemStart(item *QueueItem) {
-	// Could emit events here for UI updates
-	fmt.Printf("[Queue] Starting %s (%s) priority=%d\n", item.ID, item.Type, item.Priority)
+	waited := time.Since(item.Created)
+	m.waits.Lock()
+	m.waits.started++
+	if item.Promoted {
+		m.waits.promoted++
+	}
+	m.waits.total += waited
+	if waited > m.waits.max {
+		m.waits.max, m.waits.maxType = waited, item.Type
+	}
+	m.waits.Unlock()
+
+	m.notifyChange()
+	debugf("[Queue] Starting %s (%s) priority=%d boost=%d waited=%v\n", item.ID, item.Type, item.Priority, item.Boost, waited)
 }
 
 func (m *Manager) onItemComplete(item *QueueItem, err error, duration time.Duration) {
@@ -231,15 +324,16 @@ func (m *Manager) onItemComplete(item *QueueItem, err error, duration time.Durat
 	
 	// Remove from tracking
 	m.removeItem(item.ID)
+	m.notifyChange()
 	
 	// Adapt concurrency based on performance
 	m.AdaptConcurrency()
 	
 	// Log completion
 	if err != nil {
-		fmt.Printf("[Queue] Failed %s (%s) after %v: %v\n", item.ID, item.Type, duration, err)
+		debugf("[Queue] Failed %s (%s) after %v: %v\n", item.ID, item.Type, duration, err)
 	} else {
-		fmt.Printf("[Queue] Completed %s (%s) in %v\n", item.ID, item.Type, duration)
+		debugf("[Queue] Completed %s (%s) in %v\n", item.ID, item.Type, duration)
 	}
 }
 
@@ -253,4 +347,12 @@ func (m *Manager) removeItem(id string) {
 	m.itemsMu.Lock()
 	defer m.itemsMu.Unlock()
 	delete(m.items, id)
-}
\ No newline at end of file
+}
+
+// debugf logs queue activity when LOCO_DEBUG is set; printing
+// unconditionally would scribble over the TUI.
+func debugf(format string, args ...interface{}) {
+	if os.Getenv("LOCO_DEBUG") == "true" {
+		fmt.Printf(format, args...)
+	}
+}
@@ -2,24 +2,39 @@ package queue
 
 import (
 	"context"
-	"fmt"
 	"sync"
 	"time"
+
+	"github.com/billie-coop/loco/internal/crash"
 )
 
+// DefaultModelLimit is how many requests run at once on a model that has
+// no limit of its own
+const DefaultModelLimit = 1
+
 // Processor pulls items from a queue and executes them.
 // It manages concurrency (how many requests run in parallel) and
 // tracks metrics for adaptive behavior.
 //
 // The processor is the "worker" that actually runs LLM requests.
 // It respects the maxWorkers limit to avoid overwhelming LM Studio.
+// Requests bound to a model (WithModel) are limited per model instead, so
+// a small and a medium model that are both loaded can work at once.
 //
 // Used by: Manager (starts/stops it)
 // Connects to: Queue (pulls items), LLM client (executes requests)
 type Processor struct {
-	queue      *Queue
-	maxWorkers int
-	semaphore  chan struct{}
+	queue *Queue
+
+	// Worker slots: maxWorkers for requests without a model, and a limit
+	// per model for the rest. active counts running requests by model
+	// ("" is the shared pool).
+	slots struct {
+		sync.Mutex
+		maxWorkers  int
+		modelLimits map[string]int
+		active      map[string]int
+	}
 	
 	// Lifecycle
 	ctx    context.Context
@@ -50,13 +65,15 @@ func NewProcessor(queue *Queue, maxWorkers int) *Processor {
 	
 	ctx, cancel := context.WithCancel(context.Background())
 	
-	return &Processor{
-		queue:      queue,
-		maxWorkers: maxWorkers,
-		semaphore:  make(chan struct{}, maxWorkers),
-		ctx:        ctx,
-		cancel:     cancel,
+	p := &Processor{
+		queue:  queue,
+		ctx:    ctx,
+		cancel: cancel,
 	}
+	p.slots.maxWorkers = maxWorkers
+	p.slots.modelLimits = make(map[string]int)
+	p.slots.active = make(map[string]int)
+	return p
 }
 
 // Start begins processing items from the queue.
@@ -70,6 +87,7 @@ func (p *Processor) Start() {
 // Waits for in-flight requests to complete.
 func (p *Processor) Stop() {
 	p.cancel()
+	p.queue.Close() // Unblock the loop waiting on an empty queue
 	p.wg.Wait()
 }
 
@@ -79,10 +97,44 @@ func (p *Processor) SetMaxWorkers(n int) {
 	if n < 1 {
 		n = 1
 	}
-	// Create new semaphore with new size
-	// (simple approach, could be more sophisticated)
-	p.semaphore = make(chan struct{}, n)
-	p.maxWorkers = n
+	p.slots.Lock()
+	p.slots.maxWorkers = n
+	p.slots.Unlock()
+	p.queue.Wake() // Waiting items may fit now
+}
+
+// MaxWorkers returns the limit for requests without a model.
+func (p *Processor) MaxWorkers() int {
+	p.slots.Lock()
+	defer p.slots.Unlock()
+	return p.slots.maxWorkers
+}
+
+// SetModelLimit sets how many requests for a model run at once.
+// Models without a limit get DefaultModelLimit.
+func (p *Processor) SetModelLimit(model string, n int) {
+	if n < 1 {
+		n = 1
+	}
+	p.slots.Lock()
+	p.slots.modelLimits[model] = n
+	p.slots.Unlock()
+	p.queue.Wake()
+}
+
+// ActiveByModel returns the number of running requests per model
+// ("" is the shared pool).
+func (p *Processor) ActiveByModel() map[string]int {
+	p.slots.Lock()
+	defer p.slots.Unlock()
+
+	active := make(map[string]int, len(p.slots.active))
+	for model, n := range p.slots.active {
+		if n > 0 {
+			active[model] = n
+		}
+	}
+	return active
 }
 
 // OnStart sets callback for when item starts processing.
@@ -112,6 +164,7 @@ func (p *Processor) GetMetrics() (avgTime time.Duration, errorRate float64) {
 // run is the main processor loop.
 // Pulls items from queue and executes them with concurrency control.
 func (p *Processor) run() {
+	defer crash.Recover("LLM queue")
 	defer p.wg.Done()
 	
 	for {
@@ -121,8 +174,8 @@ func (p *Processor) run() {
 		default:
 		}
 		
-		// Get next item (blocks if queue empty)
-		item := p.queue.Pop()
+		// Get the next item with a free worker slot (blocks until there is one)
+		item := p.queue.PopWhere(p.hasSlot)
 		if item == nil {
 			continue
 		}
@@ -130,27 +183,53 @@ func (p *Processor) run() {
 		// Check if already canceled
 		select {
 		case <-item.Context.Done():
-			continue // Skip canceled items
+			// Skip canceled items, letting the manager stop tracking them
+			if p.onComplete != nil {
+				p.onComplete(item, item.Context.Err(), 0)
+			}
+			continue
 		default:
 		}
 		
-		// Acquire semaphore (wait for worker slot)
-		select {
-		case p.semaphore <- struct{}{}:
-			// Got a slot, process item
-			p.wg.Add(1)
-			go p.process(item)
-		case <-p.ctx.Done():
-			return
+		// Only this loop takes slots, so the one hasSlot saw is still free
+		p.slots.Lock()
+		p.slots.active[item.Model]++
+		p.slots.Unlock()
+		p.wg.Add(1)
+		go p.process(item)
+	}
+}
+
+// hasSlot reports whether item can start now.
+// Called by the queue with its lock held.
+func (p *Processor) hasSlot(item *QueueItem) bool {
+	p.slots.Lock()
+	defer p.slots.Unlock()
+
+	limit := p.slots.maxWorkers
+	if item.Model != "" {
+		limit = DefaultModelLimit
+		if n, ok := p.slots.modelLimits[item.Model]; ok {
+			limit = n
 		}
 	}
+	return p.slots.active[item.Model] < limit
+}
+
+// releaseSlot frees the worker slot an item held
+func (p *Processor) releaseSlot(item *QueueItem) {
+	p.slots.Lock()
+	p.slots.active[item.Model]--
+	p.slots.Unlock()
+	p.queue.Wake()
 }
 
 // process executes a single queue item.
 // Runs in its own goroutine with timeout and metrics tracking.
 func (p *Processor) process(item *QueueItem) {
+	defer crash.Recover("LLM request")
 	defer p.wg.Done()
-	defer func() { <-p.semaphore }() // Release worker slot
+	defer p.releaseSlot(item)
 	
 	// Notify start
 	if p.onStart != nil {
@@ -160,8 +239,11 @@ func (p *Processor) process(item *QueueItem) {
 	// Track timing
 	start := time.Now()
 	
-	// Execute with timeout (2 minutes default, could be configurable)
-	ctx, cancel := context.WithTimeout(item.Context, 2*time.Minute)
+	// Execute with the item's timeout (DefaultTimeout unless set)
+	ctx, cancel := item.Context, context.CancelFunc(func() {})
+	if item.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(item.Context, item.Timeout)
+	}
 	defer cancel()
 	
 	// Run the actual request
@@ -178,7 +260,7 @@ func (p *Processor) process(item *QueueItem) {
 	
 	// Log errors for debugging
 	if err != nil {
-		fmt.Printf("Queue item %s (%s) failed: %v\n", item.ID, item.Type, err)
+		debugf("Queue item %s (%s) failed: %v\n", item.ID, item.Type, err)
 	}
 }
 
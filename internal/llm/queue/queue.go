@@ -2,12 +2,16 @@ package queue
 
 import (
 	"container/heap"
+	"sort"
 	"sync"
+	"time"
 )
 
 // Queue is a thread-safe priority queue for LLM requests.
 // Items with higher priority are dequeued first.
 // If priorities are equal, older items go first (FIFO within priority).
+// Aging adds to the priority of items the longer they wait, so low
+// priority items are not starved by a steady stream of higher ones.
 //
 // This is the core data structure that holds pending requests.
 // The Processor pulls items from here to execute them.
@@ -15,15 +19,18 @@ import (
 // Used by: Manager (adds items), Processor (removes items)
 // Thread-safe: Yes (all operations lock)
 type Queue struct {
-	items priorityQueue
-	mutex sync.Mutex
-	cond  *sync.Cond
+	items  priorityQueue
+	aging  Aging
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	closed bool
 }
 
 // NewQueue creates an empty priority queue.
 func NewQueue() *Queue {
 	q := &Queue{
 		items: make(priorityQueue, 0),
+		aging: DefaultAging(),
 	}
 	q.cond = sync.NewCond(&q.mutex)
 	heap.Init(&q.items)
@@ -38,22 +45,114 @@ func (q *Queue) Push(item *QueueItem) {
 	defer q.mutex.Unlock()
 	
 	heap.Push(&q.items, item)
-	q.cond.Signal() // Wake up waiting processor
+	q.cond.Broadcast() // Wake up waiting processor
 }
 
 // Pop removes and returns the highest priority item.
 // Blocks if queue is empty (waits for Push).
+// Returns nil once the queue is closed.
 // Called by Processor to get next item to execute.
 func (q *Queue) Pop() *QueueItem {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 	
 	// Wait for items
-	for q.items.Len() == 0 {
+	for q.items.Len() == 0 && !q.closed {
 		q.cond.Wait()
 	}
+	if q.closed {
+		return nil
+	}
+	
+	return q.popBest(nil)
+}
+
+// PopWhere removes and returns the highest priority item that ready accepts.
+// Blocks until there is one (call Wake when ready may have changed its mind).
+// Returns nil once the queue is closed.
+// Called by Processor so a busy model doesn't hold up items for other models.
+func (q *Queue) PopWhere(ready func(*QueueItem) bool) *QueueItem {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for !q.closed {
+		if item := q.popBest(ready); item != nil {
+			return item
+		}
+		q.cond.Wait()
+	}
+	return nil
+}
+
+// popBest removes and returns the item ready accepts (any item when ready
+// is nil) that goes first once aging is brought up to date, or nil when
+// there is none. The caller holds q.mutex.
+func (q *Queue) popBest(ready func(*QueueItem) bool) *QueueItem {
+	q.age(time.Now())
+
+	best, topPriority := -1, 0
+	for i, item := range q.items {
+		if ready != nil && !ready(item) {
+			continue
+		}
+		if best < 0 || item.Priority > topPriority {
+			topPriority = item.Priority
+		}
+		if best < 0 || q.items.Less(i, best) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	item := heap.Remove(&q.items, best).(*QueueItem)
+	item.Promoted = item.Priority < topPriority
+	return item
+}
+
+// age refreshes every item's aging boost and restores the heap order the
+// new boosts imply. The caller holds q.mutex.
+func (q *Queue) age(now time.Time) {
+	for _, item := range q.items {
+		item.Boost = q.aging.Boost(item.Priority, now.Sub(item.Created))
+	}
+	heap.Init(&q.items)
+}
+
+// SetAging changes how waiting raises priorities.
+// Called by Manager when the configured policy is applied.
+func (q *Queue) SetAging(aging Aging) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.aging = aging
+	q.age(time.Now())
+}
+
+// Aging returns the current aging policy.
+func (q *Queue) Aging() Aging {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.aging
+}
+
+// Wake re-checks blocked PopWhere calls.
+// Called by Processor when a worker slot frees up.
+func (q *Queue) Wake() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.cond.Broadcast()
+}
+
+// Close wakes any blocked Pop and makes later Pops return nil.
+// Called by Processor when stopping so its loop can exit.
+func (q *Queue) Close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
 	
-	return heap.Pop(&q.items).(*QueueItem)
+	q.closed = true
+	q.cond.Broadcast()
 }
 
 // TryPop is like Pop but returns nil immediately if queue is empty.
@@ -66,7 +165,7 @@ func (q *Queue) TryPop() *QueueItem {
 		return nil
 	}
 	
-	return heap.Pop(&q.items).(*QueueItem)
+	return q.popBest(nil)
 }
 
 // Remove removes an item by ID.
@@ -104,16 +203,55 @@ func (q *Queue) Items() []*QueueItem {
 	return result
 }
 
+// WaitingItem describes a pending item at one moment.
+type WaitingItem struct {
+	ID       string
+	Type     string
+	Model    string
+	Priority int
+	Boost    int // Added by aging for the time waited
+	Waited   time.Duration
+}
+
+// Waiting returns the pending items in the order they would start,
+// workers permitting, with their aging boosts brought up to date.
+// Used by Manager for status displays.
+func (q *Queue) Waiting() []WaitingItem {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := time.Now()
+	q.age(now)
+	sorted := make(priorityQueue, len(q.items))
+	copy(sorted, q.items)
+	sort.Sort(sorted)
+
+	waiting := make([]WaitingItem, len(sorted))
+	for i, item := range sorted {
+		waiting[i] = WaitingItem{
+			ID:       item.ID,
+			Type:     item.Type,
+			Model:    item.Model,
+			Priority: item.Priority,
+			Boost:    item.Boost,
+			Waited:   now.Sub(item.Created),
+		}
+	}
+	return waiting
+}
+
 // priorityQueue implements heap.Interface for priority ordering.
-// Higher priority items come first. Within same priority, older items come first.
+// Higher priority items, aging boost included, come first. Within same
+// priority, older items come first.
 type priorityQueue []*QueueItem
 
 func (pq priorityQueue) Len() int { return len(pq) }
 
 func (pq priorityQueue) Less(i, j int) bool {
-	// Higher priority first
-	if pq[i].Priority != pq[j].Priority {
-		return pq[i].Priority > pq[j].Priority
+	// Higher priority first, counting what aging added
+	pi, pj := pq[i].Priority+pq[i].Boost, pq[j].Priority+pq[j].Boost
+	if pi != pj {
+		return pi > pj
 	}
 	// Same priority: older first (FIFO)
 	return pq[i].Created.Before(pq[j].Created)
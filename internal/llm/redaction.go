@@ -0,0 +1,22 @@
+package llm
+
+import "github.com/billie-coop/loco/internal/redact"
+
+// SetRedactor scrubs secrets from every chat request the client sends with
+// r; nil sends messages as they are
+func (c *LMStudioClient) SetRedactor(r *redact.Redactor) { c.redactor = r }
+
+// redactMessages returns messages with their secrets replaced, leaving the
+// caller's slice alone. Requests are redacted before they are recorded,
+// tracked or sent, so cassettes and the request log hold no secrets either.
+func (c *LMStudioClient) redactMessages(messages []Message) []Message {
+	if c.redactor == nil {
+		return messages
+	}
+	redacted := make([]Message, len(messages))
+	for i, msg := range messages {
+		msg.Content = c.redactor.Redact("model request", msg.Content)
+		redacted[i] = msg
+	}
+	return redacted
+}
@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RequestInfo describes a chat request sent to the model server. The most
+// recent one is kept so crash reports can say what the model was doing.
+type RequestInfo struct {
+	Started     time.Time     `json:"started"`
+	Endpoint    string        `json:"endpoint"`
+	Model       string        `json:"model,omitempty"`
+	Stream      bool          `json:"stream"`
+	Messages    int           `json:"messages"`
+	PromptChars int           `json:"prompt_chars"`
+	ContextSize int           `json:"context_size,omitempty"`
+	Duration    time.Duration `json:"duration,omitempty"` // Until the server answered; zero while waiting
+	Status      int           `json:"status,omitempty"`   // HTTP status once answered
+	Error       string        `json:"error,omitempty"`
+
+	err error // The error itself, for observers that check its kind
+}
+
+// Err returns the error that ended the request, for checking its kind
+func (r RequestInfo) Err() error {
+	return r.err
+}
+
+var lastRequest atomic.Pointer[RequestInfo]
+
+// LastRequest returns the most recent request, if any was sent
+func LastRequest() (RequestInfo, bool) {
+	info := lastRequest.Load()
+	if info == nil {
+		return RequestInfo{}, false
+	}
+	return *info, true
+}
+
+// requestTracker updates the recorded request when it finishes
+type requestTracker struct {
+	info RequestInfo
+}
+
+// trackRequest records a request as in flight
+func trackRequest(endpoint, model string, messages []Message, stream bool, contextSize int) *requestTracker {
+	chars := 0
+	for _, msg := range messages {
+		chars += len(msg.Content)
+	}
+	t := &requestTracker{info: RequestInfo{
+		Started:     time.Now(),
+		Endpoint:    endpoint,
+		Model:       model,
+		Stream:      stream,
+		Messages:    len(messages),
+		PromptChars: chars,
+		ContextSize: contextSize,
+	}}
+	info := t.info
+	lastRequest.Store(&info)
+	notifyActivity(info, int(activeRequests.Add(1)))
+	return t
+}
+
+// finish records the response status or the error that ended the request
+func (t *requestTracker) finish(status int, err error) {
+	info := t.info
+	info.Duration = time.Since(info.Started)
+	info.Status = status
+	if err != nil {
+		info.Error = err.Error()
+		info.err = err
+	}
+	t.info = info
+	lastRequest.Store(&info)
+	notifyObservers(info)
+}
+
+// done marks the response as read to the end, or abandoned
+func (t *requestTracker) done() {
+	notifyActivity(t.info, int(activeRequests.Add(-1)))
+}
+
+// activeRequests counts chat requests sent and not yet read to the end
+var activeRequests atomic.Int64
+
+var activityObservers struct {
+	sync.Mutex
+	fns []func(RequestInfo, int)
+}
+
+// ObserveActivity calls fn when a chat request to the model server starts
+// and when its response has been read to the end, with how many are in
+// flight. The request's Duration is zero when it has just started.
+func ObserveActivity(fn func(info RequestInfo, active int)) {
+	activityObservers.Lock()
+	defer activityObservers.Unlock()
+	activityObservers.fns = append(activityObservers.fns, fn)
+}
+
+// notifyActivity passes a started or finished request to every observer
+func notifyActivity(info RequestInfo, active int) {
+	activityObservers.Lock()
+	fns := append([]func(RequestInfo, int){}, activityObservers.fns...)
+	activityObservers.Unlock()
+	for _, fn := range fns {
+		fn(info, active)
+	}
+}
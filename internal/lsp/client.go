@@ -0,0 +1,334 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// message is a JSON-RPC 2.0 request, response or notification
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *ResponseError   `json:"error,omitempty"`
+}
+
+// ResponseError is an error returned by the server
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("language server error %d: %s", e.Code, e.Message)
+}
+
+// Client is a connection to one language server process
+type Client struct {
+	name    string
+	rootDir string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *message
+	opened  map[string]int // URI -> version
+	closed  bool
+	done    chan struct{}
+}
+
+// StartClient launches a server and performs the initialize handshake
+func StartClient(ctx context.Context, name, command string, args []string, rootDir string) (*Client, error) {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return nil, fmt.Errorf("%s language server %q not found in PATH", name, command)
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Dir = rootDir
+	cmd.Stderr = io.Discard
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", command, err)
+	}
+
+	c := &Client{
+		name:    name,
+		rootDir: rootDir,
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[int64]chan *message),
+		opened:  make(map[string]int),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop(bufio.NewReader(stdout))
+
+	if err := c.initialize(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// initialize sends initialize and initialized
+func (c *Client) initialize(ctx context.Context) error {
+	rootURI := PathToURI(c.rootDir)
+	params := map[string]any{
+		"processId": os.Getpid(),
+		"rootUri":   rootURI,
+		"workspaceFolders": []map[string]string{
+			{"uri": rootURI, "name": c.rootDir},
+		},
+		"capabilities": map[string]any{
+			"textDocument": map[string]any{
+				"definition":     map[string]any{"linkSupport": false},
+				"references":     map[string]any{},
+				"documentSymbol": map[string]any{"hierarchicalDocumentSymbolSupport": true},
+				"synchronization": map[string]any{
+					"didSave": false,
+				},
+			},
+			"workspace": map[string]any{
+				"workspaceFolders": true,
+				"configuration":    true,
+			},
+		},
+	}
+	if err := c.Call(ctx, "initialize", params, nil); err != nil {
+		return fmt.Errorf("initializing %s: %w", c.name, err)
+	}
+	return c.Notify("initialized", map[string]any{})
+}
+
+// Call sends a request and decodes its result into result (if non-nil)
+func (c *Client) Call(ctx context.Context, method string, params any, result any) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return fmt.Errorf("%s language server is not running", c.name)
+	}
+	c.nextID++
+	id := c.nextID
+	ch := make(chan *message, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	raw := json.RawMessage(strconv.FormatInt(id, 10))
+	if err := c.send(&message{ID: &raw, Method: method, Params: mustMarshal(params)}); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		_ = c.Notify("$/cancelRequest", map[string]any{"id": id})
+		return ctx.Err()
+	case <-c.done:
+		return fmt.Errorf("%s language server exited", c.name)
+	case resp := <-ch:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result != nil && len(resp.Result) > 0 {
+			return json.Unmarshal(resp.Result, result)
+		}
+		return nil
+	}
+}
+
+// Notify sends a notification
+func (c *Client) Notify(method string, params any) error {
+	return c.send(&message{Method: method, Params: mustMarshal(params)})
+}
+
+// OpenFile tells the server about a file's current content. Files already
+// open are re-sent so the server sees edits made since.
+func (c *Client) OpenFile(path, languageID string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	uri := PathToURI(path)
+
+	c.mu.Lock()
+	version, open := c.opened[uri]
+	version++
+	c.opened[uri] = version
+	c.mu.Unlock()
+
+	if open {
+		return uri, c.Notify("textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": uri, "version": version},
+			"contentChanges": []map[string]any{{"text": string(data)}},
+		})
+	}
+	return uri, c.Notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{
+			"uri":        uri,
+			"languageId": languageID,
+			"version":    version,
+			"text":       string(data),
+		},
+	})
+}
+
+// Close shuts the server down, killing it if it does not exit promptly
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_ = c.Call(ctx, "shutdown", nil, nil)
+	_ = c.Notify("exit", nil)
+
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	c.stdin.Close()
+
+	exited := make(chan struct{})
+	go func() {
+		c.cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(2 * time.Second):
+		c.cmd.Process.Kill()
+	}
+	return nil
+}
+
+// send writes one framed message
+func (c *Client) send(msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := fmt.Fprintf(c.stdin, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = c.stdin.Write(body)
+	return err
+}
+
+// readLoop dispatches responses and answers server-initiated requests
+func (c *Client) readLoop(r *bufio.Reader) {
+	defer close(c.done)
+	for {
+		msg, err := readMessage(r)
+		if err != nil {
+			return
+		}
+
+		switch {
+		case msg.ID != nil && msg.Method == "":
+			id, err := strconv.ParseInt(string(*msg.ID), 10, 64)
+			if err != nil {
+				continue
+			}
+			c.mu.Lock()
+			ch, ok := c.pending[id]
+			c.mu.Unlock()
+			if ok {
+				ch <- msg
+			}
+		case msg.ID != nil:
+			// Requests from the server (configuration, progress tokens, ...)
+			// get an empty success so it does not stall
+			go c.replyEmpty(msg)
+		}
+	}
+}
+
+// replyEmpty answers a server request with a neutral result
+func (c *Client) replyEmpty(req *message) {
+	result := json.RawMessage("null")
+	if req.Method == "workspace/configuration" {
+		var params struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		result = mustMarshal(make([]any, len(params.Items)))
+	}
+	_ = c.send(&message{ID: req.ID, Result: result})
+}
+
+// readMessage reads one Content-Length framed message
+func readMessage(r *bufio.Reader) (*message, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("bad Content-Length %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("message without Content-Length")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func mustMarshal(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,23 @@
+// Package lsp runs language servers (gopls, typescript-language-server, ...)
+// for the project and answers structural questions about the code.
+//
+// # Overview
+//
+// Raw file reads tell the model what a file says; a language server tells
+// it what a symbol means: where it is defined, who uses it and what a file
+// declares. This package speaks just enough of the Language Server
+// Protocol to answer those questions.
+//
+// # Architecture
+//
+//   - Client: one JSON-RPC connection to a server process over stdio
+//   - Manager: starts one client per language on first use, keyed by file
+//     extension, and shuts them all down on exit
+//
+// # Usage in Loco
+//
+// The find_definition, find_references and symbol_outline tools call the
+// Manager, and rename_symbol has it compute renames. Servers are
+// configured under "lsp" in .loco/config.jsonc; a missing server binary
+// only disables the tools for that language.
+package lsp
@@ -0,0 +1,287 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/config"
+)
+
+// startTimeout bounds how long a server may take to initialize
+const startTimeout = 30 * time.Second
+
+// Manager starts language servers on demand, one per configured language
+type Manager struct {
+	workingDir string
+	servers    map[string]config.LSPServerConfig
+
+	mu      sync.Mutex
+	clients map[string]*Client
+	failed  map[string]error // Servers that could not start are not retried
+}
+
+// NewManager creates a manager for the project
+func NewManager(workingDir string, servers map[string]config.LSPServerConfig) *Manager {
+	return &Manager{
+		workingDir: workingDir,
+		servers:    servers,
+		clients:    make(map[string]*Client),
+		failed:     make(map[string]error),
+	}
+}
+
+// ClientFor returns the running client for a file, starting it if needed,
+// along with the LSP language ID to open the file with.
+func (m *Manager) ClientFor(ctx context.Context, path string) (*Client, string, error) {
+	name, server, ok := m.serverFor(path)
+	if !ok {
+		return nil, "", fmt.Errorf("no language server configured for %s files", filepath.Ext(path))
+	}
+	languageID := server.LanguageID
+	if languageID == "" {
+		languageID = name
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if client, ok := m.clients[name]; ok {
+		return client, languageID, nil
+	}
+	if err, ok := m.failed[name]; ok {
+		return nil, "", err
+	}
+
+	startCtx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+	client, err := StartClient(startCtx, name, server.Command, server.Args, m.workingDir)
+	if err != nil {
+		// Only remember failures that retrying will not fix
+		if ctx.Err() == nil {
+			m.failed[name] = err
+		}
+		return nil, "", err
+	}
+	m.clients[name] = client
+	return client, languageID, nil
+}
+
+// Definition returns where the symbol at a position is defined
+func (m *Manager) Definition(ctx context.Context, path string, pos Position) ([]Location, error) {
+	return m.locations(ctx, "textDocument/definition", path, pos, false)
+}
+
+// References returns every use of the symbol at a position
+func (m *Manager) References(ctx context.Context, path string, pos Position, includeDeclaration bool) ([]Location, error) {
+	return m.locations(ctx, "textDocument/references", path, pos, includeDeclaration)
+}
+
+// Symbols returns the symbols a file declares, as a tree
+func (m *Manager) Symbols(ctx context.Context, path string) ([]DocumentSymbol, error) {
+	client, languageID, err := m.ClientFor(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	uri, err := client.OpenFile(path, languageID)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw json.RawMessage
+	if err := client.Call(ctx, "textDocument/documentSymbol", map[string]any{
+		"textDocument": TextDocumentIdentifier{URI: uri},
+	}, &raw); err != nil {
+		return nil, err
+	}
+	return decodeSymbols(raw)
+}
+
+// Rename returns the edits that rename the symbol at a position to
+// newName, by file path. The server only computes them; nothing is written.
+func (m *Manager) Rename(ctx context.Context, path string, pos Position, newName string) (map[string][]TextEdit, error) {
+	client, languageID, err := m.ClientFor(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	uri, err := client.OpenFile(path, languageID)
+	if err != nil {
+		return nil, err
+	}
+
+	var edit WorkspaceEdit
+	if err := client.Call(ctx, "textDocument/rename", map[string]any{
+		"textDocument": TextDocumentIdentifier{URI: uri},
+		"position":     pos,
+		"newName":      newName,
+	}, &edit); err != nil {
+		return nil, err
+	}
+
+	edits := make(map[string][]TextEdit)
+	for uri, changes := range edit.Changes {
+		edits[URIToPath(uri)] = append(edits[URIToPath(uri)], changes...)
+	}
+	for _, change := range edit.DocumentChanges {
+		if change.Kind != "" {
+			return nil, fmt.Errorf("the server wants to %s a file, which renames here do not do", change.Kind)
+		}
+		path := URIToPath(change.TextDocument.URI)
+		edits[path] = append(edits[path], change.Edits...)
+	}
+	return edits, nil
+}
+
+// Close shuts down every running server
+func (m *Manager) Close() {
+	m.mu.Lock()
+	clients := m.clients
+	m.clients = make(map[string]*Client)
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			c.Close()
+		}(client)
+	}
+	wg.Wait()
+}
+
+// locations runs a position request that answers with locations
+func (m *Manager) locations(ctx context.Context, method, path string, pos Position, includeDeclaration bool) ([]Location, error) {
+	client, languageID, err := m.ClientFor(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	uri, err := client.OpenFile(path, languageID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := ReferenceParams{TextDocumentPositionParams: TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     pos,
+	}}
+	params.Context.IncludeDeclaration = includeDeclaration
+
+	var raw json.RawMessage
+	var callParams any = params.TextDocumentPositionParams
+	if method == "textDocument/references" {
+		callParams = params
+	}
+	if err := client.Call(ctx, method, callParams, &raw); err != nil {
+		return nil, err
+	}
+
+	locations, err := decodeLocations(raw)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(locations, func(i, j int) bool {
+		if locations[i].URI != locations[j].URI {
+			return locations[i].URI < locations[j].URI
+		}
+		return locations[i].Range.Start.Line < locations[j].Range.Start.Line
+	})
+	return locations, nil
+}
+
+// serverFor finds the configured server handling a file's extension
+func (m *Manager) serverFor(path string) (string, config.LSPServerConfig, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	names := make([]string, 0, len(m.servers))
+	for name := range m.servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		server := m.servers[name]
+		if server.Command == "" {
+			continue
+		}
+		for _, e := range server.Extensions {
+			if strings.ToLower(e) == ext {
+				return name, server, true
+			}
+		}
+	}
+	return "", config.LSPServerConfig{}, false
+}
+
+// decodeLocations accepts Location, []Location or []LocationLink
+func decodeLocations(raw json.RawMessage) ([]Location, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	if raw[0] == '{' {
+		var loc Location
+		if err := json.Unmarshal(raw, &loc); err != nil {
+			return nil, err
+		}
+		return []Location{loc}, nil
+	}
+
+	var items []struct {
+		Location
+		TargetURI            string `json:"targetUri"`
+		TargetSelectionRange Range  `json:"targetSelectionRange"`
+	}
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	locations := make([]Location, 0, len(items))
+	for _, item := range items {
+		if item.TargetURI != "" {
+			locations = append(locations, Location{URI: item.TargetURI, Range: item.TargetSelectionRange})
+			continue
+		}
+		locations = append(locations, item.Location)
+	}
+	return locations, nil
+}
+
+// decodeSymbols accepts []DocumentSymbol or the flat []SymbolInformation
+func decodeSymbols(raw json.RawMessage) ([]DocumentSymbol, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var probe []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, err
+	}
+	if len(probe) == 0 {
+		return nil, nil
+	}
+	if _, hierarchical := probe[0]["selectionRange"]; hierarchical {
+		var symbols []DocumentSymbol
+		err := json.Unmarshal(raw, &symbols)
+		return symbols, err
+	}
+
+	var flat []SymbolInformation
+	if err := json.Unmarshal(raw, &flat); err != nil {
+		return nil, err
+	}
+	symbols := make([]DocumentSymbol, 0, len(flat))
+	for _, info := range flat {
+		symbols = append(symbols, DocumentSymbol{
+			Name:           info.Name,
+			Detail:         info.ContainerName,
+			Kind:           info.Kind,
+			Range:          info.Location.Range,
+			SelectionRange: info.Location.Range,
+		})
+	}
+	return symbols, nil
+}
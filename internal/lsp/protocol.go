@@ -0,0 +1,144 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Position is a zero-based line and UTF-16 character offset
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span between two positions
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range inside a document
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// TextDocumentIdentifier names a document by URI
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// TextDocumentPositionParams points at a position in a document
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// ReferenceParams asks for the references to a symbol
+type ReferenceParams struct {
+	TextDocumentPositionParams
+	Context struct {
+		IncludeDeclaration bool `json:"includeDeclaration"`
+	} `json:"context"`
+}
+
+// DocumentSymbol is a hierarchical symbol returned by documentSymbol
+type DocumentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail,omitempty"`
+	Kind           SymbolKind       `json:"kind"`
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
+// SymbolInformation is the flat symbol form some servers return
+type SymbolInformation struct {
+	Name          string     `json:"name"`
+	Kind          SymbolKind `json:"kind"`
+	Location      Location   `json:"location"`
+	ContainerName string     `json:"containerName,omitempty"`
+}
+
+// TextEdit replaces a range of a document with new text
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// WorkspaceEdit is a set of edits across documents, in either of the two
+// forms servers send
+type WorkspaceEdit struct {
+	Changes         map[string][]TextEdit `json:"changes,omitempty"`
+	DocumentChanges []struct {
+		TextDocument TextDocumentIdentifier `json:"textDocument"`
+		Edits        []TextEdit             `json:"edits"`
+		Kind         string                 `json:"kind,omitempty"` // Set on create, rename and delete operations
+	} `json:"documentChanges,omitempty"`
+}
+
+// SymbolKind is the LSP symbol kind enumeration
+type SymbolKind int
+
+var symbolKindNames = map[SymbolKind]string{
+	1: "file", 2: "module", 3: "namespace", 4: "package", 5: "class",
+	6: "method", 7: "property", 8: "field", 9: "constructor", 10: "enum",
+	11: "interface", 12: "function", 13: "variable", 14: "constant", 15: "string",
+	16: "number", 17: "boolean", 18: "array", 19: "object", 20: "key",
+	21: "null", 22: "enum member", 23: "struct", 24: "event", 25: "operator",
+	26: "type parameter",
+}
+
+// String returns the lower-case kind name
+func (k SymbolKind) String() string {
+	if name, ok := symbolKindNames[k]; ok {
+		return name
+	}
+	return "symbol"
+}
+
+// PathToURI converts an absolute file path to a file:// URI
+func PathToURI(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
+
+// URIToPath converts a file:// URI back to a path
+func URIToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return strings.TrimPrefix(uri, "file://")
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// UTF16Column converts a byte offset within line to a UTF-16 offset
+func UTF16Column(line string, byteOffset int) int {
+	if byteOffset > len(line) {
+		byteOffset = len(line)
+	}
+	col := 0
+	for _, r := range line[:byteOffset] {
+		col += len(utf16.Encode([]rune{r}))
+	}
+	return col
+}
+
+// ByteColumn converts a UTF-16 offset within line to a byte offset
+func ByteColumn(line string, utf16Offset int) int {
+	col := 0
+	for i, r := range line {
+		if col >= utf16Offset {
+			return i
+		}
+		if r == utf8.RuneError {
+			col++
+			continue
+		}
+		col += len(utf16.Encode([]rune{r}))
+	}
+	return len(line)
+}
@@ -0,0 +1,305 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/config"
+)
+
+// Client is a connection to one external MCP server process over stdio
+type Client struct {
+	name    string
+	rootDir string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  *tailWriter
+	info    Implementation
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *message
+	closed  bool
+	done    chan struct{}
+}
+
+// StartClient launches a server and performs the initialize handshake
+func StartClient(ctx context.Context, name string, cfg config.MCPServerConfig, rootDir string) (*Client, error) {
+	path, err := exec.LookPath(cfg.Command)
+	if err != nil {
+		return nil, fmt.Errorf("%s MCP server %q not found in PATH", name, cfg.Command)
+	}
+
+	cmd := exec.Command(path, cfg.Args...)
+	cmd.Dir = rootDir
+	cmd.Env = os.Environ()
+	for key, value := range cfg.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	stderr := &tailWriter{limit: 2048}
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", cfg.Command, err)
+	}
+
+	c := &Client{
+		name:    name,
+		rootDir: rootDir,
+		cmd:     cmd,
+		stdin:   stdin,
+		stderr:  stderr,
+		pending: make(map[int64]chan *message),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop(bufio.NewReader(stdout))
+
+	if err := c.initialize(ctx); err != nil {
+		c.Close()
+		if tail := strings.TrimSpace(stderr.String()); tail != "" {
+			err = fmt.Errorf("%w: %s", err, tail)
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// Info returns the name and version the server reported
+func (c *Client) Info() Implementation {
+	return c.info
+}
+
+// initialize sends initialize and notifications/initialized
+func (c *Client) initialize(ctx context.Context) error {
+	params := initializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities: map[string]any{
+			"roots": map[string]any{"listChanged": false},
+		},
+		ClientInfo: Implementation{Name: "loco", Version: "dev"},
+	}
+	var result initializeResult
+	if err := c.Call(ctx, "initialize", params, &result); err != nil {
+		return fmt.Errorf("initializing %s: %w", c.name, err)
+	}
+	c.info = result.ServerInfo
+	return c.Notify("notifications/initialized", nil)
+}
+
+// ListTools returns every tool the server offers, following pagination
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var all []Tool
+	cursor := ""
+	for {
+		var result listToolsResult
+		if err := c.Call(ctx, "tools/list", listParams{Cursor: cursor}, &result); err != nil {
+			return nil, err
+		}
+		all = append(all, result.Tools...)
+		if result.NextCursor == "" || result.NextCursor == cursor {
+			return all, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// CallTool runs a tool with JSON-encoded arguments
+func (c *Client) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*CallToolResult, error) {
+	var result CallToolResult
+	if err := c.Call(ctx, "tools/call", callToolParams{Name: name, Arguments: arguments}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Call sends a request and decodes its result into result (if non-nil)
+func (c *Client) Call(ctx context.Context, method string, params any, result any) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return fmt.Errorf("%s MCP server is not running", c.name)
+	}
+	c.nextID++
+	id := c.nextID
+	ch := make(chan *message, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	raw := json.RawMessage(strconv.FormatInt(id, 10))
+	if err := c.send(&message{ID: raw, Method: method, Params: mustMarshal(params)}); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		_ = c.Notify("notifications/cancelled", cancelledParams{RequestID: raw, Reason: ctx.Err().Error()})
+		return ctx.Err()
+	case <-c.done:
+		return fmt.Errorf("%s MCP server exited", c.name)
+	case resp := <-ch:
+		if resp.Error != nil {
+			return fmt.Errorf("%s MCP server error %d: %s", c.name, resp.Error.Code, resp.Error.Message)
+		}
+		if result != nil && len(resp.Result) > 0 {
+			return json.Unmarshal(resp.Result, result)
+		}
+		return nil
+	}
+}
+
+// Notify sends a notification
+func (c *Client) Notify(method string, params any) error {
+	return c.send(&message{Method: method, Params: mustMarshal(params)})
+}
+
+// Close closes the server's stdin, killing it if it does not exit promptly
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+	c.stdin.Close()
+
+	exited := make(chan struct{})
+	go func() {
+		c.cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(2 * time.Second):
+		c.cmd.Process.Kill()
+	}
+	return nil
+}
+
+// send writes one message as a single line
+func (c *Client) send(msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = c.stdin.Write(append(body, '\n'))
+	return err
+}
+
+// readLoop dispatches responses and answers server-initiated requests
+func (c *Client) readLoop(r *bufio.Reader) {
+	defer close(c.done)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			c.handle(line)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *Client) handle(line []byte) {
+	var msg message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		// Some servers log to stdout; skip anything that isn't JSON-RPC
+		return
+	}
+
+	switch {
+	case msg.isRequest():
+		go c.reply(&msg)
+	case len(msg.ID) > 0:
+		id, err := strconv.ParseInt(string(msg.ID), 10, 64)
+		if err != nil {
+			return
+		}
+		c.mu.Lock()
+		ch, ok := c.pending[id]
+		c.mu.Unlock()
+		if ok {
+			ch <- &msg
+		}
+	}
+	// Notifications (progress, logging, list changes) are ignored
+}
+
+// reply answers a request from the server
+func (c *Client) reply(req *message) {
+	resp := &message{ID: req.ID}
+	switch req.Method {
+	case "ping":
+		resp.Result = json.RawMessage("{}")
+	case "roots/list":
+		resp.Result = mustMarshal(map[string]any{
+			"roots": []root{{URI: "file://" + filepath.ToSlash(c.rootDir), Name: filepath.Base(c.rootDir)}},
+		})
+	default:
+		resp.Error = &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+	_ = c.send(resp)
+}
+
+func mustMarshal(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// tailWriter keeps the last limit bytes written to it, so a server that
+// fails to start can explain why
+type tailWriter struct {
+	mu    sync.Mutex
+	limit int
+	buf   []byte
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	if over := len(w.buf) - w.limit; over > 0 {
+		w.buf = w.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.buf)
+}
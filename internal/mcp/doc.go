@@ -0,0 +1,34 @@
+// Package mcp speaks the Model Context Protocol in both directions: MCP
+// clients such as Claude Desktop can use what Loco knows about a project,
+// and Loco can use the tools of external MCP servers.
+//
+// # Overview
+//
+// `loco mcp` runs a Server over stdio: newline-delimited JSON-RPC 2.0 on
+// stdin and stdout, with logs kept to stderr. It offers:
+//
+//   - Tools: every tool in the registry except the TUI-only ones (chat,
+//     copy, startup_welcome), with their parameter schemas
+//   - Resources: the cached analysis of each tier as JSON
+//     (loco://analysis/{tier}) and its knowledge documents as markdown
+//     (loco://knowledge/{tier}/{file})
+//
+// # Usage
+//
+// Point the client at the project with -C, for example in Claude Desktop's
+// claude_desktop_config.json:
+//
+//	{"mcpServers": {"loco": {"command": "loco", "args": ["-C", "/path/to/project", "mcp"]}}}
+//
+// Tools still go through Loco's permission policies. There is no dialog to
+// answer prompts, so only tools whose policy is "allow" will act.
+//
+// # External servers
+//
+// Servers listed under "mcp_servers" in .loco/config.jsonc are started by a
+// Manager when the app starts (over stdio, in parallel, each given a few
+// seconds to connect). Each of their tools is registered as
+// mcp_<server>_<tool> and asks permission before every call, so the usual
+// tool_policies apply; an unlisted tool defaults to "ask". /mcp shows which
+// servers connected and why others did not.
+package mcp
@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/permission"
+	"github.com/billie-coop/loco/internal/tools"
+)
+
+// connectTimeout bounds how long a server may take to start and list its
+// tools. Servers connect while Loco starts, so this is kept short.
+const connectTimeout = 10 * time.Second
+
+// ServerStatus describes one configured server
+type ServerStatus struct {
+	Name     string
+	Command  string
+	Disabled bool
+	Info     Implementation // Reported by the server once connected
+	Tools    []string       // Registered tool names
+	Err      error          // Why the server is not connected
+}
+
+// Manager connects to the external MCP servers in the config
+type Manager struct {
+	workingDir string
+	servers    map[string]config.MCPServerConfig
+
+	mu      sync.Mutex
+	clients map[string]*Client
+	tools   map[string][]Tool
+	failed  map[string]error
+}
+
+// NewManager creates a manager for the project
+func NewManager(workingDir string, servers map[string]config.MCPServerConfig) *Manager {
+	return &Manager{
+		workingDir: workingDir,
+		servers:    servers,
+		clients:    make(map[string]*Client),
+		tools:      make(map[string][]Tool),
+		failed:     make(map[string]error),
+	}
+}
+
+// Connect starts every enabled server in parallel and lists its tools.
+// A server that fails is recorded and left out; it does not stop the rest.
+func (m *Manager) Connect(ctx context.Context) {
+	var wg sync.WaitGroup
+	for name, server := range m.servers {
+		if server.Disabled {
+			continue
+		}
+		wg.Add(1)
+		go func(name string, server config.MCPServerConfig) {
+			defer wg.Done()
+			m.connect(ctx, name, server)
+		}(name, server)
+	}
+	wg.Wait()
+}
+
+func (m *Manager) connect(ctx context.Context, name string, server config.MCPServerConfig) {
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	client, err := StartClient(ctx, name, server, m.workingDir)
+	if err == nil {
+		var list []Tool
+		list, err = client.ListTools(ctx)
+		if err == nil {
+			m.mu.Lock()
+			m.clients[name] = client
+			m.tools[name] = list
+			m.mu.Unlock()
+			return
+		}
+		client.Close()
+	}
+
+	m.mu.Lock()
+	m.failed[name] = err
+	m.mu.Unlock()
+}
+
+// Tools adapts every connected server's tools for the registry. Calls go
+// through permissions under the registered name, so tool_policies applies.
+func (m *Manager) Tools(permissions permission.Service) []tools.BaseTool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []tools.BaseTool
+	for _, server := range sortedKeys(m.tools) {
+		for _, tool := range m.tools[server] {
+			result = append(result, &remoteTool{
+				name:        ToolName(server, tool.Name),
+				server:      server,
+				tool:        tool,
+				client:      m.clients[server],
+				permissions: permissions,
+			})
+		}
+	}
+	return result
+}
+
+// Status reports every configured server, sorted by name
+func (m *Manager) Status() []ServerStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]ServerStatus, 0, len(m.servers))
+	for _, name := range sortedKeys(m.servers) {
+		server := m.servers[name]
+		status := ServerStatus{
+			Name:     name,
+			Command:  server.Command,
+			Disabled: server.Disabled,
+			Err:      m.failed[name],
+		}
+		if client, ok := m.clients[name]; ok {
+			status.Info = client.Info()
+			for _, tool := range m.tools[name] {
+				status.Tools = append(status.Tools, ToolName(name, tool.Name))
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Close shuts down every connected server
+func (m *Manager) Close() {
+	m.mu.Lock()
+	clients := m.clients
+	m.clients = make(map[string]*Client)
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			c.Close()
+		}(client)
+	}
+	wg.Wait()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
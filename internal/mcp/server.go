@@ -0,0 +1,401 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/tools"
+)
+
+// Resource URI prefixes
+const (
+	analysisURIPrefix  = "loco://analysis/"  // loco://analysis/{tier}
+	knowledgeURIPrefix = "loco://knowledge/" // loco://knowledge/{tier}/{file}
+)
+
+// hiddenTools only make sense inside the TUI
+var hiddenTools = map[string]bool{
+	"chat":                       true,
+	"copy":                       true,
+	tools.ModelToolName:          true,
+	tools.StartupWelcomeToolName: true,
+}
+
+// exposed reports whether a registry tool is offered to MCP clients. Tools
+// proxied from other MCP servers are left out; clients can connect to those
+// servers directly.
+func exposed(tool tools.BaseTool) bool {
+	if _, remote := tool.(*remoteTool); remote {
+		return false
+	}
+	return !hiddenTools[tool.Name()]
+}
+
+// serverInstructions tells clients what the server is for
+const serverInstructions = `Loco exposes a local project's code tools and the knowledge it has built about it.
+Resources hold cached analyses and knowledge documents per tier (quick, detailed, deep, full).
+Use ask_codebase or rag_query to search the indexed code.`
+
+// Server answers MCP requests over a stream, one JSON-RPC message per line
+type Server struct {
+	tools       *tools.Registry
+	analysis    analysis.Service
+	projectPath string
+	info        Implementation
+
+	writeMu sync.Mutex
+	out     io.Writer
+
+	// Cancel functions of requests still running, by JSON-encoded ID
+	inflightMu sync.Mutex
+	inflight   map[string]context.CancelFunc
+}
+
+// NewServer creates an MCP server exposing registry's tools and the cached
+// analyses of projectPath
+func NewServer(registry *tools.Registry, analysisService analysis.Service, projectPath, version string) *Server {
+	return &Server{
+		tools:       registry,
+		analysis:    analysisService,
+		projectPath: projectPath,
+		info:        Implementation{Name: "loco", Version: version},
+		inflight:    make(map[string]context.CancelFunc),
+	}
+}
+
+// Serve reads requests from in and writes responses to out until in is
+// closed or ctx is canceled. Requests run concurrently so a slow tool call
+// doesn't hold up pings or cancellations.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = out
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(strings.TrimSpace(string(line))) > 0 {
+				lines <- line
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.cancelAll()
+			return ctx.Err()
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case line := <-lines:
+			var msg message
+			if err := json.Unmarshal(line, &msg); err != nil {
+				s.writeError(json.RawMessage("null"), codeParseError, "invalid JSON")
+				continue
+			}
+			if msg.JSONRPC != "2.0" {
+				if len(msg.ID) > 0 {
+					s.writeError(msg.ID, codeInvalidRequest, "jsonrpc must be \"2.0\"")
+				}
+				continue
+			}
+			if msg.isRequest() {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					s.handleRequest(ctx, &msg)
+				}()
+			} else if msg.Method != "" {
+				s.handleNotification(&msg)
+			}
+			// Responses are ignored; the server sends no requests
+		}
+	}
+}
+
+// handleRequest runs one request and writes its response
+func (s *Server) handleRequest(ctx context.Context, msg *message) {
+	ctx, cancel := context.WithCancel(ctx)
+	key := string(msg.ID)
+	s.inflightMu.Lock()
+	s.inflight[key] = cancel
+	s.inflightMu.Unlock()
+	defer func() {
+		s.inflightMu.Lock()
+		delete(s.inflight, key)
+		s.inflightMu.Unlock()
+		cancel()
+	}()
+
+	result, err := s.dispatch(ctx, msg.Method, msg.Params)
+	if ctx.Err() != nil {
+		// Cancelled requests get no response
+		return
+	}
+	if err != nil {
+		var rpcErr *rpcError
+		if errors.As(err, &rpcErr) {
+			s.writeError(msg.ID, rpcErr.Code, rpcErr.Message)
+		} else {
+			s.writeError(msg.ID, codeInternalError, err.Error())
+		}
+		return
+	}
+	s.writeResult(msg.ID, result)
+}
+
+// handleNotification reacts to messages that expect no response
+func (s *Server) handleNotification(msg *message) {
+	if msg.Method != "notifications/cancelled" {
+		return
+	}
+	var params cancelledParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return
+	}
+	s.inflightMu.Lock()
+	cancel := s.inflight[string(params.RequestID)]
+	s.inflightMu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// cancelAll cancels every running request
+func (s *Server) cancelAll() {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	for _, cancel := range s.inflight {
+		cancel()
+	}
+}
+
+// dispatch routes a request to its handler
+func (s *Server) dispatch(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		return s.initialize(params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(ctx, params)
+	case "resources/list":
+		return s.listResources(), nil
+	case "resources/read":
+		return s.readResource(params)
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", method)}
+}
+
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	var req initializeParams
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	// Answer in the client's revision when we know it, else our newest
+	version := ProtocolVersion
+	if slices.Contains(supportedVersions, req.ProtocolVersion) {
+		version = req.ProtocolVersion
+	}
+
+	return initializeResult{
+		ProtocolVersion: version,
+		Capabilities: map[string]any{
+			"tools":     map[string]any{},
+			"resources": map[string]any{},
+		},
+		ServerInfo:   s.info,
+		Instructions: serverInstructions,
+	}, nil
+}
+
+func (s *Server) listTools() listToolsResult {
+	result := listToolsResult{Tools: []Tool{}}
+	for _, tool := range s.tools.GetAll() {
+		info := tool.Info()
+		if !exposed(tool) {
+			continue
+		}
+		properties, required, _ := s.tools.ParamSchema(info.Name)
+		schema := map[string]any{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		result.Tools = append(result.Tools, Tool{
+			Name:        info.Name,
+			Description: info.Description,
+			InputSchema: schema,
+		})
+	}
+	sort.Slice(result.Tools, func(i, j int) bool {
+		return result.Tools[i].Name < result.Tools[j].Name
+	})
+	return result
+}
+
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var req callToolParams
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	tool, ok := s.tools.Get(req.Name)
+	if !ok || !exposed(tool) {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", req.Name)}
+	}
+
+	input := string(req.Arguments)
+	if input == "" || input == "null" {
+		input = "{}"
+	}
+	ctx = context.WithValue(ctx, tools.InitiatorKey, "agent")
+
+	// Tool failures are results the model can read, not protocol errors
+	response, err := tool.Run(ctx, tools.ToolCall{Name: req.Name, Input: input})
+	if err != nil {
+		return CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Tool execution failed: %v", err)}},
+			IsError: true,
+		}, nil
+	}
+	return CallToolResult{
+		Content: []Content{{Type: "text", Text: response.Content}},
+		IsError: response.IsError,
+	}, nil
+}
+
+// tiers lists analysis tiers from fastest to most thorough
+var tiers = []analysis.Tier{analysis.TierInstant, analysis.TierQuick, analysis.TierDetailed, analysis.TierDeep, analysis.TierFull}
+
+func (s *Server) listResources() listResourcesResult {
+	result := listResourcesResult{Resources: []Resource{}}
+	if s.analysis == nil {
+		return result
+	}
+
+	for _, tier := range tiers {
+		cached, err := s.analysis.GetCachedAnalysis(s.projectPath, tier)
+		if err != nil || cached == nil {
+			continue
+		}
+		result.Resources = append(result.Resources, Resource{
+			URI:         analysisURIPrefix + string(tier),
+			Name:        fmt.Sprintf("%s analysis", tier),
+			Description: fmt.Sprintf("Loco's %s analysis of the project, generated %s", tier, cached.GetGenerated().Format("2006-01-02 15:04")),
+			MimeType:    "application/json",
+		})
+
+		files := cached.GetKnowledgeFiles()
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			result.Resources = append(result.Resources, Resource{
+				URI:         knowledgeURIPrefix + string(tier) + "/" + name,
+				Name:        fmt.Sprintf("%s (%s)", name, tier),
+				Description: fmt.Sprintf("Knowledge document from the %s analysis", tier),
+				MimeType:    "text/markdown",
+			})
+		}
+	}
+	return result
+}
+
+func (s *Server) readResource(params json.RawMessage) (any, error) {
+	var req readResourceParams
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	notFound := &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("resource not found: %s", req.URI)}
+	if s.analysis == nil {
+		return nil, notFound
+	}
+
+	var tier, file string
+	switch {
+	case strings.HasPrefix(req.URI, analysisURIPrefix):
+		tier = strings.TrimPrefix(req.URI, analysisURIPrefix)
+	case strings.HasPrefix(req.URI, knowledgeURIPrefix):
+		var ok bool
+		tier, file, ok = strings.Cut(strings.TrimPrefix(req.URI, knowledgeURIPrefix), "/")
+		if !ok {
+			return nil, notFound
+		}
+	default:
+		return nil, notFound
+	}
+
+	cached, err := s.analysis.GetCachedAnalysis(s.projectPath, analysis.Tier(tier))
+	if err != nil || cached == nil {
+		return nil, notFound
+	}
+
+	contents := resourceContents{URI: req.URI}
+	if file == "" {
+		data, err := json.MarshalIndent(cached, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		contents.MimeType = "application/json"
+		contents.Text = string(data)
+	} else {
+		text, ok := cached.GetKnowledgeFiles()[file]
+		if !ok {
+			return nil, notFound
+		}
+		contents.MimeType = "text/markdown"
+		contents.Text = text
+	}
+	return readResourceResult{Contents: []resourceContents{contents}}, nil
+}
+
+func (s *Server) writeResult(id json.RawMessage, result any) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		s.writeError(id, codeInternalError, err.Error())
+		return
+	}
+	s.write(message{JSONRPC: "2.0", ID: id, Result: data})
+}
+
+func (s *Server) writeError(id json.RawMessage, code int, text string) {
+	s.write(message{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: text}})
+}
+
+// write sends one message as a single line
+func (s *Server) write(msg message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, _ = s.out.Write(append(data, '\n'))
+}
@@ -0,0 +1,203 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/permission"
+	"github.com/billie-coop/loco/internal/tools"
+)
+
+// ToolNamePrefix starts the registry name of every external MCP tool
+const ToolNamePrefix = "mcp_"
+
+// maxToolNameLength is the longest tool name most model APIs accept
+const maxToolNameLength = 64
+
+// ToolName is the registry name of a server's tool: mcp_<server>_<tool>,
+// with anything outside [a-zA-Z0-9_-] replaced so models accept it
+func ToolName(server, tool string) string {
+	name := ToolNamePrefix + sanitizeName(server) + "_" + sanitizeName(tool)
+	if len(name) > maxToolNameLength {
+		name = name[:maxToolNameLength]
+	}
+	return name
+}
+
+func sanitizeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// remoteTool exposes one tool of an external MCP server in the registry
+type remoteTool struct {
+	name        string
+	server      string
+	tool        Tool
+	client      *Client
+	permissions permission.Service
+}
+
+// Name returns the registered tool name
+func (t *remoteTool) Name() string {
+	return t.name
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: calls wait
+// on a permission prompt and the server
+func (t *remoteTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information, passing the server's input schema through
+func (t *remoteTool) Info() tools.ToolInfo {
+	schema := t.tool.InputSchema
+	if schema == nil {
+		schema = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+
+	var required []string
+	if list, ok := schema["required"].([]any); ok {
+		for _, item := range list {
+			if name, ok := item.(string); ok {
+				required = append(required, name)
+			}
+		}
+	}
+
+	description := strings.TrimSpace(t.tool.Description)
+	if description == "" && t.tool.Annotations != nil {
+		description = t.tool.Annotations.Title
+	}
+	return tools.ToolInfo{
+		Name:        t.name,
+		Description: fmt.Sprintf("%s\n\n(Tool %q from the %s MCP server)", description, t.tool.Name, t.server),
+		Parameters:  schema,
+		Required:    required,
+	}
+}
+
+// Run asks permission, then calls the tool on its server
+func (t *remoteTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+	arguments := json.RawMessage(strings.TrimSpace(call.Input))
+	if len(arguments) == 0 || string(arguments) == "null" {
+		arguments = json.RawMessage("{}")
+	}
+	if !json.Valid(arguments) {
+		return tools.NewTextErrorResponse("invalid parameters: arguments must be a JSON object"), nil
+	}
+
+	if t.permissions != nil {
+		sessionID, _ := tools.GetContextValues(ctx)
+		granted := t.permissions.Request(ctx, permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			ToolCallID:  call.ID,
+			ToolName:    t.name,
+			Action:      "call",
+			Path:        t.server,
+			Description: fmt.Sprintf("Call %s on the %s MCP server", t.tool.Name, t.server),
+			Params:      arguments,
+		})
+		if !granted {
+			return tools.NewTextErrorResponse(fmt.Sprintf("permission denied: %s was not called", t.tool.Name)), nil
+		}
+	}
+
+	result, err := t.client.CallTool(ctx, t.tool.Name, arguments)
+	if err != nil {
+		return tools.NewTextErrorResponse(err.Error()), nil
+	}
+
+	var parts []string
+	for _, content := range result.Content {
+		if content.Type == "text" {
+			parts = append(parts, content.Text)
+			continue
+		}
+		kind := content.Type
+		if content.MimeType != "" {
+			kind += " " + content.MimeType
+		}
+		parts = append(parts, fmt.Sprintf("[%s content not shown]", kind))
+	}
+	text := strings.Join(parts, "\n")
+	if text == "" {
+		text = "(no output)"
+	}
+	if result.IsError {
+		return tools.NewTextErrorResponse(text), nil
+	}
+	return tools.NewTextResponse(text), nil
+}
+
+// StatusToolName is the name of the /mcp status tool
+const StatusToolName = "mcp_servers"
+
+// statusTool lists configured servers and the tools they contributed
+type statusTool struct {
+	manager *Manager
+}
+
+// NewStatusTool creates the /mcp tool. manager may be nil when no servers
+// are configured.
+func NewStatusTool(manager *Manager) tools.BaseTool {
+	return &statusTool{manager: manager}
+}
+
+// Name returns the tool name
+func (t *statusTool) Name() string {
+	return StatusToolName
+}
+
+// Info returns the tool information
+func (t *statusTool) Info() tools.ToolInfo {
+	return tools.ToolInfo{
+		Name:        StatusToolName,
+		Description: "List the external MCP servers from the config, whether they connected and the tools they provide.",
+		Parameters:  map[string]any{},
+		Commands: []tools.CommandInfo{
+			{
+				Command:     "mcp",
+				Description: "Show external MCP servers and their tools",
+				Examples:    []string{"/mcp"},
+			},
+		},
+	}
+}
+
+// Run lists the servers
+func (t *statusTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+	if t.manager == nil || len(t.manager.servers) == 0 {
+		return tools.NewTextResponse("No MCP servers configured. Add them under \"mcp_servers\" in .loco/config.jsonc."), nil
+	}
+
+	var b strings.Builder
+	for i, status := range t.manager.Status() {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		switch {
+		case status.Disabled:
+			fmt.Fprintf(&b, "○ %s (disabled)\n", status.Name)
+		case status.Err != nil:
+			fmt.Fprintf(&b, "✗ %s: %v\n", status.Name, status.Err)
+		default:
+			label := status.Name
+			if status.Info.Name != "" {
+				label = fmt.Sprintf("%s (%s %s)", status.Name, status.Info.Name, status.Info.Version)
+			}
+			fmt.Fprintf(&b, "✓ %s, %d tools\n", label, len(status.Tools))
+			for _, name := range status.Tools {
+				fmt.Fprintf(&b, "  - %s\n", name)
+			}
+		}
+	}
+	return tools.NewTextResponse(strings.TrimRight(b.String(), "\n")), nil
+}
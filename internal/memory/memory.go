@@ -0,0 +1,137 @@
+// Package memory keeps the project's long-term notes: facts and
+// preferences worth following in every conversation, such as "we use
+// table-driven tests" or "never touch vendored code".
+//
+// Notes live in .loco/memory.md as a Markdown list, one "- " line each, so
+// they can be read, edited and committed like any other file. Lines that
+// aren't list items (the heading, or anything written by hand) are kept as
+// they are.
+package memory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// File is the notes file under .loco
+const File = "memory.md"
+
+// header starts a new notes file
+const header = `# Project memory
+
+Loco includes these notes in every chat. Edit or delete them freely.
+
+`
+
+// Store reads and writes a project's notes file
+type Store struct {
+	path string
+	mu   sync.Mutex // Serializes read-modify-write of the file
+}
+
+// NewStore returns the store for the project at projectPath
+func NewStore(projectPath string) *Store {
+	return &Store{path: filepath.Join(projectPath, ".loco", File)}
+}
+
+// Path returns the notes file's path
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load returns the notes file as written, or "" when there is none
+func (s *Store) Load() (string, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return string(data), err
+}
+
+// Notes returns the notes in the file, in order
+func (s *Store) Notes() ([]string, error) {
+	content, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	var notes []string
+	for _, line := range strings.Split(content, "\n") {
+		if note, ok := noteText(line); ok {
+			notes = append(notes, note)
+		}
+	}
+	return notes, nil
+}
+
+// Add appends a note. It reports false when the file already has it.
+func (s *Store) Add(note string) (bool, error) {
+	note = strings.Join(strings.Fields(note), " ")
+	if note == "" {
+		return false, fmt.Errorf("the note is empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content, err := s.Load()
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if existing, ok := noteText(line); ok && strings.EqualFold(existing, note) {
+			return false, nil
+		}
+	}
+
+	if content == "" {
+		content = header
+	} else if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return true, s.write(content + "- " + note + "\n")
+}
+
+// Forget removes the nth note, counting from 1, and returns it
+func (s *Store) Forget(n int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content, err := s.Load()
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(content, "\n")
+	seen := 0
+	for i, line := range lines {
+		note, ok := noteText(line)
+		if !ok {
+			continue
+		}
+		if seen++; seen == n {
+			lines = append(lines[:i], lines[i+1:]...)
+			return note, s.write(strings.Join(lines, "\n"))
+		}
+	}
+	return "", fmt.Errorf("there is no note %d (memory has %d)", n, seen)
+}
+
+func (s *Store) write(content string) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, []byte(content), 0o644)
+}
+
+// noteText returns the note on a list line
+func noteText(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	for _, bullet := range []string{"- ", "* "} {
+		if note, ok := strings.CutPrefix(trimmed, bullet); ok && strings.TrimSpace(note) != "" {
+			return strings.TrimSpace(note), true
+		}
+	}
+	return "", false
+}
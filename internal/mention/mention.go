@@ -0,0 +1,55 @@
+// Package mention finds @path file mentions in chat messages, such as
+// "why does @internal/app/app.go panic?".
+//
+// A mention starts with @ at the beginning of the text or after whitespace
+// and runs to the next whitespace. Punctuation ending a sentence is not part
+// of the path.
+package mention
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionPattern matches an @ that starts a word and the path after it
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([^\s@]+)`)
+
+// trailingPunctuation is cut from the end of a mention
+const trailingPunctuation = ".,;:!?)]}'\""
+
+// Mention is one @path in a text
+type Mention struct {
+	Path  string // The path as written, without the @
+	Start int    // Byte offset of the @
+	End   int    // Byte offset just after the path
+}
+
+// Find returns the mentions in text, in order
+func Find(text string) []Mention {
+	var mentions []Mention
+	for _, loc := range mentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		path := strings.TrimRight(text[loc[2]:loc[3]], trailingPunctuation)
+		if path == "" {
+			continue
+		}
+		mentions = append(mentions, Mention{
+			Path:  path,
+			Start: loc[2] - 1,
+			End:   loc[2] + len(path),
+		})
+	}
+	return mentions
+}
+
+// Paths returns the distinct paths mentioned in text, in order
+func Paths(text string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, m := range Find(text) {
+		if !seen[m.Path] {
+			seen[m.Path] = true
+			paths = append(paths, m.Path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,285 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/files"
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+// Citation modes of the verify.citations setting
+const (
+	CitationsOff     = "off"
+	CitationsFlag    = "flag"
+	CitationsCorrect = "correct"
+)
+
+// sourceExtensions are the extensions that make a bare word like
+// "config.go" a file name
+var sourceExtensions = []string{
+	"go", "mod", "sum", "md", "txt", "json", "yaml", "yml", "toml", "ini", "env",
+	"js", "jsx", "ts", "tsx", "mjs", "cjs", "css", "scss", "html", "vue", "svelte",
+	"py", "rb", "rs", "java", "kt", "swift", "c", "h", "cc", "cpp", "hpp", "cs",
+	"php", "sh", "bash", "sql", "proto", "lock",
+}
+
+// pathPattern matches words that look like project paths: a slash-separated
+// path, or a file name with an extension
+var pathPattern = regexp.MustCompile(`^(?:[\w.-]+/)*[\w.-]+$`)
+
+// Citations checks the file references in an answer as verify.citations
+// says, returning them with the answer, corrected in correct mode. It
+// returns none when checking is off.
+func (v *Verifier) Citations(answer string) ([]llm.Citation, string) {
+	mode := CitationsFlag
+	if v.config != nil {
+		if cfg := v.config.Get(); cfg != nil && cfg.Verify.Citations != "" {
+			mode = cfg.Verify.Citations
+		}
+	}
+	if mode == CitationsOff {
+		return nil, answer
+	}
+	citations := CheckCitations(v.workingDir, answer)
+	if mode == CitationsCorrect {
+		answer, citations = CorrectCitations(answer, citations)
+	}
+	return citations, answer
+}
+
+// CheckCitations checks each file reference in text against the project:
+// the files the canonical file_summaries.json describes, and the files on
+// disk, which covers files too new to be summarized. A reference naming no
+// file is missing, with a suggestion when exactly one file is a close match.
+func CheckCitations(workingDir, text string) []llm.Citation {
+	written := pathCandidates(text)
+	if len(written) == 0 {
+		return nil
+	}
+	known := projectPaths(workingDir)
+	citations := make([]llm.Citation, 0, len(written))
+	for _, ref := range written {
+		citations = append(citations, resolveCitation(workingDir, known, ref))
+	}
+	return citations
+}
+
+// CorrectCitations replaces the missing references in text that have a
+// suggestion with the file suggested, outside code blocks, and marks them
+// corrected. It returns the corrected text and citations.
+func CorrectCitations(text string, citations []llm.Citation) (string, []llm.Citation) {
+	corrected := slices.Clone(citations)
+	var patterns []*regexp.Regexp
+	var replacements []string
+	for i, citation := range corrected {
+		if citation.Status != llm.CitationMissing || citation.Suggestion == "" {
+			continue
+		}
+		patterns = append(patterns, regexp.MustCompile(`(^|[^\w./-])`+regexp.QuoteMeta(citation.Text)+`($|[^\w/-])`))
+		replacements = append(replacements, "${1}"+citation.Suggestion+"${2}")
+		corrected[i].Path = citation.Suggestion
+		corrected[i].Suggestion = ""
+		corrected[i].Status = llm.CitationCorrected
+	}
+	if len(patterns) == 0 {
+		return text, corrected
+	}
+
+	lines := strings.Split(text, "\n")
+	inBlock := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inBlock = !inBlock
+			continue
+		}
+		if inBlock {
+			continue
+		}
+		for j, pattern := range patterns {
+			line = pattern.ReplaceAllString(line, replacements[j])
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n"), corrected
+}
+
+// projectPaths returns the summarized and listed project files, sorted
+func projectPaths(workingDir string) []string {
+	listed, _ := files.ForDir(context.Background(), workingDir).Files(context.Background())
+	paths := append(analysis.SummarizedFiles(workingDir), listed...)
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
+
+// resolveCitation finds the project file a reference names. Partial paths
+// like "app/app.go" name the files they end; directories are valid when
+// they hold project files.
+func resolveCitation(workingDir string, known []string, ref string) llm.Citation {
+	citation := llm.Citation{Text: ref, Status: llm.CitationValid}
+	clean := path.Clean(ref)
+	if _, ok := slices.BinarySearch(known, clean); ok {
+		citation.Path = clean
+		return citation
+	}
+	if info, err := os.Stat(filepath.Join(workingDir, filepath.FromSlash(clean))); err == nil {
+		if !info.IsDir() {
+			citation.Path = clean
+		}
+		return citation
+	}
+
+	var matches []string
+	dir := false
+	for _, file := range known {
+		switch {
+		case strings.HasSuffix(file, "/"+clean):
+			matches = append(matches, file)
+		case strings.HasPrefix(file, clean+"/"), strings.Contains(file, "/"+clean+"/"):
+			dir = true
+		}
+	}
+	if len(matches) == 1 {
+		citation.Path = matches[0]
+	}
+	if len(matches) > 0 || dir {
+		return citation
+	}
+
+	citation.Status = llm.CitationMissing
+	citation.Suggestion = closestPath(known, clean)
+	return citation
+}
+
+// closestPath returns the one project file a missing reference most likely
+// meant: the closest by spelling, within a typo or two, or else the only
+// file with its name. It returns "" when no file, or more than one, fits.
+func closestPath(known []string, ref string) string {
+	maxEdits := 1
+	if len(path.Base(ref)) >= 8 {
+		maxEdits = 2
+	}
+	segments := strings.Count(ref, "/") + 1
+	best, bestEdits, tied := "", maxEdits+1, false
+	for _, file := range known {
+		edits := editDistance(ref, tail(file, segments))
+		switch {
+		case edits < bestEdits:
+			best, bestEdits, tied = file, edits, false
+		case edits == bestEdits:
+			tied = true
+		}
+	}
+	if best != "" && !tied {
+		return best
+	}
+
+	// The right name in the wrong directory
+	var sameName []string
+	for _, file := range known {
+		if path.Base(file) == path.Base(ref) {
+			sameName = append(sameName, file)
+		}
+	}
+	if len(sameName) == 1 {
+		return sameName[0]
+	}
+	return ""
+}
+
+// tail returns the last n segments of a slash-separated path
+func tail(file string, n int) string {
+	parts := strings.Split(file, "/")
+	if n >= len(parts) {
+		return file
+	}
+	return strings.Join(parts[len(parts)-n:], "/")
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// isPathBreak reports whether r ends a word that could be a path, so paths
+// in quotes, brackets and tool call JSON are read on their own
+func isPathBreak(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("\"'()[]{}<>,=", r)
+}
+
+// pathCandidates returns the words of text that look like project paths:
+// names with a source extension like "config.go" or "internal/app/app.go",
+// and slash-separated paths in backticks like `internal/app`
+func pathCandidates(text string) []string {
+	var candidates []string
+	seen := make(map[string]bool)
+	add := func(word string, quoted bool) {
+		word = strings.TrimLeft(word, "@")
+		word = strings.TrimRight(word, ".:;!?")
+		if i := strings.IndexByte(word, ':'); i > 0 {
+			word = word[:i] // file.go:42
+		}
+		word = strings.TrimPrefix(word, "./")
+		if word == "" || seen[word] || strings.HasPrefix(word, "/") || strings.HasPrefix(word, "~") ||
+			strings.Contains(word, "//") || !pathPattern.MatchString(word) {
+			return
+		}
+		ext := strings.TrimPrefix(path.Ext(word), ".")
+		hasExt := slices.Contains(sourceExtensions, strings.ToLower(ext))
+		if !hasExt && !(quoted && strings.Contains(word, "/")) {
+			return
+		}
+		if !filepath.IsLocal(filepath.FromSlash(word)) {
+			return
+		}
+		seen[word] = true
+		candidates = append(candidates, word)
+	}
+
+	// Code blocks hold code, not references, so only prose is read
+	inBlock := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inBlock = !inBlock
+			continue
+		}
+		if inBlock {
+			continue
+		}
+		parts := strings.Split(line, "`")
+		for i, part := range parts {
+			quoted := i%2 == 1 && i < len(parts)-1
+			if quoted {
+				add(strings.TrimSpace(part), true)
+				continue
+			}
+			for _, word := range strings.FieldsFunc(part, isPathBreak) {
+				add(word, false)
+			}
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,156 @@
+// Package orchestrator routes chat requests. Each message is classified as
+// chat, code-edit, explain or analysis, and answered by the team model,
+// with the system prompt and temperature, configured for that kind under
+// "routing" in the config.
+//
+// Classification reads the wording of the message: the earliest cue
+// decides, so "explain why the build fails, then fix it" is explain.
+// Messages without a cue are chat.
+//
+// When "verify" is enabled in the config, a Verifier has a second model
+// review answers before they are shown, for file paths that don't exist and
+// claims the project knowledge contradicts.
+package orchestrator
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+// Kind is the kind of request a chat message makes
+type Kind string
+
+const (
+	KindChat     Kind = "chat"
+	KindCodeEdit Kind = "code-edit"
+	KindExplain  Kind = "explain"
+	KindAnalysis Kind = "analysis"
+)
+
+// defaultTemperature is used when a route sets none
+const defaultTemperature = 0.7
+
+// cues are the words and phrases that mark each kind. A cue word ending
+// in * matches any word it starts.
+var cues = []struct {
+	kind Kind
+	cues []string
+}{
+	{KindAnalysis, []string{"analy*", "review*", "audit*", "assess*", "evaluat*", "architecture", "overview", "compare", "tech debt", "code quality"}},
+	{KindCodeEdit, []string{"fix*", "implement*", "add", "change", "refactor*", "rename*", "write", "update", "remove", "delete", "edit", "patch", "create", "make it", "replace", "convert", "migrate"}},
+	{KindExplain, []string{"explain*", "why", "how does", "how do", "how is", "what does", "what is", "what are", "walk me through", "understand", "meaning of"}},
+}
+
+// Classify returns the kind of request message makes
+func Classify(message string) Kind {
+	words := strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+	for i := range words {
+		for _, group := range cues {
+			for _, cue := range group.cues {
+				if matchAt(words, i, strings.Fields(cue)) {
+					return group.kind
+				}
+			}
+		}
+	}
+	return KindChat
+}
+
+// matchAt reports whether the cue's words start at words[i]
+func matchAt(words []string, i int, cue []string) bool {
+	if i+len(cue) > len(words) {
+		return false
+	}
+	for j, want := range cue {
+		if prefix, ok := strings.CutSuffix(want, "*"); ok {
+			if !strings.HasPrefix(words[i+j], prefix) {
+				return false
+			}
+		} else if words[i+j] != want {
+			return false
+		}
+	}
+	return true
+}
+
+// Route is how one message is answered
+type Route struct {
+	Kind         Kind
+	Client       llm.Client // nil keeps the chat client
+	Model        string     // The routed client's model, when it says
+	SystemPrompt string     // "" adds no instructions
+	Temperature  float64
+}
+
+// Router picks the route for each chat message from the config
+type Router struct {
+	config *config.Manager
+
+	mu   sync.RWMutex
+	team *llm.TeamClients
+}
+
+// NewRouter creates a router reading its routes from the config, so
+// changes apply to the next message
+func NewRouter(configManager *config.Manager) *Router {
+	return &Router{config: configManager}
+}
+
+// SetTeam sets the models routes pick from
+func (r *Router) SetTeam(team *llm.TeamClients) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.team = team
+}
+
+// Route classifies message and returns its route. It reports false when
+// routing is turned off.
+func (r *Router) Route(message string) (Route, bool) {
+	route := Route{Kind: Classify(message), Temperature: defaultTemperature}
+	if r.config == nil {
+		return route, false
+	}
+	cfg := r.config.Get()
+	if cfg == nil || !cfg.Routing.Enabled {
+		return route, false
+	}
+
+	settings, ok := cfg.Routing.Routes[string(route.Kind)]
+	if !ok {
+		settings = cfg.Routing.Routes[string(KindChat)]
+	}
+	route.SystemPrompt = strings.TrimSpace(settings.SystemPrompt)
+	if settings.Temperature != nil {
+		route.Temperature = *settings.Temperature
+	}
+	route.Client = r.client(settings.Model)
+	if model, ok := route.Client.(interface{ CurrentModel() string }); ok {
+		route.Model = model.CurrentModel()
+	}
+	return route, true
+}
+
+// client returns the team client for a model name, or nil to keep the chat
+// client
+func (r *Router) client(model string) llm.Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.team == nil {
+		return nil
+	}
+	switch strings.ToLower(strings.TrimSpace(model)) {
+	case "small":
+		return r.team.Small
+	case "medium":
+		return r.team.Medium
+	case "large":
+		return r.team.Large
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+// reviewTimeout bounds a review, so a slow reviewer only delays an answer
+const reviewTimeout = 90 * time.Second
+
+// maxReviewKnowledge caps each knowledge doc given to the reviewer
+const maxReviewKnowledge = 6000
+
+// maxReviewIssues caps the problems kept from one review
+const maxReviewIssues = 8
+
+// Review is what the reviewing model found in a draft answer
+type Review struct {
+	Model  string   // The reviewing model, when it says
+	Issues []string // Empty when the draft passed
+}
+
+// Verifier has a second model review draft answers before they are shown,
+// for file paths that don't exist in the project and claims the project
+// knowledge contradicts
+type Verifier struct {
+	workingDir string
+	config     *config.Manager
+
+	mu     sync.RWMutex
+	team   *llm.TeamClients
+	client llm.Client // Reviews when the team has no model of the configured size
+}
+
+// NewVerifier creates a verifier for the project at workingDir, reading its
+// settings from the config
+func NewVerifier(workingDir string, configManager *config.Manager) *Verifier {
+	return &Verifier{workingDir: workingDir, config: configManager}
+}
+
+// SetClient sets the model used when there is no team
+func (v *Verifier) SetClient(client llm.Client) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.client = client
+}
+
+// SetTeam sets the models reviews pick from
+func (v *Verifier) SetTeam(team *llm.TeamClients) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.team = team
+}
+
+// Wants reports whether an answer should be reviewed: verification is on,
+// and either every answer is reviewed or this one used tools
+func (v *Verifier) Wants(usedTools bool) bool {
+	if v.config == nil {
+		return false
+	}
+	cfg := v.config.Get()
+	if cfg == nil || !cfg.Verify.Enabled {
+		return false
+	}
+	return usedTools || !cfg.Verify.ToolsOnly
+}
+
+// Review has the reviewing model check draft, the answer to question,
+// against the project's files and knowledge
+func (v *Verifier) Review(ctx context.Context, question, draft string) (Review, error) {
+	client := v.reviewer()
+	if client == nil {
+		return Review{}, fmt.Errorf("no model to review the answer")
+	}
+	review := Review{}
+	if lm, ok := client.(interface{ CurrentModel() string }); ok {
+		review.Model = lm.CurrentModel()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question:\n%s\n\nDraft answer:\n%s\n\n", strings.TrimSpace(question), strings.TrimSpace(draft))
+	var missing []string
+	for _, citation := range CheckCitations(v.workingDir, draft) {
+		switch {
+		case citation.Status != llm.CitationMissing:
+		case citation.Suggestion != "":
+			missing = append(missing, fmt.Sprintf("- %s (perhaps %s)\n", citation.Text, citation.Suggestion))
+		default:
+			missing = append(missing, "- "+citation.Text+"\n")
+		}
+	}
+	if len(missing) > 0 {
+		b.WriteString("Paths the draft names that do not exist in the project (wrong unless the draft says it creates them):\n")
+		b.WriteString(strings.Join(missing, ""))
+		b.WriteString("\n")
+	}
+	for _, kind := range []string{"overview", "structure"} {
+		if source, content, ok := analysis.LoadBestKnowledge(v.workingDir, kind); ok {
+			if len(content) > maxReviewKnowledge {
+				content = content[:maxReviewKnowledge] + "\n..."
+			}
+			fmt.Fprintf(&b, "Project %s (from .loco/knowledge/%s):\n%s\n\n", kind, source, content)
+		}
+	}
+	messages := []llm.Message{
+		{Role: "system", Content: "You review a coding assistant's draft answer before the user sees it. " +
+			"Check its file paths and its claims about this project against the facts given. " +
+			"Flag only things that are wrong or made up, not style or missing detail. " +
+			"Reply with OK alone if nothing is wrong; otherwise reply with one line per problem, each starting with \"- \", " +
+			"saying what is wrong and what is true instead."},
+		{Role: "user", Content: b.String()},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reviewTimeout)
+	defer cancel()
+	var reply string
+	var err error
+	if withOptions, ok := client.(interface {
+		CompleteWithOptions(context.Context, []llm.Message, llm.CompleteOptions) (string, error)
+	}); ok {
+		opts := llm.DefaultCompleteOptions()
+		opts.Temperature = 0.1
+		reply, err = withOptions.CompleteWithOptions(ctx, messages, opts)
+	} else {
+		reply, err = client.Complete(ctx, messages)
+	}
+	if err != nil {
+		return review, fmt.Errorf("failed to review the answer: %w", err)
+	}
+	review.Issues = parseIssues(reply)
+	return review, nil
+}
+
+// reviewer returns the model configured to review
+func (v *Verifier) reviewer() llm.Client {
+	model := ""
+	if v.config != nil {
+		if cfg := v.config.Get(); cfg != nil {
+			model = cfg.Verify.Model
+		}
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if v.team != nil {
+		var client llm.Client
+		switch strings.ToLower(strings.TrimSpace(model)) {
+		case "small":
+			client = v.team.Small
+		case "large":
+			client = v.team.Large
+		default:
+			client = v.team.Medium
+		}
+		if client != nil {
+			return client
+		}
+	}
+	return v.client
+}
+
+// parseIssues reads the problems from a review reply: its bullet lines, or
+// the whole reply when it is neither OK nor a list
+func parseIssues(reply string) []string {
+	reply = strings.TrimSpace(reply)
+	if reply == "" {
+		return nil
+	}
+	var issues []string
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		item, ok := strings.CutPrefix(line, "- ")
+		if !ok {
+			item, ok = strings.CutPrefix(line, "* ")
+		}
+		if ok && strings.TrimSpace(item) != "" && len(issues) < maxReviewIssues {
+			issues = append(issues, strings.TrimSpace(item))
+		}
+	}
+	if len(issues) > 0 {
+		return issues
+	}
+	verdict := strings.ToUpper(strings.Trim(reply, " .!*`"))
+	if verdict == "OK" || strings.HasPrefix(verdict, "OK\n") || strings.HasPrefix(verdict, "OK,") {
+		return nil
+	}
+	return []string{reply}
+}
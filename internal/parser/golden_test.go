@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files from the current parser output")
+
+// goldenParse is what a replayed response must keep parsing to
+type goldenParse struct {
+	Method    string     `json:"method"`
+	ToolCalls []ToolCall `json:"tool_calls"`
+	Rejected  int        `json:"rejected,omitempty"` // Calls dropped by schema validation
+}
+
+// TestGoldenResponses replays captured model responses through Parse and
+// compares the tool calls with the .golden file saved next to each one.
+// Run with -update after an intended parser change and review the diff.
+func TestGoldenResponses(t *testing.T) {
+	paths, err := filepath.Glob("../../testdata/golden/parser/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skip("No golden responses found in testdata/golden/parser")
+	}
+
+	p := New()
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".json"), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var captured CapturedResponse
+			if err := json.Unmarshal(data, &captured); err != nil {
+				t.Fatalf("Failed to parse %s: %v", path, err)
+			}
+
+			result, err := p.Parse(captured.Response)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			got := goldenParse{Method: result.Method, ToolCalls: result.ToolCalls, Rejected: len(result.Errors)}
+			if got.ToolCalls == nil {
+				got.ToolCalls = []ToolCall{}
+			}
+			compareGolden(t, strings.TrimSuffix(path, ".json")+".golden", got)
+		})
+	}
+}
+
+// compareGolden checks got against the golden file, or rewrites the file
+// with -update
+func compareGolden(t *testing.T, goldenPath string, got any) {
+	t.Helper()
+	actual, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual = append(actual, '\n')
+
+	if *update {
+		if err := os.WriteFile(goldenPath, actual, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("Missing golden file %s (run go test -update): %v", goldenPath, err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("Output changed from %s\nwant:\n%s\ngot:\n%s", goldenPath, expected, actual)
+	}
+}
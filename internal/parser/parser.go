@@ -17,11 +17,12 @@ type ParseResult struct {
 	Text      string
 	Method    string
 	ToolCalls []ToolCall
+	Errors    []ValidationError // Calls rejected by schema validation
 }
 
 // Parser handles extracting tool calls from AI responses.
 type Parser struct {
-	// We'll add more fields as we evolve
+	schemas SchemaProvider
 }
 
 // New creates a new parser.
@@ -29,8 +30,23 @@ func New() *Parser {
 	return &Parser{}
 }
 
-// Parse extracts tool calls from an AI response.
+// SetSchemaProvider enables validation of parsed params against tool schemas.
+func (p *Parser) SetSchemaProvider(provider SchemaProvider) {
+	p.schemas = provider
+}
+
+// Parse extracts tool calls from an AI response and validates their params.
 func (p *Parser) Parse(response string) (*ParseResult, error) {
+	result, err := p.extract(response)
+	if err != nil {
+		return nil, err
+	}
+	p.validate(result)
+	return result, nil
+}
+
+// extract runs the parsing stages in order until one finds tool calls.
+func (p *Parser) extract(response string) (*ParseResult, error) {
 	result := &ParseResult{
 		Text:      response,
 		ToolCalls: []ToolCall{},
@@ -0,0 +1,233 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// SchemaProvider supplies JSON schemas for the tools the parser should validate.
+type SchemaProvider interface {
+	// ParamSchema returns the properties and required params for a tool.
+	// ok is false when the tool is unknown, in which case it is not validated.
+	ParamSchema(toolName string) (properties map[string]interface{}, required []string, ok bool)
+}
+
+// ValidationError describes why a parsed tool call was rejected.
+type ValidationError struct {
+	Call     ToolCall
+	Problems []string
+}
+
+// Error implements the error interface.
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("invalid params for %s: %s", e.Call.Name, strings.Join(e.Problems, "; "))
+}
+
+// Feedback builds a message telling the model what was wrong with its tool
+// calls so it can retry. It returns "" when every call was valid.
+func (r *ParseResult) Feedback() string {
+	if len(r.Errors) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Some of your tool calls had invalid parameters and were not run:\n")
+	for _, verr := range r.Errors {
+		sb.WriteString(fmt.Sprintf("\n%s:\n", verr.Call.Summary()))
+		for _, problem := range verr.Problems {
+			sb.WriteString("- " + problem + "\n")
+		}
+	}
+	sb.WriteString("\nPlease resend the corrected tool calls.")
+	return sb.String()
+}
+
+// validate moves calls that fail schema validation from ToolCalls to Errors.
+func (p *Parser) validate(result *ParseResult) {
+	if p.schemas == nil || len(result.ToolCalls) == 0 {
+		return
+	}
+
+	valid := make([]ToolCall, 0, len(result.ToolCalls))
+	for _, call := range result.ToolCalls {
+		properties, required, ok := p.schemas.ParamSchema(call.Name)
+		if !ok {
+			valid = append(valid, call)
+			continue
+		}
+		if problems := ValidateParams(call.Params, properties, required); len(problems) > 0 {
+			result.Errors = append(result.Errors, ValidationError{Call: call, Problems: problems})
+			continue
+		}
+		valid = append(valid, call)
+	}
+	result.ToolCalls = valid
+}
+
+// ValidateParams checks params against a JSON schema's properties and required
+// list, returning one human-readable problem per violation.
+func ValidateParams(params map[string]interface{}, properties map[string]interface{}, required []string) []string {
+	var problems []string
+
+	for _, name := range required {
+		if _, ok := params[name]; !ok {
+			problems = append(problems, "missing required parameter "+describeParam(name, properties[name]))
+		}
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		def, known := properties[name].(map[string]interface{})
+		if !known {
+			problems = append(problems, fmt.Sprintf("unknown parameter %q (expected one of: %s)", name, knownParams(properties)))
+			continue
+		}
+		problems = append(problems, checkValue(name, params[name], def)...)
+	}
+
+	return problems
+}
+
+// checkValue validates a single value against its property definition.
+func checkValue(name string, value interface{}, def map[string]interface{}) []string {
+	want, _ := def["type"].(string)
+	if want != "" && !matchesType(value, want) {
+		return []string{fmt.Sprintf("parameter %q must be %s, got %s", name, withArticle(want), jsonTypeOf(value))}
+	}
+
+	var problems []string
+	if enum, ok := def["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		problems = append(problems, fmt.Sprintf("parameter %q must be one of %v, got %v", name, enum, value))
+	}
+	if enum, ok := def["enum"].([]string); ok {
+		if s, isString := value.(string); isString && !containsString(enum, s) {
+			problems = append(problems, fmt.Sprintf("parameter %q must be one of %s, got %q", name, strings.Join(enum, ", "), s))
+		}
+	}
+	if n, isNumber := value.(float64); isNumber {
+		if minimum, ok := toFloat(def["minimum"]); ok && n < minimum {
+			problems = append(problems, fmt.Sprintf("parameter %q must be at least %v, got %v", name, minimum, n))
+		}
+		if maximum, ok := toFloat(def["maximum"]); ok && n > maximum {
+			problems = append(problems, fmt.Sprintf("parameter %q must be at most %v, got %v", name, maximum, n))
+		}
+	}
+	return problems
+}
+
+// matchesType reports whether a JSON-decoded value has the given schema type.
+func matchesType(value interface{}, want string) bool {
+	switch want {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return true
+}
+
+// jsonTypeOf names the JSON type of a decoded value for error messages.
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case float64:
+		return fmt.Sprintf("number %v", v)
+	case bool:
+		return fmt.Sprintf("boolean %v", v)
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// describeParam renders a param with its type and description when known.
+func describeParam(name string, def interface{}) string {
+	desc := fmt.Sprintf("%q", name)
+	m, ok := def.(map[string]interface{})
+	if !ok {
+		return desc
+	}
+	if t, ok := m["type"].(string); ok {
+		desc += " (" + t
+		if d, ok := m["description"].(string); ok && d != "" {
+			desc += ": " + d
+		}
+		desc += ")"
+	}
+	return desc
+}
+
+// knownParams lists the declared property names.
+func knownParams(properties map[string]interface{}) string {
+	if len(properties) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func withArticle(typeName string) string {
+	switch typeName {
+	case "integer", "array", "object":
+		return "an " + typeName
+	}
+	return "a " + typeName
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func containsValue(values []interface{}, target interface{}) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeSchemas is a SchemaProvider backed by a static map.
+type fakeSchemas map[string]struct {
+	properties map[string]interface{}
+	required   []string
+}
+
+func (f fakeSchemas) ParamSchema(name string) (map[string]interface{}, []string, bool) {
+	s, ok := f[name]
+	return s.properties, s.required, ok
+}
+
+func testSchemas() fakeSchemas {
+	return fakeSchemas{
+		"read_file": {
+			properties: map[string]interface{}{
+				"path":  map[string]interface{}{"type": "string", "description": "File to read"},
+				"limit": map[string]interface{}{"type": "integer", "minimum": 1},
+			},
+			required: []string{"path"},
+		},
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	schema := testSchemas()["read_file"]
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		expected []string
+	}{
+		{
+			name:     "valid",
+			params:   map[string]interface{}{"path": "main.go", "limit": float64(10)},
+			expected: nil,
+		},
+		{
+			name:     "missing_required",
+			params:   map[string]interface{}{},
+			expected: []string{`missing required parameter "path" (string: File to read)`},
+		},
+		{
+			name:     "wrong_type",
+			params:   map[string]interface{}{"path": float64(3)},
+			expected: []string{`parameter "path" must be a string, got number 3`},
+		},
+		{
+			name:     "fractional_integer",
+			params:   map[string]interface{}{"path": "a", "limit": 1.5},
+			expected: []string{`parameter "limit" must be an integer, got number 1.5`},
+		},
+		{
+			name:     "below_minimum",
+			params:   map[string]interface{}{"path": "a", "limit": float64(0)},
+			expected: []string{`parameter "limit" must be at least 1, got 0`},
+		},
+		{
+			name:     "unknown_param",
+			params:   map[string]interface{}{"path": "a", "file": "b"},
+			expected: []string{`unknown parameter "file" (expected one of: limit, path)`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateParams(tt.params, schema.properties, schema.required)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ValidateParams() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParser_SchemaValidation(t *testing.T) {
+	p := New()
+	p.SetSchemaProvider(testSchemas())
+
+	input := `<tool>{"name": "read_file", "params": {"file": "main.go"}}</tool>
+<tool>{"name": "read_file", "params": {"path": "ok.go"}}</tool>
+<tool>{"name": "unregistered", "params": {"x": 1}}</tool>`
+
+	result, err := p.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(result.ToolCalls) != 2 {
+		t.Fatalf("ToolCalls = %+v, want the valid and the unregistered call", result.ToolCalls)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("Errors = %+v, want 1", result.Errors)
+	}
+
+	feedback := result.Feedback()
+	for _, want := range []string{"read_file(main.go)", `missing required parameter "path"`, `unknown parameter "file"`} {
+		if !strings.Contains(feedback, want) {
+			t.Errorf("Feedback() missing %q:\n%s", want, feedback)
+		}
+	}
+}
+
+func TestParseResult_FeedbackEmptyWhenValid(t *testing.T) {
+	p := New()
+	p.SetSchemaProvider(testSchemas())
+
+	result, err := p.Parse(`<tool>{"name": "read_file", "params": {"path": "main.go"}}</tool>`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if fb := result.Feedback(); fb != "" {
+		t.Errorf("Feedback() = %q, want empty", fb)
+	}
+}
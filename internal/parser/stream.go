@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	toolOpenTag  = "<tool>"
+	toolCloseTag = "</tool>"
+)
+
+var (
+	// partialNameRegex finds the tool name inside an unfinished <tool> block.
+	partialNameRegex = regexp.MustCompile(`"name"\s*:\s*"((?:[^"\\]|\\.)*)"`)
+	// partialParamRegex finds completed string params inside an unfinished block.
+	partialParamRegex = regexp.MustCompile(`"(\w+)"\s*:\s*"((?:[^"\\]|\\.)*)"`)
+)
+
+// StreamEvent describes a tool call discovered while a response is still streaming.
+type StreamEvent struct {
+	Call     ToolCall
+	Index    int  // Position of the <tool> block within the response
+	Complete bool // True once the closing </tool> tag has arrived
+}
+
+// StreamParser incrementally detects <tool> blocks in a streaming response.
+// Feed it chunks as they arrive; it reports a tool as soon as its name is
+// known and again when the block is complete.
+type StreamParser struct {
+	buf        strings.Builder
+	scanned    int // Offset from which to look for the next <tool> tag
+	open       int // Start of the current block's content, -1 when outside a block
+	index      int // Number of blocks seen so far
+	lastParams int // Number of params reported for the current partial block
+	announced  bool
+}
+
+// NewStreamParser creates a stream parser ready for a new response.
+func NewStreamParser() *StreamParser {
+	return &StreamParser{open: -1}
+}
+
+// Reset clears all state so the parser can be reused for another response.
+func (sp *StreamParser) Reset() {
+	sp.buf.Reset()
+	sp.scanned = 0
+	sp.open = -1
+	sp.index = 0
+	sp.lastParams = 0
+	sp.announced = false
+}
+
+// Feed appends a chunk and returns any tool events it produced.
+func (sp *StreamParser) Feed(chunk string) []StreamEvent {
+	sp.buf.WriteString(chunk)
+	text := sp.buf.String()
+
+	var evts []StreamEvent
+	for {
+		if sp.open < 0 {
+			start := strings.Index(text[sp.scanned:], toolOpenTag)
+			if start < 0 {
+				// Keep enough tail to match a tag split across chunks
+				if keep := len(text) - len(toolOpenTag) + 1; keep > sp.scanned {
+					sp.scanned = keep
+				}
+				break
+			}
+			sp.open = sp.scanned + start + len(toolOpenTag)
+			sp.announced = false
+			sp.lastParams = 0
+		}
+
+		end := strings.Index(text[sp.open:], toolCloseTag)
+		if end < 0 {
+			if evt, ok := sp.partial(text[sp.open:]); ok {
+				evts = append(evts, evt)
+			}
+			break
+		}
+
+		body := strings.TrimSpace(text[sp.open : sp.open+end])
+		var tc ToolCall
+		if err := json.Unmarshal([]byte(body), &tc); err == nil && tc.Name != "" {
+			evts = append(evts, StreamEvent{Call: tc, Index: sp.index, Complete: true})
+		}
+		sp.index++
+		sp.scanned = sp.open + end + len(toolCloseTag)
+		sp.open = -1
+	}
+
+	return evts
+}
+
+// partial reports an unfinished block once its name is known, and again
+// whenever more of its string params have streamed in.
+func (sp *StreamParser) partial(body string) (StreamEvent, bool) {
+	nameMatch := partialNameRegex.FindStringSubmatch(body)
+	if nameMatch == nil {
+		return StreamEvent{}, false
+	}
+
+	params := make(map[string]interface{})
+	if idx := strings.Index(body, `"params"`); idx >= 0 {
+		for _, m := range partialParamRegex.FindAllStringSubmatch(body[idx:], -1) {
+			var value string
+			if err := json.Unmarshal([]byte(`"`+m[2]+`"`), &value); err == nil {
+				params[m[1]] = value
+			}
+		}
+	}
+
+	if sp.announced && len(params) <= sp.lastParams {
+		return StreamEvent{}, false
+	}
+	sp.announced = true
+	sp.lastParams = len(params)
+
+	var name string
+	if err := json.Unmarshal([]byte(`"`+nameMatch[1]+`"`), &name); err != nil {
+		name = nameMatch[1]
+	}
+
+	return StreamEvent{
+		Call:  ToolCall{Name: name, Params: params},
+		Index: sp.index,
+	}, true
+}
+
+// Summary renders a tool call compactly, e.g. read_file(main.go).
+func (tc ToolCall) Summary() string {
+	keys := make([]string, 0, len(tc.Params))
+	for k := range tc.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	args := make([]string, 0, len(keys))
+	for _, k := range keys {
+		value := fmt.Sprintf("%v", tc.Params[k])
+		if len(value) > 40 {
+			value = value[:37] + "..."
+		}
+		// A lone param reads better without its key
+		if len(keys) == 1 {
+			args = append(args, value)
+		} else {
+			args = append(args, k+"="+value)
+		}
+	}
+
+	return fmt.Sprintf("%s(%s)", tc.Name, strings.Join(args, ", "))
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStreamParser_Feed(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunks   []string
+		expected []StreamEvent
+	}{
+		{
+			name: "single_tool_split_across_chunks",
+			chunks: []string{
+				"Let me look. <to",
+				`ol>{"name": "read_`,
+				`file", "params": {"path": "ma`,
+				`in.go"}}</tool> Done.`,
+			},
+			expected: []StreamEvent{
+				{Call: ToolCall{Name: "read_file", Params: map[string]interface{}{}}, Index: 0},
+				{Call: ToolCall{Name: "read_file", Params: map[string]interface{}{"path": "main.go"}}, Index: 0, Complete: true},
+			},
+		},
+		{
+			name: "params_reported_before_close",
+			chunks: []string{
+				`<tool>{"name": "read_file", "params": {"path": "main.go"`,
+				`}}`,
+				`</tool>`,
+			},
+			expected: []StreamEvent{
+				{Call: ToolCall{Name: "read_file", Params: map[string]interface{}{"path": "main.go"}}, Index: 0},
+				{Call: ToolCall{Name: "read_file", Params: map[string]interface{}{"path": "main.go"}}, Index: 0, Complete: true},
+			},
+		},
+		{
+			name: "multiple_tools_in_one_chunk",
+			chunks: []string{
+				`<tool>{"name": "read_file", "params": {"path": "a.go"}}</tool> and <tool>{"name": "list_directory", "params": {"path": "src"}}</tool>`,
+			},
+			expected: []StreamEvent{
+				{Call: ToolCall{Name: "read_file", Params: map[string]interface{}{"path": "a.go"}}, Index: 0, Complete: true},
+				{Call: ToolCall{Name: "list_directory", Params: map[string]interface{}{"path": "src"}}, Index: 1, Complete: true},
+			},
+		},
+		{
+			name:     "plain_text",
+			chunks:   []string{"Just ", "talking ", "about <tools> in general."},
+			expected: nil,
+		},
+		{
+			name: "malformed_block_skipped",
+			chunks: []string{
+				`<tool>{name: broken}</tool>`,
+				`<tool>{"name": "read_file", "params": {"path": "ok.go"}}</tool>`,
+			},
+			expected: []StreamEvent{
+				{Call: ToolCall{Name: "read_file", Params: map[string]interface{}{"path": "ok.go"}}, Index: 1, Complete: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := NewStreamParser()
+			var got []StreamEvent
+			for _, chunk := range tt.chunks {
+				got = append(got, sp.Feed(chunk)...)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("events = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToolCall_Summary(t *testing.T) {
+	tests := []struct {
+		call     ToolCall
+		expected string
+	}{
+		{ToolCall{Name: "read_file", Params: map[string]interface{}{"path": "main.go"}}, "read_file(main.go)"},
+		{ToolCall{Name: "write_file", Params: map[string]interface{}{"path": "a.txt", "content": "hi"}}, "write_file(content=hi, path=a.txt)"},
+		{ToolCall{Name: "help"}, "help()"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.call.Summary(); got != tt.expected {
+			t.Errorf("Summary() = %q, want %q", got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package permission
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditEntry is one approved action recorded in the audit log
+type AuditEntry struct {
+	Time        time.Time `json:"time"`
+	SessionID   string    `json:"session_id,omitempty"`
+	ToolName    string    `json:"tool"`
+	Action      string    `json:"action,omitempty"`
+	Path        string    `json:"path,omitempty"`
+	Description string    `json:"description,omitempty"`
+	ApprovedBy  string    `json:"approved_by"` // "policy", "user" or "always" (a scope the user always allows)
+}
+
+// AuditLog appends approved actions to a JSON-lines file
+type AuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewAuditLog creates an audit log writing to path
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Record appends an entry for an approved request
+func (a *AuditLog) Record(req CreatePermissionRequest, approvedBy string) error {
+	if a == nil || a.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(AuditEntry{
+		Time:        time.Now().UTC(),
+		SessionID:   req.SessionID,
+		ToolName:    req.ToolName,
+		Action:      req.Action,
+		Path:        req.Path,
+		Description: req.Description,
+		ApprovedBy:  approvedBy,
+	})
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
@@ -1,118 +1,140 @@
 package permission
 
 import (
-	"github.com/billie-coop/loco/internal/state"
+	"context"
+	"path/filepath"
+	"sync"
+
 	"github.com/billie-coop/loco/internal/tui/events"
 	"github.com/google/uuid"
-	"sync"
 )
 
-// service is the permission service implementation using persistent state.
+// pendingRequest is a request waiting for the user's decision.
+type pendingRequest struct {
+	req    CreatePermissionRequest
+	respCh chan bool
+}
+
+// service is the permission service implementation backed by per-tool policies.
 type service struct {
-	store           *state.PermissionStore
+	policies        PolicyStore
+	audit           *AuditLog
 	eventBroker     *events.Broker
-	allowedTools    []string // Tools that never need permission
-	pendingRequests map[string]chan bool
+	pendingRequests map[string]pendingRequest
 	mu              sync.RWMutex
 }
 
-// NewService creates a new permission service.
-func NewService(eventBroker *events.Broker, allowedTools []string, statePath string) Service {
+// NewService creates a new permission service. Policies decide whether a
+// tool runs, asks or is refused; approved actions are appended to
+// audit.log under statePath.
+func NewService(eventBroker *events.Broker, policies PolicyStore, statePath string) Service {
 	s := &service{
-		store:           state.NewPermissionStore(statePath),
+		policies:        policies,
+		audit:           NewAuditLog(filepath.Join(statePath, "audit.log")),
 		eventBroker:     eventBroker,
-		allowedTools:    allowedTools,
-		pendingRequests: make(map[string]chan bool),
+		pendingRequests: make(map[string]pendingRequest),
 	}
-	
-	// Start listening for permission responses
-	go s.listenForResponses()
-	
+
+	// Start listening for permission responses. Decisions must not be
+	// dropped or the requesting tool waits until its context ends, so this
+	// subscriber blocks for as long as it takes; it only hands each
+	// decision to Respond, so it is never slow.
+	decisions := eventBroker.SubscribeWith(events.SubscribeOptions{
+		Name:         "permission decisions",
+		Backpressure: events.Block,
+		BlockTimeout: -1,
+	}, events.ToolExecutionApprovedEvent, events.ToolExecutionDeniedEvent)
+	go s.listenForResponses(decisions)
+
 	return s
 }
 
-// Request checks for permission, using stored grants or asking the user.
-func (s *service) Request(req CreatePermissionRequest) bool {
-	// Check if tool is in allowed list (like "help", "clear", etc.)
-	for _, allowed := range s.allowedTools {
-		if allowed == req.ToolName {
-			return true
-		}
+// Request applies the tool's policy, asking the user when it is "ask" and
+// the request's scope wasn't always allowed before. A request whose context
+// ends before the user answers is refused.
+func (s *service) Request(ctx context.Context, req CreatePermissionRequest) bool {
+	switch s.policy(req.ToolName) {
+	case PolicyAllow:
+		s.record(req, "policy")
+		return true
+	case PolicyDeny:
+		return false
 	}
-	
-	// Check if we already have permission stored
-	if s.store.IsGranted(req.ToolName, req.Path) {
-		return true // Previously granted with "Always allow"
+	if scope := req.GrantScope(); s.policies != nil && scope != "" && s.policies.ScopeAllowed(req.ToolName, scope) {
+		s.record(req, "always")
+		return true
 	}
-	
+
 	// Need to ask the user
 	requestID := uuid.New().String()
-	
+
 	// Create response channel
 	respCh := make(chan bool, 1)
 	s.mu.Lock()
-	s.pendingRequests[requestID] = respCh
+	s.pendingRequests[requestID] = pendingRequest{req: req, respCh: respCh}
 	s.mu.Unlock()
-	
+
 	// Publish permission request event
-	s.eventBroker.PublishAsync(events.Event{
-		Type: "permission.request",
-		Payload: PermissionRequestEvent{
-			ID:      requestID,
-			Request: req,
-		},
+	RequestTopic.PublishAsync(s.eventBroker, PermissionRequestEvent{
+		ID:      requestID,
+		Request: req,
 	})
-	
+
 	// Wait for response
-	granted := <-respCh
-	
+	var granted bool
+	select {
+	case granted = <-respCh:
+	case <-ctx.Done():
+	}
+
 	// Clean up
 	s.mu.Lock()
 	delete(s.pendingRequests, requestID)
 	s.mu.Unlock()
-	
+
+	if granted {
+		s.record(req, "user")
+	}
 	return granted
 }
 
-// GrantPermission handles a grant from the UI.
-func (s *service) GrantPermission(requestID string, forSession bool, req CreatePermissionRequest) {
-	// Save to persistent store if "Always allow"
-	if forSession {
-		s.store.Grant(req.ToolName, req.Path, true)
-	}
-	
-	// Send response to waiting request
+// Respond resolves a pending request with a decision from the UI.
+// "always" allows the request's command, path or host from then on;
+// "never" sets the tool's stored policy to deny.
+func (s *service) Respond(requestID, decision string) {
 	s.mu.RLock()
-	if respCh, ok := s.pendingRequests[requestID]; ok {
-		s.mu.RUnlock()
-		respCh <- true
-	} else {
-		s.mu.RUnlock()
+	pending, ok := s.pendingRequests[requestID]
+	s.mu.RUnlock()
+	if !ok {
+		return
 	}
-}
 
-// DenyPermission handles a denial from the UI.
-func (s *service) DenyPermission(requestID string, alwaysDeny bool, req CreatePermissionRequest) {
-	// Save to persistent store if "Always deny"
-	if alwaysDeny {
-		s.store.Deny(req.ToolName, req.Path, true)
+	if s.policies != nil {
+		switch decision {
+		case DecisionAlways:
+			// Never the whole tool: one command approved for bash must not
+			// let every other command run unasked
+			if scope := pending.req.GrantScope(); scope != "" {
+				_ = s.policies.AllowScope(pending.req.ToolName, scope)
+			}
+		case DecisionNever:
+			_ = s.policies.SetToolPolicy(pending.req.ToolName, PolicyDeny)
+		}
 	}
-	
-	// Send response to waiting request
-	s.mu.RLock()
-	if respCh, ok := s.pendingRequests[requestID]; ok {
-		s.mu.RUnlock()
-		respCh <- false
-	} else {
-		s.mu.RUnlock()
+
+	// A second answer to the same request is ignored rather than blocking
+	// the decision listener
+	select {
+	case pending.respCh <- decision == DecisionApprove || decision == DecisionAlways:
+	default:
 	}
 }
 
 // RequestAsync is for compatibility - just wraps Request.
-func (s *service) RequestAsync(req CreatePermissionRequest) <-chan bool {
+func (s *service) RequestAsync(ctx context.Context, req CreatePermissionRequest) <-chan bool {
 	ch := make(chan bool, 1)
 	go func() {
-		result := s.Request(req)
+		result := s.Request(ctx, req)
 		ch <- result
 		close(ch)
 	}()
@@ -124,37 +146,39 @@ func (s *service) SetHandler(handler RequestHandler) {
 	// No-op - we use event-based approach
 }
 
-// listenForResponses listens for permission response events from UI.
-func (s *service) listenForResponses() {
-	eventSub := s.eventBroker.Subscribe()
-	
-	for event := range eventSub {
-		switch event.Type {
-		case "tool.approved":
-			// Handle approval
-			if data, ok := event.Payload.(map[string]interface{}); ok {
-				if requestID, ok := data["requestID"].(string); ok {
-					if forSession, ok := data["forSession"].(bool); ok {
-						if request, ok := data["request"].(CreatePermissionRequest); ok {
-							s.GrantPermission(requestID, forSession, request)
-						}
-					}
-				}
-			}
-			
-		case "tool.denied":
-			// Handle denial
-			if data, ok := event.Payload.(map[string]interface{}); ok {
-				if requestID, ok := data["requestID"].(string); ok {
-					alwaysDeny := false
-					if ad, ok := data["alwaysDeny"].(bool); ok {
-						alwaysDeny = ad
-					}
-					if request, ok := data["request"].(CreatePermissionRequest); ok {
-						s.DenyPermission(requestID, alwaysDeny, request)
-					}
-				}
+// policy returns the tool's policy, treating unknown values as "ask".
+func (s *service) policy(tool string) string {
+	if s.policies == nil {
+		return PolicyAsk
+	}
+	switch p := s.policies.ToolPolicy(tool); p {
+	case PolicyAllow, PolicyDeny:
+		return p
+	default:
+		return PolicyAsk
+	}
+}
+
+// record appends an approved request to the audit log.
+func (s *service) record(req CreatePermissionRequest, approvedBy string) {
+	_ = s.audit.Record(req, approvedBy)
+}
+
+// listenForResponses listens for the permission dialog's decisions.
+func (s *service) listenForResponses(decisions <-chan events.Event) {
+	for event := range decisions {
+		payload, ok := event.Payload.(events.ToolExecutionPayload)
+		if !ok || payload.ID == "" {
+			continue
+		}
+		decision := payload.Decision
+		if decision == "" {
+			// Older publishers only signal approve/deny through the event type
+			decision = DecisionDeny
+			if event.Type == events.ToolExecutionApprovedEvent {
+				decision = DecisionApprove
 			}
 		}
+		s.Respond(payload.ID, decision)
 	}
-}
\ No newline at end of file
+}
@@ -1,12 +1,17 @@
 package permission
 
-import "errors"
+import (
+	"context"
+	"errors"
+
+	"github.com/billie-coop/loco/internal/tui/events"
+)
 
 // Service interface defines what permission services must implement.
 // This is what tools and other components depend on.
 type Service interface {
-	Request(req CreatePermissionRequest) bool
-	RequestAsync(req CreatePermissionRequest) <-chan bool
+	Request(ctx context.Context, req CreatePermissionRequest) bool
+	RequestAsync(ctx context.Context, req CreatePermissionRequest) <-chan bool
 	SetHandler(handler RequestHandler)
 }
 
@@ -19,6 +24,17 @@ type CreatePermissionRequest struct {
 	Action      string      `json:"action"`
 	Description string      `json:"description"`
 	Params      interface{} `json:"params,omitempty"`
+	Diff        string      `json:"diff,omitempty"` // Proposed file changes as a unified diff
+	Scope       string      `json:"scope,omitempty"` // What "always" allows from then on, like a host; Path when empty
+}
+
+// GrantScope returns what an "always" answer to the request allows: the
+// command, path or host it names rather than every use of the tool.
+func (r CreatePermissionRequest) GrantScope() string {
+	if r.Scope != "" {
+		return r.Scope
+	}
+	return r.Path
 }
 
 // PermissionRequestEvent is sent when permission is requested.
@@ -27,6 +43,35 @@ type PermissionRequestEvent struct {
 	Request CreatePermissionRequest `json:"request"`
 }
 
+// RequestTopic carries permission prompts to whoever can answer them.
+var RequestTopic = events.RegisterTopic[PermissionRequestEvent](events.PermissionRequestedEvent)
+
+// PolicyStore looks up and records per-tool policies ("allow", "ask" or "deny")
+// and the commands, paths and hosts the user always allows for a tool.
+// config.Manager implements it so policies live in .loco/config.jsonc.
+type PolicyStore interface {
+	ToolPolicy(tool string) string
+	SetToolPolicy(tool, policy string) error
+	ScopeAllowed(tool, scope string) bool
+	AllowScope(tool, scope string) error
+}
+
+// Policy values understood by the service
+const (
+	PolicyAllow = "allow"
+	PolicyAsk   = "ask"
+	PolicyDeny  = "deny"
+)
+
+// Decisions the permission dialog can send back
+const (
+	DecisionApprove = "approve" // Allow this request only
+	DecisionDeny    = "deny"    // Refuse this request only
+	DecisionAlways  = "always"  // Allow, and allow the same command, path or host from now on
+	DecisionNever   = "never"   // Refuse and set the tool's policy to deny
+	DecisionModify  = "modify"  // Refuse so the user can ask for a different change
+)
+
 // RequestHandler is a function that handles permission requests.
 type RequestHandler func(req CreatePermissionRequest) bool
 
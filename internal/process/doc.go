@@ -0,0 +1,17 @@
+// Package process runs long-lived commands for the project, such as dev
+// servers and file watchers, and keeps the end of their output.
+//
+// # Overview
+//
+// The bash tool waits for a command to finish; a dev server never does.
+// The Manager starts such commands in the background, on Linux in a
+// pseudo-terminal so they flush their output line by line as they would
+// in a terminal, and keeps their last lines with terminal escapes
+// stripped, for the model to read while they run.
+//
+// # Usage in Loco
+//
+// The run_process tool starts, lists, reads and stops processes. The app
+// shows each one's latest output in a tool card that updates as it runs,
+// and stops them all on exit.
+package process
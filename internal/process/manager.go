@@ -0,0 +1,428 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/crash"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// maxRunning caps the processes running at once
+	maxRunning = 5
+	// maxKept caps the processes kept, exited ones included, so their
+	// output can still be read
+	maxKept = 10
+	// maxOutputLines is how much output each process keeps
+	maxOutputLines = 2000
+	// maxPartialBytes caps a line still being written
+	maxPartialBytes = 64 * 1024
+	// updateInterval throttles update notifications per process
+	updateInterval = 500 * time.Millisecond
+	// stopGrace is how long a process has to exit after SIGTERM
+	stopGrace = 3 * time.Second
+)
+
+// Process states
+const (
+	StatusRunning = "running"
+	StatusExited  = "exited"  // Ended on its own
+	StatusStopped = "stopped" // Ended by Stop
+)
+
+// Info describes a process at one moment
+type Info struct {
+	ID       string    `json:"id"`
+	Command  string    `json:"command"`
+	PID      int       `json:"pid"`
+	Status   string    `json:"status"`
+	ExitCode int       `json:"exit_code"` // Meaningful once not running
+	PTY      bool      `json:"pty"`       // Runs in a pseudo-terminal rather than on pipes
+	Started  time.Time `json:"started"`
+	Ended    time.Time `json:"ended,omitzero"`
+	Lines    int       `json:"lines"` // Output lines seen, including those no longer kept
+}
+
+// Process is one managed command
+type Process struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+
+	mu      sync.Mutex
+	info    Info
+	lines   []string // The last maxOutputLines complete lines
+	partial string   // Output after the last newline
+	dirty   bool     // Output or state changed since the last notification
+	stopped bool
+}
+
+// Manager runs long-lived commands (dev servers, watchers) in the project
+// and keeps the end of their output
+type Manager struct {
+	workingDir string
+
+	mu        sync.Mutex
+	processes map[string]*Process
+	order     []string // IDs, oldest first
+	onUpdate  func(Info, []string)
+}
+
+// NewManager creates a manager for the project
+func NewManager(workingDir string) *Manager {
+	return &Manager{workingDir: workingDir, processes: make(map[string]*Process)}
+}
+
+// WorkingDir returns the directory processes start in
+func (m *Manager) WorkingDir() string {
+	return m.workingDir
+}
+
+// SetUpdateHandler sets fn to be called, at most every half second per
+// process, with the process's state and the last lines of its output
+func (m *Manager) SetUpdateHandler(fn func(Info, []string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onUpdate = fn
+}
+
+// Start runs command with the system shell in the project directory. name
+// becomes the process ID, made unique; empty names use the command's
+// first word.
+func (m *Manager) Start(name, command string) (Info, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return Info{}, errors.New("command is empty")
+	}
+
+	m.mu.Lock()
+	running := 0
+	for _, p := range m.processes {
+		if p.Info().Status == StatusRunning {
+			running++
+		}
+	}
+	if running >= maxRunning {
+		m.mu.Unlock()
+		return Info{}, fmt.Errorf("%d processes are already running; stop one first", running)
+	}
+	id := m.uniqueID(name, command)
+	m.mu.Unlock()
+
+	cmd := shellCommand(command)
+	cmd.Dir = m.workingDir
+	cmd.Env = os.Environ()
+
+	// A terminal makes most tools flush output line by line, as they would
+	// for a person; pipes are the fallback where none can be opened
+	var output io.ReadCloser
+	tty, pts, err := openPTY()
+	usePTY := err == nil
+	if usePTY {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = pts, pts, pts
+		output = tty
+	} else {
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			return Info{}, err
+		}
+		cmd.Stdout, cmd.Stderr = writer, writer
+		output = reader
+		pts = writer
+	}
+	configureCommand(cmd, usePTY)
+
+	if err := cmd.Start(); err != nil {
+		output.Close()
+		pts.Close()
+		return Info{}, fmt.Errorf("could not start %q: %w", command, err)
+	}
+	// The child holds its own copy; ours would keep reads from ending
+	pts.Close()
+
+	p := &Process{
+		cmd:  cmd,
+		done: make(chan struct{}),
+		info: Info{ID: id, Command: command, PID: cmd.Process.Pid, Status: StatusRunning, PTY: usePTY, Started: time.Now()},
+	}
+
+	m.mu.Lock()
+	m.processes[id] = p
+	m.order = append(m.order, id)
+	m.prune()
+	m.mu.Unlock()
+
+	go m.read(p, output)
+	go m.notify(p)
+	return p.Info(), nil
+}
+
+// uniqueID derives a process ID from name, or the command's program, that
+// no kept process has. The caller holds m.mu.
+func (m *Manager) uniqueID(name, command string) string {
+	base := strings.TrimSpace(name)
+	if base == "" {
+		fields := strings.Fields(command)
+		base = filepath.Base(fields[0])
+	}
+	base = strings.Map(func(r rune) rune {
+		if r == ' ' || r == ':' || r == '/' {
+			return '-'
+		}
+		return r
+	}, base)
+	id := base
+	for n := 2; m.processes[id] != nil; n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	return id
+}
+
+// prune forgets the oldest exited processes beyond maxKept. The caller
+// holds m.mu.
+func (m *Manager) prune() {
+	for i := 0; len(m.order) > maxKept && i < len(m.order); {
+		id := m.order[i]
+		if m.processes[id].Info().Status == StatusRunning {
+			i++
+			continue
+		}
+		delete(m.processes, id)
+		m.order = append(m.order[:i], m.order[i+1:]...)
+	}
+}
+
+// read collects a process's output until it ends, then records how it exited
+func (m *Manager) read(p *Process, output io.ReadCloser) {
+	defer crash.Recover("process " + p.info.ID)
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := output.Read(buf)
+			if n > 0 {
+				p.append(string(buf[:n]))
+			}
+			if err != nil {
+				// A terminal reports EIO rather than EOF once the child exits
+				return
+			}
+		}
+	}()
+
+	err := p.cmd.Wait()
+	// Background children may keep the output open; don't wait on them
+	select {
+	case <-readDone:
+	case <-time.After(time.Second):
+	}
+	output.Close()
+
+	p.mu.Lock()
+	p.flushPartial()
+	p.info.Ended = time.Now()
+	p.info.ExitCode = exitCode(err)
+	p.info.Status = StatusExited
+	if p.stopped {
+		p.info.Status = StatusStopped
+	}
+	p.dirty = true
+	p.mu.Unlock()
+	close(p.done)
+}
+
+// notify reports a process's changes to the update handler, throttled,
+// until it has ended and its final state was sent
+func (m *Manager) notify(p *Process) {
+	defer crash.Recover("process updates")
+	ticker := time.NewTicker(updateInterval)
+	defer ticker.Stop()
+	for {
+		ended := false
+		select {
+		case <-ticker.C:
+		case <-p.done:
+			ended = true
+		}
+
+		p.mu.Lock()
+		dirty := p.dirty
+		p.dirty = false
+		p.mu.Unlock()
+
+		m.mu.Lock()
+		onUpdate := m.onUpdate
+		m.mu.Unlock()
+		if dirty && onUpdate != nil {
+			onUpdate(p.Info(), p.Tail(15, nil))
+		}
+		if ended {
+			return
+		}
+	}
+}
+
+// append adds a chunk of output, splitting it into lines without terminal
+// escapes. A carriage return rewrites the line, as progress bars do.
+func (p *Process) append(chunk string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	text := p.partial + chunk
+	parts := strings.Split(text, "\n")
+	p.partial = parts[len(parts)-1]
+	for _, line := range parts[:len(parts)-1] {
+		p.addLine(line)
+	}
+	// Progress bars redraw one line forever; only its last state matters
+	if len(p.partial) > maxPartialBytes {
+		if i := strings.LastIndex(p.partial, "\r"); i >= 0 {
+			p.partial = p.partial[i:]
+		}
+		if len(p.partial) > maxPartialBytes {
+			p.flushPartial()
+		}
+	}
+	p.dirty = true
+}
+
+// flushPartial keeps output that never got a newline. The caller holds p.mu.
+func (p *Process) flushPartial() {
+	if p.partial != "" {
+		p.addLine(p.partial)
+		p.partial = ""
+	}
+}
+
+// addLine cleans up and stores one line. The caller holds p.mu.
+func (p *Process) addLine(line string) {
+	line = strings.TrimRight(line, "\r")
+	if i := strings.LastIndex(line, "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	line = strings.TrimRight(ansi.Strip(line), " \t")
+	p.info.Lines++
+	p.lines = append(p.lines, line)
+	if len(p.lines) > maxOutputLines {
+		p.lines = append([]string(nil), p.lines[len(p.lines)-maxOutputLines:]...)
+	}
+}
+
+// Info returns the process's current state
+func (p *Process) Info() Info {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.info
+}
+
+// Tail returns the last n lines of output, or of the lines matching
+// pattern when it is set, including a line still being written
+func (p *Process) Tail(n int, pattern *regexp.Regexp) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lines := p.lines
+	if p.partial != "" {
+		partial := strings.TrimRight(ansi.Strip(p.partial), "\r \t")
+		if i := strings.LastIndex(partial, "\r"); i >= 0 {
+			partial = partial[i+1:]
+		}
+		lines = append(lines[:len(lines):len(lines)], partial)
+	}
+	var kept []string
+	for i := len(lines) - 1; i >= 0 && len(kept) < n; i-- {
+		if pattern == nil || pattern.MatchString(lines[i]) {
+			kept = append(kept, lines[i])
+		}
+	}
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	return kept
+}
+
+// Get returns a kept process by ID
+func (m *Manager) Get(id string) (*Process, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.processes[id]
+	return p, ok
+}
+
+// List returns the kept processes, running ones first, then by start time
+func (m *Manager) List() []Info {
+	m.mu.Lock()
+	infos := make([]Info, 0, len(m.order))
+	for _, id := range m.order {
+		infos = append(infos, m.processes[id].Info())
+	}
+	m.mu.Unlock()
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Status == StatusRunning && infos[j].Status != StatusRunning
+	})
+	return infos
+}
+
+// Stop ends a process and its children: SIGTERM first, then SIGKILL if it
+// hasn't exited after a grace period
+func (m *Manager) Stop(id string) (Info, error) {
+	p, ok := m.Get(id)
+	if !ok {
+		return Info{}, fmt.Errorf("no process %q", id)
+	}
+	p.mu.Lock()
+	if p.info.Status != StatusRunning {
+		p.mu.Unlock()
+		return p.Info(), nil
+	}
+	p.stopped = true
+	p.mu.Unlock()
+
+	terminate(p.cmd)
+	select {
+	case <-p.done:
+	case <-time.After(stopGrace):
+		kill(p.cmd)
+		<-p.done
+	}
+	return p.Info(), nil
+}
+
+// Close stops every running process, for shutdown
+func (m *Manager) Close() {
+	var wg sync.WaitGroup
+	for _, info := range m.List() {
+		if info.Status != StatusRunning {
+			continue
+		}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			_, _ = m.Stop(id)
+		}(info.ID)
+	}
+	wg.Wait()
+}
+
+// exitCode returns the exit status Wait reported
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package process
+
+import (
+	"errors"
+	"os"
+)
+
+// openPTY is only implemented on Linux; elsewhere processes run on pipes
+func openPTY() (*os.File, *os.File, error) {
+	return nil, nil, errors.ErrUnsupported
+}
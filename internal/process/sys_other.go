@@ -0,0 +1,26 @@
+//go:build !unix
+
+package process
+
+import (
+	"os"
+	"os/exec"
+)
+
+// shellCommand runs command with the system shell
+func shellCommand(command string) *exec.Cmd {
+	return exec.Command("cmd", "/C", command)
+}
+
+// configureCommand has nothing to set up without process groups
+func configureCommand(cmd *exec.Cmd, tty bool) {}
+
+// terminate ends the command; there is no gentler signal to send
+func terminate(cmd *exec.Cmd) {
+	_ = cmd.Process.Signal(os.Kill)
+}
+
+// kill ends the command
+func kill(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
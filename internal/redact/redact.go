@@ -0,0 +1,203 @@
+// Package redact scrubs secrets from text before it leaves the machine.
+// API keys, tokens, private keys and credentials found in prompts, file
+// content and embedding input are replaced with placeholders like
+// [REDACTED:aws-access-key], and each secret found is logged once, masked,
+// so the user can see what was held back.
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Finding is one secret replaced in a text
+type Finding struct {
+	Kind        string // What the secret looked like, e.g. "github-token"
+	Masked      string // Its first and last characters, for telling secrets apart
+	Fingerprint string // A short hash of it, the same wherever it appears
+}
+
+// rule finds one kind of secret. When the pattern has a capture group,
+// only the group is the secret, so "password: hunter22" keeps its key.
+type rule struct {
+	kind    string
+	pattern *regexp.Regexp
+}
+
+// secretValue is the value part of a credential assignment: eight or more
+// characters that aren't a variable reference, a placeholder or redacted
+const secretValue = `([^\s"'$<{\[][^\s"'` + "`" + `,;]{7,})`
+
+// rules are checked in order, so specific token formats are named before
+// the generic credential rules see them
+var rules = []rule{
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"aws-access-key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github-token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"anthropic-key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	// Legacy keys are one unbroken run of letters and digits, so dashed
+	// identifiers like sk-learn-model-selection aren't taken for keys
+	{"openai-key", regexp.MustCompile(`\b(sk-(?:(?:proj|svcacct|admin)-[A-Za-z0-9_-]{40,}|[A-Za-z0-9]{20,}))(?:[^A-Za-z0-9_-]|$)`)},
+	{"stripe-key", regexp.MustCompile(`\b(?:sk|rk)_live_[A-Za-z0-9]{16,}\b`)},
+	{"slack-token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{"google-api-key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"url-password", regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:([^\s@/]+)@`)},
+	// KEY=value lines of .env files and shell scripts
+	{"credential", regexp.MustCompile(`(?m)^\s*(?:export\s+)?[A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|TOKEN|API_KEY|APIKEY|ACCESS_KEY|PRIVATE_KEY)[A-Z0-9_]*\s*=\s*["']?` + secretValue)},
+	// Quoted literals assigned to a credential-like name in code and config
+	{"credential", regexp.MustCompile(`(?i)\b[\w.-]*(?:password|passwd|secret|api[_-]?key|access[_-]?key|auth[_-]?token|access[_-]?token|private[_-]?key)[\w.-]*["']?\s*[:=]\s*["']` + secretValue + `["']`)},
+}
+
+// Redactor scrubs secrets from text and logs what it found
+type Redactor struct {
+	extra   []rule
+	logPath string // "" logs nothing
+
+	mu     sync.Mutex
+	logged map[string]bool // Fingerprints already logged
+	notify func(source string, findings []Finding)
+}
+
+// New creates a redactor logging to logPath. patterns are extra regular
+// expressions to redact, as kind "custom"; a capture group limits the
+// redaction to the group.
+func New(logPath string, patterns []string) (*Redactor, error) {
+	r := &Redactor{logPath: logPath, logged: make(map[string]bool)}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		r.extra = append(r.extra, rule{kind: "custom", pattern: re})
+	}
+	return r, nil
+}
+
+// OnRedact sets a function told about secrets the first time they are
+// redacted
+func (r *Redactor) OnRedact(fn func(source string, findings []Finding)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notify = fn
+}
+
+// Redact returns text with its secrets replaced by placeholders. source
+// says where the text was going, for the log. A nil Redactor returns text
+// as it is.
+func (r *Redactor) Redact(source, text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+	redacted, findings := scan(text, r.extra)
+	if len(findings) > 0 {
+		r.record(source, findings)
+	}
+	return redacted
+}
+
+// Scan returns text with the secrets the built-in rules find replaced, and
+// what was found
+func Scan(text string) (string, []Finding) {
+	return scan(text, nil)
+}
+
+// scan applies the built-in rules, then the extra ones
+func scan(text string, extra []rule) (string, []Finding) {
+	var findings []Finding
+	for _, group := range [][]rule{rules, extra} {
+		for _, rl := range group {
+			text = rl.replace(text, &findings)
+		}
+	}
+	return text, findings
+}
+
+// replace swaps each secret the rule matches for its placeholder
+func (rl rule) replace(text string, findings *[]Finding) string {
+	matches := rl.pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	placeholder := "[REDACTED:" + rl.kind + "]"
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if len(match) >= 4 && match[2] >= 0 {
+			start, end = match[2], match[3]
+		}
+		if start < last || start == end {
+			continue
+		}
+		secret := text[start:end]
+		*findings = append(*findings, Finding{Kind: rl.kind, Masked: mask(secret), Fingerprint: fingerprint(secret)})
+		b.WriteString(text[last:start])
+		b.WriteString(placeholder)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// record logs the findings not logged before and passes them on
+func (r *Redactor) record(source string, findings []Finding) {
+	r.mu.Lock()
+	var fresh []Finding
+	for _, finding := range findings {
+		if !r.logged[finding.Fingerprint] {
+			r.logged[finding.Fingerprint] = true
+			fresh = append(fresh, finding)
+		}
+	}
+	notify := r.notify
+	if len(fresh) > 0 && r.logPath != "" {
+		r.appendLog(source, fresh)
+	}
+	r.mu.Unlock()
+
+	if len(fresh) > 0 && notify != nil {
+		notify(source, fresh)
+	}
+}
+
+// appendLog writes one line per finding to the log
+func (r *Redactor) appendLog(source string, findings []Finding) {
+	if err := os.MkdirAll(filepath.Dir(r.logPath), 0o755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(r.logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	now := time.Now().Format(time.RFC3339)
+	for _, finding := range findings {
+		fmt.Fprintf(f, "%s %s: redacted %s %s (fingerprint %s)\n", now, source, finding.Kind, finding.Masked, finding.Fingerprint)
+	}
+}
+
+// mask keeps the first four and last two characters of a long secret
+func mask(secret string) string {
+	if strings.Contains(secret, "\n") {
+		first, _, _ := strings.Cut(secret, "\n")
+		return first + "…"
+	}
+	if len(secret) < 12 {
+		return "…"
+	}
+	return secret[:4] + "…" + secret[len(secret)-2:]
+}
+
+// fingerprint is a short hash identifying a secret without revealing it
+func fingerprint(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,34 @@
+// Package server exposes Loco over HTTP so editors and scripts can drive it
+// without the TUI.
+//
+// # Overview
+//
+// `loco serve` builds the same App as the TUI and answers JSON requests on
+// localhost. Every endpoint takes and returns JSON:
+//
+//	GET  /health             Project path and whether a model is connected
+//	POST /chat               {"message", "history"} -> {"reply", "context"}
+//	POST /analyze            {"tier", "project"} -> the analysis result
+//	GET  /analysis/{tier}    The cached analysis for a tier
+//	GET  /knowledge/{tier}   Knowledge documents from the cached analysis
+//	POST /knowledge/search   {"query", "k"} -> ranked chunks from the RAG index
+//	POST /knowledge/ask      {"question", "k"} -> answer with cited sources
+//	POST /tools/{name}       Tool parameters -> the tool's response
+//	GET  /events?type=...    Server-Sent Events from the event broker;
+//	                         type defaults to analysis.* (progress of runs)
+//
+// # Workspaces
+//
+// In a go.work, pnpm or Cargo workspace, "project" names a subproject to
+// analyze, and ?project= picks one for the cached endpoints. Each
+// subproject keeps its knowledge under .loco/knowledge/<project>/. A
+// project of "all" analyzes every subproject, returns the results keyed by
+// name, and writes .loco/knowledge/workspace.md.
+//
+// # Safety
+//
+// The server binds to 127.0.0.1 by default and refuses requests that carry
+// an Origin header or a non-local Host, so web pages can't reach it from a
+// browser. Tools still go through the permission service; with nobody to
+// answer prompts, only tools whose policy is "allow" will act.
+package server
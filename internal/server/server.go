@@ -0,0 +1,432 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/app"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/tools"
+	"github.com/billie-coop/loco/internal/tui/events"
+)
+
+// DefaultAddr is where `loco serve` listens unless told otherwise
+const DefaultAddr = "127.0.0.1:7777"
+
+// maxRequestBytes caps request bodies
+const maxRequestBytes = 1 << 20
+
+// Server answers HTTP requests using an App
+type Server struct {
+	app *app.App
+	mux *http.ServeMux
+
+	// Requests that use the model run one at a time, like in the TUI
+	modelMu sync.Mutex
+}
+
+// New creates a server that answers with the app's services
+func New(a *app.App) *Server {
+	s := &Server{
+		app: a,
+		mux: http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /health", s.handleHealth)
+	s.mux.HandleFunc("POST /chat", s.handleChat)
+	s.mux.HandleFunc("POST /analyze", s.handleAnalyze)
+	s.mux.HandleFunc("GET /analysis/{tier}", s.handleCachedAnalysis)
+	s.mux.HandleFunc("GET /knowledge/{tier}", s.handleKnowledge)
+	s.mux.HandleFunc("POST /knowledge/search", s.handleSearch)
+	s.mux.HandleFunc("POST /knowledge/ask", s.handleAsk)
+	s.mux.HandleFunc("POST /tools/{name}", s.handleTool)
+	s.mux.HandleFunc("GET /events", s.handleEvents)
+
+	return s
+}
+
+// Handler returns the server's HTTP handler
+func (s *Server) Handler() http.Handler {
+	return localOnly(s.mux)
+}
+
+// ListenAndServe serves on addr until ctx is canceled, then shuts down
+// gracefully
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+// localOnly rejects browser requests and requests addressed to a non-local
+// host, which guards against cross-site requests and DNS rebinding
+func localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != "" {
+			writeError(w, http.StatusForbidden, "cross-origin requests are not allowed")
+			return
+		}
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if host != "localhost" {
+			if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+				writeError(w, http.StatusForbidden, "only local requests are allowed")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// healthResponse is the reply to GET /health
+type healthResponse struct {
+	Status  string `json:"status"`
+	Project string `json:"project"`
+	Model   bool   `json:"model"` // Whether an LLM client is configured
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, healthResponse{
+		Status:  "ok",
+		Project: s.app.Sessions.ProjectPath,
+		Model:   s.app.LLM != nil,
+	})
+}
+
+// chatRequest is the body of POST /chat. History holds earlier turns; the
+// server keeps no conversation state of its own.
+type chatRequest struct {
+	Message string        `json:"message"`
+	History []llm.Message `json:"history,omitempty"`
+}
+
+// chatResponse is the reply to POST /chat
+type chatResponse struct {
+	Reply   string             `json:"reply"`
+	Context []llm.ContextChunk `json:"context,omitempty"` // Retrieved code given to the model
+}
+
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	var req chatRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		writeError(w, http.StatusBadRequest, "message is required")
+		return
+	}
+
+	s.modelMu.Lock()
+	defer s.modelMu.Unlock()
+
+	reply, chunks, err := s.app.LLMService.Complete(r.Context(), req.History, req.Message)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Sprintf("chat failed: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, chatResponse{Reply: reply, Context: chunks})
+}
+
+// analyzeRequest is the body of POST /analyze
+type analyzeRequest struct {
+	Tier    string `json:"tier,omitempty"`    // Defaults to quick
+	Project string `json:"project,omitempty"` // Workspace subproject, or "all"
+}
+
+func (s *Server) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	var req analyzeRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	tier := analysis.Tier(req.Tier)
+	if tier == "" {
+		tier = analysis.TierQuick
+	}
+
+	s.modelMu.Lock()
+	defer s.modelMu.Unlock()
+
+	if req.Project == "all" {
+		workspace := analysis.DetectWorkspace(s.app.Sessions.ProjectPath)
+		if workspace == nil {
+			writeError(w, http.StatusBadRequest, "the project is not a workspace")
+			return
+		}
+		results, err := analysis.RunWorkspace(r.Context(), s.app.Analysis, workspace, tier)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("analysis failed: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, results)
+		return
+	}
+
+	projectPath, err := analysis.ResolveProject(s.app.Sessions.ProjectPath, req.Project)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	result, err := analysis.RunTier(r.Context(), s.app.Analysis, projectPath, tier)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("analysis failed: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleCachedAnalysis(w http.ResponseWriter, r *http.Request) {
+	result, ok := s.cachedAnalysis(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleKnowledge(w http.ResponseWriter, r *http.Request) {
+	result, ok := s.cachedAnalysis(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, result.GetKnowledgeFiles())
+}
+
+// cachedAnalysis loads the analysis for the request's tier, and the
+// workspace subproject named by ?project=, writing a 404 when there is none
+func (s *Server) cachedAnalysis(w http.ResponseWriter, r *http.Request) (analysis.Analysis, bool) {
+	tier := analysis.Tier(r.PathValue("tier"))
+	projectPath, err := analysis.ResolveProject(s.app.Sessions.ProjectPath, r.URL.Query().Get("project"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	result, err := s.app.Analysis.GetCachedAnalysis(projectPath, tier)
+	if err != nil || result == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no %s analysis yet - POST /analyze with {\"tier\": %q} first", tier, tier))
+		return nil, false
+	}
+	return result, true
+}
+
+// searchRequest is the body of POST /knowledge/search
+type searchRequest struct {
+	Query string `json:"query"`
+	K     int    `json:"k,omitempty"` // Defaults to 5
+}
+
+// searchResult is one chunk in the reply to POST /knowledge/search
+type searchResult struct {
+	Path      string  `json:"path"`
+	StartLine int     `json:"start_line,omitempty"`
+	EndLine   int     `json:"end_line,omitempty"`
+	Symbol    string  `json:"symbol,omitempty"`
+	Language  string  `json:"language,omitempty"`
+	Score     float32 `json:"score"`
+	Content   string  `json:"content"`
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	var req searchRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+	if s.app.Sidecar == nil {
+		writeError(w, http.StatusServiceUnavailable, "RAG service not available")
+		return
+	}
+	if req.K <= 0 {
+		req.K = 5
+	}
+
+	results, err := s.app.Sidecar.QuerySimilar(r.Context(), req.Query, min(req.K, 50))
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Sprintf("search failed: %v", err))
+		return
+	}
+
+	response := make([]searchResult, 0, len(results))
+	for _, result := range results {
+		item := searchResult{
+			Path:    result.Path,
+			Score:   result.Score,
+			Content: result.Content,
+		}
+		if rel, err := filepath.Rel(s.app.Sessions.ProjectPath, result.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			item.Path = rel
+		}
+		item.StartLine, _ = result.Metadata["start_line"].(int)
+		item.EndLine, _ = result.Metadata["end_line"].(int)
+		item.Symbol, _ = result.Metadata["symbol"].(string)
+		item.Language, _ = result.Metadata["language"].(string)
+		response = append(response, item)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleAsk(w http.ResponseWriter, r *http.Request) {
+	var req tools.AskCodebaseParams
+	if !readJSON(w, r, &req) {
+		return
+	}
+	input, _ := json.Marshal(req)
+
+	result, status := s.runTool(r.Context(), tools.ToolCall{Name: tools.AskCodebaseToolName, Input: string(input)})
+	if status != http.StatusOK {
+		writeError(w, status, result.Content)
+		return
+	}
+	writeJSON(w, http.StatusOK, result.Metadata)
+}
+
+func (s *Server) handleTool(w http.ResponseWriter, r *http.Request) {
+	var params json.RawMessage
+	if !readJSON(w, r, &params) {
+		return
+	}
+	if len(params) == 0 || string(params) == "null" {
+		params = json.RawMessage("{}")
+	}
+
+	result, status := s.runTool(r.Context(), tools.ToolCall{
+		ID:    fmt.Sprintf("http_%d", time.Now().UnixNano()),
+		Name:  r.PathValue("name"),
+		Input: string(params),
+	})
+	writeJSON(w, status, result)
+}
+
+// runTool runs a registered tool, mapping failures to an HTTP status
+func (s *Server) runTool(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, int) {
+	tool, ok := s.app.Tools.Get(call.Name)
+	if !ok {
+		return tools.NewTextErrorResponse(fmt.Sprintf("unknown tool: %s", call.Name)), http.StatusNotFound
+	}
+
+	s.modelMu.Lock()
+	defer s.modelMu.Unlock()
+
+	ctx = context.WithValue(ctx, tools.InitiatorKey, "agent")
+	result, err := tool.Run(ctx, call)
+	if s.app.Tasks != nil && call.Name != tools.PlanToolName {
+		s.app.Tasks.Record(call.Name, err == nil && !result.IsError)
+	}
+	if err != nil {
+		return tools.NewTextErrorResponse(fmt.Sprintf("tool execution failed: %v", err)), http.StatusInternalServerError
+	}
+	if result.IsError {
+		return result, http.StatusUnprocessableEntity
+	}
+	return result, http.StatusOK
+}
+
+// streamedEvent is one Server-Sent Event from GET /events
+type streamedEvent struct {
+	Type    events.EventType `json:"type"`
+	Payload any              `json:"payload,omitempty"`
+}
+
+// handleEvents streams broker events as Server-Sent Events until the client
+// goes away. Each ?type= is an event type or pattern; the default follows
+// analysis runs.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	patterns := []events.EventType{"analysis.*"}
+	if types := r.URL.Query()["type"]; len(types) > 0 {
+		patterns = make([]events.EventType, len(types))
+		for i, t := range types {
+			patterns[i] = events.EventType(t)
+		}
+	}
+
+	ch := s.app.EventBroker.SubscribeWith(events.SubscribeOptions{Name: "http events"}, patterns...)
+	defer s.app.EventBroker.Unsubscribe(ch, patterns...)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, open := <-ch:
+			if !open {
+				return
+			}
+			data, err := json.Marshal(streamedEvent{Type: event.Type, Payload: event.Payload})
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// readJSON decodes the request body into v, writing a 400 on failure. An
+// empty body leaves v unchanged.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return false
+	}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(v)
+	if err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON: %v", err))
+		return false
+	}
+	return true
+}
+
+// errorResponse is the body of every error reply
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/billie-coop/loco/internal/csync"
 	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/tasks"
 )
 
 // sessionJSON is a helper struct for JSON marshaling/unmarshaling
@@ -14,8 +15,11 @@ type sessionJSON struct {
 	LastUpdated time.Time     `json:"last_updated"`
 	ID          string        `json:"id"`
 	Title       string        `json:"title"`
+	Model       string        `json:"model,omitempty"`
 	Team        *ModelTeam    `json:"team"`
 	Messages    []llm.Message `json:"messages"`
+	View        *ViewState    `json:"view,omitempty"`
+	Plan        *tasks.Plan   `json:"plan,omitempty"`
 }
 
 // MarshalJSON implements json.Marshaler for Session
@@ -25,8 +29,11 @@ func (s *Session) MarshalJSON() ([]byte, error) {
 		LastUpdated: s.LastUpdated,
 		ID:          s.ID,
 		Title:       s.Title,
+		Model:       s.Model,
 		Team:        s.Team,
 		Messages:    s.Messages.ToSlice(),
+		View:        s.View,
+		Plan:        s.Plan,
 	})
 }
 
@@ -41,8 +48,11 @@ func (s *Session) UnmarshalJSON(data []byte) error {
 	s.LastUpdated = temp.LastUpdated
 	s.ID = temp.ID
 	s.Title = temp.Title
+	s.Model = temp.Model
 	s.Team = temp.Team
 	s.Messages = csync.NewSliceFrom(temp.Messages)
+	s.View = temp.View
+	s.Plan = temp.Plan
 	
 	return nil
 }
\ No newline at end of file
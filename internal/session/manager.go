@@ -12,6 +12,7 @@ import (
 
 	"github.com/billie-coop/loco/internal/csync"
 	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/tasks"
 )
 
 // ModelTeam represents the S/M/L model configuration for a session.
@@ -28,8 +29,19 @@ type Session struct {
 	LastUpdated time.Time                   `json:"last_updated"`
 	ID          string                      `json:"id"`
 	Title       string                      `json:"title"`
+	Model       string                      `json:"model,omitempty"` // Chat model picked with /model; empty keeps the default
 	Team        *ModelTeam                  `json:"team"`
 	Messages    *csync.Slice[llm.Message]   `json:"messages"`
+	View        *ViewState                  `json:"view,omitempty"`
+	Plan        *tasks.Plan                 `json:"plan,omitempty"` // Steps being worked through, set with /plan
+}
+
+// ViewState is where the chat view was left, so showing the session again
+// returns to the same place.
+type ViewState struct {
+	Saved         time.Time    `json:"saved"`
+	ScrollOffset  int          `json:"scroll_offset"`            // Lines scrolled up from the newest message
+	ExpandedTools map[int]bool `json:"expanded_tools,omitempty"` // Tool blocks expanded or collapsed, by message index
 }
 
 // Manager handles multiple chat sessions.
@@ -65,6 +77,7 @@ func (m *Manager) NewSession(model string) (*Session, error) {
 	session := &Session{
 		ID:          m.generateID(),
 		Title:       "New Chat",
+		Model:       model,
 		Messages:    csync.NewSlice[llm.Message](),
 		Created:     time.Now(),
 		LastUpdated: time.Now(),
@@ -138,6 +151,9 @@ func (m *Manager) AddMessage(msg llm.Message) error {
 		return err
 	}
 
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
 	session.Messages.Append(msg)
 	session.LastUpdated = time.Now()
 
@@ -149,6 +165,49 @@ func (m *Manager) AddMessage(msg llm.Message) error {
 	return m.saveSession(session)
 }
 
+// SetModel sets the chat model of the current session.
+func (m *Manager) SetModel(model string) error {
+	session, err := m.GetCurrent()
+	if err != nil {
+		return err
+	}
+
+	session.Model = model
+	return m.saveSession(session)
+}
+
+// SetView records where the chat view of the current session was left.
+// Viewing a session does not count as updating it.
+func (m *Manager) SetView(view *ViewState) error {
+	session, err := m.GetCurrent()
+	if err != nil {
+		return err
+	}
+
+	session.View = view
+	return m.saveSession(session)
+}
+
+// Plan returns the plan of the current session, or nil when it has none.
+func (m *Manager) Plan() (*tasks.Plan, error) {
+	session, err := m.GetCurrent()
+	if err != nil {
+		return nil, err
+	}
+	return session.Plan, nil
+}
+
+// SetPlan sets the plan of the current session; nil clears it.
+func (m *Manager) SetPlan(plan *tasks.Plan) error {
+	session, err := m.GetCurrent()
+	if err != nil {
+		return err
+	}
+
+	session.Plan = plan
+	return m.saveSession(session)
+}
+
 // GetMessages returns all messages from the current session as a slice.
 func (m *Manager) GetMessages() ([]llm.Message, error) {
 	session, err := m.GetCurrent()
@@ -239,15 +298,28 @@ func (m *Manager) loadSessions() error {
 		m.sessions.Set(session.ID, &session)
 	}
 
-	// Set current to most recent if not set
+	// Set current to the one last shown or updated if not set
 	if m.currentID == "" && m.sessions.Len() > 0 {
-		sessions := m.ListSessions()
-		m.currentID = sessions[0].ID
+		var latest time.Time
+		for _, session := range m.ListSessions() {
+			if seen := lastSeen(session); m.currentID == "" || seen.After(latest) {
+				m.currentID = session.ID
+				latest = seen
+			}
+		}
 	}
 
 	return nil
 }
 
+// lastSeen is when a session was last updated or left on screen
+func lastSeen(session *Session) time.Time {
+	if session.View != nil && session.View.Saved.After(session.LastUpdated) {
+		return session.View.Saved
+	}
+	return session.LastUpdated
+}
+
 func (m *Manager) saveSession(session *Session) error {
 	sessionPath := filepath.Join(m.sessionsPath, session.ID+".json")
 
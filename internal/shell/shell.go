@@ -89,6 +89,14 @@ func (ps *PersistentShell) Exec(ctx context.Context, command string) (stdout, st
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
 
+	// The interpreter panics on some redirects it doesn't support, like
+	// ">& file"; report them like any other failure
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("shell error: %v", r)
+		}
+	}()
+
 	// Parse the command
 	prog, err := syntax.NewParser().Parse(strings.NewReader(command), "")
 	if err != nil {
@@ -254,4 +262,202 @@ func ArgumentsBlocker(blockedCombos [][]string) BlockFunc {
 		}
 		return nil
 	}
-}
\ No newline at end of file
+}
+
+// SimpleCommands parses a command line and returns the argv of every simple
+// command it contains, including those inside pipelines, lists and subshells.
+// Quotes and escapes are removed, so 'sudo' and \sudo both give sudo. Words
+// that are not plain literals (e.g. "$VAR" or "$(cmd)") are returned as they
+// appear in the source so callers can still inspect them.
+func SimpleCommands(command string) ([][]string, error) {
+	prog, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+
+	printer := syntax.NewPrinter()
+	var commands [][]string
+	syntax.Walk(prog, func(node syntax.Node) bool {
+		call, ok := node.(*syntax.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		argv := make([]string, 0, len(call.Args))
+		for _, word := range call.Args {
+			if lit, ok := literalWord(word); ok {
+				argv = append(argv, lit)
+				continue
+			}
+			var buf bytes.Buffer
+			if err := printer.Print(&buf, word); err == nil {
+				argv = append(argv, buf.String())
+			}
+		}
+		commands = append(commands, argv)
+		return true
+	})
+
+	return commands, nil
+}
+
+// WritesFiles reports whether a command line redirects output into a file
+// (anything other than /dev/null), which makes otherwise read-only commands
+// such as echo or cat able to modify the project.
+func WritesFiles(command string) bool {
+	prog, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return true
+	}
+
+	writes := false
+	syntax.Walk(prog, func(node syntax.Node) bool {
+		redirect, ok := node.(*syntax.Redirect)
+		if !ok {
+			return !writes
+		}
+		switch redirect.Op {
+		case syntax.RdrOut, syntax.AppOut, syntax.RdrAll, syntax.AppAll, syntax.RdrInOut, syntax.ClbOut:
+			if redirect.Word == nil || redirect.Word.Lit() != "/dev/null" {
+				writes = true
+			}
+		case syntax.DplOut, syntax.DplIn:
+			// >&2 and <&- only duplicate or close descriptors; >& file
+			// writes the file
+			if word := redirect.Word.Lit(); word != "-" && !isDigits(word) {
+				writes = true
+			}
+		}
+		return !writes
+	})
+	return writes
+}
+
+// isDigits reports whether s is a non-empty run of decimal digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// PathWords returns the words of a command line that may name files: the
+// words of every simple command, the values of --option=value words and the
+// targets of redirects. Quotes are removed and braces expanded, so
+// .{e,}nv gives .env and .nv. Globs are returned as they are. Words built
+// from expansions, like "$HOME/notes", are left out since their value is
+// only known when the command runs; see HasExpansions.
+func PathWords(command string) ([]string, error) {
+	prog, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+
+	var words []string
+	var add func(word *syntax.Word)
+	add = func(word *syntax.Word) {
+		if syntax.SplitBraces(word) {
+			for _, expanded := range expand.Braces(word) {
+				add(expanded)
+			}
+			return
+		}
+		value, ok := literalWord(word)
+		if !ok || value == "" {
+			return
+		}
+		words = append(words, value)
+		if _, option, ok := strings.Cut(value, "="); ok && strings.HasPrefix(value, "-") && option != "" {
+			words = append(words, option)
+		}
+	}
+	// Splitting braces changes the tree, so collect the words first
+	var found []*syntax.Word
+	syntax.Walk(prog, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.CallExpr:
+			found = append(found, node.Args...)
+		case *syntax.Redirect:
+			if node.Word != nil {
+				found = append(found, node.Word)
+			}
+		}
+		return true
+	})
+	for _, word := range found {
+		add(word)
+	}
+	return words, nil
+}
+
+// HasExpansions reports whether a command line uses parameter, arithmetic
+// or command expansions, whose values are only known when it runs
+func HasExpansions(command string) bool {
+	prog, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return true
+	}
+
+	found := false
+	syntax.Walk(prog, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.ParamExp, *syntax.CmdSubst, *syntax.ProcSubst, *syntax.ArithmExp:
+			found = true
+		case *syntax.SglQuoted:
+			found = found || node.Dollar
+		}
+		return !found
+	})
+	return found
+}
+
+// literalWord returns the value of a word made only of literal text and
+// quotes, and false for words with expansions
+func literalWord(word *syntax.Word) (string, bool) {
+	var b strings.Builder
+	for _, part := range word.Parts {
+		switch part := part.(type) {
+		case *syntax.Lit:
+			b.WriteString(unescape(part.Value))
+		case *syntax.SglQuoted:
+			if part.Dollar {
+				return "", false
+			}
+			b.WriteString(part.Value)
+		case *syntax.DblQuoted:
+			for _, inner := range part.Parts {
+				lit, ok := inner.(*syntax.Lit)
+				if !ok {
+					return "", false
+				}
+				b.WriteString(strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\$`, `$`, "\\`", "`").Replace(lit.Value))
+			}
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// unescape removes the backslashes of an unquoted literal, where a
+// backslash makes the next character plain text and drops a newline
+func unescape(value string) string {
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+			if value[i] == '\n' {
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,168 @@
+package sidecar
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const (
+	lineChunkSize    = 30 // Lines per chunk for the line-based fallback
+	lineChunkOverlap = 5  // Overlapping lines for the line-based fallback
+
+	maxChunkLines = 80 // Declarations longer than this are split
+	minChunkLines = 8  // Smaller neighbouring declarations are merged
+)
+
+// chunk represents a file chunk.
+type chunk struct {
+	content   string
+	startLine int
+	endLine   int
+	symbol    string // Declared name(s), when the chunk follows syntax
+	kind      string // "func", "method", "type", "class", ... ("" for line chunks)
+}
+
+// chunkFile splits a file into chunks for embedding, aligned with
+// functions and types where the language has a grammar.
+func (s *service) chunkFile(path string, content string) []chunk {
+	chunks := declarationChunks(path, content)
+	if len(chunks) == 0 {
+		return lineChunks(strings.Split(content, "\n"), 1)
+	}
+	return chunks
+}
+
+// embedText is what gets embedded: the chunk prefixed with where it lives
+// and what it declares, so queries naming a symbol or file match it.
+func (c chunk) embedText(path string) string {
+	header := "// " + path
+	if c.symbol != "" {
+		header += " " + strings.TrimSpace(c.kind+" "+c.symbol)
+	}
+	return header + "\n" + c.content
+}
+
+// lineChunks splits lines into overlapping windows; firstLine is the
+// 1-based line number of lines[0].
+func lineChunks(lines []string, firstLine int) []chunk {
+	var chunks []chunk
+	for i := 0; i < len(lines); i += (lineChunkSize - lineChunkOverlap) {
+		end := i + lineChunkSize
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		chunkContent := strings.Join(lines[i:end], "\n")
+		if strings.TrimSpace(chunkContent) != "" {
+			chunks = append(chunks, chunk{
+				content:   chunkContent,
+				startLine: firstLine + i,
+				endLine:   firstLine + end - 1,
+			})
+		}
+
+		if end >= len(lines) {
+			break
+		}
+	}
+	return chunks
+}
+
+// span is a declaration's 1-based line range before it becomes a chunk
+type span struct {
+	start, end int
+	symbol     string
+	kind       string
+}
+
+// spansToChunks merges small neighbouring spans, splits oversized ones and
+// trims surrounding blank lines.
+func spansToChunks(lines []string, spans []span) []chunk {
+	var chunks []chunk
+	var pending *chunk
+
+	flush := func() {
+		if pending != nil && strings.TrimSpace(pending.content) != "" {
+			chunks = append(chunks, *pending)
+		}
+		pending = nil
+	}
+
+	for _, sp := range spans {
+		start, end := trimBlank(lines, sp.start, sp.end)
+		if start > end {
+			continue
+		}
+		size := end - start + 1
+
+		if size > maxChunkLines {
+			flush()
+			chunks = append(chunks, splitSpan(lines, start, end, sp)...)
+			continue
+		}
+
+		text := strings.Join(lines[start-1:end], "\n")
+		if pending != nil && pending.endLine-pending.startLine+1+size <= minChunkLines*2 &&
+			(pending.endLine-pending.startLine+1 < minChunkLines || size < minChunkLines) {
+			pending.content += "\n\n" + text
+			pending.endLine = end
+			pending.symbol = joinSymbols(pending.symbol, sp.symbol)
+			if pending.kind != sp.kind {
+				pending.kind = ""
+			}
+			continue
+		}
+
+		flush()
+		pending = &chunk{content: text, startLine: start, endLine: end, symbol: sp.symbol, kind: sp.kind}
+	}
+	flush()
+	return chunks
+}
+
+// splitSpan breaks a long declaration into line windows, repeating its
+// first line on later windows so each still says what it belongs to.
+func splitSpan(lines []string, start, end int, sp span) []chunk {
+	parts := lineChunks(lines[start-1:end], start)
+	signature := strings.TrimSpace(lines[start-1])
+	for i := range parts {
+		parts[i].symbol = sp.symbol
+		parts[i].kind = sp.kind
+		if i > 0 && signature != "" {
+			parts[i].content = signature + " // (continued)\n" + parts[i].content
+		}
+	}
+	return parts
+}
+
+// trimBlank narrows a 1-based inclusive range to exclude blank edge lines
+func trimBlank(lines []string, start, end int) (int, int) {
+	if end > len(lines) {
+		end = len(lines)
+	}
+	for start <= end && strings.TrimSpace(lines[start-1]) == "" {
+		start++
+	}
+	for end >= start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return start, end
+}
+
+func joinSymbols(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return a + ", " + b
+}
+
+// relativePath shows a path relative to the project when possible
+func (s *service) relativePath(path string) string {
+	if rel, err := filepath.Rel(s.workingDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
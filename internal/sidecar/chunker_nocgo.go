@@ -0,0 +1,9 @@
+//go:build !cgo
+
+package sidecar
+
+// declarationChunks returns nil without cgo: the tree-sitter grammars are C
+// libraries, so every file falls back to line chunks.
+func declarationChunks(path string, content string) []chunk {
+	return nil
+}
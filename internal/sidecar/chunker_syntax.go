@@ -0,0 +1,386 @@
+//go:build cgo
+
+package sidecar
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/c"
+	"github.com/smacker/go-tree-sitter/cpp"
+	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/java"
+	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/rust"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
+	"github.com/smacker/go-tree-sitter/typescript/typescript"
+)
+
+// declarationChunks chunks a file along its declarations, or returns nil
+// when there is no grammar for it or it fails to parse.
+func declarationChunks(path string, content string) []chunk {
+	if g, ok := grammarFor(path); ok {
+		return syntaxChunks(g, content)
+	}
+	return nil
+}
+
+// grammar describes how to chunk one language's syntax tree.
+type grammar struct {
+	language *sitter.Language
+	// kinds maps declaration node types to the kind recorded on the chunk.
+	// Node types not listed still become chunks, just without a symbol.
+	kinds map[string]string
+	// containers are declarations whose members are chunked individually
+	// when the whole declaration is too large for one chunk.
+	containers map[string]bool
+}
+
+var (
+	jsKinds = map[string]string{
+		"function_declaration":           "function",
+		"generator_function_declaration": "function",
+		"class_declaration":              "class",
+		"lexical_declaration":            "const",
+		"variable_declaration":           "var",
+		"method_definition":              "method",
+		"field_definition":               "field",
+	}
+	tsKinds = mergeKinds(jsKinds, map[string]string{
+		"abstract_class_declaration": "class",
+		"interface_declaration":      "interface",
+		"type_alias_declaration":     "type",
+		"enum_declaration":           "enum",
+		"internal_module":            "namespace",
+		"module":                     "namespace",
+		"public_field_definition":    "field",
+		"method_signature":           "method",
+	})
+	tsContainers = map[string]bool{
+		"class_declaration": true, "abstract_class_declaration": true,
+		"interface_declaration": true, "internal_module": true, "module": true,
+	}
+	cKinds = map[string]string{
+		"function_definition": "function",
+		"struct_specifier":    "struct",
+		"union_specifier":     "union",
+		"enum_specifier":      "enum",
+		"type_definition":     "type",
+		"declaration":         "var",
+	}
+)
+
+// grammars maps languages from detectLanguage to their tree-sitter grammar.
+var grammars = map[string]grammar{
+	"go": {
+		language: golang.GetLanguage(),
+		kinds: map[string]string{
+			"function_declaration": "func",
+			"method_declaration":   "method",
+			"type_declaration":     "type",
+			"const_declaration":    "const",
+			"var_declaration":      "var",
+		},
+	},
+	"javascript": {
+		language:   javascript.GetLanguage(),
+		kinds:      jsKinds,
+		containers: map[string]bool{"class_declaration": true},
+	},
+	"typescript": {
+		language:   typescript.GetLanguage(),
+		kinds:      tsKinds,
+		containers: tsContainers,
+	},
+	"python": {
+		language: python.GetLanguage(),
+		kinds: map[string]string{
+			"function_definition": "def",
+			"class_definition":    "class",
+		},
+		containers: map[string]bool{"class_definition": true},
+	},
+	"rust": {
+		language: rust.GetLanguage(),
+		kinds: map[string]string{
+			"function_item":           "fn",
+			"function_signature_item": "fn",
+			"struct_item":             "struct",
+			"enum_item":               "enum",
+			"union_item":              "union",
+			"trait_item":              "trait",
+			"impl_item":               "impl",
+			"mod_item":                "mod",
+			"type_item":               "type",
+			"const_item":              "const",
+			"static_item":             "static",
+			"macro_definition":        "macro",
+		},
+		containers: map[string]bool{"impl_item": true, "trait_item": true, "mod_item": true},
+	},
+	"java": {
+		language: java.GetLanguage(),
+		kinds: map[string]string{
+			"class_declaration":       "class",
+			"interface_declaration":   "interface",
+			"enum_declaration":        "enum",
+			"record_declaration":      "record",
+			"method_declaration":      "method",
+			"constructor_declaration": "constructor",
+			"field_declaration":       "field",
+		},
+		containers: map[string]bool{
+			"class_declaration": true, "interface_declaration": true,
+			"enum_declaration": true, "record_declaration": true,
+		},
+	},
+	"c": {
+		language: c.GetLanguage(),
+		kinds:    cKinds,
+	},
+	"cpp": {
+		language: cpp.GetLanguage(),
+		kinds: mergeKinds(cKinds, map[string]string{
+			"class_specifier":      "class",
+			"namespace_definition": "namespace",
+			"template_declaration": "template",
+			"field_declaration":    "field",
+		}),
+		containers: map[string]bool{"class_specifier": true, "struct_specifier": true, "namespace_definition": true},
+	},
+}
+
+// tsxGrammar parses .tsx files, which the plain TypeScript grammar rejects.
+var tsxGrammar = grammar{language: tsx.GetLanguage(), kinds: tsKinds, containers: tsContainers}
+
+// grammarFor picks the grammar for a file, if there is one
+func grammarFor(path string) (grammar, bool) {
+	if strings.EqualFold(filepath.Ext(path), ".tsx") {
+		return tsxGrammar, true
+	}
+	g, ok := grammars[detectLanguage(path)]
+	return g, ok
+}
+
+// syntaxChunks parses content and chunks it along top-level declarations,
+// keeping leading comments with the declaration they describe.
+func syntaxChunks(g grammar, content string) []chunk {
+	src := []byte(content)
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(g.language)
+
+	tree, err := parser.ParseCtx(context.Background(), nil, src)
+	if err != nil || tree == nil {
+		return nil
+	}
+	defer tree.Close()
+
+	spans := g.spans(tree.RootNode(), src, "")
+	if len(spans) == 0 {
+		return nil
+	}
+	return spansToChunks(strings.Split(content, "\n"), spans)
+}
+
+// spans turns the named children of parent into spans. prefix qualifies
+// member symbols with their enclosing declaration.
+func (g grammar) spans(parent *sitter.Node, src []byte, prefix string) []span {
+	var spans []span
+	commentStart := 0 // first line of a comment run awaiting its declaration
+
+	count := int(parent.NamedChildCount())
+	for i := 0; i < count; i++ {
+		node := parent.NamedChild(i)
+		start, end := nodeLines(node)
+
+		if isComment(node) {
+			if commentStart == 0 {
+				commentStart = start
+			}
+			// Comments separated from what follows by a blank line stand alone
+			next := node.NextNamedSibling()
+			if next == nil || int(next.StartPoint().Row)+1 > end+1 {
+				spans = append(spans, span{start: commentStart, end: end})
+				commentStart = 0
+			}
+			continue
+		}
+
+		decl := declarationNode(node)
+		sp := span{start: start, end: end, kind: g.kinds[decl.Type()]}
+		if commentStart != 0 {
+			sp.start = commentStart
+			commentStart = 0
+		}
+		if sp.kind != "" {
+			sp.symbol = declarationName(decl, src)
+			if prefix != "" && sp.symbol != "" {
+				sp.symbol = prefix + "." + sp.symbol
+			}
+		}
+
+		if sp.end-sp.start+1 > maxChunkLines && g.containers[decl.Type()] {
+			if members := g.memberSpans(decl, src, sp); len(members) > 0 {
+				spans = append(spans, members...)
+				continue
+			}
+		}
+		spans = append(spans, sp)
+	}
+	return spans
+}
+
+// memberSpans chunks a large container (class, impl, ...) member by member.
+// The header up to the first member and the closing lines after the last
+// one become spans of their own so nothing is dropped.
+func (g grammar) memberSpans(decl *sitter.Node, src []byte, outer span) []span {
+	body := decl.ChildByFieldName("body")
+	if body == nil {
+		return nil
+	}
+	name := outer.symbol
+	if name == "" {
+		name = declarationName(decl, src)
+	}
+	members := g.spans(body, src, name)
+	if len(members) == 0 {
+		return nil
+	}
+
+	var spans []span
+	if first := members[0].start; first > outer.start {
+		spans = append(spans, span{start: outer.start, end: first - 1, symbol: outer.symbol, kind: outer.kind})
+	}
+	spans = append(spans, members...)
+	if last := members[len(members)-1].end; last < outer.end {
+		spans = append(spans, span{start: last + 1, end: outer.end})
+	}
+	return spans
+}
+
+// nodeLines returns a node's 1-based inclusive line range
+func nodeLines(node *sitter.Node) (int, int) {
+	start := int(node.StartPoint().Row) + 1
+	endPoint := node.EndPoint()
+	end := int(endPoint.Row) + 1
+	// A node ending at column 0 stops before that line starts
+	if endPoint.Column == 0 && end > start {
+		end--
+	}
+	return start, end
+}
+
+func isComment(node *sitter.Node) bool {
+	return strings.Contains(node.Type(), "comment")
+}
+
+// declarationNode unwraps export statements, decorators and templates to
+// the declaration they carry.
+func declarationNode(node *sitter.Node) *sitter.Node {
+	for _, field := range []string{"declaration", "definition"} {
+		if inner := node.ChildByFieldName(field); inner != nil {
+			return declarationNode(inner)
+		}
+	}
+	if node.Type() == "template_declaration" {
+		for i := int(node.NamedChildCount()) - 1; i >= 0; i-- {
+			if child := node.NamedChild(i); child.Type() != "template_parameter_list" {
+				return child
+			}
+		}
+	}
+	return node
+}
+
+// declarationName extracts the declared name(s) of a declaration node
+func declarationName(node *sitter.Node, src []byte) string {
+	switch node.Type() {
+	case "method_declaration":
+		// Go methods are named after their receiver type
+		if recv := node.ChildByFieldName("receiver"); recv != nil {
+			name := node.ChildByFieldName("name")
+			if name != nil {
+				return receiverType(recv, src) + "." + name.Content(src)
+			}
+		}
+	case "impl_item":
+		if typ := node.ChildByFieldName("type"); typ != nil {
+			name := typ.Content(src)
+			if trait := node.ChildByFieldName("trait"); trait != nil {
+				name = trait.Content(src) + " for " + name
+			}
+			return name
+		}
+	}
+
+	if name := node.ChildByFieldName("name"); name != nil {
+		return name.Content(src)
+	}
+	if declarator := node.ChildByFieldName("declarator"); declarator != nil {
+		return declaratorName(declarator, src)
+	}
+
+	// Grouped declarations (Go type/const/var blocks, JS let/const lists,
+	// Java fields) name each of their specs
+	var names []string
+	collectSpecNames(node, src, 2, &names)
+	return strings.Join(names, ", ")
+}
+
+// collectSpecNames gathers the names of specs nested up to depth levels down
+func collectSpecNames(node *sitter.Node, src []byte, depth int, names *[]string) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if name := child.ChildByFieldName("name"); name != nil {
+			*names = append(*names, name.Content(src))
+		} else if declarator := child.ChildByFieldName("declarator"); declarator != nil {
+			*names = append(*names, declaratorName(declarator, src))
+		} else if depth > 1 {
+			collectSpecNames(child, src, depth-1, names)
+		}
+	}
+}
+
+// declaratorName digs the identifier out of a C-style declarator
+// (pointer, function and array declarators wrap it)
+func declaratorName(node *sitter.Node, src []byte) string {
+	for {
+		inner := node.ChildByFieldName("declarator")
+		if inner == nil {
+			break
+		}
+		node = inner
+	}
+	if name := node.ChildByFieldName("name"); name != nil {
+		return name.Content(src)
+	}
+	return node.Content(src)
+}
+
+// receiverType returns the bare type name of a Go method receiver
+func receiverType(recv *sitter.Node, src []byte) string {
+	text := strings.Trim(recv.Content(src), "()")
+	if fields := strings.Fields(text); len(fields) > 0 {
+		text = fields[len(fields)-1]
+	}
+	text = strings.TrimLeft(text, "*")
+	if i := strings.Index(text, "["); i >= 0 {
+		text = text[:i]
+	}
+	return text
+}
+
+func mergeKinds(base, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(extra))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
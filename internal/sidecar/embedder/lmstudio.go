@@ -141,4 +141,16 @@ func (e *LMStudioEmbedder) Dimension() int {
 // SetModel sets the embedding model to use
 func (e *LMStudioEmbedder) SetModel(model string) {
 	e.model = model
-}
\ No newline at end of file
+}
+// DetectDimension embeds a probe text to learn the loaded model's size;
+// LM Studio's API doesn't report it
+func (e *LMStudioEmbedder) DetectDimension(ctx context.Context) (int, error) {
+	if e.dimension != -1 {
+		return e.dimension, nil
+	}
+	embedding, err := e.Embed(ctx, "test")
+	if err != nil {
+		return 0, err
+	}
+	return len(embedding), nil
+}
@@ -59,6 +59,11 @@ func (m *MockEmbedder) Dimension() int {
 	return m.dimension
 }
 
+// DetectDimension returns the fixed mock dimension
+func (m *MockEmbedder) DetectDimension(ctx context.Context) (int, error) {
+	return m.dimension, nil
+}
+
 // HashToFloat32 converts part of a hash to float32
 func hashToFloat32(hash []byte, offset int) float32 {
 	if offset+4 > len(hash) {
@@ -0,0 +1,154 @@
+package embedder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OllamaEmbedder uses Ollama's native embeddings endpoint
+type OllamaEmbedder struct {
+	baseURL   string
+	client    *http.Client
+	dimension int
+	model     string
+}
+
+// NewOllamaEmbedder creates a new Ollama embedder
+// Note: the model must be pulled first (ollama pull nomic-embed-text)
+func NewOllamaEmbedder(baseURL string) *OllamaEmbedder {
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+	return &OllamaEmbedder{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		client:    &http.Client{},
+		dimension: -1, // Set by DetectDimension or the first embedding
+		model:     "nomic-embed-text",
+	}
+}
+
+// ollamaEmbedRequest is the body of POST /api/embed
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// ollamaEmbedResponse is the reply from POST /api/embed
+type ollamaEmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// Embed generates embedding for a single text using Ollama
+func (e *OllamaEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// EmbedBatch generates embeddings for multiple texts in one request
+func (e *OllamaEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	var embResp ollamaEmbedResponse
+	if err := e.post(ctx, "/api/embed", ollamaEmbedRequest{Model: e.model, Input: texts}, &embResp); err != nil {
+		return nil, err
+	}
+	if len(embResp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("Ollama returned %d embeddings for %d texts", len(embResp.Embeddings), len(texts))
+	}
+
+	if e.dimension == -1 {
+		e.dimension = len(embResp.Embeddings[0])
+	}
+	return embResp.Embeddings, nil
+}
+
+// DetectDimension asks Ollama for the model's embedding length, falling
+// back to embedding a probe text for models that don't report it
+func (e *OllamaEmbedder) DetectDimension(ctx context.Context) (int, error) {
+	if e.dimension != -1 {
+		return e.dimension, nil
+	}
+
+	var show struct {
+		ModelInfo map[string]any `json:"model_info"`
+	}
+	if err := e.post(ctx, "/api/show", map[string]string{"model": e.model}, &show); err == nil {
+		// Keys are prefixed with the architecture, e.g. nomic-bert.embedding_length
+		for key, value := range show.ModelInfo {
+			if length, ok := value.(float64); ok && strings.HasSuffix(key, ".embedding_length") {
+				e.dimension = int(length)
+				return e.dimension, nil
+			}
+		}
+	}
+
+	embedding, err := e.Embed(ctx, "test")
+	if err != nil {
+		return 0, err
+	}
+	return len(embedding), nil
+}
+
+// Dimension returns the embedding dimension
+func (e *OllamaEmbedder) Dimension() int {
+	if e.dimension == -1 {
+		return 768 // nomic-embed-text, the default model
+	}
+	return e.dimension
+}
+
+// SetModel sets the embedding model to use
+func (e *OllamaEmbedder) SetModel(model string) {
+	if model != e.model {
+		e.model = model
+		e.dimension = -1
+	}
+}
+
+// post sends a JSON request to Ollama and decodes the reply into out
+func (e *OllamaEmbedder) post(ctx context.Context, path string, body any, out any) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.baseURL+path, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResp struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil && errorResp.Error != "" {
+			if resp.StatusCode == http.StatusNotFound {
+				return fmt.Errorf("embedding model %s not found in Ollama (run: ollama pull %s): %s", e.model, e.model, errorResp.Error)
+			}
+			return fmt.Errorf("Ollama error: %s", errorResp.Error)
+		}
+		return fmt.Errorf("Ollama returned status %d for %s", resp.StatusCode, path)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
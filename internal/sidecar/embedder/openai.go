@@ -0,0 +1,167 @@
+package embedder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// openAIDimensions lists the embedding sizes of OpenAI's hosted models, so
+// they can be known without spending a request
+var openAIDimensions = map[string]int{
+	"text-embedding-3-small": 1536,
+	"text-embedding-3-large": 3072,
+	"text-embedding-ada-002": 1536,
+}
+
+// OpenAIEmbedder uses an OpenAI-compatible embeddings endpoint: OpenAI
+// itself, or servers such as vLLM, llama.cpp and LocalAI
+type OpenAIEmbedder struct {
+	baseURL   string
+	apiKey    string
+	client    *http.Client
+	dimension int
+	model     string
+}
+
+// NewOpenAIEmbedder creates a new OpenAI-compatible embedder. An empty
+// apiKey falls back to the OPENAI_API_KEY environment variable; local
+// servers usually need none.
+func NewOpenAIEmbedder(baseURL, apiKey string) *OpenAIEmbedder {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com"
+	}
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENAI_API_KEY")
+	}
+	// Accept base URLs with or without the /v1 suffix
+	baseURL = strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v1")
+	return &OpenAIEmbedder{
+		baseURL:   baseURL,
+		apiKey:    apiKey,
+		client:    &http.Client{},
+		dimension: -1, // Set by DetectDimension or the first embedding
+		model:     "text-embedding-3-small",
+	}
+}
+
+// batchEmbeddingRequest is embeddingRequest with several inputs
+type batchEmbeddingRequest struct {
+	Input []string `json:"input"`
+	Model string   `json:"model,omitempty"`
+}
+
+// Embed generates embedding for a single text
+func (e *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// EmbedBatch generates embeddings for multiple texts in one request
+func (e *OpenAIEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	jsonData, err := json.Marshal(batchEmbeddingRequest{Input: texts, Model: e.model})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.baseURL+"/v1/embeddings", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", e.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResp struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil && errorResp.Error.Message != "" {
+			return nil, fmt.Errorf("embeddings error: %s", errorResp.Error.Message)
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("embeddings request unauthorized - set analysis.rag.embedder_api_key or OPENAI_API_KEY")
+		}
+		return nil, fmt.Errorf("embeddings endpoint returned status %d for model %s", resp.StatusCode, e.model)
+	}
+
+	var embResp embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(embResp.Data) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d texts", len(embResp.Data), len(texts))
+	}
+
+	// Results carry their input index and aren't guaranteed to be in order
+	sort.Slice(embResp.Data, func(i, j int) bool {
+		return embResp.Data[i].Index < embResp.Data[j].Index
+	})
+	embeddings := make([][]float32, len(embResp.Data))
+	for i, data := range embResp.Data {
+		embeddings[i] = data.Embedding
+	}
+
+	if e.dimension == -1 {
+		e.dimension = len(embeddings[0])
+	}
+	return embeddings, nil
+}
+
+// DetectDimension returns the embedding size, from the table of OpenAI
+// models when possible and by embedding a probe text otherwise
+func (e *OpenAIEmbedder) DetectDimension(ctx context.Context) (int, error) {
+	if e.dimension != -1 {
+		return e.dimension, nil
+	}
+	if dimension, ok := openAIDimensions[e.model]; ok {
+		e.dimension = dimension
+		return dimension, nil
+	}
+
+	embedding, err := e.Embed(ctx, "test")
+	if err != nil {
+		return 0, err
+	}
+	return len(embedding), nil
+}
+
+// Dimension returns the embedding dimension
+func (e *OpenAIEmbedder) Dimension() int {
+	if e.dimension != -1 {
+		return e.dimension
+	}
+	if dimension, ok := openAIDimensions[e.model]; ok {
+		return dimension
+	}
+	return 1536 // Most common for OpenAI-style models
+}
+
+// SetModel sets the embedding model to use
+func (e *OpenAIEmbedder) SetModel(model string) {
+	if model != e.model {
+		e.model = model
+		e.dimension = -1
+	}
+}
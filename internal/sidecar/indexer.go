@@ -0,0 +1,193 @@
+package sidecar
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/crash"
+)
+
+// indexer re-embeds changed files in the background. Watcher events only
+// queue paths, so a burst of saves to the same file is indexed once, and
+// progress is reported while the queue drains.
+type indexer struct {
+	svc *service
+
+	mu       sync.Mutex
+	pending  []string        // Paths in arrival order
+	queued   map[string]bool // Paths in pending
+	progress IndexProgress   // Current burst of changes
+	onUpdate func(IndexProgress)
+
+	wake chan struct{}
+}
+
+func newIndexer(svc *service) *indexer {
+	return &indexer{
+		svc:    svc,
+		queued: make(map[string]bool),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// setProgressHandler sets the callback that receives progress updates
+func (ix *indexer) setProgressHandler(handler func(IndexProgress)) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.onUpdate = handler
+}
+
+// enqueue schedules paths for re-indexing. Paths already waiting are not
+// added twice.
+func (ix *indexer) enqueue(paths []string) {
+	ix.mu.Lock()
+	if ix.progress.Done {
+		ix.progress = IndexProgress{} // Start a new burst
+	}
+	added := 0
+	for _, path := range paths {
+		if ix.queued[path] {
+			continue
+		}
+		ix.queued[path] = true
+		ix.pending = append(ix.pending, path)
+		added++
+	}
+	ix.progress.Total += added
+	update := ix.snapshot()
+	ix.mu.Unlock()
+
+	if added == 0 {
+		return
+	}
+	ix.report(update)
+
+	select {
+	case ix.wake <- struct{}{}:
+	default: // Already signalled
+	}
+}
+
+// run processes queued paths until stop is closed
+func (ix *indexer) run(stop <-chan struct{}) {
+	defer crash.Recover("RAG indexer")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ix.wake:
+		}
+
+		for {
+			path, ok := ix.next()
+			if !ok {
+				break
+			}
+			err := ix.indexPath(ctx, path)
+			if ctx.Err() != nil {
+				return
+			}
+			ix.finish(err)
+		}
+	}
+}
+
+// next pops the oldest pending path and marks it as current
+func (ix *indexer) next() (string, bool) {
+	ix.mu.Lock()
+	if len(ix.pending) == 0 {
+		ix.progress.CurrentFile = ""
+		ix.progress.Done = ix.progress.Total > 0
+		update := ix.snapshot()
+		ix.mu.Unlock()
+		if update.Done {
+			ix.report(update)
+		}
+		return "", false
+	}
+
+	path := ix.pending[0]
+	ix.pending = ix.pending[1:]
+	delete(ix.queued, path)
+	ix.progress.CurrentFile = ix.svc.relativePath(path)
+	update := ix.snapshot()
+	ix.mu.Unlock()
+
+	ix.report(update)
+	return path, true
+}
+
+// finish records the outcome of the current path
+func (ix *indexer) finish(err error) {
+	ix.mu.Lock()
+	ix.progress.Completed++
+	if err != nil {
+		ix.progress.Failed++
+	}
+	ix.mu.Unlock()
+}
+
+// indexPath re-embeds a file, or drops its chunks if it no longer exists
+func (ix *indexer) indexPath(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := ix.svc.vectorStore.Delete(ctx, path); err != nil {
+			return err
+		}
+		ix.svc.deleteFileState(ctx, path)
+		return nil
+	}
+
+	err := ix.svc.UpdateFile(ctx, path)
+	state := FileState{
+		Hash:      FileHash(path),
+		IndexedAt: time.Now(),
+		Success:   err == nil,
+	}
+	if err != nil {
+		state.Error = err.Error()
+	}
+	_ = ix.svc.SetFileState(ctx, path, state) // Stores without metadata support just skip this
+	return err
+}
+
+// snapshot copies the progress; callers hold ix.mu
+func (ix *indexer) snapshot() IndexProgress {
+	update := ix.progress
+	update.Pending = len(ix.pending)
+	return update
+}
+
+func (ix *indexer) report(update IndexProgress) {
+	ix.mu.Lock()
+	handler := ix.onUpdate
+	ix.mu.Unlock()
+	if handler != nil {
+		handler(update)
+	}
+}
+
+// FileHash fingerprints a file by path, size and modification time. This
+// is what FileState.Hash holds, so callers can tell when a file changed
+// since it was indexed.
+func FileHash(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+
+	h := sha256.New()
+	h.Write([]byte(path))
+	h.Write([]byte(fmt.Sprintf("%d%d", info.Size(), info.ModTime().Unix())))
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
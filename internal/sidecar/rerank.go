@@ -0,0 +1,146 @@
+package sidecar
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/llm/queue"
+)
+
+// rerankPreviewChars is how much of each candidate the model sees; enough
+// to recognise the code without overflowing a small model's context
+const rerankPreviewChars = 300
+
+// candidateNumber matches the candidate numbers in the model's reply
+var candidateNumber = regexp.MustCompile(`\d+`)
+
+// LLMReranker asks a (small) chat model to reorder retrieval candidates.
+// Requests go through the LLM queue so they share LM Studio fairly with
+// everything else.
+type LLMReranker struct {
+	client  llm.Client
+	queue   *queue.Manager // nil calls the client directly
+	timeout time.Duration
+}
+
+// NewLLMReranker creates a reranker backed by client. A zero timeout
+// leaves the deadline to the caller's context.
+func NewLLMReranker(client llm.Client, q *queue.Manager, timeout time.Duration) *LLMReranker {
+	return &LLMReranker{
+		client:  client,
+		queue:   q,
+		timeout: timeout,
+	}
+}
+
+// Rerank returns the k candidates the model judges most relevant to
+// query, best first. Candidates the model leaves out keep their retrieval
+// order behind the ones it picked.
+func (r *LLMReranker) Rerank(ctx context.Context, query string, candidates []SimilarDocument, k int) ([]SimilarDocument, error) {
+	if len(candidates) <= 1 {
+		return candidates, nil
+	}
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	messages := []llm.Message{
+		{
+			Role: "system",
+			Content: "You rank code search results. Reply with only the numbers of the passages " +
+				"that help answer the query, most relevant first, separated by commas. No explanations.",
+		},
+		{Role: "user", Content: rerankPrompt(query, candidates, k)},
+	}
+
+	reply, err := r.complete(ctx, messages)
+	if err != nil {
+		return nil, fmt.Errorf("rerank request failed: %w", err)
+	}
+
+	return applyRanking(reply, candidates, k), nil
+}
+
+// complete runs the request on the queue and waits for it
+func (r *LLMReranker) complete(ctx context.Context, messages []llm.Message) (string, error) {
+	if r.queue == nil {
+		return r.client.Complete(ctx, messages)
+	}
+
+	var reply string
+	var err error
+	done := make(chan struct{})
+	r.queue.Submit(ctx, func(ctx context.Context) error {
+		defer close(done)
+		reply, err = r.client.Complete(ctx, messages)
+		return err
+	}, queue.WithPriority(10), queue.WithType("rerank"))
+
+	select {
+	case <-done:
+		return reply, err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// rerankPrompt lists the numbered candidates under the query
+func rerankPrompt(query string, candidates []SimilarDocument, k int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Query: %s\n\n", query)
+	for i, doc := range candidates {
+		fmt.Fprintf(&b, "[%d] %s", i+1, doc.Path)
+		if start, ok := doc.Metadata["start_line"].(int); ok {
+			fmt.Fprintf(&b, ":%d", start)
+		}
+		if symbol, ok := doc.Metadata["symbol"].(string); ok && symbol != "" {
+			fmt.Fprintf(&b, " (%s)", symbol)
+		}
+		preview := doc.Content
+		if len(preview) > rerankPreviewChars {
+			preview = preview[:rerankPreviewChars] + "..."
+		}
+		fmt.Fprintf(&b, "\n%s\n\n", preview)
+	}
+	fmt.Fprintf(&b, "List up to %d passage numbers, most relevant first.", k)
+	return b.String()
+}
+
+// applyRanking orders candidates by the numbers in reply, filling up to k
+// from the original order. Unknown or repeated numbers are ignored.
+func applyRanking(reply string, candidates []SimilarDocument, k int) []SimilarDocument {
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+
+	picked := make([]bool, len(candidates))
+	ranked := make([]SimilarDocument, 0, k)
+	for _, match := range candidateNumber.FindAllString(reply, -1) {
+		if len(ranked) == k {
+			break
+		}
+		n, err := strconv.Atoi(match)
+		if err != nil || n < 1 || n > len(candidates) || picked[n-1] {
+			continue
+		}
+		picked[n-1] = true
+		ranked = append(ranked, candidates[n-1])
+	}
+
+	for i, doc := range candidates {
+		if len(ranked) == k {
+			break
+		}
+		if !picked[i] {
+			ranked = append(ranked, doc)
+		}
+	}
+	return ranked
+}
@@ -2,14 +2,15 @@ package sidecar
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/billie-coop/loco/internal/crash"
 	"github.com/billie-coop/loco/internal/files"
 )
 
@@ -43,7 +44,11 @@ type service struct {
 	// File watching
 	fileWatcher FileWatcher
 	autoIndexOnChange bool
-	toolExecutor ToolExecutor // For triggering auto-indexing via tool system
+	indexer          *indexer // Re-indexes changed files in the background
+	
+	// Optional second pass over retrieval results
+	reranker         Reranker
+	rerankCandidates int
 	
 	mu          sync.RWMutex
 	processing  map[string]bool // Track files being processed
@@ -54,18 +59,20 @@ type service struct {
 
 // NewService creates a new sidecar service.
 func NewService(workingDir string, embedder Embedder, store VectorStore) Service {
-	return &service{
+	s := &service{
 		workingDir:  workingDir,
 		embedder:    embedder,
 		vectorStore: store,
 		processing:  make(map[string]bool),
 		stopCh:      make(chan struct{}),
 	}
+	s.indexer = newIndexer(s)
+	return s
 }
 
 // NewServiceWithWatcher creates a new sidecar service with file watching.
 func NewServiceWithWatcher(workingDir string, embedder Embedder, store VectorStore, watcher FileWatcher, autoIndexOnChange bool) Service {
-	return &service{
+	s := &service{
 		workingDir:        workingDir,
 		embedder:          embedder,
 		vectorStore:       store,
@@ -74,11 +81,22 @@ func NewServiceWithWatcher(workingDir string, embedder Embedder, store VectorSto
 		processing:        make(map[string]bool),
 		stopCh:            make(chan struct{}),
 	}
+	s.indexer = newIndexer(s)
+	return s
 }
 
-// SetToolExecutor sets the tool executor for auto-indexing
-func (s *service) SetToolExecutor(executor ToolExecutor) {
-	s.toolExecutor = executor
+// SetProgressHandler receives the background indexer's progress
+func (s *service) SetProgressHandler(handler func(IndexProgress)) {
+	s.indexer.setProgressHandler(handler)
+}
+
+// SetReranker reorders query results with reranker, drawing from the top
+// candidates results of retrieval. A nil reranker disables the stage.
+func (s *service) SetReranker(reranker Reranker, candidates int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reranker = reranker
+	s.rerankCandidates = candidates
 }
 
 // UpdateFile processes and stores embeddings for a file.
@@ -120,7 +138,7 @@ func (s *service) UpdateFile(ctx context.Context, path string) error {
 	// Generate embeddings for each chunk
 	docs := make([]Document, 0, len(chunks))
 	for i, chunk := range chunks {
-		embedding, err := s.embedder.Embed(ctx, chunk.content)
+		embedding, err := s.embedder.Embed(ctx, chunk.embedText(s.relativePath(path)))
 		if err != nil {
 			return fmt.Errorf("failed to embed chunk %d: %w", i, err)
 		}
@@ -138,6 +156,10 @@ func (s *service) UpdateFile(ctx context.Context, path string) error {
 			},
 			UpdatedAt: time.Now(),
 		}
+		if chunk.symbol != "" {
+			doc.Metadata["symbol"] = chunk.symbol
+			doc.Metadata["kind"] = chunk.kind
+		}
 		docs = append(docs, doc)
 	}
 	
@@ -160,6 +182,7 @@ func (s *service) UpdateFiles(ctx context.Context, paths []string) error {
 	for _, path := range paths {
 		wg.Add(1)
 		go func(p string) {
+			defer crash.Recover("indexing")
 			defer wg.Done()
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
@@ -192,7 +215,28 @@ func (s *service) UpdateFiles(ctx context.Context, paths []string) error {
 
 // QuerySimilar finds similar documents to a query.
 func (s *service) QuerySimilar(ctx context.Context, query string, k int) ([]SimilarDocument, error) {
-	return s.vectorStore.QueryText(ctx, query, k)
+	s.mu.RLock()
+	reranker, candidates := s.reranker, s.rerankCandidates
+	s.mu.RUnlock()
+
+	if reranker == nil {
+		return s.vectorStore.QueryText(ctx, query, k)
+	}
+
+	if candidates < k {
+		candidates = k
+	}
+	results, err := s.vectorStore.QueryText(ctx, query, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	reranked, err := reranker.Rerank(ctx, query, results, k)
+	if err != nil {
+		// Reranking is a refinement; fall back to retrieval order
+		return results[:min(k, len(results))], nil
+	}
+	return reranked, nil
 }
 
 // Start begins watching for file changes.
@@ -206,6 +250,7 @@ func (s *service) Start(ctx context.Context) error {
 	
 	// Subscribe to file change events if watcher is available and auto-indexing is enabled
 	if s.fileWatcher != nil && s.autoIndexOnChange {
+		go s.indexer.run(s.stopCh)
 		s.fileWatcher.Subscribe(s.onFileChange)
 	}
 	
@@ -227,39 +272,8 @@ func (s *service) onFileChange(event FileChangeEvent) {
 		return
 	}
 	
-	// Use tool executor if available, otherwise fallback to direct indexing
-	if s.toolExecutor != nil {
-		// Create JSON input with the list of changed files
-		pathsJSON := make([]string, len(indexablePaths))
-		for i, path := range indexablePaths {
-			// Convert to relative paths for display
-			if relPath := strings.TrimPrefix(path, s.workingDir+"/"); relPath != path {
-				pathsJSON[i] = relPath
-			} else {
-				pathsJSON[i] = path
-			}
-		}
-		
-		input := fmt.Sprintf(`{"trigger": "file-watch", "changed_files": %s}`, toJSON(pathsJSON))
-		
-		// Trigger RAG indexing via tool system immediately - the tool itself will handle any debouncing
-		// This ensures the UI feels snappy while still batching rapid changes at the tool level
-		s.toolExecutor.ExecuteFileWatch(ToolCall{
-			Name:  "rag_index", 
-			Input: input,
-		})
-	} else {
-		// Fallback to direct indexing for backward compatibility
-		go func() {
-			ctx := context.Background()
-			for _, path := range indexablePaths {
-				if err := s.UpdateFile(ctx, path); err != nil {
-					// Fail silently to avoid UI spam
-					_ = err
-				}
-			}
-		}()
-	}
+	// Re-embed just these files in the background
+	s.indexer.enqueue(indexablePaths)
 }
 
 // Stop stops watching and cleanup.
@@ -274,94 +288,60 @@ func (s *service) Stop() error {
 	
 	close(s.stopCh)
 	
+	// Let the store flush and release its resources
+	if closer, ok := s.vectorStore.(io.Closer); ok {
+		return closer.Close()
+	}
 	return nil
 }
 
 // SetRAGMetadata stores RAG metadata using the vector store
 func (s *service) SetRAGMetadata(ctx context.Context, metadata RAGMetadata) error {
-	// Cast to SQLite store to access metadata methods
-	if sqliteStore, ok := s.vectorStore.(interface {
-		SetRAGMetadata(ctx context.Context, metadata RAGMetadata) error
-	}); ok {
-		return sqliteStore.SetRAGMetadata(ctx, metadata)
+	if store, ok := s.vectorStore.(MetadataStore); ok {
+		return store.SetRAGMetadata(ctx, metadata)
 	}
 	return fmt.Errorf("vector store does not support metadata operations")
 }
 
 // GetRAGMetadata retrieves RAG metadata using the vector store
 func (s *service) GetRAGMetadata(ctx context.Context) (*RAGMetadata, error) {
-	// Cast to SQLite store to access metadata methods
-	if sqliteStore, ok := s.vectorStore.(interface {
-		GetRAGMetadata(ctx context.Context) (*RAGMetadata, error)
-	}); ok {
-		return sqliteStore.GetRAGMetadata(ctx)
+	if store, ok := s.vectorStore.(MetadataStore); ok {
+		return store.GetRAGMetadata(ctx)
 	}
 	return nil, fmt.Errorf("vector store does not support metadata operations")
 }
 
 // SetFileState stores file state using the vector store
 func (s *service) SetFileState(ctx context.Context, path string, state FileState) error {
-	// Cast to SQLite store to access metadata methods
-	if sqliteStore, ok := s.vectorStore.(interface {
-		SetFileState(ctx context.Context, path string, state FileState) error
-	}); ok {
-		return sqliteStore.SetFileState(ctx, path, state)
+	if store, ok := s.vectorStore.(MetadataStore); ok {
+		return store.SetFileState(ctx, path, state)
 	}
 	return fmt.Errorf("vector store does not support metadata operations")
 }
 
 // GetFileStates retrieves all file states using the vector store
 func (s *service) GetFileStates(ctx context.Context) (map[string]FileState, error) {
-	// Cast to SQLite store to access metadata methods
-	if sqliteStore, ok := s.vectorStore.(interface {
-		GetFileStates(ctx context.Context) (map[string]FileState, error)
-	}); ok {
-		return sqliteStore.GetFileStates(ctx)
+	if store, ok := s.vectorStore.(MetadataStore); ok {
+		return store.GetFileStates(ctx)
 	}
 	return nil, fmt.Errorf("vector store does not support metadata operations")
 }
 
-// chunk represents a file chunk.
-type chunk struct {
-	content   string
-	startLine int
-	endLine   int
+// deleteFileState forgets a removed file's indexing state, if supported
+func (s *service) deleteFileState(ctx context.Context, path string) {
+	if store, ok := s.vectorStore.(MetadataStore); ok {
+		_ = store.DeleteFileState(ctx, path)
+	}
 }
 
-// chunkFile splits a file into chunks for embedding.
-func (s *service) chunkFile(path string, content string) []chunk {
-	// Simple line-based chunking for now
-	// TODO: Implement AST-based chunking for code files
-	
-	lines := strings.Split(content, "\n")
-	chunkSize := 30  // Lines per chunk
-	overlap := 5     // Overlapping lines
-	
-	var chunks []chunk
-	for i := 0; i < len(lines); i += (chunkSize - overlap) {
-		end := i + chunkSize
-		if end > len(lines) {
-			end = len(lines)
-		}
-		
-		chunkContent := strings.Join(lines[i:end], "\n")
-		if strings.TrimSpace(chunkContent) != "" {
-			chunks = append(chunks, chunk{
-				content:   chunkContent,
-				startLine: i + 1,
-				endLine:   end,
-			})
-		}
-		
-		if end >= len(lines) {
-			break
-		}
+// Prune drops chunks for files that left the project and compacts the store
+func (s *service) Prune(ctx context.Context) (*PruneResult, error) {
+	if store, ok := s.vectorStore.(Pruner); ok {
+		return store.Prune(ctx)
 	}
-	
-	return chunks
+	return nil, fmt.Errorf("vector store does not support pruning")
 }
 
-
 // isBinary checks if content appears to be binary.
 func isBinary(content []byte) bool {
 	if len(content) == 0 {
@@ -416,12 +396,3 @@ func min(a, b int) int {
 	}
 	return b
 }
-
-// toJSON converts a value to JSON string
-func toJSON(v interface{}) string {
-	data, err := json.Marshal(v)
-	if err != nil {
-		return "[]" // Fallback to empty array
-	}
-	return string(data)
-}
\ No newline at end of file
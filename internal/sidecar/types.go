@@ -32,7 +32,16 @@ type Embedder interface {
 	Dimension() int
 }
 
+// DimensionDetector is implemented by embedders that can find out their
+// embedding size up front, asking the backend when it can tell them.
+// Stores that fix the size at creation use it before the first embedding.
+type DimensionDetector interface {
+	DetectDimension(ctx context.Context) (int, error)
+}
+
 // VectorStore manages vector embeddings and similarity search.
+// Implementations live in the vectordb package; stores may also implement
+// MetadataStore, Pruner and io.Closer, which the service uses when present.
 type VectorStore interface {
 	// Store saves a document with its embedding
 	Store(ctx context.Context, doc Document) error
@@ -56,6 +65,28 @@ type VectorStore interface {
 	Count(ctx context.Context) (int, error)
 }
 
+// MetadataStore is implemented by vector stores that also track what has
+// been indexed, so unchanged files can be skipped.
+type MetadataStore interface {
+	SetRAGMetadata(ctx context.Context, metadata RAGMetadata) error
+	GetRAGMetadata(ctx context.Context) (*RAGMetadata, error)
+	SetFileState(ctx context.Context, path string, state FileState) error
+	GetFileStates(ctx context.Context) (map[string]FileState, error)
+	DeleteFileState(ctx context.Context, path string) error
+}
+
+// Pruner is implemented by vector stores that can drop chunks for files no
+// longer in the project and reclaim the space.
+type Pruner interface {
+	Prune(ctx context.Context) (*PruneResult, error)
+}
+
+// Reranker reorders retrieval candidates by relevance to a query.
+type Reranker interface {
+	// Rerank returns the k most relevant candidates, best first
+	Rerank(ctx context.Context, query string, candidates []SimilarDocument, k int) ([]SimilarDocument, error)
+}
+
 // RAGMetadata represents the structure matching the JSON file
 type RAGMetadata struct {
 	ContentHash    string                    `json:"content_hash"`
@@ -72,15 +103,30 @@ type FileState struct {
 	Error     string    `json:"error,omitempty"`
 }
 
-// ToolExecutor interface for triggering tool execution
-type ToolExecutor interface {
-	ExecuteFileWatch(call ToolCall)
+// IndexProgress reports the background indexer's work on changed files.
+type IndexProgress struct {
+	Total       int    // Files in the current burst of changes
+	Completed   int    // Files processed so far, including failures
+	Failed      int    // Files that could not be indexed
+	Pending     int    // Files still waiting
+	CurrentFile string // Path being indexed, relative to the project ("" when idle)
+	Done        bool   // True once every queued file has been processed
 }
 
-// ToolCall represents a tool call (simplified interface)
-type ToolCall struct {
-	Name  string `json:"name"`
-	Input string `json:"input"`
+// PruneResult reports what a vector store prune removed.
+type PruneResult struct {
+	RemovedFiles  int   // Files whose chunks were deleted
+	RemovedChunks int   // Chunks deleted across those files
+	BytesBefore   int64 // Database size on disk before pruning
+	BytesAfter    int64 // Database size on disk after vacuuming
+}
+
+// Reclaimed returns the disk space freed by the prune
+func (r PruneResult) Reclaimed() int64 {
+	if r.BytesAfter >= r.BytesBefore {
+		return 0
+	}
+	return r.BytesBefore - r.BytesAfter
 }
 
 // Service provides RAG capabilities.
@@ -100,8 +146,14 @@ type Service interface {
 	// Stop stops watching and cleanup
 	Stop() error
 	
-	// SetToolExecutor sets the tool executor for auto-indexing
-	SetToolExecutor(executor ToolExecutor)
+	// SetProgressHandler receives progress while changed files are re-indexed
+	SetProgressHandler(handler func(IndexProgress))
+	
+	// Prune drops chunks for files no longer in the project and compacts the store
+	Prune(ctx context.Context) (*PruneResult, error)
+	
+	// SetReranker adds a reranking pass over the top candidates results
+	SetReranker(reranker Reranker, candidates int)
 	
 	// RAG metadata methods (replaces JSON file functionality)
 	SetRAGMetadata(ctx context.Context, metadata RAGMetadata) error
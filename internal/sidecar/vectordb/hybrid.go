@@ -0,0 +1,186 @@
+//go:build cgo
+
+package vectordb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+const (
+	deleteKeywordsByID = `DELETE FROM document_fts WHERE doc_id = ?`
+	insertKeywords     = `INSERT INTO document_fts (doc_id, path, symbol, content) VALUES (?, ?, ?, ?)`
+)
+
+// initKeywordIndex creates the FTS5 table next to document_vectors and
+// backfills it from documents indexed before it existed. Identifiers keep
+// their underscores so snake_case names match as a whole.
+func (s *SQLiteStore) initKeywordIndex() error {
+	createFTSTable := `
+	CREATE VIRTUAL TABLE IF NOT EXISTS document_fts USING fts5(
+		doc_id UNINDEXED,
+		path,
+		symbol,
+		content,
+		tokenize = "unicode61 tokenchars '_'"
+	)`
+
+	if _, err := s.db.Exec(createFTSTable); err != nil {
+		if strings.Contains(err.Error(), "no such module") {
+			return nil // FTS5 not compiled in; hybrid search degrades to vectors
+		}
+		return fmt.Errorf("failed to create keyword index: %w", err)
+	}
+	s.hasFTS = true
+
+	var indexed, total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM document_fts").Scan(&indexed); err != nil {
+		return fmt.Errorf("failed to count keyword index: %w", err)
+	}
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM documents").Scan(&total); err != nil {
+		return fmt.Errorf("failed to count documents: %w", err)
+	}
+	if indexed < total {
+		backfill := `
+		INSERT INTO document_fts (doc_id, path, symbol, content)
+		SELECT id, path, '', content FROM documents
+		WHERE id NOT IN (SELECT doc_id FROM document_fts)`
+		if _, err := s.db.Exec(backfill); err != nil {
+			return fmt.Errorf("failed to backfill keyword index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// QueryHybrid finds the k most relevant documents to query text by fusing
+// vector similarity and BM25 keyword ranks with reciprocal-rank fusion, so
+// exact identifiers rank well even when the embedding misses them.
+// Without a keyword index it is equivalent to vector search.
+func (s *SQLiteStore) QueryHybrid(ctx context.Context, query string, k int) ([]sidecar.SimilarDocument, error) {
+	if k <= 0 {
+		return []sidecar.SimilarDocument{}, nil
+	}
+	if s.embedder == nil {
+		return nil, fmt.Errorf("embedder not configured")
+	}
+
+	// Generate embedding for query
+	embedding, err := s.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if !s.hasFTS {
+		return s.Query(ctx, embedding, k)
+	}
+
+	candidates := k * 4
+	if candidates < hybridCandidates {
+		candidates = hybridCandidates
+	}
+
+	vectorResults, err := s.Query(ctx, embedding, candidates)
+	if err != nil {
+		return nil, err
+	}
+	keywordResults, err := s.QueryKeywords(ctx, query, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	return fuseRanks(k, vectorResults, keywordResults), nil
+}
+
+// QueryKeywords finds up to k documents matching any identifier in query,
+// best BM25 match first. Scores are relative to the best match.
+func (s *SQLiteStore) QueryKeywords(ctx context.Context, query string, k int) ([]sidecar.SimilarDocument, error) {
+	match := matchExpression(query)
+	if !s.hasFTS || match == "" || k <= 0 {
+		return []sidecar.SimilarDocument{}, nil
+	}
+
+	// bm25() is lower for better matches; weight symbol names over paths
+	// and paths over body text (columns: doc_id, path, symbol, content)
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT
+		d.id, d.path, d.content, d.updated_at,
+		d.chunk_index, d.start_line, d.end_line, d.language,
+		bm25(document_fts, 0, 2.0, 4.0, 1.0) AS rank
+	FROM document_fts f
+	JOIN documents d ON d.id = f.doc_id
+	WHERE document_fts MATCH ?
+	ORDER BY rank
+	LIMIT ?`, match, k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query keyword index: %w", err)
+	}
+	defer rows.Close()
+
+	var results []sidecar.SimilarDocument
+	var best float64
+	for rows.Next() {
+		var doc sidecar.Document
+		var rank float64
+		var updatedAt int64
+		var chunkIndex, startLine, endLine sql.NullInt64
+		var language sql.NullString
+
+		err := rows.Scan(
+			&doc.ID, &doc.Path, &doc.Content, &updatedAt,
+			&chunkIndex, &startLine, &endLine, &language,
+			&rank,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		doc.Metadata = documentMetadata(chunkIndex, startLine, endLine, language)
+
+		if len(results) == 0 {
+			best = rank
+		}
+		score := float32(1)
+		if best != 0 {
+			score = float32(rank / best)
+		}
+
+		results = append(results, sidecar.SimilarDocument{Document: doc, Score: score})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// matchExpression turns free text into an FTS5 query that matches any of
+// its terms. Each term is quoted so FTS5 syntax in the query is inert.
+func matchExpression(query string) string {
+	terms := queryTerms(query)
+	for i, term := range terms {
+		terms[i] = `"` + term + `"`
+	}
+	return strings.Join(terms, " OR ")
+}
+
+// documentMetadata rebuilds the metadata map from the documents columns
+func documentMetadata(chunkIndex, startLine, endLine sql.NullInt64, language sql.NullString) map[string]interface{} {
+	metadata := make(map[string]interface{})
+	if chunkIndex.Valid {
+		metadata["chunk_index"] = int(chunkIndex.Int64)
+	}
+	if startLine.Valid {
+		metadata["start_line"] = int(startLine.Int64)
+	}
+	if endLine.Valid {
+		metadata["end_line"] = int(endLine.Int64)
+	}
+	if language.Valid {
+		metadata["language"] = language.String
+	}
+	return metadata
+}
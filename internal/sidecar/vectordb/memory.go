@@ -0,0 +1,462 @@
+package vectordb
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+// memoryFlushDelay batches writes: indexing stores one file at a time, and
+// rewriting the whole gob file for each would be quadratic.
+const memoryFlushDelay = 2 * time.Second
+
+// MemoryStore is a pure-Go vector store for builds without cgo. Documents
+// live in memory and are searched by brute force, with a keyword pass fused
+// in the same way as SQLiteStore's hybrid search. Changes are persisted to
+// a gob file shortly after they happen and on Close.
+type MemoryStore struct {
+	embedder sidecar.Embedder
+	path     string // Gob file; "" keeps the store in memory only
+
+	mu         sync.RWMutex
+	docs       map[string]sidecar.Document
+	metadata   *sidecar.RAGMetadata // Without FileStates, which live in fileStates
+	fileStates map[string]sidecar.FileState
+	dirty      bool
+	flushTimer *time.Timer
+}
+
+// memorySnapshot is what a MemoryStore writes to disk
+type memorySnapshot struct {
+	Documents  []sidecar.Document
+	Metadata   *sidecar.RAGMetadata
+	FileStates map[string]sidecar.FileState
+}
+
+// NewMemoryStore creates a memory store persisted at path, loading what a
+// previous run saved there. An empty path disables persistence.
+func NewMemoryStore(path string, embedder sidecar.Embedder) (*MemoryStore, error) {
+	store := &MemoryStore{
+		embedder:   embedder,
+		path:       path,
+		docs:       make(map[string]sidecar.Document),
+		fileStates: make(map[string]sidecar.FileState),
+	}
+
+	if path != "" {
+		if err := ensureDir(filepath.Dir(path)); err != nil {
+			return nil, fmt.Errorf("failed to create store directory: %w", err)
+		}
+		if err := store.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+// Store saves a document with its embedding
+func (m *MemoryStore) Store(ctx context.Context, doc sidecar.Document) error {
+	return m.StoreBatch(ctx, []sidecar.Document{doc})
+}
+
+// StoreBatch saves multiple documents
+func (m *MemoryStore) StoreBatch(ctx context.Context, docs []sidecar.Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, doc := range docs {
+		if doc.UpdatedAt.IsZero() {
+			doc.UpdatedAt = time.Now()
+		}
+		m.docs[doc.ID] = doc
+	}
+	m.markDirty()
+	return nil
+}
+
+// Query finds k most similar documents to query embedding. Scores use the
+// same scale as SQLiteStore (1 = identical, 0 = opposite).
+func (m *MemoryStore) Query(ctx context.Context, embedding []float32, k int) ([]sidecar.SimilarDocument, error) {
+	if k <= 0 {
+		return []sidecar.SimilarDocument{}, nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := make([]sidecar.SimilarDocument, 0, len(m.docs))
+	for _, doc := range m.docs {
+		if len(doc.Embedding) != len(embedding) {
+			continue // Embedded by a different model
+		}
+		similarity := float32((1 + cosine(doc.Embedding, embedding)) / 2)
+		results = append(results, sidecar.SimilarDocument{Document: resultDoc(doc), Score: similarity})
+	}
+
+	return topK(results, k), nil
+}
+
+// QueryText finds k most relevant documents to query text, fusing vector
+// similarity with keyword matches like SQLiteStore.QueryHybrid
+func (m *MemoryStore) QueryText(ctx context.Context, query string, k int) ([]sidecar.SimilarDocument, error) {
+	if k <= 0 {
+		return []sidecar.SimilarDocument{}, nil
+	}
+	if m.embedder == nil {
+		return nil, fmt.Errorf("embedder not configured")
+	}
+
+	embedding, err := m.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	candidates := k * 4
+	if candidates < hybridCandidates {
+		candidates = hybridCandidates
+	}
+
+	vectorResults, err := m.Query(ctx, embedding, candidates)
+	if err != nil {
+		return nil, err
+	}
+	keywordResults := m.QueryKeywords(query, candidates)
+
+	return fuseRanks(k, vectorResults, keywordResults), nil
+}
+
+// QueryKeywords finds up to k documents containing identifiers from query.
+// Matches in symbol names count most, then paths, then body text, the same
+// weighting SQLiteStore gives BM25. Scores are relative to the best match.
+func (m *MemoryStore) QueryKeywords(query string, k int) []sidecar.SimilarDocument {
+	terms := queryTerms(query)
+	if len(terms) == 0 || k <= 0 {
+		return []sidecar.SimilarDocument{}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var results []sidecar.SimilarDocument
+	for _, doc := range m.docs {
+		symbol := strings.ToLower(docSymbol(doc))
+		path := strings.ToLower(doc.Path)
+		content := strings.ToLower(doc.Content)
+
+		var score float64
+		for _, term := range terms {
+			if strings.Contains(symbol, term) {
+				score += 4
+			}
+			if strings.Contains(path, term) {
+				score += 2
+			}
+			if n := strings.Count(content, term); n > 0 {
+				score += 1 + math.Log(float64(n))
+			}
+		}
+		if score > 0 {
+			results = append(results, sidecar.SimilarDocument{Document: resultDoc(doc), Score: float32(score)})
+		}
+	}
+
+	results = topK(results, k)
+	if len(results) > 0 {
+		best := results[0].Score
+		for i := range results {
+			results[i].Score /= best
+		}
+	}
+	return results
+}
+
+// Delete removes documents by path
+func (m *MemoryStore) Delete(ctx context.Context, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := false
+	for id, doc := range m.docs {
+		if doc.Path == path {
+			delete(m.docs, id)
+			removed = true
+		}
+	}
+	if removed {
+		m.markDirty()
+	}
+	return nil
+}
+
+// Clear removes all documents
+func (m *MemoryStore) Clear(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.docs = make(map[string]sidecar.Document)
+	m.markDirty()
+	return nil
+}
+
+// Count returns total number of documents
+func (m *MemoryStore) Count(ctx context.Context) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.docs), nil
+}
+
+// SetRAGMetadata stores the RAG metadata, replacing all file states
+func (m *MemoryStore) SetRAGMetadata(ctx context.Context, metadata sidecar.RAGMetadata) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fileStates = make(map[string]sidecar.FileState, len(metadata.FileStates))
+	for path, state := range metadata.FileStates {
+		m.fileStates[path] = state
+	}
+	metadata.FileStates = nil
+	m.metadata = &metadata
+	m.markDirty()
+	return nil
+}
+
+// GetRAGMetadata retrieves the RAG metadata, or nil if none was stored
+func (m *MemoryStore) GetRAGMetadata(ctx context.Context) (*sidecar.RAGMetadata, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.metadata == nil {
+		return nil, nil
+	}
+	metadata := *m.metadata
+	metadata.FileStates = m.copyFileStates()
+	return &metadata, nil
+}
+
+// SetFileState stores or updates a single file's indexing state
+func (m *MemoryStore) SetFileState(ctx context.Context, path string, state sidecar.FileState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fileStates[path] = state
+	m.markDirty()
+	return nil
+}
+
+// DeleteFileState forgets a single file's indexing state
+func (m *MemoryStore) DeleteFileState(ctx context.Context, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.fileStates[path]; ok {
+		delete(m.fileStates, path)
+		m.markDirty()
+	}
+	return nil
+}
+
+// GetFileStates returns all file states
+func (m *MemoryStore) GetFileStates(ctx context.Context) (map[string]sidecar.FileState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.copyFileStates(), nil
+}
+
+// Prune deletes documents and file states for files that are no longer
+// part of the project (see SQLiteStore.Prune), then rewrites the gob file.
+func (m *MemoryStore) Prune(ctx context.Context) (*sidecar.PruneResult, error) {
+	if m.path == "" {
+		return nil, fmt.Errorf("prune needs a persisted store")
+	}
+	root, projectFiles, err := listProjectFiles(ctx, m.path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Measure what is actually on disk before the rewrite
+	if err := m.Flush(); err != nil {
+		return nil, err
+	}
+	result := &sidecar.PruneResult{BytesBefore: m.diskSize()}
+
+	m.mu.Lock()
+	stale := make(map[string]bool)
+	for id, doc := range m.docs {
+		if stale[doc.Path] || isStale(doc.Path, root, projectFiles) {
+			stale[doc.Path] = true
+			delete(m.docs, id)
+			result.RemovedChunks++
+		}
+	}
+	for path := range m.fileStates {
+		if stale[path] || isStale(path, root, projectFiles) {
+			stale[path] = true
+			delete(m.fileStates, path)
+		}
+	}
+	result.RemovedFiles = len(stale)
+	if len(stale) > 0 {
+		m.markDirty()
+	}
+	m.mu.Unlock()
+
+	if err := m.Flush(); err != nil {
+		return nil, err
+	}
+	result.BytesAfter = m.diskSize()
+
+	return result, nil
+}
+
+// Flush writes pending changes to the gob file
+func (m *MemoryStore) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.flushTimer != nil {
+		m.flushTimer.Stop()
+		m.flushTimer = nil
+	}
+	if !m.dirty || m.path == "" {
+		return nil
+	}
+
+	snapshot := memorySnapshot{
+		Documents:  make([]sidecar.Document, 0, len(m.docs)),
+		Metadata:   m.metadata,
+		FileStates: m.fileStates,
+	}
+	for _, doc := range m.docs {
+		snapshot.Documents = append(snapshot.Documents, doc)
+	}
+
+	// Write to a temporary file first so a crash never leaves half a store
+	tmp := m.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create store file: %w", err)
+	}
+	if err := gob.NewEncoder(file).Encode(snapshot); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to encode store: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return fmt.Errorf("failed to replace store file: %w", err)
+	}
+
+	m.dirty = false
+	return nil
+}
+
+// Close writes any pending changes
+func (m *MemoryStore) Close() error {
+	return m.Flush()
+}
+
+// load reads the gob file, if a previous run wrote one
+func (m *MemoryStore) load() error {
+	file, err := os.Open(m.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open store file: %w", err)
+	}
+	defer file.Close()
+
+	var snapshot memorySnapshot
+	if err := gob.NewDecoder(file).Decode(&snapshot); err != nil {
+		return fmt.Errorf("failed to decode store file %s: %w", m.path, err)
+	}
+
+	for _, doc := range snapshot.Documents {
+		m.docs[doc.ID] = doc
+	}
+	m.metadata = snapshot.Metadata
+	for path, state := range snapshot.FileStates {
+		m.fileStates[path] = state
+	}
+	return nil
+}
+
+// markDirty schedules a flush; callers hold m.mu
+func (m *MemoryStore) markDirty() {
+	m.dirty = true
+	if m.path != "" && m.flushTimer == nil {
+		m.flushTimer = time.AfterFunc(memoryFlushDelay, func() {
+			_ = m.Flush()
+		})
+	}
+}
+
+// copyFileStates copies the file states; callers hold m.mu
+func (m *MemoryStore) copyFileStates() map[string]sidecar.FileState {
+	states := make(map[string]sidecar.FileState, len(m.fileStates))
+	for path, state := range m.fileStates {
+		states[path] = state
+	}
+	return states
+}
+
+// diskSize returns the size of the gob file
+func (m *MemoryStore) diskSize() int64 {
+	if info, err := os.Stat(m.path); err == nil {
+		return info.Size()
+	}
+	return 0
+}
+
+// resultDoc copies a document for a query result, leaving out the
+// embedding as SQLiteStore does
+func resultDoc(doc sidecar.Document) sidecar.Document {
+	metadata := make(map[string]interface{}, len(doc.Metadata))
+	for key, value := range doc.Metadata {
+		metadata[key] = value
+	}
+	doc.Metadata = metadata
+	doc.Embedding = nil
+	return doc
+}
+
+// topK sorts results best first and keeps the first k
+func topK(results []sidecar.SimilarDocument, k int) []sidecar.SimilarDocument {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID // Stable order for equal scores
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// cosine returns the cosine similarity of two equal-length vectors
+func cosine(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
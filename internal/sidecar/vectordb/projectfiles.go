@@ -0,0 +1,52 @@
+package vectordb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/files"
+)
+
+// isStale reports whether an indexed path should be pruned
+func isStale(path, root string, projectFiles map[string]bool) bool {
+	rel, err := filepath.Rel(root, path)
+	inProject := err == nil && !strings.HasPrefix(rel, "..")
+	// Loco's own files, such as fetched documentation, are never project
+	// files but are indexed on purpose
+	if inProject && !strings.HasPrefix(filepath.ToSlash(rel), ".loco/") {
+		return !projectFiles[filepath.ToSlash(rel)]
+	}
+
+	// Outside the project (or under a different spelling of it, such as a
+	// symlinked path) or under .loco: only prune what is gone from disk
+	_, err = os.Stat(path)
+	return os.IsNotExist(err)
+}
+
+// listProjectFiles returns the root of the project holding the database at
+// dbPath, the directory containing its .loco directory, and the project's
+// files relative to it in slash form
+func listProjectFiles(ctx context.Context, dbPath string) (string, map[string]bool, error) {
+	root := filepath.Dir(dbPath)
+	for dir := root; ; dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == ".loco" {
+			root = filepath.Dir(dir)
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	listed, err := files.ForDir(ctx, root).Files(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	projectFiles := make(map[string]bool, len(listed))
+	for _, file := range listed {
+		projectFiles[file] = true
+	}
+	return root, projectFiles, nil
+}
@@ -0,0 +1,120 @@
+//go:build cgo
+
+package vectordb
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+// Prune deletes chunks for files that are no longer part of the project,
+// then vacuums the database to give the space back to the filesystem.
+//
+// Project files are those of the project holding the database: in a git
+// repository, tracked files plus untracked ones that aren't ignored, so new
+// files picked up by the watcher survive; outside one, every file under the
+// project. Indexed paths outside the project are only dropped once they no
+// longer exist on disk.
+func (s *SQLiteStore) Prune(ctx context.Context) (*sidecar.PruneResult, error) {
+	root, projectFiles, err := listProjectFiles(ctx, s.filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	indexed, err := s.indexedPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sidecar.PruneResult{BytesBefore: s.diskSize()}
+
+	chunksBefore, err := s.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range indexed {
+		if !isStale(path, root, projectFiles) {
+			continue
+		}
+		if err := s.Delete(ctx, path); err != nil {
+			return nil, fmt.Errorf("failed to prune %s: %w", path, err)
+		}
+		if err := s.DeleteFileState(ctx, path); err != nil {
+			return nil, err
+		}
+		result.RemovedFiles++
+	}
+
+	chunksAfter, err := s.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.RemovedChunks = chunksBefore - chunksAfter
+
+	if err := s.compact(ctx); err != nil {
+		return nil, err
+	}
+	result.BytesAfter = s.diskSize()
+
+	return result, nil
+}
+
+// indexedPaths lists every path with chunks or an indexing state
+func (s *SQLiteStore) indexedPaths(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT path FROM documents UNION SELECT path FROM file_states`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexed paths: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to scan indexed path: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating indexed paths: %w", err)
+	}
+
+	return paths, nil
+}
+
+// compact optimizes the keyword index and vacuums the database. The WAL is
+// checkpointed on both sides so the size on disk reflects the main file.
+func (s *SQLiteStore) compact(ctx context.Context) error {
+	if s.hasFTS {
+		if _, err := s.db.ExecContext(ctx, `INSERT INTO document_fts (document_fts) VALUES ('optimize')`); err != nil {
+			return fmt.Errorf("failed to optimize keyword index: %w", err)
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("failed to checkpoint database: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("failed to checkpoint database: %w", err)
+	}
+
+	return nil
+}
+
+// diskSize returns the size of the database file and its write-ahead log
+func (s *SQLiteStore) diskSize() int64 {
+	var size int64
+	for _, path := range []string{s.filePath, s.filePath + "-wal"} {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
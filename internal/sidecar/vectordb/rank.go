@@ -0,0 +1,83 @@
+package vectordb
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+const (
+	// rrfK dampens how much the very top ranks dominate the fused score;
+	// 60 is the constant from the original reciprocal-rank fusion paper
+	rrfK = 60
+
+	// hybridCandidates is the minimum number of results pulled from each
+	// of the vector and keyword searches before fusing
+	hybridCandidates = 50
+)
+
+// keywordTerm matches identifier-like words in a query
+var keywordTerm = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// queryTerms returns the distinct lowercased identifiers in query, in the
+// order they appear
+func queryTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range keywordTerm.FindAllString(query, -1) {
+		term = strings.ToLower(term)
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// fuseRanks merges ranked result lists with reciprocal-rank fusion and
+// returns the top k. Scores are scaled so a document ranked first in every
+// list scores 1.
+func fuseRanks(k int, lists ...[]sidecar.SimilarDocument) []sidecar.SimilarDocument {
+	fused := make(map[string]*sidecar.SimilarDocument)
+	var order []string
+
+	for _, list := range lists {
+		for rank, result := range list {
+			contribution := float32(1.0 / float64(rrfK+rank+1))
+			if existing, ok := fused[result.ID]; ok {
+				existing.Score += contribution
+				continue
+			}
+			doc := result
+			doc.Score = contribution
+			fused[result.ID] = &doc
+			order = append(order, result.ID)
+		}
+	}
+
+	results := make([]sidecar.SimilarDocument, 0, len(order))
+	maxScore := float32(len(lists)) / float32(rrfK+1)
+	for _, id := range order {
+		doc := *fused[id]
+		doc.Score /= maxScore
+		results = append(results, doc)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// docSymbol returns the symbol names a chunk declares, if the chunker
+// recorded any
+func docSymbol(doc sidecar.Document) string {
+	symbol, _ := doc.Metadata["symbol"].(string)
+	return symbol
+}
@@ -1,10 +1,11 @@
+//go:build cgo
+
 package vectordb
 
 import (
 	"context"
 	"database/sql"
 	"fmt"
-	"os"
 	"path/filepath"
 	"time"
 
@@ -18,6 +19,8 @@ type SQLiteStore struct {
 	db       *sql.DB
 	embedder sidecar.Embedder
 	dbPath   string
+	filePath string // Database file on disk, without connection parameters
+	hasFTS   bool   // Whether the FTS5 keyword index is available
 }
 
 // NewSQLiteStore creates a new SQLite vector store
@@ -30,6 +33,8 @@ func NewSQLiteStore(dbPath string, embedder sidecar.Embedder) (*SQLiteStore, err
 	// Enable sqlite-vec extension (required for CGO bindings)
 	sqlite_vec.Auto()
 
+	filePath := dbPath
+
 	// Open database using mattn/go-sqlite3 driver with sqlite-vec
 	// Add connection parameters for better concurrency and performance
 	dbPath = dbPath + "?_journal_mode=WAL&_busy_timeout=30000&_synchronous=NORMAL&_cache_size=1000"
@@ -46,6 +51,7 @@ func NewSQLiteStore(dbPath string, embedder sidecar.Embedder) (*SQLiteStore, err
 		db:       db,
 		embedder: embedder,
 		dbPath:   dbPath,
+		filePath: filePath,
 	}
 
 	// Initialize schema
@@ -65,12 +71,11 @@ func (s *SQLiteStore) initSchema() error {
 		return fmt.Errorf("sqlite-vec not available: %w", err)
 	}
 
-	// Detect embedding dimensions by creating a test embedding
-	testEmbedding, err := s.embedder.Embed(context.Background(), "test")
+	// Detect embedding dimensions; the vec0 table is created with a fixed size
+	embeddingDim, err := detectDimension(context.Background(), s.embedder)
 	if err != nil {
 		return fmt.Errorf("failed to detect embedding dimensions: %w", err)
 	}
-	embeddingDim := len(testEmbedding)
 
 	// Create documents table with metadata
 	createDocsTable := `
@@ -103,6 +108,12 @@ func (s *SQLiteStore) initSchema() error {
 		return fmt.Errorf("failed to create vector table: %w", err)
 	}
 
+	// Create the FTS5 keyword index used by hybrid search. SQLite builds
+	// without FTS5 (missing the sqlite_fts5 tag) fall back to vectors only.
+	if err := s.initKeywordIndex(); err != nil {
+		return err
+	}
+
 	// Create metadata table for RAG indexing state (replaces JSON file)
 	createMetadataTable := `
 	CREATE TABLE IF NOT EXISTS rag_metadata (
@@ -207,6 +218,15 @@ func (s *SQLiteStore) Store(ctx context.Context, doc sidecar.Document) error {
 		return fmt.Errorf("failed to insert vector: %w", err)
 	}
 
+	if s.hasFTS {
+		if _, err := tx.ExecContext(ctx, deleteKeywordsByID, doc.ID); err != nil {
+			return fmt.Errorf("failed to replace keyword index entry: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, insertKeywords, doc.ID, doc.Path, docSymbol(doc), doc.Content); err != nil {
+			return fmt.Errorf("failed to index keywords: %w", err)
+		}
+	}
+
 	return tx.Commit()
 }
 
@@ -240,6 +260,18 @@ func (s *SQLiteStore) StoreBatch(ctx context.Context, docs []sidecar.Document) e
 	}
 	defer stmtVec.Close()
 
+	var stmtFTSDelete, stmtFTSInsert *sql.Stmt
+	if s.hasFTS {
+		if stmtFTSDelete, err = tx.PrepareContext(ctx, deleteKeywordsByID); err != nil {
+			return fmt.Errorf("failed to prepare keyword delete statement: %w", err)
+		}
+		defer stmtFTSDelete.Close()
+		if stmtFTSInsert, err = tx.PrepareContext(ctx, insertKeywords); err != nil {
+			return fmt.Errorf("failed to prepare keyword statement: %w", err)
+		}
+		defer stmtFTSInsert.Close()
+	}
+
 	for _, doc := range docs {
 		// Insert document metadata
 		var chunkIndex, startLine, endLine interface{}
@@ -280,6 +312,15 @@ func (s *SQLiteStore) StoreBatch(ctx context.Context, docs []sidecar.Document) e
 		if err != nil {
 			return fmt.Errorf("failed to insert vector for %s: %w", doc.ID, err)
 		}
+
+		if s.hasFTS {
+			if _, err := stmtFTSDelete.ExecContext(ctx, doc.ID); err != nil {
+				return fmt.Errorf("failed to replace keyword index entry for %s: %w", doc.ID, err)
+			}
+			if _, err := stmtFTSInsert.ExecContext(ctx, doc.ID, doc.Path, docSymbol(doc), doc.Content); err != nil {
+				return fmt.Errorf("failed to index keywords for %s: %w", doc.ID, err)
+			}
+		}
 	}
 
 	return tx.Commit()
@@ -331,20 +372,7 @@ func (s *SQLiteStore) Query(ctx context.Context, embedding []float32, k int) ([]
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
-		// Build metadata
-		doc.Metadata = make(map[string]interface{})
-		if chunkIndex.Valid {
-			doc.Metadata["chunk_index"] = int(chunkIndex.Int64)
-		}
-		if startLine.Valid {
-			doc.Metadata["start_line"] = int(startLine.Int64)
-		}
-		if endLine.Valid {
-			doc.Metadata["end_line"] = int(endLine.Int64)
-		}
-		if language.Valid {
-			doc.Metadata["language"] = language.String
-		}
+		doc.Metadata = documentMetadata(chunkIndex, startLine, endLine, language)
 
 		// Convert distance to similarity score (1 - cosine_distance)
 		// sqlite-vec returns cosine distance (0 = identical, 2 = opposite)
@@ -367,19 +395,10 @@ func (s *SQLiteStore) Query(ctx context.Context, embedding []float32, k int) ([]
 	return results, nil
 }
 
-// QueryText finds k most similar documents to query text
+// QueryText finds k most relevant documents to query text, combining
+// vector similarity with keyword matches (see QueryHybrid)
 func (s *SQLiteStore) QueryText(ctx context.Context, query string, k int) ([]sidecar.SimilarDocument, error) {
-	if s.embedder == nil {
-		return nil, fmt.Errorf("embedder not configured")
-	}
-
-	// Generate embedding for query
-	embedding, err := s.embedder.Embed(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to embed query: %w", err)
-	}
-
-	return s.Query(ctx, embedding, k)
+	return s.QueryHybrid(ctx, query, k)
 }
 
 // Delete removes documents by path
@@ -415,6 +434,13 @@ func (s *SQLiteStore) Delete(ctx context.Context, path string) error {
 		}
 	}
 
+	// Delete keyword index entries
+	if s.hasFTS {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM document_fts WHERE path = ?", path); err != nil {
+			return fmt.Errorf("failed to delete keyword index entries: %w", err)
+		}
+	}
+
 	// Delete documents
 	_, err = tx.ExecContext(ctx, "DELETE FROM documents WHERE path = ?", path)
 	if err != nil {
@@ -441,6 +467,12 @@ func (s *SQLiteStore) Clear(ctx context.Context) error {
 		return fmt.Errorf("failed to clear documents: %w", err)
 	}
 
+	if s.hasFTS {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM document_fts"); err != nil {
+			return fmt.Errorf("failed to clear keyword index: %w", err)
+		}
+	}
+
 	return tx.Commit()
 }
 
@@ -591,6 +623,14 @@ func (s *SQLiteStore) SetFileState(ctx context.Context, path string, state sidec
 	return nil
 }
 
+// DeleteFileState forgets a single file's indexing state
+func (s *SQLiteStore) DeleteFileState(ctx context.Context, path string) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM file_states WHERE path = ?", path); err != nil {
+		return fmt.Errorf("failed to delete file state for %s: %w", path, err)
+	}
+	return nil
+}
+
 // GetFileStates returns all file states as a map (for backward compatibility)
 func (s *SQLiteStore) GetFileStates(ctx context.Context) (map[string]sidecar.FileState, error) {
 	query := `SELECT path, file_hash, indexed_at, success, error_message FROM file_states`
@@ -674,16 +714,17 @@ func (s *SQLiteStore) Close() error {
 	return nil
 }
 
-// ensureDir creates directory if it doesn't exist
-func ensureDir(dir string) error {
-	if dir == "" {
-		return nil
-	}
-	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		return err
-	}); err != nil {
-		// Directory doesn't exist, create it
-		return os.MkdirAll(dir, 0755)
-	}
-	return nil
-}
\ No newline at end of file
+// Compile-time checks that SQLiteStore provides what the service uses
+var (
+	_ sidecar.VectorStore   = (*SQLiteStore)(nil)
+	_ sidecar.MetadataStore = (*SQLiteStore)(nil)
+	_ sidecar.Pruner        = (*SQLiteStore)(nil)
+)
+
+// sqliteAvailable reports whether the SQLite backend was compiled in
+const sqliteAvailable = true
+
+// openSQLite opens the SQLite backend for New
+func openSQLite(path string, embedder sidecar.Embedder) (sidecar.VectorStore, error) {
+	return NewSQLiteStore(path, embedder)
+}
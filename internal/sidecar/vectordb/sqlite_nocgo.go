@@ -0,0 +1,17 @@
+//go:build !cgo
+
+package vectordb
+
+import (
+	"fmt"
+
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+// sqliteAvailable is false without cgo: sqlite-vec is a C extension
+const sqliteAvailable = false
+
+// openSQLite fails in builds without cgo
+func openSQLite(path string, embedder sidecar.Embedder) (sidecar.VectorStore, error) {
+	return nil, fmt.Errorf("the sqlite vector store needs a cgo build; use %q instead", BackendMemory)
+}
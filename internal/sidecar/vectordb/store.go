@@ -0,0 +1,76 @@
+package vectordb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+// Vector store backends, selected with analysis.rag.vector_store
+const (
+	BackendAuto   = "auto"   // SQLite when built with cgo, memory otherwise
+	BackendSQLite = "sqlite" // sqlite-vec with an FTS5 keyword index (needs cgo)
+	BackendMemory = "memory" // Pure Go, persisted to a gob file
+)
+
+// Compile-time checks that both backends provide what the service uses
+var (
+	_ sidecar.VectorStore   = (*MemoryStore)(nil)
+	_ sidecar.MetadataStore = (*MemoryStore)(nil)
+	_ sidecar.Pruner        = (*MemoryStore)(nil)
+)
+
+// New opens the vector store for backend at path. The memory backend keeps
+// path's name with a .gob extension, so the two never read each other's
+// files.
+func New(backend, path string, embedder sidecar.Embedder) (sidecar.VectorStore, error) {
+	switch backend {
+	case "", BackendAuto:
+		if sqliteAvailable {
+			return openSQLite(path, embedder)
+		}
+		return NewMemoryStore(gobPath(path), embedder)
+	case BackendSQLite:
+		return openSQLite(path, embedder)
+	case BackendMemory:
+		return NewMemoryStore(gobPath(path), embedder)
+	default:
+		return nil, fmt.Errorf("unknown vector store %q (want %s, %s or %s)", backend, BackendAuto, BackendSQLite, BackendMemory)
+	}
+}
+
+// detectDimension returns the embedder's vector size, embedding a probe
+// text when the embedder has no better way to find out
+func detectDimension(ctx context.Context, embedder sidecar.Embedder) (int, error) {
+	if detector, ok := embedder.(sidecar.DimensionDetector); ok {
+		return detector.DetectDimension(ctx)
+	}
+	embedding, err := embedder.Embed(ctx, "test")
+	if err != nil {
+		return 0, err
+	}
+	return len(embedding), nil
+}
+
+// gobPath swaps path's extension for .gob
+func gobPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".gob"
+}
+
+// ensureDir creates directory if it doesn't exist
+func ensureDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		return err
+	}); err != nil {
+		// Directory doesn't exist, create it
+		return os.MkdirAll(dir, 0755)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+// Package tasks tracks the plan of a chat session: a goal broken into
+// steps, each with a status, and the tool runs made while working on each
+// step.
+//
+// One step is active at a time. A new plan starts on its first step, and
+// finishing or skipping the active step moves on to the next pending one,
+// so tool runs are attributed without the model having to say which step
+// they belong to. Plans are saved with their session.
+package tasks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Status is where a step stands
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusActive  Status = "in_progress"
+	StatusDone    Status = "done"
+	StatusSkipped Status = "skipped"
+	StatusFailed  Status = "failed"
+)
+
+// ToolRun is a tool executed while a step was active
+type ToolRun struct {
+	Tool string    `json:"tool"`
+	OK   bool      `json:"ok"`
+	Time time.Time `json:"time"`
+}
+
+// Step is one step of a plan
+type Step struct {
+	Title   string    `json:"title"`
+	Status  Status    `json:"status"`
+	Runs    []ToolRun `json:"runs,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+// Plan is a goal and the steps to reach it
+type Plan struct {
+	Goal    string    `json:"goal"`
+	Steps   []Step    `json:"steps"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// maxSteps caps the steps of a plan
+const maxSteps = 30
+
+// New creates a plan working on its first step
+func New(goal string, steps []string) (*Plan, error) {
+	now := time.Now()
+	p := &Plan{Goal: strings.TrimSpace(goal), Created: now, Updated: now}
+	for _, title := range steps {
+		if title = strings.Join(strings.Fields(title), " "); title != "" {
+			p.Steps = append(p.Steps, Step{Title: title, Status: StatusPending, Updated: now})
+		}
+	}
+	if len(p.Steps) == 0 {
+		return nil, fmt.Errorf("a plan needs at least one step")
+	}
+	if len(p.Steps) > maxSteps {
+		return nil, fmt.Errorf("a plan can have at most %d steps, got %d", maxSteps, len(p.Steps))
+	}
+	if p.Goal == "" {
+		p.Goal = p.Steps[0].Title
+	}
+	p.Steps[0].Status = StatusActive
+	return p, nil
+}
+
+// Active returns the number of the active step, counting from 1, or 0 when
+// no step is active
+func (p *Plan) Active() int {
+	for i, step := range p.Steps {
+		if step.Status == StatusActive {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// SetStatus sets the status of step n, counting from 1; n of 0 means the
+// active step. Starting a step pauses the one that was active, and ending
+// the active step starts the next pending one.
+func (p *Plan) SetStatus(n int, status Status) error {
+	switch status {
+	case StatusPending, StatusActive, StatusDone, StatusSkipped, StatusFailed:
+	default:
+		return fmt.Errorf("unknown status %q", status)
+	}
+	if n == 0 {
+		if n = p.Active(); n == 0 {
+			return fmt.Errorf("no step is in progress; give a step number")
+		}
+	}
+	if n < 1 || n > len(p.Steps) {
+		return fmt.Errorf("there is no step %d (the plan has %d)", n, len(p.Steps))
+	}
+
+	now := time.Now()
+	wasActive := p.Steps[n-1].Status == StatusActive
+	if status == StatusActive {
+		if active := p.Active(); active != 0 && active != n {
+			p.Steps[active-1].Status = StatusPending
+			p.Steps[active-1].Updated = now
+		}
+	}
+	p.Steps[n-1].Status = status
+	p.Steps[n-1].Updated = now
+	p.Updated = now
+
+	if wasActive && (status == StatusDone || status == StatusSkipped) {
+		for i := range p.Steps {
+			if p.Steps[i].Status == StatusPending {
+				p.Steps[i].Status = StatusActive
+				p.Steps[i].Updated = now
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Record attributes a tool run to the active step. It reports false when
+// no step is active.
+func (p *Plan) Record(tool string, ok bool, at time.Time) bool {
+	n := p.Active()
+	if n == 0 {
+		return false
+	}
+	p.Steps[n-1].Runs = append(p.Steps[n-1].Runs, ToolRun{Tool: tool, OK: ok, Time: at})
+	p.Steps[n-1].Updated = at
+	p.Updated = at
+	return true
+}
+
+// Progress returns how many steps are finished, done or skipped, and how
+// many there are
+func (p *Plan) Progress() (finished, total int) {
+	for _, step := range p.Steps {
+		if step.Status == StatusDone || step.Status == StatusSkipped {
+			finished++
+		}
+	}
+	return finished, len(p.Steps)
+}
+
+// Clone returns a deep copy of the plan
+func (p *Plan) Clone() *Plan {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	c.Steps = make([]Step, len(p.Steps))
+	for i, step := range p.Steps {
+		step.Runs = append([]ToolRun(nil), step.Runs...)
+		c.Steps[i] = step
+	}
+	return &c
+}
+
+// Marker returns the checkbox a step is shown with
+func (s Step) Marker() string {
+	switch s.Status {
+	case StatusDone:
+		return "[x]"
+	case StatusActive:
+		return "[>]"
+	case StatusSkipped:
+		return "[-]"
+	case StatusFailed:
+		return "[!]"
+	default:
+		return "[ ]"
+	}
+}
+
+// String renders the plan as a numbered checklist, with the tools run for
+// each step
+func (p *Plan) String() string {
+	var b strings.Builder
+	finished, total := p.Progress()
+	fmt.Fprintf(&b, "Plan: %s (%d/%d done)\n", p.Goal, finished, total)
+	for i, step := range p.Steps {
+		fmt.Fprintf(&b, "  %s %d. %s", step.Marker(), i+1, step.Title)
+		if len(step.Runs) > 0 {
+			fmt.Fprintf(&b, " — %s", runSummary(step.Runs))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// runSummary counts a step's tool runs by tool, in order of first use
+func runSummary(runs []ToolRun) string {
+	var order []string
+	counts := make(map[string]int)
+	failed := 0
+	for _, run := range runs {
+		if counts[run.Tool] == 0 {
+			order = append(order, run.Tool)
+		}
+		counts[run.Tool]++
+		if !run.OK {
+			failed++
+		}
+	}
+	parts := make([]string, len(order))
+	for i, tool := range order {
+		parts[i] = tool
+		if counts[tool] > 1 {
+			parts[i] = fmt.Sprintf("%s ×%d", tool, counts[tool])
+		}
+	}
+	summary := strings.Join(parts, ", ")
+	if failed > 0 {
+		summary += fmt.Sprintf(" (%d failed)", failed)
+	}
+	return summary
+}
+
+// listItem matches a numbered or bulleted list line
+var listItem = regexp.MustCompile(`^\s*(?:\d+[.)]|[-*+]|\[[ xX]?\])\s+(.+)$`)
+
+// ParseSteps returns the items of the numbered or bulleted list in text,
+// such as a plan the model wrote out. Markdown emphasis is dropped.
+func ParseSteps(text string) []string {
+	var steps []string
+	for _, line := range strings.Split(text, "\n") {
+		m := listItem.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		step := strings.TrimSpace(strings.NewReplacer("**", "", "__", "", "`", "").Replace(m[1]))
+		// A checkbox after a bullet, as in "- [ ] step"
+		for _, box := range []string{"[ ] ", "[x] ", "[X] "} {
+			step = strings.TrimPrefix(step, box)
+		}
+		if step != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
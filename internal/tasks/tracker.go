@@ -0,0 +1,94 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+// Store keeps the plan of the current session
+type Store interface {
+	Plan() (*Plan, error) // nil when the session has no plan
+	SetPlan(plan *Plan) error
+}
+
+// Tracker reads and changes the current session's plan. Changes are made
+// one at a time, so tool runs finishing together are all recorded.
+type Tracker struct {
+	store Store
+	mu    sync.Mutex
+}
+
+// NewTracker creates a tracker over the store
+func NewTracker(store Store) *Tracker {
+	return &Tracker{store: store}
+}
+
+// Current returns a copy of the plan, or nil when there is none
+func (t *Tracker) Current() (*Plan, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	plan, err := t.store.Plan()
+	return plan.Clone(), err
+}
+
+// Set replaces the plan; nil clears it
+func (t *Tracker) Set(plan *Plan) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.store.SetPlan(plan)
+}
+
+// Update changes a copy of the plan with fn and saves it in the plan's
+// place, so readers never see it half changed. Returns a copy of the
+// result.
+func (t *Tracker) Update(fn func(*Plan) error) (*Plan, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stored, err := t.store.Plan()
+	if err != nil {
+		return nil, err
+	}
+	plan := stored.Clone()
+	if plan == nil {
+		return nil, fmt.Errorf("there is no plan; start one with /plan new <goal>")
+	}
+	if err := fn(plan); err != nil {
+		return nil, err
+	}
+	if err := t.store.SetPlan(plan); err != nil {
+		return nil, err
+	}
+	return plan.Clone(), nil
+}
+
+// Record attributes a tool run to the active step, if any
+func (t *Tracker) Record(tool string, ok bool) {
+	_, _ = t.Update(func(p *Plan) error {
+		if !p.Record(tool, ok, time.Now()) {
+			return fmt.Errorf("no step is in progress")
+		}
+		return nil
+	})
+}
+
+// Propose asks the model for the steps to reach goal
+func Propose(ctx context.Context, client llm.Client, goal string) ([]string, error) {
+	messages := []llm.Message{
+		{Role: "system", Content: "You plan software work. Reply with a numbered list only."},
+		{Role: "user", Content: fmt.Sprintf("Break this goal into 3 to 8 concrete steps, each one short imperative line that can be checked off:\n\n%s", goal)},
+	}
+	reply, err := client.Complete(ctx, messages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to propose a plan: %w", err)
+	}
+	steps := ParseSteps(reply)
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("the model proposed no steps: %s", strings.TrimSpace(reply))
+	}
+	return steps, nil
+}
@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/agents"
+)
+
+// AgentParams represents parameters for the agent tool
+type AgentParams struct {
+	Task  string        `json:"task,omitempty"`  // One task; may start with small, medium or large
+	Size  string        `json:"size,omitempty"`  // small, medium or large; empty routes by the task's wording
+	Paths []string      `json:"paths,omitempty"` // Files or directories the task needs
+	Tasks []agents.Task `json:"tasks,omitempty"` // Several tasks to run side by side
+}
+
+// AgentResult is the metadata of an agent response
+type AgentResult struct {
+	Results []agents.Result `json:"results"`
+}
+
+// maxAgentTasks caps the sub-agents one call spawns
+const maxAgentTasks = 6
+
+// agentTool hands scoped tasks to sub-agents on the model team
+type agentTool struct {
+	runner *agents.Runner
+}
+
+const (
+	// AgentToolName is the name of this tool
+	AgentToolName = "agent"
+	// agentDescription describes what this tool does
+	agentDescription = `Hand scoped tasks to sub-agents, each running on one model of the team with a context window of its own.
+
+WHEN TO USE:
+- Work that needs a lot of reading but only a short answer, like summarizing a package
+- Independent questions that can be answered side by side
+- Hard design questions worth the large model's time
+
+SIZES:
+- small: summaries, lookups, lists and extraction
+- medium (default): explanations and ordinary questions
+- large: design, refactoring plans, reviews and debugging
+Without a size the task's wording picks one.
+
+A sub-agent sees only its task and the files or directories given in paths (or @-mentioned in the task), never this conversation, so say everything it needs. Answers come back into the chat.`
+)
+
+// NewAgentTool creates a new agent tool
+func NewAgentTool(runner *agents.Runner) BaseTool {
+	return &agentTool{runner: runner}
+}
+
+// Name returns the tool name
+func (t *agentTool) Name() string {
+	return AgentToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: the agent waits on the model for many turns
+func (t *agentTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *agentTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        AgentToolName,
+		Description: agentDescription,
+		Parameters: map[string]any{
+			"task": map[string]any{
+				"type":        "string",
+				"description": "The task, complete in itself",
+			},
+			"size": map[string]any{
+				"type":        "string",
+				"description": "Which model runs it (default by the task's wording)",
+				"enum":        []string{"small", "medium", "large"},
+			},
+			"paths": map[string]any{
+				"type":        "array",
+				"description": "Project-relative files or directories the sub-agent reads",
+				"items":       map[string]any{"type": "string"},
+			},
+			"tasks": map[string]any{
+				"type":        "array",
+				"description": "Several tasks to run side by side, each {task, size, paths}",
+				"items":       map[string]any{"type": "object"},
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "agent",
+				Description: "Hand a task to a sub-agent on the small, medium or large model",
+				Examples:    []string{"/agent summarize @internal/session", "/agent large design a plugin system for tools"},
+				Args:        []string{"task"},
+			},
+		},
+	}
+}
+
+// Run spawns the sub-agents and waits for their answers
+func (t *agentTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params AgentParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	tasks := params.Tasks
+	if task := strings.TrimSpace(params.Task); task != "" {
+		size := params.Size
+		if size == "" {
+			// "/agent large design ..." names the size up front
+			if first, rest, ok := strings.Cut(task, " "); ok && isSizeName(first) {
+				size, task = first, strings.TrimSpace(rest)
+			}
+		}
+		tasks = append(tasks, agents.Task{Goal: task, Size: agents.Size(size), Paths: params.Paths})
+	}
+	if len(tasks) == 0 {
+		return NewTextErrorResponse("usage: /agent [small|medium|large] <task>"), nil
+	}
+	if len(tasks) > maxAgentTasks {
+		return NewTextErrorResponse(fmt.Sprintf("at most %d tasks at once, got %d", maxAgentTasks, len(tasks))), nil
+	}
+	for i := range tasks {
+		if strings.TrimSpace(tasks[i].Goal) == "" {
+			return NewTextErrorResponse(fmt.Sprintf("task %d is empty", i+1)), nil
+		}
+		if tasks[i].Size != "" {
+			size, ok := agents.ParseSize(string(tasks[i].Size))
+			if !ok {
+				return NewTextErrorResponse(fmt.Sprintf("unknown size %q; use small, medium or large", tasks[i].Size)), nil
+			}
+			tasks[i].Size = size
+		}
+	}
+
+	results := t.runner.RunAll(ctx, tasks)
+
+	var b strings.Builder
+	failed := 0
+	for i, result := range results {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		model := string(result.Size)
+		if result.Model != "" {
+			model += ", " + result.Model
+		}
+		fmt.Fprintf(&b, "**Sub-agent %d** (%s, %s): %s\n", i+1, model, result.Elapsed, result.Goal)
+		if len(result.Files) > 0 {
+			fmt.Fprintf(&b, "Read %s\n", strings.Join(result.Files, ", "))
+		}
+		if result.Error != "" {
+			failed++
+			fmt.Fprintf(&b, "\nFailed: %s", result.Error)
+			continue
+		}
+		b.WriteString("\n" + result.Output)
+	}
+
+	response := NewTextResponse(b.String())
+	if failed == len(results) {
+		response = NewTextErrorResponse(b.String())
+	}
+	return WithResponseMetadata(response, AgentResult{Results: results}), nil
+}
+
+// isSizeName reports whether word names a sub-agent size in full
+func isSizeName(word string) bool {
+	switch strings.ToLower(word) {
+	case "small", "medium", "large":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+// AskCodebaseParams represents parameters for codebase questions
+type AskCodebaseParams struct {
+	Question string `json:"question"`    // The question to answer
+	K        int    `json:"k,omitempty"` // Number of chunks to retrieve (default 8)
+}
+
+// AskCodebaseSource is a retrieved chunk the answer may cite
+type AskCodebaseSource struct {
+	Path      string  `json:"path"`
+	StartLine int     `json:"start_line,omitempty"`
+	EndLine   int     `json:"end_line,omitempty"`
+	Symbol    string  `json:"symbol,omitempty"`
+	Language  string  `json:"language,omitempty"`
+	Score     float32 `json:"score"`
+	Content   string  `json:"content"`
+}
+
+// Location renders the source as path:start-end, the form answers cite
+func (s AskCodebaseSource) Location() string {
+	if s.StartLine > 0 {
+		return fmt.Sprintf("%s:%d-%d", s.Path, s.StartLine, s.EndLine)
+	}
+	return s.Path
+}
+
+// AskCodebaseResult is the metadata of an ask_codebase response
+type AskCodebaseResult struct {
+	Answer  string              `json:"answer"`
+	Sources []AskCodebaseSource `json:"sources"`
+}
+
+// askCodebaseTool answers questions from the RAG index with citations
+type askCodebaseTool struct {
+	sidecarService sidecar.Service
+	llmClient      llm.Client
+	workingDir     string
+}
+
+const (
+	// AskCodebaseToolName is the name of this tool
+	AskCodebaseToolName = "ask_codebase"
+	// CodebaseSourceName labels the tool cards showing cited sources
+	CodebaseSourceName = "codebase_source"
+	// askCodebaseDescription describes what this tool does
+	askCodebaseDescription = `Answer a question about the codebase from the RAG index.
+
+WHAT THIS DOES:
+- Retrieves the chunks most relevant to the question
+- Asks the model to answer using only those chunks
+- Cites sources as file:line ranges
+
+WHEN TO USE:
+- "Where is X handled?" or "How does Y work?" questions
+- Getting a grounded overview before reading files
+
+LIMITATIONS:
+- Only as fresh as the index; run /rag-index after large changes`
+
+	// defaultAskCodebaseK is how many chunks are retrieved by default
+	defaultAskCodebaseK = 8
+
+	// maxAskCodebaseChunkChars truncates each chunk in the prompt
+	maxAskCodebaseChunkChars = 2500
+)
+
+// NewAskCodebaseTool creates a new ask-codebase tool. llmClient may be nil
+// until a model is configured.
+func NewAskCodebaseTool(sidecarService sidecar.Service, llmClient llm.Client, workingDir string) BaseTool {
+	return &askCodebaseTool{
+		sidecarService: sidecarService,
+		llmClient:      llmClient,
+		workingDir:     workingDir,
+	}
+}
+
+// Name returns the tool name
+func (a *askCodebaseTool) Name() string {
+	return AskCodebaseToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: answering waits on the model
+func (a *askCodebaseTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (a *askCodebaseTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        AskCodebaseToolName,
+		Description: askCodebaseDescription,
+		Parameters: map[string]any{
+			"question": map[string]any{
+				"type":        "string",
+				"description": "The question to answer",
+			},
+			"k": map[string]any{
+				"type":        "integer",
+				"description": "Number of chunks to retrieve (default 8)",
+			},
+		},
+		Required: []string{"question"},
+		Commands: []CommandInfo{
+			{
+				Command:     "ask-codebase",
+				Aliases:     []string{"ask"},
+				Description: "Answer a question with cited sources",
+				Examples:    []string{"/ask-codebase how are tool permissions checked", "/ask where is the config loaded"},
+			},
+		},
+	}
+}
+
+// Run retrieves relevant chunks and has the model answer from them
+func (a *askCodebaseTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	if a.sidecarService == nil {
+		return NewTextErrorResponse("RAG service not available"), nil
+	}
+	if a.llmClient == nil {
+		return NewTextErrorResponse("No model available to answer - check your LM Studio connection"), nil
+	}
+
+	var params AskCodebaseParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	params.Question = strings.TrimSpace(params.Question)
+	if params.Question == "" {
+		return NewTextErrorResponse("question parameter is required"), nil
+	}
+	if params.K <= 0 {
+		params.K = defaultAskCodebaseK
+	}
+	if params.K > 20 {
+		params.K = 20
+	}
+
+	publish := GetProgressPublisher(ctx)
+	publish("Retrieving", 0, 0, "")
+
+	results, err := a.sidecarService.QuerySimilar(ctx, params.Question, params.K)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("RAG query failed: %s", err)), nil
+	}
+	if len(results) == 0 {
+		return NewTextErrorResponse("No indexed code matched the question. Run /rag-index if the index is empty."), nil
+	}
+
+	sources := make([]AskCodebaseSource, 0, len(results))
+	for _, result := range results {
+		sources = append(sources, a.source(result))
+	}
+
+	publish("Answering", len(sources), 0, "")
+
+	answer, err := a.llmClient.Complete(ctx, []llm.Message{
+		{Role: "system", Content: askCodebaseSystemPrompt},
+		{Role: "user", Content: askCodebasePrompt(params.Question, sources)},
+	})
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("Failed to generate answer: %s", err)), nil
+	}
+
+	summary := fmt.Sprintf("%s\n\nAnswered from %d sources", params.Question, len(sources))
+	return WithResponseMetadata(NewTextResponse(summary), AskCodebaseResult{
+		Answer:  strings.TrimSpace(answer),
+		Sources: sources,
+	}), nil
+}
+
+// source converts a search result, making its path relative to the project
+func (a *askCodebaseTool) source(result sidecar.SimilarDocument) AskCodebaseSource {
+	source := AskCodebaseSource{
+		Path:    result.Path,
+		Score:   result.Score,
+		Content: result.Content,
+	}
+	if a.workingDir != "" {
+		if rel, err := filepath.Rel(a.workingDir, result.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			source.Path = rel
+		}
+	}
+	source.StartLine, _ = result.Metadata["start_line"].(int)
+	source.EndLine, _ = result.Metadata["end_line"].(int)
+	source.Symbol, _ = result.Metadata["symbol"].(string)
+	source.Language, _ = result.Metadata["language"].(string)
+	if source.Language == "text" {
+		source.Language = ""
+	}
+	return source
+}
+
+const askCodebaseSystemPrompt = `You answer questions about a software project using only the numbered sources provided.
+
+Rules:
+- Cite every claim with the source location in square brackets, exactly as given, e.g. [internal/app/app.go:40-72]
+- If the sources don't contain the answer, say so instead of guessing
+- Be concise; prefer short paragraphs or bullet points`
+
+// askCodebasePrompt lists the sources followed by the question
+func askCodebasePrompt(question string, sources []AskCodebaseSource) string {
+	var b strings.Builder
+	b.WriteString("Sources:\n")
+	for i, source := range sources {
+		content := source.Content
+		if len(content) > maxAskCodebaseChunkChars {
+			content = content[:maxAskCodebaseChunkChars] + "\n... (truncated)"
+		}
+		b.WriteString(fmt.Sprintf("\n[%d] %s", i+1, source.Location()))
+		if source.Symbol != "" {
+			b.WriteString(" (" + source.Symbol + ")")
+		}
+		b.WriteString("\n```" + source.Language + "\n" + content + "\n```\n")
+	}
+	b.WriteString("\nQuestion: " + question)
+	return b.String()
+}
@@ -0,0 +1,550 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/permission"
+	"github.com/billie-coop/loco/internal/shell"
+)
+
+// BashParams represents parameters for the bash tool
+type BashParams struct {
+	Command   string `json:"command"`              // Command line to run
+	TimeoutMs int    `json:"timeout_ms,omitempty"` // Override the configured timeout
+}
+
+// bashTool runs shell commands in a sandboxed interpreter rooted at the project
+type bashTool struct {
+	permissions   permission.Service
+	workingDir    string
+	configManager *config.Manager
+
+	mu    sync.Mutex
+	shell *shell.PersistentShell
+}
+
+const (
+	// BashToolName is the name of this tool
+	BashToolName = "bash"
+	// bashDescription describes what this tool does
+	bashDescription = `Run a shell command in the project directory.
+
+WHAT THIS DOES:
+- Executes the command with a built-in POSIX shell interpreter
+- Keeps the working directory and environment between calls
+- Captures stdout, stderr and the exit code
+
+SAFETY:
+- Read-only commands (ls, cat, git status, ...) and the project allowlist run directly
+- Anything else requires the user's permission
+- Commands on the project denylist are always refused
+- Commands naming guardrails.protected_paths, directly, through a glob or by reading a directory
+  that contains one (grep -r, rg), are always refused; name the files or directories to read instead
+- Long-running commands are stopped after the timeout
+
+OUTPUT:
+- Combined output, truncated in the middle when very long
+- Exit code`
+
+	minBashTimeout = time.Second
+	maxBashTimeout = 10 * time.Minute
+)
+
+// NewBashTool creates a new bash tool
+func NewBashTool(permissions permission.Service, workingDir string, configManager *config.Manager) BaseTool {
+	return &bashTool{
+		permissions:   permissions,
+		workingDir:    workingDir,
+		configManager: configManager,
+	}
+}
+
+// Name returns the tool name
+func (b *bashTool) Name() string {
+	return BashToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: calls may wait on a permission prompt and on the command
+func (b *bashTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (b *bashTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        BashToolName,
+		Description: bashDescription,
+		Parameters: map[string]any{
+			"command": map[string]any{
+				"type":        "string",
+				"description": "The command line to execute",
+			},
+			"timeout_ms": map[string]any{
+				"type":        "integer",
+				"description": "Timeout in milliseconds (default from config, max 600000)",
+				"minimum":     1000,
+			},
+		},
+		Required: []string{"command"},
+		Commands: []CommandInfo{
+			{
+				Command:     "bash",
+				Aliases:     []string{"sh"},
+				Description: "Run a shell command",
+				Examples:    []string{"/bash go test ./...", "/sh ls -la"},
+			},
+		},
+	}
+}
+
+// TouchedPaths returns the words of the command that may name files,
+// resolved against the shell's working directory
+func (b *bashTool) TouchedPaths(call ToolCall) []string {
+	var params BashParams
+	if json.Unmarshal([]byte(call.Input), &params) != nil {
+		return nil
+	}
+	words, err := shell.PathWords(params.Command)
+	if err != nil {
+		return nil
+	}
+	return expandPathWords(words, b.currentDir())
+}
+
+// ReadTrees returns the directories the command reads recursively, like
+// the ones given to grep -r
+func (b *bashTool) ReadTrees(call ToolCall) []TreeRead {
+	var params BashParams
+	if json.Unmarshal([]byte(call.Input), &params) != nil {
+		return nil
+	}
+	commands, err := shell.SimpleCommands(params.Command)
+	if err != nil {
+		return nil
+	}
+	return recursiveReads(commands, b.currentDir())
+}
+
+// currentDir returns the interpreter's working directory
+func (b *bashTool) currentDir() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.shell != nil {
+		return b.shell.GetWorkingDir()
+	}
+	return b.workingDir
+}
+
+// expandPathWords resolves words against dir, adding the files a word
+// matches when it is a glob, so .en* is checked as .env
+func expandPathWords(words []string, dir string) []string {
+	paths := make([]string, 0, len(words))
+	for _, word := range words {
+		if !filepath.IsAbs(word) {
+			word = filepath.Join(dir, word)
+		}
+		paths = append(paths, word)
+		if strings.ContainsAny(word, "*?[") {
+			matches, _ := filepath.Glob(word)
+			paths = append(paths, matches...)
+		}
+	}
+	return paths
+}
+
+// recursiveReads returns the directories that recursiveReaders among the
+// commands read, resolved against dir
+func recursiveReads(commands [][]string, dir string) []TreeRead {
+	var trees []TreeRead
+	for _, argv := range commands {
+		for _, launched := range launchedCommands(argv) {
+			reader, ok := recursiveReaders[filepath.Base(launched[0])]
+			if !ok {
+				continue
+			}
+			args := launched[1:]
+			if len(reader.recursive) > 0 && !hasUnsafeArgument(args, reader.recursive) {
+				continue
+			}
+			skipHidden := len(reader.hidden) > 0 && !hasUnsafeArgument(args, reader.hidden)
+
+			// The words that aren't options or their values; a searcher's
+			// first one is the pattern, unless given with -e or -f
+			var operands []string
+			patternGiven := !reader.searcher
+			for i := 0; i < len(args); i++ {
+				switch arg := args[i]; {
+				case arg == "-e" || arg == "-f" || strings.HasPrefix(arg, "--regexp") || strings.HasPrefix(arg, "--file"):
+					patternGiven = true
+					if !strings.Contains(arg, "=") {
+						i++
+					}
+				case slices.Contains(reader.valued, arg):
+					i++
+				case strings.HasPrefix(arg, "-") && arg != "-":
+				case !patternGiven:
+					patternGiven = true
+				default:
+					operands = append(operands, arg)
+				}
+			}
+
+			found := false
+			for _, operand := range operands {
+				path := operand
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, path)
+				}
+				if info, err := os.Stat(path); err == nil && info.IsDir() {
+					trees = append(trees, TreeRead{Dir: path, SkipHidden: skipHidden})
+					found = true
+				}
+			}
+			if reader.searcher && len(operands) == 0 && !found {
+				trees = append(trees, TreeRead{Dir: dir, SkipHidden: skipHidden})
+			}
+		}
+	}
+	return trees
+}
+
+// Run executes the command
+func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params BashParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	params.Command = strings.TrimSpace(params.Command)
+	if params.Command == "" {
+		return NewTextErrorResponse("command parameter is required"), nil
+	}
+
+	cfg := b.bashConfig()
+
+	commands, err := shell.SimpleCommands(params.Command)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("could not parse command: %s", err)), nil
+	}
+
+	// Denylist always wins, even over previously granted permissions
+	if entry, denied := matchDenylist(commands, cfg.Denylist); denied {
+		return NewTextErrorResponse(fmt.Sprintf("command refused: %q is on the denylist", entry)), nil
+	}
+
+	if !isAllowlisted(commands, params.Command, cfg.Allowlist) {
+		sessionID, _ := GetContextValues(ctx)
+		granted := b.permissions != nil && b.permissions.Request(ctx, permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			ToolCallID:  call.ID,
+			ToolName:    BashToolName,
+			Action:      "execute",
+			Path:        params.Command,
+			Description: fmt.Sprintf("Run command: %s", params.Command),
+			Params:      params,
+		})
+		if !granted {
+			return NewTextErrorResponse("permission denied: command was not run"), nil
+		}
+	}
+
+	timeout := time.Duration(cfg.TimeoutMs) * time.Millisecond
+	if params.TimeoutMs > 0 {
+		timeout = time.Duration(params.TimeoutMs) * time.Millisecond
+	}
+	if timeout < minBashTimeout {
+		timeout = minBashTimeout
+	}
+	if timeout > maxBashTimeout {
+		timeout = maxBashTimeout
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	sh := b.getShell(cfg.Denylist)
+	start := time.Now()
+	stdout, stderr, runErr := sh.Exec(runCtx, params.Command)
+	elapsed := time.Since(start)
+
+	// Keep the interpreter confined to the project
+	if !b.insideProject(sh.GetWorkingDir()) {
+		b.resetShell()
+		stderr += fmt.Sprintf("\n(working directory left the project; reset to %s)", b.workingDir)
+	}
+
+	exitCode := shell.ExitCode(runErr)
+	timedOut := runCtx.Err() == context.DeadlineExceeded
+
+	output := stdout
+	if stderr != "" {
+		if output != "" && !strings.HasSuffix(output, "\n") {
+			output += "\n"
+		}
+		output += stderr
+	}
+	output = truncateOutput(output, cfg.MaxOutputBytes)
+
+	var response strings.Builder
+	if output != "" {
+		response.WriteString(output)
+		if !strings.HasSuffix(output, "\n") {
+			response.WriteString("\n")
+		}
+	}
+	switch {
+	case timedOut:
+		response.WriteString(fmt.Sprintf("\nCommand timed out after %s", timeout))
+	case runErr != nil && exitCode == 1 && !isExitStatus(runErr):
+		response.WriteString(fmt.Sprintf("\nCommand failed: %s", runErr))
+	default:
+		response.WriteString(fmt.Sprintf("\nExit code: %d", exitCode))
+	}
+
+	result := NewTextResponse(response.String())
+	result.IsError = timedOut || exitCode != 0
+	return WithResponseMetadata(result, map[string]any{
+		"command":     params.Command,
+		"exit_code":   exitCode,
+		"timed_out":   timedOut,
+		"duration_ms": elapsed.Milliseconds(),
+		"working_dir": sh.GetWorkingDir(),
+	}), nil
+}
+
+// bashConfig returns the configured sandbox settings, falling back to defaults
+func (b *bashTool) bashConfig() config.BashConfig {
+	cfg := config.DefaultConfig().Bash
+	if b.configManager != nil {
+		if current := b.configManager.Get(); current != nil {
+			if current.Bash.Allowlist != nil {
+				cfg.Allowlist = current.Bash.Allowlist
+			}
+			if current.Bash.Denylist != nil {
+				cfg.Denylist = current.Bash.Denylist
+			}
+			if current.Bash.TimeoutMs > 0 {
+				cfg.TimeoutMs = current.Bash.TimeoutMs
+			}
+			if current.Bash.MaxOutputBytes > 0 {
+				cfg.MaxOutputBytes = current.Bash.MaxOutputBytes
+			}
+		}
+	}
+	return cfg
+}
+
+// isAllowlisted reports whether every command in the line may run without
+// asking. run_process uses it too.
+func isAllowlisted(commands [][]string, command string, allowlist []string) bool {
+	// Expansions hide what the command will touch until it runs
+	if len(commands) == 0 || shell.WritesFiles(command) || shell.HasExpansions(command) {
+		return false
+	}
+	allowed := append(append([]string{}, safeCommands...), allowlist...)
+	for _, argv := range commands {
+		// A wrapper is only as safe as what it runs
+		for _, launched := range launchedCommands(argv) {
+			entry, ok := matchCommandPrefix(launched, allowed)
+			if !ok || hasUnsafeArgument(launched[len(strings.Fields(entry)):], unsafeArguments[entry]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchDenylist finds the first denylist entry that any of the commands,
+// or a command they launch through env, xargs and the like, starts with
+func matchDenylist(commands [][]string, denylist []string) (string, bool) {
+	for _, argv := range commands {
+		for _, launched := range launchedCommands(argv) {
+			if entry, denied := matchCommandPrefix(launched, denylist); denied {
+				return entry, true
+			}
+		}
+	}
+	return "", false
+}
+
+// launchedCommands returns argv followed by the commands it runs through
+// commandWrappers, so "env nice sudo ls" gives "nice sudo ls" and "sudo ls"
+// after it
+func launchedCommands(argv []string) [][]string {
+	launched := [][]string{argv}
+	for {
+		argv = unwrapCommand(argv)
+		if len(argv) == 0 {
+			return launched
+		}
+		launched = append(launched, argv)
+	}
+}
+
+// unwrapCommand returns the command a wrapper runs, or nil when argv isn't
+// a wrapper or runs nothing
+func unwrapCommand(argv []string) []string {
+	if len(argv) == 0 {
+		return nil
+	}
+	wrapper, ok := commandWrappers[filepath.Base(argv[0])]
+	if !ok {
+		return nil
+	}
+	operands := wrapper.operands
+	for i := 1; i < len(argv); i++ {
+		arg := argv[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch {
+		case arg == "--":
+			operands = 0
+		case slices.Contains(wrapper.split, name) && hasValue:
+			return append(strings.Fields(value), argv[i+1:]...)
+		case slices.Contains(wrapper.split, arg) && i+1 < len(argv):
+			return append(strings.Fields(argv[i+1]), argv[i+2:]...)
+		case slices.Contains(wrapper.valued, arg):
+			i++
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			// Flags, and options with their value attached
+		case wrapper.assignments && hasValue:
+		case operands > 0:
+			operands--
+		default:
+			return argv[i:]
+		}
+	}
+	return nil
+}
+
+// hasUnsafeArgument reports whether args use one of the unsafe options or
+// subcommands. A single-letter option also matches when grouped with
+// others, like -ao for -o; an option starting with -- also matches its
+// abbreviations.
+func hasUnsafeArgument(args []string, unsafe []string) bool {
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "=")
+		for _, option := range unsafe {
+			switch {
+			case name == option:
+				return true
+			case len(option) == 2 && option[0] == '-' && strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") &&
+				strings.ContainsRune(arg[1:], rune(option[1])):
+				return true
+			case strings.HasPrefix(option, "--") && len(name) > 3 && strings.HasPrefix(option, name):
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getShell returns the tool's interpreter, creating it on first use
+func (b *bashTool) getShell(denylist []string) *shell.PersistentShell {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.shell == nil {
+		b.shell = shell.NewPersistentShell(b.workingDir)
+	}
+
+	// Enforce the denylist inside the interpreter too, so commands reached
+	// through eval or functions are still blocked
+	var single []string
+	var combos [][]string
+	for _, entry := range denylist {
+		words := strings.Fields(entry)
+		switch len(words) {
+		case 0:
+		case 1:
+			single = append(single, words[0])
+		default:
+			combos = append(combos, words)
+		}
+	}
+	blockers := []shell.BlockFunc{
+		shell.CommandsBlocker(single),
+		shell.ArgumentsBlocker(combos),
+	}
+	// Commands run through env, xargs and the like reach the interpreter
+	// as the wrapper's arguments
+	wrapped := func(cmd string, args []string) error {
+		for _, argv := range launchedCommands(append([]string{cmd}, args...))[1:] {
+			for _, block := range blockers {
+				if err := block(argv[0], argv[1:]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	b.shell.SetBlockFuncs(append(blockers, wrapped))
+
+	return b.shell
+}
+
+// resetShell discards the interpreter so the next command starts fresh
+func (b *bashTool) resetShell() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.shell = nil
+}
+
+// insideProject reports whether dir is the working directory or below it
+func (b *bashTool) insideProject(dir string) bool {
+	rel, err := filepath.Rel(b.workingDir, dir)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// matchCommandPrefix finds the first entry whose words prefix argv
+func matchCommandPrefix(argv []string, entries []string) (string, bool) {
+	if len(argv) == 0 {
+		return "", false
+	}
+	name := filepath.Base(argv[0])
+	for _, entry := range entries {
+		words := strings.Fields(entry)
+		if len(words) == 0 || len(words) > len(argv) || words[0] != name {
+			continue
+		}
+		match := true
+		for i := 1; i < len(words); i++ {
+			if argv[i] != words[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return entry, true
+		}
+	}
+	return "", false
+}
+
+// truncateOutput keeps the head and tail of long output
+func truncateOutput(output string, maxBytes int) string {
+	if maxBytes <= 0 || len(output) <= maxBytes {
+		return output
+	}
+	half := maxBytes / 2
+	omitted := len(output) - 2*half
+	return fmt.Sprintf("%s\n\n... [%d bytes truncated] ...\n\n%s", output[:half], omitted, output[len(output)-half:])
+}
+
+// isExitStatus reports whether err is a plain non-zero exit from the command
+func isExitStatus(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "exit status")
+}
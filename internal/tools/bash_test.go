@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/shell"
+)
+
+func TestBashCommandChecks(t *testing.T) {
+	denylist := config.DefaultConfig().Bash.Denylist
+	tests := []struct {
+		command     string
+		allowlisted bool
+		denied      string
+	}{
+		{command: "ls -la", allowlisted: true},
+		{command: "git status && git log --oneline -5", allowlisted: true},
+		{command: "git diff --stat", allowlisted: true},
+		{command: "find . -name '*.go'", allowlisted: true},
+		{command: "rg --pre-glob '*.gz' TODO", allowlisted: true},
+		{command: "timeout 5 ls", allowlisted: false},
+		{command: "echo x > go.mod", allowlisted: false},
+		{command: "echo x > /dev/null", allowlisted: true},
+		{command: "ls missing 2>&1", allowlisted: true},
+		{command: "echo x >&2", allowlisted: true},
+		{command: "cat README.md <&-", allowlisted: true},
+		{command: "echo hi >& out.txt", allowlisted: false},
+		{command: "echo hi >&out.txt", allowlisted: false},
+		{command: "cat <& in.txt", allowlisted: false},
+		{command: "echo hi >& $FD", allowlisted: false},
+
+		{command: "env rm -rf x", allowlisted: false},
+		{command: "env", allowlisted: false},
+		{command: "find . -delete", allowlisted: false},
+		{command: "find . -exec rm -rf {} +", allowlisted: false},
+		{command: "find . -execdir rm {} ;", allowlisted: false},
+		{command: "find . -ok rm {} ;", allowlisted: false},
+		{command: "git branch -D main", allowlisted: false},
+		{command: "git branch", allowlisted: false},
+		{command: "git diff --output=x", allowlisted: false},
+		{command: "git diff --output x", allowlisted: false},
+		{command: "git log --outp=x", allowlisted: false},
+		{command: "git diff --ext-diff", allowlisted: false},
+		{command: "git remote add evil https://example.com/x.git", allowlisted: false},
+		{command: "rg --pre ./run.sh TODO", allowlisted: false},
+		{command: "man -aP ./run.sh ls", allowlisted: false},
+		{command: "less README.md", allowlisted: false},
+		{command: "more README.md", allowlisted: false},
+		{command: "top", allowlisted: false},
+		{command: "htop", allowlisted: false},
+
+		{command: "sudo ls", denied: "sudo"},
+		{command: "env sudo ls", denied: "sudo"},
+		{command: "'sudo' ls", denied: "sudo"},
+		{command: "\\sudo ls", denied: "sudo"},
+		{command: "\"/usr/bin/su\"do ls", denied: "sudo"},
+		{command: "env -i FOO=bar sudo ls", denied: "sudo"},
+		{command: "env -u HOME -S 'sudo ls'", denied: "sudo"},
+		{command: "nice -n 5 sudo ls", denied: "sudo"},
+		{command: "timeout -s KILL 10s sudo ls", denied: "sudo"},
+		{command: "find . | xargs -0 -I{} sudo rm {}", denied: "sudo"},
+		{command: "nohup env nice dd if=/dev/zero of=x", denied: "dd"},
+		{command: "ls | xargs rm -rf /", denied: "rm -rf /"},
+		{command: "timeout 5 ls", denied: ""},
+	}
+
+	for _, tt := range tests {
+		commands, err := shell.SimpleCommands(tt.command)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.command, err)
+		}
+		entry, denied := matchDenylist(commands, denylist)
+		if entry != tt.denied || denied != (tt.denied != "") {
+			t.Errorf("%s: denylist match = %q, want %q", tt.command, entry, tt.denied)
+		}
+		if tt.denied != "" {
+			continue
+		}
+		if got := isAllowlisted(commands, tt.command, nil); got != tt.allowlisted {
+			t.Errorf("%s: allowlisted = %v, want %v", tt.command, got, tt.allowlisted)
+		}
+	}
+}
+
+func TestBashAllowlistCoversWrappedCommands(t *testing.T) {
+	allowlist := []string{"timeout", "make test"}
+	for command, want := range map[string]bool{
+		"timeout 60 make test":  true,
+		"timeout 60 rm -rf x":   false,
+		"timeout 60 env rm x":   false,
+		"make test":             true,
+		"make install":          false,
+		"timeout 60 git status": true,
+	} {
+		commands, err := shell.SimpleCommands(command)
+		if err != nil {
+			t.Fatalf("%s: %v", command, err)
+		}
+		if got := isAllowlisted(commands, command, allowlist); got != want {
+			t.Errorf("%s: allowlisted = %v, want %v", command, got, want)
+		}
+	}
+}
+
+func TestBashShellReportsUnsupportedRedirects(t *testing.T) {
+	sh := shell.NewPersistentShell(t.TempDir())
+	if _, _, err := sh.Exec(context.Background(), "echo hi >& out.txt"); err == nil {
+		t.Error("echo hi >& out.txt: want an error from the interpreter")
+	}
+	if stdout, _, err := sh.Exec(context.Background(), "echo still running"); err != nil || stdout != "still running\n" {
+		t.Errorf("after the failure: %q, %v", stdout, err)
+	}
+}
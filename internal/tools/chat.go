@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/billie-coop/loco/internal/crash"
 	"github.com/billie-coop/loco/internal/llm"
 	"github.com/billie-coop/loco/internal/session"
 )
@@ -103,6 +104,7 @@ func (t *ChatTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	if t.llmService != nil {
 		// This will handle streaming responses and events asynchronously
 		go func() {
+			defer crash.Recover("chat message")
 			t.llmService.HandleUserMessage(messages, params.Message)
 		}()
 	}
@@ -0,0 +1,259 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCheckpoints is how many checkpoints are kept before the oldest are pruned
+const maxCheckpoints = 50
+
+// CheckpointFile is the pre-edit snapshot of one file
+type CheckpointFile struct {
+	Path      string      `json:"path"`       // Project-relative path
+	Existed   bool        `json:"existed"`    // False if the tool created the file
+	Mode      os.FileMode `json:"mode"`       // Permissions to restore
+	Before    string      `json:"before"`     // Content before the tool ran
+	AfterHash string      `json:"after_hash"` // Hash of the content the tool wrote
+}
+
+// Checkpoint records the files one tool execution changed
+type Checkpoint struct {
+	ID      string           `json:"id"`
+	Time    time.Time        `json:"time"`
+	Tool    string           `json:"tool"`
+	Summary string           `json:"summary"`
+	Files   []CheckpointFile `json:"files"`
+}
+
+// CheckpointStore keeps pre-edit snapshots under .loco/checkpoints so
+// tool-applied changes can be undone, even after a restart.
+type CheckpointStore struct {
+	workingDir string
+	dir        string
+
+	mu          sync.Mutex
+	checkpoints []Checkpoint // Oldest first
+	loaded      bool
+}
+
+// NewCheckpointStore creates a store for files under workingDir
+func NewCheckpointStore(workingDir string) *CheckpointStore {
+	return &CheckpointStore{
+		workingDir: workingDir,
+		dir:        filepath.Join(workingDir, ".loco", "checkpoints"),
+	}
+}
+
+// Record saves a checkpoint for changes that were just written
+func (s *CheckpointStore) Record(tool, summary string, changes []FileChange) error {
+	if s == nil || len(changes) == 0 {
+		return nil
+	}
+
+	cp := Checkpoint{
+		ID:      strconv.FormatInt(time.Now().UnixNano(), 10),
+		Time:    time.Now(),
+		Tool:    tool,
+		Summary: summary,
+	}
+	for _, change := range changes {
+		mode := change.mode
+		if mode == 0 {
+			mode = 0o644
+		}
+		cp.Files = append(cp.Files, CheckpointFile{
+			Path:      change.Path,
+			Existed:   change.Existed,
+			Mode:      mode,
+			Before:    change.Original,
+			AfterHash: contentHash(change.Content),
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path(cp.ID), data, 0o644); err != nil {
+		return err
+	}
+	s.checkpoints = append(s.checkpoints, cp)
+
+	// Prune the oldest
+	for len(s.checkpoints) > maxCheckpoints {
+		os.Remove(s.path(s.checkpoints[0].ID))
+		s.checkpoints = s.checkpoints[1:]
+	}
+	return nil
+}
+
+// List returns checkpoints newest first
+func (s *CheckpointStore) List() []Checkpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+
+	out := make([]Checkpoint, 0, len(s.checkpoints))
+	for i := len(s.checkpoints) - 1; i >= 0; i-- {
+		out = append(out, s.checkpoints[i])
+	}
+	return out
+}
+
+// CheckpointStatus pairs a checkpoint with the files that block undoing it
+type CheckpointStatus struct {
+	Checkpoint
+	Modified []string // Files changed outside the tools since the checkpoint
+}
+
+// Statuses returns checkpoints newest first, with the files that would
+// block /undo from reaching each one. Files touched by newer checkpoints
+// are judged by the content those checkpoints would restore.
+func (s *CheckpointStore) Statuses() []CheckpointStatus {
+	list := s.List()
+	expected := make(map[string]string) // Path -> hash after undoing newer checkpoints
+	out := make([]CheckpointStatus, 0, len(list))
+	for _, cp := range list {
+		status := CheckpointStatus{Checkpoint: cp}
+		for _, file := range cp.Files {
+			current, ok := expected[file.Path]
+			if !ok {
+				current = s.currentHash(file.Path)
+			}
+			if current != file.AfterHash {
+				status.Modified = append(status.Modified, file.Path)
+			}
+			expected[file.Path] = ""
+			if file.Existed {
+				expected[file.Path] = contentHash(file.Before)
+			}
+		}
+		out = append(out, status)
+	}
+	return out
+}
+
+// ModifiedSince returns the files in a checkpoint that no longer hold the
+// content the tool wrote; such a checkpoint cannot be undone safely.
+func (s *CheckpointStore) ModifiedSince(cp Checkpoint) []string {
+	var modified []string
+	for _, file := range cp.Files {
+		if s.currentHash(file.Path) != file.AfterHash {
+			modified = append(modified, file.Path)
+		}
+	}
+	return modified
+}
+
+// currentHash hashes a file's content on disk, or returns "" if it is missing
+func (s *CheckpointStore) currentHash(path string) string {
+	data, err := os.ReadFile(filepath.Join(s.workingDir, path))
+	if err != nil {
+		return ""
+	}
+	return contentHash(string(data))
+}
+
+// Undo reverts the newest n checkpoints, newest first. It stops at the
+// first checkpoint whose files were changed afterwards and returns the
+// checkpoints that were reverted.
+func (s *CheckpointStore) Undo(n int) ([]Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+
+	if len(s.checkpoints) == 0 {
+		return nil, fmt.Errorf("nothing to undo")
+	}
+
+	var undone []Checkpoint
+	for i := 0; i < n && len(s.checkpoints) > 0; i++ {
+		cp := s.checkpoints[len(s.checkpoints)-1]
+		if modified := s.ModifiedSince(cp); len(modified) > 0 {
+			return undone, fmt.Errorf("%s changed after %s %s; revert it by hand", strings.Join(modified, ", "), cp.Tool, cp.Summary)
+		}
+		if err := s.restore(cp); err != nil {
+			return undone, err
+		}
+		os.Remove(s.path(cp.ID))
+		s.checkpoints = s.checkpoints[:len(s.checkpoints)-1]
+		undone = append(undone, cp)
+	}
+	return undone, nil
+}
+
+// restore writes back every file in a checkpoint
+func (s *CheckpointStore) restore(cp Checkpoint) error {
+	for _, file := range cp.Files {
+		absPath, _, err := resolveProjectPath(s.workingDir, file.Path)
+		if err != nil {
+			return err
+		}
+		if !file.Existed {
+			if err := os.Remove(absPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("could not remove %s: %w", file.Path, err)
+			}
+			continue
+		}
+		if err := writeFileAtomic(absPath, []byte(file.Before), file.Mode); err != nil {
+			return fmt.Errorf("could not restore %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+// load reads saved checkpoints on first use
+func (s *CheckpointStore) load() {
+	if s.loaded {
+		return
+	}
+	s.loaded = true
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var cp Checkpoint
+		if err := json.Unmarshal(data, &cp); err != nil || cp.ID == "" {
+			continue
+		}
+		s.checkpoints = append(s.checkpoints, cp)
+	}
+	sort.Slice(s.checkpoints, func(i, j int) bool {
+		return s.checkpoints[i].Time.Before(s.checkpoints[j].Time)
+	})
+}
+
+func (s *CheckpointStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
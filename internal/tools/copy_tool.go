@@ -4,10 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
-	"runtime"
 	"strings"
 
+	"github.com/billie-coop/loco/internal/clipboard"
 	"github.com/billie-coop/loco/internal/llm"
 	"github.com/billie-coop/loco/internal/permission"
 	"github.com/billie-coop/loco/internal/session"
@@ -19,6 +18,9 @@ type CopyTool struct {
 	sessions    *session.Manager
 }
 
+// longTranscript is how many copied messages make /export worth suggesting
+const longTranscript = 10
+
 // CopyParams represents the parameters for the copy tool.
 type CopyParams struct {
 	Count int `json:"count,omitempty"` // Number of messages to copy (default: 1)
@@ -102,7 +104,7 @@ func (t *CopyTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	formatted := formatMessages(messagesToCopy)
 
 	// Copy to clipboard
-	if err := copyToClipboard(formatted); err != nil {
+	if err := clipboard.Copy(formatted); err != nil {
 		return NewTextErrorResponse(fmt.Sprintf("Failed to copy to clipboard: %v", err)), nil
 	}
 
@@ -111,7 +113,11 @@ func (t *CopyTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	if len(messagesToCopy) > 1 {
 		messageWord = "messages"
 	}
-	return NewTextResponse(fmt.Sprintf("📋 Copied %d %s to clipboard!", len(messagesToCopy), messageWord)), nil
+	response := fmt.Sprintf("📋 Copied %d %s to clipboard!", len(messagesToCopy), messageWord)
+	if len(messagesToCopy) >= longTranscript {
+		response += " For long transcripts, /export md|html saves a document instead."
+	}
+	return NewTextResponse(response), nil
 }
 
 // formatMessages formats messages for clipboard.
@@ -138,54 +144,3 @@ func formatMessages(messages []llm.Message) string {
 	}
 	return formatted.String()
 }
-
-// copyToClipboard copies text to the system clipboard.
-func copyToClipboard(text string) error {
-	var cmd *exec.Cmd
-
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("pbcopy")
-	case "linux":
-		// Try xclip first, then xsel
-		if _, err := exec.LookPath("xclip"); err == nil {
-			cmd = exec.Command("xclip", "-selection", "clipboard")
-		} else if _, err := exec.LookPath("xsel"); err == nil {
-			cmd = exec.Command("xsel", "--clipboard", "--input")
-		} else {
-			return fmt.Errorf("no clipboard command found (install xclip or xsel)")
-		}
-	case "windows":
-		cmd = exec.Command("cmd", "/c", "clip")
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
-	}
-
-	// Set up stdin
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return fmt.Errorf("failed to get stdin pipe: %w", err)
-	}
-
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start clipboard command: %w", err)
-	}
-
-	// Write the text
-	if _, err := stdin.Write([]byte(text)); err != nil {
-		return fmt.Errorf("failed to write to clipboard: %w", err)
-	}
-
-	// Close stdin
-	if err := stdin.Close(); err != nil {
-		return fmt.Errorf("failed to close stdin: %w", err)
-	}
-
-	// Wait for command to complete
-	if err := cmd.Wait(); err != nil {
-		return fmt.Errorf("clipboard command failed: %w", err)
-	}
-
-	return nil
-}
\ No newline at end of file
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/analysis"
+)
+
+// DecisionsParams represents parameters for the decisions tool
+type DecisionsParams struct {
+	Action string `json:"action,omitempty"` // "show" or "update"
+}
+
+// decisionsTool shows the decision log built from past conversations
+type decisionsTool struct {
+	workingDir string
+	scan       func(ctx context.Context) (int, error)
+}
+
+// maxDecisionsShown caps how much of the log is shown; the newest entries
+// are kept
+const maxDecisionsShown = 16000
+
+const (
+	// DecisionsToolName is the name of this tool
+	DecisionsToolName = "decisions"
+	// decisionsDescription describes what this tool does
+	decisionsDescription = `Show the project's decision log: choices made in earlier conversations, why they were made and the session each came from.
+
+WHEN TO USE:
+- Before changing something that may have been decided on purpose
+- When the user asks why the project does something a certain way
+
+ACTIONS:
+- show (default): the log, newest entries last
+- update: scan conversations since the last update for new decisions first
+
+The log is kept in .loco/knowledge/decisions.md and grows in the background as conversations settle.`
+)
+
+// NewDecisionsTool creates a new decisions tool. scan logs the decisions of
+// conversations not yet scanned; nil leaves update unavailable.
+func NewDecisionsTool(workingDir string, scan func(ctx context.Context) (int, error)) BaseTool {
+	return &decisionsTool{workingDir: workingDir, scan: scan}
+}
+
+// Name returns the tool name
+func (t *decisionsTool) Name() string {
+	return DecisionsToolName
+}
+
+// Info returns the tool information
+func (t *decisionsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        DecisionsToolName,
+		Description: decisionsDescription,
+		Parameters: map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "What to do (default show)",
+				"enum":        []string{"show", "update"},
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "decisions",
+				Description: "Show the decisions made in past conversations",
+				Examples:    []string{"/decisions", "/decisions update"},
+				Args:        []string{"action"},
+			},
+		},
+	}
+}
+
+// Run shows the log, updating it first when asked
+func (t *decisionsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params DecisionsParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	var note string
+	switch action := strings.TrimSpace(params.Action); action {
+	case "", "show":
+	case "update":
+		if t.scan == nil {
+			return NewTextErrorResponse("the decision log can't be updated without a model"), nil
+		}
+		logged, err := t.scan(ctx)
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("failed to update the decision log: %s", err)), nil
+		}
+		note = fmt.Sprintf("Logged %d new decision(s).\n\n", logged)
+	default:
+		return NewTextErrorResponse(fmt.Sprintf("unknown action %q; use show or update", action)), nil
+	}
+
+	log, err := analysis.LoadDecisions(t.workingDir)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to read the decision log: %s", err)), nil
+	}
+	if strings.TrimSpace(log) == "" {
+		return NewTextResponse(note + "No decisions logged yet: they are added as conversations settle, or run /decisions update"), nil
+	}
+	if len(log) > maxDecisionsShown {
+		log = strings.ToValidUTF8(log[len(log)-maxDecisionsShown:], "")
+		if i := strings.Index(log, "\n## "); i >= 0 {
+			log = log[i+1:]
+		}
+		log = "... (older decisions omitted)\n\n" + log
+	}
+	return NewTextResponse(note + strings.TrimSpace(log)), nil
+}
@@ -0,0 +1,281 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/permission"
+)
+
+// EditFileParams represents parameters for the edit_file tool
+type EditFileParams struct {
+	Path  string      `json:"path"`            // File to edit, relative to the project
+	Diff  string      `json:"diff,omitempty"`  // Unified diff or SEARCH/REPLACE blocks
+	Edits []EditBlock `json:"edits,omitempty"` // Structured search/replace edits
+}
+
+// editFileTool applies targeted edits to a file
+type editFileTool struct {
+	permissions permission.Service
+	workingDir  string
+	checkpoints *CheckpointStore
+}
+
+const (
+	// EditFileToolName is the name of this tool
+	EditFileToolName = "edit_file"
+	// editFileDescription describes what this tool does
+	editFileDescription = `Edit a file by applying a patch instead of rewriting it.
+
+ACCEPTED FORMATS (use one):
+- diff: a unified diff with @@ -start,count +start,count @@ hunks
+- diff: SEARCH/REPLACE blocks:
+    <<<<<<< SEARCH
+    exact existing lines
+    =======
+    replacement lines
+    >>>>>>> REPLACE
+- edits: [{"old_text": "...", "new_text": "...", "replace_all": false}]
+
+RULES:
+- Search text must match the file exactly (including indentation) and be unique
+- Diff context lines must match the current file
+- Nothing is written unless every edit applies
+- The write is atomic: the file is replaced in one step
+
+OUTPUT:
+- Lines added and removed`
+)
+
+// NewEditFileTool creates a new edit_file tool
+func NewEditFileTool(permissions permission.Service, workingDir string, checkpoints *CheckpointStore) BaseTool {
+	return &editFileTool{
+		permissions: permissions,
+		workingDir:  workingDir,
+		checkpoints: checkpoints,
+	}
+}
+
+// Name returns the tool name
+func (t *editFileTool) Name() string {
+	return EditFileToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: calls may wait on a permission prompt
+func (t *editFileTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *editFileTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        EditFileToolName,
+		Description: editFileDescription,
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Path of the file to edit, relative to the project",
+			},
+			"diff": map[string]any{
+				"type":        "string",
+				"description": "Unified diff or SEARCH/REPLACE blocks to apply",
+			},
+			"edits": map[string]any{
+				"type":        "array",
+				"description": "Search/replace edits: objects with old_text, new_text and optional replace_all",
+			},
+		},
+		Required: []string{"path"},
+	}
+}
+
+// TouchedPaths returns the file the call edits
+func (t *editFileTool) TouchedPaths(call ToolCall) []string {
+	var params EditFileParams
+	if json.Unmarshal([]byte(call.Input), &params) != nil {
+		return nil
+	}
+	return []string{params.Path}
+}
+
+// Run applies the edits
+func (t *editFileTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params EditFileParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	if params.Path == "" {
+		return NewTextErrorResponse("path parameter is required"), nil
+	}
+	if params.Diff == "" && len(params.Edits) == 0 {
+		return NewTextErrorResponse("provide either diff or edits"), nil
+	}
+	if params.Diff != "" && len(params.Edits) > 0 {
+		return NewTextErrorResponse("provide diff or edits, not both"), nil
+	}
+
+	absPath, relPath, err := resolveProjectPath(t.workingDir, params.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	var original string
+	mode := os.FileMode(0o644)
+	exists := true
+	if info, statErr := os.Stat(absPath); statErr == nil {
+		if info.IsDir() {
+			return NewTextErrorResponse(fmt.Sprintf("%s is a directory", relPath)), nil
+		}
+		mode = info.Mode().Perm()
+		data, readErr := os.ReadFile(absPath)
+		if readErr != nil {
+			return NewTextErrorResponse(fmt.Sprintf("could not read %s: %s", relPath, readErr)), nil
+		}
+		original = string(data)
+	} else if os.IsNotExist(statErr) {
+		exists = false
+	} else {
+		return NewTextErrorResponse(fmt.Sprintf("could not stat %s: %s", relPath, statErr)), nil
+	}
+
+	updated, format, err := t.apply(original, params)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("edit not applied to %s: %s", relPath, err)), nil
+	}
+	if !exists && original == "" && format != "unified diff" {
+		return NewTextErrorResponse(fmt.Sprintf("%s does not exist; search/replace edits need an existing file", relPath)), nil
+	}
+	if updated == original {
+		return NewTextResponse(fmt.Sprintf("No changes: %s already matches the requested edit", relPath)), nil
+	}
+
+	added, removed := countLineChanges(original, updated)
+
+	if t.permissions != nil {
+		sessionID, _ := GetContextValues(ctx)
+		granted := t.permissions.Request(ctx, permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			ToolCallID:  call.ID,
+			ToolName:    EditFileToolName,
+			Action:      "write",
+			Path:        relPath,
+			Description: fmt.Sprintf("Edit %s (+%d -%d lines)", relPath, added, removed),
+			Params:      params,
+			Diff:        fileDiff(relPath, exists, original, updated),
+		})
+		if !granted {
+			return NewTextErrorResponse(fmt.Sprintf("permission denied: %s was not modified", relPath)), nil
+		}
+	}
+
+	if err := writeFileAtomic(absPath, []byte(updated), mode); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to write %s: %s", relPath, err)), nil
+	}
+	_ = t.checkpoints.Record(EditFileToolName, relPath, []FileChange{{
+		Path:     relPath,
+		Content:  updated,
+		Original: original,
+		Existed:  exists,
+		mode:     mode,
+	}})
+
+	verb := "Edited"
+	if !exists {
+		verb = "Created"
+	}
+	return WithResponseMetadata(
+		NewTextResponse(fmt.Sprintf("%s %s (+%d -%d lines, %s)", verb, relPath, added, removed, format)),
+		map[string]any{
+			"path":          relPath,
+			"lines_added":   added,
+			"lines_removed": removed,
+			"format":        format,
+		},
+	), nil
+}
+
+// apply produces the new content and names the format that was used
+func (t *editFileTool) apply(original string, params EditFileParams) (string, string, error) {
+	if len(params.Edits) > 0 {
+		updated, err := applyEdits(original, params.Edits)
+		return updated, "search/replace", err
+	}
+
+	if isSearchReplaceBlocks(params.Diff) {
+		edits, err := parseSearchReplaceBlocks(params.Diff)
+		if err != nil {
+			return "", "", err
+		}
+		updated, err := applyEdits(original, edits)
+		return updated, "search/replace", err
+	}
+
+	hunks, err := parseUnifiedDiff(params.Diff)
+	if err != nil {
+		return "", "", err
+	}
+	updated, err := applyUnifiedDiff(original, hunks)
+	return updated, "unified diff", err
+}
+
+// resolveProjectPath returns the absolute and project-relative forms of a
+// path, refusing anything outside the working directory.
+func resolveProjectPath(workingDir, path string) (string, string, error) {
+	absPath := path
+	if !filepath.IsAbs(absPath) {
+		absPath = filepath.Join(workingDir, path)
+	}
+	absPath = filepath.Clean(absPath)
+
+	rel, err := filepath.Rel(workingDir, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", "", fmt.Errorf("path %s is outside the project", path)
+	}
+	return absPath, rel, nil
+}
+
+// writeFileAtomic writes data to a temp file in the same directory and
+// renames it over the target, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".loco-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
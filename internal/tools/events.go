@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/tui/events"
+)
+
+// EventsParams represents parameters for the events tool
+type EventsParams struct {
+	Filter string `json:"filter,omitempty"` // Event type or pattern such as tool.*
+	Count  int    `json:"count,omitempty"`  // Number of recent events to list
+}
+
+// eventsTool shows what is flowing through the event broker
+type eventsTool struct {
+	broker *events.Broker
+}
+
+const (
+	// EventsToolName is the name of this tool
+	EventsToolName = "events"
+	// eventsDescription describes what this tool does
+	eventsDescription = `Debug view of the event broker: recent events, subscribers and topics.
+
+OUTPUT:
+- Recent events with time, type and payload, oldest first
+- Each subscriber's patterns, buffer use, backpressure policy and drops
+- Registered topics with their payload type and publish count`
+
+	// maxEventPayloadLength caps how much of a payload is shown
+	maxEventPayloadLength = 120
+)
+
+// NewEventsTool creates a new events tool
+func NewEventsTool(broker *events.Broker) BaseTool {
+	return &eventsTool{broker: broker}
+}
+
+// Name returns the tool name
+func (t *eventsTool) Name() string {
+	return EventsToolName
+}
+
+// Info returns the tool information
+func (t *eventsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        EventsToolName,
+		Description: eventsDescription,
+		Parameters: map[string]any{
+			"filter": map[string]any{
+				"type":        "string",
+				"description": "Only list events of this type; a trailing * matches a prefix (e.g. tool.*)",
+			},
+			"count": map[string]any{
+				"type":        "integer",
+				"description": "Number of recent events to list (default: 20)",
+				"minimum":     1,
+				"maximum":     200,
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "events",
+				Description: "Show recent events and broker subscribers",
+				Examples:    []string{"/events", "/events tool.*", "/events stream.* 50"},
+				Args:        []string{"filter", "count"},
+			},
+		},
+	}
+}
+
+// Run lists recent events, subscribers and topics
+func (t *eventsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params EventsParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	if params.Count <= 0 {
+		params.Count = 20
+	}
+	if params.Count > 200 {
+		params.Count = 200
+	}
+	if t.broker == nil {
+		return NewTextErrorResponse("event broker is not available"), nil
+	}
+
+	counts := t.broker.PublishedCounts()
+	var total uint64
+	for _, count := range counts {
+		total += count
+	}
+
+	var sb strings.Builder
+	records := t.broker.Recent(params.Count, events.EventType(params.Filter))
+	heading := fmt.Sprintf("Recent events (%d shown, %d published", len(records), total)
+	if params.Filter != "" {
+		heading += ", filter " + params.Filter
+	}
+	sb.WriteString(heading + "):\n")
+	if len(records) == 0 {
+		sb.WriteString("  (none)\n")
+	}
+	for _, record := range records {
+		line := fmt.Sprintf("  %s  %-24s %s", record.Time.Format("15:04:05.000"), record.Event.Type, summarizePayload(record.Event.Payload))
+		if record.Mismatch {
+			line += fmt.Sprintf("  ⚠ unexpected payload %T", record.Event.Payload)
+		}
+		sb.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+
+	sb.WriteString("\nSubscribers:\n")
+	for _, sub := range t.broker.Subscriptions() {
+		name := sub.Name
+		if name == "" {
+			name = "(unnamed)"
+		}
+		patterns := make([]string, len(sub.Patterns))
+		for i, pattern := range sub.Patterns {
+			patterns[i] = string(pattern)
+		}
+		fmt.Fprintf(&sb, "  %s [%s] %d/%d buffered, %s, %d delivered, %d dropped\n",
+			name, strings.Join(patterns, " "), sub.Buffered, sub.Capacity, sub.Backpressure, sub.Delivered, sub.Dropped)
+	}
+
+	sb.WriteString("\nTopics:\n")
+	registered := make(map[events.EventType]string)
+	for _, topic := range events.Topics() {
+		registered[topic.Type] = topic.Payload
+	}
+	types := make([]string, 0, len(counts)+len(registered))
+	for eventType := range registered {
+		types = append(types, string(eventType))
+	}
+	for eventType := range counts {
+		if _, ok := registered[eventType]; !ok {
+			types = append(types, string(eventType))
+		}
+	}
+	sort.Strings(types)
+	for _, eventType := range types {
+		payload := registered[events.EventType(eventType)]
+		if payload == "" {
+			payload = "(untyped)"
+		}
+		fmt.Fprintf(&sb, "  %-26s %-34s %d\n", eventType, payload, counts[events.EventType(eventType)])
+	}
+
+	return NewTextResponse(strings.TrimRight(sb.String(), "\n")), nil
+}
+
+// summarizePayload renders a payload on one short line
+func summarizePayload(payload any) string {
+	if payload == nil {
+		return ""
+	}
+	text := strings.Join(strings.Fields(fmt.Sprintf("%+v", payload)), " ")
+	if runes := []rune(text); len(runes) > maxEventPayloadLength {
+		text = string(runes[:maxEventPayloadLength]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,425 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+// ExplainParams represents parameters for the explain tool
+type ExplainParams struct {
+	Target string `json:"target"` // path, path:line or path:start-end
+}
+
+// explainTool explains a region of code with its callers and callees
+type explainTool struct {
+	sidecarService sidecar.Service
+	llmClient      llm.Client
+	workingDir     string
+}
+
+// Limits on what an explanation reads
+const (
+	maxExplainLines      = 300  // Lines of the region sent to the model
+	maxExplainSymbols    = 3    // Declared names looked up as callers
+	maxExplainCalls      = 6    // Called names looked up as callees
+	maxExplainReferences = 5    // Callers, and callees, kept
+	maxExplainRefChars   = 1500 // Of each caller and callee in the prompt
+)
+
+const (
+	// ExplainToolName is the name of this tool
+	ExplainToolName = "explain"
+	// explainDescription describes what this tool does
+	explainDescription = `Explain a region of code: what it does step by step, how its callers use it and what its callees do.
+
+TARGET:
+- path: the whole file, up to 300 lines
+- path:line or path:start-end: that region
+
+WHAT THIS DOES:
+- Reads the region and finds its callers and callees in the RAG index
+- Has the model explain it with that context
+- Shows the result as an explanation card
+
+LIMITATIONS:
+- Callers and callees come from the index; without one the region is explained alone`
+
+	explainSystemPrompt = `You explain code to a developer who is about to change it.
+Use the related code only to say how the region is used and what it relies on; don't explain it for its own sake.
+
+Reply with JSON only:
+{"summary": "<what the region is for, in one or two sentences>",
+ "steps": ["<what it does, in order, one step per item>"],
+ "notes": ["<assumptions, side effects, error handling and pitfalls worth knowing before changing it>"],
+ "related": [{"ref": "<C1, D1, ...>", "note": "<how it relates to the region, in one sentence>"}]}`
+)
+
+var (
+	// declarationPattern finds names declared at the start of a line in
+	// most languages: func (r T) Name, def name, class Name, fn name, ...
+	declarationPattern = regexp.MustCompile(`(?m)^\s*(?:export\s+)?(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:func|def|class|function|fn|type|interface|struct)\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)`)
+	// callPattern finds names followed by an argument list
+	callPattern = regexp.MustCompile(`([A-Za-z_]\w*)\s*\(`)
+	// targetRangePattern is the :line or :start-end suffix of a target
+	targetRangePattern = regexp.MustCompile(`:(\d+)(?:-(\d+))?$`)
+)
+
+// notCalls are keywords and builtins that look like calls
+var notCalls = map[string]bool{
+	"if": true, "for": true, "switch": true, "while": true, "return": true, "func": true, "function": true,
+	"catch": true, "def": true, "fn": true, "make": true, "len": true, "cap": true, "append": true, "new": true,
+	"panic": true, "recover": true, "print": true, "println": true, "string": true, "int": true, "byte": true,
+	"rune": true, "float64": true, "int64": true, "error": true, "map": true, "copy": true, "delete": true,
+	"close": true, "min": true, "max": true, "super": true, "typeof": true, "require": true, "range": true,
+}
+
+// NewExplainTool creates a new explain tool. llmClient may be nil until a
+// model is configured, and sidecarService when RAG is off.
+func NewExplainTool(sidecarService sidecar.Service, llmClient llm.Client, workingDir string) BaseTool {
+	return &explainTool{
+		sidecarService: sidecarService,
+		llmClient:      llmClient,
+		workingDir:     workingDir,
+	}
+}
+
+// Name returns the tool name
+func (t *explainTool) Name() string {
+	return ExplainToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: explaining waits on the model
+func (t *explainTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *explainTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ExplainToolName,
+		Description: explainDescription,
+		Parameters: map[string]any{
+			"target": map[string]any{
+				"type":        "string",
+				"description": "A file, optionally with a line or a line range: path, path:42 or path:10-60",
+			},
+		},
+		Required: []string{"target"},
+		Commands: []CommandInfo{
+			{
+				Command:     "explain",
+				Description: "Explain a file or a range of lines",
+				Examples:    []string{"/explain internal/app/app.go", "/explain internal/tools/tools.go:180-240"},
+				Args:        []string{"target"},
+			},
+		},
+	}
+}
+
+// Run reads the region, gathers its callers and callees and has the model
+// explain it
+func (t *explainTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	if t.llmClient == nil {
+		return NewTextErrorResponse("No model available to explain with - check your LM Studio connection"), nil
+	}
+	var params ExplainParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	params.Target = strings.TrimSpace(params.Target)
+	if params.Target == "" {
+		return NewTextErrorResponse("target parameter is required"), nil
+	}
+
+	explanation, region, err := t.readRegion(params.Target)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	publish := GetProgressPublisher(ctx)
+	publish("Finding callers and callees", 0, 0, "")
+	sources := map[string]string{}
+	if t.sidecarService != nil {
+		explanation.Callers, explanation.Callees = t.related(ctx, explanation, region, sources)
+	}
+
+	publish("Explaining", 0, 0, "")
+	reply, err := t.llmClient.Complete(ctx, []llm.Message{
+		{Role: "system", Content: explainSystemPrompt},
+		{Role: "user", Content: explainPrompt(explanation, region, sources)},
+	})
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("Failed to explain: %s", err)), nil
+	}
+	parseExplanation(reply, &explanation)
+
+	return WithResponseMetadata(NewTextResponse(FormatExplanation(explanation)), explanation), nil
+}
+
+// readRegion resolves the target and returns the explanation to fill in
+// with the region's source
+func (t *explainTool) readRegion(target string) (llm.Explanation, string, error) {
+	path, start, end := target, 0, 0
+	if m := targetRangePattern.FindStringSubmatch(target); m != nil {
+		path = strings.TrimSuffix(target, m[0])
+		start, _ = strconv.Atoi(m[1])
+		end = start
+		if m[2] != "" {
+			end, _ = strconv.Atoi(m[2])
+		}
+	}
+	absPath, relPath, err := resolveProjectPath(t.workingDir, path)
+	if err != nil {
+		return llm.Explanation{}, "", err
+	}
+	data, err := os.ReadFile(absPath)
+	if os.IsNotExist(err) {
+		return llm.Explanation{}, "", fmt.Errorf("%s does not exist", relPath)
+	}
+	if err != nil {
+		return llm.Explanation{}, "", fmt.Errorf("failed to read %s: %w", relPath, err)
+	}
+	lines := splitLines(string(data))
+	if len(lines) == 0 {
+		return llm.Explanation{}, "", fmt.Errorf("%s is empty", relPath)
+	}
+
+	if start == 0 {
+		start, end = 1, len(lines)
+	}
+	if start < 1 || end < start || start > len(lines) {
+		return llm.Explanation{}, "", fmt.Errorf("%s has %d lines; lines %d-%d are not in it", relPath, len(lines), start, end)
+	}
+	end = min(end, len(lines), start+maxExplainLines-1)
+	return llm.Explanation{Path: relPath, StartLine: start, EndLine: end}, strings.Join(lines[start-1:end], "\n"), nil
+}
+
+// related finds the chunks that call what the region declares, and the
+// chunks declaring what it calls, recording their content in sources by
+// location
+func (t *explainTool) related(ctx context.Context, e llm.Explanation, region string, sources map[string]string) ([]llm.CodeReference, []llm.CodeReference) {
+	declared := declaredNames(region)
+	inRegion := func(ref llm.CodeReference) bool {
+		return ref.Path == e.Path && ref.StartLine <= e.EndLine && ref.EndLine >= e.StartLine
+	}
+
+	var callers []llm.CodeReference
+	for _, name := range declared[:min(maxExplainSymbols, len(declared))] {
+		results, err := t.sidecarService.QuerySimilar(ctx, name, 8)
+		if err != nil {
+			break
+		}
+		calls := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\s*\(`)
+		for _, result := range results {
+			ref, content := t.reference(result)
+			if _, seen := sources[ref.Location()]; seen || inRegion(ref) || !calls.MatchString(content) || declares(ref, content, name) {
+				continue
+			}
+			sources[ref.Location()] = content
+			callers = append(callers, ref)
+		}
+	}
+
+	var callees []llm.CodeReference
+	for _, name := range calledNames(region, declared) {
+		if len(callees) == maxExplainReferences {
+			break
+		}
+		results, err := t.sidecarService.QuerySimilar(ctx, name, 4)
+		if err != nil {
+			break
+		}
+		for _, result := range results {
+			ref, content := t.reference(result)
+			if _, seen := sources[ref.Location()]; !seen && !inRegion(ref) && declares(ref, content, name) {
+				sources[ref.Location()] = content
+				callees = append(callees, ref)
+				break
+			}
+		}
+	}
+	return callers[:min(maxExplainReferences, len(callers))], callees
+}
+
+// reference converts a search result, making its path relative to the
+// project, and returns the chunk's content with it
+func (t *explainTool) reference(result sidecar.SimilarDocument) (llm.CodeReference, string) {
+	ref := llm.CodeReference{Path: result.Path}
+	if _, rel, err := resolveProjectPath(t.workingDir, result.Path); err == nil {
+		ref.Path = rel
+	}
+	ref.StartLine, _ = result.Metadata["start_line"].(int)
+	ref.EndLine, _ = result.Metadata["end_line"].(int)
+	ref.Symbol, _ = result.Metadata["symbol"].(string)
+	return ref, result.Content
+}
+
+// declares reports whether a chunk declares name, by its indexed symbol or
+// by its source when it was chunked by lines
+func declares(ref llm.CodeReference, content, name string) bool {
+	for _, symbol := range strings.Split(ref.Symbol, ", ") {
+		if symbol == name || strings.HasSuffix(symbol, "."+name) {
+			return true
+		}
+	}
+	for _, declared := range declaredNames(content) {
+		if declared == name {
+			return true
+		}
+	}
+	return false
+}
+
+// declaredNames lists the names declared in source, in order
+func declaredNames(source string) []string {
+	var names []string
+	for _, m := range declarationPattern.FindAllStringSubmatch(source, -1) {
+		if !slices.Contains(names, m[1]) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// calledNames lists up to maxExplainCalls names source calls that it
+// doesn't declare, in order of first call
+func calledNames(source string, declared []string) []string {
+	var names []string
+	for _, m := range callPattern.FindAllStringSubmatchIndex(source, -1) {
+		name := source[m[2]:m[3]]
+		if len(name) < 3 || notCalls[name] || slices.Contains(declared, name) || slices.Contains(names, name) {
+			continue
+		}
+		if prefix := strings.TrimRight(source[:m[2]], " \t"); strings.HasSuffix(prefix, "func") || strings.HasSuffix(prefix, "def") {
+			continue // A declaration the pattern missed, e.g. a nested def
+		}
+		names = append(names, name)
+		if len(names) == maxExplainCalls {
+			break
+		}
+	}
+	return names
+}
+
+// explainPrompt gives the model the region followed by its related code,
+// labelled C1.. for callers and D1.. for callees
+func explainPrompt(e llm.Explanation, region string, sources map[string]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Explain %s:%d-%d:\n\n```\n%s\n```\n", e.Path, e.StartLine, e.EndLine, region)
+	for _, group := range []struct {
+		title, label string
+		refs         []llm.CodeReference
+	}{
+		{"Callers", "C", e.Callers},
+		{"Callees", "D", e.Callees},
+	} {
+		if len(group.refs) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n", group.title)
+		for i, ref := range group.refs {
+			fmt.Fprintf(&b, "\n[%s%d] %s", group.label, i+1, ref.Location())
+			if ref.Symbol != "" {
+				b.WriteString(" (" + ref.Symbol + ")")
+			}
+			b.WriteString("\n```\n" + truncateOutput(sources[ref.Location()], maxExplainRefChars) + "\n```\n")
+		}
+	}
+	return b.String()
+}
+
+// parseExplanation fills e from the model's JSON reply, or takes the reply
+// as the summary when it isn't JSON
+func parseExplanation(reply string, e *llm.Explanation) {
+	var parsed struct {
+		Summary string   `json:"summary"`
+		Steps   []string `json:"steps"`
+		Notes   []string `json:"notes"`
+		Related []struct {
+			Ref  string `json:"ref"`
+			Note string `json:"note"`
+		} `json:"related"`
+	}
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end <= start || json.Unmarshal([]byte(reply[start:end+1]), &parsed) != nil {
+		e.Summary = strings.TrimSpace(reply)
+		return
+	}
+	e.Summary = strings.TrimSpace(parsed.Summary)
+	e.Steps = nonEmpty(parsed.Steps)
+	e.Notes = nonEmpty(parsed.Notes)
+	for _, related := range parsed.Related {
+		ref := strings.ToUpper(strings.Trim(strings.TrimSpace(related.Ref), "[]"))
+		refs := e.Callers
+		if strings.HasPrefix(ref, "D") {
+			refs = e.Callees
+		}
+		if i, err := strconv.Atoi(strings.TrimLeft(ref, "CD")); err == nil && i >= 1 && i <= len(refs) {
+			refs[i-1].Note = strings.TrimSpace(related.Note)
+		}
+	}
+}
+
+// nonEmpty trims items and drops the blank ones
+func nonEmpty(items []string) []string {
+	var kept []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// FormatExplanation renders an explanation as Markdown, the form it takes
+// in the conversation and in the terminal
+func FormatExplanation(e llm.Explanation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s:%d-%d**\n\n%s\n", e.Path, e.StartLine, e.EndLine, e.Summary)
+	if len(e.Steps) > 0 {
+		b.WriteString("\n**Step by step**\n\n")
+		for i, step := range e.Steps {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+		}
+	}
+	for _, group := range []struct {
+		title string
+		refs  []llm.CodeReference
+	}{
+		{"Called by", e.Callers},
+		{"Calls", e.Callees},
+	} {
+		if len(group.refs) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n**%s**\n\n", group.title)
+		for _, ref := range group.refs {
+			fmt.Fprintf(&b, "- `%s`", ref.Location())
+			if ref.Symbol != "" {
+				fmt.Fprintf(&b, " %s", ref.Symbol)
+			}
+			if ref.Note != "" {
+				fmt.Fprintf(&b, ": %s", ref.Note)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(e.Notes) > 0 {
+		b.WriteString("\n**Notes**\n\n")
+		for _, note := range e.Notes {
+			fmt.Fprintf(&b, "- %s\n", note)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,575 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+// FetchDocsParams represents parameters for the fetch_docs tool
+type FetchDocsParams struct {
+	URL     string `json:"url"`               // https:// is assumed when the scheme is missing
+	Refresh bool   `json:"refresh,omitempty"` // Fetch again even when a fresh copy is cached
+	Embed   bool   `json:"embed,omitempty"`   // Add the page to the vector store
+}
+
+// fetchDocsTool downloads documentation pages as plain text
+type fetchDocsTool struct {
+	workingDir     string
+	sidecarService sidecar.Service
+	configManager  *config.Manager
+}
+
+// cachedDoc describes a fetched page kept under .loco/cache/web
+type cachedDoc struct {
+	URL         string    `json:"url"`
+	FinalURL    string    `json:"final_url,omitempty"` // After redirects, when different
+	Title       string    `json:"title,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Fetched     time.Time `json:"fetched"`
+	Chars       int       `json:"chars"`
+}
+
+const (
+	// FetchDocsToolName is the name of this tool
+	FetchDocsToolName = "fetch_docs"
+	// fetchDocsDescription describes what this tool does
+	fetchDocsDescription = `Fetch a documentation page from the web as readable text.
+
+WHAT THIS DOES:
+- Downloads the page and extracts its text, dropping navigation, scripts and styling
+- Keeps headings, lists and code blocks in a markdown-like form
+- Caches the text under .loco/cache/web, so asking again is instant
+- With embed, adds the page to the vector store so rag and ask_codebase can find it
+
+WHEN TO USE:
+- To read the documentation of a library or API the project uses
+- Embed pages that will be needed again during the session
+
+SAFETY:
+- Only hosts in docs.allowed_hosts in the config can be fetched; redirects elsewhere are refused
+
+OUTPUT:
+- Title, source and whether the copy came from the cache
+- The page text, truncated when very long (the cache keeps all of it)`
+
+	// docsCacheDir is where fetched pages are kept, under the project
+	docsCacheDir     = ".loco/cache/web"
+	docsFetchTimeout = 30 * time.Second
+	// maxDocsReadBytes caps what is downloaded of a page
+	maxDocsReadBytes = 5 << 20
+)
+
+// NewFetchDocsTool creates a new fetch_docs tool
+func NewFetchDocsTool(workingDir string, sidecarService sidecar.Service, configManager *config.Manager) BaseTool {
+	return &fetchDocsTool{workingDir: workingDir, sidecarService: sidecarService, configManager: configManager}
+}
+
+// Name returns the tool name
+func (t *fetchDocsTool) Name() string {
+	return FetchDocsToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: pages download slowly
+func (t *fetchDocsTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *fetchDocsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        FetchDocsToolName,
+		Description: fetchDocsDescription,
+		Parameters: map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": "Page to fetch, e.g. https://pkg.go.dev/net/http",
+			},
+			"refresh": map[string]any{
+				"type":        "boolean",
+				"description": "Fetch again even when the cached copy is still fresh",
+			},
+			"embed": map[string]any{
+				"type":        "boolean",
+				"description": "Add the page to the vector store for semantic search",
+			},
+		},
+		Required: []string{"url"},
+		Commands: []CommandInfo{
+			{
+				Command:     "docs",
+				Description: "Fetch a documentation page as text",
+				Examples:    []string{"/docs https://pkg.go.dev/net/http"},
+				Args:        []string{"url"},
+			},
+		},
+	}
+}
+
+// Run fetches the page, or reads it from the cache, and embeds it if asked
+func (t *fetchDocsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params FetchDocsParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	raw := strings.TrimSpace(params.URL)
+	if raw != "" && !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	target, err := parseRequestURL(raw)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	// The fragment names a spot on the page, not a different page
+	target.Fragment = ""
+
+	cfg := t.docsConfig()
+	if !hostAllowed(target, cfg.AllowedHosts) {
+		return NewTextErrorResponse(fmt.Sprintf("%s is not an allowed documentation host; add it to docs.allowed_hosts in .loco/config.jsonc to allow it", target.Host)), nil
+	}
+
+	publish := GetProgressPublisher(ctx)
+	cacheBase := filepath.Join(t.workingDir, docsCacheDir, docsCacheKey(target.String()))
+	meta, text, cacheErr := readCachedDoc(cacheBase)
+	ttl := time.Duration(cfg.CacheTTLHours) * time.Hour
+	fresh := cacheErr == nil && time.Since(meta.Fetched) < ttl
+	source := "cached " + formatAge(time.Since(meta.Fetched)) + " ago"
+
+	if params.Refresh || !fresh {
+		publish("fetching", 0, 0, target.String())
+		fetched, fetchedText, err := fetchDoc(ctx, target.String(), cfg.AllowedHosts)
+		switch {
+		case err == nil:
+			meta, text, source = fetched, fetchedText, "fetched now"
+			if err := writeCachedDoc(cacheBase, meta, text); err != nil {
+				return NewTextErrorResponse(fmt.Sprintf("caching the page failed: %s", err)), nil
+			}
+		case cacheErr == nil:
+			// A stale copy beats none when the site is unreachable
+			source = fmt.Sprintf("cached %s ago; fetching again failed: %s", formatAge(time.Since(meta.Fetched)), err)
+		default:
+			return NewTextErrorResponse(err.Error()), nil
+		}
+	}
+
+	embedded := false
+	if params.Embed {
+		if t.sidecarService == nil {
+			return NewTextErrorResponse("the vector store is not available; fetch without embed to read the page"), nil
+		}
+		publish("embedding", 0, 0, target.String())
+		if err := t.sidecarService.UpdateFile(ctx, cacheBase+".md"); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("embedding the page failed: %s", err)), nil
+		}
+		embedded = true
+	}
+
+	var sb strings.Builder
+	if meta.Title != "" {
+		sb.WriteString(meta.Title + "\n")
+	}
+	fmt.Fprintf(&sb, "Source: %s (%s, %d chars)\n", meta.URL, source, meta.Chars)
+	if embedded {
+		sb.WriteString("Embedded into the vector store; rag and ask_codebase will find it.\n")
+	}
+	body := text
+	if cfg.MaxChars > 0 && len(body) > cfg.MaxChars {
+		cut := cfg.MaxChars
+		for cut > 0 && !utf8.RuneStart(body[cut]) {
+			cut--
+		}
+		rel, _ := filepath.Rel(t.workingDir, cacheBase+".md")
+		body = fmt.Sprintf("%s\n\n... [truncated; the full text is in %s] ...", strings.TrimRight(body[:cut], "\n"), filepath.ToSlash(rel))
+	}
+	sb.WriteString("\n" + body)
+
+	return WithResponseMetadata(NewTextResponse(sb.String()), map[string]any{
+		"url":      meta.URL,
+		"title":    meta.Title,
+		"cached":   source != "fetched now",
+		"fetched":  meta.Fetched,
+		"chars":    meta.Chars,
+		"embedded": embedded,
+		"path":     cacheBase + ".md",
+	}), nil
+}
+
+// docsConfig returns the fetch_docs settings, falling back to defaults
+func (t *fetchDocsTool) docsConfig() config.DocsConfig {
+	cfg := config.DefaultConfig().Docs
+	if t.configManager != nil {
+		if current := t.configManager.Get(); current != nil {
+			if current.Docs.AllowedHosts != nil {
+				cfg.AllowedHosts = current.Docs.AllowedHosts
+			}
+			if current.Docs.CacheTTLHours > 0 {
+				cfg.CacheTTLHours = current.Docs.CacheTTLHours
+			}
+			if current.Docs.MaxChars > 0 {
+				cfg.MaxChars = current.Docs.MaxChars
+			}
+		}
+	}
+	return cfg
+}
+
+// docsCacheKey names a page's cache files after its URL
+func docsCacheKey(pageURL string) string {
+	sum := sha256.Sum256([]byte(pageURL))
+	return hex.EncodeToString(sum[:8])
+}
+
+// readCachedDoc loads a page's cache files; base is their path without
+// extension
+func readCachedDoc(base string) (cachedDoc, string, error) {
+	var meta cachedDoc
+	data, err := os.ReadFile(base + ".json")
+	if err != nil {
+		return meta, "", err
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, "", err
+	}
+	content, err := os.ReadFile(base + ".md")
+	if err != nil {
+		return meta, "", err
+	}
+	// The text file starts with a header for the embedder's sake
+	_, text, _ := strings.Cut(strings.TrimSuffix(string(content), "\n"), "\n\n")
+	return meta, text, nil
+}
+
+// writeCachedDoc saves a page as text, headed by its title and source so
+// embedded chunks say where they came from, and its details as JSON
+func writeCachedDoc(base string, meta cachedDoc, text string) error {
+	if err := os.MkdirAll(filepath.Dir(base), 0o755); err != nil {
+		return err
+	}
+	title := meta.Title
+	if title == "" {
+		title = meta.URL
+	}
+	content := fmt.Sprintf("# %s\nSource: %s\n\n%s\n", title, meta.URL, text)
+	if err := os.WriteFile(base+".md", []byte(content), 0o644); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(base+".json", data, 0o644)
+}
+
+// fetchDoc downloads a page and extracts its text, following redirects
+// only to allowed hosts
+func fetchDoc(ctx context.Context, pageURL string, allowed []string) (cachedDoc, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, docsFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return cachedDoc{}, "", fmt.Errorf("invalid request: %s", err)
+	}
+	req.Header.Set("User-Agent", "loco-fetch-docs/1.0")
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9, text/markdown;q=0.9, */*;q=0.5")
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxHTTPRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxHTTPRedirects)
+			}
+			if !hostAllowed(req.URL, allowed) {
+				return fmt.Errorf("redirect to %s is not an allowed documentation host", req.URL.Host)
+			}
+			return nil
+		},
+	}
+	defer client.CloseIdleConnections()
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return cachedDoc{}, "", fmt.Errorf("fetching %s timed out after %s", pageURL, docsFetchTimeout)
+		}
+		return cachedDoc{}, "", fmt.Errorf("fetching %s failed: %s", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return cachedDoc{}, "", fmt.Errorf("fetching %s failed: %s", pageURL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocsReadBytes))
+	if err != nil {
+		return cachedDoc{}, "", fmt.Errorf("reading %s failed: %s", pageURL, err)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" {
+		mediaType = http.DetectContentType(body)
+		mediaType, _, _ = mime.ParseMediaType(mediaType)
+	}
+
+	meta := cachedDoc{URL: pageURL, ContentType: mediaType, Fetched: time.Now()}
+	if final := resp.Request.URL.String(); final != pageURL {
+		meta.FinalURL = final
+	}
+	var text string
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		meta.Title, text, err = extractReadableText(body)
+		if err != nil {
+			return cachedDoc{}, "", fmt.Errorf("could not read the page: %s", err)
+		}
+	case !utf8.Valid(body) || bytes.IndexByte(body, 0) >= 0:
+		return cachedDoc{}, "", fmt.Errorf("%s is %s, not a text page", pageURL, mediaType)
+	default:
+		// Plain text, markdown, JSON and source files read as they are
+		text = strings.TrimSpace(string(body))
+	}
+	if text == "" {
+		return cachedDoc{}, "", fmt.Errorf("%s has no readable text (it may need JavaScript to render)", pageURL)
+	}
+	meta.Chars = len(text)
+	return meta, text, nil
+}
+
+// skippedElements hold no reading matter
+var skippedElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true, atom.Form: true,
+	atom.Button: true, atom.Svg: true, atom.Iframe: true, atom.Canvas: true, atom.Select: true,
+}
+
+// blockElements are set apart by blank lines
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+	atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Table: true, atom.Blockquote: true,
+	atom.Figure: true, atom.Figcaption: true, atom.Details: true, atom.Summary: true, atom.Hr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+// lineElements start on a line of their own
+var lineElements = map[atom.Atom]bool{
+	atom.Li: true, atom.Tr: true, atom.Dt: true, atom.Dd: true, atom.Br: true,
+}
+
+var (
+	spaceRun     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLines   = regexp.MustCompile(`\n{3,}`)
+	headingLevel = map[atom.Atom]int{atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6}
+)
+
+// extractReadableText returns an HTML page's title and its main text in a
+// markdown-like form: headings, list items, code blocks and paragraphs.
+// The page's main or article element is used when it has one, so site
+// chrome outside it is left out.
+func extractReadableText(page []byte) (string, string, error) {
+	doc, err := html.Parse(bytes.NewReader(page))
+	if err != nil {
+		return "", "", err
+	}
+
+	var title string
+	var main, article, body *html.Node
+	var find func(*html.Node)
+	find = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Title:
+				if title == "" && n.FirstChild != nil {
+					title = strings.TrimSpace(spaceRun.ReplaceAllString(n.FirstChild.Data, " "))
+				}
+			case atom.Main:
+				if main == nil {
+					main = n
+				}
+			case atom.Article:
+				if article == nil {
+					article = n
+				}
+			case atom.Body:
+				body = n
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			find(c)
+		}
+	}
+	find(doc)
+
+	root := doc
+	for _, candidate := range []*html.Node{main, article, body} {
+		if candidate != nil {
+			root = candidate
+			break
+		}
+	}
+	w := &readableWriter{}
+	w.walk(root)
+	text := blankLines.ReplaceAllString(w.sb.String(), "\n\n")
+	return title, strings.TrimSpace(text), nil
+}
+
+// readableWriter renders HTML nodes as text
+type readableWriter struct {
+	sb        strings.Builder
+	lineStart bool // Nothing but markup on the current line yet
+	space     bool // A space is due before the next word
+	blank     bool // The output ends with a blank line
+}
+
+// walk renders n and its children
+func (w *readableWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(spaceRun.ReplaceAllString(n.Data, " "))
+		return
+	case html.ElementNode:
+		if skippedElements[n.DataAtom] || hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+			return
+		}
+	case html.DocumentNode:
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Pre:
+		w.block()
+		w.sb.WriteString("```\n" + strings.Trim(nodeText(n), "\n") + "\n```")
+		w.lineStart, w.blank = false, false
+		w.block()
+		return
+	case atom.Code:
+		w.text("`" + nodeText(n) + "`")
+		return
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			w.text("[" + alt + "]")
+		}
+		return
+	}
+
+	if blockElements[n.DataAtom] {
+		w.block()
+	} else if lineElements[n.DataAtom] {
+		w.newline()
+	}
+	switch {
+	case headingLevel[n.DataAtom] > 0:
+		w.prefix(strings.Repeat("#", headingLevel[n.DataAtom]) + " ")
+	case n.DataAtom == atom.Li:
+		w.prefix("- ")
+	case n.DataAtom == atom.Td || n.DataAtom == atom.Th:
+		if !w.lineStart {
+			w.prefix(" | ")
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+	if blockElements[n.DataAtom] {
+		w.block()
+	}
+}
+
+// text adds inline text; runs of space between words become one, and
+// none is kept at the start or end of a line
+func (w *readableWriter) text(s string) {
+	if strings.HasPrefix(s, " ") {
+		w.space = true
+	}
+	trailing := strings.HasSuffix(s, " ")
+	if s = strings.TrimSpace(s); s != "" {
+		if w.space && !w.lineStart {
+			w.sb.WriteString(" ")
+		}
+		w.sb.WriteString(s)
+		w.lineStart, w.space, w.blank = false, false, false
+	}
+	if trailing {
+		w.space = true
+	}
+}
+
+// prefix adds markup that the line's text follows, such as a list bullet
+func (w *readableWriter) prefix(s string) {
+	w.sb.WriteString(s)
+	w.lineStart, w.space, w.blank = true, false, false
+}
+
+// newline ends the current line, if anything is on it
+func (w *readableWriter) newline() {
+	if !w.lineStart {
+		w.sb.WriteString("\n")
+	}
+	w.lineStart, w.space = true, false
+}
+
+// block ends the current line and leaves a blank one after it
+func (w *readableWriter) block() {
+	w.newline()
+	if w.sb.Len() > 0 && !w.blank {
+		w.sb.WriteString("\n")
+		w.blank = true
+	}
+}
+
+// nodeText returns the text under n as written, for code
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Br {
+			sb.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+	return sb.String()
+}
+
+// attr returns an attribute's value, or "" when n doesn't have it
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasAttr reports whether n has the attribute, whatever its value
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
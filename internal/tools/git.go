@@ -0,0 +1,353 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// maxGitOutputBytes caps diff and log output sent back to the model
+const maxGitOutputBytes = 30000
+
+// GitDiffParams represents parameters for the git_diff tool
+type GitDiffParams struct {
+	Path   string `json:"path,omitempty"`   // Limit the diff to a file or directory
+	Staged bool   `json:"staged,omitempty"` // Show staged changes instead of unstaged
+	Ref    string `json:"ref,omitempty"`    // Compare the working tree against a commit or branch
+	Stat   bool   `json:"stat,omitempty"`   // Only show a per-file summary
+}
+
+// GitLogParams represents parameters for the git_log tool
+type GitLogParams struct {
+	Count int    `json:"count,omitempty"` // Number of commits (default 10)
+	Path  string `json:"path,omitempty"`  // Only commits touching this path
+	Ref   string `json:"ref,omitempty"`   // Start from this commit or branch
+}
+
+// gitStatusTool shows the working tree status
+type gitStatusTool struct {
+	workingDir string
+}
+
+// gitDiffTool shows changes in the working tree
+type gitDiffTool struct {
+	workingDir string
+}
+
+// gitLogTool shows recent commits
+type gitLogTool struct {
+	workingDir string
+}
+
+const (
+	// GitStatusToolName is the name of this tool
+	GitStatusToolName = "git_status"
+	// gitStatusDescription describes what this tool does
+	gitStatusDescription = `Show the git status of the project.
+
+OUTPUT:
+- Current branch and how far it is ahead of or behind its upstream
+- Staged, unstaged and untracked files`
+
+	// GitDiffToolName is the name of this tool
+	GitDiffToolName = "git_diff"
+	// gitDiffDescription describes what this tool does
+	gitDiffDescription = `Show uncommitted changes as a unified diff.
+
+WHEN TO USE:
+- Review what has changed before committing
+- staged: true shows what the next commit will contain
+- ref compares against a commit or branch (e.g. "main", "HEAD~3")
+- stat: true only lists files with added/removed line counts
+
+OUTPUT:
+- Unified diff, truncated in the middle when very long`
+
+	// GitLogToolName is the name of this tool
+	GitLogToolName = "git_log"
+	// gitLogDescription describes what this tool does
+	gitLogDescription = `Show recent commits.
+
+OUTPUT:
+- One line per commit: short hash, date, author and subject`
+)
+
+// NewGitStatusTool creates a new git_status tool
+func NewGitStatusTool(workingDir string) BaseTool {
+	return &gitStatusTool{workingDir: workingDir}
+}
+
+// Name returns the tool name
+func (t *gitStatusTool) Name() string {
+	return GitStatusToolName
+}
+
+// Info returns the tool information
+func (t *gitStatusTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        GitStatusToolName,
+		Description: gitStatusDescription,
+		Parameters:  map[string]any{},
+		Commands: []CommandInfo{
+			{
+				Command:     "git-status",
+				Aliases:     []string{"gs"},
+				Description: "Show git status",
+				Examples:    []string{"/git-status"},
+			},
+		},
+	}
+}
+
+// Run shows the status
+func (t *gitStatusTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	out, err := runGit(ctx, t.workingDir, "status", "--short", "--branch")
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	lines := splitLines(out)
+	branch := ""
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "## ") {
+		branch = strings.TrimPrefix(lines[0], "## ")
+		lines = lines[1:]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Branch: %s\n", branch))
+	if len(lines) == 0 {
+		sb.WriteString("Working tree clean")
+	} else {
+		sb.WriteString(fmt.Sprintf("Changes (%d):\n", len(lines)))
+		sb.WriteString(strings.Join(lines, "\n"))
+	}
+
+	return WithResponseMetadata(NewTextResponse(sb.String()), map[string]any{
+		"branch":        branch,
+		"changed_files": len(lines),
+	}), nil
+}
+
+// NewGitDiffTool creates a new git_diff tool
+func NewGitDiffTool(workingDir string) BaseTool {
+	return &gitDiffTool{workingDir: workingDir}
+}
+
+// Name returns the tool name
+func (t *gitDiffTool) Name() string {
+	return GitDiffToolName
+}
+
+// Info returns the tool information
+func (t *gitDiffTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        GitDiffToolName,
+		Description: gitDiffDescription,
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Limit the diff to this file or directory",
+			},
+			"staged": map[string]any{
+				"type":        "boolean",
+				"description": "Show staged changes (default: unstaged)",
+			},
+			"ref": map[string]any{
+				"type":        "string",
+				"description": "Commit or branch to compare the working tree against",
+			},
+			"stat": map[string]any{
+				"type":        "boolean",
+				"description": "Only show changed files with line counts",
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "diff",
+				Description: "Show uncommitted changes",
+				Examples:    []string{"/diff", "/diff internal/app"},
+				Args:        []string{"path"},
+			},
+		},
+	}
+}
+
+// Run shows the diff
+func (t *gitDiffTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params GitDiffParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	if strings.HasPrefix(params.Ref, "-") {
+		return NewTextErrorResponse("ref must be a commit or branch name"), nil
+	}
+
+	args := []string{"diff", "--no-color", "--no-ext-diff"}
+	if params.Staged {
+		args = append(args, "--cached")
+	}
+	if params.Stat {
+		args = append(args, "--stat")
+	}
+	if params.Ref != "" {
+		args = append(args, params.Ref)
+	}
+	args = append(args, "--")
+	if params.Path != "" {
+		if _, _, err := resolveProjectPath(t.workingDir, params.Path); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		args = append(args, params.Path)
+	}
+
+	out, err := runGit(ctx, t.workingDir, args...)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if strings.TrimSpace(out) == "" {
+		what := "unstaged changes"
+		if params.Staged {
+			what = "staged changes"
+		}
+		if params.Ref != "" {
+			what = "changes against " + params.Ref
+		}
+		return NewTextResponse("No " + what), nil
+	}
+
+	added, removed := diffLineCounts(out)
+	return WithResponseMetadata(NewTextResponse(truncateOutput(out, maxGitOutputBytes)), map[string]any{
+		"lines_added":   added,
+		"lines_removed": removed,
+	}), nil
+}
+
+// NewGitLogTool creates a new git_log tool
+func NewGitLogTool(workingDir string) BaseTool {
+	return &gitLogTool{workingDir: workingDir}
+}
+
+// Name returns the tool name
+func (t *gitLogTool) Name() string {
+	return GitLogToolName
+}
+
+// Info returns the tool information
+func (t *gitLogTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        GitLogToolName,
+		Description: gitLogDescription,
+		Parameters: map[string]any{
+			"count": map[string]any{
+				"type":        "integer",
+				"description": "Number of commits to show (default: 10)",
+				"minimum":     1,
+				"maximum":     200,
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Only show commits touching this path",
+			},
+			"ref": map[string]any{
+				"type":        "string",
+				"description": "Commit or branch to start from (default: HEAD)",
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "log",
+				Description: "Show recent commits",
+				Examples:    []string{"/log", "/log 25"},
+				Args:        []string{"count"},
+			},
+		},
+	}
+}
+
+// Run shows the log
+func (t *gitLogTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params GitLogParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	if params.Count <= 0 {
+		params.Count = 10
+	}
+	if params.Count > 200 {
+		params.Count = 200
+	}
+	if strings.HasPrefix(params.Ref, "-") {
+		return NewTextErrorResponse("ref must be a commit or branch name"), nil
+	}
+
+	args := []string{"log", "--no-color", "-n", strconv.Itoa(params.Count), "--date=short", "--pretty=format:%h %ad %an: %s"}
+	if params.Ref != "" {
+		args = append(args, params.Ref)
+	}
+	args = append(args, "--")
+	if params.Path != "" {
+		args = append(args, params.Path)
+	}
+
+	out, err := runGit(ctx, t.workingDir, args...)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if strings.TrimSpace(out) == "" {
+		return NewTextResponse("No commits"), nil
+	}
+	return NewTextResponse(truncateOutput(out, maxGitOutputBytes)), nil
+}
+
+// runGit runs git in dir and returns stdout, turning failures into errors
+// that carry git's own message.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	return runGitInput(ctx, dir, "", args...)
+}
+
+// runGitInput is runGit with stdin
+func runGitInput(ctx context.Context, dir, input string, args ...string) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("git is not installed")
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return stdout.String(), fmt.Errorf("git %s failed: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// diffLineCounts counts added and removed lines in a unified diff
+func diffLineCounts(diff string) (added, removed int) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/permission"
+)
+
+// GitBranchParams represents parameters for the git_branch tool
+type GitBranchParams struct {
+	Create string `json:"create,omitempty"` // Create and switch to this branch
+}
+
+// gitBranchTool lists branches and creates new ones
+type gitBranchTool struct {
+	permissions permission.Service
+	workingDir  string
+}
+
+const (
+	// GitBranchToolName is the name of this tool
+	GitBranchToolName = "git_branch"
+	// gitBranchDescription describes what this tool does
+	gitBranchDescription = `List git branches, or create and switch to a new one.
+
+WHAT THIS DOES:
+- Without parameters: lists local branches, marking the current one
+- create: makes a branch from the current commit and switches to it
+  (requires the user's permission; uncommitted changes carry over)
+
+OUTPUT:
+- Branch list or the new branch name`
+)
+
+// NewGitBranchTool creates a new git_branch tool
+func NewGitBranchTool(permissions permission.Service, workingDir string) BaseTool {
+	return &gitBranchTool{
+		permissions: permissions,
+		workingDir:  workingDir,
+	}
+}
+
+// Name returns the tool name
+func (t *gitBranchTool) Name() string {
+	return GitBranchToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: calls may wait on a permission prompt
+func (t *gitBranchTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *gitBranchTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        GitBranchToolName,
+		Description: gitBranchDescription,
+		Parameters: map[string]any{
+			"create": map[string]any{
+				"type":        "string",
+				"description": "Name of a new branch to create and switch to",
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "branch",
+				Description: "List branches or create one",
+				Examples:    []string{"/branch", "/branch feature/undo"},
+			},
+		},
+	}
+}
+
+// Run lists or creates branches
+func (t *gitBranchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params GitBranchParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	name := strings.TrimSpace(params.Create)
+	if name == "" {
+		out, err := runGit(ctx, t.workingDir, "branch", "--no-color", "--list")
+		if err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		if strings.TrimSpace(out) == "" {
+			return NewTextResponse("No branches yet"), nil
+		}
+		return NewTextResponse(strings.TrimSuffix(out, "\n")), nil
+	}
+
+	if _, err := runGit(ctx, t.workingDir, "check-ref-format", "--branch", name); err != nil || strings.HasPrefix(name, "-") {
+		return NewTextErrorResponse(fmt.Sprintf("%q is not a valid branch name", name)), nil
+	}
+
+	if t.permissions != nil {
+		sessionID, _ := GetContextValues(ctx)
+		granted := t.permissions.Request(ctx, permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			ToolCallID:  call.ID,
+			ToolName:    GitBranchToolName,
+			Action:      "branch",
+			Path:        name,
+			Description: fmt.Sprintf("Create and switch to branch %s", name),
+			Params:      params,
+		})
+		if !granted {
+			return NewTextErrorResponse("permission denied: branch was not created"), nil
+		}
+	}
+
+	if _, err := runGit(ctx, t.workingDir, "switch", "-c", name); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	return WithResponseMetadata(NewTextResponse(fmt.Sprintf("Switched to new branch %s", name)), map[string]any{
+		"branch": name,
+	}), nil
+}
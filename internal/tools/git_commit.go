@@ -0,0 +1,252 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/permission"
+)
+
+// maxCommitDiffBytes caps the diff sent to the model to write a message
+const maxCommitDiffBytes = 12000
+
+// GitCommitParams represents parameters for the git_commit tool
+type GitCommitParams struct {
+	Message string   `json:"message,omitempty"` // Commit message; generated from the diff when empty
+	Files   []string `json:"files,omitempty"`   // Commit only these paths (new files are added)
+	All     bool     `json:"all,omitempty"`     // Commit every change to tracked files
+}
+
+// gitCommitTool creates commits, asking for permission first
+type gitCommitTool struct {
+	permissions permission.Service
+	workingDir  string
+	llmClient   llm.Client
+}
+
+const (
+	// GitCommitToolName is the name of this tool
+	GitCommitToolName = "git_commit"
+	// gitCommitDescription describes what this tool does
+	gitCommitDescription = `Create a git commit (requires the user's permission).
+
+WHAT GETS COMMITTED:
+- files: only these paths, adding new files as needed
+- all: true commits every change to tracked files
+- otherwise: whatever is already staged
+
+MESSAGE:
+- Pass message to use your own
+- Leave it empty to generate one from the diff
+- The user sees the message before approving
+
+OUTPUT:
+- The new commit's short hash and subject`
+
+	commitMessagePrompt = `Write a git commit message for the diff below.
+Use an imperative subject line under 72 characters, then a blank line and a
+short body only if the change needs explaining. Reply with the message only,
+no quotes or code fences.`
+)
+
+// NewGitCommitTool creates a new git_commit tool
+func NewGitCommitTool(permissions permission.Service, workingDir string, llmClient llm.Client) BaseTool {
+	return &gitCommitTool{
+		permissions: permissions,
+		workingDir:  workingDir,
+		llmClient:   llmClient,
+	}
+}
+
+// Name returns the tool name
+func (t *gitCommitTool) Name() string {
+	return GitCommitToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: calls may wait on the model and a permission prompt
+func (t *gitCommitTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *gitCommitTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        GitCommitToolName,
+		Description: gitCommitDescription,
+		Parameters: map[string]any{
+			"message": map[string]any{
+				"type":        "string",
+				"description": "Commit message (generated from the diff when omitted)",
+			},
+			"files": map[string]any{
+				"type":        "array",
+				"description": "Paths to commit; new files are added",
+			},
+			"all": map[string]any{
+				"type":        "boolean",
+				"description": "Commit all changes to tracked files",
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "commit",
+				Description: "Commit staged changes",
+				Examples:    []string{"/commit", "/commit Fix typo in README"},
+				Args:        []string{"message"},
+			},
+		},
+	}
+}
+
+// Run creates the commit
+func (t *gitCommitTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params GitCommitParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	if len(params.Files) > 0 && params.All {
+		return NewTextErrorResponse("provide files or all, not both"), nil
+	}
+	for _, file := range params.Files {
+		if _, _, err := resolveProjectPath(t.workingDir, file); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+	}
+
+	// Show what would be committed before touching the index
+	diffArgs := t.diffArgs(params)
+	stat, err := runGit(ctx, t.workingDir, append([]string{"diff", "--stat"}, diffArgs[1:]...)...)
+	if err != nil && len(params.Files) == 0 {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	untracked := t.untracked(ctx, params.Files)
+	if strings.TrimSpace(stat) == "" && len(untracked) == 0 {
+		return NewTextErrorResponse("nothing to commit: " + t.scope(params)), nil
+	}
+
+	message := strings.TrimSpace(params.Message)
+	generated := false
+	if message == "" {
+		message, err = t.generateMessage(ctx, diffArgs, untracked)
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("no message given and could not generate one: %s", err)), nil
+		}
+		generated = true
+	}
+
+	if t.permissions != nil {
+		sessionID, _ := GetContextValues(ctx)
+		summary := strings.TrimSpace(stat)
+		if len(untracked) > 0 {
+			summary = strings.TrimSpace(summary + "\nnew: " + strings.Join(untracked, ", "))
+		}
+		granted := t.permissions.Request(ctx, permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			ToolCallID:  call.ID,
+			ToolName:    GitCommitToolName,
+			Action:      "commit",
+			Path:        t.scope(params),
+			Description: fmt.Sprintf("Commit with message:\n%s\n\n%s", message, summary),
+			Params:      params,
+		})
+		if !granted {
+			return NewTextErrorResponse("permission denied: nothing was committed"), nil
+		}
+	}
+
+	commitArgs := []string{"commit", "-F", "-"}
+	switch {
+	case len(params.Files) > 0:
+		if _, err := runGit(ctx, t.workingDir, append([]string{"add", "--"}, params.Files...)...); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		commitArgs = append(append(commitArgs, "--"), params.Files...)
+	case params.All:
+		commitArgs = append(commitArgs, "-a")
+	}
+	if _, err := runGitInput(ctx, t.workingDir, message+"\n", commitArgs...); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	head, _ := runGit(ctx, t.workingDir, "log", "-1", "--pretty=format:%h %s")
+	_, _ = analysis.RefreshChangelog(ctx, t.workingDir) // So the chat knows about the commit
+	hash, subject, _ := strings.Cut(strings.TrimSpace(head), " ")
+	return WithResponseMetadata(NewTextResponse(fmt.Sprintf("Committed %s: %s", hash, subject)), map[string]any{
+		"commit":            hash,
+		"subject":           subject,
+		"message_generated": generated,
+	}), nil
+}
+
+// diffArgs returns the git diff arguments covering what will be committed
+func (t *gitCommitTool) diffArgs(params GitCommitParams) []string {
+	switch {
+	case len(params.Files) > 0:
+		return append([]string{"diff", "--no-color", "--no-ext-diff", "HEAD", "--"}, params.Files...)
+	case params.All:
+		return []string{"diff", "--no-color", "--no-ext-diff", "HEAD"}
+	default:
+		return []string{"diff", "--no-color", "--no-ext-diff", "--cached"}
+	}
+}
+
+// untracked returns the listed files git does not track yet
+func (t *gitCommitTool) untracked(ctx context.Context, files []string) []string {
+	if len(files) == 0 {
+		return nil
+	}
+	out, err := runGit(ctx, t.workingDir, append([]string{"ls-files", "--others", "--exclude-standard", "--"}, files...)...)
+	if err != nil {
+		return nil
+	}
+	return splitLines(out)
+}
+
+// scope describes what the commit covers
+func (t *gitCommitTool) scope(params GitCommitParams) string {
+	switch {
+	case len(params.Files) > 0:
+		return strings.Join(params.Files, ", ")
+	case params.All:
+		return "all tracked changes"
+	default:
+		return "staged changes"
+	}
+}
+
+// generateMessage asks the model to summarize the diff
+func (t *gitCommitTool) generateMessage(ctx context.Context, diffArgs []string, untracked []string) (string, error) {
+	if t.llmClient == nil {
+		return "", fmt.Errorf("no model is loaded")
+	}
+
+	// An unborn HEAD makes the diff fail; the file list still helps
+	diff, _ := runGit(ctx, t.workingDir, diffArgs...)
+	for _, file := range untracked {
+		diff += fmt.Sprintf("\nnew file: %s", file)
+	}
+	diff = truncateOutput(diff, maxCommitDiffBytes)
+
+	reply, err := t.llmClient.Complete(ctx, []llm.Message{
+		{Role: "system", Content: commitMessagePrompt},
+		{Role: "user", Content: diff},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	message := strings.TrimSpace(reply)
+	message = strings.TrimPrefix(message, "```")
+	message = strings.TrimSuffix(message, "```")
+	message = strings.Trim(strings.TrimSpace(message), `"`)
+	if message == "" {
+		return "", fmt.Errorf("the model returned an empty message")
+	}
+	return message, nil
+}
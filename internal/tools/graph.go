@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/analysis"
+)
+
+// GraphParams represents parameters for the graph tool
+type GraphParams struct {
+	Path string `json:"path,omitempty"` // File or module directory to show
+}
+
+// graphTool answers fan-in and fan-out questions from the dependency graph
+type graphTool struct {
+	workingDir string
+}
+
+const (
+	// GraphToolName is the name of this tool
+	GraphToolName = "graph"
+	// graphDescription describes what this tool does
+	graphDescription = `Show the import graph around a file or module.
+
+WHEN TO USE:
+- Before changing a file, to see what depends on it
+- To find the files a change ripples furthest from
+
+OUTPUT:
+- For a file: its module, the project files it imports (fan-out) and the files importing it (fan-in)
+- For a module directory: the modules it depends on and the modules using it
+- Without a path: the most imported files
+
+Uses .loco/knowledge/graph.json from the last analysis run, or builds the graph from source when there is none.`
+
+	// maxGraphListing caps how many most-imported files are listed
+	maxGraphListing = 15
+)
+
+// NewGraphTool creates a new graph tool
+func NewGraphTool(workingDir string) BaseTool {
+	return &graphTool{workingDir: workingDir}
+}
+
+// Name returns the tool name
+func (t *graphTool) Name() string {
+	return GraphToolName
+}
+
+// Info returns the tool information
+func (t *graphTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        GraphToolName,
+		Description: graphDescription,
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File or module directory, relative to the project root",
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "graph",
+				Description: "Show what a file imports and what imports it",
+				Examples:    []string{"/graph", "/graph internal/app/app.go", "/graph internal/llm"},
+			},
+		},
+	}
+}
+
+// Run shows the fan-in and fan-out of a file or module
+func (t *graphTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params GraphParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	graph, err := analysis.LoadDependencyGraph(t.workingDir)
+	source := "graph.json from " + graphAge(graph)
+	if err != nil {
+		if graph, err = analysis.BuildDependencyGraph(t.workingDir); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("failed to build dependency graph: %s", err)), nil
+		}
+		source = "built from source; any analysis run saves it"
+	}
+
+	path := strings.TrimSpace(params.Path)
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(t.workingDir, path); err == nil {
+			path = rel
+		}
+	}
+
+	var sb strings.Builder
+	switch {
+	case path == "":
+		sb.WriteString(fmt.Sprintf("Dependency graph (%s): %d files, %d modules, %d import cycles\n\n", source, len(graph.Files), len(graph.Modules), len(graph.Cycles)))
+		sb.WriteString("Most imported files:\n")
+		for _, file := range graph.MostImported(maxGraphListing) {
+			if len(file.ImportedBy) == 0 {
+				break
+			}
+			sb.WriteString(fmt.Sprintf("  %3d  %s\n", len(file.ImportedBy), file.Path))
+		}
+		for _, cycle := range graph.Cycles {
+			sb.WriteString("\nImport cycle: " + strings.Join(cycle, " ↔ ") + "\n")
+		}
+
+	case graph.File(path) != nil:
+		file := graph.File(path)
+		sb.WriteString(fmt.Sprintf("%s (module %s, %s)\n", file.Path, file.Module, source))
+		writeGraphList(&sb, "Imports (fan-out)", file.Imports)
+		writeGraphList(&sb, "Imported by (fan-in)", file.ImportedBy)
+
+	case graph.Module(path) != nil:
+		module := graph.Module(path)
+		sb.WriteString(fmt.Sprintf("%s/ (module, %d files, %s)\n", module.Path, len(module.Files), source))
+		writeGraphList(&sb, "Depends on (fan-out)", module.DependsOn)
+		writeGraphList(&sb, "Used by (fan-in)", module.UsedBy)
+
+	default:
+		return NewTextErrorResponse(fmt.Sprintf("%s is not in the dependency graph; only Go, JavaScript, TypeScript and Python sources outside tests are", path)), nil
+	}
+
+	return NewTextResponse(strings.TrimRight(sb.String(), "\n")), nil
+}
+
+// writeGraphList writes a titled list with its length
+func writeGraphList(sb *strings.Builder, title string, items []string) {
+	sb.WriteString(fmt.Sprintf("\n%s: %d\n", title, len(items)))
+	for _, item := range items {
+		sb.WriteString("  " + item + "\n")
+	}
+}
+
+// graphAge describes when a saved graph was built
+func graphAge(graph *analysis.DependencyGraph) string {
+	if graph == nil || graph.Generated.IsZero() {
+		return "an earlier run"
+	}
+	return graph.Generated.Format("2006-01-02 15:04")
+}
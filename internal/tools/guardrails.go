@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/config"
+)
+
+// PathToucher is implemented by tools that change files or run commands.
+// The registry refuses their calls when a path they name is protected by
+// guardrails.protected_paths, before the tool runs or asks permission.
+type PathToucher interface {
+	// TouchedPaths returns the paths a call names, absolute or relative to
+	// the project
+	TouchedPaths(call ToolCall) []string
+}
+
+// TreeReader is implemented by tools whose calls may read whole directory
+// trees, like bash running grep -r. The registry refuses a call when a
+// protected path lies below a directory it reads.
+type TreeReader interface {
+	// ReadTrees returns the directories a call reads with everything below
+	// them
+	ReadTrees(call ToolCall) []TreeRead
+}
+
+// TreeRead is a directory a call reads recursively
+type TreeRead struct {
+	Dir        string // Absolute, or relative to the project
+	SkipHidden bool   // Dot files and directories below Dir are left out, as rg does by default
+}
+
+// guardedTool refuses calls of a PathToucher or TreeReader that touch
+// protected paths
+type guardedTool struct {
+	BaseTool
+	registry *Registry
+}
+
+// Run checks the call's paths, then runs the tool
+func (t *guardedTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	if toucher, ok := t.BaseTool.(PathToucher); ok {
+		for _, touched := range toucher.TouchedPaths(call) {
+			if rel, glob, ok := t.registry.Protected(touched); ok {
+				return NewTextErrorResponse(protectedMessage(t.Name(), rel, glob)), nil
+			}
+		}
+	}
+	if reader, ok := t.BaseTool.(TreeReader); ok {
+		for _, tree := range reader.ReadTrees(call) {
+			if rel, glob, ok := t.registry.ProtectedUnder(tree.Dir, tree.SkipHidden); ok {
+				return NewTextErrorResponse(fmt.Sprintf("refused: %s would read everything under %s, including %s, which is protected (%q in guardrails.protected_paths); name the files or directories to read instead",
+					t.Name(), tree.Dir, rel, glob)), nil
+			}
+		}
+	}
+	return t.BaseTool.Run(ctx, call)
+}
+
+// RunsInBackground passes on whether the wrapped tool runs off the UI loop
+func (t *guardedTool) RunsInBackground() bool {
+	return RunsInBackground(t.BaseTool)
+}
+
+// guard wraps tools that touch paths so the registry checks their calls
+func (r *Registry) guard(tool BaseTool) BaseTool {
+	_, touches := tool.(PathToucher)
+	_, reads := tool.(TreeReader)
+	if !touches && !reads {
+		return tool
+	}
+	return &guardedTool{BaseTool: tool, registry: r}
+}
+
+// SetGuardrails sets the config the protected paths are read from. Changes
+// to it apply to the next call.
+func (r *Registry) SetGuardrails(configManager *config.Manager) {
+	r.guardrails = configManager
+}
+
+// Protected reports whether a path, absolute or relative to the project, is
+// protected, with its project-relative form and the glob that protects it.
+// Paths outside the project are not.
+func (r *Registry) Protected(p string) (string, string, bool) {
+	globs := r.protectedPaths()
+	if len(globs) == 0 || p == "" {
+		return "", "", false
+	}
+	_, rel, err := resolveProjectPath(r.workingDir, p)
+	if err != nil || rel == "." {
+		return "", "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if glob, ok := matchProtectedGlobs(globs, rel); ok {
+		return rel, glob, true
+	}
+	return "", "", false
+}
+
+// ProtectedUnder reports whether a protected path exists below a directory,
+// absolute or relative to the project, with its project-relative form and
+// the glob that protects it. A directory above the project covers the
+// whole project; one beside it covers nothing.
+func (r *Registry) ProtectedUnder(dir string, skipHidden bool) (string, string, bool) {
+	globs := r.protectedPaths()
+	if len(globs) == 0 || dir == "" {
+		return "", "", false
+	}
+	root := dir
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(r.workingDir, root)
+	}
+	root = filepath.Clean(root)
+	if up, err := filepath.Rel(root, r.workingDir); err == nil && up != ".." && !strings.HasPrefix(up, ".."+string(filepath.Separator)) {
+		root = r.workingDir
+	} else if _, _, err := resolveProjectPath(r.workingDir, root); err != nil {
+		return "", "", false
+	}
+
+	var found, foundGlob string
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, relErr := filepath.Rel(r.workingDir, p)
+		if relErr != nil || rel == "." {
+			return nil
+		}
+		// A hidden directory named on the command line is still read
+		if skipHidden && p != root && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if glob, ok := matchProtectedGlobs(globs, rel); ok {
+			found, foundGlob = rel, glob
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found, foundGlob, found != ""
+}
+
+// protectedPaths returns the configured protected globs; none without a
+// project or config
+func (r *Registry) protectedPaths() []string {
+	if r.guardrails == nil || r.workingDir == "" {
+		return nil
+	}
+	cfg := r.guardrails.Get()
+	if cfg == nil {
+		return nil
+	}
+	return cfg.Guardrails.ProtectedPaths
+}
+
+// matchProtectedGlobs returns the first glob that protects rel
+func matchProtectedGlobs(globs []string, rel string) (string, bool) {
+	for _, glob := range globs {
+		if matchProtected(glob, rel) {
+			return glob, true
+		}
+	}
+	return "", false
+}
+
+// protectedMessage explains a refusal to touch a protected path
+func protectedMessage(tool, rel, glob string) string {
+	return fmt.Sprintf("refused: %s is protected (%q in guardrails.protected_paths), so %s won't touch it", rel, glob, tool)
+}
+
+// matchProtected reports whether a project-relative path, or a directory
+// it is in, matches a protected glob
+func matchProtected(glob, rel string) bool {
+	glob = strings.Trim(filepath.ToSlash(strings.TrimSpace(glob)), "/")
+	if glob == "" {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	if !strings.Contains(glob, "/") {
+		for _, part := range parts {
+			if ok, _ := path.Match(glob, part); ok {
+				return true
+			}
+		}
+		return false
+	}
+	pattern := strings.Split(glob, "/")
+	for n := len(parts); n > 0; n-- {
+		if matchSegments(pattern, parts[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against glob segments, where **
+// stands for any number of segments
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], parts[0])
+	return ok && matchSegments(pattern[1:], parts[1:])
+}
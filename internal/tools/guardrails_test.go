@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/billie-coop/loco/internal/config"
+)
+
+func TestMatchProtected(t *testing.T) {
+	tests := []struct {
+		glob string
+		rel  string
+		want bool
+	}{
+		{".env", ".env", true},
+		{".env", "app/.env", true},
+		{".env", ".env.example", false},
+		{"*.pem", "certs/server.pem", true},
+		{"*.pem", "certs/server.pem.txt", false},
+		{"id_rsa*", "keys/id_rsa.pub", true},
+		{".git/**", ".git", true},
+		{".git/**", ".git/config", true},
+		{".git/**", "app/.git/config", false},
+		{".git/**", ".github/workflows/ci.yml", false},
+		{"secrets/**", "secrets/prod/key", true},
+		{"secrets/**", "app/secrets/key", false},
+		{"/secrets/", "secrets/key", true},
+		{"config/*.json", "config/prod.json", true},
+		{"config/*.json", "config/sub/prod.json", false},
+		{"**/*.key", "a/b/c.key", true},
+		{"**/*.key", "c.key", true},
+		{"node_modules", "web/node_modules/x/index.js", true},
+		{"  ", "anything", false},
+	}
+	for _, tt := range tests {
+		if got := matchProtected(tt.glob, tt.rel); got != tt.want {
+			t.Errorf("matchProtected(%q, %q) = %v, want %v", tt.glob, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"a/b", "a/b", true},
+		{"a/b", "a/b/c", false},
+		{"a/*", "a/b", true},
+		{"**", "", true},
+		{"**", "a/b/c", true},
+		{"a/**/d", "a/d", true},
+		{"a/**/d", "a/b/c/d", true},
+		{"a/**/d", "a/b/c/e", false},
+		{"**/b/**", "x/b/y/z", true},
+		{"a/[bc]", "a/c", true},
+		{"a/[bc]", "a/d", false},
+	}
+	for _, tt := range tests {
+		var parts []string
+		if tt.path != "" {
+			parts = strings.Split(tt.path, "/")
+		}
+		if got := matchSegments(strings.Split(tt.pattern, "/"), parts); got != tt.want {
+			t.Errorf("matchSegments(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGuardrailsRefuseHiddenProtectedPaths(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{".env", "secrets/key", "internal/app.go", ".git/config", "docs/readme.md"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("KEY=1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configManager := config.NewManager(root)
+	if err := configManager.Load(); err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRegistry()
+	registry.workingDir = root
+	registry.SetGuardrails(configManager)
+	registry.Register(NewBashTool(nil, root, configManager))
+	bash, _ := registry.Get(BashToolName)
+
+	tests := []struct {
+		command string
+		refused bool
+	}{
+		{"cat .env", true},
+		{"cat .en*", true},
+		{"cat .e?v", true},
+		{"cat .{e,}nv", true},
+		{"cat sec*/key", true},
+		{"grep -r KEY .", true},
+		{"grep -rn KEY", true},
+		{"grep -r KEY ..", true},
+		{"rg KEY", true},
+		{"rg -g '*.go' KEY", true},
+		{"env rg KEY", true},
+		{"cp -r . /tmp/copy", true},
+		{"grep -r KEY internal docs", false},
+		{"rg KEY internal", false},
+		{"grep KEY internal/app.go", false},
+		{"cat docs/*.md", false},
+		{"ls", false},
+	}
+	for _, tt := range tests {
+		input, _ := json.Marshal(BashParams{Command: tt.command})
+		resp, err := bash.Run(context.Background(), ToolCall{Name: BashToolName, Input: string(input)})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.command, err)
+		}
+		if refused := strings.HasPrefix(resp.Content, "refused:"); refused != tt.refused {
+			t.Errorf("%s: refused = %v, want %v (%s)", tt.command, refused, tt.refused, resp.Content)
+		}
+	}
+}
+
+func TestGuardedToolsKeepRunningInBackground(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(NewBashTool(nil, t.TempDir(), nil))
+	registry.Register(NewStatsTool(nil))
+	bash, _ := registry.Get(BashToolName)
+	if _, guarded := bash.(*guardedTool); !guarded || !RunsInBackground(bash) {
+		t.Errorf("bash: guarded %v, runs in background %v", guarded, RunsInBackground(bash))
+	}
+	if stats, _ := registry.Get(StatsToolName); RunsInBackground(stats) {
+		t.Error("stats runs in background")
+	}
+}
@@ -0,0 +1,342 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/permission"
+)
+
+// HTTPRequestParams represents parameters for the http_request tool
+type HTTPRequestParams struct {
+	Method    string            `json:"method,omitempty"`     // GET when empty
+	URL       string            `json:"url"`                  // http:// is assumed when the scheme is missing
+	Headers   map[string]string `json:"headers,omitempty"`    // Request headers
+	Body      string            `json:"body,omitempty"`       // Request body
+	TimeoutMs int               `json:"timeout_ms,omitempty"` // Override the configured timeout
+}
+
+// httpRequestTool sends requests to the project's own services
+type httpRequestTool struct {
+	permissions   permission.Service
+	configManager *config.Manager
+}
+
+const (
+	// HTTPRequestToolName is the name of this tool
+	HTTPRequestToolName = "http_request"
+	// httpRequestDescription describes what this tool does
+	httpRequestDescription = `Send an HTTP request to a service the project runs, to exercise its API while debugging.
+
+WHAT THIS DOES:
+- Sends the request with the given method, headers and body
+- Returns the status, the response headers and the body (JSON is indented)
+
+WHEN TO USE:
+- After starting the project's server (see run_process), to check an endpoint's behavior
+
+SAFETY:
+- Every request requires the user's permission
+- Only localhost is allowed unless http.allowed_hosts in the config lists more hosts; redirects elsewhere are refused
+
+OUTPUT:
+- Status line and duration
+- Response headers
+- Body, truncated in the middle when very long`
+
+	maxHTTPRedirects = 10
+	maxHTTPTimeout   = 5 * time.Minute
+	// maxHTTPReadBytes caps what is read of a response body
+	maxHTTPReadBytes = 10 << 20
+)
+
+// httpMethods are the methods the tool sends
+var httpMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// NewHTTPRequestTool creates a new http_request tool
+func NewHTTPRequestTool(permissions permission.Service, configManager *config.Manager) BaseTool {
+	return &httpRequestTool{permissions: permissions, configManager: configManager}
+}
+
+// Name returns the tool name
+func (t *httpRequestTool) Name() string {
+	return HTTPRequestToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: calls wait on a permission prompt and the network
+func (t *httpRequestTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *httpRequestTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        HTTPRequestToolName,
+		Description: httpRequestDescription,
+		Parameters: map[string]any{
+			"method": map[string]any{
+				"type":        "string",
+				"enum":        httpMethods,
+				"description": "HTTP method (default GET)",
+			},
+			"url": map[string]any{
+				"type":        "string",
+				"description": "URL to request, e.g. http://localhost:8080/api/users",
+			},
+			"headers": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+				"description":          "Request headers",
+			},
+			"body": map[string]any{
+				"type":        "string",
+				"description": "Request body; JSON bodies get a JSON content type unless headers set one",
+			},
+			"timeout_ms": map[string]any{
+				"type":        "integer",
+				"description": "Timeout in milliseconds (default from config, max 300000)",
+				"minimum":     1,
+			},
+		},
+		Required: []string{"url"},
+		Commands: []CommandInfo{
+			{
+				Command:     "http",
+				Description: "Send an HTTP request to a local service",
+				Examples:    []string{"/http GET http://localhost:8080/health"},
+				Args:        []string{"method", "url"},
+			},
+		},
+	}
+}
+
+// Run checks the URL, asks permission and sends the request
+func (t *httpRequestTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params HTTPRequestParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	// "/http localhost:8080/health" leaves out the method
+	if params.URL == "" && strings.ContainsAny(params.Method, "/:.") {
+		params.Method, params.URL = "", params.Method
+	}
+	params.Method = strings.ToUpper(strings.TrimSpace(params.Method))
+	if params.Method == "" {
+		params.Method = "GET"
+	}
+	known := false
+	for _, method := range httpMethods {
+		known = known || method == params.Method
+	}
+	if !known {
+		return NewTextErrorResponse(fmt.Sprintf("unsupported method %q (use %s)", params.Method, strings.Join(httpMethods, ", "))), nil
+	}
+
+	cfg := t.httpConfig()
+	target, err := parseRequestURL(params.URL)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !hostAllowed(target, cfg.AllowedHosts) {
+		return NewTextErrorResponse(fmt.Sprintf("%s is not an allowed host; add it to http.allowed_hosts in .loco/config.jsonc to allow it", target.Host)), nil
+	}
+
+	sessionID, _ := GetContextValues(ctx)
+	granted := t.permissions != nil && t.permissions.Request(ctx, permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		ToolCallID:  call.ID,
+		ToolName:    HTTPRequestToolName,
+		Action:      strings.ToLower(params.Method),
+		Path:        target.String(),
+		Scope:       target.Host,
+		Description: fmt.Sprintf("Send %s %s", params.Method, target),
+		Params:      params,
+	})
+	if !granted {
+		return NewTextErrorResponse("permission denied: request was not sent"), nil
+	}
+
+	timeout := time.Duration(cfg.TimeoutMs) * time.Millisecond
+	if params.TimeoutMs > 0 {
+		timeout = time.Duration(params.TimeoutMs) * time.Millisecond
+	}
+	if timeout > maxHTTPTimeout {
+		timeout = maxHTTPTimeout
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, params.Method, target.String(), strings.NewReader(params.Body))
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("invalid request: %s", err)), nil
+	}
+	for name, value := range params.Headers {
+		req.Header.Set(name, value)
+	}
+	if params.Body != "" && req.Header.Get("Content-Type") == "" && json.Valid([]byte(params.Body)) {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{
+		// Proxies from the environment would see requests meant for localhost
+		Transport: &http.Transport{Proxy: nil},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxHTTPRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxHTTPRedirects)
+			}
+			if !hostAllowed(req.URL, cfg.AllowedHosts) {
+				return fmt.Errorf("redirect to %s is not allowed", req.URL.Host)
+			}
+			return nil
+		},
+	}
+	defer client.CloseIdleConnections()
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(reqCtx.Err(), context.DeadlineExceeded) {
+			return NewTextErrorResponse(fmt.Sprintf("%s %s timed out after %s", params.Method, target, timeout)), nil
+		}
+		return NewTextErrorResponse(fmt.Sprintf("%s %s failed: %s", params.Method, target, err)), nil
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPReadBytes))
+	elapsed := time.Since(start)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("reading the response failed: %s", err)), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s (%s)\n", resp.Proto, resp.Status, elapsed.Round(time.Millisecond))
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range resp.Header[name] {
+			fmt.Fprintf(&sb, "%s: %s\n", name, value)
+		}
+	}
+	if text := responseBodyText(body, resp.Header.Get("Content-Type")); text != "" {
+		sb.WriteString("\n" + truncateOutput(text, cfg.MaxResponseBytes))
+	}
+
+	result := NewTextResponse(strings.TrimSuffix(sb.String(), "\n"))
+	result.IsError = resp.StatusCode >= 500
+	return WithResponseMetadata(result, map[string]any{
+		"method":      params.Method,
+		"url":         target.String(),
+		"status":      resp.StatusCode,
+		"duration_ms": elapsed.Milliseconds(),
+		"bytes":       len(body),
+	}), nil
+}
+
+// httpConfig returns the configured limits, falling back to defaults
+func (t *httpRequestTool) httpConfig() config.HTTPConfig {
+	cfg := config.DefaultConfig().HTTP
+	if t.configManager != nil {
+		if current := t.configManager.Get(); current != nil {
+			if current.HTTP.AllowedHosts != nil {
+				cfg.AllowedHosts = current.HTTP.AllowedHosts
+			}
+			if current.HTTP.TimeoutMs > 0 {
+				cfg.TimeoutMs = current.HTTP.TimeoutMs
+			}
+			if current.HTTP.MaxResponseBytes > 0 {
+				cfg.MaxResponseBytes = current.HTTP.MaxResponseBytes
+			}
+		}
+	}
+	return cfg
+}
+
+// parseRequestURL parses an http or https URL, assuming http when the
+// scheme is missing, as in localhost:8080/health
+func parseRequestURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, errors.New("url parameter is required")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	target, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %s", err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q (use http or https)", target.Scheme)
+	}
+	if target.Hostname() == "" {
+		return nil, fmt.Errorf("url %q has no host", raw)
+	}
+	return target, nil
+}
+
+// hostAllowed reports whether the URL's host matches an allowed entry: a
+// name or IP (any port), host:port, or *.domain for its subdomains
+func hostAllowed(target *url.URL, allowed []string) bool {
+	host := strings.ToLower(target.Hostname())
+	port := target.Port()
+	if port == "" {
+		port = "80"
+		if target.Scheme == "https" {
+			port = "443"
+		}
+	}
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		entryHost = strings.Trim(entryHost, "[]")
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(entryHost, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == entryHost {
+			return true
+		}
+	}
+	return false
+}
+
+// responseBodyText renders a body for reading: indented when it is JSON,
+// and described rather than shown when it is binary
+func responseBodyText(body []byte, contentType string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if !utf8.Valid(body) {
+		return fmt.Sprintf("(%d bytes of %s)", len(body), strings.TrimSpace(contentType+" binary content"))
+	}
+	if strings.Contains(contentType, "json") {
+		var indented bytes.Buffer
+		if json.Indent(&indented, body, "", "  ") == nil {
+			return indented.String()
+		}
+	}
+	return string(body)
+}
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestHostAllowed(t *testing.T) {
+	allowed := []string{
+		"localhost",
+		"api.example.com:8443",
+		"*.internal.dev",
+		"10.0.0.5",
+		"::1",
+		"[fd00::2]",
+		"[fd00::3]:9000",
+		"  Docs.Example.com ",
+	}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"http://localhost:3000/health", true},
+		{"https://localhost/", true},
+		{"http://LOCALHOST/", true},
+		{"https://api.example.com:8443/v1", true},
+		{"https://api.example.com/v1", false},
+		{"http://api.example.com:8080/v1", false},
+		{"https://svc.internal.dev/", true},
+		{"https://a.b.internal.dev:9999/", true},
+		{"https://internal.dev/", false},
+		{"https://evilinternal.dev/", false},
+		{"http://10.0.0.5:8080/", true},
+		{"http://10.0.0.50/", false},
+		{"http://[::1]:8080/", true},
+		{"http://[fd00::2]/", true},
+		{"http://[fd00::3]:9000/", true},
+		{"http://[fd00::3]:9001/", false},
+		{"https://docs.example.com/guide", true},
+		{"https://example.com/", false},
+		{"http://localhost.evil.com/", false},
+	}
+	for _, tt := range tests {
+		target, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.url, err)
+		}
+		if got := hostAllowed(target, allowed); got != tt.want {
+			t.Errorf("%s: allowed = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestHostAllowedDefaultPorts(t *testing.T) {
+	tests := []struct {
+		url   string
+		entry string
+		want  bool
+	}{
+		{"http://example.com/", "example.com:80", true},
+		{"https://example.com/", "example.com:443", true},
+		{"https://example.com/", "example.com:80", false},
+		{"http://example.com/", "example.com:443", false},
+	}
+	for _, tt := range tests {
+		target, _ := url.Parse(tt.url)
+		if got := hostAllowed(target, []string{tt.entry}); got != tt.want {
+			t.Errorf("%s with %s: allowed = %v, want %v", tt.url, tt.entry, got, tt.want)
+		}
+	}
+}
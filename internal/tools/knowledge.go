@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/analysis"
+)
+
+// KnowledgeParams represents parameters for the knowledge tool
+type KnowledgeParams struct {
+	Action string `json:"action,omitempty"` // "diff"
+	Target string `json:"target,omitempty"` // <tier>/<file>
+}
+
+// knowledgeTool shows how the generated knowledge files changed between runs
+type knowledgeTool struct {
+	workingDir string
+}
+
+const (
+	// KnowledgeToolName is the name of this tool
+	KnowledgeToolName = "knowledge"
+	// knowledgeDescription describes what this tool does
+	knowledgeDescription = `Show how a knowledge file changed the last time an analysis tier regenerated it.
+
+WHEN TO USE:
+- After new commits and a rerun, to see what the model's understanding of the project changed
+
+OUTPUT:
+- diff <tier>/<file>: a unified diff of the file's previous and current version; modules/<file> for a module doc
+- Without a target: the knowledge files that have a saved diff
+
+Diffs are saved under .loco/knowledge/diffs/ whenever a tier rewrites a file with different content.`
+)
+
+// NewKnowledgeTool creates a new knowledge tool
+func NewKnowledgeTool(workingDir string) BaseTool {
+	return &knowledgeTool{workingDir: workingDir}
+}
+
+// Name returns the tool name
+func (t *knowledgeTool) Name() string {
+	return KnowledgeToolName
+}
+
+// Info returns the tool information
+func (t *knowledgeTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        KnowledgeToolName,
+		Description: knowledgeDescription,
+		Parameters: map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "What to show",
+				"enum":        []string{"diff"},
+			},
+			"target": map[string]any{
+				"type":        "string",
+				"description": "Knowledge file as <tier>/<file>, e.g. deep/overview.md",
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "knowledge",
+				Description: "Show what changed in a knowledge file on the last analysis run",
+				Examples:    []string{"/knowledge diff", "/knowledge diff deep/overview.md", "/knowledge diff full/ARCHITECTURE.md"},
+				Args:        []string{"action", "target"},
+			},
+		},
+	}
+}
+
+// CompleteArgument offers the knowledge files that have a saved diff as
+// targets
+func (t *knowledgeTool) CompleteArgument(param string) []string {
+	if param != "target" {
+		return nil
+	}
+	targets, err := analysis.ListKnowledgeDiffs(t.workingDir)
+	if err != nil {
+		return nil
+	}
+	return targets
+}
+
+// Run shows a knowledge file's latest diff or lists the saved diffs
+func (t *knowledgeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params KnowledgeParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	if action := strings.TrimSpace(params.Action); action != "" && action != "diff" {
+		return NewTextErrorResponse(fmt.Sprintf("unknown action %q; use /knowledge diff <tier>/<file>", action)), nil
+	}
+
+	target := strings.TrimSpace(params.Target)
+	if target == "" {
+		targets, err := analysis.ListKnowledgeDiffs(t.workingDir)
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("failed to list knowledge diffs: %s", err)), nil
+		}
+		if len(targets) == 0 {
+			return NewTextResponse("No knowledge diffs yet: they are saved when an analysis run changes a file an earlier run wrote"), nil
+		}
+		return NewTextResponse("Knowledge files with a saved diff:\n  " + strings.Join(targets, "\n  ")), nil
+	}
+
+	diff, err := analysis.LoadKnowledgeDiff(t.workingDir, target)
+	if os.IsNotExist(err) {
+		return NewTextResponse(fmt.Sprintf("No diff for %s: it has not changed since it was first generated", target)), nil
+	}
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	return NewTextResponse(strings.TrimRight(diff, "\n")), nil
+}
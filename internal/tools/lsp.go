@@ -0,0 +1,341 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/lsp"
+)
+
+// maxLSPLocations caps how many locations are listed
+const maxLSPLocations = 100
+
+// SymbolPositionParams represents parameters for find_definition and find_references
+type SymbolPositionParams struct {
+	Path   string `json:"path"`             // File containing a use of the symbol
+	Symbol string `json:"symbol"`           // Identifier to look up
+	Line   int    `json:"line,omitempty"`   // 1-based line where the symbol appears (first occurrence if omitted)
+	Column int    `json:"column,omitempty"` // 1-based column, when the line has several matches
+}
+
+// SymbolOutlineParams represents parameters for the symbol_outline tool
+type SymbolOutlineParams struct {
+	Path string `json:"path"` // File to outline
+}
+
+// lspTool implements find_definition, find_references and symbol_outline
+type lspTool struct {
+	name       string
+	manager    *lsp.Manager
+	workingDir string
+}
+
+const (
+	// FindDefinitionToolName is the name of this tool
+	FindDefinitionToolName = "find_definition"
+	// findDefinitionDescription describes what this tool does
+	findDefinitionDescription = `Find where a symbol is defined, using the language server.
+
+WHEN TO USE:
+- You see a function, type or variable used and need its definition
+- Give the file where it is used and the symbol name; add line when the
+  name appears more than once
+
+OUTPUT:
+- path:line:column of each definition with the source line`
+
+	// FindReferencesToolName is the name of this tool
+	FindReferencesToolName = "find_references"
+	// findReferencesDescription describes what this tool does
+	findReferencesDescription = `Find every use of a symbol across the project, using the language server.
+
+WHEN TO USE:
+- Before renaming or changing a function's signature
+- To see how a type or function is used
+
+OUTPUT:
+- path:line:column of each reference with the source line`
+
+	// SymbolOutlineToolName is the name of this tool
+	SymbolOutlineToolName = "symbol_outline"
+	// symbolOutlineDescription describes what this tool does
+	symbolOutlineDescription = `List the symbols a file declares: types, functions, methods, fields and constants.
+
+WHEN TO USE:
+- Get the structure of a large file without reading all of it
+- Find the line range of a function before reading or editing it
+
+OUTPUT:
+- Indented tree of kind, name and line range`
+)
+
+// NewFindDefinitionTool creates a new find_definition tool
+func NewFindDefinitionTool(manager *lsp.Manager, workingDir string) BaseTool {
+	return &lspTool{name: FindDefinitionToolName, manager: manager, workingDir: workingDir}
+}
+
+// NewFindReferencesTool creates a new find_references tool
+func NewFindReferencesTool(manager *lsp.Manager, workingDir string) BaseTool {
+	return &lspTool{name: FindReferencesToolName, manager: manager, workingDir: workingDir}
+}
+
+// NewSymbolOutlineTool creates a new symbol_outline tool
+func NewSymbolOutlineTool(manager *lsp.Manager, workingDir string) BaseTool {
+	return &lspTool{name: SymbolOutlineToolName, manager: manager, workingDir: workingDir}
+}
+
+// Name returns the tool name
+func (t *lspTool) Name() string {
+	return t.name
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: the language server may have to start and index the project first
+func (t *lspTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *lspTool) Info() ToolInfo {
+	if t.name == SymbolOutlineToolName {
+		return ToolInfo{
+			Name:        SymbolOutlineToolName,
+			Description: symbolOutlineDescription,
+			Parameters: map[string]any{
+				"path": map[string]any{
+					"type":        "string",
+					"description": "File to outline",
+				},
+			},
+			Required: []string{"path"},
+			Commands: []CommandInfo{
+				{
+					Command:     "outline",
+					Description: "List the symbols in a file",
+					Examples:    []string{"/outline internal/app/app.go"},
+				},
+			},
+		}
+	}
+
+	info := ToolInfo{
+		Name: t.name,
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File where the symbol appears",
+			},
+			"symbol": map[string]any{
+				"type":        "string",
+				"description": "Name of the function, type or variable",
+			},
+			"line": map[string]any{
+				"type":        "integer",
+				"description": "1-based line where the symbol appears (default: first occurrence)",
+				"minimum":     1,
+			},
+			"column": map[string]any{
+				"type":        "integer",
+				"description": "1-based column when the line contains the name more than once",
+				"minimum":     1,
+			},
+		},
+		Required: []string{"path", "symbol"},
+	}
+	if t.name == FindDefinitionToolName {
+		info.Description = findDefinitionDescription
+		info.Commands = []CommandInfo{{
+			Command:     "def",
+			Aliases:     []string{"definition"},
+			Description: "Go to a symbol's definition",
+			Examples:    []string{"/def internal/app/app.go NewLLMService"},
+		}}
+	} else {
+		info.Description = findReferencesDescription
+		info.Commands = []CommandInfo{{
+			Command:     "refs",
+			Aliases:     []string{"references"},
+			Description: "Find a symbol's references",
+			Examples:    []string{"/refs internal/tools/tools.go Registry"},
+		}}
+	}
+	return info
+}
+
+// Run queries the language server
+func (t *lspTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	if t.manager == nil {
+		return NewTextErrorResponse("language servers are disabled (set lsp.enabled in .loco/config.jsonc)"), nil
+	}
+	if t.name == SymbolOutlineToolName {
+		return t.outline(ctx, call)
+	}
+
+	var params SymbolPositionParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	if params.Path == "" || params.Symbol == "" {
+		return NewTextErrorResponse("path and symbol parameters are required"), nil
+	}
+
+	absPath, relPath, err := resolveProjectPath(t.workingDir, params.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("could not read %s: %s", relPath, err)), nil
+	}
+	pos, err := symbolPosition(string(data), params)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("%s: %s", relPath, err)), nil
+	}
+
+	var locations []lsp.Location
+	if t.name == FindDefinitionToolName {
+		locations, err = t.manager.Definition(ctx, absPath, pos)
+	} else {
+		locations, err = t.manager.References(ctx, absPath, pos, false)
+	}
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	what := "definition"
+	if t.name == FindReferencesToolName {
+		what = "reference"
+	}
+	if len(locations) == 0 {
+		return NewTextResponse(fmt.Sprintf("No %ss found for %s at %s:%d", what, params.Symbol, relPath, pos.Line+1)), nil
+	}
+
+	var sb strings.Builder
+	if len(locations) > 1 {
+		what += "s"
+	}
+	sb.WriteString(fmt.Sprintf("%d %s of %s:\n", len(locations), what, params.Symbol))
+	for i, loc := range locations {
+		if i == maxLSPLocations {
+			sb.WriteString(fmt.Sprintf("... and %d more\n", len(locations)-i))
+			break
+		}
+		sb.WriteString(t.formatLocation(loc) + "\n")
+	}
+
+	return WithResponseMetadata(NewTextResponse(strings.TrimSuffix(sb.String(), "\n")), map[string]any{
+		"symbol": params.Symbol,
+		"count":  len(locations),
+	}), nil
+}
+
+// outline lists a file's symbols
+func (t *lspTool) outline(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params SymbolOutlineParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	if params.Path == "" {
+		return NewTextErrorResponse("path parameter is required"), nil
+	}
+
+	absPath, relPath, err := resolveProjectPath(t.workingDir, params.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	symbols, err := t.manager.Symbols(ctx, absPath)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if len(symbols) == 0 {
+		return NewTextResponse(fmt.Sprintf("No symbols found in %s", relPath)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Symbols in %s:\n", relPath))
+	count := writeSymbols(&sb, symbols, 0)
+	return WithResponseMetadata(NewTextResponse(strings.TrimSuffix(sb.String(), "\n")), map[string]any{
+		"path":  relPath,
+		"count": count,
+	}), nil
+}
+
+// writeSymbols renders a symbol tree, returning how many symbols it wrote
+func writeSymbols(sb *strings.Builder, symbols []lsp.DocumentSymbol, depth int) int {
+	count := 0
+	for _, sym := range symbols {
+		sb.WriteString(strings.Repeat("  ", depth))
+		sb.WriteString(fmt.Sprintf("%s %s", sym.Kind, sym.Name))
+		if sym.Detail != "" {
+			sb.WriteString(" " + sym.Detail)
+		}
+		start, end := sym.Range.Start.Line+1, sym.Range.End.Line+1
+		if start == end {
+			sb.WriteString(fmt.Sprintf(" (line %d)\n", start))
+		} else {
+			sb.WriteString(fmt.Sprintf(" (lines %d-%d)\n", start, end))
+		}
+		count++
+		count += writeSymbols(sb, sym.Children, depth+1)
+	}
+	return count
+}
+
+// formatLocation renders path:line:col and the source line
+func (t *lspTool) formatLocation(loc lsp.Location) string {
+	path := lsp.URIToPath(loc.URI)
+	display := path
+	if rel, err := filepath.Rel(t.workingDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		display = rel
+	}
+
+	line := loc.Range.Start.Line
+	source := ""
+	if data, err := os.ReadFile(path); err == nil {
+		lines := strings.Split(string(data), "\n")
+		if line < len(lines) {
+			source = lines[line]
+		}
+	}
+	col := lsp.ByteColumn(source, loc.Range.Start.Character) + 1
+	if source == "" {
+		return fmt.Sprintf("%s:%d:%d", display, line+1, col)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", display, line+1, col, strings.TrimSpace(source))
+}
+
+// symbolPosition finds the LSP position of a symbol in a file
+func symbolPosition(content string, params SymbolPositionParams) (lsp.Position, error) {
+	lines := strings.Split(content, "\n")
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(params.Symbol) + `\b`)
+
+	if params.Line > 0 {
+		if params.Line > len(lines) {
+			return lsp.Position{}, fmt.Errorf("line %d is past the end of the file (%d lines)", params.Line, len(lines))
+		}
+		text := lines[params.Line-1]
+		if params.Column > 0 {
+			return lsp.Position{Line: params.Line - 1, Character: lsp.UTF16Column(text, params.Column-1)}, nil
+		}
+		loc := word.FindStringIndex(text)
+		if loc == nil {
+			return lsp.Position{}, fmt.Errorf("%s does not appear on line %d", params.Symbol, params.Line)
+		}
+		return lsp.Position{Line: params.Line - 1, Character: lsp.UTF16Column(text, loc[0])}, nil
+	}
+
+	for i, text := range lines {
+		if loc := word.FindStringIndex(text); loc != nil {
+			return lsp.Position{Line: i, Character: lsp.UTF16Column(text, loc[0])}, nil
+		}
+	}
+	return lsp.Position{}, fmt.Errorf("%s does not appear in the file", params.Symbol)
+}
@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/memory"
+	"github.com/billie-coop/loco/internal/permission"
+)
+
+// MemoryParams represents parameters for the memory tool
+type MemoryParams struct {
+	Action string `json:"action,omitempty"` // "add", "list" or "forget"
+	Note   string `json:"note,omitempty"`   // The note to add, or the number of the one to forget
+}
+
+// memoryTool keeps the project's long-term notes in .loco/memory.md
+type memoryTool struct {
+	permissions permission.Service
+	store       *memory.Store
+}
+
+const (
+	// MemoryToolName is the name of this tool
+	MemoryToolName = "memory"
+	// memoryDescription describes what this tool does
+	memoryDescription = `Remember a fact or preference about this project for all future conversations.
+
+WHEN TO USE:
+- The user states a lasting convention or preference ("we use table-driven tests", "never touch vendored code")
+- A decision is made that later sessions should follow
+
+WHEN NOT TO USE:
+- For anything only relevant to the current task
+- For facts the code itself makes obvious
+
+ACTIONS:
+- add (default): save the note; asks the user's permission first
+- list: show the saved notes, numbered
+- forget: remove the note with the given number
+
+Notes are kept in .loco/memory.md and included in every chat's system prompt.`
+)
+
+// NewMemoryTool creates a new memory tool for the project at workingDir
+func NewMemoryTool(permissions permission.Service, workingDir string) BaseTool {
+	return &memoryTool{
+		permissions: permissions,
+		store:       memory.NewStore(workingDir),
+	}
+}
+
+// Name returns the tool name
+func (t *memoryTool) Name() string {
+	return MemoryToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: adding and forgetting may wait on a permission prompt
+func (t *memoryTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *memoryTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        MemoryToolName,
+		Description: memoryDescription,
+		Parameters: map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "What to do (default add)",
+				"enum":        []string{"add", "list", "forget"},
+			},
+			"note": map[string]any{
+				"type":        "string",
+				"description": "The fact or preference to remember, as one short sentence; for forget, the note's number",
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "remember",
+				Description: "Remember a fact or preference in every future chat",
+				Examples:    []string{"/remember we use table-driven tests", "/remember never touch vendored code"},
+				Args:        []string{"note"},
+			},
+			{
+				Command:     "memory",
+				Description: "List or forget the project's remembered notes",
+				Examples:    []string{"/memory", "/memory forget 2"},
+				Args:        []string{"action", "note"},
+			},
+		},
+	}
+}
+
+// Run adds, lists or forgets notes
+func (t *memoryTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params MemoryParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	note := strings.TrimSpace(params.Note)
+
+	action := strings.TrimSpace(params.Action)
+	if action == "" {
+		action = "list"
+		if note != "" {
+			action = "add"
+		}
+	}
+
+	switch action {
+	case "list":
+		return t.list()
+	case "add":
+		if note == "" {
+			return NewTextErrorResponse("usage: /remember <note>"), nil
+		}
+		if !t.permitted(ctx, call, params, "write", "Remember: "+note) {
+			return NewTextErrorResponse("permission denied: the note was not saved"), nil
+		}
+		added, err := t.store.Add(note)
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("failed to save the note: %s", err)), nil
+		}
+		if !added {
+			return NewTextResponse("Already remembered: " + note), nil
+		}
+		return NewTextResponse("Remembered: " + note), nil
+	case "forget":
+		n, err := strconv.Atoi(note)
+		if err != nil {
+			return NewTextErrorResponse("usage: /memory forget <number>, as numbered by /memory"), nil
+		}
+		if !t.permitted(ctx, call, params, "delete", fmt.Sprintf("Forget note %d", n)) {
+			return NewTextErrorResponse("permission denied: the note was kept"), nil
+		}
+		forgotten, err := t.store.Forget(n)
+		if err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		return NewTextResponse("Forgot: " + forgotten), nil
+	default:
+		return NewTextErrorResponse(fmt.Sprintf("unknown action %q; use add, list or forget", action)), nil
+	}
+}
+
+// list numbers the saved notes
+func (t *memoryTool) list() (ToolResponse, error) {
+	notes, err := t.store.Notes()
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to read memory: %s", err)), nil
+	}
+	if len(notes) == 0 {
+		return NewTextResponse("Nothing remembered yet: use /remember <note> to keep a fact or preference in every chat"), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Remembered in %s:\n", memory.File)
+	for i, note := range notes {
+		fmt.Fprintf(&b, "  %d. %s\n", i+1, note)
+	}
+	return NewTextResponse(strings.TrimSuffix(b.String(), "\n")), nil
+}
+
+// permitted asks for permission to change the notes file
+func (t *memoryTool) permitted(ctx context.Context, call ToolCall, params MemoryParams, action, description string) bool {
+	if t.permissions == nil {
+		return true
+	}
+	sessionID, _ := GetContextValues(ctx)
+	return t.permissions.Request(ctx, permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		ToolCallID:  call.ID,
+		ToolName:    MemoryToolName,
+		Action:      action,
+		Path:        t.store.Path(),
+		Description: description,
+		Params:      params,
+	})
+}
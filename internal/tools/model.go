@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/session"
+	"github.com/billie-coop/loco/internal/tui/events"
+)
+
+// ChatModelSwitcher changes the model that answers chat messages
+type ChatModelSwitcher interface {
+	ChatModel() string
+	SetChatModel(modelID string) bool
+}
+
+// ModelParams represents parameters for the model tool
+type ModelParams struct {
+	Model string `json:"model,omitempty"` // Model ID or size
+}
+
+// modelTool switches the chat model for the current session
+type modelTool struct {
+	chat     ChatModelSwitcher
+	sessions *session.Manager
+	broker   *events.Broker
+	team     *llm.ModelTeam
+	probes   []llm.ModelProbe
+}
+
+const (
+	// ModelToolName is the name of this tool
+	ModelToolName = "model"
+	// modelDescription describes what this tool does
+	modelDescription = `Show or switch the model that answers chat messages in this session.
+
+USAGE:
+- /model: the current chat model
+- /model <id>: switch to a loaded model
+- /model small|medium|large: switch to that model of the team
+- /model xs|s|m|l|xl: switch to the fastest probed model of that size
+
+The choice is saved with the session. The team's models for analysis are not changed.`
+)
+
+// NewModelTool creates a new model tool. team and probes resolve sizes to
+// models and may be nil before LM Studio is reached.
+func NewModelTool(chat ChatModelSwitcher, sessions *session.Manager, broker *events.Broker, team *llm.ModelTeam, probes []llm.ModelProbe) BaseTool {
+	return &modelTool{chat: chat, sessions: sessions, broker: broker, team: team, probes: probes}
+}
+
+// Name returns the tool name
+func (t *modelTool) Name() string {
+	return ModelToolName
+}
+
+// Info returns the tool information
+func (t *modelTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ModelToolName,
+		Description: modelDescription,
+		Parameters: map[string]any{
+			"model": map[string]any{
+				"type":        "string",
+				"description": "Model ID, a team slot (small, medium, large) or a size (xs, s, m, l, xl)",
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "model",
+				Description: "Show or switch the chat model for this session",
+				Examples:    []string{"/model", "/model large", "/model qwen2.5-coder-7b-instruct"},
+				Args:        []string{"model"},
+			},
+		},
+	}
+}
+
+// CompleteArgument offers the team slots and the probed models
+func (t *modelTool) CompleteArgument(param string) []string {
+	if param != "model" {
+		return nil
+	}
+	values := []string{"small", "medium", "large"}
+	for _, probe := range t.probes {
+		if probe.Size != llm.SizeSpecial {
+			values = append(values, probe.ID)
+		}
+	}
+	return values
+}
+
+// Run shows the chat model or switches to another one
+func (t *modelTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ModelParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	requested := strings.TrimSpace(params.Model)
+	if requested == "" {
+		current := t.chat.ChatModel()
+		if current == "" {
+			current = "(LM Studio's loaded model)"
+		}
+		return NewTextResponse("Chat model: " + current), nil
+	}
+
+	modelID, err := t.resolve(requested)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !t.chat.SetChatModel(modelID) {
+		return NewTextErrorResponse("the current LLM client can't switch models"), nil
+	}
+	if t.sessions != nil {
+		if err := t.sessions.SetModel(modelID); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("switched to %s but failed to save it with the session: %s", modelID, err)), nil
+		}
+	}
+
+	size := llm.DetectModelSize(modelID)
+	for _, probe := range t.probes {
+		if probe.ID == modelID {
+			size = probe.Size
+		}
+	}
+	if t.broker != nil {
+		t.broker.Publish(events.Event{
+			Type: events.ModelSelectedEvent,
+			Payload: events.ModelSelectedPayload{
+				ModelID:   modelID,
+				ModelSize: size,
+			},
+		})
+	}
+
+	return NewTextResponse(fmt.Sprintf("Chat model for this session: %s (%s)", modelID, size)), nil
+}
+
+// resolve turns a team slot, size or model ID into a model ID
+func (t *modelTool) resolve(requested string) (string, error) {
+	slot := strings.ToLower(requested)
+	if t.team != nil {
+		var modelID string
+		switch slot {
+		case "small":
+			modelID = t.team.Small
+		case "medium":
+			modelID = t.team.Medium
+		case "large":
+			modelID = t.team.Large
+		}
+		if modelID != "" {
+			return modelID, nil
+		}
+	}
+	if slot == "small" || slot == "medium" || slot == "large" {
+		return "", fmt.Errorf("the team has no %s model", slot)
+	}
+
+	// A size picks the fastest probed model of that size
+	switch size := llm.ModelSize(strings.ToUpper(requested)); size {
+	case llm.SizeXS, llm.SizeS, llm.SizeM, llm.SizeL, llm.SizeXL:
+		var best *llm.ModelProbe
+		for i, probe := range t.probes {
+			if probe.Size == size && (best == nil || probe.TokensPerSecond > best.TokensPerSecond) {
+				best = &t.probes[i]
+			}
+		}
+		if best == nil {
+			return "", fmt.Errorf("no probed model of size %s", size)
+		}
+		return best.ID, nil
+	}
+
+	// Anything else is a model ID, checked against the probed models when
+	// there are any
+	if len(t.probes) == 0 {
+		return requested, nil
+	}
+	for _, probe := range t.probes {
+		if strings.EqualFold(probe.ID, requested) {
+			return probe.ID, nil
+		}
+	}
+	return "", fmt.Errorf("unknown model %q; /team lists the loaded models", requested)
+}
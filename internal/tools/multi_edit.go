@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/permission"
+)
+
+// MultiEditChange is one file operation within a multi_edit call
+type MultiEditChange struct {
+	Path    string      `json:"path"`              // File to change, relative to the project
+	Content *string     `json:"content,omitempty"` // Full new content (write)
+	Diff    string      `json:"diff,omitempty"`    // Unified diff or SEARCH/REPLACE blocks
+	Edits   []EditBlock `json:"edits,omitempty"`   // Structured search/replace edits
+}
+
+// MultiEditParams represents parameters for the multi_edit tool
+type MultiEditParams struct {
+	Changes []MultiEditChange `json:"changes"`
+}
+
+// multiEditTool applies several file changes as one transaction
+type multiEditTool struct {
+	registry *Registry
+}
+
+const (
+	// MultiEditToolName is the name of this tool
+	MultiEditToolName = "multi_edit"
+	// multiEditDescription describes what this tool does
+	multiEditDescription = `Change several files at once, all or nothing.
+
+WHAT THIS DOES:
+- Applies every change in order; later changes see earlier ones
+- Asks for permission once for the whole set
+- If any change fails to apply or write, no file is left modified
+
+EACH CHANGE HAS:
+- path: file to change
+- one of: content (full new file), diff (unified diff or SEARCH/REPLACE
+  blocks), edits ([{"old_text", "new_text", "replace_all"}])
+
+WHEN TO USE:
+- Renames or refactors that touch several files
+- Adding a file together with the code that uses it`
+)
+
+// NewMultiEditTool creates a new multi_edit tool
+func NewMultiEditTool(registry *Registry) BaseTool {
+	return &multiEditTool{registry: registry}
+}
+
+// Name returns the tool name
+func (t *multiEditTool) Name() string {
+	return MultiEditToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: calls may wait on a permission prompt
+func (t *multiEditTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *multiEditTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        MultiEditToolName,
+		Description: multiEditDescription,
+		Parameters: map[string]any{
+			"changes": map[string]any{
+				"type":        "array",
+				"description": "File changes: objects with path and one of content, diff or edits",
+			},
+		},
+		Required: []string{"changes"},
+	}
+}
+
+// TouchedPaths returns the files the call changes
+func (t *multiEditTool) TouchedPaths(call ToolCall) []string {
+	var params MultiEditParams
+	if json.Unmarshal([]byte(call.Input), &params) != nil {
+		return nil
+	}
+	paths := make([]string, 0, len(params.Changes))
+	for _, change := range params.Changes {
+		paths = append(paths, change.Path)
+	}
+	return paths
+}
+
+// Run stages every change and commits them together
+func (t *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params MultiEditParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	if len(params.Changes) == 0 {
+		return NewTextErrorResponse("changes parameter is required"), nil
+	}
+
+	tx := t.registry.BeginTransaction()
+	for i, change := range params.Changes {
+		if err := stageChange(tx, change); err != nil {
+			tx.Rollback()
+			return NewTextErrorResponse(fmt.Sprintf("change %d not applied, no files were modified: %s", i+1, err)), nil
+		}
+	}
+
+	applied, err := tx.Commit(ctx, MultiEditToolName)
+	if errors.Is(err, permission.ErrorPermissionDenied) {
+		return NewTextErrorResponse("permission denied: no files were modified"), nil
+	}
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if len(applied) == 0 {
+		return NewTextResponse("No changes: every file already matches the requested edits"), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Applied changes to %d files:\n", len(applied)))
+	paths := make([]string, 0, len(applied))
+	for _, change := range applied {
+		verb := "Edited"
+		if !change.Existed {
+			verb = "Created"
+		}
+		sb.WriteString(fmt.Sprintf("- %s %s (+%d -%d lines)\n", verb, change.Path, change.LinesAdded, change.LinesRemoved))
+		paths = append(paths, change.Path)
+	}
+
+	return WithResponseMetadata(NewTextResponse(sb.String()), map[string]any{
+		"paths": paths,
+	}), nil
+}
+
+// stageChange adds one change to the transaction
+func stageChange(tx *Transaction, change MultiEditChange) error {
+	if change.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+
+	set := 0
+	if change.Content != nil {
+		set++
+	}
+	if change.Diff != "" {
+		set++
+	}
+	if len(change.Edits) > 0 {
+		set++
+	}
+	if set != 1 {
+		return fmt.Errorf("%s: provide exactly one of content, diff or edits", change.Path)
+	}
+
+	switch {
+	case change.Content != nil:
+		return tx.Write(change.Path, *change.Content)
+	case change.Diff != "":
+		return tx.Patch(change.Path, change.Diff)
+	default:
+		return tx.Edit(change.Path, change.Edits)
+	}
+}
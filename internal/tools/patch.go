@@ -0,0 +1,332 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EditBlock is a single search/replace edit
+type EditBlock struct {
+	OldText    string `json:"old_text"`              // Exact text to find
+	NewText    string `json:"new_text"`              // Replacement text
+	ReplaceAll bool   `json:"replace_all,omitempty"` // Replace every occurrence instead of requiring exactly one
+}
+
+// patchHunk is one @@ section of a unified diff
+type patchHunk struct {
+	oldStart int
+	oldLines []string // Context and removed lines, in order
+	newLines []string // Context and added lines, in order
+}
+
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+const (
+	searchMarker  = "<<<<<<< SEARCH"
+	dividerMarker = "======="
+	replaceMarker = ">>>>>>> REPLACE"
+
+	// hunkFuzz is how far (in lines) a hunk may drift from its stated position
+	hunkFuzz = 200
+)
+
+// isSearchReplaceBlocks reports whether text uses the SEARCH/REPLACE block format
+func isSearchReplaceBlocks(text string) bool {
+	return strings.Contains(text, searchMarker) && strings.Contains(text, replaceMarker)
+}
+
+// parseSearchReplaceBlocks parses
+//
+//	<<<<<<< SEARCH
+//	old
+//	=======
+//	new
+//	>>>>>>> REPLACE
+//
+// blocks into edits.
+func parseSearchReplaceBlocks(text string) ([]EditBlock, error) {
+	var edits []EditBlock
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != searchMarker {
+			continue
+		}
+		start := i
+		var oldLines, newLines []string
+		i++
+		for i < len(lines) && strings.TrimSpace(lines[i]) != dividerMarker {
+			oldLines = append(oldLines, lines[i])
+			i++
+		}
+		if i >= len(lines) {
+			return nil, fmt.Errorf("block starting at line %d is missing %q", start+1, dividerMarker)
+		}
+		i++
+		for i < len(lines) && strings.TrimSpace(lines[i]) != replaceMarker {
+			newLines = append(newLines, lines[i])
+			i++
+		}
+		if i >= len(lines) {
+			return nil, fmt.Errorf("block starting at line %d is missing %q", start+1, replaceMarker)
+		}
+		edits = append(edits, EditBlock{
+			OldText: strings.Join(oldLines, "\n"),
+			NewText: strings.Join(newLines, "\n"),
+		})
+	}
+
+	if len(edits) == 0 {
+		return nil, fmt.Errorf("no SEARCH/REPLACE blocks found")
+	}
+	return edits, nil
+}
+
+// applyEdits applies search/replace edits in order, requiring each search
+// text to match exactly once unless ReplaceAll is set.
+func applyEdits(content string, edits []EditBlock) (string, error) {
+	for i, edit := range edits {
+		if edit.OldText == "" {
+			return "", fmt.Errorf("edit %d: old_text must not be empty", i+1)
+		}
+		count := strings.Count(content, edit.OldText)
+		switch {
+		case count == 0:
+			return "", fmt.Errorf("edit %d: old_text not found in file%s", i+1, closestLineHint(content, edit.OldText))
+		case count > 1 && !edit.ReplaceAll:
+			return "", fmt.Errorf("edit %d: old_text matches %d places; include more surrounding lines to make it unique or set replace_all", i+1, count)
+		}
+		if edit.ReplaceAll {
+			content = strings.ReplaceAll(content, edit.OldText, edit.NewText)
+		} else {
+			content = strings.Replace(content, edit.OldText, edit.NewText, 1)
+		}
+	}
+	return content, nil
+}
+
+// closestLineHint points at where the first line of a failed search text
+// appears, which usually means whitespace or later lines differ.
+func closestLineHint(content, search string) string {
+	first := strings.TrimSpace(strings.SplitN(search, "\n", 2)[0])
+	if first == "" {
+		return ""
+	}
+	for i, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == first {
+			return fmt.Sprintf(" (its first line resembles line %d; check whitespace and the following lines)", i+1)
+		}
+	}
+	return ""
+}
+
+// parseUnifiedDiff parses the hunks of a single-file unified diff. The
+// header counts decide where a hunk ends, so blank lines after it (models
+// often send one) are not read as context; lines that still start with
+// ' ', '+' or '-' extend a hunk whose counts are too small.
+func parseUnifiedDiff(diff string) ([]patchHunk, error) {
+	var hunks []patchHunk
+	var current *patchHunk
+	files := 0
+	oldLeft, newLeft := 0, 0 // Lines the current hunk's header still expects
+	blanks := 0              // Blank lines past the header counts, kept in case the hunk goes on
+
+	// keepBlanks adds the held blank lines once the hunk turns out to go on
+	keepBlanks := func() {
+		for ; blanks > 0; blanks-- {
+			current.oldLines = append(current.oldLines, "")
+			current.newLines = append(current.newLines, "")
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n")
+	// A trailing newline produces one empty element that is not a diff line
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	for n, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "--- ") && (current == nil || n+1 < len(lines) && strings.HasPrefix(lines[n+1], "+++ ")):
+			files++
+			if files > 1 {
+				return nil, fmt.Errorf("diff touches more than one file; send one edit_file call per file")
+			}
+			current = nil
+		case strings.HasPrefix(line, "+++ ") && current == nil:
+		case strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+		case strings.HasPrefix(line, "@@"):
+			m := hunkHeaderRegex.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("line %d: malformed hunk header %q", n+1, line)
+			}
+			start, _ := strconv.Atoi(m[1])
+			hunks = append(hunks, patchHunk{oldStart: start})
+			current = &hunks[len(hunks)-1]
+			oldLeft, newLeft, blanks = hunkCount(m[2]), hunkCount(m[4]), 0
+		case current == nil:
+			// Preamble text before the first hunk
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		case strings.HasPrefix(line, "+"):
+			keepBlanks()
+			current.newLines = append(current.newLines, line[1:])
+			newLeft--
+		case strings.HasPrefix(line, "-"):
+			keepBlanks()
+			current.oldLines = append(current.oldLines, line[1:])
+			oldLeft--
+		case strings.HasPrefix(line, " "):
+			keepBlanks()
+			current.oldLines = append(current.oldLines, line[1:])
+			current.newLines = append(current.newLines, line[1:])
+			oldLeft--
+			newLeft--
+		case line == "" && oldLeft > 0 && newLeft > 0:
+			// Some models drop the leading space on blank context lines
+			current.oldLines = append(current.oldLines, "")
+			current.newLines = append(current.newLines, "")
+			oldLeft--
+			newLeft--
+		case line == "":
+			blanks++
+		default:
+			return nil, fmt.Errorf("line %d: unexpected diff line %q (lines must start with ' ', '+', '-' or '@@')", n+1, line)
+		}
+	}
+
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("no hunks found; a unified diff needs @@ -start,count +start,count @@ headers")
+	}
+	return hunks, nil
+}
+
+// hunkCount reads a hunk header's line count, which defaults to 1
+func hunkCount(count string) int {
+	if count == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(count)
+	return n
+}
+
+// applyUnifiedDiff applies hunks to content, tolerating hunks whose line
+// numbers have drifted as long as their context still matches exactly.
+func applyUnifiedDiff(content string, hunks []patchHunk) (string, error) {
+	trailingNewline := content == "" || strings.HasSuffix(content, "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	offset := 0 // Net lines added by earlier hunks
+	searchFrom := 0
+	for i, hunk := range hunks {
+		want := hunk.oldStart - 1 + offset
+		if len(hunk.oldLines) == 0 {
+			// Pure insertion: oldStart is the line after which to insert
+			want = hunk.oldStart + offset
+		}
+		pos := findHunk(lines, hunk.oldLines, want, searchFrom)
+		if pos < 0 {
+			return "", fmt.Errorf("hunk %d (@@ -%d) does not match the current file%s", i+1, hunk.oldStart, hunkMismatchHint(lines, hunk.oldLines, want))
+		}
+
+		updated := make([]string, 0, len(lines)-len(hunk.oldLines)+len(hunk.newLines))
+		updated = append(updated, lines[:pos]...)
+		updated = append(updated, hunk.newLines...)
+		updated = append(updated, lines[pos+len(hunk.oldLines):]...)
+		lines = updated
+
+		offset += len(hunk.newLines) - len(hunk.oldLines)
+		searchFrom = pos + len(hunk.newLines)
+	}
+
+	result := strings.Join(lines, "\n")
+	if trailingNewline && len(lines) > 0 {
+		result += "\n"
+	}
+	return result, nil
+}
+
+// findHunk locates old lines at or near want, never before minPos
+func findHunk(lines, old []string, want, minPos int) int {
+	if want < minPos {
+		want = minPos
+	}
+	if len(old) == 0 {
+		if want > len(lines) {
+			return len(lines)
+		}
+		return want
+	}
+	for delta := 0; delta <= hunkFuzz; delta++ {
+		for _, pos := range []int{want + delta, want - delta} {
+			if pos < minPos || pos+len(old) > len(lines) {
+				continue
+			}
+			if linesEqual(lines[pos:pos+len(old)], old) {
+				return pos
+			}
+			if delta == 0 {
+				break
+			}
+		}
+	}
+	return -1
+}
+
+// hunkMismatchHint shows the first differing line at the expected position
+func hunkMismatchHint(lines, old []string, want int) string {
+	for j, expected := range old {
+		pos := want + j
+		if pos < 0 || pos >= len(lines) {
+			return fmt.Sprintf(": expected line %d to be %q but the file has only %d lines", pos+1, expected, len(lines))
+		}
+		if lines[pos] != expected {
+			return fmt.Sprintf(": line %d is %q, diff expects %q", pos+1, lines[pos], expected)
+		}
+	}
+	return ""
+}
+
+func linesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// countLineChanges reports how many lines were added and removed
+func countLineChanges(before, after string) (added, removed int) {
+	oldCounts := make(map[string]int)
+	for _, line := range splitLines(before) {
+		oldCounts[line]++
+	}
+	for _, line := range splitLines(after) {
+		if oldCounts[line] > 0 {
+			oldCounts[line]--
+			continue
+		}
+		added++
+	}
+	for _, n := range oldCounts {
+		removed += n
+	}
+	return added, removed
+}
+
+// splitLines splits content into lines, ignoring a final newline
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		diff    string
+		want    []patchHunk
+		wantErr string
+	}{
+		{
+			name: "single_hunk",
+			diff: "--- a/x.go\n+++ b/x.go\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n",
+			want: []patchHunk{{oldStart: 1, oldLines: []string{"a", "b"}, newLines: []string{"a", "c"}}},
+		},
+		{
+			name: "trailing_blank_line",
+			diff: "@@ -1,2 +1,2 @@\n a\n-b\n+c\n\n",
+			want: []patchHunk{{oldStart: 1, oldLines: []string{"a", "b"}, newLines: []string{"a", "c"}}},
+		},
+		{
+			name: "blank_context_without_space",
+			diff: "@@ -1,3 +1,3 @@\n a\n\n-b\n+c\n",
+			want: []patchHunk{{oldStart: 1, oldLines: []string{"a", "", "b"}, newLines: []string{"a", "", "c"}}},
+		},
+		{
+			name: "counts_too_small",
+			diff: "@@ -1,1 +1,1 @@\n-a\n+b\n\n c\n",
+			want: []patchHunk{{oldStart: 1, oldLines: []string{"a", "", "c"}, newLines: []string{"b", "", "c"}}},
+		},
+		{
+			name: "blank_lines_between_hunks",
+			diff: "@@ -1 +1 @@\n-a\n+b\n\n@@ -5 +5 @@\n-e\n+f\n",
+			want: []patchHunk{
+				{oldStart: 1, oldLines: []string{"a"}, newLines: []string{"b"}},
+				{oldStart: 5, oldLines: []string{"e"}, newLines: []string{"f"}},
+			},
+		},
+		{
+			name: "no_newline_marker",
+			diff: "@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+b\n",
+			want: []patchHunk{{oldStart: 1, oldLines: []string{"a"}, newLines: []string{"b"}}},
+		},
+		{
+			name:    "two_files",
+			diff:    "--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n--- a/y\n+++ b/y\n@@ -1 +1 @@\n-a\n+b\n",
+			wantErr: "more than one file",
+		},
+		{
+			name:    "malformed_header",
+			diff:    "@@ -x +1 @@\n-a\n",
+			wantErr: "malformed hunk header",
+		},
+		{
+			name:    "no_hunks",
+			diff:    "just some text\n",
+			wantErr: "no hunks found",
+		},
+		{
+			name:    "unexpected_line",
+			diff:    "@@ -1 +1 @@\n-a\n+b\nnot a diff line\n",
+			wantErr: "unexpected diff line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseUnifiedDiff(tt.diff)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hunks:\ngot  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		diff    string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "replace_line",
+			content: "a\nb\n",
+			diff:    "@@ -1,2 +1,2 @@\n a\n-b\n+c\n\n",
+			want:    "a\nc\n",
+		},
+		{
+			name:    "drifted_hunk",
+			content: "x\ny\na\nb\n",
+			diff:    "@@ -1,2 +1,2 @@\n a\n-b\n+c\n",
+			want:    "x\ny\na\nc\n",
+		},
+		{
+			name:    "two_hunks_with_offset",
+			content: "1\n2\n3\n4\n5\n",
+			diff:    "@@ -1 +1,2 @@\n 1\n+1.5\n@@ -4 +5 @@\n-4\n+four\n",
+			want:    "1\n1.5\n2\n3\nfour\n5\n",
+		},
+		{
+			name:    "pure_insertion",
+			content: "a\nb\n",
+			diff:    "@@ -1,0 +2 @@\n+inserted\n",
+			want:    "a\ninserted\nb\n",
+		},
+		{
+			name:    "new_file",
+			content: "",
+			diff:    "@@ -0,0 +1,2 @@\n+a\n+b\n",
+			want:    "a\nb\n",
+		},
+		{
+			name:    "no_trailing_newline_kept",
+			content: "a\nb",
+			diff:    "@@ -2 +2 @@\n-b\n+c\n",
+			want:    "a\nc",
+		},
+		{
+			name:    "context_mismatch",
+			content: "a\nb\n",
+			diff:    "@@ -1,2 +1,2 @@\n a\n-x\n+c\n",
+			wantErr: `line 2 is "b", diff expects "x"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hunks, err := parseUnifiedDiff(tt.diff)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got, err := applyUnifiedDiff(tt.content, hunks)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindHunk(t *testing.T) {
+	lines := []string{"a", "b", "c", "a", "b"}
+	tests := []struct {
+		name   string
+		old    []string
+		want   int
+		minPos int
+		pos    int
+	}{
+		{name: "at_position", old: []string{"c"}, want: 2, pos: 2},
+		{name: "before_position", old: []string{"c"}, want: 4, pos: 2},
+		{name: "after_position", old: []string{"c"}, want: 0, pos: 2},
+		{name: "nearest_of_two", old: []string{"a", "b"}, want: 4, pos: 3},
+		{name: "not_before_min", old: []string{"a", "b"}, want: 0, minPos: 1, pos: 3},
+		{name: "missing", old: []string{"z"}, want: 0, pos: -1},
+		{name: "insertion_clamped", old: nil, want: 9, pos: 5},
+		{name: "insertion_at_min", old: nil, want: 1, minPos: 3, pos: 3},
+	}
+	for _, tt := range tests {
+		if got := findHunk(lines, tt.old, tt.want, tt.minPos); got != tt.pos {
+			t.Errorf("%s: findHunk = %d, want %d", tt.name, got, tt.pos)
+		}
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		edits   []EditBlock
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "single_edit",
+			content: "func a() {}\nfunc b() {}\n",
+			edits:   []EditBlock{{OldText: "func b() {}", NewText: "func c() {}"}},
+			want:    "func a() {}\nfunc c() {}\n",
+		},
+		{
+			name:    "edits_apply_in_order",
+			content: "x = 1\n",
+			edits:   []EditBlock{{OldText: "x = 1", NewText: "x = 2"}, {OldText: "x = 2", NewText: "x = 3"}},
+			want:    "x = 3\n",
+		},
+		{
+			name:    "replace_all",
+			content: "a a a",
+			edits:   []EditBlock{{OldText: "a", NewText: "b", ReplaceAll: true}},
+			want:    "b b b",
+		},
+		{
+			name:    "ambiguous",
+			content: "a a",
+			edits:   []EditBlock{{OldText: "a", NewText: "b"}},
+			wantErr: "matches 2 places",
+		},
+		{
+			name:    "not_found_with_hint",
+			content: "one\n  two\n",
+			edits:   []EditBlock{{OldText: "two\nthree", NewText: "x"}},
+			wantErr: "resembles line 2",
+		},
+		{
+			name:    "empty_old_text",
+			content: "a",
+			edits:   []EditBlock{{OldText: "", NewText: "b"}},
+			wantErr: "must not be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyEdits(tt.content, tt.edits)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
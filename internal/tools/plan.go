@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/tasks"
+)
+
+// PlanParams represents parameters for the plan tool
+type PlanParams struct {
+	Action string   `json:"action,omitempty"` // "show", "new", "start", "done", "skip", "fail" or "clear"
+	Text   string   `json:"text,omitempty"`   // The goal for new, or the step number for start, done, skip and fail
+	Steps  []string `json:"steps,omitempty"`  // The steps for new; without them the model proposes some
+}
+
+// planTool tracks the current session's plan
+type planTool struct {
+	tracker *tasks.Tracker
+	client  llm.Client
+}
+
+const (
+	// PlanToolName is the name of this tool
+	PlanToolName = "plan"
+	// planDescription describes what this tool does
+	planDescription = `Track a multi-step plan for the current session.
+
+WHEN TO USE:
+- Before work that takes several steps: propose the plan with new, giving the goal and the steps
+- As work goes on: mark each step done (or skip, or fail) so the next one starts
+
+ACTIONS:
+- show (default): the plan as a checklist, with the tools run for each step
+- new: start a plan for the goal in text; without steps, the model proposes them
+- start / done / skip / fail: set the status of the step numbered in text, or of the step in progress
+- clear: drop the plan
+
+One step is in progress at a time, and every tool run is recorded against it. The plan is saved with the session.`
+)
+
+// statusActions maps the status actions to the status they set
+var statusActions = map[string]tasks.Status{
+	"start": tasks.StatusActive,
+	"done":  tasks.StatusDone,
+	"skip":  tasks.StatusSkipped,
+	"fail":  tasks.StatusFailed,
+}
+
+// NewPlanTool creates a new plan tool. client proposes steps for plans
+// started without them; it may be nil.
+func NewPlanTool(tracker *tasks.Tracker, client llm.Client) BaseTool {
+	return &planTool{tracker: tracker, client: client}
+}
+
+// Name returns the tool name
+func (t *planTool) Name() string {
+	return PlanToolName
+}
+
+// Info returns the tool information
+func (t *planTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        PlanToolName,
+		Description: planDescription,
+		Parameters: map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "What to do (default show)",
+				"enum":        []string{"show", "new", "start", "done", "skip", "fail", "clear"},
+			},
+			"text": map[string]any{
+				"type":        "string",
+				"description": "The goal for new; the step number for start, done, skip and fail (default the step in progress)",
+			},
+			"steps": map[string]any{
+				"type":        "array",
+				"description": "The steps for new, in order, each a short imperative line",
+				"items":       map[string]any{"type": "string"},
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "plan",
+				Description: "Show or update the session's plan",
+				Examples:    []string{"/plan", "/plan new add OAuth login", "/plan done", "/plan start 3", "/plan clear"},
+				Args:        []string{"action", "text"},
+			},
+		},
+	}
+}
+
+// Run shows or changes the plan
+func (t *planTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params PlanParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	text := strings.TrimSpace(params.Text)
+
+	action := strings.TrimSpace(params.Action)
+	switch action {
+	case "", "show":
+		plan, err := t.tracker.Current()
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("failed to read the plan: %s", err)), nil
+		}
+		if plan == nil {
+			return NewTextResponse("No plan yet: start one with /plan new <goal>"), nil
+		}
+		return planResponse(plan), nil
+
+	case "new":
+		steps := params.Steps
+		if len(steps) == 0 {
+			if text == "" {
+				return NewTextErrorResponse("usage: /plan new <goal>"), nil
+			}
+			if t.client == nil {
+				return NewTextErrorResponse("no model to propose steps; give the steps yourself"), nil
+			}
+			proposed, err := tasks.Propose(ctx, t.client, text)
+			if err != nil {
+				return NewTextErrorResponse(err.Error()), nil
+			}
+			steps = proposed
+		}
+		plan, err := tasks.New(text, steps)
+		if err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		if err := t.tracker.Set(plan); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("failed to save the plan: %s", err)), nil
+		}
+		return planResponse(plan), nil
+
+	case "clear":
+		if err := t.tracker.Set(nil); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("failed to clear the plan: %s", err)), nil
+		}
+		return NewTextResponse("Plan cleared"), nil
+
+	default:
+		status, ok := statusActions[action]
+		if !ok {
+			return NewTextErrorResponse(fmt.Sprintf("unknown action %q; use show, new, start, done, skip, fail or clear", action)), nil
+		}
+		n := 0
+		if text != "" {
+			var err error
+			if n, err = strconv.Atoi(strings.TrimPrefix(text, "#")); err != nil {
+				return NewTextErrorResponse(fmt.Sprintf("usage: /plan %s [step number]", action)), nil
+			}
+		}
+		plan, err := t.tracker.Update(func(p *tasks.Plan) error {
+			return p.SetStatus(n, status)
+		})
+		if err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		return planResponse(plan), nil
+	}
+}
+
+// planResponse shows the plan, with the plan itself as metadata for
+// clients that render it
+func planResponse(plan *tasks.Plan) ToolResponse {
+	return WithResponseMetadata(NewTextResponse(plan.String()), plan)
+}
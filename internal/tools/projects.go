@@ -0,0 +1,210 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/analysis"
+)
+
+// ProjectsParams represents parameters for the projects tool
+type ProjectsParams struct {
+	Action string `json:"action,omitempty"` // "search" or "show"
+	Query  string `json:"query,omitempty"`  // Words to search for, or the project to show
+}
+
+// projectsTool searches the knowledge other projects' analyses left in the
+// global registry
+type projectsTool struct {
+	workingDir string
+}
+
+// maxProjectContext caps how much of another project's knowledge /projects
+// show pulls into the conversation
+const maxProjectContext = 24000
+
+const (
+	// ProjectsToolName is the name of this tool
+	ProjectsToolName = "projects"
+	// projectsDescription describes what this tool does
+	projectsDescription = `Search the knowledge of other projects analyzed on this machine and pull it into the conversation.
+
+WHEN TO USE:
+- The code uses a sibling repository, such as a shared internal library, and you need to know how it works
+- To find which of the user's projects deals with something
+
+OUTPUT:
+- Without an action: every registered project with a one-line summary
+- search <words>: projects whose knowledge mentions the words, with matching lines
+- show <project>: that project's knowledge documents from its most complete analysis
+
+Every analysis run records its knowledge in ~/.loco/registry/, so a project appears once it has been analyzed.`
+)
+
+// NewProjectsTool creates a new projects tool
+func NewProjectsTool(workingDir string) BaseTool {
+	return &projectsTool{workingDir: workingDir}
+}
+
+// Name returns the tool name
+func (t *projectsTool) Name() string {
+	return ProjectsToolName
+}
+
+// Info returns the tool information
+func (t *projectsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ProjectsToolName,
+		Description: projectsDescription,
+		Parameters: map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "What to do",
+				"enum":        []string{"search", "show"},
+			},
+			"query": map[string]any{
+				"type":        "string",
+				"description": "Words to search for, or the name or path of the project to show",
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "projects",
+				Description: "Search other analyzed projects and pull in their knowledge",
+				Examples:    []string{"/projects", "/projects search auth middleware", "/projects show shared-lib"},
+				Args:        []string{"action", "query"},
+			},
+		},
+	}
+}
+
+// CompleteArgument offers the registered projects' names to show
+func (t *projectsTool) CompleteArgument(param string) []string {
+	if param != "query" {
+		return nil
+	}
+	entries, err := analysis.LoadRegistry()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+// Run lists, searches or shows registered projects
+func (t *projectsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ProjectsParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	entries, err := analysis.LoadRegistry()
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to read the project registry: %s", err)), nil
+	}
+	if len(entries) == 0 {
+		return NewTextResponse("No projects registered yet: run an analysis in a project to add it"), nil
+	}
+
+	query := strings.TrimSpace(params.Query)
+	switch strings.TrimSpace(params.Action) {
+	case "":
+		return NewTextResponse(t.list(entries)), nil
+	case "search":
+		if query == "" {
+			return NewTextErrorResponse("usage: /projects search <words>"), nil
+		}
+		return NewTextResponse(t.search(entries, query)), nil
+	case "show":
+		if query == "" {
+			return NewTextErrorResponse("usage: /projects show <project>"), nil
+		}
+		entry, err := analysis.FindRegistryEntry(entries, query)
+		if err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		return NewTextResponse(showProject(entry)), nil
+	default:
+		return NewTextErrorResponse(fmt.Sprintf("unknown action %q; use /projects search <words> or /projects show <project>", params.Action)), nil
+	}
+}
+
+// list describes every registered project
+func (t *projectsTool) list(entries []*analysis.RegistryEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d analyzed projects:\n", len(entries))
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "\n%s%s\n  %s\n  %s\n", entry.Name, t.marker(entry), entry.Path, describeEntry(entry))
+	}
+	b.WriteString("\nUse /projects show <project> to pull one's knowledge into the conversation.")
+	return b.String()
+}
+
+// search lists the projects matching query with the lines that matched
+func (t *projectsTool) search(entries []*analysis.RegistryEntry, query string) string {
+	matches := analysis.SearchRegistry(entries, query)
+	if len(matches) == 0 {
+		return fmt.Sprintf("No analyzed project mentions %q", query)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Projects mentioning %q:\n", query)
+	for _, match := range matches {
+		fmt.Fprintf(&b, "\n%s%s (%s)\n  %s\n", match.Entry.Name, t.marker(match.Entry), match.Entry.Path, match.Entry.Summary)
+		for _, line := range match.Lines {
+			fmt.Fprintf(&b, "  > %s\n", line)
+		}
+	}
+	return b.String()
+}
+
+// marker flags the project the session is working in
+func (t *projectsTool) marker(entry *analysis.RegistryEntry) string {
+	if abs, err := filepath.Abs(t.workingDir); err == nil && abs == entry.Path {
+		return " (this project)"
+	}
+	return ""
+}
+
+// describeEntry summarizes a registry entry in a line
+func describeEntry(entry *analysis.RegistryEntry) string {
+	tier, knowledge, ok := entry.Best()
+	if !ok {
+		return entry.Summary
+	}
+	return fmt.Sprintf("%s (%s analysis, %s)", entry.Summary, tier, knowledge.Generated.Format("Jan 2"))
+}
+
+// showProject renders a project's most complete knowledge, cut off at
+// maxProjectContext
+func showProject(entry *analysis.RegistryEntry) string {
+	tier, knowledge, ok := entry.Best()
+	if !ok {
+		return fmt.Sprintf("%s has no knowledge recorded", entry.Name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Knowledge of %s (%s)\n\nFrom its %s analysis on %s. %s\n",
+		entry.Name, entry.Path, tier, knowledge.Generated.Format("Jan 2, 2006"), entry.Summary)
+	for _, name := range slices.Sorted(maps.Keys(knowledge.Knowledge)) {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", name, strings.TrimSpace(knowledge.Knowledge[name]))
+		if b.Len() > maxProjectContext {
+			break
+		}
+	}
+
+	doc := b.String()
+	if len(doc) > maxProjectContext {
+		doc = strings.ToValidUTF8(doc[:maxProjectContext], "") + "\n\n[truncated]"
+	}
+	return doc
+}
@@ -177,6 +177,9 @@ func (r *ragTool) formatResults(query string, results []sidecar.SimilarDocument,
 		if lang, ok := doc.Metadata["language"].(string); ok {
 			response.WriteString(fmt.Sprintf(" • %s", lang))
 		}
+		if symbol, ok := doc.Metadata["symbol"].(string); ok && symbol != "" {
+			response.WriteString(fmt.Sprintf(" • %s", symbol))
+		}
 		
 		response.WriteString("\n\n")
 		
@@ -172,13 +172,14 @@ func (r *ragIndexTool) Run(ctx context.Context, call ToolCall) (ToolResponse, er
 	// Get progress publisher - all tools have access to this as baseline capability
 	publishProgress := GetProgressPublisher(ctx)
 	
-	// Check if embedding model is available in LM Studio
+	// Track the embedding model so switching it triggers a re-index, and
+	// check it is loaded when LM Studio serves the embeddings
 	embeddingModel := ""
-	if r.llmClient != nil && r.configManager != nil {
-		if lmClient, ok := r.llmClient.(*llm.LMStudioClient); ok {
-			cfg := r.configManager.Get()
-			if cfg != nil && cfg.Analysis.RAG.EmbeddingModel != "" {
-				embeddingModel = cfg.Analysis.RAG.EmbeddingModel
+	if r.configManager != nil {
+		if cfg := r.configManager.Get(); cfg != nil {
+			embeddingModel = cfg.Analysis.RAG.ResolvedEmbeddingModel()
+			usesLMStudio := cfg.Analysis.RAG.Embedder == "" || cfg.Analysis.RAG.Embedder == "lmstudio"
+			if lmClient, ok := r.llmClient.(*llm.LMStudioClient); ok && usesLMStudio {
 				if err := lmClient.CheckEmbeddingModel(embeddingModel); err != nil {
 					return NewTextErrorResponse(fmt.Sprintf("❌ Embedding model check failed: %s\n\nPlease load the embedding model in LM Studio before indexing.", err)), nil
 				}
@@ -374,7 +375,7 @@ func (r *ragIndexTool) Run(ctx context.Context, call ToolCall) (ToolResponse, er
 			failed++
 			response += fmt.Sprintf("❌ File %d/%d failed: %s - %v\n", i+1, len(filesToIndex), relPath, err)
 			newFileStates[file] = sidecar.FileState{
-				Hash:      computeFileHash(file),
+				Hash:      sidecar.FileHash(file),
 				IndexedAt: time.Now(),
 				Success:   false,
 				Error:     err.Error(),
@@ -385,7 +386,7 @@ func (r *ragIndexTool) Run(ctx context.Context, call ToolCall) (ToolResponse, er
 				response += fmt.Sprintf("✅ File %d/%d indexed: %s\n", i+1, len(filesToIndex), relPath)
 			}
 			newFileStates[file] = sidecar.FileState{
-				Hash:      computeFileHash(file),
+				Hash:      sidecar.FileHash(file),
 				IndexedAt: time.Now(),
 				Success:   true,
 			}
@@ -446,20 +447,6 @@ func (r *ragIndexTool) Run(ctx context.Context, call ToolCall) (ToolResponse, er
 
 // fileHashChanged checks if a file's hash has changed
 func fileHashChanged(filePath string, oldHash string) bool {
-	newHash := computeFileHash(filePath)
+	newHash := sidecar.FileHash(filePath)
 	return newHash != oldHash
 }
-
-// computeFileHash computes a hash of a file's content
-func computeFileHash(filePath string) string {
-	info, err := os.Stat(filePath)
-	if err != nil {
-		return ""
-	}
-	
-	h := sha256.New()
-	h.Write([]byte(filePath))
-	h.Write([]byte(fmt.Sprintf("%d%d", info.Size(), info.ModTime().Unix())))
-	return fmt.Sprintf("%x", h.Sum(nil))
-}
-
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+// ragPruneTool removes stale vectors and compacts the RAG database
+type ragPruneTool struct {
+	sidecarService sidecar.Service
+}
+
+const (
+	// RagPruneToolName is the name of this tool
+	RagPruneToolName = "rag_prune"
+	// ragPruneDescription describes what this tool does
+	ragPruneDescription = `Remove stale entries from the RAG index and compact it.
+
+WHAT THIS DOES:
+- Deletes chunks for files no longer listed by git ls-files
+- Keeps embedded documentation pages while they are cached under .loco/cache/web
+- Vacuums the vector database
+- Reports reclaimed disk space
+
+WHEN TO USE:
+- After deleting or moving many files
+- When .loco/vectors.db has grown large on a long-lived project`
+)
+
+// NewRagPruneTool creates a new RAG prune tool
+func NewRagPruneTool(sidecarService sidecar.Service) BaseTool {
+	return &ragPruneTool{
+		sidecarService: sidecarService,
+	}
+}
+
+// Name returns the tool name
+func (r *ragPruneTool) Name() string {
+	return RagPruneToolName
+}
+
+// Info returns the tool information
+func (r *ragPruneTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RagPruneToolName,
+		Description: ragPruneDescription,
+		Parameters:  map[string]any{},
+		Required:    []string{},
+		Commands: []CommandInfo{
+			{
+				Command:     "rag-prune",
+				Description: "Remove stale vectors and compact the index",
+				Examples:    []string{"/rag-prune"},
+			},
+		},
+	}
+}
+
+// Run prunes the vector store
+func (r *ragPruneTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	if r.sidecarService == nil {
+		return NewTextErrorResponse("RAG service not available"), nil
+	}
+
+	result, err := r.sidecarService.Prune(ctx)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("RAG prune failed: %s", err)), nil
+	}
+
+	var response strings.Builder
+	response.WriteString("🧹 **RAG Index Pruned**\n\n")
+	if result.RemovedFiles == 0 {
+		response.WriteString("No stale files found.\n")
+	} else {
+		response.WriteString(fmt.Sprintf("Removed %d chunks from %d files no longer in the project.\n", result.RemovedChunks, result.RemovedFiles))
+	}
+	response.WriteString(fmt.Sprintf("Database: %s → %s (reclaimed %s)\n",
+		formatBytes(result.BytesBefore), formatBytes(result.BytesAfter), formatBytes(result.Reclaimed())))
+
+	return NewTextResponse(response.String()), nil
+}
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,346 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/permission"
+	"github.com/billie-coop/loco/internal/sidecar"
+)
+
+// RefactorParams represents parameters for the refactor tool
+type RefactorParams struct {
+	Goal string `json:"goal"` // What the refactoring should achieve
+}
+
+// Refactor file actions
+const (
+	RefactorCreate = "create"
+	RefactorEdit   = "edit"
+)
+
+// Refactor file statuses
+const (
+	RefactorStaged  = "staged"  // Its change is part of the approved set
+	RefactorFailed  = "failed"  // The model's change could not be applied
+	RefactorSkipped = "skipped" // Not attempted, e.g. too large or over the limit
+)
+
+// RefactorFile is one file of a refactoring plan
+type RefactorFile struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Why    string `json:"why"`
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RefactorPlan is the metadata of a refactor response
+type RefactorPlan struct {
+	Goal      string         `json:"goal"`
+	Summary   string         `json:"summary"`
+	Structure string         `json:"structure"`
+	Files     []RefactorFile `json:"files"`
+	Applied   bool           `json:"applied"`
+}
+
+// refactorTool plans a multi-file change and generates its edits
+type refactorTool struct {
+	registry       *Registry
+	sidecarService sidecar.Service
+	llmClient      llm.Client
+}
+
+// Limits on what a refactoring reads and changes
+const (
+	maxRefactorFiles       = 10    // Files changed in one run
+	maxRefactorFileBytes   = 40000 // Larger files are skipped rather than sent whole
+	maxRefactorChunks      = 10    // Related chunks shown to the planner
+	maxRefactorFileList    = 6000  // Bytes of the project file list shown to the planner
+	maxRefactorWrittenFile = 3000  // Bytes of each new file shown to later steps
+)
+
+const (
+	// RefactorToolName is the name of this tool
+	RefactorToolName = "refactor"
+	// refactorDescription describes what this tool does
+	refactorDescription = `Plan a refactoring that spans several files and generate the edits for it.
+
+WHAT THIS DOES:
+- Proposes a plan from the project's structure, patterns and the code related to the goal: the files to create and edit and the resulting layout
+- Has the model write each file's change: new files whole, existing ones as SEARCH/REPLACE edits
+- Asks for permission once, showing the whole diff; nothing is written unless approved
+
+WHEN TO USE:
+- Moving code into a new package, splitting a file, extracting an interface
+
+LIMITATIONS:
+- At most 10 files per run; a file whose edit doesn't apply is left out and reported
+- Run the build and tests after applying; the edits are generated, not compiled`
+)
+
+// NewRefactorTool creates a new refactor tool. llmClient may be nil until
+// a model is configured, and sidecarService when RAG is off.
+func NewRefactorTool(registry *Registry, sidecarService sidecar.Service, llmClient llm.Client) BaseTool {
+	return &refactorTool{registry: registry, sidecarService: sidecarService, llmClient: llmClient}
+}
+
+// Name returns the tool name
+func (t *refactorTool) Name() string {
+	return RefactorToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: calls wait on the model and a permission prompt
+func (t *refactorTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *refactorTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RefactorToolName,
+		Description: refactorDescription,
+		Parameters: map[string]any{
+			"goal": map[string]any{
+				"type":        "string",
+				"description": "What the refactoring should achieve, e.g. \"extract HTTP handling into its own package\"",
+			},
+		},
+		Required: []string{"goal"},
+		Commands: []CommandInfo{
+			{
+				Command:     "refactor",
+				Description: "Plan a multi-file refactoring and apply it after review",
+				Examples:    []string{"/refactor extract HTTP handling into its own package", "/refactor split tools.go into one file per tool"},
+			},
+		},
+	}
+}
+
+// Run plans the change, generates each file's edit and stages them for
+// one approval
+func (t *refactorTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	if t.llmClient == nil {
+		return NewTextErrorResponse("No model available to plan with - check your LM Studio connection"), nil
+	}
+	var params RefactorParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	params.Goal = strings.TrimSpace(params.Goal)
+	if params.Goal == "" {
+		return NewTextErrorResponse("goal parameter is required"), nil
+	}
+
+	publish := GetProgressPublisher(ctx)
+	publish("Planning", 0, 0, params.Goal)
+	plan, err := t.plan(ctx, params.Goal)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	tx := t.registry.BeginTransaction()
+	var written strings.Builder
+	attempted := 0
+	for i := range plan.Files {
+		file := &plan.Files[i]
+		if attempted == maxRefactorFiles {
+			file.Status, file.Error = RefactorSkipped, fmt.Sprintf("over the limit of %d files per run", maxRefactorFiles)
+			continue
+		}
+		attempted++
+		publish("Writing", len(plan.Files), i, file.Path)
+		if err := t.stageFile(ctx, tx, plan, file, written.String()); err != nil {
+			file.Status, file.Error = RefactorFailed, err.Error()
+			continue
+		}
+		file.Status = RefactorStaged
+		if file.Action == RefactorCreate {
+			for _, change := range tx.Changes() {
+				if change.Path == file.Path {
+					fmt.Fprintf(&written, "FILE: %s\n```\n%s\n```\n\n", file.Path, truncateOutput(change.Content, maxRefactorWrittenFile))
+				}
+			}
+		}
+	}
+
+	applied, err := tx.Commit(ctx, RefactorToolName)
+	if errors.Is(err, permission.ErrorPermissionDenied) {
+		return WithResponseMetadata(NewTextErrorResponse(formatRefactorPlan(plan)+"\n\nPermission denied: no files were modified"), plan), nil
+	}
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	plan.Applied = len(applied) > 0
+
+	text := formatRefactorPlan(plan)
+	if plan.Applied {
+		text += fmt.Sprintf("\n\nApplied changes to %d files. Build and run the tests to check them.", len(applied))
+	} else {
+		text += "\n\nNo file changed: none of the generated edits applied."
+	}
+	return WithResponseMetadata(NewTextResponse(text), plan), nil
+}
+
+// plan has the model propose the files to change for goal
+func (t *refactorTool) plan(ctx context.Context, goal string) (*RefactorPlan, error) {
+	workingDir := t.registry.workingDir
+	data := map[string]any{"Goal": goal, "Structure": "", "Patterns": "", "Code": "", "Files": ""}
+	for field, kind := range map[string]string{"Structure": "structure", "Patterns": "patterns"} {
+		if _, content, ok := analysis.LoadBestKnowledge(workingDir, kind); ok {
+			data[field] = truncateOutput(content, 4000)
+		}
+	}
+	if t.sidecarService != nil {
+		if results, err := t.sidecarService.QuerySimilar(ctx, goal, maxRefactorChunks); err == nil {
+			var code strings.Builder
+			for _, result := range results {
+				path := result.Path
+				if _, rel, err := resolveProjectPath(workingDir, path); err == nil {
+					path = rel
+				}
+				fmt.Fprintf(&code, "%s\n```\n%s\n```\n\n", path, truncateOutput(result.Content, 1500))
+			}
+			data["Code"] = strings.TrimSpace(code.String())
+		}
+	}
+	files, err := analysis.GetProjectFiles(workingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project files: %w", err)
+	}
+	data["Files"] = truncateOutput(strings.Join(files, "\n"), maxRefactorFileList)
+
+	reply, err := t.llmClient.Complete(ctx, []llm.Message{
+		{Role: "user", Content: analysis.RenderPrompt(workingDir, "refactor_plan", data)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan: %w", err)
+	}
+	plan := &RefactorPlan{Goal: goal}
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end <= start || json.Unmarshal([]byte(reply[start:end+1]), plan) != nil {
+		return nil, fmt.Errorf("the model's plan was not valid JSON:\n%s", truncateOutput(reply, 2000))
+	}
+	plan.Goal = goal
+
+	// New files first, so the edits that use them can see them
+	var kept []RefactorFile
+	seen := map[string]bool{}
+	for _, file := range plan.Files {
+		_, rel, err := resolveProjectPath(workingDir, strings.TrimSpace(file.Path))
+		if err != nil || seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		file.Path = rel
+		if file.Action != RefactorCreate {
+			file.Action = RefactorEdit
+		}
+		kept = append(kept, file)
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].Action == RefactorCreate && kept[j].Action != RefactorCreate
+	})
+	plan.Files = kept
+	if len(plan.Files) == 0 {
+		return nil, fmt.Errorf("the model's plan names no files to change")
+	}
+	return plan, nil
+}
+
+// stageFile has the model write one file's change and stages it
+func (t *refactorTool) stageFile(ctx context.Context, tx *Transaction, plan *RefactorPlan, file *RefactorFile, written string) error {
+	content := ""
+	absPath, _, err := resolveProjectPath(t.registry.workingDir, file.Path)
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(absPath)
+	switch {
+	case file.Action == RefactorCreate && err == nil:
+		return fmt.Errorf("already exists; the plan should edit it")
+	case file.Action == RefactorEdit && os.IsNotExist(err):
+		return fmt.Errorf("does not exist; the plan should create it")
+	case file.Action == RefactorEdit && err != nil:
+		return err
+	case len(existing) > maxRefactorFileBytes:
+		return fmt.Errorf("too large to edit here (%d bytes)", len(existing))
+	}
+	content = string(existing)
+
+	var listed strings.Builder
+	for _, f := range plan.Files {
+		fmt.Fprintf(&listed, "- %s %s: %s\n", f.Action, f.Path, f.Why)
+	}
+	reply, err := t.llmClient.Complete(ctx, []llm.Message{
+		{Role: "user", Content: analysis.RenderPrompt(t.registry.workingDir, "refactor_file", map[string]any{
+			"Goal":      plan.Goal,
+			"Summary":   plan.Summary,
+			"Structure": plan.Structure,
+			"Plan":      strings.TrimSpace(listed.String()),
+			"Written":   strings.TrimSpace(written),
+			"Action":    file.Action,
+			"Path":      file.Path,
+			"Why":       file.Why,
+			"Content":   content,
+		})},
+	})
+	if err != nil {
+		return err
+	}
+
+	if file.Action == RefactorCreate {
+		return tx.Write(file.Path, fencedContent(reply))
+	}
+	if !isSearchReplaceBlocks(reply) {
+		return fmt.Errorf("the model replied without SEARCH/REPLACE blocks")
+	}
+	return tx.Patch(file.Path, reply)
+}
+
+// fencedContent returns the first fenced code block of a reply, or the
+// whole reply when it has none, ending in a newline
+func fencedContent(reply string) string {
+	lines := strings.Split(reply, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "```") {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "```" {
+			end++
+		}
+		return strings.TrimRight(strings.Join(lines[i+1:min(end, len(lines))], "\n"), "\n") + "\n"
+	}
+	return strings.TrimRight(strings.TrimSpace(reply), "\n") + "\n"
+}
+
+// formatRefactorPlan renders the plan and each file's outcome
+func formatRefactorPlan(plan *RefactorPlan) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Refactoring: %s\n", plan.Goal)
+	if plan.Summary != "" {
+		fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(plan.Summary))
+	}
+	if plan.Structure != "" {
+		fmt.Fprintf(&b, "\nStructure after the change:\n%s\n", strings.TrimSpace(plan.Structure))
+	}
+	b.WriteString("\nFiles:\n")
+	for _, file := range plan.Files {
+		fmt.Fprintf(&b, "- %s %s: %s", file.Action, file.Path, file.Why)
+		if file.Status != "" && file.Status != RefactorStaged {
+			fmt.Fprintf(&b, " [%s: %s]", file.Status, file.Error)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,356 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/lsp"
+)
+
+// goFile is a parsed Go file of the project
+type goFile struct {
+	path    string // Relative to the project, with forward slashes
+	content string
+	fset    *token.FileSet
+	ast     *ast.File
+}
+
+// renameGo renames the Go identifier at pos without type information. A
+// local is renamed where the parser resolved it; a package-level
+// declaration in its package and, when exported, wherever another package
+// selects it through an import. Methods and fields are refused: telling
+// them apart from others of the same name needs the types.
+func renameGo(workingDir, relPath, content string, pos lsp.Position, symbol, newName string) (map[string]string, []RenameLocation, error) {
+	target, err := parseGoFile(filepath.ToSlash(relPath), content)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines := strings.Split(content, "\n")
+	offset := 0
+	for _, line := range lines[:pos.Line] {
+		offset += len(line) + 1
+	}
+	offset += lsp.ByteColumn(lines[pos.Line], pos.Character)
+
+	var ident *ast.Ident
+	parents := map[ast.Node]ast.Node{}
+	var stack []ast.Node
+	ast.Inspect(target.ast, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		if len(stack) > 0 {
+			parents[n] = stack[len(stack)-1]
+		}
+		stack = append(stack, n)
+		if id, ok := n.(*ast.Ident); ok && target.fset.Position(id.Pos()).Offset == offset && id.Name == symbol {
+			ident = id
+		}
+		return true
+	})
+	if ident == nil {
+		return nil, nil, fmt.Errorf("%s:%d: %s there is not an identifier (a comment or a string?)", relPath, pos.Line+1, symbol)
+	}
+
+	needsTypes := fmt.Errorf("%s looks like a method, a field or a name from another package; renaming it safely needs a language server (enable gopls under lsp in .loco/config.jsonc), or point at its declaration", symbol)
+	if isGoMemberDeclaration(ident, parents) {
+		return nil, nil, needsTypes
+	}
+	if ident.Obj != nil && !isTopLevel(target.ast, ident.Obj) {
+		return renameGoLocal(target, ident, newName)
+	}
+	if !isGoPackageMember(ident, parents) {
+		return nil, nil, needsTypes
+	}
+	return renameGoPackageLevel(workingDir, target, symbol, newName)
+}
+
+// renameGoLocal renames a function's local variable, parameter or label
+// everywhere the parser resolved to the same object
+func renameGoLocal(file *goFile, ident *ast.Ident, newName string) (map[string]string, []RenameLocation, error) {
+	var offsets []int
+	var scope ast.Node
+	for _, decl := range file.ast.Decls {
+		if decl.Pos() <= ident.Pos() && ident.End() <= decl.End() {
+			scope = decl
+		}
+	}
+	conflict := false
+	ast.Inspect(scope, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if id.Obj == ident.Obj {
+				offsets = append(offsets, file.fset.Position(id.Pos()).Offset)
+			} else if id.Name == newName {
+				conflict = true
+			}
+		}
+		return true
+	})
+	if conflict {
+		return nil, nil, fmt.Errorf("%s is already used in the function; pick another name", newName)
+	}
+	return applyGoRenames(map[*goFile][]int{file: offsets}, ident.Name, newName)
+}
+
+// renameGoPackageLevel renames a package-level declaration in its package
+// and in the files importing it
+func renameGoPackageLevel(workingDir string, target *goFile, symbol, newName string) (map[string]string, []RenameLocation, error) {
+	projectFiles, err := analysis.GetProjectFiles(workingDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list project files: %w", err)
+	}
+	dir := path.Dir(target.path)
+	packageName := target.ast.Name.Name
+	importPath := goModulePath(workingDir)
+	if importPath != "" && dir != "." {
+		importPath += "/" + dir
+	}
+
+	// A cheap word search picks the files worth parsing
+	packageFiles := []*goFile{target}
+	var importers []*goFile
+	for _, rel := range projectFiles {
+		if rel == target.path || !strings.HasSuffix(rel, ".go") || strings.Contains("/"+rel, "/vendor/") || strings.Contains("/"+rel, "/testdata/") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(workingDir, rel))
+		if err != nil || !bytes.Contains(data, []byte(symbol)) && !bytes.Contains(data, []byte(newName)) {
+			continue
+		}
+		file, err := parseGoFile(rel, string(data))
+		if err != nil {
+			return nil, nil, err
+		}
+		if path.Dir(rel) == dir && file.ast.Name.Name == packageName {
+			packageFiles = append(packageFiles, file)
+		} else if importPath != "" && bytes.Contains(data, []byte(strconv.Quote(importPath))) {
+			importers = append(importers, file)
+		}
+	}
+
+	renames := map[*goFile][]int{}
+	for _, file := range packageFiles {
+		if declaresGoName(file.ast, newName) {
+			return nil, nil, fmt.Errorf("package %s already declares %s in %s", packageName, newName, file.path)
+		}
+		uses, err := packageUses(file, symbol, newName)
+		if err != nil {
+			return nil, nil, err
+		}
+		renames[file] = uses
+	}
+
+	if token.IsExported(symbol) {
+		for _, file := range importers {
+			uses, err := selectorUses(file, importPath, packageName, symbol)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(uses) > 0 && !token.IsExported(newName) {
+				return nil, nil, fmt.Errorf("%s is used from %s; an unexported name would break it", symbol, file.path)
+			}
+			if len(uses) > 0 {
+				renames[file] = append(renames[file], uses...)
+			}
+		}
+	}
+	return applyGoRenames(renames, symbol, newName)
+}
+
+// packageUses returns the offsets of the identifiers in a file that refer
+// to a package-level name: not a selected field or method, a method or
+// field declaration, a label, or a local that shadows it. It fails when a
+// local named newName would capture one of them.
+func packageUses(file *goFile, symbol, newName string) ([]int, error) {
+	skip := map[*ast.Ident]bool{}
+	ast.Inspect(file.ast, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			skip[n.Sel] = true
+		case *ast.FuncDecl:
+			if n.Recv != nil {
+				skip[n.Name] = true
+			}
+		case *ast.Field:
+			for _, name := range n.Names {
+				skip[name] = true
+			}
+		case *ast.KeyValueExpr:
+			if key, ok := n.Key.(*ast.Ident); ok && key.Obj == nil {
+				skip[key] = true // Most likely a struct field in a composite literal
+			}
+		case *ast.LabeledStmt:
+			skip[n.Label] = true
+		case *ast.BranchStmt:
+			if n.Label != nil {
+				skip[n.Label] = true
+			}
+		}
+		return true
+	})
+
+	var offsets []int
+	for _, decl := range file.ast.Decls {
+		var uses []int
+		shadowed := false
+		ast.Inspect(decl, func(n ast.Node) bool {
+			id, ok := n.(*ast.Ident)
+			switch {
+			case !ok:
+			case id.Name == newName && id.Obj != nil && !isTopLevel(file.ast, id.Obj):
+				shadowed = true
+			case skip[id]:
+			case id.Name == symbol && (id.Obj == nil || isTopLevel(file.ast, id.Obj)):
+				uses = append(uses, file.fset.Position(id.Pos()).Offset)
+			}
+			return true
+		})
+		if shadowed && len(uses) > 0 {
+			line := file.fset.Position(decl.Pos()).Line
+			return nil, fmt.Errorf("%s:%d declares a local %s that the renamed %s would refer to; rename the local first", file.path, line, newName, symbol)
+		}
+		offsets = append(offsets, uses...)
+	}
+	return offsets, nil
+}
+
+// selectorUses returns the offsets of pkg.symbol selections in a file that
+// imports the package
+func selectorUses(file *goFile, importPath, packageName, symbol string) ([]int, error) {
+	local := ""
+	for _, spec := range file.ast.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p != importPath {
+			continue
+		}
+		local = packageName
+		if spec.Name != nil {
+			local = spec.Name.Name
+		}
+	}
+	switch local {
+	case "", "_":
+		return nil, nil
+	case ".":
+		return nil, fmt.Errorf("%s dot-imports the package; rename %s there by hand or with a language server", file.path, symbol)
+	}
+
+	var offsets []int
+	ast.Inspect(file.ast, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == symbol {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Name == local && x.Obj == nil {
+				offsets = append(offsets, file.fset.Position(sel.Sel.Pos()).Offset)
+			}
+		}
+		return true
+	})
+	return offsets, nil
+}
+
+// applyGoRenames replaces the identifiers at the offsets, checking that
+// every changed file still parses
+func applyGoRenames(renames map[*goFile][]int, symbol, newName string) (map[string]string, []RenameLocation, error) {
+	contents := map[string]string{}
+	var locations []RenameLocation
+	for file, offsets := range renames {
+		if len(offsets) == 0 {
+			continue
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+		tokenFile := file.fset.File(file.ast.Pos())
+		lines := strings.Split(file.content, "\n")
+		updated := file.content
+		for i, offset := range offsets {
+			if i > 0 && offset == offsets[i-1] {
+				continue
+			}
+			updated = updated[:offset] + newName + updated[offset+len(symbol):]
+			line := tokenFile.Line(tokenFile.Pos(offset))
+			locations = append(locations, RenameLocation{Path: file.path, Line: line, Text: strings.TrimSpace(lines[line-1])})
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), file.path, updated, parser.SkipObjectResolution); err != nil {
+			return nil, nil, fmt.Errorf("the rename would break %s: %w", file.path, err)
+		}
+		contents[file.path] = updated
+	}
+	sortRenameLocations(locations)
+	return contents, locations, nil
+}
+
+// parseGoFile parses a project file, resolving identifiers within it
+func parseGoFile(rel, content string) (*goFile, error) {
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, rel, content, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("cannot rename while %s does not parse: %w", rel, err)
+	}
+	return &goFile{path: rel, content: content, fset: fset, ast: parsed}, nil
+}
+
+// isTopLevel reports whether a resolved object is declared at package level
+func isTopLevel(file *ast.File, obj *ast.Object) bool {
+	return file.Scope != nil && file.Scope.Lookup(obj.Name) == obj
+}
+
+// isGoPackageMember reports whether an unresolved identifier can name a
+// package-level declaration, rather than a method, field or selection
+func isGoPackageMember(ident *ast.Ident, parents map[ast.Node]ast.Node) bool {
+	switch parent := parents[ident].(type) {
+	case *ast.SelectorExpr:
+		return parent.X == ident
+	case *ast.FuncDecl:
+		return parent.Recv == nil
+	case *ast.Field:
+		return !slices.Contains(parent.Names, ident)
+	case *ast.KeyValueExpr:
+		return parent.Key != ident
+	}
+	return true
+}
+
+// isGoMemberDeclaration reports whether an identifier declares a struct
+// field or an interface method
+func isGoMemberDeclaration(ident *ast.Ident, parents map[ast.Node]ast.Node) bool {
+	field, ok := parents[ident].(*ast.Field)
+	if !ok || !slices.Contains(field.Names, ident) {
+		return false
+	}
+	switch parents[parents[field]].(type) {
+	case *ast.StructType, *ast.InterfaceType:
+		return true
+	}
+	return false
+}
+
+// declaresGoName reports whether a file declares name at package level
+func declaresGoName(file *ast.File, name string) bool {
+	return file.Scope != nil && file.Scope.Lookup(name) != nil
+}
+
+// goModulePath reads the module path from the project's go.mod
+func goModulePath(workingDir string) string {
+	f, err := os.Open(filepath.Join(workingDir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,323 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/lsp"
+	"github.com/billie-coop/loco/internal/permission"
+)
+
+// RenameSymbolParams represents parameters for the rename_symbol tool
+type RenameSymbolParams struct {
+	Path    string `json:"path"`              // File containing the declaration or a use of the symbol
+	Symbol  string `json:"symbol"`            // Identifier to rename
+	NewName string `json:"new_name"`          // What to rename it to
+	Line    int    `json:"line,omitempty"`    // 1-based line where the symbol appears (first occurrence if omitted)
+	Column  int    `json:"column,omitempty"`  // 1-based column, when the line has several matches
+	Preview bool   `json:"preview,omitempty"` // List the locations without changing anything
+}
+
+// RenameLocation is one occurrence a rename changes
+type RenameLocation struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"` // The line before the rename
+}
+
+// renameSymbolTool renames an identifier everywhere it is used
+type renameSymbolTool struct {
+	registry *Registry
+	manager  *lsp.Manager
+}
+
+// identifierPattern is what a new name must look like
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+const (
+	// RenameSymbolToolName is the name of this tool
+	RenameSymbolToolName = "rename_symbol"
+	// renameSymbolDescription describes what this tool does
+	renameSymbolDescription = `Rename a function, type, variable or constant everywhere it is used, safely.
+
+WHAT THIS DOES:
+- Finds every occurrence that refers to the same symbol: with the language server when one is configured, else for Go by parsing the code
+- Shows every affected location; with preview, stops there
+- Applies the whole rename at once after one permission prompt, or not at all
+
+WHEN TO USE:
+- Always prefer this to search/replace edits for renames: it skips unrelated names that look the same, comments and strings
+
+LIMITATIONS:
+- Without a language server only Go is supported, and Go methods and fields need one (gopls)`
+)
+
+// NewRenameSymbolTool creates a new rename_symbol tool. manager may be nil
+// when language servers are disabled.
+func NewRenameSymbolTool(registry *Registry, manager *lsp.Manager) BaseTool {
+	return &renameSymbolTool{registry: registry, manager: manager}
+}
+
+// Name returns the tool name
+func (t *renameSymbolTool) Name() string {
+	return RenameSymbolToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: calls wait on the language server and a permission prompt
+func (t *renameSymbolTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *renameSymbolTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RenameSymbolToolName,
+		Description: renameSymbolDescription,
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File where the symbol is declared or used",
+			},
+			"symbol": map[string]any{
+				"type":        "string",
+				"description": "Current name of the symbol",
+			},
+			"new_name": map[string]any{
+				"type":        "string",
+				"description": "New name for the symbol",
+			},
+			"line": map[string]any{
+				"type":        "integer",
+				"description": "1-based line where the symbol appears (default: first occurrence)",
+				"minimum":     1,
+			},
+			"column": map[string]any{
+				"type":        "integer",
+				"description": "1-based column when the line contains the name more than once",
+				"minimum":     1,
+			},
+			"preview": map[string]any{
+				"type":        "boolean",
+				"description": "Only list the locations that would change",
+			},
+		},
+		Required: []string{"path", "symbol", "new_name"},
+		Commands: []CommandInfo{
+			{
+				Command:     "rename",
+				Description: "Rename a symbol across the project",
+				Examples:    []string{"/rename internal/tools/tools.go Registry ToolRegistry"},
+				Args:        []string{"path", "symbol", "new_name"},
+			},
+		},
+	}
+}
+
+// TouchedPaths returns the file the call names; the others are only known
+// once the occurrences are found, and the transaction checks those
+func (t *renameSymbolTool) TouchedPaths(call ToolCall) []string {
+	var params RenameSymbolParams
+	if json.Unmarshal([]byte(call.Input), &params) != nil || params.Path == "" {
+		return nil
+	}
+	return []string{params.Path}
+}
+
+// Run finds the occurrences and applies the rename as one transaction
+func (t *renameSymbolTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params RenameSymbolParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	params.Symbol, params.NewName = strings.TrimSpace(params.Symbol), strings.TrimSpace(params.NewName)
+	if params.Path == "" || params.Symbol == "" || params.NewName == "" {
+		return NewTextErrorResponse("path, symbol and new_name parameters are required"), nil
+	}
+	if !identifierPattern.MatchString(params.NewName) {
+		return NewTextErrorResponse(fmt.Sprintf("%q is not a valid identifier", params.NewName)), nil
+	}
+	if params.NewName == params.Symbol {
+		return NewTextErrorResponse("new_name is the current name"), nil
+	}
+
+	workingDir := t.registry.workingDir
+	absPath, relPath, err := resolveProjectPath(workingDir, params.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("could not read %s: %s", relPath, err)), nil
+	}
+	pos, err := symbolPosition(string(data), SymbolPositionParams{Path: params.Path, Symbol: params.Symbol, Line: params.Line, Column: params.Column})
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("%s: %s", relPath, err)), nil
+	}
+
+	// The language server knows the types; without one, Go is parsed
+	var contents map[string]string
+	var locations []RenameLocation
+	via := "language server"
+	serverErr := errors.New("language servers are disabled (set lsp.enabled in .loco/config.jsonc)")
+	if t.manager != nil {
+		contents, locations, serverErr = t.renameWithServer(ctx, absPath, pos, params.NewName)
+	}
+	if serverErr != nil {
+		if filepath.Ext(relPath) != ".go" {
+			return NewTextErrorResponse(fmt.Sprintf("renaming %s files needs a language server: %s", filepath.Ext(relPath), serverErr)), nil
+		}
+		via = "Go syntax"
+		contents, locations, err = renameGo(workingDir, relPath, string(data), pos, params.Symbol, params.NewName)
+		if err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+	}
+	if len(locations) == 0 {
+		return NewTextErrorResponse(fmt.Sprintf("found no occurrences of %s to rename", params.Symbol)), nil
+	}
+
+	files := make([]string, 0, len(contents))
+	for path := range contents {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	listing := formatRenameLocations(locations)
+	metadata := map[string]any{
+		"symbol":    params.Symbol,
+		"new_name":  params.NewName,
+		"via":       via,
+		"files":     files,
+		"locations": locations,
+	}
+	if params.Preview {
+		summary := fmt.Sprintf("Renaming %s to %s would change %d occurrences in %d files (found by %s):\n%s",
+			params.Symbol, params.NewName, len(locations), len(files), via, listing)
+		return WithResponseMetadata(NewTextResponse(summary), metadata), nil
+	}
+
+	tx := t.registry.BeginTransaction()
+	for _, path := range files {
+		if err := tx.Write(path, contents[path]); err != nil {
+			tx.Rollback()
+			return NewTextErrorResponse(fmt.Sprintf("rename not applied, no files were modified: %s", err)), nil
+		}
+	}
+	if _, err := tx.Commit(ctx, RenameSymbolToolName); errors.Is(err, permission.ErrorPermissionDenied) {
+		return NewTextErrorResponse("permission denied: no files were modified"), nil
+	} else if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	summary := fmt.Sprintf("Renamed %s to %s: %d occurrences in %d files (found by %s):\n%s",
+		params.Symbol, params.NewName, len(locations), len(files), via, listing)
+	return WithResponseMetadata(NewTextResponse(summary), metadata), nil
+}
+
+// renameWithServer has the language server compute the rename and applies
+// its edits to the files' contents in memory
+func (t *renameSymbolTool) renameWithServer(ctx context.Context, absPath string, pos lsp.Position, newName string) (map[string]string, []RenameLocation, error) {
+	edits, err := t.manager.Rename(ctx, absPath, pos, newName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contents := make(map[string]string, len(edits))
+	var locations []RenameLocation
+	for path, fileEdits := range edits {
+		_, rel, err := resolveProjectPath(t.registry.workingDir, path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("the rename would change %s, outside the project", path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read %s: %w", rel, err)
+		}
+		updated, lines, err := applyTextEdits(string(data), fileEdits)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", rel, err)
+		}
+		contents[rel] = updated
+		original := strings.Split(string(data), "\n")
+		for _, line := range lines {
+			locations = append(locations, RenameLocation{Path: rel, Line: line + 1, Text: strings.TrimSpace(original[line])})
+		}
+	}
+	sortRenameLocations(locations)
+	return contents, locations, nil
+}
+
+// applyTextEdits applies LSP edits to content, returning it with the
+// zero-based lines the edits start on
+func applyTextEdits(content string, edits []lsp.TextEdit) (string, []int, error) {
+	lines := strings.Split(content, "\n")
+	starts := make([]int, len(lines)+1)
+	for i, line := range lines {
+		starts[i+1] = starts[i] + len(line) + 1
+	}
+	offset := func(pos lsp.Position) (int, error) {
+		if pos.Line < 0 || pos.Line >= len(lines) {
+			return 0, fmt.Errorf("edit at line %d is past the end of the file", pos.Line+1)
+		}
+		return starts[pos.Line] + lsp.ByteColumn(lines[pos.Line], pos.Character), nil
+	}
+
+	type span struct {
+		start, end int
+		text       string
+	}
+	spans := make([]span, 0, len(edits))
+	var touched []int
+	for _, edit := range edits {
+		start, err := offset(edit.Range.Start)
+		if err != nil {
+			return "", nil, err
+		}
+		end, err := offset(edit.Range.End)
+		if err != nil {
+			return "", nil, err
+		}
+		spans = append(spans, span{start, end, edit.NewText})
+		touched = append(touched, edit.Range.Start.Line)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start > spans[j].start })
+	for i, s := range spans {
+		if s.end < s.start || (i > 0 && s.end > spans[i-1].start) {
+			return "", nil, fmt.Errorf("the server sent overlapping edits")
+		}
+		content = content[:s.start] + s.text + content[s.end:]
+	}
+	return content, touched, nil
+}
+
+// sortRenameLocations orders locations by file and line
+func sortRenameLocations(locations []RenameLocation) {
+	sort.SliceStable(locations, func(i, j int) bool {
+		if locations[i].Path != locations[j].Path {
+			return locations[i].Path < locations[j].Path
+		}
+		return locations[i].Line < locations[j].Line
+	})
+}
+
+// formatRenameLocations lists the locations as path:line: text, one per
+// line, capped at maxLSPLocations
+func formatRenameLocations(locations []RenameLocation) string {
+	var sb strings.Builder
+	for i, loc := range locations {
+		if i == maxLSPLocations {
+			fmt.Fprintf(&sb, "... and %d more\n", len(locations)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "%s:%d: %s\n", loc.Path, loc.Line, loc.Text)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
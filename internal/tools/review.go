@@ -0,0 +1,333 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+// ReviewParams represents parameters for the review tool
+type ReviewParams struct {
+	Target string `json:"target,omitempty"` // A ref, a range, "staged", or a patch file; empty for uncommitted changes
+}
+
+// Review comment categories
+const (
+	ReviewCorrectness = "correctness"
+	ReviewStyle       = "style"
+	ReviewRisk        = "risk"
+)
+
+// Review comment severities, most serious first
+const (
+	ReviewError   = "error"
+	ReviewWarning = "warning"
+	ReviewNote    = "note"
+)
+
+// ReviewComment is one finding on a diff
+type ReviewComment struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"` // In the new version of the file
+	Category string `json:"category"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// ReviewResult is the metadata of a review response
+type ReviewResult struct {
+	Target   string          `json:"target"`
+	Files    []string        `json:"files"`
+	Summary  string          `json:"summary"`
+	Comments []ReviewComment `json:"comments"`
+}
+
+// SeverityRank orders severities for thresholds: error 0, warning 1, note 2
+func SeverityRank(severity string) int {
+	switch severity {
+	case ReviewError:
+		return 0
+	case ReviewWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// reviewTool reviews a diff against the project's knowledge
+type reviewTool struct {
+	workingDir string
+	llmClient  llm.Client
+}
+
+// maxReviewDiffBytes caps the diff sent to the model
+const maxReviewDiffBytes = 24000
+
+const (
+	// ReviewToolName is the name of this tool
+	ReviewToolName = "review"
+	// reviewDescription describes what this tool does
+	reviewDescription = `Review a diff and return structured comments: correctness bugs, departures from the project's patterns (patterns.md), and risky areas.
+
+TARGET:
+- empty: uncommitted changes against HEAD
+- staged: what the next commit would contain
+- a ref, e.g. main or origin/main: changes since the branch point with it, uncommitted ones included
+- a range, e.g. HEAD~3..HEAD or main...feature
+- a path to a .patch or .diff file
+
+OUTPUT:
+- A summary and comments by file and line, each with a category (correctness, style, risk) and a severity (error, warning, note)
+- Changed files no test covers, per knowledge/tests.json, are flagged as risks`
+
+	reviewSystemPrompt = `You review code changes for a project you are given notes about.
+Report only real problems in the changed lines: bugs and unhandled errors
+(correctness), departures from the project's documented patterns (style), and
+changes to fragile or widely used code that deserve a closer look (risk).
+Don't praise, don't restate the change, and don't comment on unchanged code.
+
+Reply with JSON only:
+{"summary": "<one or two sentences on the change and its biggest problem>",
+ "comments": [{"file": "<path>", "line": <line in the new file>, "category": "correctness|style|risk", "severity": "error|warning|note", "message": "<what is wrong and how to fix it>"}]}
+Use "error" only for changes that are wrong as written. An empty comments list is fine.`
+)
+
+// NewReviewTool creates a new review tool. llmClient may be nil until a
+// model is configured.
+func NewReviewTool(workingDir string, llmClient llm.Client) BaseTool {
+	return &reviewTool{workingDir: workingDir, llmClient: llmClient}
+}
+
+// Name returns the tool name
+func (t *reviewTool) Name() string {
+	return ReviewToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: reviewing waits on the model
+func (t *reviewTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *reviewTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ReviewToolName,
+		Description: reviewDescription,
+		Parameters: map[string]any{
+			"target": map[string]any{
+				"type":        "string",
+				"description": "A ref, a range, staged, or a patch file (default: uncommitted changes)",
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "review",
+				Description: "Review changes against the project's patterns",
+				Examples:    []string{"/review", "/review staged", "/review main", "/review HEAD~1..HEAD", "/review fix.patch"},
+				Args:        []string{"target"},
+			},
+		},
+	}
+}
+
+// Run reads the diff, asks the model for comments and adds the ones the
+// project's knowledge shows without one
+func (t *reviewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	if t.llmClient == nil {
+		return NewTextErrorResponse("No model available to review with - check your LM Studio connection"), nil
+	}
+	var params ReviewParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	target := strings.TrimSpace(params.Target)
+
+	diff, err := t.diff(ctx, target)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if strings.TrimSpace(diff) == "" {
+		return NewTextResponse("Nothing to review: the diff is empty"), nil
+	}
+	changed := diffFiles(diff)
+
+	publish := GetProgressPublisher(ctx)
+	publish("Reviewing", len(changed), 0, "")
+
+	reply, err := t.llmClient.Complete(ctx, []llm.Message{
+		{Role: "system", Content: reviewSystemPrompt},
+		{Role: "user", Content: t.prompt(truncateOutput(diff, maxReviewDiffBytes))},
+	})
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("Failed to review: %s", err)), nil
+	}
+
+	result := ReviewResult{Target: target, Files: changed, Comments: []ReviewComment{}}
+	if target == "" {
+		result.Target = "uncommitted changes"
+	}
+	var parsed struct {
+		Summary  string          `json:"summary"`
+		Comments []ReviewComment `json:"comments"`
+	}
+	if start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}"); start >= 0 && end > start && json.Unmarshal([]byte(reply[start:end+1]), &parsed) == nil {
+		result.Summary = strings.TrimSpace(parsed.Summary)
+		for _, comment := range parsed.Comments {
+			if comment = normalizeReviewComment(comment); comment.Message != "" {
+				result.Comments = append(result.Comments, comment)
+			}
+		}
+	} else {
+		result.Summary = strings.TrimSpace(reply) // The model ignored the format; its prose is still a review
+	}
+	result.Comments = append(result.Comments, t.untestedComments(changed)...)
+	sort.SliceStable(result.Comments, func(i, j int) bool {
+		a, b := result.Comments[i], result.Comments[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	return WithResponseMetadata(NewTextResponse(formatReview(result)), result), nil
+}
+
+// diff returns the patch to review for target
+func (t *reviewTool) diff(ctx context.Context, target string) (string, error) {
+	base := []string{"diff", "--no-color", "--no-ext-diff"}
+	switch {
+	case target == "":
+		return runGit(ctx, t.workingDir, append(base, "HEAD")...)
+	case target == "staged":
+		return runGit(ctx, t.workingDir, append(base, "--cached")...)
+	case strings.Contains(target, ".."):
+		return runGit(ctx, t.workingDir, append(base, target)...)
+	}
+
+	path := target
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.workingDir, path)
+	}
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", target, err)
+		}
+		return string(data), nil
+	}
+	if _, err := runGit(ctx, t.workingDir, "rev-parse", "--verify", "--quiet", target+"^{commit}"); err != nil {
+		return "", fmt.Errorf("%q is not a ref, a range or a patch file", target)
+	}
+	return runGit(ctx, t.workingDir, append(base, "--merge-base", target)...)
+}
+
+// prompt gives the model the project's patterns and overview with the diff
+func (t *reviewTool) prompt(diff string) string {
+	var b strings.Builder
+	for _, kind := range []string{"patterns", "overview"} {
+		if source, content, ok := analysis.LoadBestKnowledge(t.workingDir, kind); ok {
+			fmt.Fprintf(&b, "## Project %s (%s)\n\n%s\n\n", kind, source, truncateOutput(content, 4000))
+		}
+	}
+	b.WriteString("## Diff\n\n")
+	b.WriteString(diff)
+	return b.String()
+}
+
+// untestedComments flags changed source files that no test covers
+func (t *reviewTool) untestedComments(changed []string) []ReviewComment {
+	testMap, _, err := analysis.RefreshTestMap(t.workingDir)
+	if err != nil {
+		return nil
+	}
+	var comments []ReviewComment
+	for _, file := range changed {
+		if slices.Contains(testMap.Untested, file) {
+			comments = append(comments, ReviewComment{
+				File:     file,
+				Category: ReviewRisk,
+				Severity: ReviewNote,
+				Message:  "No test covers this file; the change is unverified",
+			})
+		}
+	}
+	return comments
+}
+
+// normalizeReviewComment maps a model's comment onto the known categories
+// and severities
+func normalizeReviewComment(c ReviewComment) ReviewComment {
+	c.File = strings.TrimPrefix(strings.TrimSpace(c.File), "b/")
+	c.Message = strings.TrimSpace(c.Message)
+	c.Category = strings.ToLower(strings.TrimSpace(c.Category))
+	if c.Category != ReviewCorrectness && c.Category != ReviewStyle {
+		c.Category = ReviewRisk
+	}
+	c.Severity = strings.ToLower(strings.TrimSpace(c.Severity))
+	if c.Severity != ReviewError && c.Severity != ReviewWarning {
+		c.Severity = ReviewNote
+	}
+	if c.Line < 0 {
+		c.Line = 0
+	}
+	return c
+}
+
+// diffFiles lists the files a unified diff changes, in order
+func diffFiles(diff string) []string {
+	var changed []string
+	for _, line := range splitLines(diff) {
+		file, ok := strings.CutPrefix(line, "+++ ")
+		if !ok || file == "/dev/null" {
+			continue
+		}
+		file, _, _ = strings.Cut(file, "\t")
+		file = strings.TrimPrefix(file, "b/")
+		if !slices.Contains(changed, file) {
+			changed = append(changed, file)
+		}
+	}
+	return changed
+}
+
+// formatReview renders a review for the conversation and the terminal
+func formatReview(result ReviewResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Review of %s (%d files)\n\n", result.Target, len(result.Files))
+	if result.Summary != "" {
+		fmt.Fprintf(&b, "%s\n", result.Summary)
+	}
+	if len(result.Comments) == 0 {
+		b.WriteString("\nNo comments.")
+		return b.String()
+	}
+
+	counts := map[string]int{}
+	for _, c := range result.Comments {
+		counts[c.Severity]++
+	}
+	fmt.Fprintf(&b, "\n%d errors, %d warnings, %d notes\n", counts[ReviewError], counts[ReviewWarning], counts[ReviewNote])
+	file := ""
+	for _, c := range result.Comments {
+		if c.File != file {
+			file = c.File
+			fmt.Fprintf(&b, "\n%s\n", file)
+		}
+		location := "-"
+		if c.Line > 0 {
+			location = fmt.Sprint(c.Line)
+		}
+		fmt.Fprintf(&b, "  %s: %s [%s] %s\n", location, c.Severity, c.Category, c.Message)
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,345 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/permission"
+	"github.com/billie-coop/loco/internal/process"
+	"github.com/billie-coop/loco/internal/shell"
+)
+
+// RunProcessParams represents parameters for the run_process tool
+type RunProcessParams struct {
+	Action  string `json:"action"`            // start, output, list or stop
+	Command string `json:"command,omitempty"` // Command line to start
+	Name    string `json:"name,omitempty"`    // ID to give a started process
+	ID      string `json:"id,omitempty"`      // Process to read or stop
+	Lines   int    `json:"lines,omitempty"`   // How many output lines to return
+	Grep    string `json:"grep,omitempty"`    // Only return output lines matching this regular expression
+	WaitMs  int    `json:"wait_ms,omitempty"` // How long start waits for early output
+}
+
+// runProcessTool starts and watches long-running commands
+type runProcessTool struct {
+	manager       *process.Manager
+	permissions   permission.Service
+	configManager *config.Manager
+}
+
+const (
+	// RunProcessToolName is the name of this tool
+	RunProcessToolName = "run_process"
+	// ProcessCardPrefix starts the tool card names of running processes,
+	// followed by the process ID
+	ProcessCardPrefix = "process:"
+	// runProcessDescription describes what this tool does
+	runProcessDescription = `Start a long-running command (dev server, watcher, tail -f) in the background and read its output later.
+
+WHAT THIS DOES:
+- start: runs the command in the project directory in a terminal, waits briefly, and returns its first output
+- output: the latest lines of a process's output, optionally only those matching grep
+- list: the processes with their status
+- stop: ends a process and everything it started
+
+WHEN TO USE:
+- For commands that don't finish on their own; use bash for ones that do
+- Start a server, then exercise it and check output for errors
+
+SAFETY:
+- Starting a command requires the user's permission unless bash would run it without asking
+- Commands on the bash denylist, and commands naming guardrails.protected_paths, are always refused
+- Processes are stopped when Loco exits
+
+OUTPUT:
+- Process ID, status and output lines, with terminal colors removed`
+
+	defaultProcessLines = 50
+	maxProcessLines     = 500
+	defaultProcessWait  = 2 * time.Second
+	maxProcessWait      = 30 * time.Second
+)
+
+// NewRunProcessTool creates a new run_process tool
+func NewRunProcessTool(manager *process.Manager, permissions permission.Service, configManager *config.Manager) BaseTool {
+	return &runProcessTool{manager: manager, permissions: permissions, configManager: configManager}
+}
+
+// Name returns the tool name
+func (t *runProcessTool) Name() string {
+	return RunProcessToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: starting a process may wait on a permission prompt
+func (t *runProcessTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *runProcessTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RunProcessToolName,
+		Description: runProcessDescription,
+		Parameters: map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"start", "output", "list", "stop"},
+				"description": "What to do",
+			},
+			"command": map[string]any{
+				"type":        "string",
+				"description": "For start: the command line to run",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "For start: a short ID for the process (default: the program's name)",
+			},
+			"id": map[string]any{
+				"type":        "string",
+				"description": "For output and stop: the process ID",
+			},
+			"lines": map[string]any{
+				"type":        "integer",
+				"description": "For output: how many of the latest lines to return (default 50, max 500)",
+				"minimum":     1,
+			},
+			"grep": map[string]any{
+				"type":        "string",
+				"description": "For output: only lines matching this regular expression",
+			},
+			"wait_ms": map[string]any{
+				"type":        "integer",
+				"description": "For start: how long to wait for early output (default 2000, max 30000)",
+				"minimum":     0,
+			},
+		},
+		Required: []string{"action"},
+		Commands: []CommandInfo{
+			{
+				Command:     "process",
+				Aliases:     []string{"ps"},
+				Description: "Start, read, list or stop background processes",
+				Examples:    []string{"/process start npm run dev", "/process output npm", "/process stop npm", "/process list"},
+				Args:        []string{"action", "command"},
+			},
+		},
+	}
+}
+
+// TouchedPaths returns the words of a command being started that may name
+// files, with the files its globs match
+func (t *runProcessTool) TouchedPaths(call ToolCall) []string {
+	var params RunProcessParams
+	if t.manager == nil || json.Unmarshal([]byte(call.Input), &params) != nil || params.Action != "start" {
+		return nil
+	}
+	words, err := shell.PathWords(params.Command)
+	if err != nil {
+		return nil
+	}
+	return expandPathWords(words, t.manager.WorkingDir())
+}
+
+// ReadTrees returns the directories a command being started reads
+// recursively
+func (t *runProcessTool) ReadTrees(call ToolCall) []TreeRead {
+	var params RunProcessParams
+	if t.manager == nil || json.Unmarshal([]byte(call.Input), &params) != nil || params.Action != "start" {
+		return nil
+	}
+	commands, err := shell.SimpleCommands(params.Command)
+	if err != nil {
+		return nil
+	}
+	return recursiveReads(commands, t.manager.WorkingDir())
+}
+
+// Run dispatches the action
+func (t *runProcessTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params RunProcessParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	if t.manager == nil {
+		return NewTextErrorResponse("background processes are not available"), nil
+	}
+	// The slash command puts everything after the action in command
+	if params.ID == "" && params.Action != "start" {
+		params.ID = strings.TrimSpace(params.Command)
+	}
+
+	switch params.Action {
+	case "start":
+		return t.start(ctx, call, params)
+	case "output":
+		return t.output(params)
+	case "list":
+		return t.list(), nil
+	case "stop":
+		if params.ID == "" {
+			return NewTextErrorResponse("id parameter is required"), nil
+		}
+		info, err := t.manager.Stop(params.ID)
+		if err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		return WithResponseMetadata(NewTextResponse(fmt.Sprintf("Stopped %s (%s)", info.ID, describeProcess(info))), info), nil
+	case "":
+		return NewTextErrorResponse("action parameter is required (start, output, list or stop)"), nil
+	default:
+		return NewTextErrorResponse(fmt.Sprintf("unknown action %q (use start, output, list or stop)", params.Action)), nil
+	}
+}
+
+// start asks permission, starts the command and returns its early output
+func (t *runProcessTool) start(ctx context.Context, call ToolCall, params RunProcessParams) (ToolResponse, error) {
+	params.Command = strings.TrimSpace(params.Command)
+	if params.Command == "" {
+		return NewTextErrorResponse("command parameter is required"), nil
+	}
+
+	cfg := config.DefaultConfig().Bash
+	if t.configManager != nil {
+		if current := t.configManager.Get(); current != nil {
+			cfg = current.Bash
+		}
+	}
+	commands, err := shell.SimpleCommands(params.Command)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("could not parse command: %s", err)), nil
+	}
+	if entry, denied := matchDenylist(commands, cfg.Denylist); denied {
+		return NewTextErrorResponse(fmt.Sprintf("command refused: %q is on the denylist", entry)), nil
+	}
+	if !isAllowlisted(commands, params.Command, cfg.Allowlist) {
+		sessionID, _ := GetContextValues(ctx)
+		granted := t.permissions != nil && t.permissions.Request(ctx, permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			ToolCallID:  call.ID,
+			ToolName:    RunProcessToolName,
+			Action:      "execute",
+			Path:        params.Command,
+			Description: fmt.Sprintf("Start background process: %s", params.Command),
+			Params:      params,
+		})
+		if !granted {
+			return NewTextErrorResponse("permission denied: process was not started"), nil
+		}
+	}
+
+	info, err := t.manager.Start(params.Name, params.Command)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	// Give it a moment, so a crash on startup is reported right away
+	wait := defaultProcessWait
+	if params.WaitMs > 0 {
+		wait = time.Duration(params.WaitMs) * time.Millisecond
+	}
+	if wait > maxProcessWait {
+		wait = maxProcessWait
+	}
+	p, _ := t.manager.Get(info.ID)
+	deadline := time.After(wait)
+	for waiting := true; waiting; {
+		select {
+		case <-ctx.Done():
+			waiting = false
+		case <-deadline:
+			waiting = false
+		case <-time.After(100 * time.Millisecond):
+			waiting = p.Info().Status == process.StatusRunning
+		}
+	}
+
+	info = p.Info()
+	lines := p.Tail(defaultProcessLines, nil)
+	var sb strings.Builder
+	if info.Status == process.StatusRunning {
+		fmt.Fprintf(&sb, "Started %s (%s). Read more with action output, id %q.", info.ID, describeProcess(info), info.ID)
+	} else {
+		fmt.Fprintf(&sb, "%s ended right away (%s).", info.ID, describeProcess(info))
+	}
+	if len(lines) == 0 {
+		sb.WriteString("\nNo output yet.")
+	} else {
+		sb.WriteString("\nOutput so far:\n" + strings.Join(lines, "\n"))
+	}
+
+	result := NewTextResponse(sb.String())
+	result.IsError = info.Status != process.StatusRunning && info.ExitCode != 0
+	return WithResponseMetadata(result, info), nil
+}
+
+// output returns the latest lines of a process
+func (t *runProcessTool) output(params RunProcessParams) (ToolResponse, error) {
+	if params.ID == "" {
+		return NewTextErrorResponse("id parameter is required"), nil
+	}
+	p, ok := t.manager.Get(params.ID)
+	if !ok {
+		return NewTextErrorResponse(fmt.Sprintf("no process %q; list shows the processes", params.ID)), nil
+	}
+	n := params.Lines
+	if n <= 0 {
+		n = defaultProcessLines
+	}
+	if n > maxProcessLines {
+		n = maxProcessLines
+	}
+	var pattern *regexp.Regexp
+	if params.Grep != "" {
+		var err error
+		if pattern, err = regexp.Compile(params.Grep); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("invalid grep pattern: %s", err)), nil
+		}
+	}
+
+	info := p.Info()
+	lines := p.Tail(n, pattern)
+	header := fmt.Sprintf("%s (%s), output lines so far: %d", info.ID, describeProcess(info), info.Lines)
+	switch {
+	case len(lines) == 0 && pattern != nil:
+		header += fmt.Sprintf("; none of the kept lines match %q", params.Grep)
+	case len(lines) == 0:
+		header += "; nothing printed yet"
+	case pattern != nil:
+		header += fmt.Sprintf("; the last %d matching %q:", len(lines), params.Grep)
+	default:
+		header += fmt.Sprintf("; the last %d:", len(lines))
+	}
+	return WithResponseMetadata(NewTextResponse(strings.TrimSpace(header+"\n"+strings.Join(lines, "\n"))), info), nil
+}
+
+// list describes every kept process
+func (t *runProcessTool) list() ToolResponse {
+	infos := t.manager.List()
+	if len(infos) == 0 {
+		return NewTextResponse("No background processes.")
+	}
+	var sb strings.Builder
+	for _, info := range infos {
+		fmt.Fprintf(&sb, "%s: %s (%s)\n", info.ID, info.Command, describeProcess(info))
+	}
+	return WithResponseMetadata(NewTextResponse(strings.TrimSuffix(sb.String(), "\n")), map[string]any{"processes": infos})
+}
+
+// describeProcess summarizes a process's state, e.g. "running for 2m, pid 123"
+func describeProcess(info process.Info) string {
+	switch info.Status {
+	case process.StatusRunning:
+		return fmt.Sprintf("running for %s, pid %d", time.Since(info.Started).Round(time.Second), info.PID)
+	case process.StatusStopped:
+		return fmt.Sprintf("stopped after %s", info.Ended.Sub(info.Started).Round(100*time.Millisecond))
+	default:
+		return fmt.Sprintf("exited with code %d after %s", info.ExitCode, info.Ended.Sub(info.Started).Round(100*time.Millisecond))
+	}
+}
@@ -0,0 +1,529 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RunTestsParams represents parameters for the run_tests tool
+type RunTestsParams struct {
+	Packages  []string `json:"packages,omitempty"`   // Packages or paths to test (default: everything)
+	Run       string   `json:"run,omitempty"`        // Only run tests matching this pattern
+	TimeoutMs int      `json:"timeout_ms,omitempty"` // Override the default timeout
+}
+
+// TestFailure is one failing test, or a package that failed to build
+type TestFailure struct {
+	Package string `json:"package,omitempty"`
+	Test    string `json:"test,omitempty"` // Empty when the package itself failed
+	Output  string `json:"output"`         // The lines that explain the failure
+}
+
+// runTestsTool runs the project's tests and summarizes what failed
+type runTestsTool struct {
+	workingDir string
+}
+
+// testRunner is how a kind of project runs its tests
+type testRunner struct {
+	name    string
+	command string
+	args    func(params RunTestsParams) ([]string, error)
+}
+
+const (
+	// RunTestsToolName is the name of this tool
+	RunTestsToolName = "run_tests"
+	// runTestsDescription describes what this tool does
+	runTestsDescription = `Run the project's tests and get a compact summary of what failed.
+
+WHAT THIS DOES:
+- Detects the test runner: go test for Go modules, cargo test, npm test, or pytest
+- Runs all tests, or only the given packages and the tests matching a pattern
+- Summarizes the failures: which tests failed and the lines that explain why
+
+WHEN TO USE:
+- After changing code, to check nothing broke
+- Prefer this to running the test command with bash: the summary is much shorter than the raw output
+
+OUTPUT:
+- Pass/fail counts
+- For each failing test (or package that didn't build): its name and the relevant output`
+
+	defaultTestTimeout = 5 * time.Minute
+	maxTestTimeout     = 20 * time.Minute
+
+	// Keep the summary small enough to feed back to a local model
+	maxTestFailures      = 20
+	maxFailureLines      = 25
+	maxGenericOutputTail = 40
+)
+
+// testFailureLine picks out the lines that explain a failure in output from
+// runners whose format isn't parsed
+var testFailureLine = regexp.MustCompile(`(?i)\b(fail(ed|ure)?|error|panic(ked)?|assert(ion)?)\b`)
+
+// NewRunTestsTool creates a new run_tests tool
+func NewRunTestsTool(workingDir string) BaseTool {
+	return &runTestsTool{workingDir: workingDir}
+}
+
+// Name returns the tool name
+func (t *runTestsTool) Name() string {
+	return RunTestsToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: tests can take minutes
+func (t *runTestsTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *runTestsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RunTestsToolName,
+		Description: runTestsDescription,
+		Parameters: map[string]any{
+			"packages": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Packages or paths to test, e.g. ./internal/tools (default: all)",
+			},
+			"run": map[string]any{
+				"type":        "string",
+				"description": "Only run tests whose names match this pattern",
+			},
+			"timeout_ms": map[string]any{
+				"type":        "integer",
+				"description": "Timeout in milliseconds (default 300000, max 1200000)",
+				"minimum":     1000,
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "test",
+				Description: "Run the tests and summarize failures",
+				Examples:    []string{"/test", "/test ./internal/tools,./internal/app"},
+				Args:        []string{"packages"},
+			},
+		},
+	}
+}
+
+// Run detects the runner, runs the tests and summarizes the result
+func (t *runTestsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params RunTestsParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	params.Packages = cleanPackageArgs(params.Packages)
+	for _, pkg := range params.Packages {
+		if strings.HasPrefix(pkg, "-") {
+			return NewTextErrorResponse(fmt.Sprintf("%q is not a package or path", pkg)), nil
+		}
+	}
+
+	runner, ok := detectTestRunner(t.workingDir)
+	if !ok {
+		return NewTextErrorResponse("could not detect how to run this project's tests (looked for go.mod, Cargo.toml, package.json with a test script, and pytest configuration)"), nil
+	}
+	if _, err := exec.LookPath(runner.command); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("%s is not installed", runner.command)), nil
+	}
+	args, err := runner.args(params)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	timeout := defaultTestTimeout
+	if params.TimeoutMs > 0 {
+		timeout = time.Duration(params.TimeoutMs) * time.Millisecond
+	}
+	if timeout < minBashTimeout {
+		timeout = minBashTimeout
+	}
+	if timeout > maxTestTimeout {
+		timeout = maxTestTimeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	commandLine := strings.Join(append([]string{runner.command}, args...), " ")
+	publish := GetProgressPublisher(ctx)
+	publish("Testing", 0, 0, commandLine)
+
+	start := time.Now()
+	var summary string
+	var failures []TestFailure
+	var passed bool
+	var exitCode int
+	if runner.name == "go" {
+		summary, failures, exitCode, err = runGoTests(runCtx, t.workingDir, args, publish)
+	} else {
+		summary, failures, exitCode, err = runOtherTests(runCtx, t.workingDir, runner.command, args)
+	}
+	elapsed := time.Since(start)
+	if runCtx.Err() == context.DeadlineExceeded {
+		return NewTextErrorResponse(fmt.Sprintf("%s timed out after %s", commandLine, timeout)), nil
+	}
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("%s: %s", commandLine, err)), nil
+	}
+	passed = exitCode == 0 && len(failures) == 0
+
+	var response strings.Builder
+	status := "PASS"
+	if !passed {
+		status = "FAIL"
+	}
+	fmt.Fprintf(&response, "%s: %s (%s)\n%s\n", commandLine, status, elapsed.Round(100*time.Millisecond), summary)
+	response.WriteString(formatTestFailures(failures))
+
+	result := NewTextResponse(strings.TrimSuffix(response.String(), "\n"))
+	result.IsError = !passed
+	return WithResponseMetadata(result, map[string]any{
+		"runner":      runner.name,
+		"command":     commandLine,
+		"passed":      passed,
+		"exit_code":   exitCode,
+		"failures":    failures,
+		"duration_ms": elapsed.Milliseconds(),
+	}), nil
+}
+
+// cleanPackageArgs trims the packages and drops empty ones, which a
+// trailing comma in a slash command leaves
+func cleanPackageArgs(packages []string) []string {
+	var cleaned []string
+	for _, pkg := range packages {
+		if pkg = strings.TrimSpace(pkg); pkg != "" {
+			cleaned = append(cleaned, pkg)
+		}
+	}
+	return cleaned
+}
+
+// detectTestRunner works out the test command from the project's manifests
+func detectTestRunner(dir string) (testRunner, bool) {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	switch {
+	case exists("go.mod"):
+		return testRunner{name: "go", command: "go", args: func(p RunTestsParams) ([]string, error) {
+			args := []string{"test", "-json"}
+			if p.Run != "" {
+				args = append(args, "-run", p.Run)
+			}
+			if len(p.Packages) == 0 {
+				return append(args, "./..."), nil
+			}
+			return append(args, p.Packages...), nil
+		}}, true
+
+	case exists("Cargo.toml"):
+		return testRunner{name: "cargo", command: "cargo", args: func(p RunTestsParams) ([]string, error) {
+			args := []string{"test"}
+			for _, pkg := range p.Packages {
+				args = append(args, "-p", pkg)
+			}
+			if p.Run != "" {
+				args = append(args, p.Run)
+			}
+			return args, nil
+		}}, true
+
+	case hasNpmTestScript(filepath.Join(dir, "package.json")):
+		return testRunner{name: "npm", command: "npm", args: func(p RunTestsParams) ([]string, error) {
+			if p.Run != "" {
+				return nil, fmt.Errorf("the run filter isn't supported for npm test; pass test files as packages instead")
+			}
+			args := []string{"test", "--silent"}
+			if len(p.Packages) > 0 {
+				args = append(append(args, "--"), p.Packages...)
+			}
+			return args, nil
+		}}, true
+
+	case exists("pytest.ini") || exists("pyproject.toml") || exists("setup.cfg") || exists("tox.ini") || exists("conftest.py"):
+		return testRunner{name: "pytest", command: "pytest", args: func(p RunTestsParams) ([]string, error) {
+			args := []string{"-q", "--tb=short", "-rf"}
+			if p.Run != "" {
+				args = append(args, "-k", p.Run)
+			}
+			return append(args, p.Packages...), nil
+		}}, true
+	}
+	return testRunner{}, false
+}
+
+// hasNpmTestScript reports whether package.json defines a real test script
+func hasNpmTestScript(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return false
+	}
+	script := pkg.Scripts["test"]
+	// npm init's placeholder only prints an error
+	return script != "" && !strings.Contains(script, "no test specified")
+}
+
+// goTestEvent is one line of go test -json output
+type goTestEvent struct {
+	Action     string `json:"Action"`
+	Package    string `json:"Package"`
+	ImportPath string `json:"ImportPath"` // Set instead of Package on build output
+	Test       string `json:"Test"`
+	Output     string `json:"Output"`
+}
+
+// goTestKey identifies a test, or a package when test is empty
+type goTestKey struct{ pkg, test string }
+
+// runGoTests runs go test -json, reporting each finished package as
+// progress, and collects the failing tests with their output
+func runGoTests(ctx context.Context, dir string, args []string, publish ProgressPublisher) (string, []TestFailure, int, error) {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", nil, 0, err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", nil, 0, err
+	}
+
+	// Output is kept per test, and per package for lines outside tests
+	output := make(map[goTestKey][]string)
+	var failed []goTestKey
+	var other []string
+	passedPkgs, failedPkgs, passedTests, skippedTests := 0, 0, 0, 0
+	failedTests := make(map[string]int)
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var ev goTestEvent
+		if len(line) == 0 || line[0] != '{' || json.Unmarshal(line, &ev) != nil {
+			// Build errors come as plain text before the JSON
+			other = append(other, string(line))
+			continue
+		}
+		if ev.Package == "" {
+			ev.Package = strings.Fields(ev.ImportPath + " ")[0]
+		}
+		k := goTestKey{ev.Package, ev.Test}
+		switch ev.Action {
+		case "output", "build-output":
+			output[k] = append(output[k], strings.TrimRight(ev.Output, "\n"))
+		case "pass":
+			if ev.Test == "" {
+				passedPkgs++
+				publish("Testing", 0, passedPkgs+failedPkgs, ev.Package)
+			} else {
+				passedTests++
+			}
+		case "skip":
+			if ev.Test != "" {
+				skippedTests++
+			}
+		case "fail":
+			if ev.Test == "" {
+				failedPkgs++
+				publish("Testing", 0, passedPkgs+failedPkgs, ev.Package)
+			} else {
+				failedTests[ev.Package]++
+			}
+			failed = append(failed, k)
+		}
+	}
+	scanErr := scanner.Err()
+	runErr := cmd.Wait()
+	exitCode := 0
+	if exitErr, ok := runErr.(*exec.ExitError); ok {
+		exitCode = exitErr.ExitCode()
+	} else if runErr != nil {
+		return "", nil, 0, runErr
+	}
+	if scanErr != nil {
+		return "", nil, exitCode, scanErr
+	}
+
+	var failures []TestFailure
+	for _, k := range failed {
+		// Subtest failures fail their parents too; report the innermost
+		if k.test != "" && hasFailedSubtest(failed, k.pkg, k.test) {
+			continue
+		}
+		if k.test == "" && failedTests[k.pkg] > 0 {
+			continue
+		}
+		lines := relevantGoTestOutput(output[k], k.test == "")
+		if k.test == "" && len(lines) == 0 {
+			lines = relevantGoTestOutput(other, true)
+		}
+		failures = append(failures, TestFailure{Package: k.pkg, Test: k.test, Output: strings.Join(lines, "\n")})
+	}
+
+	// Failures outside any package (a bad pattern, a broken go.mod)
+	if exitCode != 0 && len(failures) == 0 {
+		lines := relevantGoTestOutput(append(other, strings.Split(strings.TrimSpace(stderr.String()), "\n")...), true)
+		failures = append(failures, TestFailure{Output: strings.Join(lines, "\n")})
+	}
+
+	total := 0
+	for _, n := range failedTests {
+		total += n
+	}
+	summary := fmt.Sprintf("%d packages passed, %d failed; %d tests passed, %d failed, %d skipped",
+		passedPkgs, failedPkgs, passedTests, total, skippedTests)
+	return summary, failures, exitCode, nil
+}
+
+// hasFailedSubtest reports whether one of test's subtests failed
+func hasFailedSubtest(failed []goTestKey, pkg, test string) bool {
+	for _, k := range failed {
+		if k.pkg == pkg && strings.HasPrefix(k.test, test+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// relevantGoTestOutput drops go test's bookkeeping lines, keeping what the
+// test or compiler printed
+func relevantGoTestOutput(lines []string, packageLevel bool) []string {
+	var kept []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "=== "):
+		case strings.HasPrefix(trimmed, "--- "):
+		case trimmed == "PASS", trimmed == "FAIL":
+		case strings.HasPrefix(trimmed, "ok "), strings.HasPrefix(trimmed, "?  "):
+		case packageLevel && strings.HasPrefix(trimmed, "FAIL\t"):
+		default:
+			kept = append(kept, strings.TrimRight(line, " \t"))
+		}
+	}
+	return capLines(kept, maxFailureLines)
+}
+
+// runOtherTests runs a runner whose output isn't parsed, keeping the lines
+// that mention failures, or the end of the output when none do
+func runOtherTests(ctx context.Context, dir, command string, args []string) (string, []TestFailure, int, error) {
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Dir = dir
+	// CI makes watch-mode runners such as jest run once and exit
+	cmd.Env = append(os.Environ(), "CI=true")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	runErr := cmd.Run()
+	exitCode := 0
+	if exitErr, ok := runErr.(*exec.ExitError); ok {
+		exitCode = exitErr.ExitCode()
+	} else if runErr != nil {
+		return "", nil, 0, runErr
+	}
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if exitCode == 0 {
+		return lastNonEmptyLine(lines), nil, 0, nil
+	}
+	var kept []string
+	for _, line := range lines {
+		if testFailureLine.MatchString(line) {
+			kept = append(kept, strings.TrimRight(line, " \t"))
+		}
+	}
+	if len(kept) == 0 {
+		kept = lines
+		if len(kept) > maxGenericOutputTail {
+			kept = kept[len(kept)-maxGenericOutputTail:]
+		}
+	}
+	failure := TestFailure{Output: strings.Join(capLines(kept, maxGenericOutputTail), "\n")}
+	return lastNonEmptyLine(lines), []TestFailure{failure}, exitCode, nil
+}
+
+// lastNonEmptyLine returns the last line with text, which is where most
+// runners print their totals
+func lastNonEmptyLine(lines []string) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// capLines keeps the first max lines, noting how many were dropped
+func capLines(lines []string, max int) []string {
+	if len(lines) <= max {
+		return lines
+	}
+	return append(lines[:max:max], fmt.Sprintf("... %d more lines", len(lines)-max))
+}
+
+// formatTestFailures lists the failures grouped by package
+func formatTestFailures(failures []TestFailure) string {
+	if len(failures) == 0 {
+		return ""
+	}
+	sorted := append([]TestFailure(nil), failures...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Package < sorted[j].Package })
+
+	var sb strings.Builder
+	lastPkg := "\x00"
+	for i, f := range sorted {
+		if i == maxTestFailures {
+			fmt.Fprintf(&sb, "\n... and %d more failures\n", len(sorted)-i)
+			break
+		}
+		if f.Package != lastPkg && f.Package != "" {
+			fmt.Fprintf(&sb, "\nFAIL %s\n", f.Package)
+		}
+		lastPkg = f.Package
+		indent := "  "
+		switch {
+		case f.Test != "":
+			fmt.Fprintf(&sb, "  %s\n", f.Test)
+			indent = "    "
+		case f.Package != "":
+			sb.WriteString("  (no test failed: the package did not build or exited early)\n")
+		default:
+			sb.WriteString("\n")
+		}
+		for _, line := range strings.Split(f.Output, "\n") {
+			if line != "" {
+				sb.WriteString(indent + strings.TrimSpace(line) + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
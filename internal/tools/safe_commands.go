@@ -7,13 +7,13 @@ var safeCommands = []string{
 	"git status",
 	"git log",
 	"git diff",
-	"git branch",
 	"git show",
 	"git remote",
 	"git config --get",
 	
 	// Directory navigation and listing
 	"pwd",
+	"cd",
 	"ls",
 	"dir",
 	"tree",
@@ -22,8 +22,6 @@ var safeCommands = []string{
 	"cat",
 	"head",
 	"tail",
-	"less",
-	"more",
 	
 	// System information
 	"whoami",
@@ -34,8 +32,6 @@ var safeCommands = []string{
 	
 	// Process information
 	"ps",
-	"top",
-	"htop",
 	
 	// Package info (read-only)
 	"npm list",
@@ -45,7 +41,6 @@ var safeCommands = []string{
 	"cargo tree",
 	
 	// Environment
-	"env",
 	"printenv",
 	"echo",
 	
@@ -81,4 +76,72 @@ var safeCommands = []string{
 	"which",
 	"whereis",
 	"type",
-}
\ No newline at end of file
+}
+
+// unsafeArguments are the options and subcommands that let a safe command
+// change something or run another program. A command using one of them
+// needs permission. Options starting with -- also match git's
+// abbreviations of them, like --out=x for --output=x.
+var unsafeArguments = map[string][]string{
+	"git log":    {"--output", "--ext-diff"},
+	"git diff":   {"--output", "--ext-diff"},
+	"git show":   {"--output", "--ext-diff"},
+	"git remote": {"add", "remove", "rm", "rename", "set-url", "set-head", "set-branches", "prune", "update"},
+	"tree":       {"-o"},
+	"go list":    {"-toolexec", "--toolexec"},
+	"man":        {"-P", "--pager", "-H", "--html"},
+	"rg":         {"--pre"},
+	"ag":         {"--pager"},
+	"find":       {"-exec", "-execdir", "-ok", "-okdir", "-delete", "-fprint", "-fprint0", "-fprintf", "-fls"},
+}
+
+// commandWrappers run the command that follows their own options, like
+// env, xargs or timeout. The denylist and allowlist are checked against
+// the wrapped command as well as the wrapper.
+var commandWrappers = map[string]commandWrapper{
+	"env":     {valued: []string{"-u", "--unset", "-C", "--chdir"}, split: []string{"-S", "--split-string"}, assignments: true},
+	"nice":    {valued: []string{"-n", "--adjustment"}},
+	"timeout": {valued: []string{"-s", "--signal", "-k", "--kill-after"}, operands: 1},
+	"xargs":   {valued: []string{"-a", "--arg-file", "-d", "--delimiter", "-E", "-I", "-L", "--max-lines", "-n", "--max-args", "-P", "--max-procs", "-s", "--max-chars", "--process-slot-var"}},
+	"nohup":   {},
+	"exec":    {valued: []string{"-a"}},
+}
+
+// commandWrapper describes the arguments a wrapper takes before the
+// command it runs
+type commandWrapper struct {
+	valued      []string // Options whose value is the next word
+	split       []string // Options whose value is the command line to run, like env -S
+	operands    int      // Words before the command that aren't options, like timeout's duration
+	assignments bool     // NAME=value words come before the command
+}
+
+// recursiveReaders read every file below the directories they are given,
+// so the guardrails check what lies below those directories too
+var recursiveReaders = map[string]recursiveReader{
+	"grep":  grepReader,
+	"egrep": grepReader,
+	"fgrep": grepReader,
+	"rg":    {valued: []string{"-e", "-f", "-g", "-t", "-T", "-m", "-A", "-B", "-C", "-M", "-j", "-E", "--glob", "--iglob", "--type", "--type-not"}, hidden: []string{"--hidden", "-.", "-u", "--unrestricted"}, searcher: true},
+	"ag":    {valued: []string{"-G", "-m", "-A", "-B", "-C", "--ignore", "--file-search-regex"}, hidden: []string{"--hidden", "-u", "--unrestricted"}, searcher: true},
+	"cp":    {recursive: []string{"-r", "-R", "-a", "--recursive", "--archive"}},
+	"scp":   {recursive: []string{"-r"}},
+	"zip":   {recursive: []string{"-r", "--recurse-paths"}},
+	"tar":   {},
+	"rsync": {},
+}
+
+// grepReader is grep and its variants, which recurse when asked to
+var grepReader = recursiveReader{
+	recursive: []string{"-r", "-R", "--recursive", "--dereference-recursive", "--directories", "recurse"},
+	valued:    []string{"-e", "-f", "-m", "-A", "-B", "-C", "-d", "-D"},
+	searcher:  true,
+}
+
+// recursiveReader describes how a command reads directory trees
+type recursiveReader struct {
+	recursive []string // Options that make it recurse; none means it always does
+	valued    []string // Options whose value is the next word
+	hidden    []string // Options that make it read dot files it otherwise skips
+	searcher  bool     // Takes a pattern first, and reads the working directory when given no path
+}
@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/permission"
+)
+
+// ScaffoldParams represents parameters for the scaffold tool
+type ScaffoldParams struct {
+	Thing string `json:"thing"` // What to create, e.g. "package cache with an LRU type"
+}
+
+// scaffoldTool generates new files following the project's conventions
+type scaffoldTool struct {
+	registry  *Registry
+	llmClient llm.Client
+}
+
+// Limits on the example files shown to the model
+const (
+	maxScaffoldExamples     = 2    // Source files, each with its test
+	maxScaffoldExampleBytes = 3000 // Of each file, from the top
+)
+
+const (
+	// ScaffoldToolName is the name of this tool
+	ScaffoldToolName = "scaffold"
+	// scaffoldDescription describes what this tool does
+	scaffoldDescription = `Generate skeleton files for a new module, package, handler or tool, following the project's conventions.
+
+WHAT THIS DOES:
+- Reads the project's layout, file naming and test style, its patterns.md and the existing files most like what is asked for
+- Has the model write the new files as skeletons with a test
+- Shows the diff in the permission prompt; nothing is written unless approved
+
+RULES:
+- Only creates files; a generated path that already exists is skipped
+- The prompt can be tuned per project in .loco/prompts/scaffold.tmpl`
+)
+
+// NewScaffoldTool creates a new scaffold tool. llmClient may be nil until a
+// model is configured.
+func NewScaffoldTool(registry *Registry, llmClient llm.Client) BaseTool {
+	return &scaffoldTool{registry: registry, llmClient: llmClient}
+}
+
+// Name returns the tool name
+func (t *scaffoldTool) Name() string {
+	return ScaffoldToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: calls wait on the model and a permission prompt
+func (t *scaffoldTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *scaffoldTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ScaffoldToolName,
+		Description: scaffoldDescription,
+		Parameters: map[string]any{
+			"thing": map[string]any{
+				"type":        "string",
+				"description": "What to create, e.g. \"package cache with an LRU type\" or \"tool that lists open ports\"",
+			},
+		},
+		Required: []string{"thing"},
+		Commands: []CommandInfo{
+			{
+				Command:     "scaffold",
+				Description: "Generate skeleton files the way the project lays them out",
+				Examples:    []string{"/scaffold package cache with an LRU type", "/scaffold tool that lists open ports"},
+			},
+		},
+	}
+}
+
+// Run builds the prompt from the project's conventions, has the model write
+// the files and stages them for one approval
+func (t *scaffoldTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	if t.llmClient == nil {
+		return NewTextErrorResponse("No model available to scaffold with - check your LM Studio connection"), nil
+	}
+	var params ScaffoldParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	params.Thing = strings.TrimSpace(params.Thing)
+	if params.Thing == "" {
+		return NewTextErrorResponse("thing parameter is required"), nil
+	}
+	workingDir := t.registry.workingDir
+
+	conventions, err := analysis.DescribeConventions(workingDir)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	data := map[string]any{"Thing": params.Thing, "Conventions": strings.TrimSpace(conventions), "Patterns": "", "Examples": ""}
+	if _, patterns, ok := analysis.LoadBestKnowledge(workingDir, "patterns"); ok {
+		data["Patterns"] = truncateOutput(patterns, 4000)
+	}
+	examples, _ := analysis.ConventionExamples(workingDir, params.Thing, maxScaffoldExamples)
+	var shown strings.Builder
+	for _, example := range examples {
+		content, err := os.ReadFile(filepath.Join(workingDir, example))
+		if err != nil {
+			continue
+		}
+		if len(content) > maxScaffoldExampleBytes {
+			content = append(content[:maxScaffoldExampleBytes], "\n..."...)
+		}
+		fmt.Fprintf(&shown, "FILE: %s\n```\n%s\n```\n\n", example, strings.TrimRight(string(content), "\n"))
+	}
+	data["Examples"] = strings.TrimSpace(shown.String())
+
+	publish := GetProgressPublisher(ctx)
+	publish("Generating", 0, 0, params.Thing)
+
+	reply, err := t.llmClient.Complete(ctx, []llm.Message{
+		{Role: "user", Content: analysis.RenderPrompt(workingDir, "scaffold", data)},
+	})
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("Failed to generate files: %s", err)), nil
+	}
+	files := parseScaffoldFiles(reply)
+	if len(files) == 0 {
+		return NewTextErrorResponse("The model didn't return any files in the FILE: format"), nil
+	}
+
+	tx := t.registry.BeginTransaction()
+	var skipped []string
+	for _, file := range files {
+		absPath, relPath, err := resolveProjectPath(workingDir, file.path)
+		if err != nil {
+			tx.Rollback()
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		if _, err := os.Stat(absPath); err == nil {
+			skipped = append(skipped, relPath)
+			continue
+		}
+		if err := tx.Write(relPath, file.content); err != nil {
+			tx.Rollback()
+			return NewTextErrorResponse(fmt.Sprintf("no files were created: %s", err)), nil
+		}
+	}
+
+	applied, err := tx.Commit(ctx, ScaffoldToolName)
+	if errors.Is(err, permission.ErrorPermissionDenied) {
+		return NewTextErrorResponse("permission denied: no files were created"), nil
+	}
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if len(applied) == 0 {
+		return NewTextErrorResponse(fmt.Sprintf("Nothing created: every generated file already exists (%s)", strings.Join(skipped, ", "))), nil
+	}
+
+	var sb strings.Builder
+	paths := make([]string, 0, len(applied))
+	fmt.Fprintf(&sb, "Created %d files for %s:\n", len(applied), params.Thing)
+	for _, change := range applied {
+		fmt.Fprintf(&sb, "- %s (%d lines)\n", change.Path, change.LinesAdded)
+		paths = append(paths, change.Path)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&sb, "Skipped, already existing: %s\n", strings.Join(skipped, ", "))
+	}
+	return WithResponseMetadata(NewTextResponse(sb.String()), map[string]any{
+		"paths":   paths,
+		"skipped": skipped,
+	}), nil
+}
+
+// scaffoldFile is one file of the model's reply
+type scaffoldFile struct {
+	path    string
+	content string
+}
+
+// parseScaffoldFiles reads FILE: <path> lines each followed by a fenced
+// code block
+func parseScaffoldFiles(reply string) []scaffoldFile {
+	var files []scaffoldFile
+	lines := strings.Split(reply, "\n")
+	for i := 0; i < len(lines); i++ {
+		name, ok := strings.CutPrefix(strings.TrimSpace(lines[i]), "FILE:")
+		if !ok {
+			continue
+		}
+		name = strings.Trim(strings.TrimSpace(name), "`*")
+		start := i + 1
+		for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+			start++
+		}
+		if name == "" || start >= len(lines) || !strings.HasPrefix(strings.TrimSpace(lines[start]), "```") {
+			continue
+		}
+		end := start + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "```" {
+			end++
+		}
+		content := strings.Join(lines[start+1:min(end, len(lines))], "\n")
+		files = append(files, scaffoldFile{path: name, content: strings.TrimRight(content, "\n") + "\n"})
+		i = end
+	}
+	return files
+}
@@ -0,0 +1,524 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/files"
+)
+
+// SearchCodeParams represents parameters for code search
+type SearchCodeParams struct {
+	Pattern    string `json:"pattern"`               // Regex (or literal) to search for
+	Path       string `json:"path,omitempty"`        // Directory or file to search (default: working directory)
+	Include    string `json:"include,omitempty"`     // Glob filter such as *.go or internal/**/*.go
+	Context    int    `json:"context,omitempty"`     // Lines of context around each match
+	MaxResults int    `json:"max_results,omitempty"` // Maximum number of matching lines
+	IgnoreCase bool   `json:"ignore_case,omitempty"` // Case-insensitive matching
+	Literal    bool   `json:"literal,omitempty"`     // Treat pattern as a fixed string
+}
+
+// searchLine is a single output line, either a match or surrounding context.
+type searchLine struct {
+	Path    string
+	Line    int
+	Text    string
+	IsMatch bool
+}
+
+// searchCodeTool implements pattern search across the project
+type searchCodeTool struct {
+	workingDir string
+}
+
+const (
+	// SearchCodeToolName is the name of this tool
+	SearchCodeToolName = "search_code"
+	// searchCodeDescription describes what this tool does
+	searchCodeDescription = `Search the codebase for a regex or literal pattern.
+
+WHAT THIS DOES:
+- Finds matching lines across project files
+- Shows surrounding context lines
+- Respects the same ignore rules as indexing
+- Uses ripgrep when installed, otherwise a built-in walker
+
+WHEN TO USE:
+- Locating where a symbol is defined or used
+- Finding examples of a pattern before editing
+- Checking a file's contents without reading all of it
+
+OUTPUT:
+- Matches grouped by file with line numbers
+- Context lines marked separately from matches`
+
+	defaultSearchResults = 50
+	maxSearchResults     = 500
+	maxSearchContext     = 10
+	maxSearchFileSize    = 1 << 20
+	maxSearchLineLength  = 300
+)
+
+// NewSearchCodeTool creates a new code search tool
+func NewSearchCodeTool(workingDir string) BaseTool {
+	return &searchCodeTool{
+		workingDir: workingDir,
+	}
+}
+
+// Name returns the tool name
+func (s *searchCodeTool) Name() string {
+	return SearchCodeToolName
+}
+
+// Info returns the tool information
+func (s *searchCodeTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        SearchCodeToolName,
+		Description: searchCodeDescription,
+		Parameters: map[string]any{
+			"pattern": map[string]any{
+				"type":        "string",
+				"description": "Regular expression to search for (ripgrep's syntax when it is installed, otherwise Go's)",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Directory or file to search, relative to the project (default: project root)",
+			},
+			"include": map[string]any{
+				"type":        "string",
+				"description": "Only search files matching this glob (e.g. *.go)",
+			},
+			"context": map[string]any{
+				"type":        "integer",
+				"description": "Lines of context to show around each match (default 0, max 10)",
+				"minimum":     0,
+			},
+			"max_results": map[string]any{
+				"type":        "integer",
+				"description": "Maximum matching lines to return (default 50)",
+				"minimum":     1,
+			},
+			"ignore_case": map[string]any{
+				"type":        "boolean",
+				"description": "Match case-insensitively",
+			},
+			"literal": map[string]any{
+				"type":        "boolean",
+				"description": "Treat pattern as a fixed string instead of a regex",
+			},
+		},
+		Required: []string{"pattern"},
+		Commands: []CommandInfo{
+			{
+				Command:     "search",
+				Aliases:     []string{"grep"},
+				Description: "Search code",
+				Examples:    []string{"/search NewService", "/grep TODO"},
+			},
+		},
+	}
+}
+
+// Run executes the search
+func (s *searchCodeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params SearchCodeParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	if params.Pattern == "" {
+		return NewTextErrorResponse("pattern parameter is required"), nil
+	}
+	if params.MaxResults <= 0 {
+		params.MaxResults = defaultSearchResults
+	}
+	if params.MaxResults > maxSearchResults {
+		params.MaxResults = maxSearchResults
+	}
+	if params.Context < 0 {
+		params.Context = 0
+	}
+	if params.Context > maxSearchContext {
+		params.Context = maxSearchContext
+	}
+
+	root, err := s.resolvePath(params.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	var lines []searchLine
+	var truncated bool
+	if rgPath, lookErr := exec.LookPath("rg"); lookErr == nil {
+		// ripgrep checks the pattern itself; its regex syntax differs from Go's
+		lines, truncated, err = s.searchRipgrep(ctx, rgPath, root, params)
+	} else {
+		expr := params.Pattern
+		if params.Literal {
+			expr = regexp.QuoteMeta(expr)
+		}
+		if params.IgnoreCase {
+			expr = "(?i)" + expr
+		}
+		re, compileErr := regexp.Compile(expr)
+		if compileErr != nil {
+			return NewTextErrorResponse(fmt.Sprintf("invalid pattern: %s", compileErr)), nil
+		}
+		lines, truncated, err = s.searchNative(ctx, root, re, params)
+	}
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("search failed: %s", err)), nil
+	}
+
+	return s.formatResults(params, lines, truncated), nil
+}
+
+// resolvePath turns a user path into an absolute path inside the working directory
+func (s *searchCodeTool) resolvePath(path string) (string, error) {
+	if path == "" {
+		return s.workingDir, nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.workingDir, path)
+	}
+	path = filepath.Clean(path)
+
+	rel, err := filepath.Rel(s.workingDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside the project", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("path not found: %s", rel)
+	}
+	return path, nil
+}
+
+// searchNative walks the tree and matches lines with Go's regexp engine
+func (s *searchCodeTool) searchNative(ctx context.Context, root string, re *regexp.Regexp, params SearchCodeParams) ([]searchLine, bool, error) {
+	var results []searchLine
+	matches := 0
+	truncated := false
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		rel := s.relPath(path)
+		if d.IsDir() {
+			if path != root && files.ShouldIgnore(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if files.ShouldIgnore(rel) || !matchesInclude(params.Include, rel) {
+			return nil
+		}
+
+		fileLines, fileMatches := searchFile(path, rel, re, params.Context, params.MaxResults-matches)
+		results = append(results, fileLines...)
+		matches += fileMatches
+		if matches >= params.MaxResults {
+			truncated = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return results, truncated, nil
+}
+
+// searchFile returns matching lines (with context) from one file, up to limit matches
+func searchFile(path, rel string, re *regexp.Regexp, contextLines, limit int) ([]searchLine, int) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxSearchFileSize {
+		return nil, 0
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || isBinary(data) {
+		return nil, 0
+	}
+
+	var all []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSearchFileSize)
+	for scanner.Scan() {
+		all = append(all, scanner.Text())
+	}
+
+	var results []searchLine
+	matches := 0
+	emitted := -1 // Index of the last line already emitted
+	for i, text := range all {
+		if !re.MatchString(text) {
+			continue
+		}
+		start := i - contextLines
+		if start <= emitted {
+			start = emitted + 1
+		}
+		for j := start; j < i; j++ {
+			results = append(results, searchLine{Path: rel, Line: j + 1, Text: all[j]})
+		}
+		results = append(results, searchLine{Path: rel, Line: i + 1, Text: text, IsMatch: true})
+		emitted = i
+		matches++
+
+		// Trailing context, stopping short of the next match so it is reported as one
+		end := i + contextLines
+		if end >= len(all) {
+			end = len(all) - 1
+		}
+		for j := i + 1; j <= end && !re.MatchString(all[j]); j++ {
+			results = append(results, searchLine{Path: rel, Line: j + 1, Text: all[j]})
+			emitted = j
+		}
+
+		if matches >= limit {
+			break
+		}
+	}
+
+	return results, matches
+}
+
+// rgMessage is the subset of ripgrep's --json output we consume
+type rgMessage struct {
+	Type string `json:"type"`
+	Data struct {
+		Path struct {
+			Text string `json:"text"`
+		} `json:"path"`
+		Lines struct {
+			Text string `json:"text"`
+		} `json:"lines"`
+		LineNumber int `json:"line_number"`
+	} `json:"data"`
+}
+
+// searchRipgrep shells out to ripgrep and converts its JSON stream,
+// stopping ripgrep once max_results matches have been read
+func (s *searchCodeTool) searchRipgrep(ctx context.Context, rgPath, root string, params SearchCodeParams) ([]searchLine, bool, error) {
+	// No file can contribute more than the whole limit
+	args := []string{"--json", "--max-filesize", strconv.Itoa(maxSearchFileSize), "--max-count", strconv.Itoa(params.MaxResults)}
+	if params.Context > 0 {
+		args = append(args, "--context", strconv.Itoa(params.Context))
+	}
+	if params.IgnoreCase {
+		args = append(args, "--ignore-case")
+	}
+	if params.Literal {
+		args = append(args, "--fixed-strings")
+	}
+	if params.Include != "" {
+		args = append(args, "--glob", params.Include)
+	}
+	args = append(args, "--regexp", params.Pattern, root)
+
+	rgCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	cmd := exec.CommandContext(rgCtx, rgPath, args...)
+	cmd.Dir = s.workingDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, false, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, false, err
+	}
+
+	var results []searchLine
+	matches := 0
+	truncated := false
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSearchFileSize)
+	for scanner.Scan() {
+		var msg rgMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if msg.Type != "match" && msg.Type != "context" {
+			continue
+		}
+
+		rel := s.relPath(msg.Data.Path.Text)
+		if files.ShouldIgnore(rel) {
+			continue
+		}
+		isMatch := msg.Type == "match"
+		if isMatch && matches >= params.MaxResults {
+			truncated = true
+			break
+		}
+		results = append(results, searchLine{
+			Path:    rel,
+			Line:    msg.Data.LineNumber,
+			Text:    strings.TrimRight(msg.Data.Lines.Text, "\r\n"),
+			IsMatch: isMatch,
+		})
+		if isMatch {
+			matches++
+		}
+	}
+
+	scanErr := scanner.Err()
+	if truncated || scanErr != nil {
+		// Nothing more is read, so ripgrep needn't finish the search
+		stop()
+	}
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return nil, false, ctx.Err()
+	}
+	if scanErr != nil {
+		return nil, false, fmt.Errorf("reading ripgrep output: %w", scanErr)
+	}
+	if truncated {
+		return results, true, nil
+	}
+	if err != nil {
+		// Exit code 1 means no matches
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, false, fmt.Errorf("%s", msg)
+			}
+			return nil, false, err
+		}
+	}
+
+	return results, truncated, nil
+}
+
+// relPath converts an absolute path to one relative to the working directory
+func (s *searchCodeTool) relPath(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.workingDir, path)
+	}
+	if rel, err := filepath.Rel(s.workingDir, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// formatResults renders search lines grouped by file
+func (s *searchCodeTool) formatResults(params SearchCodeParams, lines []searchLine, truncated bool) ToolResponse {
+	var response strings.Builder
+
+	matches := 0
+	fileCount := 0
+	lastPath := ""
+	for _, line := range lines {
+		if line.IsMatch {
+			matches++
+		}
+		if line.Path != lastPath {
+			fileCount++
+			lastPath = line.Path
+		}
+	}
+
+	response.WriteString("🔎 **Code Search Results**\n\n")
+	response.WriteString(fmt.Sprintf("**Pattern:** `%s`\n", params.Pattern))
+	if params.Include != "" {
+		response.WriteString(fmt.Sprintf("**Include:** %s\n", params.Include))
+	}
+
+	if matches == 0 {
+		response.WriteString("\n*No matches found.*\n")
+		return NewTextResponse(response.String())
+	}
+
+	response.WriteString(fmt.Sprintf("**Found:** %d matches in %d files\n", matches, fileCount))
+
+	lastPath = ""
+	lastLine := 0
+	for _, line := range lines {
+		if line.Path != lastPath {
+			if lastPath != "" {
+				response.WriteString("```\n")
+			}
+			response.WriteString(fmt.Sprintf("\n## %s\n```\n", line.Path))
+			lastPath = line.Path
+		} else if line.Line > lastLine+1 {
+			response.WriteString("--\n")
+		}
+		lastLine = line.Line
+
+		text := line.Text
+		if len(text) > maxSearchLineLength {
+			text = text[:maxSearchLineLength] + "..."
+		}
+		sep := "-"
+		if line.IsMatch {
+			sep = ":"
+		}
+		response.WriteString(fmt.Sprintf("%d%s %s\n", line.Line, sep, text))
+	}
+	response.WriteString("```\n")
+
+	if truncated {
+		response.WriteString(fmt.Sprintf("\n*Stopped after %d matches. Narrow the pattern or raise max_results to see more.*\n", params.MaxResults))
+	}
+
+	return WithResponseMetadata(NewTextResponse(response.String()), map[string]any{
+		"matches":   matches,
+		"files":     fileCount,
+		"truncated": truncated,
+	})
+}
+
+// matchesInclude applies an include glob to a relative path.
+// Patterns without a slash match the base name, like ripgrep's --glob.
+func matchesInclude(include, rel string) bool {
+	if include == "" {
+		return true
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.Contains(include, "/") {
+		ok, _ := filepath.Match(include, filepath.Base(rel))
+		return ok
+	}
+	if strings.Contains(include, "**") {
+		// Treat ** as "any directories"; compare prefix and suffix around it
+		parts := strings.SplitN(include, "**", 2)
+		prefix := parts[0]
+		suffix := strings.TrimPrefix(parts[1], "/")
+		if !strings.HasPrefix(rel, prefix) {
+			return false
+		}
+		ok, _ := filepath.Match(suffix, filepath.Base(rel))
+		return ok
+	}
+	ok, _ := filepath.Match(include, rel)
+	return ok
+}
+
+// isBinary reports whether data looks like a binary file
+func isBinary(data []byte) bool {
+	sample := data
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+	return bytes.IndexByte(sample, 0) >= 0
+}
@@ -0,0 +1,234 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/llm/queue"
+	"github.com/billie-coop/loco/internal/permission"
+)
+
+// startupScanTool detects what a project is at a glance: type, language and
+// framework from the file list, and a one-line purpose from the model. The
+// model request goes through the queue, and a new scan supersedes one still
+// waiting or running.
+type startupScanTool struct {
+	permissions   permission.Service
+	queue         *queue.Manager
+	analysis      analysis.Service
+	client        llm.Client
+	configManager *config.Manager
+	workingDir    string
+
+	mu         sync.Mutex
+	lastID     string             // Queue ID of the latest scan
+	lastCancel context.CancelFunc // Stops the latest scan's wait
+}
+
+const (
+	// StartupScanToolName is the name of this tool
+	StartupScanToolName = "startup_scan"
+
+	// startupScanPriority puts the scan ahead of background tiers, which use 5
+	startupScanPriority = 8
+	// startupScanTimeout bounds the model request
+	startupScanTimeout = 2 * time.Minute
+
+	// startupScanDescription describes what this tool does
+	startupScanDescription = `Identify the project at a glance: its type, main language, framework and a one-line purpose.
+
+WHEN TO USE:
+- At startup, or after large changes, for a quick picture of the project before deeper analysis
+
+OUTPUT:
+- Type, language and framework detected from the file list
+- A purpose of at most 10 words from the model, refined on each run
+- Saved to .loco/startup_scan.json, where the quick tier builds on it
+
+The first scan in a project asks permission before sending its file list and README to the model.`
+)
+
+// NewStartupScanTool creates a new startup scan tool. Without a queue the
+// model is called directly; without a client only the file list is used.
+func NewStartupScanTool(permissions permission.Service, q *queue.Manager, analysisService analysis.Service, client llm.Client, configManager *config.Manager, workingDir string) BaseTool {
+	return &startupScanTool{
+		permissions:   permissions,
+		queue:         q,
+		analysis:      analysisService,
+		client:        client,
+		configManager: configManager,
+		workingDir:    workingDir,
+	}
+}
+
+// Name returns the tool name
+func (t *startupScanTool) Name() string { return StartupScanToolName }
+
+// Info returns the tool information
+func (t *startupScanTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        StartupScanToolName,
+		Description: startupScanDescription,
+		Parameters:  map[string]any{},
+		Required:    []string{},
+		Commands: []CommandInfo{
+			{
+				Command:     "scan",
+				Description: "Instant project detection",
+				Examples:    []string{"/scan"},
+			},
+		},
+	}
+}
+
+// Run scans the project and saves the result
+func (t *startupScanTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	start := time.Now()
+
+	scan, files, err := analysis.ScanProjectStructure(t.workingDir)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to list project files: %s", err)), nil
+	}
+
+	// A clean scan starts over as if none had run before
+	previous := analysis.LoadStartupScan(t.workingDir)
+	if cfg := t.config(); cfg != nil && cfg.Analysis.Startup.Clean {
+		previous = nil
+	}
+
+	note := ""
+	if t.client == nil {
+		note = "LM Studio not connected: detected from the file list only"
+	} else if !t.allowed(ctx, call, previous) {
+		// Not saved, so the next scan asks again
+		scan.Iteration = 1
+		scan.Duration = time.Since(start)
+		return NewTextResponse(formatStartupScan(scan, "Permission denied: detected from the file list only")), nil
+	} else {
+		purpose, err := t.describe(ctx, scan, files, previous)
+		switch {
+		case err == nil && purpose != "":
+			scan.Purpose = purpose
+			scan.Confidence = 0.8
+			if previous != nil && strings.EqualFold(previous.Purpose, purpose) {
+				// Two runs agreeing is worth a little more
+				scan.Confidence = 0.9
+			}
+		case ctx.Err() != nil:
+			return NewTextErrorResponse("startup scan canceled"), nil
+		case errors.Is(err, context.Canceled):
+			return NewTextErrorResponse("startup scan superseded by a newer scan"), nil
+		case err != nil:
+			note = fmt.Sprintf("Model unavailable (%s): detected from the file list only", err)
+		}
+	}
+
+	if scan.Purpose == "" && previous != nil {
+		scan.Purpose = previous.Purpose
+	}
+	scan.Iteration = 1
+	if previous != nil {
+		scan.Iteration = previous.Iteration + 1
+	}
+	scan.Duration = time.Since(start)
+	if t.analysis != nil {
+		t.analysis.StoreStartupScan(t.workingDir, scan)
+	}
+	return NewTextResponse(formatStartupScan(scan, note)), nil
+}
+
+// allowed asks before the first scan in a project sends anything to the
+// model; later scans go ahead
+func (t *startupScanTool) allowed(ctx context.Context, call ToolCall, previous *analysis.StartupScanResult) bool {
+	if previous != nil || t.permissions == nil {
+		return true
+	}
+	sessionID, _ := GetContextValues(ctx)
+	return t.permissions.Request(ctx, permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		ToolCallID:  call.ID,
+		ToolName:    StartupScanToolName,
+		Action:      "scan",
+		Path:        t.workingDir,
+		Description: "Send this project's file list and README to the local model to identify what it is for",
+		Params:      map[string]any{},
+	})
+}
+
+// describe asks the model for the purpose through the queue, superseding
+// the previous scan. It returns context.Canceled when a newer scan took over.
+func (t *startupScanTool) describe(ctx context.Context, scan *analysis.StartupScanResult, files []string, previous *analysis.StartupScanResult) (string, error) {
+	if t.queue == nil {
+		return analysis.DescribeProject(ctx, t.client, scan, files, previous)
+	}
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var purpose string
+	done := make(chan error, 1)
+
+	opts := []queue.Option{
+		queue.WithPriority(startupScanPriority),
+		queue.WithType(StartupScanToolName),
+		queue.WithTimeout(startupScanTimeout),
+	}
+
+	t.mu.Lock()
+	if t.lastID != "" {
+		// A superseded scan that has not started never runs, so its wait
+		// is stopped here too
+		t.lastCancel()
+		opts = append(opts, queue.WithSupersedes(t.lastID))
+	}
+	id := t.queue.Submit(waitCtx, func(ctx context.Context) error {
+		var err error
+		purpose, err = analysis.DescribeProject(ctx, t.client, scan, files, previous)
+		done <- err
+		return err
+	}, opts...)
+	t.lastID, t.lastCancel = id, cancel
+	t.mu.Unlock()
+
+	select {
+	case err := <-done:
+		return purpose, err
+	case <-waitCtx.Done():
+		return "", context.Canceled
+	}
+}
+
+// config returns the current config, if any
+func (t *startupScanTool) config() *config.Config {
+	if t.configManager == nil {
+		return nil
+	}
+	return t.configManager.Get()
+}
+
+// formatStartupScan renders a scan in the fields the tool card shows
+func formatStartupScan(scan *analysis.StartupScanResult, note string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Type: %s\n", scan.ProjectType))
+	sb.WriteString(fmt.Sprintf("Language: %s\n", scan.Language))
+	if scan.Framework != "" {
+		sb.WriteString(fmt.Sprintf("Framework: %s\n", scan.Framework))
+	}
+	if scan.Purpose != "" {
+		sb.WriteString(fmt.Sprintf("Purpose: %s\n", scan.Purpose))
+	}
+	sb.WriteString(fmt.Sprintf("Files: %d\n", scan.FileCount))
+	sb.WriteString(fmt.Sprintf("Confidence: %.0f%%\n", scan.Confidence*100))
+	sb.WriteString(fmt.Sprintf("Iteration: %d (%s)", scan.Iteration, scan.Duration.Round(time.Millisecond)))
+	if note != "" {
+		sb.WriteString("\n" + note)
+	}
+	return sb.String()
+}
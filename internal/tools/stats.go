@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/llm/queue"
+)
+
+// statsTool shows how the model request queue is doing
+type statsTool struct {
+	queue *queue.Manager
+}
+
+const (
+	// StatsToolName is the name of this tool
+	StatsToolName = "stats"
+	// statsDescription describes what this tool does
+	statsDescription = `Show the model request queue: what runs, what waits and how fairly it is scheduled.
+
+OUTPUT:
+- Running and waiting requests, per model, with average time and error rate
+- The aging policy, how long started requests waited and how many aging moved ahead
+- Waiting requests in the order they will start, with what aging has added to their priority`
+
+	// maxStatsWaiting caps how many waiting requests are listed
+	maxStatsWaiting = 20
+)
+
+// NewStatsTool creates a new stats tool
+func NewStatsTool(queueManager *queue.Manager) BaseTool {
+	return &statsTool{queue: queueManager}
+}
+
+// Name returns the tool name
+func (t *statsTool) Name() string {
+	return StatsToolName
+}
+
+// Info returns the tool information
+func (t *statsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        StatsToolName,
+		Description: statsDescription,
+		Parameters:  map[string]any{},
+		Commands: []CommandInfo{
+			{
+				Command:     "stats",
+				Description: "Show model queue activity and fairness",
+				Examples:    []string{"/stats"},
+			},
+		},
+	}
+}
+
+// Run reports the queue's status
+func (t *statsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	if t.queue == nil {
+		return NewTextErrorResponse("the request queue is not available"), nil
+	}
+	status := t.queue.GetStatus()
+	waiting := t.queue.Waiting()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Model queue: %d running, %d waiting\n", status.Running, status.Pending)
+	if len(status.ActiveByModel) > 0 {
+		models := make([]string, 0, len(status.ActiveByModel))
+		for model, n := range status.ActiveByModel {
+			if model == "" {
+				model = "(shared pool)"
+			}
+			models = append(models, fmt.Sprintf("%s %d", model, n))
+		}
+		sort.Strings(models)
+		fmt.Fprintf(&sb, "Running per model: %s\n", strings.Join(models, ", "))
+	}
+	if status.AvgTime > 0 {
+		fmt.Fprintf(&sb, "Requests take %s on average, %.0f%% fail\n", status.AvgTime.Round(100*time.Millisecond), status.ErrorRate*100)
+	}
+
+	fmt.Fprintf(&sb, "\nFairness (aging: %s):\n", status.Aging)
+	if status.Started == 0 {
+		sb.WriteString("  Nothing has started yet\n")
+	} else {
+		fmt.Fprintf(&sb, "  %d started, after waiting %s on average; the longest wait was %s (%s)\n",
+			status.Started, status.AvgWait.Round(100*time.Millisecond), status.MaxWait.Round(100*time.Millisecond), status.MaxWaitType)
+		fmt.Fprintf(&sb, "  %d started ahead of higher-priority requests thanks to aging\n", status.Promoted)
+	}
+
+	if len(waiting) > 0 {
+		sb.WriteString("\nWaiting, in the order they will start:\n")
+		for i, item := range waiting {
+			if i == maxStatsWaiting {
+				fmt.Fprintf(&sb, "  ... and %d more\n", len(waiting)-i)
+				break
+			}
+			priority := fmt.Sprintf("priority %d", item.Priority)
+			if item.Boost > 0 {
+				priority += fmt.Sprintf(" +%d aging = %d", item.Boost, item.Priority+item.Boost)
+			}
+			name := item.Type
+			if item.Model != "" {
+				name += " on " + item.Model
+			}
+			fmt.Fprintf(&sb, "  %-32s %-26s waited %s\n", name, priority, item.Waited.Round(100*time.Millisecond))
+		}
+	}
+
+	return WithResponseMetadata(NewTextResponse(strings.TrimRight(sb.String(), "\n")), map[string]any{
+		"status":  status,
+		"waiting": waiting,
+	}), nil
+}
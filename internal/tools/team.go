@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+// teamTool shows which models fill the team slots and what they can do
+type teamTool struct {
+	team   *llm.ModelTeam
+	probes []llm.ModelProbe
+}
+
+const (
+	// TeamToolName is the name of this tool
+	TeamToolName = "team"
+	// teamDescription describes what this tool does
+	teamDescription = `Show the model team used for analysis tiers.
+
+OUTPUT:
+- The Small, Medium and Large models and the tiers they run
+- For each model probed at startup: size class, context length, tool-call support and speed
+
+Slots without a model_id in config.llm are picked from the probed models.`
+)
+
+// NewTeamTool creates a new team tool. A nil team means LM Studio was not
+// reachable yet.
+func NewTeamTool(team *llm.ModelTeam, probes []llm.ModelProbe) BaseTool {
+	return &teamTool{team: team, probes: probes}
+}
+
+// Name returns the tool name
+func (t *teamTool) Name() string {
+	return TeamToolName
+}
+
+// Info returns the tool information
+func (t *teamTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        TeamToolName,
+		Description: teamDescription,
+		Parameters:  map[string]any{},
+		Commands: []CommandInfo{
+			{
+				Command:     "team",
+				Description: "Show the model team and probed model capabilities",
+				Examples:    []string{"/team"},
+			},
+		},
+	}
+}
+
+// Run lists the team slots and the probed models
+func (t *teamTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	if t.team == nil {
+		return NewTextErrorResponse("no model team yet: LM Studio is not connected or has no models loaded"), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(t.team.Name + ":\n")
+	for _, slot := range []struct{ name, model, tiers string }{
+		{"Small", t.team.Small, "quick"},
+		{"Medium", t.team.Medium, "detailed"},
+		{"Large", t.team.Large, "deep"},
+	} {
+		model := slot.model
+		if model == "" {
+			model = "(none)"
+		}
+		sb.WriteString(fmt.Sprintf("  %-7s %s  [%s]\n", slot.name, model, slot.tiers))
+	}
+
+	sb.WriteString("\nProbed models:\n")
+	if len(t.probes) == 0 {
+		sb.WriteString("  (none; LM Studio's /api/v0 REST API was not available)\n")
+	}
+	for _, probe := range t.probes {
+		line := fmt.Sprintf("  %s  size %s", probe.ID, probe.Size)
+		if probe.ContextLength > 0 {
+			line += fmt.Sprintf(", ctx %d", probe.ContextLength)
+		}
+		if probe.ToolUse {
+			line += ", tool use"
+		}
+		switch {
+		case probe.TokensPerSecond > 0:
+			line += fmt.Sprintf(", %.1f tok/s", probe.TokensPerSecond)
+		case probe.BenchmarkError != "":
+			line += ", benchmark failed: " + probe.BenchmarkError
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	return NewTextResponse(strings.TrimRight(sb.String(), "\n")), nil
+}
@@ -0,0 +1,174 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/analysis"
+)
+
+// TodosParams represents parameters for the todos tool
+type TodosParams struct {
+	Filter string `json:"filter,omitempty"`
+}
+
+// todosTool lists the project's TODO, FIXME, HACK and XXX comments
+type todosTool struct {
+	workingDir string
+}
+
+// maxTodosShown caps how many comments one listing shows
+const maxTodosShown = 200
+
+const (
+	// TodosToolName is the name of this tool
+	TodosToolName = "todos"
+	// todosDescription describes what this tool does
+	todosDescription = `List the TODO, FIXME, HACK and XXX comments in the project with their file, line and owner.
+
+WHEN TO USE:
+- When the user asks about tech debt, known issues or unfinished work
+- Before changing code, to see what its authors left to do there
+
+FILTER (space-separated terms, all must match):
+- todo, fixme, hack or xxx: only that tag
+- @name: owners whose name contains name
+- a path or directory, e.g. internal/app/: files under it
+- any other word: comments containing it
+
+The owner is the name in TODO(name), else whoever last committed the line. The inventory is kept in .loco/knowledge/todos.json and rescanned when files change.`
+)
+
+// NewTodosTool creates a new todos tool
+func NewTodosTool(workingDir string) BaseTool {
+	return &todosTool{workingDir: workingDir}
+}
+
+// Name returns the tool name
+func (t *todosTool) Name() string {
+	return TodosToolName
+}
+
+// RunsInBackground reports that the tool runs off the UI loop: refreshing
+// runs git blame on every file with TODOs
+func (t *todosTool) RunsInBackground() bool {
+	return true
+}
+
+// Info returns the tool information
+func (t *todosTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        TodosToolName,
+		Description: todosDescription,
+		Parameters: map[string]any{
+			"filter": map[string]any{
+				"type":        "string",
+				"description": "Space-separated terms: a tag, @owner, a path, or words in the comment",
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "todos",
+				Description: "List TODO/FIXME/HACK comments, filtered by tag, @owner, path or text",
+				Examples:    []string{"/todos", "/todos fixme", "/todos @ana internal/app/", "/todos hack cache"},
+				Args:        []string{"filter"},
+			},
+		},
+	}
+}
+
+// Run rescans the project if anything changed and lists the matching
+// comments
+func (t *todosTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params TodosParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	inventory, _, err := analysis.RefreshTodos(ctx, t.workingDir)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to scan for TODOs: %s", err)), nil
+	}
+
+	terms := strings.Fields(params.Filter)
+	var matched []analysis.Todo
+	for _, todo := range inventory.Todos {
+		if todoMatches(todo, terms) {
+			matched = append(matched, todo)
+		}
+	}
+	if len(matched) == 0 {
+		if len(terms) > 0 {
+			return NewTextResponse(fmt.Sprintf("No TODOs match %q (%d in the project)", params.Filter, len(inventory.Todos))), nil
+		}
+		return NewTextResponse("No TODO, FIXME, HACK or XXX comments found"), nil
+	}
+
+	counts := map[string]int{}
+	for _, todo := range matched {
+		counts[todo.Tag]++
+	}
+	var tally []string
+	for _, tag := range []string{"TODO", "FIXME", "HACK", "XXX"} {
+		if counts[tag] > 0 {
+			tally = append(tally, fmt.Sprintf("%d %s", counts[tag], tag))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d comments (%s)", len(matched), strings.Join(tally, ", "))
+	if len(terms) > 0 {
+		fmt.Fprintf(&b, " matching %q, of %d", params.Filter, len(inventory.Todos))
+	}
+	b.WriteString("\n")
+	file := ""
+	for _, todo := range matched[:min(maxTodosShown, len(matched))] {
+		if todo.File != file {
+			file = todo.File
+			fmt.Fprintf(&b, "\n%s\n", file)
+		}
+		fmt.Fprintf(&b, "  %d: %s", todo.Line, todo.Tag)
+		if todo.Owner != "" {
+			fmt.Fprintf(&b, " (%s", todo.Owner)
+			if !todo.Committed.IsZero() {
+				fmt.Fprintf(&b, ", %s", todo.Committed.Format("2006-01-02"))
+			}
+			b.WriteString(")")
+		}
+		fmt.Fprintf(&b, " %s\n", todo.Text)
+	}
+	if len(matched) > maxTodosShown {
+		fmt.Fprintf(&b, "\n... and %d more; narrow the filter to see them\n", len(matched)-maxTodosShown)
+	}
+	return NewTextResponse(strings.TrimSpace(b.String())), nil
+}
+
+// todoMatches reports whether a comment matches every filter term
+func todoMatches(todo analysis.Todo, terms []string) bool {
+	for _, term := range terms {
+		lower := strings.ToLower(term)
+		switch {
+		case lower == "todo" || lower == "fixme" || lower == "hack" || lower == "xxx":
+			if todo.Tag != strings.ToUpper(term) {
+				return false
+			}
+		case strings.HasPrefix(term, "@"):
+			if !strings.Contains(strings.ToLower(todo.Owner), strings.TrimPrefix(lower, "@")) {
+				return false
+			}
+		case strings.Contains(term, "/") || strings.Contains(term, "."):
+			if todo.File != term && !strings.HasPrefix(todo.File, strings.TrimSuffix(term, "/")+"/") {
+				return false
+			}
+		default:
+			if !strings.Contains(strings.ToLower(todo.Text), lower) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/billie-coop/loco/internal/config"
 	"github.com/billie-coop/loco/internal/permission"
 )
 
@@ -24,12 +26,34 @@ type BaseTool interface {
 	Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 }
 
+// ArgumentCompleter is implemented by tools whose slash command arguments
+// take values that are only known at runtime, such as file names.
+type ArgumentCompleter interface {
+	// CompleteArgument returns the values param can take
+	CompleteArgument(param string) []string
+}
+
+// BackgroundRunner is implemented by tools whose calls can take a while,
+// waiting on a permission prompt, a model, a language server or a slow
+// command. The executor runs them off the UI loop so the UI keeps drawing.
+type BackgroundRunner interface {
+	// RunsInBackground reports whether calls must run off the UI loop
+	RunsInBackground() bool
+}
+
+// RunsInBackground reports whether a tool's calls must run off the UI loop
+func RunsInBackground(tool BaseTool) bool {
+	runner, ok := tool.(BackgroundRunner)
+	return ok && runner.RunsInBackground()
+}
+
 // CommandInfo represents a slash command declaration for a tool.
 type CommandInfo struct {
 	Command     string   `json:"command"`     // The slash command name (e.g., "help", "analyze")
 	Aliases     []string `json:"aliases"`     // Alternative command names (e.g., ["h"] for help)
 	Description string   `json:"description"` // Description shown in completion popup
 	Examples    []string `json:"examples"`    // Usage examples for help text
+	Args        []string `json:"args,omitempty"` // Parameters filled by positional arguments, in order (default: Required)
 }
 
 // ToolInfo represents OpenAI-compatible tool information with command declarations.
@@ -152,6 +176,21 @@ func ConvertToOpenAIFormat(tool BaseTool) map[string]any {
 // Registry manages available tools.
 type Registry struct {
 	tools map[string]BaseTool
+
+	// Used by transactions to resolve paths and ask for permission
+	permissions permission.Service
+	workingDir  string
+
+	// Pre-edit snapshots of every tool-applied write, for /undo
+	checkpoints *CheckpointStore
+
+	// Where the paths no tool may touch are configured
+	guardrails *config.Manager
+}
+
+// Checkpoints returns the store of pre-edit snapshots
+func (r *Registry) Checkpoints() *CheckpointStore {
+	return r.checkpoints
 }
 
 // NewRegistry creates a new tool registry.
@@ -166,13 +205,13 @@ func (r *Registry) Register(tool BaseTool) error {
 	if _, exists := r.tools[tool.Name()]; exists {
 		return fmt.Errorf("tool %s already registered", tool.Name())
 	}
-	r.tools[tool.Name()] = tool
+	r.tools[tool.Name()] = r.guard(tool)
 	return nil
 }
 
 // Replace swaps in a tool implementation, overwriting any existing tool of the same name.
 func (r *Registry) Replace(tool BaseTool) {
-	r.tools[tool.Name()] = tool
+	r.tools[tool.Name()] = r.guard(tool)
 }
 
 // Get retrieves a tool by name.
@@ -190,6 +229,48 @@ func (r *Registry) GetAll() []BaseTool {
 	return tools
 }
 
+// ParamSchema returns the JSON schema properties and required params for a tool.
+// It lets the parser validate model-produced params before they reach the tool.
+func (r *Registry) ParamSchema(toolName string) (map[string]any, []string, bool) {
+	tool, exists := r.tools[toolName]
+	if !exists {
+		return nil, nil, false
+	}
+	info := tool.Info()
+	return schemaProperties(info), schemaRequired(info), true
+}
+
+// schemaProperties normalizes the two shapes tools use for Parameters:
+// a full object schema with "properties", or a bare properties map.
+func schemaProperties(info ToolInfo) map[string]any {
+	if properties, ok := info.Parameters["properties"].(map[string]any); ok {
+		return properties
+	}
+	if info.Parameters["type"] == "object" {
+		return map[string]any{}
+	}
+	return info.Parameters
+}
+
+// schemaRequired merges Required with any "required" list in the object schema.
+func schemaRequired(info ToolInfo) []string {
+	required := append([]string{}, info.Required...)
+	nested, _ := info.Parameters["required"].([]string)
+	for _, name := range nested {
+		found := false
+		for _, existing := range required {
+			if existing == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			required = append(required, name)
+		}
+	}
+	return required
+}
+
 // GetOpenAITools returns all tools in OpenAI format.
 func (r *Registry) GetOpenAITools() []map[string]any {
 	tools := make([]map[string]any, 0, len(r.tools))
@@ -302,7 +383,7 @@ func (r *Registry) ParseCommand(command string) (*ToolCall, error) {
 	}
 	
 	// Parse arguments based on parameter schema
-	params, err := r.parseArguments(tool.Info(), args)
+	params, err := r.parseArguments(tool.Info(), commandName, args)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing arguments: %v", err)
 	}
@@ -322,12 +403,12 @@ func (r *Registry) ParseCommand(command string) (*ToolCall, error) {
 
 // parseArguments parses command line arguments into a parameter map
 // based on the tool's parameter schema.
-func (r *Registry) parseArguments(info ToolInfo, args []string) (map[string]any, error) {
+func (r *Registry) parseArguments(info ToolInfo, commandName string, args []string) (map[string]any, error) {
 	params := make(map[string]any)
 	
 	// Get parameter properties from schema
-	properties, ok := info.Parameters["properties"].(map[string]any)
-	if !ok || properties == nil {
+	properties := schemaProperties(info)
+	if len(properties) == 0 {
 		// No parameters expected, but args provided
 		if len(args) > 0 {
 			return nil, fmt.Errorf("command does not accept arguments")
@@ -337,7 +418,7 @@ func (r *Registry) parseArguments(info ToolInfo, args []string) (map[string]any,
 	
 	// Simple positional argument parsing
 	// For now, we'll map arguments to required parameters in order
-	requiredParams := info.Required
+	requiredParams := positionalParams(info, commandName, properties)
 	
 	// Map positional arguments to required parameters
 	for i, arg := range args {
@@ -354,6 +435,14 @@ func (r *Registry) parseArguments(info ToolInfo, args []string) (map[string]any,
 		
 		// Type conversion based on parameter schema
 		paramType, _ := paramDef["type"].(string)
+
+		// The last required string param takes the rest of the line,
+		// so "/bash go test ./..." keeps the whole command
+		if i == len(requiredParams)-1 && (paramType == "string" || paramType == "") {
+			params[paramName] = strings.Join(args[i:], " ")
+			break
+		}
+
 		switch paramType {
 		case "integer", "number":
 			if val, err := strconv.Atoi(arg); err == nil {
@@ -386,6 +475,75 @@ func (r *Registry) parseArguments(info ToolInfo, args []string) (map[string]any,
 	return params, nil
 }
 
+// positionalParams returns the parameters a command's arguments fill, in
+// order: the command's Args, else the tool's required parameters.
+func positionalParams(info ToolInfo, commandName string, properties map[string]any) []string {
+	params := info.Required
+	for _, cmd := range info.Commands {
+		if len(cmd.Args) > 0 && (cmd.Command == commandName || slices.Contains(cmd.Aliases, commandName)) {
+			params = cmd.Args
+		}
+	}
+
+	// A tool with a single optional parameter takes it positionally,
+	// so "/undo 3" fills count
+	if len(params) == 0 && len(properties) == 1 {
+		for name := range properties {
+			params = []string{name}
+		}
+	}
+	return params
+}
+
+// CompleteArgument returns completions for the argument at index of a slash
+// command, given without its slash: the values from the parameter's enum,
+// true and false for a boolean, or what the tool offers at runtime.
+func (r *Registry) CompleteArgument(commandName string, index int) []CompletionCommand {
+	toolName, exists := r.GetCommandRegistry()[commandName]
+	if !exists {
+		return nil
+	}
+	tool, exists := r.Get(toolName)
+	if !exists {
+		return nil
+	}
+
+	info := tool.Info()
+	properties := schemaProperties(info)
+	params := positionalParams(info, commandName, properties)
+	if index < 0 || index >= len(params) {
+		return nil
+	}
+	param := params[index]
+	paramDef, _ := properties[param].(map[string]any)
+	description, _ := paramDef["description"].(string)
+
+	var values []string
+	switch enum := paramDef["enum"].(type) {
+	case []string:
+		values = enum
+	case []any:
+		for _, v := range enum {
+			values = append(values, fmt.Sprint(v))
+		}
+	}
+	if len(values) == 0 && paramDef["type"] == "boolean" {
+		values = []string{"true", "false"}
+	}
+	if completer, ok := tool.(ArgumentCompleter); ok {
+		values = append(values, completer.CompleteArgument(param)...)
+	}
+
+	completions := make([]CompletionCommand, 0, len(values))
+	for _, value := range values {
+		completions = append(completions, CompletionCommand{
+			Name:        value,
+			Description: description,
+		})
+	}
+	return completions
+}
+
 // generateToolCallID creates a unique ID for tool calls
 func generateToolCallID() string {
 	return fmt.Sprintf("cmd_%d", time.Now().UnixNano())
@@ -394,6 +552,9 @@ func generateToolCallID() string {
 // CreateDefaultRegistry creates a registry with all default tools.
 func CreateDefaultRegistry(permissionService permission.Service, workingDir string, analysisService interface{}) *Registry {
 	registry := NewRegistry()
+	registry.permissions = permissionService
+	registry.workingDir = workingDir
+	registry.checkpoints = NewCheckpointStore(workingDir)
 
 	// Only register the tools we actually use
 	// Main tools are registered in app.go
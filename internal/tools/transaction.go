@@ -0,0 +1,308 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/permission"
+)
+
+// FileChange is one staged file write within a transaction
+type FileChange struct {
+	Path         string // Project-relative path
+	Content      string // New content
+	Original     string // Content before the transaction ("" for new files)
+	Existed      bool   // Whether the file existed before the transaction
+	LinesAdded   int
+	LinesRemoved int
+
+	absPath string
+	mode    os.FileMode
+}
+
+// Transaction stages writes and edits across several files so they either
+// all apply or none do. Edits see the content staged by earlier operations.
+type Transaction struct {
+	permissions permission.Service
+	workingDir  string
+	checkpoints *CheckpointStore
+	protected   func(path string) (string, string, bool) // Reports protected paths; nil protects none
+
+	mu      sync.Mutex
+	changes map[string]*FileChange
+	order   []string
+	done    bool
+}
+
+// BeginTransaction starts a new multi-file transaction rooted at the
+// registry's working directory.
+func (r *Registry) BeginTransaction() *Transaction {
+	tx := NewTransaction(r.workingDir, r.permissions)
+	tx.checkpoints = r.checkpoints
+	tx.protected = r.Protected
+	return tx
+}
+
+// NewTransaction creates a transaction for files under workingDir
+func NewTransaction(workingDir string, permissions permission.Service) *Transaction {
+	return &Transaction{
+		permissions: permissions,
+		workingDir:  workingDir,
+		changes:     make(map[string]*FileChange),
+	}
+}
+
+// Write stages the full content of a file
+func (tx *Transaction) Write(path, content string) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	change, err := tx.stage(path)
+	if err != nil {
+		return err
+	}
+	change.Content = content
+	return nil
+}
+
+// Edit stages search/replace edits against the file's staged content
+func (tx *Transaction) Edit(path string, edits []EditBlock) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	change, err := tx.stage(path)
+	if err != nil {
+		return err
+	}
+	if !change.Existed && change.Content == "" {
+		return fmt.Errorf("%s does not exist; search/replace edits need an existing file", change.Path)
+	}
+	updated, err := applyEdits(change.Content, edits)
+	if err != nil {
+		return fmt.Errorf("%s: %w", change.Path, err)
+	}
+	change.Content = updated
+	return nil
+}
+
+// Patch stages a unified diff or SEARCH/REPLACE blocks against the staged content
+func (tx *Transaction) Patch(path, diff string) error {
+	if isSearchReplaceBlocks(diff) {
+		edits, err := parseSearchReplaceBlocks(diff)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return tx.Edit(path, edits)
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	change, err := tx.stage(path)
+	if err != nil {
+		return err
+	}
+	hunks, err := parseUnifiedDiff(diff)
+	if err != nil {
+		return fmt.Errorf("%s: %w", change.Path, err)
+	}
+	updated, err := applyUnifiedDiff(change.Content, hunks)
+	if err != nil {
+		return fmt.Errorf("%s: %w", change.Path, err)
+	}
+	change.Content = updated
+	return nil
+}
+
+// Changes returns the staged changes that actually modify files, in the
+// order their paths were first touched.
+func (tx *Transaction) Changes() []FileChange {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	var out []FileChange
+	for _, rel := range tx.order {
+		change := tx.changes[rel]
+		if change.Existed && change.Content == change.Original {
+			continue
+		}
+		c := *change
+		c.LinesAdded, c.LinesRemoved = countLineChanges(c.Original, c.Content)
+		out = append(out, c)
+	}
+	return out
+}
+
+// Commit asks once for permission to write every staged file, then applies
+// them. If any write fails, files already written are restored.
+func (tx *Transaction) Commit(ctx context.Context, toolName string) ([]FileChange, error) {
+	changes := tx.Changes()
+
+	tx.mu.Lock()
+	if tx.done {
+		tx.mu.Unlock()
+		return nil, fmt.Errorf("transaction already finished")
+	}
+	tx.done = true
+	tx.mu.Unlock()
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	if tx.permissions != nil {
+		sessionID, messageID := GetContextValues(ctx)
+		granted := tx.permissions.Request(ctx, permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			ToolCallID:  messageID,
+			ToolName:    toolName,
+			Action:      "write",
+			Path:        changedPaths(changes),
+			Description: describeChanges(changes),
+			Diff:        diffChanges(changes),
+		})
+		if !granted {
+			return nil, permission.ErrorPermissionDenied
+		}
+	}
+
+	applied := make([]FileChange, 0, len(changes))
+	for _, change := range changes {
+		if err := ctx.Err(); err != nil {
+			tx.restore(applied)
+			return nil, err
+		}
+		if err := writeFileAtomic(change.absPath, []byte(change.Content), change.mode); err != nil {
+			if restoreErr := tx.restore(applied); restoreErr != nil {
+				return nil, fmt.Errorf("writing %s failed (%v) and rollback failed: %w", change.Path, err, restoreErr)
+			}
+			return nil, fmt.Errorf("writing %s failed, all changes rolled back: %w", change.Path, err)
+		}
+		applied = append(applied, change)
+	}
+
+	// The files are written; a failed snapshot only costs the ability to undo
+	_ = tx.checkpoints.Record(toolName, changedPaths(applied), applied)
+
+	return applied, nil
+}
+
+// Rollback discards everything staged so far
+func (tx *Transaction) Rollback() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.changes = make(map[string]*FileChange)
+	tx.order = nil
+	tx.done = true
+}
+
+// stage returns the change for a path, loading the file on first touch
+func (tx *Transaction) stage(path string) (*FileChange, error) {
+	if tx.done {
+		return nil, fmt.Errorf("transaction already finished")
+	}
+
+	absPath, rel, err := resolveProjectPath(tx.workingDir, path)
+	if err != nil {
+		return nil, err
+	}
+	if tx.protected != nil {
+		if _, glob, ok := tx.protected(rel); ok {
+			return nil, fmt.Errorf("%s is protected (%q in guardrails.protected_paths)", rel, glob)
+		}
+	}
+	if change, ok := tx.changes[rel]; ok {
+		return change, nil
+	}
+
+	change := &FileChange{Path: rel, absPath: absPath, mode: 0o644}
+	info, err := os.Stat(absPath)
+	switch {
+	case err == nil && info.IsDir():
+		return nil, fmt.Errorf("%s is a directory", rel)
+	case err == nil:
+		data, readErr := os.ReadFile(absPath)
+		if readErr != nil {
+			return nil, fmt.Errorf("could not read %s: %w", rel, readErr)
+		}
+		change.Existed = true
+		change.Original = string(data)
+		change.Content = change.Original
+		change.mode = info.Mode().Perm()
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("could not stat %s: %w", rel, err)
+	}
+
+	tx.changes[rel] = change
+	tx.order = append(tx.order, rel)
+	return change, nil
+}
+
+// restore puts applied files back the way they were, newest first
+func (tx *Transaction) restore(applied []FileChange) error {
+	var failed []string
+	for i := len(applied) - 1; i >= 0; i-- {
+		change := applied[i]
+		var err error
+		if change.Existed {
+			err = writeFileAtomic(change.absPath, []byte(change.Original), change.mode)
+		} else {
+			err = os.Remove(change.absPath)
+		}
+		if err != nil {
+			failed = append(failed, change.Path)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("could not restore %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// changedPaths joins the sorted paths for the permission prompt
+func changedPaths(changes []FileChange) string {
+	paths := make([]string, 0, len(changes))
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	sort.Strings(paths)
+	return strings.Join(paths, ", ")
+}
+
+// describeChanges summarizes every file in one line each
+func describeChanges(changes []FileChange) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Write %d files:", len(changes)))
+	for _, change := range changes {
+		verb := "edit"
+		if !change.Existed {
+			verb = "create"
+		}
+		sb.WriteString(fmt.Sprintf("\n  %s %s (+%d -%d)", verb, change.Path, change.LinesAdded, change.LinesRemoved))
+	}
+	return sb.String()
+}
+
+// diffChanges renders every staged file as one unified diff
+func diffChanges(changes []FileChange) string {
+	var sb strings.Builder
+	for _, change := range changes {
+		sb.WriteString(fileDiff(change.Path, change.Existed, change.Original, change.Content))
+	}
+	return sb.String()
+}
+
+// fileDiff renders the change to one file as a unified diff, from /dev/null
+// when the file is new
+func fileDiff(path string, existed bool, before, after string) string {
+	oldName := "a/" + path
+	if !existed {
+		oldName = "/dev/null"
+	}
+	return analysis.UnifiedDiff(oldName, "b/"+path, before, after)
+}
@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTransactionCommitRollsBackFailedWrites(t *testing.T) {
+	tests := []struct {
+		name    string
+		writes  []string // Staged in order; each gets "new <path>" as content
+		blocked string   // Turned into a non-empty directory after staging, so writing it fails
+		wantErr string
+	}{
+		{
+			name:    "last_write_fails",
+			writes:  []string{"a.txt", "b.txt", "c.txt"},
+			blocked: "c.txt",
+			wantErr: "writing c.txt failed, all changes rolled back",
+		},
+		{
+			name:    "middle_write_fails",
+			writes:  []string{"a.txt", "c.txt", "b.txt"},
+			blocked: "c.txt",
+			wantErr: "writing c.txt failed, all changes rolled back",
+		},
+		{
+			name:    "first_write_fails",
+			writes:  []string{"c.txt", "a.txt"},
+			blocked: "c.txt",
+			wantErr: "writing c.txt failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			// a.txt exists before the transaction; b.txt and c.txt are new
+			if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("old a\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			tx := NewTransaction(root, nil)
+			for _, path := range tt.writes {
+				if err := tx.Write(path, "new "+path+"\n"); err != nil {
+					t.Fatalf("stage %s: %v", path, err)
+				}
+			}
+			if err := os.MkdirAll(filepath.Join(root, tt.blocked, "keep"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			applied, err := tx.Commit(context.Background(), "test")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+			if applied != nil {
+				t.Errorf("applied = %v, want none", applied)
+			}
+
+			if data, _ := os.ReadFile(filepath.Join(root, "a.txt")); string(data) != "old a\n" {
+				t.Errorf("a.txt = %q, want it restored", data)
+			}
+			if _, err := os.Stat(filepath.Join(root, "b.txt")); !os.IsNotExist(err) {
+				t.Errorf("b.txt: want it removed, stat error %v", err)
+			}
+			entries, _ := os.ReadDir(root)
+			for _, entry := range entries {
+				if strings.Contains(entry.Name(), ".loco-") {
+					t.Errorf("temporary file %s left behind", entry.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestTransactionCommit(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := NewTransaction(root, nil)
+	if err := tx.Edit("a.txt", []EditBlock{{OldText: "two", NewText: "2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Write("dir/b.txt", "b\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Write("same.txt", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := tx.Commit(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 3 || applied[0].Path != "a.txt" || applied[0].LinesAdded != 1 || applied[0].LinesRemoved != 1 {
+		t.Errorf("applied = %+v", applied)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "a.txt")); string(data) != "one\n2\n" {
+		t.Errorf("a.txt = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "dir", "b.txt")); string(data) != "b\n" {
+		t.Errorf("dir/b.txt = %q", data)
+	}
+
+	if _, err := tx.Commit(context.Background(), "test"); err == nil {
+		t.Error("second commit: want an error")
+	}
+	if err := tx.Write("c.txt", "c"); err == nil {
+		t.Error("write after commit: want an error")
+	}
+}
+
+func TestTransactionCommitCanceled(t *testing.T) {
+	root := t.TempDir()
+	tx := NewTransaction(root, nil)
+	if err := tx.Write("a.txt", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := tx.Commit(ctx, "test"); err != context.Canceled {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a.txt")); !os.IsNotExist(err) {
+		t.Errorf("a.txt written despite the canceled context: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UndoParams represents parameters for the undo tool
+type UndoParams struct {
+	Count int `json:"count,omitempty"` // Number of checkpoints to revert (default 1)
+}
+
+// undoTool reverts tool-applied file changes
+type undoTool struct {
+	checkpoints *CheckpointStore
+}
+
+// checkpointsTool lists the checkpoints /undo can revert
+type checkpointsTool struct {
+	checkpoints *CheckpointStore
+}
+
+const (
+	// UndoToolName is the name of this tool
+	UndoToolName = "undo"
+	// undoDescription describes what this tool does
+	undoDescription = `Revert the most recent file changes made by tools.
+
+WHAT THIS DOES:
+- Restores files to their content before the last N edit_file/multi_edit runs
+- Deletes files those runs created
+- Refuses to revert a change whose files were modified afterwards
+
+SAFETY:
+- Only runs when the user asks for it with /undo; calls from a model are refused
+
+OUTPUT:
+- The checkpoints that were reverted`
+
+	// CheckpointsToolName is the name of this tool
+	CheckpointsToolName = "checkpoints"
+	// checkpointsDescription describes what this tool does
+	checkpointsDescription = `List the tool-applied file changes that /undo can revert, newest first.
+
+OUTPUT:
+- One line per checkpoint with its tool, files and age
+- Checkpoints whose files changed afterwards are marked as not revertible`
+)
+
+// NewUndoTool creates a new undo tool
+func NewUndoTool(checkpoints *CheckpointStore) BaseTool {
+	return &undoTool{checkpoints: checkpoints}
+}
+
+// Name returns the tool name
+func (t *undoTool) Name() string {
+	return UndoToolName
+}
+
+// Info returns the tool information
+func (t *undoTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        UndoToolName,
+		Description: undoDescription,
+		Parameters: map[string]any{
+			"count": map[string]any{
+				"type":        "integer",
+				"description": "Number of changes to revert (default: 1)",
+				"minimum":     1,
+			},
+		},
+		Commands: []CommandInfo{
+			{
+				Command:     "undo",
+				Description: "Revert the last tool-applied file changes",
+				Examples:    []string{"/undo", "/undo 3"},
+			},
+		},
+	}
+}
+
+// Run reverts the requested number of checkpoints
+func (t *undoTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params UndoParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	if params.Count <= 0 {
+		params.Count = 1
+	}
+	if t.checkpoints == nil {
+		return NewTextErrorResponse("undo is not available"), nil
+	}
+	// Reverting would let a model silently throw away edits the user accepted
+	if initiator, _ := ctx.Value(InitiatorKey).(string); initiator != "user" {
+		return NewTextErrorResponse("undo only runs when the user asks for it with /undo"), nil
+	}
+
+	undone, err := t.checkpoints.Undo(params.Count)
+
+	var sb strings.Builder
+	for _, cp := range undone {
+		sb.WriteString(fmt.Sprintf("↩ Reverted %s: %s\n", cp.Tool, cp.Summary))
+	}
+	if err != nil {
+		if len(undone) > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("Stopped: %s", err))
+		return WithResponseMetadata(NewTextErrorResponse(sb.String()), map[string]any{"undone": len(undone)}), nil
+	}
+
+	return WithResponseMetadata(NewTextResponse(strings.TrimSuffix(sb.String(), "\n")), map[string]any{"undone": len(undone)}), nil
+}
+
+// NewCheckpointsTool creates a new checkpoints tool
+func NewCheckpointsTool(checkpoints *CheckpointStore) BaseTool {
+	return &checkpointsTool{checkpoints: checkpoints}
+}
+
+// Name returns the tool name
+func (t *checkpointsTool) Name() string {
+	return CheckpointsToolName
+}
+
+// Info returns the tool information
+func (t *checkpointsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        CheckpointsToolName,
+		Description: checkpointsDescription,
+		Parameters:  map[string]any{},
+		Commands: []CommandInfo{
+			{
+				Command:     "checkpoints",
+				Aliases:     []string{"undo-list"},
+				Description: "List changes that /undo can revert",
+				Examples:    []string{"/checkpoints"},
+			},
+		},
+	}
+}
+
+// Run lists the checkpoints
+func (t *checkpointsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	if t.checkpoints == nil {
+		return NewTextErrorResponse("undo is not available"), nil
+	}
+
+	list := t.checkpoints.Statuses()
+	if len(list) == 0 {
+		return NewTextResponse("No tool-applied changes to undo"), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d checkpoints (newest first, /undo N reverts the top N):\n\n", len(list)))
+	blocked := false
+	for i, cp := range list {
+		status := "✓"
+		note := ""
+		if len(cp.Modified) > 0 {
+			blocked = true
+			status = "✗"
+			note = fmt.Sprintf(" (modified since: %s)", strings.Join(cp.Modified, ", "))
+		} else if blocked {
+			status = "·"
+			note = " (behind a modified checkpoint)"
+		}
+		sb.WriteString(fmt.Sprintf("%s %2d. %s %s, %s ago%s\n", status, i+1, cp.Tool, cp.Summary, formatAge(time.Since(cp.Time)), note))
+	}
+
+	return NewTextResponse(strings.TrimSuffix(sb.String(), "\n")), nil
+}
+
+// formatAge renders a duration as a short relative age
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"slices"
+
+	"github.com/billie-coop/loco/internal/tui/components/status"
+)
+
+// analysisTiers are the analysis phases shown as running in the status bar,
+// in the order they run
+var analysisTiers = []string{"quick", "detailed", "deep", "full"}
+
+// setTierRunning marks an analysis tier as running or finished in the
+// status bar
+func (m *Model) setTierRunning(tier string, running bool) {
+	if !slices.Contains(analysisTiers, tier) {
+		return
+	}
+	tiers := slices.DeleteFunc(m.activity.Tiers, func(t string) bool { return t == tier })
+	if running {
+		tiers = append(tiers, tier)
+		slices.SortFunc(tiers, func(a, b string) int {
+			return slices.Index(analysisTiers, a) - slices.Index(analysisTiers, b)
+		})
+	}
+	m.activity.Tiers = tiers
+	m.statusBar.SetActivity(m.activity)
+}
+
+// setModelState records a model's state in LM Studio
+func (m *Model) setModelState(modelID string, state status.ModelState) {
+	if m.modelStates == nil {
+		m.modelStates = make(map[string]status.ModelState)
+	}
+	m.modelStates[modelID] = state
+	m.showModelState()
+}
+
+// showModelState shows a model being loaded in the status bar, otherwise
+// the chat model
+func (m *Model) showModelState() {
+	m.activity.Model, m.activity.ModelState = "", status.ModelUnknown
+	if m.app.LLMService != nil {
+		m.activity.Model = m.app.LLMService.ChatModel()
+		m.activity.ModelState = m.modelStates[m.activity.Model]
+	}
+	for id, s := range m.modelStates {
+		if s == status.ModelLoading && (m.activity.ModelState != status.ModelLoading || id < m.activity.Model) {
+			m.activity.Model, m.activity.ModelState = id, s
+		}
+	}
+	m.statusBar.SetActivity(m.activity)
+}
@@ -0,0 +1,29 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+// attachPastedImage attaches an image whose path was pasted or dropped into
+// the terminal, returning the @-mention to put in the draft. Anything else
+// is pasted as text.
+func (m *Model) attachPastedImage(text string) (string, bool) {
+	path := strings.TrimSpace(text)
+	if path == "" || strings.Contains(path, "\n") || !llm.IsImage(strings.Trim(path, `'"`)) {
+		return "", false
+	}
+	attached, err := m.app.AttachImage(path)
+	if err != nil {
+		m.showStatus("⚠️ " + err.Error())
+		return "", false
+	}
+	m.showStatus("🖼️ Attached " + attached)
+
+	mention := "@" + attached + " "
+	if value := m.input.Value(); value != "" && !strings.HasSuffix(value, " ") && !strings.HasSuffix(value, "\n") {
+		mention = " " + mention
+	}
+	return mention, true
+}
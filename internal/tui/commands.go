@@ -3,8 +3,10 @@ package tui
 import (
 	"strings"
 
+	"github.com/billie-coop/loco/internal/clipboard"
 	"github.com/billie-coop/loco/internal/llm"
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // handleSendMessage processes sending a message to the LLM
@@ -15,6 +17,12 @@ func (m *Model) handleSendMessage(content string) tea.Cmd {
 		return nil
 	}
 
+	// /debug export zips diagnostics for a bug report
+	if fields := strings.Fields(content); fields[0] == "/debug" && len(fields) > 1 && fields[1] == "export" {
+		m.exportDebugBundle(fields[2:])
+		return nil
+	}
+
 	// Special case: /debug is UI-specific, handle locally
 	if content == "/debug" {
 		m.debugMode = !m.debugMode
@@ -27,6 +35,13 @@ func (m *Model) handleSendMessage(content string) tea.Cmd {
 		return nil
 	}
 
+	// /export renders what's on screen, tool blocks included, so it is
+	// handled here rather than as a tool
+	if fields := strings.Fields(content); fields[0] == "/export" {
+		m.exportConversation(fields[1:])
+		return nil
+	}
+
 	// Route all input through the unified InputRouter
 	if m.app.InputRouter != nil {
 		m.app.InputRouter.Route(content)
@@ -37,6 +52,19 @@ func (m *Model) handleSendMessage(content string) tea.Cmd {
 	return nil
 }
 
+// copyScreen copies what the screen shows, as plain text, to the clipboard
+func (m *Model) copyScreen() {
+	lines := strings.Split(ansi.Strip(m.View()), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	if err := clipboard.Copy(strings.TrimSpace(strings.Join(lines, "\n"))); err != nil {
+		m.showStatus("⚠️ Failed to copy screen: " + err.Error())
+		return
+	}
+	m.showStatus("📋 Copied screen to clipboard")
+}
+
 // handleCommand processes slash commands
 func (m *Model) handleCommand(input string) tea.Cmd {
 	parts := strings.Fields(input)
@@ -111,13 +139,20 @@ func (m *Model) getHelpText() string {
 /help          - Show this help message
 /clear         - Clear all messages
 /model [name]  - Set or show current model
+/export md|html [path] - Save the conversation as a document
 /session       - Show session info
 /debug         - Toggle debug mode
+/debug export [path] - Zip debug dumps, config and versions for a bug report
 /quit          - Exit Loco
 
 Keyboard Shortcuts:
 Ctrl+L         - Clear messages
-Ctrl+P         - Open command palette
+Ctrl+K         - Open command palette
+Ctrl+B         - Browse knowledge files
+Ctrl+E         - Write the message in $EDITOR
+Alt+E          - Edit and resend last message
+Ctrl+R         - Regenerate last reply
+Alt+R          - Regenerate with the team's next model
 Ctrl+C         - Quit
-Tab            - Trigger completions`
+Tab            - Complete commands and their arguments`
 }
\ No newline at end of file
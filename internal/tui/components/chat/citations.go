@@ -0,0 +1,76 @@
+package chat
+
+import (
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/tui/styles"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// pathWord matches the words of rendered text that may be file references
+var pathWord = regexp.MustCompile(`[\w./-]+`)
+
+// linkCitations makes the file references of a rendered message that name
+// project files clickable, as terminal hyperlinks to the files
+func linkCitations(rendered string, citations []llm.Citation) string {
+	links := make(map[string]string)
+	for _, citation := range citations {
+		if citation.Path == "" || citation.Status == llm.CitationMissing {
+			continue
+		}
+		text := citation.Text
+		if citation.Status == llm.CitationCorrected {
+			text = citation.Path // The message now reads the corrected path
+		}
+		abs, err := filepath.Abs(filepath.FromSlash(citation.Path))
+		if err != nil {
+			continue
+		}
+		links[text] = (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+	}
+	if len(links) == 0 {
+		return rendered
+	}
+	return pathWord.ReplaceAllStringFunc(rendered, func(word string) string {
+		trimmed := strings.TrimRight(word, ".")
+		link, ok := links[trimmed]
+		if !ok {
+			return word
+		}
+		return ansi.SetHyperlink(link) + trimmed + ansi.ResetHyperlink() + word[len(trimmed):]
+	})
+}
+
+// renderCitations notes the file references of a message that named no
+// project file: those still missing, and those corrected to the file meant
+func renderCitations(citations []llm.Citation, width int) string {
+	var missing, corrected []string
+	for _, citation := range citations {
+		switch citation.Status {
+		case llm.CitationMissing:
+			line := "- " + citation.Text
+			if citation.Suggestion != "" {
+				line += " (did you mean " + citation.Suggestion + "?)"
+			}
+			missing = append(missing, line)
+		case llm.CitationCorrected:
+			corrected = append(corrected, "- "+citation.Text+" → "+citation.Path)
+		}
+	}
+
+	theme := styles.CurrentTheme()
+	var blocks []string
+	if len(missing) > 0 {
+		text := "⚠ Not in the project:\n" + strings.Join(missing, "\n")
+		blocks = append(blocks, theme.S().Warning.Render(wrapText(text, width)))
+	}
+	if len(corrected) > 0 {
+		text := "✎ Corrected file references:\n" + strings.Join(corrected, "\n")
+		blocks = append(blocks, theme.S().Muted.Render(wrapText(text, width)))
+	}
+	return strings.Join(blocks, "\n\n")
+}
@@ -170,11 +170,15 @@ func (c *CompletionsModel) View() string {
 		}
 		
 		// Format: "/command - description"
+		label := cmd.Name
+		if cmd.Description != "" {
+			label += " - " + cmd.Description
+		}
 		item := itemStyle.
 			Width(c.width - 2).
 			PaddingLeft(1).
 			PaddingRight(1).
-			Render(cmd.Name + " - " + cmd.Description)
+			Render(label)
 		
 		items = append(items, item)
 	}
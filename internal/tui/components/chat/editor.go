@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// EditorFinishedMsg is sent when the external editor opened on the draft
+// exits. The input takes Value as its draft unless Err is set.
+type EditorFinishedMsg struct {
+	Value string
+	Err   error
+}
+
+// editorCommand returns the user's editor with its arguments, from $VISUAL
+// or $EDITOR, falling back to vi
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// OpenEditor hands the draft to the user's editor, suspending the TUI until
+// it exits, and reads the saved file back
+func (im *InputModel) OpenEditor() tea.Cmd {
+	file, err := os.CreateTemp("", "loco-draft-*.md")
+	if err != nil {
+		return editorFailed(err)
+	}
+	path := file.Name()
+	_, err = file.WriteString(im.value)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return editorFailed(err)
+	}
+
+	args := append(editorCommand(), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return EditorFinishedMsg{Err: fmt.Errorf("%s: %w", args[0], err)}
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return EditorFinishedMsg{Err: err}
+		}
+		// Editors end the file with a newline the draft never had
+		return EditorFinishedMsg{Value: strings.TrimRight(string(content), "\r\n")}
+	})
+}
+
+// editorFailed reports an editor that could not be started
+func editorFailed(err error) tea.Cmd {
+	return func() tea.Msg {
+		return EditorFinishedMsg{Err: err}
+	}
+}
@@ -0,0 +1,73 @@
+package chat
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/tui/styles"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// renderExplanation shows an /explain answer as a card: the region as a
+// title, the summary, the steps, the code around it and the notes
+func renderExplanation(e *llm.Explanation, width int) string {
+	theme := styles.CurrentTheme()
+	region := fmt.Sprintf("%s:%d-%d", e.Path, e.StartLine, e.EndLine)
+	blocks := []string{
+		theme.S().Title.Render("🔎 " + fileLink(e.Path, region)),
+		theme.S().Text.Render(wrapText(e.Summary, width)),
+	}
+
+	if len(e.Steps) > 0 {
+		lines := make([]string, 0, len(e.Steps))
+		for i, step := range e.Steps {
+			lines = append(lines, fmt.Sprintf("%d. %s", i+1, step))
+		}
+		blocks = append(blocks, theme.S().Subtitle.Render("Step by step")+"\n"+theme.S().Text.Render(wrapText(strings.Join(lines, "\n"), width)))
+	}
+
+	for _, group := range []struct {
+		title string
+		refs  []llm.CodeReference
+	}{
+		{"Called by", e.Callers},
+		{"Calls", e.Callees},
+	} {
+		if len(group.refs) == 0 {
+			continue
+		}
+		lines := []string{theme.S().Subtitle.Render(group.title)}
+		for _, ref := range group.refs {
+			line := "- " + fileLink(ref.Path, ref.Location())
+			if ref.Symbol != "" {
+				line += " " + theme.S().Muted.Render(ref.Symbol)
+			}
+			lines = append(lines, line)
+			if ref.Note != "" {
+				lines = append(lines, theme.S().Muted.Render(wrapText("  "+ref.Note, width)))
+			}
+		}
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+
+	if len(e.Notes) > 0 {
+		lines := make([]string, 0, len(e.Notes))
+		for _, note := range e.Notes {
+			lines = append(lines, "- "+note)
+		}
+		blocks = append(blocks, theme.S().Subtitle.Render("Notes")+"\n"+theme.S().Warning.Render(wrapText(strings.Join(lines, "\n"), width)))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// fileLink shows text as a terminal hyperlink to a project file
+func fileLink(path, text string) string {
+	abs, err := filepath.Abs(filepath.FromSlash(path))
+	if err != nil {
+		return text
+	}
+	return ansi.SetHyperlink((&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()) + text + ansi.ResetHyperlink()
+}
@@ -9,8 +9,15 @@ import (
 	"github.com/billie-coop/loco/internal/tui/styles"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/sahilm/fuzzy"
 )
 
+// maxMentionCompletions caps the files offered for an @-mention
+const maxMentionCompletions = 6
+
+// MaxInputLines caps how tall the input grows for a multi-line draft
+const MaxInputLines = 8
+
 // Re-export completion types for easier access
 type (
 	Command              = completions.Command
@@ -34,7 +41,9 @@ type InputModel struct {
 	// For completion support
 	completionsOpen bool
 	completionQuery string
+	argCompletion   bool // Open completions are for a command argument
 	toolRegistry    *tools.Registry // For dynamic command completion
+	listFiles       func() []string // Project files for @-mention completion
 }
 
 // Ensure InputModel implements required interfaces
@@ -54,6 +63,11 @@ func NewInput(toolRegistry *tools.Registry) *InputModel {
 	}
 }
 
+// SetFileLister sets where @-mention completion gets the project's files
+func (im *InputModel) SetFileLister(listFiles func() []string) {
+	im.listFiles = listFiles
+}
+
 // Init initializes the input component
 func (im *InputModel) Init() tea.Cmd {
 	return nil
@@ -61,11 +75,29 @@ func (im *InputModel) Init() tea.Cmd {
 
 // Update handles messages for the input component
 func (im *InputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// The draft comes back from the editor however the input changed
+	if msg, ok := msg.(EditorFinishedMsg); ok {
+		if msg.Err == nil {
+			im.SetValue(msg.Value)
+			im.CursorEnd()
+		}
+		return im, nil
+	}
+
 	if !im.enabled || !im.focused {
 		return im, nil
 	}
 
 	switch msg := msg.(type) {
+	case tea.PasteMsg:
+		// Pasted newlines stay in the draft rather than sending it
+		im.Insert(strings.NewReplacer("\r\n", "\n", "\r", "\n", "\t", "    ").Replace(string(msg)))
+		if im.completionsOpen {
+			im.completionsOpen = false
+			im.completionQuery = ""
+			return im, im.closeCompletions()
+		}
+
 	case tea.KeyMsg:
 		keyStr := msg.String()
 		
@@ -76,8 +108,16 @@ func (im *InputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if strings.HasPrefix(word, "/") && !im.completionsOpen {
 				im.completionsOpen = true
 				im.completionQuery = word
+				im.argCompletion = false
 				return im, im.triggerCompletions()
 			}
+			if strings.HasPrefix(word, "@") && !im.completionsOpen {
+				return im, im.completeMention()
+			}
+			// Past the command name, complete its arguments
+			if !im.completionsOpen && im.IsSlashCommand() {
+				return im, im.completeArgument(word)
+			}
 			// Otherwise let tab be handled by completions component if open
 			return im, nil
 		}
@@ -105,9 +145,11 @@ func (im *InputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Update filter if completions are open
 				if im.completionsOpen {
 					word := im.GetCurrentWord()
-					if strings.HasPrefix(word, "/") {
+					if strings.HasPrefix(word, "/") || im.argCompletion {
 						im.completionQuery = word
 						return im, im.filterCompletions()
+					} else if strings.HasPrefix(word, "@") {
+						return im, im.completeMention()
 					} else {
 						// Close if we've deleted the slash
 						im.completionsOpen = false
@@ -135,7 +177,7 @@ func (im *InputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+a":
 			im.cursorPos = 0
 		case "ctrl+e":
-			im.cursorPos = len(im.value)
+			return im, im.OpenEditor()
 		case "ctrl+k":
 			// Kill to end of line
 			im.value = im.value[:im.cursorPos]
@@ -161,11 +203,15 @@ func (im *InputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if char == '/' && (im.cursorPos == 1 || (im.cursorPos > 1 && im.value[im.cursorPos-2] == ' ')) {
 						im.completionsOpen = true
 						im.completionQuery = "/"
+						im.argCompletion = false
 						cmd = im.triggerCompletions()
+					} else if strings.HasPrefix(im.GetCurrentWord(), "@") {
+						// Typing a file mention
+						cmd = im.completeMention()
 					} else if im.completionsOpen {
 						// Update filter if completions are open and we're typing
 						word := im.GetCurrentWord()
-						if strings.HasPrefix(word, "/") {
+						if strings.HasPrefix(word, "/") || im.argCompletion {
 							im.completionQuery = word
 							cmd = im.filterCompletions()
 						} else {
@@ -202,8 +248,26 @@ func (im *InputModel) SetSize(width, height int) tea.Cmd {
 	return nil
 }
 
-// View renders the input component
+// View renders the input component, scrolled to keep the cursor in sight
+// when the draft is taller than the input
 func (im *InputModel) View() string {
+	display, cursorLine := im.render()
+	lines := strings.Split(display, "\n")
+	if im.height <= 0 || len(lines) <= im.height {
+		return display
+	}
+	start := max(0, min(cursorLine-im.height+1, len(lines)-im.height))
+	return strings.Join(lines[start:start+im.height], "\n")
+}
+
+// Lines returns how many lines the draft takes at the input's width
+func (im *InputModel) Lines() int {
+	display, _ := im.render()
+	return lipgloss.Height(display)
+}
+
+// render renders the whole draft and returns the line the cursor is on
+func (im *InputModel) render() (string, int) {
 	theme := styles.CurrentTheme()
 	
 	// Create styles with theme colors
@@ -239,9 +303,12 @@ func (im *InputModel) View() string {
 			after := ""
 			cursor := " "
 			
-			if im.cursorPos < len(im.value) {
+			// At a line break the cursor shows as a space before it
+			if im.cursorPos < len(im.value) && im.value[im.cursorPos] != '\n' {
 				cursor = string(im.value[im.cursorPos])
 				after = im.value[im.cursorPos+1:]
+			} else if im.cursorPos < len(im.value) {
+				after = im.value[im.cursorPos:]
 			}
 			
 			// Use theme primary color for cursor
@@ -250,13 +317,14 @@ func (im *InputModel) View() string {
 				Foreground(theme.FgInverted)
 			
 			display = inputStyle.Render(before + cursorStyle.Render(cursor) + after)
+			return display, lipgloss.Height(inputStyle.Render(before+cursor)) - 1
 		} else {
 			// Show without cursor
 			display = inputStyle.Render(im.value)
 		}
 	}
 
-	return display
+	return display, 0
 }
 
 // Focus focuses the input component
@@ -287,6 +355,12 @@ func (im *InputModel) SetValue(value string) {
 	im.cursorPos = len(value)
 }
 
+// Insert puts text into the draft at the cursor
+func (im *InputModel) Insert(text string) {
+	im.value = im.value[:im.cursorPos] + text + im.value[im.cursorPos:]
+	im.cursorPos += len(text)
+}
+
 // Reset clears the input
 func (im *InputModel) Reset() {
 	im.value = ""
@@ -359,6 +433,105 @@ func (im *InputModel) triggerCompletions() tea.Cmd {
 	}
 }
 
+// completeArgument completes the slash command argument word being typed:
+// a single match is filled in, several open the completions popup
+func (im *InputModel) completeArgument(word string) tea.Cmd {
+	if im.toolRegistry == nil {
+		return nil
+	}
+	fields := strings.Fields(im.value[:im.cursorPos-len(word)])
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var matches []Command
+	command := strings.TrimPrefix(fields[0], "/")
+	for _, arg := range im.toolRegistry.CompleteArgument(command, len(fields)-1) {
+		if strings.HasPrefix(strings.ToLower(arg.Name), strings.ToLower(word)) {
+			matches = append(matches, Command{
+				Name:        arg.Name,
+				Description: arg.Description,
+			})
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil
+	case 1:
+		im.replaceCurrentWord(matches[0].Name)
+		// Like a shell, move on to the next argument
+		if im.cursorPos == len(im.value) {
+			im.value += " "
+			im.cursorPos++
+		}
+		return nil
+	}
+
+	im.completionsOpen = true
+	im.completionQuery = word
+	im.argCompletion = true
+	x := im.x + im.cursorPos + 2 // +2 for padding
+	y := im.y
+	return func() tea.Msg {
+		return OpenCompletionsMsg{
+			Commands: matches,
+			X:        x,
+			Y:        y,
+		}
+	}
+}
+
+// completeMention offers the project files that fuzzy match the @-mention
+// being typed, best first. The popup is reopened on every keystroke since
+// its own filtering only matches prefixes.
+func (im *InputModel) completeMention() tea.Cmd {
+	if im.listFiles == nil {
+		return nil
+	}
+	word := im.GetCurrentWord()
+	query := strings.TrimPrefix(word, "@")
+
+	var files []string
+	if query == "" {
+		files = im.listFiles()
+	} else {
+		for _, match := range fuzzy.Find(query, im.listFiles()) {
+			files = append(files, match.Str)
+		}
+	}
+	if len(files) > maxMentionCompletions {
+		files = files[:maxMentionCompletions]
+	}
+
+	if len(files) == 0 {
+		if !im.completionsOpen {
+			return nil
+		}
+		im.completionsOpen = false
+		im.completionQuery = ""
+		return im.closeCompletions()
+	}
+
+	commands := make([]Command, 0, len(files))
+	for _, file := range files {
+		commands = append(commands, Command{Name: "@" + file})
+	}
+	im.completionsOpen = true
+	im.completionQuery = ""
+	im.argCompletion = false
+	// Anchor the popup at the @ so it stays put while typing
+	x := im.x + im.cursorPos - len(word) + 2 // +2 for padding
+	y := im.y
+	return func() tea.Msg {
+		return OpenCompletionsMsg{
+			Commands: commands,
+			X:        x,
+			Y:        y,
+		}
+	}
+}
+
 func (im *InputModel) filterCompletions() tea.Cmd {
 	return func() tea.Msg {
 		return FilterCompletionsMsg{
@@ -375,12 +548,23 @@ func (im *InputModel) closeCompletions() tea.Cmd {
 
 // HandleCompletionSelect handles when a completion is selected
 func (im *InputModel) HandleCompletionSelect(value string) {
-	im.value = value
-	im.cursorPos = len(value)
+	im.replaceCurrentWord(value)
 	im.completionsOpen = false
 	im.completionQuery = ""
 }
 
+// replaceCurrentWord replaces the word under the cursor with value and
+// moves the cursor after it
+func (im *InputModel) replaceCurrentWord(value string) {
+	start := im.cursorPos - len(im.GetCurrentWord())
+	end := im.cursorPos
+	for end < len(im.value) && im.value[end] != ' ' {
+		end++
+	}
+	im.value = im.value[:start] + value + im.value[end:]
+	im.cursorPos = start + len(value)
+}
+
 // IsCompletionsOpen returns true if completions are open
 func (im *InputModel) IsCompletionsOpen() bool {
 	return im.completionsOpen
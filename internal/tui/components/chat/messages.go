@@ -2,10 +2,12 @@ package chat
 
 import (
 	"fmt"
+	"maps"
 	"strings"
 	"time"
 
 	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/mention"
 	"github.com/billie-coop/loco/internal/tui/components/core"
 	"github.com/billie-coop/loco/internal/tui/components/list"
 	"github.com/billie-coop/loco/internal/tui/styles"
@@ -145,6 +147,21 @@ func (m *messageCmp) View() string {
 		align = lipgloss.Right
 	case "assistant":
 		rolePrefix = "Loco:"
+		if meta := m.message.Metadata; meta != nil {
+			var about []string
+			if meta.Model != "" {
+				about = append(about, meta.Model)
+			}
+			if meta.Route != "" {
+				about = append(about, meta.Route)
+			}
+			if meta.Explanation != nil {
+				about = append(about, "explain")
+			}
+			if len(about) > 0 {
+				rolePrefix = "Loco (" + strings.Join(about, ", ") + "):"
+			}
+		}
 		contentStyle = getAssistantStyle()
 		align = lipgloss.Left
 	case "system":
@@ -180,24 +197,41 @@ func (m *messageCmp) View() string {
 		}
 	}
 
-	// Apply markdown rendering for assistant messages
-	if m.message.Role == "assistant" && m.width > 4 {
+	// Explanations render as a card; other assistant messages as markdown
+	if meta := m.message.Metadata; m.message.Role == "assistant" && meta != nil && meta.Explanation != nil && m.width > 4 {
+		content = renderExplanation(meta.Explanation, m.width-8)
+	} else if m.message.Role == "assistant" && m.width > 4 {
 		rendered, err := renderMarkdown(content, m.width-8)
 		if err == nil {
 			content = rendered
 		}
+		if meta := m.message.Metadata; meta != nil && !m.isStreaming {
+			content = linkCitations(content, meta.Citations)
+		}
 	} else if m.width > 4 {
 		// Apply word wrapping for non-assistant messages
 		content = wrapText(content, m.width-8)
 	}
 
-	// Apply style
+	// Apply style; files the user mentioned show as chips
+	styled := contentStyle.Render(content)
+	if m.message.Role == "user" {
+		styled = renderMentionChips(content, contentStyle)
+	}
+	if meta := m.message.Metadata; m.message.Role == "assistant" && meta != nil {
+		if notes := renderCitations(meta.Citations, m.width-8); notes != "" {
+			styled += "\n\n" + notes
+		}
+		if len(meta.Review) > 0 {
+			styled += "\n\n" + renderReview(meta, m.width-8)
+		}
+	}
 	bubble := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(styles.CurrentTheme().BorderFocus).
 		Padding(0, 1).
 		Width(m.width - 4).
-		Render(contentStyle.Render(content))
+		Render(styled)
 
 	// Align bubble left or right depending on role
 	return lipgloss.NewStyle().
@@ -206,6 +240,23 @@ func (m *messageCmp) View() string {
 		Render(bubble)
 }
 
+// renderReview shows the problems the reviewing model found in an answer
+func renderReview(meta *llm.MessageMetadata, width int) string {
+	title := "⚠ Review"
+	if meta.Reviewer != "" {
+		title += " (" + meta.Reviewer + ")"
+	}
+	lines := []string{title + ":"}
+	for _, issue := range meta.Review {
+		lines = append(lines, "- "+issue)
+	}
+	text := strings.Join(lines, "\n")
+	if width > 0 {
+		text = wrapText(text, width)
+	}
+	return styles.CurrentTheme().S().Warning.Render(text)
+}
+
 // GetSize implements list.Item
 func (m *messageCmp) GetSize() (int, int) {
 	return m.width, 0 // Height is calculated by list
@@ -258,6 +309,7 @@ type MessageListModel struct {
 
 	// Tool rendering
 	toolRegistry *ToolRegistry
+	toolExpanded map[int]bool // Tool blocks the user expanded or collapsed, by message index
 
 	// Spinner for creating new items
 	spinner spinner.Model
@@ -282,6 +334,7 @@ func NewMessageList() *MessageListModel {
 		spinner:      s,
 		messagesMeta: make(map[int]*MessageMetadata),
 		toolRegistry: NewToolRegistry(),
+		toolExpanded: make(map[int]bool),
 	}
 }
 
@@ -408,6 +461,59 @@ func (ml *MessageListModel) SetDebugMode(showDebug bool) {
 	ml.refreshContent()
 }
 
+// ToggleToolAt expands or collapses the tool block on line y of the view,
+// reporting whether there was one
+func (ml *MessageListModel) ToggleToolAt(y int) bool {
+	// The list starts below the top padding
+	item, ok := ml.list.ItemAt(y - 1)
+	if !ok {
+		return false
+	}
+	mc, ok := item.(*messageCmp)
+	if !ok || mc.toolMessage == nil || mc.isStreaming {
+		return false
+	}
+
+	expanded := !mc.toolMessage.Expanded()
+	mc.toolMessage.SetExpanded(expanded)
+	ml.toolExpanded[mc.index] = expanded
+	ml.list.UpdateItem(mc.ID(), mc)
+	return true
+}
+
+// ResetToolState forgets which tool blocks were expanded or collapsed, for
+// when the messages are replaced by another conversation
+func (ml *MessageListModel) ResetToolState() {
+	ml.toolExpanded = make(map[int]bool)
+}
+
+// ExpandedTools returns which tool blocks the user expanded or collapsed,
+// by message index
+func (ml *MessageListModel) ExpandedTools() map[int]bool {
+	return maps.Clone(ml.toolExpanded)
+}
+
+// SetExpandedTools restores the tool blocks the user expanded or collapsed,
+// for when a conversation is shown again
+func (ml *MessageListModel) SetExpandedTools(expanded map[int]bool) {
+	ml.toolExpanded = maps.Clone(expanded)
+	if ml.toolExpanded == nil {
+		ml.toolExpanded = make(map[int]bool)
+	}
+}
+
+// ScrollOffset returns how many lines the view is scrolled up from the
+// newest message
+func (ml *MessageListModel) ScrollOffset() int {
+	return ml.list.ScrollOffset()
+}
+
+// ScrollToOffset scrolls the view to lines above the newest message
+func (ml *MessageListModel) ScrollToOffset(lines int) {
+	ml.list.GoToBottom()
+	ml.list.MoveUp(lines)
+}
+
 // GotoBottom scrolls to the bottom of the list
 func (ml *MessageListModel) GotoBottom() {
 	ml.list.GoToBottom()
@@ -440,6 +546,9 @@ func (ml *MessageListModel) refreshContent() {
 		if mc, ok := item.(*messageCmp); ok {
 			mc.SetIndex(i)
 			mc.width = itemWidth
+			if expanded, ok := ml.toolExpanded[i]; ok && mc.toolMessage != nil {
+				mc.toolMessage.SetExpanded(expanded)
+			}
 		}
 		items = append(items, item)
 	}
@@ -605,6 +714,41 @@ func getSystemStyle() lipgloss.Style {
 		Italic(true)
 }
 
+func getMentionChipStyle() lipgloss.Style {
+	theme := styles.CurrentTheme()
+	return lipgloss.NewStyle().
+		Foreground(theme.FgBase).
+		Background(theme.BgBaseLighter).
+		Bold(true)
+}
+
+// renderMentionChips renders text in style with each @path mention drawn
+// as a chip
+func renderMentionChips(text string, style lipgloss.Style) string {
+	if len(mention.Find(text)) == 0 {
+		return style.Render(text)
+	}
+
+	chip := getMentionChipStyle()
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		var sb strings.Builder
+		last := 0
+		for _, m := range mention.Find(line) {
+			if m.Start > last {
+				sb.WriteString(style.Render(line[last:m.Start]))
+			}
+			sb.WriteString(chip.Render(line[m.Start:m.End]))
+			last = m.End
+		}
+		if last < len(line) {
+			sb.WriteString(style.Render(line[last:]))
+		}
+		lines[i] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
 func getMetaStyle() lipgloss.Style {
 	theme := styles.CurrentTheme()
 	return lipgloss.NewStyle().
@@ -7,6 +7,7 @@ import (
 
 	"github.com/billie-coop/loco/internal/llm"
 	"github.com/billie-coop/loco/internal/session"
+	"github.com/billie-coop/loco/internal/tasks"
 	"github.com/billie-coop/loco/internal/tui/components/core"
 	"github.com/billie-coop/loco/internal/tui/styles"
 	tea "github.com/charmbracelet/bubbletea/v2"
@@ -28,6 +29,7 @@ type AnalysisState struct {
 	DetailedRunning      bool
 	DetailedCompleted    bool
 	DeepCompleted        bool
+	FullCompleted        bool
 	KnowledgeRunning     bool
 	KnowledgeCompleted   bool
 	CurrentPhase         string
@@ -36,6 +38,37 @@ type AnalysisState struct {
 	CompletedFiles       int
 }
 
+// IndexState represents background re-indexing of changed files
+type IndexState struct {
+	IsRunning   bool
+	Total       int
+	Completed   int
+	Failed      int
+	CurrentFile string
+}
+
+// SidebarTargetKind is what a clickable sidebar row refers to
+type SidebarTargetKind int
+
+const (
+	// TargetSession is a saved chat session
+	TargetSession SidebarTargetKind = iota
+	// TargetKnowledgeFile is a generated knowledge file
+	TargetKnowledgeFile
+)
+
+// SidebarTarget is a clickable row in the sidebar
+type SidebarTarget struct {
+	Kind SidebarTargetKind
+	ID   string // Session ID or knowledge file path
+}
+
+// Rows listed before the rest are summarized as "+N more"
+const (
+	maxSidebarSessions       = 5
+	maxSidebarKnowledgeFiles = 8
+)
+
 // SidebarModel implements the sidebar component
 type SidebarModel struct {
 	width  int
@@ -51,7 +84,22 @@ type SidebarModel struct {
 	sessionManager *session.Manager
 	projectContext *Context
 	analysisState  *AnalysisState
+	indexState     *IndexState
 	messages       []llm.Message
+	knowledgeFiles []string
+
+	// Clickable rows of the last render, by line
+	targets map[int]SidebarTarget
+
+	// Concurrent analysis workers, scaled with LM Studio's health
+	workerLevel  int
+	workerMax    int
+	workerReason string // Why the level last changed
+
+	// How far the quick tier's workers agreed: "high", "medium", "low" or
+	// "" before any ranking
+	quickConfidence string
+	quickAgreement  float64
 
 	// Timer for analysis tracking
 	analysisTimer *core.Timer
@@ -126,6 +174,7 @@ func (s *SidebarModel) View() string {
 		Padding(0)
 
 	var content strings.Builder
+	s.targets = make(map[int]SidebarTarget)
 
 	// Title section
 	s.renderTitle(&content)
@@ -139,12 +188,21 @@ func (s *SidebarModel) View() string {
 	// Session information
 	s.renderSessionInfo(&content)
 
+	// The session's plan
+	s.renderPlan(&content)
+
 	// Project information
 	s.renderProjectInfo(&content)
 
 	// Analysis tiers
 	s.renderAnalysisTiers(&content)
 
+	// Generated knowledge
+	s.renderKnowledgeFiles(&content)
+
+	// Background indexing
+	s.renderIndexState(&content)
+
 	// Message counts
 	s.renderMessageCounts(&content)
 
@@ -212,11 +270,46 @@ func (s *SidebarModel) SetAnalysisState(state *AnalysisState) tea.Cmd {
 	return nil
 }
 
+// SetIndexState updates the background indexing state
+func (s *SidebarModel) SetIndexState(state *IndexState) {
+	s.indexState = state
+}
+
+// SetWorkerConcurrency updates the analysis worker level
+func (s *SidebarModel) SetWorkerConcurrency(level, max int, reason string) {
+	s.workerLevel = level
+	s.workerMax = max
+	s.workerReason = reason
+}
+
+// SetQuickConfidence shows how far the quick tier's workers agreed; an
+// empty level hides it
+func (s *SidebarModel) SetQuickConfidence(level string, agreement float64) {
+	s.quickConfidence = level
+	s.quickAgreement = agreement
+}
+
 // SetMessages updates the messages for count calculation
 func (s *SidebarModel) SetMessages(messages []llm.Message) {
 	s.messages = messages
 }
 
+// SetKnowledgeFiles updates the knowledge files listed for opening
+func (s *SidebarModel) SetKnowledgeFiles(files []string) {
+	s.knowledgeFiles = files
+}
+
+// TargetAt returns the clickable row on line y of the view, if any
+func (s *SidebarModel) TargetAt(y int) (SidebarTarget, bool) {
+	target, ok := s.targets[y]
+	return target, ok
+}
+
+// addTarget makes the next line written to content clickable
+func (s *SidebarModel) addTarget(content *strings.Builder, target SidebarTarget) {
+	s.targets[strings.Count(content.String(), "\n")] = target
+}
+
 // Private rendering methods
 
 func (s *SidebarModel) renderTitle(content *strings.Builder) {
@@ -293,6 +386,17 @@ func (s *SidebarModel) renderStatus(content *strings.Builder) {
 		content.WriteString(statusStyle.Render("✅ Connected"))
 	}
 	content.WriteString("\n\n")
+
+	// Analysis workers LM Studio is keeping up with
+	if s.workerMax > 0 {
+		content.WriteString(labelStyle.Render("Workers: "))
+		content.WriteString(statusStyle.Render(fmt.Sprintf("%d/%d", s.workerLevel, s.workerMax)))
+		if s.workerReason != "" {
+			content.WriteString("\n")
+			content.WriteString(theme.S().Subtle.Render(s.workerReason))
+		}
+		content.WriteString("\n\n")
+	}
 }
 
 func (s *SidebarModel) renderModelInfo(content *strings.Builder) {
@@ -346,26 +450,81 @@ func (s *SidebarModel) renderModelInfo(content *strings.Builder) {
 }
 
 func (s *SidebarModel) renderSessionInfo(content *strings.Builder) {
+	if s.sessionManager == nil {
+		return
+	}
+	sessions := s.sessionManager.ListSessions()
+	if len(sessions) == 0 {
+		return
+	}
+
 	theme := styles.CurrentTheme()
 	labelStyle := theme.S().Muted
 	statusStyle := theme.S().Info
+	dimStyle := theme.S().Subtle
 
-	if s.sessionManager != nil {
-		currentSession, err := s.sessionManager.GetCurrent()
-		if err != nil {
-			currentSession = nil
-		}
-		if currentSession != nil {
-			content.WriteString(labelStyle.Render("Session:"))
+	currentSession, err := s.sessionManager.GetCurrent()
+	if err != nil {
+		currentSession = nil
+	}
+
+	content.WriteString(labelStyle.Render("Sessions:"))
+	content.WriteString("\n")
+	for i, sess := range sessions {
+		if i == maxSidebarSessions {
+			content.WriteString(dimStyle.Render(fmt.Sprintf("  +%d more", len(sessions)-i)))
 			content.WriteString("\n")
-			truncTitle := currentSession.Title
-			if len(truncTitle) > s.width-8 {
-				truncTitle = truncTitle[:s.width-11] + "..."
-			}
-			content.WriteString(statusStyle.Render(truncTitle))
-			content.WriteString("\n\n")
+			break
+		}
+		marker, style := "  ", dimStyle
+		if currentSession != nil && sess.ID == currentSession.ID {
+			marker, style = "▸ ", statusStyle
 		}
+		truncTitle := sess.Title
+		if len(truncTitle) > s.width-8 {
+			truncTitle = truncTitle[:s.width-11] + "..."
+		}
+		s.addTarget(content, SidebarTarget{Kind: TargetSession, ID: sess.ID})
+		content.WriteString(style.Render(marker + truncTitle))
+		content.WriteString("\n")
 	}
+	content.WriteString("\n")
+}
+
+func (s *SidebarModel) renderPlan(content *strings.Builder) {
+	if s.sessionManager == nil {
+		return
+	}
+	plan, err := s.sessionManager.Plan()
+	if err != nil || plan == nil {
+		return
+	}
+
+	theme := styles.CurrentTheme()
+	labelStyle := theme.S().Muted
+	dimStyle := theme.S().Subtle
+
+	finished, total := plan.Progress()
+	content.WriteString(labelStyle.Render(fmt.Sprintf("Plan (%d/%d):", finished, total)))
+	content.WriteString("\n")
+	for i, step := range plan.Steps {
+		style := dimStyle
+		switch step.Status {
+		case tasks.StatusActive:
+			style = theme.S().Info
+		case tasks.StatusDone:
+			style = theme.S().Success
+		case tasks.StatusFailed:
+			style = theme.S().Error
+		}
+		line := fmt.Sprintf("%s %d. %s", step.Marker(), i+1, step.Title)
+		if len(line) > s.width-4 {
+			line = line[:max(0, s.width-7)] + "..."
+		}
+		content.WriteString(style.Render(line))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
 }
 
 func (s *SidebarModel) renderProjectInfo(content *strings.Builder) {
@@ -390,6 +549,18 @@ func (s *SidebarModel) renderProjectInfo(content *strings.Builder) {
 	}
 }
 
+// confidenceStyle colors a consensus confidence level
+func confidenceStyle(level string) lipgloss.Style {
+	theme := styles.CurrentTheme()
+	switch level {
+	case "high":
+		return theme.S().Success
+	case "medium":
+		return theme.S().Warning
+	}
+	return theme.S().Error
+}
+
 func (s *SidebarModel) renderAnalysisTiers(content *strings.Builder) {
 	theme := styles.CurrentTheme()
 	labelStyle := theme.S().Muted
@@ -430,6 +601,10 @@ func (s *SidebarModel) renderAnalysisTiers(content *strings.Builder) {
 		content.WriteString(completeStyle.Render(fmt.Sprintf("%s Quick", quickIcon)))
 		content.WriteString(" ")
 		content.WriteString(dimStyle.Render("✓"))
+		if s.quickConfidence != "" {
+			content.WriteString(" ")
+			content.WriteString(confidenceStyle(s.quickConfidence).Render(fmt.Sprintf("%s %.2f", s.quickConfidence, s.quickAgreement)))
+		}
 	} else if s.analysisState != nil && s.analysisState.IsRunning && s.analysisState.CurrentPhase == "quick" {
 		content.WriteString(runningStyle.Render(fmt.Sprintf("%s Quick", quickIcon)))
 		content.WriteString(" ")
@@ -476,7 +651,7 @@ func (s *SidebarModel) renderAnalysisTiers(content *strings.Builder) {
 		content.WriteString(completeStyle.Render(fmt.Sprintf("%s Deep", deepIcon)))
 		content.WriteString(" ")
 		content.WriteString(dimStyle.Render("✓"))
-	} else if s.analysisState != nil && s.analysisState.IsRunning && (s.analysisState.CurrentPhase == "deep" || s.analysisState.CurrentPhase == "full") {
+	} else if s.analysisState != nil && s.analysisState.IsRunning && s.analysisState.CurrentPhase == "deep" {
 		content.WriteString(runningStyle.Render(fmt.Sprintf("%s Deep", deepIcon)))
 		content.WriteString(" ")
 		content.WriteString(dimStyle.Render("⏳"))
@@ -487,11 +662,15 @@ func (s *SidebarModel) renderAnalysisTiers(content *strings.Builder) {
 	}
 	content.WriteString("\n")
 
-	// Tier 4: Full Analysis (shows same as deep for now)
-	if s.analysisState != nil && s.analysisState.DeepCompleted {
+	// Tier 4: Full Analysis
+	if s.analysisState != nil && s.analysisState.FullCompleted {
 		content.WriteString(completeStyle.Render(fmt.Sprintf("%s Full", fullIcon)))
 		content.WriteString(" ")
 		content.WriteString(dimStyle.Render("✓"))
+	} else if s.analysisState != nil && s.analysisState.IsRunning && s.analysisState.CurrentPhase == "full" {
+		content.WriteString(runningStyle.Render(fmt.Sprintf("%s Full", fullIcon)))
+		content.WriteString(" ")
+		content.WriteString(dimStyle.Render("⏳"))
 	} else {
 		content.WriteString(pendingStyle.Render(fmt.Sprintf("%s Full", fullIcon)))
 		content.WriteString(" ")
@@ -510,6 +689,8 @@ func (s *SidebarModel) renderAnalysisTiers(content *strings.Builder) {
 			phaseText = "📊 Analyzing files..."
 		case "knowledge":
 			phaseText = "💎 Generating deep knowledge..."
+		case "full":
+			phaseText = "🚀 Documenting architecture..."
 		case "complete":
 			phaseText = "✨ Analysis complete!"
 		}
@@ -526,6 +707,76 @@ func (s *SidebarModel) renderAnalysisTiers(content *strings.Builder) {
 	content.WriteString("\n\n")
 }
 
+func (s *SidebarModel) renderKnowledgeFiles(content *strings.Builder) {
+	if len(s.knowledgeFiles) == 0 {
+		return
+	}
+
+	theme := styles.CurrentTheme()
+	labelStyle := theme.S().Muted
+	dimStyle := theme.S().Subtle
+
+	content.WriteString(labelStyle.Render("Knowledge:"))
+	content.WriteString("\n")
+	for i, file := range s.knowledgeFiles {
+		if i == maxSidebarKnowledgeFiles {
+			content.WriteString(dimStyle.Render(fmt.Sprintf("  +%d more", len(s.knowledgeFiles)-i)))
+			content.WriteString("\n")
+			break
+		}
+		s.addTarget(content, SidebarTarget{Kind: TargetKnowledgeFile, ID: file})
+		content.WriteString(dimStyle.Render("  " + truncatePath(file, s.width-6)))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+}
+
+func (s *SidebarModel) renderIndexState(content *strings.Builder) {
+	// Nothing to show until the watcher has queued a change
+	if s.indexState == nil {
+		return
+	}
+
+	theme := styles.CurrentTheme()
+	labelStyle := theme.S().Muted
+	dimStyle := theme.S().Subtle
+
+	content.WriteString(labelStyle.Render("RAG Index:"))
+	content.WriteString("\n")
+
+	if s.indexState.IsRunning {
+		content.WriteString(theme.S().Info.Render(fmt.Sprintf("🔄 Indexing %d/%d", s.indexState.Completed, s.indexState.Total)))
+		content.WriteString("\n")
+		if s.indexState.Total > 0 {
+			progress := float64(s.indexState.Completed) / float64(s.indexState.Total)
+			filled := int(20 * progress)
+			bar := strings.Repeat("█", filled) + strings.Repeat("░", 20-filled)
+			content.WriteString(theme.S().Success.Render(bar))
+			content.WriteString("\n")
+		}
+		if s.indexState.CurrentFile != "" {
+			content.WriteString(dimStyle.Render(truncatePath(s.indexState.CurrentFile, s.width-4)))
+			content.WriteString("\n")
+		}
+	} else if s.indexState.Failed > 0 {
+		content.WriteString(theme.S().Warning.Render(fmt.Sprintf("⚠️  %d of %d files failed", s.indexState.Failed, s.indexState.Total)))
+		content.WriteString("\n")
+	} else {
+		content.WriteString(theme.S().Success.Render("✓ Up to date"))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+}
+
+// truncatePath shortens a path from the left to fit width
+func truncatePath(path string, width int) string {
+	if width <= 3 || len(path) <= width {
+		return path
+	}
+	return "..." + path[len(path)-width+3:]
+}
+
 func (s *SidebarModel) renderMessageCounts(content *strings.Builder) {
 	theme := styles.CurrentTheme()
 	labelStyle := theme.S().Muted
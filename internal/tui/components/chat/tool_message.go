@@ -6,8 +6,10 @@ import (
 	"strings"
 
 	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/tui/highlight"
 	"github.com/billie-coop/loco/internal/tui/styles"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // ToolRenderer defines the interface for tool-specific rendering
@@ -33,6 +35,8 @@ func NewToolRegistry() *ToolRegistry {
 	registry.Register("list_files", &ListFilesRenderer{})
 	registry.Register("search", &SearchRenderer{})
 	registry.Register("analyze", &AnalyzeRenderer{})
+	registry.Register("edit_file", &DiffRenderer{})
+	registry.Register("git_diff", &DiffRenderer{})
 	
 	return registry
 }
@@ -147,6 +151,59 @@ func (b *BaseRenderer) RenderContent(content string, width int, maxLines int) st
 	return result
 }
 
+// RenderDiffContent renders a unified diff with added and removed lines and
+// hunk headers colored by the theme, truncated like RenderContent
+func (b *BaseRenderer) RenderDiffContent(diff string, width int, maxLines int) string {
+	theme := styles.CurrentTheme()
+
+	// Normalize content; leading spaces mark context lines, so keep them
+	diff = strings.ReplaceAll(diff, "\r\n", "\n")
+	diff = strings.ReplaceAll(diff, "\t", "    ")
+	diff = strings.TrimRight(diff, "\n")
+
+	lines := strings.Split(diff, "\n")
+	total := len(lines)
+	if maxLines <= 0 {
+		maxLines = 10
+	}
+	truncated := false
+	if total > maxLines {
+		lines = lines[:maxLines]
+		truncated = true
+	}
+
+	lines = highlight.Diff(strings.Join(lines, "\n"), theme.BgBaseLighter)
+
+	lineWidth := width - 4 // Account for "  " padding
+	lineStyle := lipgloss.NewStyle().
+		Background(theme.BgBaseLighter).
+		Width(lineWidth)
+	styledLines := make([]string, len(lines))
+	for i, line := range lines {
+		if ansi.StringWidth(line) > lineWidth {
+			line = ansi.Truncate(line, lineWidth, "…")
+		}
+		styledLines[i] = "  " + lineStyle.Render(line)
+	}
+
+	result := strings.Join(styledLines, "\n")
+
+	if truncated {
+		truncateMsg := "  " + theme.S().Subtle.
+			Background(theme.BgBaseLighter).
+			Width(lineWidth).
+			Render(fmt.Sprintf("… (%d more lines)", total-maxLines))
+		result = result + "\n" + truncateMsg
+	}
+
+	return result
+}
+
+// isUnifiedDiff reports whether text is a unified diff with at least one hunk
+func isUnifiedDiff(text string) bool {
+	return strings.HasPrefix(text, "@@ ") || strings.Contains(text, "\n@@ ")
+}
+
 // ToolStatus represents the status of a tool execution
 type ToolStatus int
 
@@ -336,6 +393,9 @@ func (r *ReadFileRenderer) RenderCodeContent(code, filename string, width, offse
 		truncated = true
 	}
 	
+	// Color the visible lines with the theme
+	lines = highlight.Lines(strings.Join(lines, "\n"), filename, theme.BgBaseLighter)
+
 	// Calculate line number width
 	maxLineNum := offset + len(lines)
 	lineNumWidth := len(fmt.Sprintf("%d", maxLineNum))
@@ -349,8 +409,8 @@ func (r *ReadFileRenderer) RenderCodeContent(code, filename string, width, offse
 		lineNum := offset + i + 1
 		
 		// Truncate long lines
-		if len(line) > codeWidth {
-			line = line[:codeWidth-1] + "…"
+		if ansi.StringWidth(line) > codeWidth {
+			line = ansi.Truncate(line, codeWidth, "…")
 		}
 		
 		// Format line number
@@ -423,6 +483,65 @@ func (w *WriteFileRenderer) Render(call llm.ToolCall, result *llm.ToolResult, wi
 	return header
 }
 
+// DiffRenderer handles tools that show a unified diff: the change an
+// edit_file call makes, or git_diff's output
+type DiffRenderer struct {
+	BaseRenderer
+}
+
+func (d *DiffRenderer) Render(call llm.ToolCall, result *llm.ToolResult, width int) string {
+	status := ToolPending
+	if result != nil {
+		if result.Error != nil {
+			status = ToolError
+		} else {
+			status = ToolSuccess
+		}
+	}
+
+	// Parse parameters
+	var params struct {
+		Path string `json:"path"`
+		Diff string `json:"diff"`
+	}
+	json.Unmarshal([]byte(call.Parameters), &params)
+
+	name := "diff"
+	if params.Diff != "" {
+		name = "edit"
+	}
+	var headerParams []string
+	if params.Path != "" {
+		headerParams = append(headerParams, params.Path)
+	}
+	header := d.RenderHeader(name, headerParams, status, width)
+
+	if result != nil && result.Error != nil {
+		theme := styles.CurrentTheme()
+		errorTag := theme.S().Error.
+			Background(theme.Error).
+			Foreground(theme.FgInverted).
+			Padding(0, 1).
+			Render("ERROR")
+		errorMsg := theme.S().Muted.Render(result.Error.Error())
+		return header + "\n" + fmt.Sprintf("  %s %s", errorTag, errorMsg)
+	}
+
+	// An edit previews its diff before it runs; git_diff shows its output
+	diff := params.Diff
+	if diff == "" && result != nil {
+		diff = result.Output
+	}
+	if isUnifiedDiff(diff) {
+		return header + "\n" + d.RenderDiffContent(diff, width, 10)
+	}
+	if result != nil && result.Output != "" {
+		return header + "\n\n" + d.RenderContent(result.Output, width, 10)
+	}
+
+	return header
+}
+
 // ListFilesRenderer handles directory listing
 type ListFilesRenderer struct {
 	BaseRenderer
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/tools"
 	"github.com/billie-coop/loco/internal/tui/components/anim"
+	"github.com/billie-coop/loco/internal/tui/highlight"
 	"github.com/billie-coop/loco/internal/tui/styles"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
@@ -33,6 +35,11 @@ func NewToolMessage(msg llm.Message) *ToolMessage {
 		startTime: time.Now(),
 	}
 
+	// Cited sources start collapsed so the answer stays readable
+	if msg.ToolExecution != nil && msg.ToolExecution.Name == "codebase_source" {
+		tm.expanded = false
+	}
+
 	// Create spinner for pending/running states
 	if msg.ToolExecution != nil && (msg.ToolExecution.Status == "pending" || msg.ToolExecution.Status == "running") {
 		tm.spinner = anim.NewSpinner(anim.SpinnerDots)
@@ -113,6 +120,14 @@ func (tm *ToolMessage) View() string {
 	if tm.message.ToolExecution.Name == "startup_welcome" {
 		header = "👋 Welcome"
 	}
+	// Sources show their location instead of a name and a progress line
+	if tm.message.ToolExecution.Name == "codebase_source" {
+		return tm.renderSource(theme)
+	}
+	// Background processes are named by their ID, with the command below
+	if id, ok := strings.CutPrefix(tm.message.ToolExecution.Name, tools.ProcessCardPrefix); ok {
+		header = fmt.Sprintf("%s 🖥️ %s", icon, id)
+	}
 
 	// Add spinner and elapsed time if pending/running
 	if tm.spinner != nil && (tm.message.ToolExecution.Status == "pending" || tm.message.ToolExecution.Status == "running") {
@@ -177,6 +192,16 @@ func (tm *ToolMessage) SetWidth(width int) {
 	tm.width = width
 }
 
+// Expanded reports whether the tool output is shown
+func (tm *ToolMessage) Expanded() bool {
+	return tm.expanded
+}
+
+// SetExpanded shows or hides the tool output
+func (tm *ToolMessage) SetExpanded(expanded bool) {
+	tm.expanded = expanded
+}
+
 func (tm *ToolMessage) getStatusIcon() string {
 	if tm.message.ToolExecution == nil {
 		return "🔧"
@@ -210,6 +235,12 @@ func (tm *ToolMessage) renderContent() string {
 	if tm.message.ToolExecution == nil {
 		return ""
 	}
+	// Diffs, from git_diff or /knowledge diff, are colored whatever the tool
+	if isUnifiedDiff(tm.message.Content) {
+		lines := strings.Count(tm.message.Content, "\n") + 1
+		return (&BaseRenderer{}).RenderDiffContent(tm.message.Content, tm.width-3, lines)
+	}
+
 	// Special rendering based on tool type
 	switch tm.message.ToolExecution.Name {
 	case "startup_scan":
@@ -290,6 +321,35 @@ func (tm *ToolMessage) renderAnalyze() string {
 	return strings.Join(output, "\n")
 }
 
+// renderSource renders a cited source as a compact card whose header is
+// the file location; the code shows when expanded
+func (tm *ToolMessage) renderSource(theme *styles.Theme) string {
+	toggle := "▸"
+	if tm.expanded {
+		toggle = "▾"
+	}
+	body := fmt.Sprintf("%s 📄 %s", toggle, tm.message.ToolExecution.Progress)
+	if tm.expanded && tm.message.Content != "" {
+		// The location starts with the path, whose extension picks the
+		// language, followed by the lines or the symbol
+		path, _, _ := strings.Cut(tm.message.ToolExecution.Progress, " · ")
+		path, _, _ = strings.Cut(path, ":")
+		lines := highlight.Lines(tm.message.Content, path, theme.BgBaseLighter)
+		for i, line := range lines {
+			lines[i] = "  " + line
+		}
+		body += "\n" + strings.Join(lines, "\n")
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.BorderFocus).
+		Background(theme.BgBaseLighter).
+		Padding(0, 1).
+		Width(max(10, tm.width-1)).
+		Render(body)
+}
+
 func (tm *ToolMessage) renderCopy() string {
 	// Just show with a checkmark
 	return "  ✓ " + tm.message.Content
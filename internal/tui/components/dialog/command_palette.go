@@ -8,22 +8,56 @@ import (
 	"github.com/billie-coop/loco/internal/tui/styles"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/sahilm/fuzzy"
 )
 
+// PaletteKind is what selecting a palette entry does
+type PaletteKind string
+
+const (
+	PaletteCommand   PaletteKind = "command"   // Run the command or shortcut in Action
+	PaletteSession   PaletteKind = "session"   // Switch to the session whose ID is Action
+	PaletteKnowledge PaletteKind = "knowledge" // Open the knowledge file at Action
+	PaletteFile      PaletteKind = "file"      // Mention the project file at Action
+)
+
+// paletteIcons mark each kind of entry in the list
+var paletteIcons = map[PaletteKind]string{
+	PaletteCommand:   "⚡",
+	PaletteSession:   "💬",
+	PaletteKnowledge: "📄",
+	PaletteFile:      "📝",
+}
+
 // Command represents a command in the palette
 type Command struct {
 	Name        string
 	Description string
 	Shortcut    string
 	Action      string // The actual command to execute
+	Kind        PaletteKind
+}
+
+// paletteMatch is a command that matches the search, with the byte offsets
+// of the matched characters in its name
+type paletteMatch struct {
+	Command
+	matched []int
 }
 
+// paletteSource exposes commands to fuzzy matching by name and shortcut
+type paletteSource []Command
+
+func (s paletteSource) String(i int) string { return s[i].Name + " " + s[i].Shortcut }
+func (s paletteSource) Len() int            { return len(s) }
+
 // CommandPaletteDialog displays a searchable list of all commands
 type CommandPaletteDialog struct {
 	*BaseDialog
 
 	commands         []Command
-	filteredCommands []Command
+	entries          []Command // Sessions and files, refreshed before each open
+	filteredCommands []paletteMatch
 	searchQuery      string
 	selectedIndex    int
 	eventBroker      *events.Broker
@@ -35,6 +69,7 @@ type CommandPaletteDialog struct {
 	selectedItemStyle lipgloss.Style
 	shortcutStyle     lipgloss.Style
 	descStyle         lipgloss.Style
+	matchStyle        lipgloss.Style
 }
 
 // NewCommandPaletteDialog creates a new command palette dialog
@@ -65,14 +100,24 @@ func NewCommandPaletteDialog(eventBroker *events.Broker, toolRegistry *tools.Reg
 
 		descStyle: lipgloss.NewStyle().
 			Foreground(theme.FgMuted),
+
+		matchStyle: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true),
 	}
 
 	// Generate commands dynamically from tool registry
 	d.commands = d.generateCommands()
-	d.filteredCommands = d.commands
+	d.filterCommands()
 	return d
 }
 
+// SetEntries sets the sessions and files listed after the commands
+func (d *CommandPaletteDialog) SetEntries(entries []Command) {
+	d.entries = entries
+	d.filterCommands()
+}
+
 // generateCommands creates command list from tool registry with fallback
 func (d *CommandPaletteDialog) generateCommands() []Command {
 	var commands []Command
@@ -86,21 +131,27 @@ func (d *CommandPaletteDialog) generateCommands() []Command {
 				Description: cmd.Description,
 				Shortcut:    cmd.Name, // Keep slash for shortcut display
 				Action:      cmd.Name, // Keep slash for action
+				Kind:        PaletteCommand,
 			})
 		}
 	}
 	
 	// Add non-tool commands (keyboard shortcuts)
 	commands = append(commands,
-		Command{Name: "Clear Messages", Description: "Clear the message history", Shortcut: "Ctrl+L", Action: "ctrl+l"},
-		Command{Name: "Command Palette", Description: "Open this command palette", Shortcut: "Ctrl+P", Action: "ctrl+p"},
+		Command{Name: "Clear Messages", Description: "Clear the message history", Shortcut: "Ctrl+L", Action: "ctrl+l", Kind: PaletteCommand},
+		Command{Name: "Copy Screen", Description: "Copy the screen to the clipboard", Shortcut: "Ctrl+S", Action: "ctrl+s", Kind: PaletteCommand},
+		Command{Name: "Browse Knowledge", Description: "Browse, search and copy knowledge files", Shortcut: "Ctrl+B", Action: "ctrl+b", Kind: PaletteCommand},
+		Command{Name: "Open Draft in Editor", Description: "Write the message in $EDITOR", Shortcut: "Ctrl+E", Action: "ctrl+e", Kind: PaletteCommand},
+		Command{Name: "Edit Last Message", Description: "Edit and resend your last message", Shortcut: "Alt+E", Action: "alt+e", Kind: PaletteCommand},
+		Command{Name: "Regenerate Reply", Description: "Ask for the last reply again", Shortcut: "Ctrl+R", Action: "ctrl+r", Kind: PaletteCommand},
+		Command{Name: "Regenerate With Next Model", Description: "Ask the team's next model for the last reply", Shortcut: "Alt+R", Action: "alt+r", Kind: PaletteCommand},
 	)
 	
 	// Fallback if no commands available
 	if len(commands) == 0 {
 		commands = []Command{
-			{Name: "Help", Description: "Show help message", Shortcut: "/help", Action: "/help"},
-			{Name: "Clear Messages", Description: "Clear all messages", Shortcut: "/clear", Action: "/clear"},
+			{Name: "Help", Description: "Show help message", Shortcut: "/help", Action: "/help", Kind: PaletteCommand},
+			{Name: "Clear Messages", Description: "Clear all messages", Shortcut: "/clear", Action: "/clear", Kind: PaletteCommand},
 		}
 	}
 	
@@ -123,11 +174,11 @@ func (d *CommandPaletteDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "esc":
 			return d, d.Close()
-		case "up", "k":
+		case "up", "ctrl+p", "shift+tab":
 			if d.selectedIndex > 0 {
 				d.selectedIndex--
 			}
-		case "down", "j":
+		case "down", "ctrl+n", "tab":
 			if d.selectedIndex < len(d.filteredCommands)-1 {
 				d.selectedIndex++
 			}
@@ -140,19 +191,25 @@ func (d *CommandPaletteDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					Type: events.CommandSelectedEvent,
 					Payload: events.CommandSelectedPayload{
 						Command: cmd.Action,
+						Kind:    string(cmd.Kind),
 					},
 				})
 				return d, d.Close()
 			}
 		case "backspace":
 			if len(d.searchQuery) > 0 {
-				d.searchQuery = d.searchQuery[:len(d.searchQuery)-1]
+				runes := []rune(d.searchQuery)
+				d.searchQuery = string(runes[:len(runes)-1])
 				d.filterCommands()
 			}
+		case "space":
+			d.searchQuery += " "
+			d.filterCommands()
 		default:
-			// Handle text input for search
-			if len(msg.String()) == 1 && msg.String()[0] >= 32 && msg.String()[0] < 127 {
-				d.searchQuery += msg.String()
+			// Handle text input for search; letters can't navigate since
+			// they are part of the query
+			if text := msg.Key().Text; text != "" && msg.Key().Mod&^tea.ModShift == 0 {
+				d.searchQuery += text
 				d.filterCommands()
 			}
 		}
@@ -194,7 +251,7 @@ func (d *CommandPaletteDialog) View() string {
 			style = d.selectedItemStyle
 		}
 
-		name := cmd.Name
+		name := paletteIcons[cmd.Kind] + " " + d.highlightMatches(cmd.Name, cmd.matched, style)
 		shortcut := d.shortcutStyle.Render(" " + cmd.Shortcut)
 		desc := d.descStyle.Render(" - " + cmd.Description)
 
@@ -202,6 +259,10 @@ func (d *CommandPaletteDialog) View() string {
 		items = append(items, item)
 	}
 
+	if len(items) == 0 {
+		items = append(items, d.itemStyle.Width(maxWidth).Render(d.descStyle.Render("No matches")))
+	}
+
 	// Join all items
 	list := lipgloss.JoinVertical(lipgloss.Left, items...)
 
@@ -216,17 +277,29 @@ func (d *CommandPaletteDialog) View() string {
 	return d.RenderDialog(content)
 }
 
-// filterCommands filters commands based on search query
+// filterCommands fuzzy matches the search query against the names and
+// shortcuts of all entries, best matches first
 func (d *CommandPaletteDialog) filterCommands() {
-	query := strings.ToLower(d.searchQuery)
-	d.filteredCommands = nil
+	all := make(paletteSource, 0, len(d.commands)+len(d.entries))
+	all = append(all, d.commands...)
+	all = append(all, d.entries...)
 
-	for _, cmd := range d.commands {
-		if query == "" ||
-			strings.Contains(strings.ToLower(cmd.Name), query) ||
-			strings.Contains(strings.ToLower(cmd.Description), query) ||
-			strings.Contains(strings.ToLower(cmd.Shortcut), query) {
-			d.filteredCommands = append(d.filteredCommands, cmd)
+	d.filteredCommands = nil
+	query := strings.TrimSpace(d.searchQuery)
+	if query == "" {
+		for _, cmd := range all {
+			d.filteredCommands = append(d.filteredCommands, paletteMatch{Command: cmd})
+		}
+	} else {
+		for _, match := range fuzzy.FindFrom(query, all) {
+			// Only offsets inside the name are highlighted
+			var matched []int
+			for _, idx := range match.MatchedIndexes {
+				if idx < len(all[match.Index].Name) {
+					matched = append(matched, idx)
+				}
+			}
+			d.filteredCommands = append(d.filteredCommands, paletteMatch{Command: all[match.Index], matched: matched})
 		}
 	}
 
@@ -236,6 +309,33 @@ func (d *CommandPaletteDialog) filterCommands() {
 	}
 }
 
+// highlightMatches marks the matched characters of name, keeping the
+// colors of the row it is drawn in
+func (d *CommandPaletteDialog) highlightMatches(name string, matched []int, row lipgloss.Style) string {
+	if len(matched) == 0 {
+		return name
+	}
+	plain := lipgloss.NewStyle().
+		Foreground(row.GetForeground()).
+		Background(row.GetBackground())
+	match := plain.Inherit(d.matchStyle)
+
+	var sb, run strings.Builder
+	next := 0
+	for i, r := range name {
+		if next < len(matched) && matched[next] == i {
+			sb.WriteString(plain.Render(run.String()))
+			run.Reset()
+			sb.WriteString(match.Render(string(r)))
+			next++
+			continue
+		}
+		run.WriteRune(r)
+	}
+	sb.WriteString(plain.Render(run.String()))
+	return sb.String()
+}
+
 // Open opens the dialog
 func (d *CommandPaletteDialog) Open() tea.Cmd {
 	// Reset search on open
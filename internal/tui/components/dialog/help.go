@@ -137,12 +137,14 @@ func (d *HelpDialog) renderCommands() string {
 	commands := [][]string{
 		{"/help", "Show this help message"},
 		{"/clear", "Clear all messages"},
+		{"/export md|html [path]", "Save the conversation as a document"},
 		{"/model", "Show current model"},
 		{"/model select", "Select a different model"},
 		{"/team", "Show current team"},
 		{"/team select", "Select a model team"},
 		{"/settings", "Open settings dialog"},
 		{"/debug", "Toggle debug mode"},
+		{"/debug export [path]", "Zip debug dumps, config and versions for a bug report"},
 		{"/quit or /exit", "Exit the application"},
 	}
 
@@ -162,8 +164,13 @@ func (d *HelpDialog) renderKeyboardShortcuts() string {
 	shortcuts := [][]string{
 		{"Ctrl+C", "Quit confirmation dialog"},
 		{"Ctrl+L", "Clear messages"},
-		{"Ctrl+P", "Open command palette"},
-		{"Tab", "Command completion"},
+		{"Ctrl+K", "Open command palette"},
+		{"Ctrl+B", "Browse knowledge files"},
+		{"Ctrl+E", "Write the message in $EDITOR"},
+		{"Alt+E", "Edit and resend last message"},
+		{"Ctrl+R", "Regenerate last reply"},
+		{"Alt+R", "Regenerate with the team's next model"},
+		{"Tab", "Command and argument completion"},
 		{"Esc", "Clear input / Close dialogs"},
 		{"↑/↓ or j/k", "Navigate in lists"},
 		{"Enter", "Send message / Select item"},
@@ -184,8 +191,9 @@ func (d *HelpDialog) renderKeyboardShortcuts() string {
 func (d *HelpDialog) renderTips() string {
 	tips := []string{
 		"• Start typing '/' to see available commands",
-		"• Press Tab after '/' for command completion",
-		"• Use Ctrl+P to quickly access any command",
+		"• Press Tab after '/' for command completion, or after a command to complete its arguments",
+		"• Use Ctrl+K to search commands, sessions and files",
+		"• Type @ and a file name to attach a project file to your message",
 		"• The sidebar shows your current model and session info",
 		"• Debug mode shows token counts and timing info",
 		"• Model teams let you switch between different model sizes",
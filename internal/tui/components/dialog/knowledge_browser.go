@@ -0,0 +1,389 @@
+package dialog
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/clipboard"
+	"github.com/billie-coop/loco/internal/tui/highlight"
+	"github.com/billie-coop/loco/internal/tui/styles"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// KnowledgeReader reads a knowledge file by its slash path relative to
+// .loco/knowledge
+type KnowledgeReader func(file string) (string, error)
+
+// knowledgeRow is one line of the file tree: a directory or a file
+type knowledgeRow struct {
+	file  string // Slash path for files, empty for directories
+	label string
+	depth int
+}
+
+// KnowledgeBrowserDialog shows the knowledge files as a tree next to a
+// preview of the selected one, with search and copy
+type KnowledgeBrowserDialog struct {
+	*BaseDialog
+
+	files    []string
+	read     KnowledgeReader
+	contents map[string]string // Files read so far, for search
+
+	rows     []knowledgeRow
+	selected int // Index into rows; always a file row when there is one
+
+	searching bool
+	query     string
+
+	preview     []string // Highlighted lines of the selected file
+	previewFile string
+	scroll      int
+	status      string
+
+	// Styling
+	treeStyle     lipgloss.Style
+	previewStyle  lipgloss.Style
+	dirStyle      lipgloss.Style
+	fileStyle     lipgloss.Style
+	selectedStyle lipgloss.Style
+	hintStyle     lipgloss.Style
+	searchStyle   lipgloss.Style
+}
+
+// NewKnowledgeBrowserDialog creates a new knowledge browser
+func NewKnowledgeBrowserDialog() *KnowledgeBrowserDialog {
+	theme := styles.CurrentTheme()
+
+	return &KnowledgeBrowserDialog{
+		BaseDialog: NewBaseDialog("Knowledge"),
+		contents:   make(map[string]string),
+
+		treeStyle: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(theme.Border).
+			Padding(0, 1),
+
+		previewStyle: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(theme.Border).
+			Background(theme.BgBaseLighter).
+			Padding(0, 1),
+
+		dirStyle: lipgloss.NewStyle().
+			Foreground(theme.Accent).
+			Bold(true),
+
+		fileStyle: lipgloss.NewStyle().
+			Foreground(theme.FgBase),
+
+		selectedStyle: lipgloss.NewStyle().
+			Background(theme.Primary).
+			Foreground(theme.FgInverted),
+
+		hintStyle: lipgloss.NewStyle().
+			Foreground(theme.FgMuted),
+
+		searchStyle: lipgloss.NewStyle().
+			Foreground(theme.Primary),
+	}
+}
+
+// SetFiles sets the knowledge files to browse and how to read them
+func (d *KnowledgeBrowserDialog) SetFiles(files []string, read KnowledgeReader) {
+	d.files = files
+	d.read = read
+	d.contents = make(map[string]string)
+	d.rebuild()
+}
+
+// Select moves the selection to file
+func (d *KnowledgeBrowserDialog) Select(file string) {
+	for i, row := range d.rows {
+		if row.file == file {
+			d.selected = i
+			d.loadPreview()
+			return
+		}
+	}
+}
+
+// Init initializes the dialog
+func (d *KnowledgeBrowserDialog) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (d *KnowledgeBrowserDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if !d.isOpen {
+		return d, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+
+	if d.searching {
+		switch keyMsg.String() {
+		case "esc":
+			d.searching = false
+			d.query = ""
+			d.rebuild()
+		case "enter":
+			d.searching = false
+		case "backspace":
+			if d.query != "" {
+				runes := []rune(d.query)
+				d.query = string(runes[:len(runes)-1])
+				d.rebuild()
+			}
+		case "space":
+			d.query += " "
+			d.rebuild()
+		case "up", "down":
+			d.moveSelection(keyMsg.String())
+		default:
+			if text := keyMsg.Key().Text; text != "" && keyMsg.Key().Mod&^tea.ModShift == 0 {
+				d.query += text
+				d.rebuild()
+			}
+		}
+		return d, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "ctrl+b":
+		return d, d.Close()
+	case "up", "k", "down", "j":
+		d.moveSelection(keyMsg.String())
+	case "/":
+		d.searching = true
+		d.status = ""
+	case "pgdown", "ctrl+d", "space":
+		d.scrollPreview(d.previewHeight() / 2)
+	case "pgup", "ctrl+u":
+		d.scrollPreview(-d.previewHeight() / 2)
+	case "home", "g":
+		d.scroll = 0
+	case "end", "G":
+		d.scrollPreview(len(d.preview))
+	case "c", "y":
+		d.copySelected()
+	}
+	return d, nil
+}
+
+// moveSelection selects the previous or next file in the tree
+func (d *KnowledgeBrowserDialog) moveSelection(key string) {
+	step := 1
+	if key == "up" || key == "k" {
+		step = -1
+	}
+	for i := d.selected + step; i >= 0 && i < len(d.rows); i += step {
+		if d.rows[i].file != "" {
+			d.selected = i
+			d.loadPreview()
+			return
+		}
+	}
+}
+
+// scrollPreview scrolls the preview by delta lines, within the file
+func (d *KnowledgeBrowserDialog) scrollPreview(delta int) {
+	d.scroll = max(0, min(d.scroll+delta, len(d.preview)-d.previewHeight()))
+}
+
+// copySelected copies the selected file's content to the clipboard
+func (d *KnowledgeBrowserDialog) copySelected() {
+	file := d.selectedFile()
+	if file == "" {
+		return
+	}
+	content, err := d.content(file)
+	if err == nil {
+		err = clipboard.Copy(content)
+	}
+	if err != nil {
+		d.status = "⚠️ " + err.Error()
+		return
+	}
+	d.status = "📋 Copied " + file
+}
+
+// selectedFile returns the selected file, or "" when the tree is empty
+func (d *KnowledgeBrowserDialog) selectedFile() string {
+	if d.selected < len(d.rows) {
+		return d.rows[d.selected].file
+	}
+	return ""
+}
+
+// content reads a file once and keeps it for search
+func (d *KnowledgeBrowserDialog) content(file string) (string, error) {
+	if content, ok := d.contents[file]; ok {
+		return content, nil
+	}
+	if d.read == nil {
+		return "", fmt.Errorf("no knowledge available")
+	}
+	content, err := d.read(file)
+	if err != nil {
+		return "", err
+	}
+	d.contents[file] = content
+	return content, nil
+}
+
+// rebuild lays out the tree of the files matching the search, keeping the
+// selection on the same file when it still matches
+func (d *KnowledgeBrowserDialog) rebuild() {
+	current := d.selectedFile()
+	query := strings.ToLower(strings.TrimSpace(d.query))
+
+	d.rows = nil
+	dir := ""
+	for _, file := range d.files {
+		if query != "" && !d.matches(file, query) {
+			continue
+		}
+		// Files come sorted, so each directory's files are together
+		if fileDir := path.Dir(file); fileDir != dir {
+			dir = fileDir
+			if dir != "." {
+				d.rows = append(d.rows, knowledgeRow{label: dir + "/"})
+			}
+		}
+		depth := 0
+		if dir != "." {
+			depth = 1
+		}
+		d.rows = append(d.rows, knowledgeRow{file: file, label: path.Base(file), depth: depth})
+	}
+
+	d.selected = 0
+	for i, row := range d.rows {
+		if row.file != "" && (row.file == current || d.rows[d.selected].file == "") {
+			d.selected = i
+			if row.file == current {
+				break
+			}
+		}
+	}
+	d.loadPreview()
+}
+
+// matches reports whether query is in a file's path or content
+func (d *KnowledgeBrowserDialog) matches(file, query string) bool {
+	if strings.Contains(strings.ToLower(file), query) {
+		return true
+	}
+	content, err := d.content(file)
+	return err == nil && strings.Contains(strings.ToLower(content), query)
+}
+
+// loadPreview highlights the selected file for the preview pane, scrolled to
+// the first search match
+func (d *KnowledgeBrowserDialog) loadPreview() {
+	file := d.selectedFile()
+	if file == d.previewFile && d.query == "" {
+		return
+	}
+	d.previewFile = file
+	d.preview = nil
+	d.scroll = 0
+	if file == "" {
+		return
+	}
+
+	content, err := d.content(file)
+	if err != nil {
+		d.preview = []string{"⚠️ " + err.Error()}
+		return
+	}
+	d.preview = highlight.Lines(content, file, styles.CurrentTheme().BgBaseLighter)
+
+	if query := strings.ToLower(strings.TrimSpace(d.query)); query != "" {
+		for i, line := range strings.Split(content, "\n") {
+			if strings.Contains(strings.ToLower(line), query) {
+				d.scrollPreview(i)
+				break
+			}
+		}
+	}
+}
+
+// previewHeight is how many preview lines fit
+func (d *KnowledgeBrowserDialog) previewHeight() int {
+	// Title, search line, hints and the borders of the dialog and panes
+	return max(d.Height-14, 5)
+}
+
+// View renders the dialog
+func (d *KnowledgeBrowserDialog) View() string {
+	if !d.isOpen {
+		return ""
+	}
+
+	width := max(d.Width-8, 40)
+	height := d.previewHeight()
+	treeWidth := min(max(width/3, 24), 40)
+	previewWidth := width - treeWidth
+
+	// File tree, scrolled to keep the selection visible
+	var tree []string
+	if len(d.rows) == 0 {
+		tree = append(tree, d.hintStyle.Render("No knowledge files"))
+	}
+	start := max(0, d.selected-height+1)
+	for i := start; i < len(d.rows) && i < start+height; i++ {
+		row := d.rows[i]
+		label := strings.Repeat("  ", row.depth) + row.label
+		label = ansi.Truncate(label, treeWidth-4, "…")
+		switch {
+		case row.file == "":
+			tree = append(tree, d.dirStyle.Render(label))
+		case i == d.selected:
+			tree = append(tree, d.selectedStyle.Width(treeWidth-4).Render(label))
+		default:
+			tree = append(tree, d.fileStyle.Render(label))
+		}
+	}
+	treePane := d.treeStyle.
+		Width(treeWidth).
+		Height(height + 2).
+		Render(strings.Join(tree, "\n"))
+
+	// Preview of the selected file
+	var preview []string
+	for i := d.scroll; i < len(d.preview) && i < d.scroll+height; i++ {
+		preview = append(preview, ansi.Truncate(d.preview[i], previewWidth-4, "…"))
+	}
+	previewPane := d.previewStyle.
+		Width(previewWidth).
+		Height(height + 2).
+		Render(strings.Join(preview, "\n"))
+
+	// Search line and key hints
+	search := d.hintStyle.Render("/ search")
+	if d.searching || d.query != "" {
+		search = d.searchStyle.Render("🔍 " + d.query)
+		if d.searching {
+			search += d.searchStyle.Render("▏")
+		}
+	}
+	hints := d.hintStyle.Render("↑/↓ select · PgUp/PgDn scroll · c copy · Esc close")
+	if d.status != "" {
+		hints = d.status
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		search,
+		lipgloss.JoinHorizontal(lipgloss.Top, treePane, previewPane),
+		hints,
+	)
+	return d.RenderDialog(content)
+}
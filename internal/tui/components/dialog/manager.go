@@ -20,6 +20,7 @@ const (
 	CommandPaletteDialogType DialogType = "command_palette"
 	HelpDialogType          DialogType = "help"
 	ThemeSwitcherDialogType DialogType = "theme_switcher"
+	KnowledgeBrowserDialogType DialogType = "knowledge_browser"
 )
 
 // Manager manages all dialogs in the application
@@ -49,6 +50,7 @@ func NewManager(eventBroker *events.Broker, toolRegistry *tools.Registry) *Manag
 	m.dialogs[CommandPaletteDialogType] = NewCommandPaletteDialog(eventBroker, toolRegistry)
 	m.dialogs[HelpDialogType] = NewHelpDialog(eventBroker)
 	m.dialogs[ThemeSwitcherDialogType] = NewThemeSwitcher()
+	m.dialogs[KnowledgeBrowserDialogType] = NewKnowledgeBrowserDialog()
 
 	return m
 }
@@ -176,6 +178,22 @@ func (m *Manager) SetTeams(teams []*session.ModelTeam) {
 	}
 }
 
+// SetPaletteEntries sets the sessions and files listed in the command palette
+func (m *Manager) SetPaletteEntries(entries []Command) {
+	if dialog, ok := m.dialogs[CommandPaletteDialogType].(*CommandPaletteDialog); ok {
+		dialog.SetEntries(entries)
+	}
+}
+
+// SetKnowledgeFiles sets the files shown in the knowledge browser and
+// selects file when it is one of them
+func (m *Manager) SetKnowledgeFiles(files []string, read KnowledgeReader, file string) {
+	if dialog, ok := m.dialogs[KnowledgeBrowserDialogType].(*KnowledgeBrowserDialog); ok {
+		dialog.SetFiles(files, read)
+		dialog.Select(file)
+	}
+}
+
 // SetSettings updates the settings dialog with current settings
 func (m *Manager) SetSettings(settings *Settings) {
 	if dialog, ok := m.dialogs[SettingsDialogType].(*SettingsDialog); ok {
@@ -5,11 +5,36 @@ import (
 	"strings"
 
 	"github.com/billie-coop/loco/internal/tui/events"
+	"github.com/billie-coop/loco/internal/tui/highlight"
 	"github.com/billie-coop/loco/internal/tui/styles"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 )
 
+// The diff preview's size: it fills the screen height the options leave
+// free and scrolls the rest
+const (
+	permissionPreviewLines = 12 // Until the screen size is known
+	permissionPreviewWidth = 120
+)
+
+// permissionOption is one choice in the dialog
+type permissionOption struct {
+	key      string
+	label    string
+	decision string
+}
+
+// permissionOptions are the dialog's choices in display order
+var permissionOptions = []permissionOption{
+	{"y", " [Y] Approve once ", "approve"},
+	{"n", " [N] Deny ", "deny"},
+	{"m", " [M] Modify (deny and say what to change) ", "modify"},
+	{"a", " [A] Always allow ", "always"},
+	{"d", " [D] Never allow ", "never"},
+}
+
 // PermissionsDialog asks user to approve or deny tool execution
 type PermissionsDialog struct {
 	*BaseDialog
@@ -17,10 +42,14 @@ type PermissionsDialog struct {
 	toolName       string
 	toolArgs       map[string]interface{}
 	requestID      string
-	decision       string // "approve", "deny", "always", "never"
-	selectedOption int    // 0=Approve, 1=Deny, 2=Always, 3=Never
+	decision       string // "approve", "deny", "modify", "always", "never"
+	selectedOption int    // Index into options()
 	eventBroker    *events.Broker
 
+	diffLines   []string // Highlighted diff of a file write
+	diffScroll  int
+	diffVisible int // Diff lines that fit on the last render
+
 	// Styling
 	toolStyle     lipgloss.Style
 	argsStyle     lipgloss.Style
@@ -65,6 +94,43 @@ func (d *PermissionsDialog) SetToolRequest(toolName string, args map[string]inte
 	d.toolArgs = args
 	d.requestID = requestID
 	d.decision = ""
+	d.selectedOption = 0
+	d.diffLines = nil
+	d.diffScroll = 0
+
+	if diff, ok := args["diff"].(string); ok && diff != "" {
+		diff = strings.ReplaceAll(diff, "\r\n", "\n")
+		diff = strings.ReplaceAll(diff, "\t", "    ")
+		d.diffLines = highlight.Diff(strings.TrimRight(diff, "\n"), styles.CurrentTheme().BgBaseLighter)
+	}
+}
+
+// options returns the choices on offer; Modify only applies to file writes.
+// Always allow only covers the request's command, path or host, and names it.
+func (d *PermissionsDialog) options() []permissionOption {
+	var options []permissionOption
+	for _, option := range permissionOptions {
+		switch option.decision {
+		case "modify":
+			if len(d.diffLines) == 0 {
+				continue
+			}
+		case "always":
+			scope := d.scope()
+			if scope == "" {
+				continue
+			}
+			option.label = fmt.Sprintf(" [A] Always allow %s ", ansi.Truncate(strings.Join(strings.Fields(scope), " "), 40, "…"))
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+// scope returns what Always allow covers
+func (d *PermissionsDialog) scope() string {
+	scope, _ := d.toolArgs["scope"].(string)
+	return scope
 }
 
 // Init initializes the dialog
@@ -80,45 +146,31 @@ func (d *PermissionsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
+		options := d.options()
+		switch key := msg.String(); key {
 		case "up", "k":
-			d.selectedOption = (d.selectedOption + 3) % 4
-			return d, nil
+			d.selectedOption = (d.selectedOption + len(options) - 1) % len(options)
 		case "down", "j", "tab":
-			d.selectedOption = (d.selectedOption + 1) % 4
-			return d, nil
+			d.selectedOption = (d.selectedOption + 1) % len(options)
+		case "pgdown", "ctrl+d":
+			d.scrollDiff(max(d.diffVisible/2, 1))
+		case "pgup", "ctrl+u":
+			d.scrollDiff(-max(d.diffVisible/2, 1))
 		case "esc":
 			d.decision = "deny"
 			return d, d.publishDecision()
-		case "y", "Y":
-			d.selectedOption = 0
-			d.decision = "approve"
-			return d, d.publishDecision()
-		case "n", "N":
-			d.selectedOption = 1
-			d.decision = "deny"
-			return d, d.publishDecision()
-		case "a", "A":
-			d.selectedOption = 2
-			d.decision = "always"
-			return d, d.publishDecision()
-		case "d", "D":
-			d.selectedOption = 3
-			d.decision = "never"
-			return d, d.publishDecision()
-		case "enter", " ":
+		case "enter", " ", "space":
 			// Select based on current selection
-			switch d.selectedOption {
-			case 0:
-				d.decision = "approve"
-			case 1:
-				d.decision = "deny"
-			case 2:
-				d.decision = "always"
-			case 3:
-				d.decision = "never"
-			}
+			d.decision = options[d.selectedOption].decision
 			return d, d.publishDecision()
+		default:
+			for i, option := range options {
+				if strings.EqualFold(key, option.key) {
+					d.selectedOption = i
+					d.decision = option.decision
+					return d, d.publishDecision()
+				}
+			}
 		}
 	}
 
@@ -132,66 +184,107 @@ func (d *PermissionsDialog) View() string {
 	}
 
 	theme := styles.CurrentTheme()
-	var content strings.Builder
+	var header strings.Builder
 
 	// Tool name (no duplicate header since BaseDialog has title)
-	content.WriteString("Tool requesting permission:\n")
-	content.WriteString(d.toolStyle.Render(fmt.Sprintf("  🔧 %s", d.toolName)) + "\n\n")
+	header.WriteString("Tool requesting permission:\n")
+	header.WriteString(d.toolStyle.Render(fmt.Sprintf("  🔧 %s", d.toolName)) + "\n")
 
 	// Arguments - display in a consistent order
 	if len(d.toolArgs) > 0 {
+		header.WriteString("\n")
 		// Extract specific fields we know about
 		if action, ok := d.toolArgs["action"].(string); ok && action != "" {
-			content.WriteString("Action: ")
-			content.WriteString(theme.S().Info.Render(action) + "\n")
+			header.WriteString("Action: ")
+			header.WriteString(theme.S().Info.Render(action) + "\n")
 		}
 		if path, ok := d.toolArgs["path"].(string); ok && path != "" {
-			content.WriteString("Path: ")
-			content.WriteString(theme.S().Muted.Render(path) + "\n")
+			header.WriteString("Path: ")
+			header.WriteString(theme.S().Muted.Render(path) + "\n")
 		}
 		if desc, ok := d.toolArgs["description"].(string); ok && desc != "" {
-			content.WriteString("Description: ")
-			content.WriteString(theme.S().Text.Render(desc) + "\n")
+			header.WriteString("Description: ")
+			header.WriteString(theme.S().Text.Render(desc) + "\n")
 		}
-		content.WriteString("\n")
 	}
 
+	var footer strings.Builder
+
 	// Security warning for certain tools
 	if d.isHighRiskTool() {
-		content.WriteString(d.warningStyle.Render("⚠️  This tool can modify files on your system!") + "\n\n")
+		footer.WriteString(d.warningStyle.Render("⚠️  This tool can modify files on your system!") + "\n\n")
 	}
 
 	// Options - properly formatted as buttons (vertical layout)
-	content.WriteString("Choose an action:\n\n")
-	
-	// Create button-like appearance for options
-	buttons := []string{
-		fmt.Sprintf(" [Y] Approve once "),
-		fmt.Sprintf(" [N] Deny "),
-		fmt.Sprintf(" [A] Always allow "),
-		fmt.Sprintf(" [D] Never allow "),
-	}
-	
-	for i, button := range buttons {
+	footer.WriteString("Choose an action:\n\n")
+	for i, option := range d.options() {
 		if i == d.selectedOption {
-			content.WriteString(d.selectedStyle.Render("▶ " + button))
+			footer.WriteString(d.selectedStyle.Render("▶ " + option.label))
 		} else {
-			content.WriteString(d.optionStyle.Render("  " + button))
+			footer.WriteString(d.optionStyle.Render("  " + option.label))
 		}
-		content.WriteString("\n")
+		footer.WriteString("\n")
 	}
-	
-	content.WriteString("\n")
-	content.WriteString(theme.S().Subtle.Render("↵ Enter to select • Esc to cancel"))
 
-	return d.RenderDialog(content.String())
+	content := strings.TrimRight(header.String(), "\n")
+	if len(d.diffLines) > 0 {
+		// The dialog's border, padding and title take 6 lines; the blank
+		// lines around the diff, its scroll position and the hint 4 more
+		height := permissionPreviewLines
+		if _, screenHeight := d.GetSize(); screenHeight > 0 {
+			height = screenHeight - 10 - lipgloss.Height(content) - lipgloss.Height(footer.String())
+		}
+		content += "\n\n" + d.renderDiff(max(height, 3))
+	}
+
+	hint := "↵ Enter to select • Esc to cancel"
+	if len(d.diffLines) > d.diffVisible {
+		hint += " • PgUp/PgDn to scroll the diff"
+	}
+	content += "\n\n" + footer.String() + "\n" + theme.S().Subtle.Render(hint)
+
+	return d.RenderDialog(content)
+}
+
+// renderDiff shows the visible part of a write's diff, colored by the theme,
+// with the scroll position below it
+func (d *PermissionsDialog) renderDiff(height int) string {
+	theme := styles.CurrentTheme()
+
+	width := permissionPreviewWidth
+	if screenWidth, _ := d.GetSize(); screenWidth > 0 {
+		// Leave room for the dialog's border and padding and the overlay
+		width = min(width, screenWidth-8)
+	}
+
+	d.diffVisible = min(height, len(d.diffLines))
+	d.scrollDiff(0)
+
+	lineStyle := lipgloss.NewStyle().
+		Background(theme.BgBaseLighter).
+		Width(width)
+	lines := make([]string, 0, d.diffVisible+1)
+	for _, line := range d.diffLines[d.diffScroll : d.diffScroll+d.diffVisible] {
+		lines = append(lines, lineStyle.Render(ansi.Truncate(line, width, "…")))
+	}
+	lines = append(lines, theme.S().Subtle.Render(fmt.Sprintf("lines %d–%d of %d",
+		d.diffScroll+1, d.diffScroll+d.diffVisible, len(d.diffLines))))
+	return strings.Join(lines, "\n")
+}
+
+// scrollDiff moves the diff by delta lines, keeping it within the diff
+func (d *PermissionsDialog) scrollDiff(delta int) {
+	d.diffScroll = max(0, min(d.diffScroll+delta, len(d.diffLines)-d.diffVisible))
 }
 
 func (d *PermissionsDialog) isHighRiskTool() bool {
 	highRiskTools := []string{
 		"write_file",
 		"write",
+		"edit_file",
+		"multi_edit",
 		"delete_file",
+		"bash",
 		"execute_command",
 		"run_command",
 	}
@@ -222,6 +315,7 @@ func (d *PermissionsDialog) publishDecision() tea.Cmd {
 				ToolName: d.toolName,
 				Args:     d.toolArgs,
 				ID:       d.requestID,
+				Decision: d.decision,
 			},
 		})
 
@@ -230,7 +324,7 @@ func (d *PermissionsDialog) publishDecision() tea.Cmd {
 			d.eventBroker.PublishAsync(events.Event{
 				Type: events.StatusMessageEvent,
 				Payload: events.StatusMessagePayload{
-					Message: fmt.Sprintf("Tool '%s' will be automatically approved", d.toolName),
+					Message: fmt.Sprintf("Tool '%s' will be automatically approved for %s (saved to permission_grants)", d.toolName, d.scope()),
 					Type:    "info",
 				},
 			})
@@ -238,7 +332,7 @@ func (d *PermissionsDialog) publishDecision() tea.Cmd {
 			d.eventBroker.PublishAsync(events.Event{
 				Type: events.StatusMessageEvent,
 				Payload: events.StatusMessagePayload{
-					Message: fmt.Sprintf("Tool '%s' will be automatically denied", d.toolName),
+					Message: fmt.Sprintf("Tool '%s' will be automatically denied (saved to tool_policies)", d.toolName),
 					Type:    "warning",
 				},
 			})
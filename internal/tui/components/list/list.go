@@ -28,12 +28,16 @@ type List[T Item] interface {
 	MoveDown(int) tea.Cmd
 	GoToTop() tea.Cmd
 	GoToBottom() tea.Cmd
+	ScrollOffset() int
 	
 	// Content management
 	SetItems([]T) tea.Cmd
 	AppendItem(T) tea.Cmd
 	UpdateItem(string, T) tea.Cmd
 	Items() []T
+
+	// Hit testing
+	ItemAt(y int) (T, bool)
 }
 
 type direction int
@@ -306,6 +310,14 @@ func (l *list[T]) GoToBottom() tea.Cmd {
 	return l.render()
 }
 
+// ScrollOffset returns how many lines the view is scrolled up from the bottom
+func (l *list[T]) ScrollOffset() int {
+	if l.direction == DirectionBackward {
+		return l.offset
+	}
+	return max(0, lipgloss.Height(l.rendered)-l.height-l.offset)
+}
+
 func (l *list[T]) incrementOffset(n int) {
 	renderedHeight := lipgloss.Height(l.rendered)
 	if renderedHeight <= l.height {
@@ -390,6 +402,24 @@ func (l *list[T]) Items() []T {
 	return l.items.All()
 }
 
+// ItemAt returns the item shown on line y of the view, if any; lines in
+// the gap between items belong to none
+func (l *list[T]) ItemAt(y int) (T, bool) {
+	var zero T
+	start, end := l.viewPosition()
+	line := start + y
+	if y < 0 || line > end {
+		return zero, false
+	}
+	for _, item := range l.items.All() {
+		rItem, ok := l.renderedItems.Get(item.ID())
+		if ok && line >= rItem.start && line <= rItem.end {
+			return item, true
+		}
+	}
+	return zero, false
+}
+
 // Size management
 
 func (l *list[T]) GetSize() (int, int) {
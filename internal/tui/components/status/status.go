@@ -2,11 +2,13 @@ package status
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/billie-coop/loco/internal/tui/styles"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // MessageType represents the type of status message
@@ -26,11 +28,32 @@ type StatusMessage struct {
 	Timestamp time.Time
 }
 
+// ModelState is whether LM Studio has a model ready
+type ModelState string
+
+const (
+	ModelUnknown ModelState = ""
+	ModelLoading ModelState = "loading"
+	ModelLoaded  ModelState = "loaded"
+	ModelError   ModelState = "error"
+)
+
+// Activity is the background work shown on the left of the status bar
+type Activity struct {
+	Queued     int      // Background LLM requests waiting in the queue
+	Running    int      // Background LLM requests running
+	Tiers      []string // Analysis tiers running, e.g. "quick"
+	Requests   int      // Chat requests to LM Studio in flight
+	Model      string
+	ModelState ModelState
+}
+
 // Component implements a status bar that shows temporary messages
 type Component struct {
 	message     *StatusMessage
 	width       int
 	leftContent string
+	activity    Activity
 	
 	// Timer for clearing messages
 	clearAfter time.Duration
@@ -82,6 +105,11 @@ func (c *Component) SetLeftContent(content string) {
 	c.leftContent = content
 }
 
+// SetActivity sets the background work shown on the left
+func (c *Component) SetActivity(activity Activity) {
+	c.activity = activity
+}
+
 // SetSize implements the Sizeable interface
 func (c *Component) SetSize(width, height int) tea.Cmd {
 	c.width = width
@@ -128,7 +156,10 @@ func (c *Component) View() string {
 		Padding(0, 1)
 	
 	// Prepare left and right content
-	leftContent := c.leftContent
+	leftContent := c.formatActivity()
+	if c.leftContent != "" {
+		leftContent = strings.TrimSpace(c.leftContent + "  " + leftContent)
+	}
 	rightContent := ""
 	
 	// Add status message to right side if present
@@ -140,14 +171,14 @@ func (c *Component) View() string {
 	availableWidth := c.width - 2 // Account for padding
 	
 	// Truncate content if necessary
-	if len(leftContent)+len(rightContent) > availableWidth {
-		if len(rightContent) > 40 {
-			rightContent = rightContent[:37] + "..."
+	if lipgloss.Width(leftContent)+lipgloss.Width(rightContent) > availableWidth {
+		if lipgloss.Width(rightContent) > 40 {
+			rightContent = ansi.Truncate(rightContent, 40, "...")
 		}
 		
-		remaining := availableWidth - len(rightContent)
-		if len(leftContent) > remaining && remaining > 3 {
-			leftContent = leftContent[:remaining-3] + "..."
+		remaining := availableWidth - lipgloss.Width(rightContent) - 1
+		if lipgloss.Width(leftContent) > remaining && remaining > 3 {
+			leftContent = ansi.Truncate(leftContent, remaining, "...")
 		}
 	}
 	
@@ -155,7 +186,7 @@ func (c *Component) View() string {
 	content := leftContent
 	if rightContent != "" {
 		// Calculate spacing to right-align the status message
-		spacesNeeded := availableWidth - len(leftContent) - len(rightContent)
+		spacesNeeded := availableWidth - lipgloss.Width(leftContent) - lipgloss.Width(rightContent)
 		if spacesNeeded > 0 {
 			content += fmt.Sprintf("%*s%s", spacesNeeded, "", rightContent)
 		} else {
@@ -166,6 +197,35 @@ func (c *Component) View() string {
 	return statusStyle.Render(content)
 }
 
+// formatActivity lists the queue, running analysis tiers, LLM requests in
+// flight and the model's state, leaving out what is idle
+func (c *Component) formatActivity() string {
+	a := c.activity
+	var parts []string
+	if a.Queued > 0 || a.Running > 0 {
+		parts = append(parts, fmt.Sprintf("⏳ %d queued, %d running", a.Queued, a.Running))
+	}
+	if len(a.Tiers) > 0 {
+		parts = append(parts, "🔬 "+strings.Join(a.Tiers, ", "))
+	}
+	if a.Requests > 0 {
+		parts = append(parts, fmt.Sprintf("⚡ %d LLM", a.Requests))
+	}
+	if a.Model != "" {
+		switch a.ModelState {
+		case ModelLoading:
+			parts = append(parts, "◌ loading "+a.Model+"…")
+		case ModelLoaded:
+			parts = append(parts, "● "+a.Model)
+		case ModelError:
+			parts = append(parts, "✗ "+a.Model)
+		default:
+			parts = append(parts, "○ "+a.Model+" not loaded")
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
 // formatMessage formats the status message with appropriate styling
 func (c *Component) formatMessage() string {
 	if c.message == nil {
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"runtime/debug"
+
+	"github.com/billie-coop/loco/internal/crash"
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// guardedModel writes a crash bundle when the model or one of its commands
+// panics. The panic is then re-raised so Bubble Tea restores the terminal
+// and Run returns tea.ErrProgramPanic.
+type guardedModel struct {
+	model tea.Model
+}
+
+// Guard wraps a model with crash reporting
+func Guard(model tea.Model) tea.Model {
+	return guardedModel{model: model}
+}
+
+func (g guardedModel) Init() tea.Cmd {
+	defer capturePanic("tui init")
+	return guardCmd(g.model.Init())
+}
+
+func (g guardedModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer capturePanic("tui update")
+	model, cmd := g.model.Update(msg)
+	return guardedModel{model: model}, guardCmd(cmd)
+}
+
+func (g guardedModel) View() string {
+	defer capturePanic("tui view")
+	if view, ok := g.model.(tea.ViewModel); ok {
+		return view.View()
+	}
+	return ""
+}
+
+// guardCmd wraps a command, and the commands of a batch it returns, so
+// their panics are reported too
+func guardCmd(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		defer capturePanic("tui command")
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			guarded := make(tea.BatchMsg, len(batch))
+			for i, c := range batch {
+				guarded[i] = guardCmd(c)
+			}
+			return guarded
+		}
+		return msg
+	}
+}
+
+// capturePanic must be deferred directly. It writes a bundle and re-panics.
+func capturePanic(where string) {
+	if r := recover(); r != nil {
+		_, _ = crash.Capture(where, r, debug.Stack())
+		panic(r)
+	}
+}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	chatcore "github.com/billie-coop/loco/internal/chat"
@@ -10,6 +11,7 @@ import (
 	"github.com/billie-coop/loco/internal/tools"
 	"github.com/billie-coop/loco/internal/tui/components/chat"
 	"github.com/billie-coop/loco/internal/tui/components/dialog"
+	"github.com/billie-coop/loco/internal/tui/components/status"
 	"github.com/billie-coop/loco/internal/tui/events"
 	tea "github.com/charmbracelet/bubbletea/v2"
 )
@@ -42,6 +44,13 @@ func (m *Model) handleEvent(event events.Event) (tea.Model, tea.Cmd) {
 			m.syncMessagesToComponents()
 			m.showStatus("Sending message...")
 
+			// Save to session, so later turns and exports have it
+			if m.app.Sessions != nil {
+				if err := m.app.Sessions.AddMessage(payload.Message); err != nil {
+					m.showStatus("⚠️ Failed to save message: " + err.Error())
+				}
+			}
+
 			// Set streaming state
 			m.isStreaming = true
 			m.streamingMessage = ""
@@ -63,6 +72,17 @@ func (m *Model) handleEvent(event events.Event) (tea.Model, tea.Cmd) {
 			m.messageList.SetStreamingState(true, m.streamingMessage)
 		}
 
+	case events.ToolCallDetectedEvent:
+		// Surface tool calls the model writes into its reply. Nothing runs them;
+		// the status only reports what was asked for.
+		if payload, ok := event.Payload.(events.ToolDetectedPayload); ok {
+			if payload.Complete {
+				m.showStatus("🔧 Model requested " + payload.Summary)
+			} else {
+				m.showStatus("🔧 Model is requesting " + payload.Summary)
+			}
+		}
+
 	case events.StreamEndEvent:
 		// Handle stream end
 		// Note: The assistant message is now added via AssistantMessageEvent
@@ -225,6 +245,7 @@ func (m *Model) handleEvent(event events.Event) (tea.Model, tea.Cmd) {
 			m.analysisState.IsRunning = true
 			m.analysisState.CurrentPhase = payload.Phase
 			m.analysisState.StartTime = time.Now()
+			m.setTierRunning(payload.Phase, true)
 			m.analysisState.TotalFiles = payload.TotalFiles
 			m.lastProgress = time.Now()
 
@@ -238,10 +259,14 @@ func (m *Model) handleEvent(event events.Event) (tea.Model, tea.Cmd) {
 				m.analysisState.DetailedRunning = true
 				m.showStatus("📊 Reading key files…")
 				m.updateToolProgress("analyze", "running", "Reading key files…", "")
-			case "deep", "full":
+			case "deep":
 				m.analysisState.KnowledgeRunning = true
 				m.showStatus("💎 Deep analysis started…")
 				m.updateToolProgress("analyze", "running", "Deep analysis started…", "")
+			case "full":
+				m.analysisState.KnowledgeRunning = true
+				m.showStatus("🚀 Full analysis started…")
+				m.updateToolProgress("analyze", "running", "Full analysis started…", "")
 			}
 
 			// IMPORTANT: Start the timer for analysis feedback
@@ -268,7 +293,11 @@ func (m *Model) handleEvent(event events.Event) (tea.Model, tea.Cmd) {
 			m.sidebar.SetAnalysisState(m.analysisState)
 
 			// Show richer status
-			if payload.Phase == "quick" {
+			if strings.HasPrefix(payload.CurrentFile, "modules/") && payload.CompletedFiles > 0 {
+				msg := fmt.Sprintf("Documenting modules %d/%d: %s", payload.CompletedFiles, payload.TotalFiles, strings.TrimPrefix(payload.CurrentFile, "modules/"))
+				m.showStatus("🗂️ " + msg)
+				m.updateToolProgress("analyze", "running", msg, "")
+			} else if payload.Phase == "quick" {
 				if payload.CompletedFiles == 0 && payload.TotalFiles > 0 && payload.CurrentFile == "discovered files" {
 					m.showStatus(fmt.Sprintf("🔎 Discovered %d files", payload.TotalFiles))
 					m.updateToolProgress("analyze", "running", fmt.Sprintf("Discovered %d files", payload.TotalFiles), "")
@@ -283,6 +312,9 @@ func (m *Model) handleEvent(event events.Event) (tea.Model, tea.Cmd) {
 					m.showStatus("📖 " + msg)
 					m.updateToolProgress("analyze", "running", msg, "")
 				}
+			} else if payload.Phase == "full" {
+				m.showStatus("🚀 " + payload.CurrentFile)
+				m.updateToolProgress("analyze", "running", payload.CurrentFile, "")
 			}
 		}
 
@@ -297,6 +329,7 @@ func (m *Model) handleEvent(event events.Event) (tea.Model, tea.Cmd) {
 			m.analysisState.IsRunning = false
 			m.analysisState.CurrentPhase = "complete"
 			m.lastProgress = time.Now()
+			m.setTierRunning(payload.Phase, false)
 
 			// Mark appropriate tier as complete (keep previous completions)
 			switch payload.Phase {
@@ -305,12 +338,17 @@ func (m *Model) handleEvent(event events.Event) (tea.Model, tea.Cmd) {
 			case "detailed":
 				m.analysisState.DetailedCompleted = true
 				m.analysisState.DetailedRunning = false
-			case "deep", "full":
+			case "deep":
+				m.analysisState.DeepCompleted = true
+				m.analysisState.KnowledgeRunning = false
+			case "full":
 				m.analysisState.DeepCompleted = true
+				m.analysisState.FullCompleted = true
 				m.analysisState.KnowledgeRunning = false
 			}
 
 			m.sidebar.SetAnalysisState(m.analysisState)
+			m.refreshKnowledgeFiles()
 			m.showStatus("✨ Analysis complete!")
 			m.updateToolProgress("analyze", "complete", "Analysis complete", "")
 		}
@@ -325,12 +363,65 @@ func (m *Model) handleEvent(event events.Event) (tea.Model, tea.Cmd) {
 				m.analysisState.KnowledgeRunning = false
 				m.sidebar.SetAnalysisState(m.analysisState)
 			}
+			m.activity.Tiers = nil
+			m.statusBar.SetActivity(m.activity)
 
 			// Show error
 			m.showStatus("❌ Analysis failed: " + payload.Message)
 			m.updateToolProgress("analyze", "error", payload.Message, "")
 		}
 
+	case events.RAGIndexProgressEvent:
+		// Background re-indexing of files changed on disk
+		if payload, ok := event.Payload.(events.RAGIndexProgressPayload); ok {
+			m.sidebar.SetIndexState(&chat.IndexState{
+				IsRunning:   !payload.Done,
+				Total:       payload.Total,
+				Completed:   payload.Completed,
+				Failed:      payload.Failed,
+				CurrentFile: payload.CurrentFile,
+			})
+			if payload.Done && payload.Failed > 0 {
+				m.showStatus(fmt.Sprintf("⚠️ Re-indexed %d files, %d failed", payload.Total, payload.Failed))
+			}
+		}
+
+	case events.WorkerConcurrency.Type:
+		// Analysis workers scaled with LM Studio's health
+		if payload, ok := events.WorkerConcurrency.Payload(event); ok {
+			m.sidebar.SetWorkerConcurrency(payload.Level, payload.Max, payload.Reason)
+		}
+
+	case events.LLMQueue.Type:
+		// Background LLM requests queued and running
+		if payload, ok := events.LLMQueue.Payload(event); ok {
+			m.activity.Queued = payload.Pending
+			m.activity.Running = payload.Running
+			m.statusBar.SetActivity(m.activity)
+		}
+
+	case events.LLMRequests.Type:
+		// Chat requests to LM Studio in flight
+		if payload, ok := events.LLMRequests.Payload(event); ok {
+			m.activity.Requests = payload.Active
+			m.statusBar.SetActivity(m.activity)
+		}
+
+	case events.ModelLoading.Type:
+		if payload, ok := events.ModelLoading.Payload(event); ok {
+			m.setModelState(payload.ModelID, status.ModelLoading)
+		}
+
+	case events.ModelLoaded.Type:
+		if payload, ok := events.ModelLoaded.Payload(event); ok {
+			m.setModelState(payload.ModelID, status.ModelLoaded)
+		}
+
+	case events.ModelError.Type:
+		if payload, ok := events.ModelError.Payload(event); ok {
+			m.setModelState(payload.ModelID, status.ModelError)
+		}
+
 	case events.DialogOpenEvent:
 		// The dialog manager handles opening; nothing else to do
 
@@ -378,24 +469,41 @@ func (m *Model) handleEvent(event events.Event) (tea.Model, tea.Cmd) {
 		}
 
 	case events.ToolExecutionApprovedEvent, events.ToolExecutionDeniedEvent:
-		// These events are handled by the permission service's listener
-		// No need to handle them here
+		// The permission service's listener answers the tool; asking for a
+		// different change also starts the follow-up message here
+		if payload, ok := event.Payload.(events.ToolExecutionPayload); ok && payload.Decision == permission.DecisionModify {
+			path, _ := payload.Args["path"].(string)
+			m.input.SetValue(fmt.Sprintf("Don't write %s like that. Instead, ", path))
+			m.input.CursorEnd()
+			cmds = append(cmds, m.input.Focus())
+		}
 
-	case "permission.request":
+	case permission.RequestTopic.Type:
 		// Handle permission request from permission service
-		// Try to handle as struct first (direct from service)
-		if reqEvent, ok := event.Payload.(permission.PermissionRequestEvent); ok {
+		if reqEvent, ok := permission.RequestTopic.Payload(event); ok {
 			// Set the request in the dialog
-			m.dialogManager.SetToolRequest(reqEvent.Request.ToolName, map[string]interface{}{
+			args := map[string]interface{}{
 				"action":      reqEvent.Request.Action,
 				"path":        reqEvent.Request.Path,
 				"description": reqEvent.Request.Description,
-			}, reqEvent.ID)
+				"scope":       reqEvent.Request.GrantScope(),
+			}
+			// File writes show what they change before they are approved
+			if reqEvent.Request.Diff != "" {
+				args["diff"] = reqEvent.Request.Diff
+			}
+			m.dialogManager.SetToolRequest(reqEvent.Request.ToolName, args, reqEvent.ID)
 
 			// Open the permissions dialog
 			cmds = append(cmds, m.dialogManager.OpenDialog(dialog.PermissionsDialogType))
 		}
 
+	case events.CommandSelectedEvent:
+		// Run whatever was picked in the command palette
+		if payload, ok := event.Payload.(events.CommandSelectedPayload); ok {
+			cmds = append(cmds, m.runPaletteEntry(dialog.PaletteKind(payload.Kind), payload.Command))
+		}
+
 	case events.ModelSelectedEvent:
 		// Apply selected model to client and sidebar
 		if payload, ok := event.Payload.(events.ModelSelectedPayload); ok {
@@ -404,6 +512,7 @@ func (m *Model) handleEvent(event events.Event) (tea.Model, tea.Cmd) {
 			}
 			// Update sidebar display
 			m.sidebar.SetModel(payload.ModelID, payload.ModelSize)
+			m.showModelState()
 			m.showStatus("Model selected: " + payload.ModelID)
 		}
 	}
@@ -1,7 +1,10 @@
 package events
 
 import (
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Subscriber receives events from the broker
@@ -9,82 +12,162 @@ type Subscriber interface {
 	HandleEvent(event Event) bool // returns true if event was handled
 }
 
+// Backpressure decides what Publish does when a subscriber's buffer is full
+type Backpressure int
+
+const (
+	// DropNewest discards the event being published (the default)
+	DropNewest Backpressure = iota
+	// DropOldest discards the oldest buffered event to make room
+	DropOldest
+	// Block waits up to BlockTimeout for room, then drops the event; a
+	// negative BlockTimeout waits as long as it takes
+	Block
+)
+
+func (p Backpressure) String() string {
+	switch p {
+	case DropOldest:
+		return "drop-oldest"
+	case Block:
+		return "block"
+	}
+	return "drop-newest"
+}
+
+// defaultBlockTimeout bounds how long Publish waits on a Block subscriber
+const defaultBlockTimeout = 250 * time.Millisecond
+
+// historySize is how many recent events the broker keeps for /events
+const historySize = 200
+
+// SubscribeOptions configures a subscription
+type SubscribeOptions struct {
+	Name         string        // Shown in /events
+	Buffer       int           // Channel capacity; 0 means the broker default
+	Backpressure Backpressure  // What to do when the buffer is full
+	BlockTimeout time.Duration // For Block; 0 means 250ms, negative means no limit
+}
+
+// subscription is one subscriber channel and the patterns it listens to
+type subscription struct {
+	ch        chan Event
+	patterns  []EventType
+	opts      SubscribeOptions
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// SubscriptionInfo describes a subscription for debugging
+type SubscriptionInfo struct {
+	Name         string
+	Patterns     []EventType
+	Buffered     int
+	Capacity     int
+	Backpressure Backpressure
+	Delivered    uint64
+	Dropped      uint64
+}
+
+// Record is a published event as kept in the broker's history
+type Record struct {
+	Time     time.Time
+	Event    Event
+	Mismatch bool // Payload type differs from the registered topic's
+}
+
 // Broker manages event distribution
 type Broker struct {
-	subscribers map[EventType][]chan Event
+	subscribers []*subscription
 	mu          sync.RWMutex
 	bufferSize  int
+
+	historyMu sync.Mutex
+	history   []Record // Ring buffer of the last historySize events
+	next      int
+	counts    map[EventType]uint64
 }
 
 // NewBroker creates a new event broker
 func NewBroker() *Broker {
 	return &Broker{
-		subscribers: make(map[EventType][]chan Event),
-		bufferSize:  10,
+		bufferSize: 10,
+		counts:     make(map[EventType]uint64),
 	}
 }
 
-// Subscribe creates a subscription to specific event types
+// Subscribe creates a subscription to specific event types. A type ending in
+// ".*" matches every type under that prefix ("tool.*" matches "tool.result");
+// no types, or "*", subscribes to everything.
 func (b *Broker) Subscribe(eventTypes ...EventType) <-chan Event {
+	return b.SubscribeWith(SubscribeOptions{}, eventTypes...)
+}
+
+// SubscribeWith is Subscribe with a name, buffer size and backpressure policy
+func (b *Broker) SubscribeWith(opts SubscribeOptions, eventTypes ...EventType) <-chan Event {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	ch := make(chan Event, b.bufferSize)
+	if opts.Buffer <= 0 {
+		opts.Buffer = b.bufferSize
+	}
+	if opts.BlockTimeout == 0 {
+		opts.BlockTimeout = defaultBlockTimeout
+	}
 
 	// If no specific types provided, subscribe to all
 	if len(eventTypes) == 0 {
 		eventTypes = []EventType{"*"} // wildcard
 	}
 
-	for _, eventType := range eventTypes {
-		b.subscribers[eventType] = append(b.subscribers[eventType], ch)
+	sub := &subscription{
+		ch:       make(chan Event, opts.Buffer),
+		patterns: append([]EventType(nil), eventTypes...),
+		opts:     opts,
 	}
-
-	return ch
+	b.subscribers = append(b.subscribers, sub)
+	return sub.ch
 }
 
-// Unsubscribe removes a subscription
+// Unsubscribe removes a subscription, or only some of its patterns. The
+// channel is closed once it listens to nothing.
 func (b *Broker) Unsubscribe(ch <-chan Event, eventTypes ...EventType) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	// If no specific types provided, unsubscribe from all
-	if len(eventTypes) == 0 {
-		for eventType := range b.subscribers {
-			b.removeChannel(eventType, ch)
+	for i, sub := range b.subscribers {
+		if sub.ch != ch {
+			continue
+		}
+		// If no specific types provided, unsubscribe from all
+		if len(eventTypes) > 0 {
+			kept := sub.patterns[:0]
+			for _, pattern := range sub.patterns {
+				if !containsType(eventTypes, pattern) {
+					kept = append(kept, pattern)
+				}
+			}
+			sub.patterns = kept
+			if len(kept) > 0 {
+				return
+			}
 		}
+		b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+		close(sub.ch)
 		return
 	}
-
-	for _, eventType := range eventTypes {
-		b.removeChannel(eventType, ch)
-	}
 }
 
 // Publish sends an event to all subscribers
 func (b *Broker) Publish(event Event) {
+	b.record(event)
+
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	// Send to specific subscribers
-	if subscribers, ok := b.subscribers[event.Type]; ok {
-		for _, ch := range subscribers {
-			select {
-			case ch <- event:
-			default:
-				// Channel full, skip this event
-			}
-		}
-	}
-
-	// Send to wildcard subscribers
-	if wildcards, ok := b.subscribers["*"]; ok {
-		for _, ch := range wildcards {
-			select {
-			case ch <- event:
-			default:
-				// Channel full, skip this event
-			}
+	for _, sub := range b.subscribers {
+		if sub.matches(event.Type) {
+			sub.deliver(event)
 		}
 	}
 }
@@ -94,35 +177,153 @@ func (b *Broker) PublishAsync(event Event) {
 	go b.Publish(event)
 }
 
-// removeChannel removes a channel from a specific event type's subscribers
-func (b *Broker) removeChannel(eventType EventType, target <-chan Event) {
-	subscribers := b.subscribers[eventType]
-	for i, ch := range subscribers {
-		if ch == target {
-			// Remove this channel
-			b.subscribers[eventType] = append(subscribers[:i], subscribers[i+1:]...)
-			// Close the channel
-			close(ch)
-			break
+// Clear removes all subscriptions
+func (b *Broker) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, sub := range b.subscribers {
+		close(sub.ch)
+	}
+	b.subscribers = nil
+}
+
+// Subscriptions describes the current subscribers
+func (b *Broker) Subscriptions() []SubscriptionInfo {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	infos := make([]SubscriptionInfo, 0, len(b.subscribers))
+	for _, sub := range b.subscribers {
+		infos = append(infos, SubscriptionInfo{
+			Name:         sub.opts.Name,
+			Patterns:     append([]EventType(nil), sub.patterns...),
+			Buffered:     len(sub.ch),
+			Capacity:     cap(sub.ch),
+			Backpressure: sub.opts.Backpressure,
+			Delivered:    sub.delivered.Load(),
+			Dropped:      sub.dropped.Load(),
+		})
+	}
+	return infos
+}
+
+// Recent returns up to limit of the latest events matching pattern ("" or
+// "*" for all), oldest first
+func (b *Broker) Recent(limit int, pattern EventType) []Record {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+
+	var records []Record
+	for i := 0; i < len(b.history) && len(records) < limit; i++ {
+		// Walk backwards from the newest entry
+		index := (b.next - 1 - i + len(b.history)) % len(b.history)
+		record := b.history[index]
+		if pattern == "" || MatchType(pattern, record.Event.Type) {
+			records = append(records, record)
 		}
 	}
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records
+}
+
+// PublishedCounts returns how many events of each type were published
+func (b *Broker) PublishedCounts() map[EventType]uint64 {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
 
-	// Clean up empty subscriber lists
-	if len(b.subscribers[eventType]) == 0 {
-		delete(b.subscribers, eventType)
+	counts := make(map[EventType]uint64, len(b.counts))
+	for eventType, count := range b.counts {
+		counts[eventType] = count
 	}
+	return counts
 }
 
-// Clear removes all subscriptions
-func (b *Broker) Clear() {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+// record adds an event to the history
+func (b *Broker) record(event Event) {
+	entry := Record{Time: time.Now(), Event: event, Mismatch: !payloadMatchesTopic(event)}
 
-	for _, subscribers := range b.subscribers {
-		for _, ch := range subscribers {
-			close(ch)
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+	b.counts[event.Type]++
+	if len(b.history) < historySize {
+		b.history = append(b.history, entry)
+		b.next = len(b.history) % historySize
+		return
+	}
+	b.history[b.next] = entry
+	b.next = (b.next + 1) % historySize
+}
+
+// MatchType reports whether an event type matches a subscription pattern
+func MatchType(pattern, eventType EventType) bool {
+	if pattern == "*" || pattern == eventType {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(string(pattern), "*"); ok {
+		return strings.HasPrefix(string(eventType), prefix)
+	}
+	return false
+}
+
+func (s *subscription) matches(eventType EventType) bool {
+	for _, pattern := range s.patterns {
+		if MatchType(pattern, eventType) {
+			return true
 		}
 	}
+	return false
+}
 
-	b.subscribers = make(map[EventType][]chan Event)
-}
\ No newline at end of file
+// deliver sends an event according to the subscription's backpressure policy
+func (s *subscription) deliver(event Event) {
+	select {
+	case s.ch <- event:
+		s.delivered.Add(1)
+		return
+	default:
+	}
+
+	switch s.opts.Backpressure {
+	case DropOldest:
+		// Make room by discarding the oldest event; the subscriber may
+		// drain the channel concurrently, so either step can find it empty
+		select {
+		case <-s.ch:
+			s.dropped.Add(1)
+		default:
+		}
+		select {
+		case s.ch <- event:
+			s.delivered.Add(1)
+			return
+		default:
+		}
+	case Block:
+		if s.opts.BlockTimeout < 0 {
+			s.ch <- event
+			s.delivered.Add(1)
+			return
+		}
+		timer := time.NewTimer(s.opts.BlockTimeout)
+		defer timer.Stop()
+		select {
+		case s.ch <- event:
+			s.delivered.Add(1)
+			return
+		case <-timer.C:
+		}
+	}
+	s.dropped.Add(1)
+}
+
+func containsType(types []EventType, target EventType) bool {
+	for _, t := range types {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
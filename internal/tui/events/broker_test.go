@@ -0,0 +1,141 @@
+package events
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMatchType(t *testing.T) {
+	tests := []struct {
+		pattern   EventType
+		eventType EventType
+		want      bool
+	}{
+		{"*", "tool.result", true},
+		{"tool.result", "tool.result", true},
+		{"tool.result", "tool.results", false},
+		{"tool.*", "tool.result", true},
+		{"tool.*", "tool.", true},
+		{"tool.*", "tool", false},
+		{"tool.*", "tools.result", false},
+		{"tool*", "tools.result", true},
+		{"stream.*", "tool.result", false},
+		{"", "tool.result", false},
+	}
+	for _, tt := range tests {
+		if got := MatchType(tt.pattern, tt.eventType); got != tt.want {
+			t.Errorf("MatchType(%q, %q) = %v, want %v", tt.pattern, tt.eventType, got, tt.want)
+		}
+	}
+}
+
+// publishNumbered publishes events "e.1" to "e.n" in order
+func publishNumbered(b *Broker, n int) {
+	for i := 1; i <= n; i++ {
+		b.Publish(Event{Type: EventType(fmt.Sprintf("e.%d", i))})
+	}
+}
+
+// drain returns the types of the events buffered on ch
+func drain(ch <-chan Event) []EventType {
+	var types []EventType
+	for {
+		select {
+		case event := <-ch:
+			types = append(types, event.Type)
+		default:
+			return types
+		}
+	}
+}
+
+func TestBackpressureWhenBufferIsFull(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          SubscribeOptions
+		want          []EventType
+		wantDelivered uint64
+		wantDropped   uint64
+	}{
+		{
+			name:          "drop_newest",
+			opts:          SubscribeOptions{Buffer: 2, Backpressure: DropNewest},
+			want:          []EventType{"e.1", "e.2"},
+			wantDelivered: 2,
+			wantDropped:   2,
+		},
+		{
+			name:          "drop_oldest",
+			opts:          SubscribeOptions{Buffer: 2, Backpressure: DropOldest},
+			want:          []EventType{"e.3", "e.4"},
+			wantDelivered: 4,
+			wantDropped:   2,
+		},
+		{
+			name:          "block_times_out",
+			opts:          SubscribeOptions{Buffer: 2, Backpressure: Block, BlockTimeout: time.Millisecond},
+			want:          []EventType{"e.1", "e.2"},
+			wantDelivered: 2,
+			wantDropped:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		b := NewBroker()
+		ch := b.SubscribeWith(tt.opts, "e.*")
+		publishNumbered(b, 4)
+
+		got := drain(ch)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: received %v, want %v", tt.name, got, tt.want)
+		}
+		info := b.Subscriptions()[0]
+		if info.Delivered != tt.wantDelivered || info.Dropped != tt.wantDropped {
+			t.Errorf("%s: delivered %d dropped %d, want %d and %d", tt.name, info.Delivered, info.Dropped, tt.wantDelivered, tt.wantDropped)
+		}
+	}
+}
+
+func TestBlockWithoutTimeoutWaitsForRoom(t *testing.T) {
+	b := NewBroker()
+	ch := b.SubscribeWith(SubscribeOptions{Buffer: 1, Backpressure: Block, BlockTimeout: -1}, "e.*")
+
+	done := make(chan struct{})
+	go func() {
+		publishNumbered(b, 3)
+		close(done)
+	}()
+
+	// Read slower than the default timeout would allow
+	var got []EventType
+	for len(got) < 3 {
+		time.Sleep(300 * time.Millisecond)
+		got = append(got, (<-ch).Type)
+	}
+	<-done
+
+	if want := []EventType{"e.1", "e.2", "e.3"}; !slices.Equal(got, want) {
+		t.Errorf("received %v, want e.1 e.2 e.3", got)
+	}
+	if info := b.Subscriptions()[0]; info.Delivered != 3 || info.Dropped != 0 {
+		t.Errorf("delivered %d dropped %d, want 3 and 0", info.Delivered, info.Dropped)
+	}
+}
+
+func TestSubscribeMatchesPatterns(t *testing.T) {
+	b := NewBroker()
+	tools := b.Subscribe("tool.*")
+	all := b.Subscribe()
+
+	b.Publish(Event{Type: "tool.result"})
+	b.Publish(Event{Type: "stream.chunk"})
+
+	if got := drain(tools); len(got) != 1 || got[0] != "tool.result" {
+		t.Errorf("tool.* received %v", got)
+	}
+	if got := drain(all); len(got) != 2 {
+		t.Errorf("everything received %v", got)
+	}
+}
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Topic binds an event type to the payload type its events carry, so
+// publishers and subscribers agree on it at compile time
+type Topic[T any] struct {
+	Type EventType
+}
+
+// topics maps registered event types to their payload types
+var topics = struct {
+	sync.RWMutex
+	payloads map[EventType]reflect.Type
+}{payloads: make(map[EventType]reflect.Type)}
+
+// RegisterTopic declares that events of eventType carry a T. Publishing
+// another payload type still works but is flagged in /events.
+func RegisterTopic[T any](eventType EventType) Topic[T] {
+	topics.Lock()
+	defer topics.Unlock()
+	topics.payloads[eventType] = reflect.TypeFor[T]()
+	return Topic[T]{Type: eventType}
+}
+
+// Publish sends payload to the topic's subscribers
+func (t Topic[T]) Publish(b *Broker, payload T) {
+	b.Publish(Event{Type: t.Type, Payload: payload})
+}
+
+// PublishAsync sends payload asynchronously
+func (t Topic[T]) PublishAsync(b *Broker, payload T) {
+	b.PublishAsync(Event{Type: t.Type, Payload: payload})
+}
+
+// Payload extracts the topic's payload from an event
+func (t Topic[T]) Payload(event Event) (T, bool) {
+	payload, ok := event.Payload.(T)
+	return payload, ok && event.Type == t.Type
+}
+
+// TopicInfo describes a registered topic
+type TopicInfo struct {
+	Type    EventType
+	Payload string // Go type of the payload
+}
+
+// Topics lists the registered topics, sorted by type
+func Topics() []TopicInfo {
+	topics.RLock()
+	defer topics.RUnlock()
+
+	infos := make([]TopicInfo, 0, len(topics.payloads))
+	for eventType, payload := range topics.payloads {
+		infos = append(infos, TopicInfo{Type: eventType, Payload: payload.String()})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Type < infos[j].Type })
+	return infos
+}
+
+// payloadMatchesTopic reports whether an event's payload has its topic's
+// registered type. Unregistered types and empty payloads always match.
+func payloadMatchesTopic(event Event) bool {
+	if event.Payload == nil {
+		return true
+	}
+	topics.RLock()
+	want, ok := topics.payloads[event.Type]
+	topics.RUnlock()
+	return !ok || reflect.TypeOf(event.Payload) == want
+}
+
+// Typed topics for the events published with a payload
+var (
+	ModelSelected  = RegisterTopic[ModelSelectedPayload](ModelSelectedEvent)
+	TeamSelected   = RegisterTopic[TeamSelectedPayload](TeamSelectedEvent)
+	UserMessage    = RegisterTopic[MessagePayload](UserMessageEvent)
+	AssistantReply = RegisterTopic[MessagePayload](AssistantMessageEvent)
+	SystemMessage  = RegisterTopic[MessagePayload](SystemMessageEvent)
+	StreamChunk    = RegisterTopic[StreamChunkPayload](StreamChunkEvent)
+
+	SessionCreated = RegisterTopic[SessionPayload](SessionCreatedEvent)
+
+	StartupScanStarted   = RegisterTopic[AnalysisProgressPayload](StartupScanStartedEvent)
+	StartupScanCompleted = RegisterTopic[AnalysisProgressPayload](StartupScanCompletedEvent)
+	AnalysisStarted      = RegisterTopic[AnalysisProgressPayload](AnalysisStartedEvent)
+	AnalysisProgress     = RegisterTopic[AnalysisProgressPayload](AnalysisProgressEvent)
+	AnalysisCompleted    = RegisterTopic[AnalysisProgressPayload](AnalysisCompletedEvent)
+	AnalysisError        = RegisterTopic[StatusMessagePayload](AnalysisErrorEvent)
+	RAGIndexProgress     = RegisterTopic[RAGIndexProgressPayload](RAGIndexProgressEvent)
+
+	WorkerConcurrency = RegisterTopic[ConcurrencyPayload](WorkerConcurrencyEvent)
+	LLMQueue          = RegisterTopic[QueuePayload](LLMQueueEvent)
+	LLMRequests       = RegisterTopic[RequestsPayload](LLMRequestsEvent)
+	ModelLoading      = RegisterTopic[ModelStatusPayload](ModelLoadingEvent)
+	ModelLoaded       = RegisterTopic[ModelStatusPayload](ModelLoadedEvent)
+	ModelError        = RegisterTopic[ModelStatusPayload](ModelErrorEvent)
+
+	ToolApproved = RegisterTopic[ToolExecutionPayload](ToolExecutionApprovedEvent)
+	ToolDenied   = RegisterTopic[ToolExecutionPayload](ToolExecutionDeniedEvent)
+	ToolDetected = RegisterTopic[ToolDetectedPayload](ToolCallDetectedEvent)
+
+	StatusMessage   = RegisterTopic[StatusMessagePayload](StatusMessageEvent)
+	ErrorMessage    = RegisterTopic[StatusMessagePayload](ErrorMessageEvent)
+	DialogOpen      = RegisterTopic[DialogPayload](DialogOpenEvent)
+	DialogClose     = RegisterTopic[DialogPayload](DialogCloseEvent)
+	CommandSelected = RegisterTopic[CommandSelectedPayload](CommandSelectedEvent)
+)
@@ -41,11 +41,23 @@ const (
 	AnalysisCompletedEvent    EventType = "analysis.completed"
 	AnalysisErrorEvent        EventType = "analysis.error"
 
+	// RAG events
+	RAGIndexProgressEvent EventType = "rag.index.progress"
+
+	// LLM events
+	WorkerConcurrencyEvent EventType = "llm.concurrency"
+	LLMQueueEvent          EventType = "llm.queue"
+	LLMRequestsEvent       EventType = "llm.requests"
+
 	// Tool events
 	ToolExecutionRequestEvent EventType = "tool.request"
 	ToolExecutionApprovedEvent EventType = "tool.approved"
 	ToolExecutionDeniedEvent  EventType = "tool.denied"
 	ToolExecutionResultEvent  EventType = "tool.result"
+	ToolCallDetectedEvent     EventType = "tool.detected"
+
+	// Permission events
+	PermissionRequestedEvent EventType = "permission.request"
 
 	// UI events
 	StatusMessageEvent      EventType = "ui.status"
@@ -107,6 +119,41 @@ type AnalysisProgressPayload struct {
 	CurrentFile    string
 }
 
+// RAGIndexProgressPayload reports background re-indexing of changed files
+type RAGIndexProgressPayload struct {
+	Total       int
+	Completed   int
+	Failed      int
+	CurrentFile string
+	Done        bool
+}
+
+// ConcurrencyPayload reports a change in how many analysis workers call
+// LM Studio at once
+type ConcurrencyPayload struct {
+	Level  int
+	Max    int
+	Reason string // Why it changed, e.g. "LM Studio returned 429"
+}
+
+// QueuePayload reports how many background LLM requests wait in the queue
+// and how many of its requests are running
+type QueuePayload struct {
+	Pending int
+	Running int
+}
+
+// RequestsPayload reports how many chat requests to LM Studio are in flight
+type RequestsPayload struct {
+	Active int
+}
+
+// ModelStatusPayload reports a model loading, loaded or failing in LM Studio
+type ModelStatusPayload struct {
+	ModelID string
+	Error   string // Set with ModelErrorEvent
+}
+
 type StatusMessagePayload struct {
 	Message string
 	Type    string // "info", "warning", "error", "success"
@@ -116,6 +163,15 @@ type ToolExecutionPayload struct {
 	ToolName string
 	Args     map[string]interface{}
 	ID       string
+	Decision string // "approve", "deny", "modify", "always" or "never" when sent by the permissions dialog
+}
+
+// ToolDetectedPayload describes a tool call spotted in a still-streaming response
+type ToolDetectedPayload struct {
+	ToolName string
+	Params   map[string]interface{}
+	Summary  string // Compact form such as read_file(main.go)
+	Complete bool   // True once the full call has streamed in
 }
 
 type DialogPayload struct {
@@ -125,4 +181,5 @@ type DialogPayload struct {
 
 type CommandSelectedPayload struct {
 	Command string
+	Kind    string // What Command names: a command, or a session, knowledge file or project file
 }
\ No newline at end of file
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/billie-coop/loco/internal/export"
+)
+
+// exportConversation writes the conversation on screen, tool blocks
+// included, to a Markdown or HTML file. With no path it goes to
+// .loco/exports, named after the session.
+func (m *Model) exportConversation(args []string) {
+	if len(args) == 0 {
+		m.showStatus("⚠️ Usage: /export md|html [path]")
+		return
+	}
+	format, err := export.ParseFormat(args[0])
+	if err != nil {
+		m.showStatus("⚠️ " + err.Error())
+		return
+	}
+
+	conversation := export.Conversation{Messages: m.messages.AllAsLLM()}
+	if m.app.Sessions != nil {
+		if current, err := m.app.Sessions.GetCurrent(); err == nil && current != nil {
+			conversation.Title = current.Title
+			conversation.SessionID = current.ID
+			conversation.Created = current.Created
+			conversation.Model = current.Model
+		}
+	}
+	if m.app.LLMService != nil && conversation.Model == "" {
+		conversation.Model = m.app.LLMService.ChatModel()
+	}
+
+	data, err := export.Render(conversation, format)
+	if err != nil {
+		m.showStatus("⚠️ Export failed: " + err.Error())
+		return
+	}
+
+	path := strings.Join(args[1:], " ")
+	if path == "" {
+		name := conversation.SessionID
+		if name == "" {
+			name = "conversation"
+		}
+		path = filepath.Join(".loco", "exports", name+"."+string(format))
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.app.WorkingDir(), path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		m.showStatus("⚠️ Export failed: " + err.Error())
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		m.showStatus("⚠️ Export failed: " + err.Error())
+		return
+	}
+
+	if rel, err := filepath.Rel(m.app.WorkingDir(), path); err == nil && filepath.IsLocal(rel) {
+		path = rel
+	}
+	m.showStatus(fmt.Sprintf("📄 Exported %d messages to %s", len(conversation.Messages), path))
+}
+
+// exportDebugBundle zips the debug dumps, redacted config, models and
+// version info for attaching to a bug report. With no path it goes to
+// .loco/exports.
+func (m *Model) exportDebugBundle(args []string) {
+	path, err := m.app.ExportDebugBundle(strings.Join(args, " "))
+	if err != nil {
+		m.showStatus("⚠️ Debug export failed: " + err.Error())
+		return
+	}
+	if rel, err := filepath.Rel(m.app.WorkingDir(), path); err == nil && filepath.IsLocal(rel) {
+		path = rel
+	}
+	m.showStatus("🧰 Debug bundle written to " + path)
+}
@@ -3,6 +3,8 @@ package highlight
 import (
 	"bytes"
 	"image/color"
+	"regexp"
+	"strings"
 
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/formatters"
@@ -11,6 +13,11 @@ import (
 	"github.com/billie-coop/loco/internal/tui/styles"
 )
 
+const sgrReset = "\x1b[0m"
+
+// sgrPattern matches the color and attribute sequences the formatter emits
+var sgrPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
 func SyntaxHighlight(source, fileName string, bg color.Color) (string, error) {
 	// Determine the language lexer to use
 	l := lexers.Match(fileName)
@@ -51,4 +58,42 @@ func SyntaxHighlight(source, fileName string, bg color.Color) (string, error) {
 	var buf bytes.Buffer
 	err = f.Format(&buf, s, it)
 	return buf.String(), err
-}
\ No newline at end of file
+}
+
+// Lines highlights source as the language of fileName and splits it into
+// lines, returning the plain lines if it cannot be highlighted. Highlighting
+// the lines together keeps tokens spanning several lines, like block
+// comments, colored throughout.
+func Lines(source, fileName string, bg color.Color) []string {
+	lines := strings.Split(source, "\n")
+	highlighted, err := SyntaxHighlight(source, fileName, bg)
+	if err != nil {
+		return lines
+	}
+	// Lexers end the last line with a newline whether or not it had one
+	out := strings.Split(strings.TrimSuffix(highlighted, "\n"), "\n")
+	if len(out) != len(lines) {
+		return lines
+	}
+
+	// A token's colors run on across line breaks, so each line reopens the
+	// colors the previous one left active and closes them itself
+	active := ""
+	for i, line := range out {
+		out[i] = active + line + sgrReset
+		for _, seq := range sgrPattern.FindAllString(line, -1) {
+			if seq == sgrReset {
+				active = ""
+			} else {
+				active += seq
+			}
+		}
+	}
+	return out
+}
+
+// Diff highlights a unified diff, coloring added and removed lines and hunk
+// headers with the theme
+func Diff(diff string, bg color.Color) []string {
+	return Lines(diff, "change.diff", bg)
+}
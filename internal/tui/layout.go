@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"github.com/billie-coop/loco/internal/tui/components/chat"
 	tea "github.com/charmbracelet/bubbletea/v2"
 )
 
@@ -11,11 +12,15 @@ func (m *Model) resizeComponents() tea.Cmd {
 	// Calculate layout dimensions
 	sidebarWidth := m.calculateSidebarWidth()
 	statusBarHeight := 1
-	inputHeight := 3
 	
 	// Main content area (accounting for borders)
 	contentWidth := m.width - sidebarWidth
 	contentHeight := m.height - statusBarHeight
+
+	// The input's height follows its draft wrapped at the new width
+	m.input.SetSize(contentWidth-2, 1)
+	inputHeight := m.calculateInputHeight()
+	m.inputHeight = inputHeight
 	
 	// Message list gets remaining height
 	messageListHeight := contentHeight - inputHeight
@@ -46,8 +51,9 @@ func (m *Model) calculateSidebarWidth() int {
 	return 30
 }
 
-// calculateInputHeight calculates the appropriate input height
+// calculateInputHeight calculates the appropriate input height, growing
+// with a multi-line draft
 func (m *Model) calculateInputHeight() int {
-	// Could be dynamic based on content
-	return 3
+	// One line of draft at least, plus the border
+	return min(max(m.input.Lines(), 1), chat.MaxInputLines) + 2
 }
\ No newline at end of file
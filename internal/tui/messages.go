@@ -44,6 +44,7 @@ func (m *Model) syncStateToComponents() {
 func (m *Model) clearMessages() {
 	m.messages.Clear()
 	m.messagesMeta = csync.NewMap[int, *chat.MessageMetadata]()
+	m.messageList.ResetToolState()
 	m.syncMessagesToComponents()
 	
 	// Clear session messages too
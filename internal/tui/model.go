@@ -4,9 +4,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/billie-coop/loco/internal/analysis"
 	"github.com/billie-coop/loco/internal/app"
 	chatpkg "github.com/billie-coop/loco/internal/chat"
 	"github.com/billie-coop/loco/internal/csync"
+	"github.com/billie-coop/loco/internal/session"
 	"github.com/billie-coop/loco/internal/tui/components/chat"
 	"github.com/billie-coop/loco/internal/tui/components/chat/completions"
 	"github.com/billie-coop/loco/internal/tui/components/dialog"
@@ -42,12 +44,20 @@ type Model struct {
 	messages         *chatpkg.MessageStore // Using chatpkg to avoid name collision
 	messagesMeta     *csync.Map[int, *chat.MessageMetadata]
 	analysisState    *chat.AnalysisState
+	activity         status.Activity              // Background work shown in the status bar
+	modelStates      map[string]status.ModelState // What LM Studio has loaded, by model ID
 
 	// UI state
 	isStreaming      bool
 	streamingMessage string
 	debugMode        bool
 	ready            bool
+	editingLast      bool // The input holds the last user message being edited
+	inputHeight      int  // Input height at the last resize, border included
+
+	// Screen regions from the last render, for routing clicks
+	sidebarRight   int // First column right of the sidebar
+	messagesBottom int // First row below the message area
 
 	// Heartbeat tracking for progress
 	lastProgress time.Time
@@ -59,6 +69,10 @@ func New(appInstance *app.App, eventBroker *events.Broker) *Model {
 	sidebarModel := chat.NewSidebar()
 	messageListModel := chat.NewMessageList()
 	inputModel := chat.NewInput(appInstance.Tools)
+	inputModel.SetFileLister(func() []string {
+		files, _ := analysis.GetProjectFiles(appInstance.WorkingDir())
+		return files
+	})
 	statusBarModel := status.New()
 	dialogManager := dialog.NewManager(eventBroker, appInstance.Tools)
 	completions := completions.NewCompletions()
@@ -77,8 +91,13 @@ func New(appInstance *app.App, eventBroker *events.Broker) *Model {
 		app:           appInstance,
 	}
 
-	// Subscribe to all events
-	m.eventSub = eventBroker.Subscribe()
+	// Subscribe to all events. Stream chunks arrive faster than the UI
+	// redraws, so buffer generously and make publishers wait rather than drop.
+	m.eventSub = eventBroker.SubscribeWith(events.SubscribeOptions{
+		Name:         "tui",
+		Buffer:       256,
+		Backpressure: events.Block,
+	})
 
 	return m
 }
@@ -119,7 +138,19 @@ func (m *Model) Init() tea.Cmd {
 	// ALWAYS set component sizes during init to ensure proper layout
 	cmds = append(cmds, m.resizeComponents())
 
+	// Show the analysis worker level until LM Studio's health changes it
+	if m.app.WorkerLimit != nil {
+		level, ceiling := m.app.WorkerLimit.Level()
+		m.sidebar.SetWorkerConcurrency(level, ceiling, "")
+	}
+
+	// Show the models LM Studio had loaded at startup
+	for _, probe := range m.app.ModelProbes {
+		m.setModelState(probe.ID, status.ModelLoaded)
+	}
+
 	// Load session messages from app
+	var view *session.ViewState
 	if m.app.Sessions != nil {
 		// Set the session manager in sidebar
 		m.sidebar.SetSessionManager(m.app.Sessions)
@@ -130,11 +161,22 @@ func (m *Model) Init() tea.Cmd {
 			if messages, err := m.app.Sessions.GetMessages(); err == nil {
 				m.messages.Replace(messages)
 			}
+			m.applySessionModel(currentSession.Model)
+			view = currentSession.View
 		}
 	}
 
-	// Sync all state to components after loading
+	// Sync all state to components after loading, putting the chat view
+	// back where the session was left
+	if view != nil {
+		m.messageList.SetExpandedTools(view.ExpandedTools)
+	}
 	m.syncStateToComponents()
+	m.showModelState()
+	if view != nil {
+		m.messageList.ScrollToOffset(view.ScrollOffset)
+	}
+	m.refreshKnowledgeFiles()
 
 	// Show welcome message in status bar only
 	m.eventBroker.PublishAsync(events.Event{
@@ -250,6 +292,16 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Sync all state to components
 		m.syncStateToComponents()
+
+	case chat.EditorFinishedMsg:
+		if msg.Err != nil {
+			m.showStatus("⚠️ Editor failed: " + msg.Err.Error())
+		}
+	}
+
+	// Handle clicks on the sidebar and chat
+	if click, ok := msg.(tea.MouseClickMsg); ok && click.Button == tea.MouseLeft && !m.dialogManager.IsDialogOpen() {
+		return m, m.handleClick(click.Mouse())
 	}
 
 	// Handle keyboard input - check for special keys first
@@ -266,10 +318,28 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+l":
 			m.clearMessages()
 			return m, nil
-		case "ctrl+p":
+		case "ctrl+k", "ctrl+p":
 			// Open command palette
-			return m, m.dialogManager.OpenDialog(dialog.CommandPaletteDialogType)
+			return m, m.openCommandPalette()
+		case "ctrl+s":
+			m.copyScreen()
+			return m, nil
+		case "ctrl+b":
+			return m, m.openKnowledgeBrowser("")
+		case "alt+e":
+			m.editLastMessage()
+			return m, nil
+		case "ctrl+r":
+			return m, m.regenerate("")
+		case "alt+r":
+			return m, m.regenerateWithNextTeamModel()
 		case "esc":
+			if m.editingLast && !m.completions.IsOpen() && !m.dialogManager.IsDialogOpen() {
+				m.editingLast = false
+				m.input.Reset()
+				m.showStatus("Edit cancelled")
+				return m, nil
+			}
 			// Universal interrupt: cancel any active tool/stream if no dialog or completion is consuming ESC
 			if m.app != nil && m.app.ToolExecutor != nil && !m.completions.IsOpen() && !m.dialogManager.IsDialogOpen() {
 				m.app.ToolExecutor.CancelCurrent()
@@ -282,6 +352,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				content := m.input.Value()
 				if content != "" {
 					m.input.Reset()
+					if m.editingLast {
+						// The edit replaces the message and what followed it
+						m.editingLast = false
+						m.rewindToLastUserMessage()
+					}
 					return m, m.handleSendMessage(content)
 				}
 			}
@@ -303,6 +378,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// A dropped or pasted image path becomes an @-mention attaching it
+	if paste, ok := msg.(tea.PasteMsg); ok && !m.dialogManager.IsDialogOpen() && m.input.Focused() {
+		if mention, ok := m.attachPastedImage(string(paste)); ok {
+			m.input.Insert(mention)
+			return m, tea.Batch(cmds...)
+		}
+	}
+
 	// Route message to input and message list components
 	{
 		inputModel, cmd := m.input.Update(msg)
@@ -310,6 +393,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.input = im
 		}
 		cmds = append(cmds, cmd)
+
+		// A multi-line draft grows the input into the message area
+		if m.inputHeight != m.calculateInputHeight() {
+			cmds = append(cmds, m.resizeComponents())
+		}
 	}
 
 	{
@@ -337,7 +425,7 @@ func (m *Model) View() string {
 	sidebarWidth := m.calculateSidebarWidth()
 	mainWidth := m.width - sidebarWidth
 	statusHeight := 1
-	inputHeight := 3
+	inputHeight := m.inputHeight
 	messageHeight := m.height - statusHeight - inputHeight
 
 	// Create bordered sidebar with rounded corners (golden orange like dialogs)
@@ -372,6 +460,8 @@ func (m *Model) View() string {
 	sidebar := sidebarStyle.Render(m.sidebar.View())
 	messages := messageAreaStyle.Render(m.messageList.View())
 	input := inputStyle.Render(m.input.View())
+	m.sidebarRight = lipgloss.Width(sidebar)
+	m.messagesBottom = lipgloss.Height(messages)
 
 	// Stack messages and input vertically
 	mainContent := lipgloss.JoinVertical(lipgloss.Left, messages, input)
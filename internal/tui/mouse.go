@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"github.com/billie-coop/loco/internal/analysis"
+	"github.com/billie-coop/loco/internal/csync"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/tui/components/chat"
+	"github.com/billie-coop/loco/internal/tui/components/dialog"
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// handleClick acts on a left click: sessions and knowledge files in the
+// sidebar, tool blocks in the chat
+func (m *Model) handleClick(mouse tea.Mouse) tea.Cmd {
+	switch {
+	case mouse.X < m.sidebarRight:
+		// Sidebar content starts inside its border
+		target, ok := m.sidebar.TargetAt(mouse.Y - 1)
+		if !ok {
+			return nil
+		}
+		switch target.Kind {
+		case chat.TargetSession:
+			m.switchSession(target.ID)
+		case chat.TargetKnowledgeFile:
+			return m.openKnowledgeBrowser(target.ID)
+		}
+
+	case mouse.Y < m.messagesBottom:
+		// So does the message list
+		m.messageList.ToggleToolAt(mouse.Y - 1)
+	}
+	return nil
+}
+
+// switchSession makes another saved session current and shows its messages
+func (m *Model) switchSession(id string) {
+	if m.app.Sessions == nil {
+		return
+	}
+	if current, err := m.app.Sessions.GetCurrent(); err == nil && current != nil && current.ID == id {
+		return
+	}
+	if m.isStreaming {
+		m.showStatus("⚠️ Wait for the reply to finish before switching sessions")
+		return
+	}
+
+	// Remember where this session was left before showing the other one
+	m.SaveViewState()
+	if err := m.app.Sessions.SetCurrent(id); err != nil {
+		m.showStatus("⚠️ Failed to switch session: " + err.Error())
+		return
+	}
+	session, err := m.app.Sessions.GetCurrent()
+	if err != nil {
+		m.showStatus("⚠️ Failed to load session: " + err.Error())
+		return
+	}
+
+	m.currentSessionID = id
+	m.messages.Replace(session.Messages.ToSlice())
+	m.messagesMeta = csync.NewMap[int, *chat.MessageMetadata]()
+	m.showSessionMessages(session.View)
+
+	m.applySessionModel(session.Model)
+	m.showStatus("Switched to " + session.Title)
+}
+
+// applySessionModel switches chat to the model saved with a session by
+// /model; sessions without one keep the current model
+func (m *Model) applySessionModel(modelID string) {
+	if modelID == "" || m.app.LLMService == nil {
+		return
+	}
+	if m.app.LLMService.SetChatModel(modelID) {
+		m.sidebar.SetModel(modelID, llm.DetectModelSize(modelID))
+		m.showModelState()
+	}
+}
+
+// openKnowledgeBrowser opens the knowledge browser on file, or on the first
+// file when it is empty
+func (m *Model) openKnowledgeBrowser(file string) tea.Cmd {
+	if m.app.Knowledge == nil {
+		m.showStatus("⚠️ No knowledge available")
+		return nil
+	}
+	files, err := m.app.Knowledge.ListFiles()
+	if err != nil {
+		m.showStatus("⚠️ " + err.Error())
+		return nil
+	}
+	m.sidebar.SetKnowledgeFiles(files)
+	m.dialogManager.SetKnowledgeFiles(files, m.app.Knowledge.GetFile, file)
+	return m.dialogManager.OpenDialog(dialog.KnowledgeBrowserDialogType)
+}
+
+// refreshKnowledgeFiles lists the generated knowledge files in the sidebar
+func (m *Model) refreshKnowledgeFiles() {
+	if m.app.Knowledge == nil {
+		return
+	}
+	files, err := m.app.Knowledge.ListFiles()
+	if err != nil {
+		return
+	}
+	m.sidebar.SetKnowledgeFiles(files)
+
+	if metrics := analysis.LoadConsensusMetrics(m.app.WorkingDir()); metrics != nil {
+		m.sidebar.SetQuickConfidence(metrics.Level(), metrics.Agreement)
+	} else {
+		m.sidebar.SetQuickConfidence("", 0)
+	}
+}
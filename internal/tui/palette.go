@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/billie-coop/loco/internal/tui/components/dialog"
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// maxPaletteRecentFiles caps how many recently edited files the palette lists
+const maxPaletteRecentFiles = 10
+
+// openCommandPalette refreshes the palette's sessions and files and opens it
+func (m *Model) openCommandPalette() tea.Cmd {
+	m.dialogManager.SetPaletteEntries(m.paletteEntries())
+	return m.dialogManager.OpenDialog(dialog.CommandPaletteDialogType)
+}
+
+// paletteEntries lists the saved sessions, knowledge files and recently
+// edited files for the command palette
+func (m *Model) paletteEntries() []dialog.Command {
+	var entries []dialog.Command
+
+	if m.app.Sessions != nil {
+		for _, session := range m.app.Sessions.ListSessions() {
+			entries = append(entries, dialog.Command{
+				Name:        session.Title,
+				Description: "Switch to this session",
+				Shortcut:    session.LastUpdated.Format("Jan 2 15:04"),
+				Action:      session.ID,
+				Kind:        dialog.PaletteSession,
+			})
+		}
+	}
+
+	if m.app.Knowledge != nil {
+		if files, err := m.app.Knowledge.ListFiles(); err == nil {
+			for _, file := range files {
+				entries = append(entries, dialog.Command{
+					Name:        file,
+					Description: "Show this knowledge file",
+					Shortcut:    "knowledge",
+					Action:      file,
+					Kind:        dialog.PaletteKnowledge,
+				})
+			}
+		}
+	}
+
+	if m.app.Tools != nil {
+		// Checkpoints come newest first, so the first sighting of a file is
+		// its latest edit
+		seen := make(map[string]bool)
+		for _, checkpoint := range m.app.Tools.Checkpoints().List() {
+			for _, file := range checkpoint.Files {
+				if seen[file.Path] || len(seen) >= maxPaletteRecentFiles {
+					continue
+				}
+				seen[file.Path] = true
+				entries = append(entries, dialog.Command{
+					Name:        file.Path,
+					Description: "Mention this file in the input",
+					Shortcut:    "recent",
+					Action:      file.Path,
+					Kind:        dialog.PaletteFile,
+				})
+			}
+		}
+	}
+
+	return entries
+}
+
+// runPaletteEntry acts on an entry picked in the command palette
+func (m *Model) runPaletteEntry(kind dialog.PaletteKind, action string) tea.Cmd {
+	switch kind {
+	case dialog.PaletteSession:
+		m.switchSession(action)
+	case dialog.PaletteKnowledge:
+		return m.openKnowledgeBrowser(action)
+	case dialog.PaletteFile:
+		value := m.input.Value()
+		if value != "" && !strings.HasSuffix(value, " ") {
+			value += " "
+		}
+		m.input.SetValue(value + action + " ")
+		m.input.CursorEnd()
+		return m.input.Focus()
+	default:
+		switch action {
+		case "ctrl+l":
+			m.clearMessages()
+		case "ctrl+s":
+			m.copyScreen()
+		case "ctrl+b":
+			return m.openKnowledgeBrowser("")
+		case "ctrl+e":
+			return m.input.OpenEditor()
+		case "alt+e":
+			m.editLastMessage()
+		case "ctrl+r":
+			return m.regenerate("")
+		case "alt+r":
+			return m.regenerateWithNextTeamModel()
+		default:
+			return m.handleSendMessage(action)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"slices"
+
+	"github.com/billie-coop/loco/internal/llm"
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// lastUserIndex returns the index of the last user message, or -1
+func lastUserIndex(messages []llm.Message) int {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastUserMessage returns the content of the last user message
+func (m *Model) lastUserMessage() (string, bool) {
+	messages := m.messages.AllAsLLM()
+	i := lastUserIndex(messages)
+	if i < 0 {
+		return "", false
+	}
+	return messages[i].Content, true
+}
+
+// editLastMessage puts the last user message in the input. Sending it
+// replaces that message and everything after it; Esc cancels.
+func (m *Model) editLastMessage() {
+	if m.isStreaming {
+		m.showStatus("⚠️ Wait for the reply to finish before editing")
+		return
+	}
+	content, ok := m.lastUserMessage()
+	if !ok {
+		m.showStatus("No message to edit")
+		return
+	}
+	m.editingLast = true
+	m.input.SetValue(content)
+	m.input.CursorEnd()
+	m.showStatus("✏️ Editing last message · Enter to resend, Esc to cancel")
+}
+
+// regenerate sends the last user message again, replacing the reply and
+// anything after it. A non-empty modelID switches the chat model first.
+func (m *Model) regenerate(modelID string) tea.Cmd {
+	if m.isStreaming {
+		m.showStatus("⚠️ Wait for the reply to finish before regenerating")
+		return nil
+	}
+	content, ok := m.lastUserMessage()
+	if !ok {
+		m.showStatus("No message to regenerate")
+		return nil
+	}
+
+	if modelID != "" {
+		client, ok := m.app.LLM.(*llm.LMStudioClient)
+		if !ok {
+			m.showStatus("⚠️ The current LLM client can't switch models")
+			return nil
+		}
+		client.SetModel(modelID)
+		m.sidebar.SetModel(modelID, llm.DetectModelSize(modelID))
+	}
+
+	m.editingLast = false
+	m.rewindToLastUserMessage()
+	return m.handleSendMessage(content)
+}
+
+// regenerateWithNextTeamModel regenerates the last reply with the team's
+// next model after the current one, cycling small, medium and large
+func (m *Model) regenerateWithNextTeamModel() tea.Cmd {
+	client, ok := m.app.LLM.(*llm.LMStudioClient)
+	if !ok || m.app.TeamClients == nil {
+		m.showStatus("⚠️ No model team to pick another model from")
+		return nil
+	}
+
+	var models []string
+	for _, member := range []llm.Client{m.app.TeamClients.Small, m.app.TeamClients.Medium, m.app.TeamClients.Large} {
+		c, ok := member.(*llm.LMStudioClient)
+		if !ok || c.CurrentModel() == "" {
+			continue
+		}
+		if !slices.Contains(models, c.CurrentModel()) {
+			models = append(models, c.CurrentModel())
+		}
+	}
+
+	current := client.CurrentModel()
+	next := ""
+	for i, model := range models {
+		if model == current {
+			next = models[(i+1)%len(models)]
+			break
+		}
+	}
+	if next == "" && len(models) > 0 {
+		next = models[0]
+	}
+	if next == "" || next == current {
+		m.showStatus("⚠️ The team has no other model")
+		return nil
+	}
+
+	m.showStatus("🔁 Regenerating with " + next)
+	return m.regenerate(next)
+}
+
+// rewindToLastUserMessage drops the last user message and everything after
+// it, on screen and in the saved session, so it can be sent again
+func (m *Model) rewindToLastUserMessage() {
+	messages := m.messages.AllAsLLM()
+	i := lastUserIndex(messages)
+	if i < 0 {
+		return
+	}
+	m.messages.Replace(messages[:i])
+	for _, key := range m.messagesMeta.Keys() {
+		if key >= i {
+			m.messagesMeta.Delete(key)
+		}
+	}
+	m.messageList.ResetToolState()
+
+	// The session only holds the conversation, not tool cards, so its
+	// indexes differ from the screen's
+	if m.app.Sessions != nil {
+		if saved, err := m.app.Sessions.GetMessages(); err == nil {
+			if j := lastUserIndex(saved); j >= 0 {
+				if err := m.app.Sessions.UpdateCurrentMessages(saved[:j]); err != nil {
+					m.showStatus("⚠️ Failed to update session: " + err.Error())
+				}
+			}
+		}
+	}
+	m.syncMessagesToComponents()
+}
@@ -0,0 +1,33 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/billie-coop/loco/internal/session"
+)
+
+// SaveViewState records where the current session's chat view was left:
+// how far it was scrolled and which tool blocks were toggled
+func (m *Model) SaveViewState() {
+	if m.app.Sessions == nil {
+		return
+	}
+	_ = m.app.Sessions.SetView(&session.ViewState{
+		Saved:         time.Now(),
+		ScrollOffset:  m.messageList.ScrollOffset(),
+		ExpandedTools: m.messageList.ExpandedTools(),
+	})
+}
+
+// showSessionMessages syncs the messages of a session that was just made
+// current, putting the chat view back where it was left
+func (m *Model) showSessionMessages(view *session.ViewState) {
+	if view == nil {
+		m.messageList.ResetToolState()
+		m.syncMessagesToComponents()
+		return
+	}
+	m.messageList.SetExpandedTools(view.ExpandedTools)
+	m.syncMessagesToComponents()
+	m.messageList.ScrollToOffset(view.ScrollOffset)
+}
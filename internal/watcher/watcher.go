@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/billie-coop/loco/internal/crash"
 	"github.com/billie-coop/loco/internal/files"
 )
 
@@ -138,6 +139,7 @@ func (w *FileWatcher) StartWatching(watchPath string) error {
 
 // processFileEvents handles fsnotify events and triggers debouncing
 func (w *FileWatcher) processFileEvents() {
+	defer crash.Recover("file watcher")
 	defer w.wg.Done()
 	
 	for {
@@ -185,6 +187,8 @@ func (w *FileWatcher) fileChanged(path string, changeType ChangeType) {
 	if files.ShouldIgnore(path) {
 		return
 	}
+	// Cached git status no longer matches the work tree
+	files.InvalidateGit()
 	
 	w.timerMu.Lock()
 	defer w.timerMu.Unlock()
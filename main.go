@@ -2,27 +2,52 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/billie-coop/loco/internal/app"
+	"github.com/billie-coop/loco/internal/clipboard"
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/crash"
+	"github.com/billie-coop/loco/internal/llm"
+	"github.com/billie-coop/loco/internal/tools"
 	"github.com/billie-coop/loco/internal/tui"
 	"github.com/billie-coop/loco/internal/tui/events"
 	tea "github.com/charmbracelet/bubbletea/v2"
 )
 
 func main() {
+	// Subcommands run headless, without Bubble Tea
+	if code, ok := runHeadless(os.Args[1:], os.Stdout, os.Stderr); ok {
+		os.Exit(code)
+	}
+
 	// Get current working directory
 	workingDir, err := os.Getwd()
 	if err != nil {
 		log.Fatalf("Failed to get working directory: %v", err)
 	}
 
+	// First run in this project: set up the model server, team and startup
+	// work in the terminal before the TUI takes over
+	if needsOnboarding(workingDir) {
+		if err := runOnboarding(workingDir, os.Stdin, os.Stdout); err != nil {
+			log.Fatalf("Setup failed: %v", err)
+		}
+	}
+
 	// Create event broker
 	eventBroker := events.NewBroker()
 
+	// Panics write a diagnostic bundle to .loco/crash/ instead of wrecking the terminal
+	setupCrashReports(workingDir, eventBroker)
+
 	// Create app with all services
 	appInstance := app.New(workingDir, eventBroker)
 
@@ -37,17 +62,57 @@ func main() {
 	// Trigger startup analysis after a small delay
 	// This is system-initiated and will show permission dialog on first run
 	go func() {
+		defer crash.Recover("startup analysis")
 		// Small delay to let UI initialize
 		time.Sleep(500 * time.Millisecond)
 		appInstance.RunStartupAnalysis()
 	}()
 
 	// Create and run Bubble Tea program
-	program := tea.NewProgram(tuiModel, tea.WithAltScreen())
+	program := tea.NewProgram(tui.Guard(tuiModel), tea.WithAltScreen(), tea.WithMouseCellMotion())
+	crash.SetRestore(program.Kill)
+	// OSC 52 clipboard escapes go out between frames; Send blocks until the
+	// program takes the message, and copies start from inside Update
+	clipboard.SetTerminal(func(seq string) { go program.Send(tea.RawMsg{Msg: seq}) })
 
 	_, err = program.Run()
+	if crash.Exiting() {
+		// A background goroutine crashed and stopped the program; it reports and exits
+		select {}
+	}
 	if err != nil {
+		if bundle := crash.LastBundle(); bundle != "" && errors.Is(err, tea.ErrProgramPanic) {
+			fmt.Fprintf(os.Stderr, "Loco crashed. Diagnostic bundle: %s\n", bundle)
+			os.Exit(crash.ExitCode)
+		}
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
+
+	// The program has stopped, so the model can be read safely
+	tuiModel.SaveViewState()
+}
+
+// setupCrashReports enables crash bundles with the recent events and the
+// last LLM request. Events carry conversation text, so they are kept as
+// logging.content says.
+func setupCrashReports(workingDir string, eventBroker *events.Broker) {
+	crash.Init(filepath.Join(workingDir, ".loco", "crash"))
+	crash.AddSection("events", func() string {
+		configManager := config.NewManager(workingDir)
+		_ = configManager.Load()
+		response, _ := tools.NewEventsTool(eventBroker).Run(context.Background(), tools.ToolCall{Input: `{"count":200}`})
+		if text, ok := configManager.Get().Logging.Dump(response.Content); ok {
+			return text
+		}
+		return "Left out: logging.content is \"none\"."
+	})
+	crash.AddSection("llm", func() string {
+		request, ok := llm.LastRequest()
+		if !ok {
+			return "No LLM request was sent."
+		}
+		data, _ := json.MarshalIndent(request, "", "  ")
+		return string(data)
+	})
 }
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/billie-coop/loco/internal/config"
+	"github.com/billie-coop/loco/internal/llm"
+)
+
+// onboardingProbeTimeout bounds benchmarking during setup; it can take a
+// while with many models, so it's longer than at startup
+const onboardingProbeTimeout = 3 * time.Minute
+
+// onboarding is the setup run in the terminal the first time loco starts in
+// a project: it finds a model server, benchmarks its models, suggests a team
+// and asks what to run on startup, then writes .loco/config.jsonc.
+type onboarding struct {
+	workingDir string
+	in         *bufio.Scanner
+	out        io.Writer
+	candidates []llm.Server // Servers to look for
+}
+
+// needsOnboarding reports whether the project has no config yet and there
+// is someone at the terminal to answer questions
+func needsOnboarding(workingDir string) bool {
+	for _, name := range []string{"config.jsonc", "config.json"} {
+		if _, err := os.Stat(filepath.Join(workingDir, ".loco", name)); err == nil {
+			return false
+		}
+	}
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// runOnboarding runs the setup flow and writes the project's config
+func runOnboarding(workingDir string, in io.Reader, out io.Writer) error {
+	o := &onboarding{
+		workingDir: workingDir,
+		in:         bufio.NewScanner(in),
+		out:        out,
+		candidates: llm.LocalServers,
+	}
+	return o.run()
+}
+
+func (o *onboarding) run() error {
+	manager := config.NewManager(o.workingDir)
+	if err := manager.Load(); err != nil {
+		return err
+	}
+	cfg := manager.Get()
+
+	fmt.Fprintln(o.out, "Welcome to Loco! Let's set up this project.")
+	fmt.Fprintln(o.out)
+
+	server, ok := o.chooseServer()
+	switch {
+	case !ok && server.URL == "mock":
+		cfg.Provider = "mock"
+		fmt.Fprintln(o.out, "Using the mock provider; set provider in .loco/config.jsonc once a server is running.")
+	case !ok:
+		cfg.Provider = "lmstudio"
+		cfg.LMStudioURL = server.URL
+		fmt.Fprintf(o.out, "Loco will use %s once it is running.\n", server.URL)
+	default:
+		cfg.Provider = "lmstudio"
+		cfg.LMStudioURL = server.URL
+		if server.Name == "Ollama" {
+			cfg.Analysis.RAG.Embedder = "ollama"
+			cfg.Analysis.RAG.EmbedderURL = server.URL
+		}
+		if team := o.chooseTeam(server); team != nil {
+			cfg.LLM.Smallest.ModelID = team.Small
+			cfg.LLM.Medium.ModelID = team.Medium
+			cfg.LLM.Largest.ModelID = team.Large
+			cfg.PreferredModel = team.Medium
+		}
+	}
+
+	fmt.Fprintln(o.out)
+	fmt.Fprintln(o.out, "On startup, Loco can:")
+	cfg.Analysis.RAG.AutoIndex = o.confirm("Index the project for search", cfg.Analysis.RAG.AutoIndex)
+	cfg.Analysis.RAG.AutoIndexOnChange = o.confirm("Re-index files when they change", cfg.Analysis.RAG.AutoIndexOnChange)
+	cfg.Analysis.Startup.Autorun = o.confirm("Scan the project for an overview", cfg.Analysis.Startup.Autorun)
+
+	if err := manager.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintln(o.out)
+	fmt.Fprintln(o.out, "Saved .loco/config.jsonc. Change it any time with /settings.")
+	return nil
+}
+
+// chooseServer finds the local model servers and picks one, asking when
+// there are several. When none answers it returns false with the URL to use
+// anyway, or "mock" for the mock provider.
+func (o *onboarding) chooseServer() (llm.Server, bool) {
+	fmt.Fprintln(o.out, "Looking for model servers...")
+	servers := llm.DetectServers(context.Background(), o.candidates)
+
+	for len(servers) == 0 {
+		var names []string
+		for _, candidate := range o.candidates {
+			names = append(names, fmt.Sprintf("%s (%s)", candidate.Name, candidate.URL))
+		}
+		fmt.Fprintf(o.out, "No server found at %s.\n", strings.Join(names, " or "))
+		url := o.ask("Server URL, or mock to try Loco without one", o.candidates[0].URL)
+		url = strings.TrimSuffix(url, "/")
+		if url == "mock" || url == o.candidates[0].URL {
+			return llm.Server{URL: url}, false
+		}
+		servers = llm.DetectServers(context.Background(), []llm.Server{{Name: "Server", URL: url}})
+	}
+
+	for i, server := range servers {
+		fmt.Fprintf(o.out, "  %d. %s at %s (%d models)\n", i+1, server.Name, server.URL, len(server.Models))
+	}
+	if len(servers) == 1 {
+		return servers[0], true
+	}
+	return servers[o.choose("Use which server?", len(servers), 1)-1], true
+}
+
+// chooseTeam benchmarks the server's models and suggests a team, letting
+// the user pick each slot instead. Returns nil when the server has no models.
+func (o *onboarding) chooseTeam(server llm.Server) *llm.ModelTeam {
+	if len(server.Models) == 0 {
+		fmt.Fprintf(o.out, "%s has no models loaded; Loco will use whatever is loaded when it starts.\n", server.Name)
+		return nil
+	}
+
+	fmt.Fprintln(o.out, "\nBenchmarking the models (this can take a minute)...")
+	client := llm.NewLMStudioClient()
+	client.SetEndpoint(server.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), onboardingProbeTimeout)
+	probes, err := client.ProbeModels(ctx, filepath.Join(o.workingDir, ".loco", "model_probes.json"))
+	cancel()
+
+	team := llm.TeamFromProbes(probes)
+	if err != nil || team == nil {
+		fmt.Fprintln(o.out, "Couldn't benchmark the models; guessing sizes from their names.")
+		team = llm.GetDefaultTeam(server.Models)
+	} else {
+		for _, probe := range probes {
+			fmt.Fprintf(o.out, "  %-40s %-2s %s\n", probe.ID, probe.Size, describeProbe(probe))
+		}
+	}
+
+	fmt.Fprintln(o.out, "\nSuggested team:")
+	fmt.Fprintf(o.out, "  Small:  %s\n  Medium: %s\n  Large:  %s\n", team.Small, team.Medium, team.Large)
+	if o.confirm("Use this team", true) {
+		return team
+	}
+
+	var models []llm.Model
+	fmt.Fprintln(o.out, "\nModels:")
+	for _, model := range server.Models {
+		if model.Size != llm.SizeSpecial {
+			models = append(models, model)
+			fmt.Fprintf(o.out, "  %d. %s\n", len(models), model.ID)
+		}
+	}
+	if len(models) == 0 {
+		return team
+	}
+	pick := func(slot, current string) string {
+		def := 1
+		for i, model := range models {
+			if model.ID == current {
+				def = i + 1
+			}
+		}
+		return models[o.choose(slot+" model?", len(models), def)-1].ID
+	}
+	return &llm.ModelTeam{
+		Name:   "Config Team",
+		Small:  pick("Small", team.Small),
+		Medium: pick("Medium", team.Medium),
+		Large:  pick("Large", team.Large),
+	}
+}
+
+// describeProbe summarizes a probed model's speed and abilities
+func describeProbe(probe llm.ModelProbe) string {
+	var parts []string
+	if probe.TokensPerSecond > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f tok/s", probe.TokensPerSecond))
+	} else {
+		parts = append(parts, "benchmark failed")
+	}
+	if probe.ToolUse {
+		parts = append(parts, "tools")
+	}
+	if probe.Vision {
+		parts = append(parts, "vision")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ask prints a question and reads a line, returning def for a blank answer
+// or when input has ended
+func (o *onboarding) ask(question, def string) string {
+	if def != "" {
+		fmt.Fprintf(o.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(o.out, "%s: ", question)
+	}
+	if !o.in.Scan() {
+		fmt.Fprintln(o.out)
+		return def
+	}
+	if answer := strings.TrimSpace(o.in.Text()); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// confirm asks a yes/no question
+func (o *onboarding) confirm(question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		switch strings.ToLower(o.ask(question+"? ("+hint+")", "")) {
+		case "":
+			return def
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
+}
+
+// choose asks for a number from 1 to n
+func (o *onboarding) choose(question string, n, def int) int {
+	for {
+		answer := o.ask(question, strconv.Itoa(def))
+		if i, err := strconv.Atoi(answer); err == nil && i >= 1 && i <= n {
+			return i
+		}
+		fmt.Fprintf(o.out, "Enter a number from 1 to %d.\n", n)
+	}
+}